@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 
 	"gomini/pkg/core"
 	"gomini/pkg/gomini"
@@ -41,7 +42,7 @@ func main() {
 	if err != nil {
 		log.Printf("Failed to send message: %v\n", err)
 	} else {
-		fmt.Printf("Response from %s: %v\n", response.Provider, response.Choices[0])
+		fmt.Printf("Response from %s: %s\n", response.Provider, response.Choices[0].Content())
 	}
 
 	// Example 2: Stream a message from current provider
@@ -54,18 +55,10 @@ func main() {
 	}, "example-prompt-1")
 
 	fmt.Print("Streaming response: ")
-	for event := range streamChan {
-		switch event.Type {
-		case gomini.EventContent:
-			if contentData, ok := event.Data.(gomini.ContentEvent); ok {
-				fmt.Print(contentData.Text)
-			}
-		case gomini.EventError:
-			fmt.Printf("\nError: %v\n", event.Error)
-		case gomini.EventFinished:
-			fmt.Print("\n")
-		}
+	if err := gomini.StreamTo(context.Background(), streamChan, os.Stdout); err != nil {
+		fmt.Printf("\nError: %v\n", err)
 	}
+	fmt.Print("\n")
 
 	// Example 3: JSON Generation
 	fmt.Println("\n=== Example 3: JSON Generation ===")
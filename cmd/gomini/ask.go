@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gomini/pkg/core"
+	"gomini/pkg/gomini"
+)
+
+// Exit codes of gomini ask, so scripts can react to the class of failure
+const (
+	exitOK          = 0
+	exitError       = 1 // Unclassified failure
+	exitUsage       = 2
+	exitAuth        = 3
+	exitRateLimit   = 4
+	exitBadRequest  = 5 // Invalid request, model or parameters
+	exitContent     = 6 // Blocked by content filtering or safety settings
+	exitTimeout     = 7
+	exitUnavailable = 8 // Server, network or provider failure
+)
+
+func runAsk(args []string) int {
+	flags := flag.NewFlagSet("ask", flag.ContinueOnError)
+	model := flags.String("m", "", "model to use (default: the provider's default model)")
+	provider := flags.String("p", "", "provider to use, e.g. openai or gemini")
+	system := flags.String("system", "", "system instruction")
+	temperature := flags.Float64("temperature", 0, "sampling temperature")
+	maxTokens := flags.Int("max-tokens", 0, "maximum output tokens")
+	schema := flags.String("schema", "", "JSON schema, inline or @file, to answer with structured JSON")
+	timeout := flags.Duration("timeout", 0, "overall timeout, e.g. 30s (default none)")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: gomini ask [flags] \"prompt\"")
+		fmt.Fprintln(os.Stderr, "       cat file | gomini ask [flags] [\"instruction\"]")
+		fmt.Fprintln(os.Stderr)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	prompt, err := askPrompt(strings.Join(flags.Args(), " "), os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gomini ask: %v\n", err)
+		return exitError
+	}
+	if prompt == "" {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var schemaMap map[string]interface{}
+	if *schema != "" {
+		if schemaMap, err = loadSchema(*schema); err != nil {
+			fmt.Fprintf(os.Stderr, "gomini ask: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	config := gomini.NewConfig()
+	if err := config.LoadFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "gomini ask: failed to load config: %v\n", err)
+		return exitError
+	}
+	client, err := core.NewClient(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gomini ask: cannot create client: %v\n", err)
+		return exitCode(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var messages []gomini.Message
+	if *system != "" {
+		messages = append(messages, gomini.NewSystemMessage(*system))
+	}
+	messages = append(messages, gomini.NewUserMessage(prompt))
+
+	requestConfig := map[string]interface{}{}
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "temperature":
+			requestConfig["temperature"] = *temperature
+		case "max-tokens":
+			requestConfig["max_output_tokens"] = *maxTokens
+		}
+	})
+
+	if schemaMap != nil {
+		err = askJSON(ctx, client, &gomini.JSONRequest{
+			Messages: messages,
+			Model:    *model,
+			Provider: gomini.ProviderType(*provider),
+			Schema:   schemaMap,
+			Config:   requestConfig,
+		})
+	} else {
+		err = gomini.StreamTo(ctx, client.SendMessageStream(ctx, &gomini.ChatRequest{
+			Messages: messages,
+			Model:    *model,
+			Provider: gomini.ProviderType(*provider),
+			Config:   requestConfig,
+		}, ""), os.Stdout)
+		if err == nil {
+			fmt.Println()
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gomini ask: %v\n", err)
+		return exitCode(err)
+	}
+	return exitOK
+}
+
+// askPrompt combines the prompt argument with text piped on stdin. Piped
+// text follows the argument, which then reads as an instruction about it.
+func askPrompt(arg string, stdin *os.File) (string, error) {
+	prompt := strings.TrimSpace(arg)
+	info, err := stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return prompt, nil
+	}
+
+	piped, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	input := strings.TrimSpace(string(piped))
+	switch {
+	case input == "":
+		return prompt, nil
+	case prompt == "":
+		return input, nil
+	default:
+		return prompt + "\n\n" + input, nil
+	}
+}
+
+// loadSchema parses an inline JSON schema or, with a leading @, a schema file
+func loadSchema(value string) (map[string]interface{}, error) {
+	data := []byte(value)
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read schema: %w", err)
+		}
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return schema, nil
+}
+
+func askJSON(ctx context.Context, client *core.Client, request *gomini.JSONRequest) error {
+	resp, err := client.GenerateJSON(ctx, request)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(resp.Data)
+}
+
+// exitCode maps an error to the exit code of its error class
+func exitCode(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return exitTimeout
+	}
+	var llmErr *gomini.LLMError
+	if !errors.As(err, &llmErr) {
+		// Classify plain errors by their message, e.g. "503 service unavailable"
+		llmErr = gomini.WrapProviderError(err, "", "")
+	}
+	switch {
+	case llmErr.IsAuthError():
+		return exitAuth
+	case llmErr.IsRateLimit():
+		return exitRateLimit
+	case llmErr.IsContentError():
+		return exitContent
+	}
+	switch llmErr.Code {
+	case gomini.ErrorTimeout:
+		return exitTimeout
+	case gomini.ErrorInvalidRequest, gomini.ErrorInvalidModel, gomini.ErrorInvalidParameters,
+		gomini.ErrorRequestTooLarge, gomini.ErrorUnsupportedFeature, gomini.ErrorTokenLimitExceeded,
+		gomini.ErrorValidation, gomini.ErrorMissingField, gomini.ErrorInvalidFormat:
+		return exitBadRequest
+	case gomini.ErrorServerError, gomini.ErrorServiceUnavailable, gomini.ErrorInternalError,
+		gomini.ErrorNetworkError, gomini.ErrorConnectionFailed, gomini.ErrorDNSError,
+		gomini.ErrorProviderNotFound, gomini.ErrorProviderDisabled, gomini.ErrorAllProvidersFailed:
+		return exitUnavailable
+	}
+	return exitError
+}
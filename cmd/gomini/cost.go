@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"gomini/pkg/core"
+)
+
+func runCost(args []string) int {
+	flags := flag.NewFlagSet("cost", flag.ContinueOnError)
+	sessionsDir := flags.String("sessions", "", "directory of a file session store to report on")
+	jsonOutput := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *sessionsDir == "" {
+		fmt.Fprintln(os.Stderr, "gomini cost: -sessions is required")
+		return 2
+	}
+
+	store, err := core.NewFileSessionStore(*sessionsDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gomini cost: %v\n", err)
+		return 1
+	}
+	report, err := core.SessionCostReport(context.Background(), store)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gomini cost: %v\n", err)
+		return 1
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION\tTURNS\tSPENT $\tBUDGET $\tUPDATED")
+	for _, session := range report.Sessions {
+		budget := "-"
+		if session.Budget > 0 {
+			budget = fmt.Sprintf("%.4f", session.Budget)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.4f\t%s\t%s\n", session.ID, session.Turns, session.Spent, budget,
+			session.UpdatedAt.Local().Format(time.DateTime))
+	}
+	fmt.Fprintf(w, "TOTAL\t%d\t%.4f\t\t\n", report.Turns, report.Total)
+	w.Flush()
+	return 0
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gomini/pkg/core"
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func runDoctor(args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	timeout := flags.Duration("timeout", 30*time.Second, "timeout for each live check")
+	models := flags.String("models", "", "models to probe, e.g. openai=gpt-4o,gpt-4o-mini;gemini=gemini-1.5-pro")
+	offline := flags.Bool("offline", false, "only check the configuration, without live requests")
+	jsonOutput := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	modelOverrides, err := parseModelFlag(*models)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gomini doctor: %v\n", err)
+		return 2
+	}
+
+	config := gomini.NewConfig()
+	if err := config.LoadFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "gomini doctor: failed to load config: %v\n", err)
+		return 1
+	}
+
+	report := &core.DoctorReport{Diagnostics: config.Diagnose()}
+	client, err := core.NewClient(config)
+	if err == nil {
+		defer client.Close()
+		report = client.Doctor(context.Background(), core.DoctorOptions{
+			Models:   modelOverrides,
+			Timeout:  *timeout,
+			SkipLive: *offline,
+		})
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		printDoctorReport(report)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\ngomini doctor: cannot create client: %v\n", err)
+		return 1
+	}
+	if !report.Healthy() {
+		return 1
+	}
+	return 0
+}
+
+// parseModelFlag parses "provider=model,model;provider=model"
+func parseModelFlag(value string) (map[providers.ProviderType][]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	overrides := make(map[providers.ProviderType][]string)
+	for _, group := range strings.Split(value, ";") {
+		provider, list, ok := strings.Cut(group, "=")
+		if !ok || provider == "" || list == "" {
+			return nil, fmt.Errorf("invalid -models entry %q, expected provider=model[,model]", group)
+		}
+		providerType := providers.ProviderType(strings.TrimSpace(provider))
+		for _, model := range strings.Split(list, ",") {
+			if model = strings.TrimSpace(model); model != "" {
+				overrides[providerType] = append(overrides[providerType], model)
+			}
+		}
+	}
+	return overrides, nil
+}
+
+func printDoctorReport(report *core.DoctorReport) {
+	fmt.Println("Configuration")
+	if len(report.Diagnostics) == 0 {
+		fmt.Println("  no problems found")
+	}
+	for _, diagnostic := range report.Diagnostics {
+		fmt.Printf("  %s\n", diagnostic)
+	}
+
+	if len(report.Results) == 0 {
+		return
+	}
+
+	type row struct {
+		provider providers.ProviderType
+		model    string
+	}
+	var rows []row
+	cells := make(map[row]map[string]core.ProbeResult)
+	for _, result := range report.Results {
+		key := row{result.Provider, result.Model}
+		if cells[key] == nil {
+			cells[key] = make(map[string]core.ProbeResult)
+			rows = append(rows, key)
+		}
+		cells[key][result.Check] = result
+	}
+
+	fmt.Println()
+	fmt.Println("Live checks")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  PROVIDER\tMODEL\t%s\n", strings.ToUpper(strings.Join(core.DoctorChecks, "\t")))
+	for _, r := range rows {
+		columns := []string{string(r.provider), r.model}
+		for _, check := range core.DoctorChecks {
+			result, ok := cells[r][check]
+			switch {
+			case !ok:
+				columns = append(columns, "-")
+			case result.OK:
+				columns = append(columns, fmt.Sprintf("ok %s", result.Latency.Round(time.Millisecond)))
+			default:
+				columns = append(columns, "FAIL")
+			}
+		}
+		fmt.Fprintf(w, "  %s\n", strings.Join(columns, "\t"))
+	}
+	w.Flush()
+
+	var failures []core.ProbeResult
+	for _, result := range report.Results {
+		if !result.OK {
+			failures = append(failures, result)
+		}
+	}
+	if len(failures) > 0 {
+		fmt.Println()
+		fmt.Println("Failures")
+		for _, result := range failures {
+			fmt.Printf("  %s/%s %s: %s\n", result.Provider, result.Model, result.Check, result.Error)
+		}
+	}
+}
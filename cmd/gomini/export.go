@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gomini/pkg/core"
+)
+
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	sessionsDir := flags.String("sessions", "", "directory of a file session store to export")
+	format := flags.String("format", string(core.DatasetOpenAI), "dataset format: openai or gemini")
+	output := flags.String("o", "", "output file (default stdout)")
+	skipTools := flags.Bool("skip-tools", false, "drop tool calls and tool results")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *sessionsDir == "" {
+		fmt.Fprintln(os.Stderr, "gomini export: -sessions is required")
+		return 2
+	}
+
+	store, err := core.NewFileSessionStore(*sessionsDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gomini export: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gomini export: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	written, err := core.ExportSessionStore(context.Background(), store, w, core.DatasetOptions{
+		Format:    core.DatasetFormat(*format),
+		SkipTools: *skipTools,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gomini export: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d example(s)\n", written)
+	return 0
+}
@@ -0,0 +1,58 @@
+// Command gomini is the command-line interface for the gomini client.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command is a gomini subcommand; run returns the process exit code
+type command struct {
+	summary string
+	run     func(args []string) int
+}
+
+var commands = map[string]command{
+	"ask":    {summary: "Send one prompt, or text piped on stdin, and print the answer", run: runAsk},
+	"cost":   {summary: "Report what stored sessions have spent", run: runCost},
+	"doctor": {summary: "Diagnose configuration and probe every provider/model", run: runDoctor},
+	"export": {summary: "Export stored sessions as a fine-tuning dataset", run: runExport},
+	"models": {summary: "List the models of every enabled provider with context size, capabilities and prices", run: runModels},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage()
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "gomini: unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
+	os.Exit(cmd.run(os.Args[2:]))
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: gomini <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gomini/pkg/core"
+	"gomini/pkg/gomini"
+)
+
+func runModels(args []string) int {
+	flags := flag.NewFlagSet("models", flag.ContinueOnError)
+	jsonOutput := flags.Bool("json", false, "print the models as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	config := gomini.NewConfig()
+	if err := config.LoadFromEnv(); err != nil {
+		fmt.Fprintf(os.Stderr, "gomini models: failed to load config: %v\n", err)
+		return 1
+	}
+	client, err := core.NewClient(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gomini models: cannot create client: %v\n", err)
+		return 1
+	}
+	defer client.Close()
+
+	listings, listErr := client.ListAllModels(context.Background())
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(listings)
+	} else {
+		printModels(listings)
+	}
+
+	if listErr != nil {
+		fmt.Fprintf(os.Stderr, "gomini models: %v\n", listErr)
+		return 1
+	}
+	return 0
+}
+
+func printModels(listings []core.ModelListing) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tMODEL\tCONTEXT\tINPUT $/1M\tOUTPUT $/1M\tCAPABILITIES")
+	for _, listing := range listings {
+		contextSize := "-"
+		if listing.ContextSize > 0 {
+			contextSize = fmt.Sprintf("%d", listing.ContextSize)
+		}
+		input, output := "-", "-"
+		if listing.Priced {
+			input = fmt.Sprintf("%.3f", listing.Price.Input)
+			output = fmt.Sprintf("%.3f", listing.Price.Output)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", listing.Provider, listing.ID, contextSize, input, output,
+			strings.Join(capabilityNames(listing.Capabilities), ","))
+	}
+	w.Flush()
+}
+
+// capabilityNames returns short names for what a model can do beyond text
+func capabilityNames(caps gomini.ModelCapabilities) []string {
+	var names []string
+	for _, capability := range []struct {
+		name string
+		has  bool
+	}{
+		{"vision", caps.ImageInput},
+		{"audio", caps.AudioInput},
+		{"documents", caps.DocumentInput},
+		{"tools", caps.FunctionCalling},
+		{"json", caps.JSONMode},
+		{"structured", caps.StructuredOutput},
+		{"streaming", caps.Streaming},
+		{"reasoning", caps.Reasoning || caps.ThinkingMode},
+		{"image-gen", caps.ImageGeneration},
+		{"speech", caps.SpeechGeneration},
+		{"transcription", caps.Transcription},
+	} {
+		if capability.has {
+			names = append(names, capability.name)
+		}
+	}
+	return names
+}
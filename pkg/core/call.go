@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gomini/pkg/gomini"
+)
+
+// ToolInvocation is a tool call picked by the model in Client.Call
+type ToolInvocation struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// Bind decodes the invocation arguments into v, typically a pointer to a struct
+// mirroring the tool's parameter schema
+func (t *ToolInvocation) Bind(v interface{}) error {
+	raw, err := json.Marshal(t.Arguments)
+	if err != nil {
+		return fmt.Errorf("failed to encode arguments for %s: %w", t.Name, err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to decode arguments for %s: %w", t.Name, err)
+	}
+	return nil
+}
+
+// CallResult is the outcome of Client.Call: either final text or a tool invocation
+type CallResult struct {
+	Text        string           `json:"text,omitempty"`
+	Invocation  *ToolInvocation  `json:"invocation,omitempty"`  // First tool call, nil when the model answered with text
+	Invocations []ToolInvocation `json:"invocations,omitempty"` // All tool calls in the turn
+	Usage       *gomini.Usage    `json:"usage,omitempty"`
+}
+
+// IsToolCall reports whether the model chose to call a tool
+func (r *CallResult) IsToolCall() bool {
+	return r.Invocation != nil
+}
+
+// Call sends a single prompt with the given tools and returns either the final
+// text or the tool the model picked. Tools are not executed and no follow-up
+// turn is made; use it for single-round function picking.
+func (c *Client) Call(ctx context.Context, model, prompt string, tools ...gomini.Tool) (*CallResult, error) {
+	request := &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage(prompt)},
+		Model:    model,
+		Tools:    tools,
+	}
+	if len(tools) > 0 {
+		request.ToolChoice = "auto"
+	}
+
+	resp, err := c.SendMessage(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	choice := resp.Choices[0]
+	result := &CallResult{Text: choice.Content(), Usage: resp.Usage}
+	for _, toolCall := range choice.ToolCalls() {
+		result.Invocations = append(result.Invocations, ToolInvocation{
+			ID:        toolCall.ID,
+			Name:      toolCall.Name,
+			Arguments: toolCall.Arguments,
+		})
+	}
+	if len(result.Invocations) > 0 {
+		result.Invocation = &result.Invocations[0]
+	}
+
+	return result, nil
+}
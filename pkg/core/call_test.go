@@ -0,0 +1,75 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"gomini/pkg/gomini"
+)
+
+var weatherTool = gomini.FunctionTool{
+	Name:        "get_weather",
+	Description: "Get the current weather for a city",
+	Parameters: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"city": map[string]interface{}{"type": "string"},
+		},
+		"required": []interface{}{"city"},
+	},
+}
+
+func TestClient_Call_ToolInvocation(t *testing.T) {
+	client, mockProvider := newGenerateTestClient(t, nil)
+	mockProvider.chatResponse = &gomini.ChatResponse{
+		Choices: []gomini.Choice{{
+			Message: gomini.NewAssistantToolCallMessage("", []gomini.ToolCall{
+				{ID: "call_1", Name: "get_weather", Arguments: map[string]interface{}{"city": "Taipei"}},
+			}),
+		}},
+		Usage: &gomini.Usage{TotalTokens: 42},
+	}
+
+	result, err := client.Call(context.Background(), "test-model", "Weather in Taipei?", weatherTool)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mockProvider.lastRequest.Tools) != 1 || mockProvider.lastRequest.ToolChoice != "auto" {
+		t.Errorf("expected tools to be forwarded, got %+v", mockProvider.lastRequest)
+	}
+	if !result.IsToolCall() || result.Invocation.Name != "get_weather" || result.Invocation.ID != "call_1" {
+		t.Fatalf("expected get_weather invocation, got %+v", result)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 42 {
+		t.Errorf("expected usage to be returned, got %+v", result.Usage)
+	}
+
+	var args struct {
+		City string `json:"city"`
+	}
+	if err := result.Invocation.Bind(&args); err != nil {
+		t.Fatalf("unexpected bind error: %v", err)
+	}
+	if args.City != "Taipei" {
+		t.Errorf("expected city Taipei, got %q", args.City)
+	}
+}
+
+func TestClient_Call_Text(t *testing.T) {
+	client, mockProvider := newGenerateTestClient(t, nil)
+
+	result, err := client.Call(context.Background(), "test-model", "Hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsToolCall() {
+		t.Errorf("expected text answer, got %+v", result.Invocation)
+	}
+	if result.Text != "Mock response" {
+		t.Errorf("expected mock text, got %q", result.Text)
+	}
+	if mockProvider.lastRequest.ToolChoice != nil {
+		t.Errorf("expected no tool choice without tools, got %v", mockProvider.lastRequest.ToolChoice)
+	}
+}
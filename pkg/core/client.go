@@ -3,12 +3,22 @@ package core
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/prompts"
 	"gomini/pkg/gomini/providers"
+	"gomini/pkg/gomini/providers/chaos"
+	"gomini/pkg/gomini/providers/deepseek"
 	"gomini/pkg/gomini/providers/gemini"
+	"gomini/pkg/gomini/providers/groq"
+	"gomini/pkg/gomini/providers/mock"
 	"gomini/pkg/gomini/providers/openai"
+	"gomini/pkg/gomini/providers/vcr"
 )
 
 // Constants from TypeScript version
@@ -18,15 +28,86 @@ const (
 
 // Client is the main unified LLM client  
 type Client struct {
-	config          *gomini.Config
-	currentProvider providers.LLMProvider
-	providerType    providers.ProviderType
-	created         time.Time
+	// Configuration and active provider, replaced as a whole by ReloadConfig
+	// and SwitchProvider
+	state   atomic.Pointer[clientState]
+	created time.Time
 	
 	// Session management and loop detection
-	sessionTurnCount int
-	lastPromptID     string
-	loopDetector     *LoopDetectionService
+	loopDetectors    *loopDetectorPool // Loop detection state per prompt ID
+
+	// Fair scheduling of provider reads, unlimited when MaxConcurrentStreams is unset
+	streamScheduler *streamScheduler
+
+	// Background cleanup of registered expiring stores
+	janitor *janitor
+
+	// Structured logging; logLevel follows Config.LogLevel for the default handler
+	logger   atomic.Pointer[slog.Logger]
+	logLevel slog.LevelVar
+
+	// Response cache; customCache is set when the backend came from SetResponseCache
+	cacheMu     sync.RWMutex
+	cache       ResponseCache
+	customCache bool
+
+	// Background refreshes of stale cache entries, by cache key
+	revalidating  sync.Map
+	revalidations sync.WaitGroup
+
+	// Turns, usage and loop status per prompt ID
+	prompts promptTracker
+
+	// Subscribers to the events of all requests
+	events eventBus
+
+	// Requests in flight per provider, capped by MaxConcurrentRequests
+	requests requestQueues
+
+	// Requests and tokens consumed per provider key
+	quota *quotaTracker
+
+	// Time to first token and tokens/second of recent streams per model
+	latency latencyTracker
+
+	// Calls in flight, latency and errors per provider, for StrategyLeastLoaded
+	load loadTracker
+
+	// Price table loaded from Config.PricingSource
+	pricingMu sync.RWMutex
+	prices    *gomini.PriceTable
+
+	// Overrides provider construction in tests
+	providerFactory func(providers.ProviderType) (providers.LLMProvider, error)
+
+	// Cassettes of Config.VCR, by mode and path
+	vcrMu     sync.Mutex
+	cassettes map[string]*vcr.Cassette
+
+	// Checks run on SendMessage responses
+	validatorsMu sync.RWMutex
+	validators   []OutputValidator
+
+	// Provider of each catalog model, for routing requests by model name
+	modelIndexMu   sync.Mutex
+	modelProviders map[string][]providers.ProviderType
+
+	// Named prompt templates used by SendTemplate
+	templatesMu sync.Mutex
+	templates   *prompts.Library
+
+	// Functions executing tool calls in RunTools, by tool name, the calls
+	// waiting on ConfirmToolCall, by call ID, and the provider of tool spans
+	toolsMu        sync.RWMutex
+	tools          map[string]*registeredTool
+	confirmations  map[string]chan bool
+	tracerProvider trace.TracerProvider
+
+	// Serializes ReloadConfig and SwitchProvider
+	stateMu sync.Mutex
+
+	closeMu sync.Mutex
+	closed  bool
 }
 
 // NewClient creates a new unified LLM client
@@ -40,10 +121,21 @@ func NewClient(config *gomini.Config) (*Client, error) {
 	}
 
 	client := &Client{
-		config:       config,
-		created:      time.Now(),
-		loopDetector: NewLoopDetectionService(config),
+		created:       time.Now(),
+		loopDetectors: newLoopDetectorPool(config),
+		quota:         newQuotaTracker(),
 	}
+	client.janitor = newJanitor(config.CleanupInterval, client.Logger)
+	client.logger.Store(client.newLogger(config))
+	client.loopDetectors.setLogger(client.Logger())
+	client.loopDetectors.setJudge(client.judgeLoop)
+	prices, err := client.loadPricing(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing: %w", err)
+	}
+	client.prices = prices
+	client.replaceCache(newConfiguredCache(config))
+	client.streamScheduler = newStreamScheduler(streamSlots(config.MaxConcurrentStreams))
 
 	// Initialize with default provider
 	defaultProvider := config.DefaultProvider
@@ -56,9 +148,12 @@ func NewClient(config *gomini.Config) (*Client, error) {
 		defaultProvider = enabledProviders[0]
 	}
 
-	if err := client.initializeProvider(defaultProvider); err != nil {
+	set := client.newProviderSet(config)
+	provider, err := set.get(defaultProvider)
+	if err != nil {
 		return nil, fmt.Errorf("failed to initialize default provider: %w", err)
 	}
+	client.state.Store(&clientState{config: config, providerType: defaultProvider, provider: provider, providers: set})
 
 	return client, nil
 }
@@ -72,15 +167,69 @@ func NewClientFromEnv() (*Client, error) {
 	return NewClient(config)
 }
 
-// initializeProvider sets up a specific provider
-func (c *Client) initializeProvider(providerType providers.ProviderType) error {
-	providerConfig, err := c.config.GetProviderConfig(providerType)
+// newProviderSet creates the set of live provider instances for config
+func (c *Client) newProviderSet(config *gomini.Config) *providerSet {
+	return newProviderSet(func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		return c.newProviderFrom(config, providerType)
+	})
+}
+
+// newProviderFrom creates a provider instance from the given configuration,
+// recording or replaying its traffic when Config.VCR is set and injecting
+// faults when Config.Chaos is set
+func (c *Client) newProviderFrom(config *gomini.Config, providerType providers.ProviderType) (providers.LLMProvider, error) {
+	var provider providers.LLMProvider
+	var err error
+	if config.VCR != nil && config.VCR.Mode != "" {
+		provider, err = c.newVCRProvider(config, providerType)
+	} else {
+		provider, err = c.buildProvider(config, providerType)
+	}
+	if err != nil || config.Chaos == nil {
+		return provider, err
+	}
+
+	// Faults are injected outside any recorder, so they are never recorded
+	chaotic, err := chaos.NewProvider(provider, config.Chaos)
+	if err != nil {
+		provider.Close()
+		return nil, fmt.Errorf("invalid chaos config: %w", err)
+	}
+	return providers.Expose(chaotic, provider), nil
+}
+
+// buildProvider creates the real provider for providerType
+func (c *Client) buildProvider(config *gomini.Config, providerType providers.ProviderType) (providers.LLMProvider, error) {
+	if c.providerFactory != nil {
+		return c.providerFactory(providerType)
+	}
+
+	providerConfig, err := config.GetProviderConfig(providerType)
 	if err != nil {
-		return fmt.Errorf("provider %s not found in config: %w", providerType, err)
+		return nil, fmt.Errorf("provider %s not found in config: %w", providerType, err)
 	}
 
 	if !providerConfig.Enabled {
-		return fmt.Errorf("provider %s is not enabled", providerType)
+		return nil, fmt.Errorf("provider %s is not enabled", providerType)
+	}
+
+	keys := providerConfig.Keys()
+	if len(keys) > 1 {
+		return c.newKeyPool(providerType, providerConfig, keys)
+	}
+	if len(keys) == 1 && providerConfig.APIKey == "" {
+		keyed := *providerConfig
+		keyed.APIKey = keys[0]
+		providerConfig = &keyed
+	}
+	return c.buildProviderFrom(providerConfig, providerType)
+}
+
+// buildProviderFrom creates a provider from its provider configuration
+func (c *Client) buildProviderFrom(providerConfig *gomini.ProviderConfig, providerType providers.ProviderType) (providers.LLMProvider, error) {
+	httpClient, err := providerConfig.HTTPClient()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s transport config: %w", providerType, err)
 	}
 
 	var provider providers.LLMProvider
@@ -88,129 +237,352 @@ func (c *Client) initializeProvider(providerType providers.ProviderType) error {
 	switch providerType {
 	case providers.ProviderGemini:
 		geminiConfig := c.convertToGeminiConfig(providerConfig)
+		geminiConfig.HTTPClient = httpClient
 		provider, err = gemini.NewProvider(geminiConfig)
 	case providers.ProviderOpenAI:
 		openaiConfig := c.convertToOpenAIConfig(providerConfig)
+		openaiConfig.HTTPClient = httpClient
 		provider, err = openai.NewProvider(openaiConfig)
+	case providers.ProviderGroq:
+		provider, err = groq.NewProvider(&groq.Config{
+			APIKey:       providerConfig.APIKey,
+			BaseURL:      providerConfig.Endpoint,
+			DefaultModel: providerConfig.DefaultModel,
+			ExtraHeaders: providerConfig.ExtraHeaders,
+			ExtraQuery:   providerConfig.ExtraQuery,
+			ExtraBody:    providerConfig.ExtraBody,
+			HTTPClient:   httpClient,
+
+			Capabilities:   providerConfig.Capabilities,
+			DiscoverModels: providerConfig.DiscoverModels,
+			ModelsTTL:      providerConfig.ModelsTTL,
+		})
+	case providers.ProviderDeepSeek:
+		provider, err = deepseek.NewProvider(&deepseek.Config{
+			APIKey:       providerConfig.APIKey,
+			BaseURL:      providerConfig.Endpoint,
+			DefaultModel: providerConfig.DefaultModel,
+			ExtraHeaders: providerConfig.ExtraHeaders,
+			ExtraQuery:   providerConfig.ExtraQuery,
+			ExtraBody:    providerConfig.ExtraBody,
+			HTTPClient:   httpClient,
+
+			Capabilities:   providerConfig.Capabilities,
+			DiscoverModels: providerConfig.DiscoverModels,
+			ModelsTTL:      providerConfig.ModelsTTL,
+		})
+	case providers.ProviderMock:
+		provider, err = mock.NewProvider(&mock.Config{
+			ScenarioFile: providerConfig.Scenario,
+			DefaultModel: providerConfig.DefaultModel,
+		})
 	default:
-		return fmt.Errorf("unsupported provider type: %s", providerType)
+		return nil, fmt.Errorf("unsupported provider type: %s", providerType)
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to initialize %s provider: %w", providerType, err)
-	}
-
-	// Close existing provider if any
-	if c.currentProvider != nil {
-		c.currentProvider.Close()
+		return nil, fmt.Errorf("failed to initialize %s provider: %w", providerType, err)
 	}
-
-	c.currentProvider = provider
-	c.providerType = providerType
-	return nil
+	return provider, nil
 }
 
-// SwitchProvider changes the active provider
+// SwitchProvider changes the active provider, used by requests that name
+// none. The previous provider stays open for requests that name it.
 func (c *Client) SwitchProvider(providerType providers.ProviderType) error {
-	if c.providerType == providerType {
-		return nil // Already using this provider
-	}
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
 
-	return c.initializeProvider(providerType)
+	switched, err := c.currentState().withProvider(providerType)
+	if err != nil {
+		return err
+	}
+	c.state.Store(switched)
+	return nil
 }
 
 // GetCurrentProvider returns the currently active provider
 func (c *Client) GetCurrentProvider() providers.LLMProvider {
-	return c.currentProvider
+	return c.currentState().provider
 }
 
 // GetCurrentProviderType returns the type of current provider
 func (c *Client) GetCurrentProviderType() providers.ProviderType {
-	return c.providerType
+	return c.currentState().providerType
 }
 
 // GetAvailableProviders returns list of available (enabled) providers
 func (c *Client) GetAvailableProviders() []providers.ProviderType {
-	return c.config.GetEnabledProviders()
+	return c.currentState().config.GetEnabledProviders()
 }
 
 // SendMessage sends a message and returns a response
 func (c *Client) SendMessage(ctx context.Context, request *gomini.ChatRequest) (*gomini.ChatResponse, error) {
-	// If request specifies a different provider, switch to it
-	if request.Provider != "" && providers.ProviderType(request.Provider) != c.providerType {
-		if err := c.SwitchProvider(providers.ProviderType(request.Provider)); err != nil {
-			return nil, fmt.Errorf("failed to switch to provider %s: %w", request.Provider, err)
+	st, release := c.holdState()
+	defer release()
+
+	start := time.Now()
+	c.logRequest(ctx, st, "chat", request.Model, request)
+	resp, served, err := c.sendMessage(ctx, st, request)
+	if err == nil {
+		resp, err = c.validateOutput(ctx, st, request, resp)
+	}
+	c.logResponse(ctx, served, "chat", request.Model, resp, err, time.Since(start))
+	var usage *gomini.Usage
+	if resp != nil {
+		usage = resp.Usage
+	}
+	c.publishResult(ctx, served, request.Model, usage, err)
+	return resp, err
+}
+
+// sendMessage sends a message, falling back to other providers on failure.
+// It also returns the state of the provider the request was routed to.
+func (c *Client) sendMessage(ctx context.Context, st *clientState, request *gomini.ChatRequest) (*gomini.ChatResponse, *clientState, error) {
+	request, err := c.applyPersona(st, request)
+	if err != nil {
+		return nil, st, err
+	}
+	pinned := request.Provider != ""
+	request = c.applyModelAlias(st, request)
+	request, err = c.applyRequirements(ctx, st, request)
+	if err != nil {
+		return nil, st, err
+	}
+	// Without a prompt ID each request is assigned to a split variant on its own
+	request = c.applyTrafficSplit(st, request, NewPromptID())
+	request = c.routeLeastLoaded(ctx, st, request)
+	request = c.routeByModel(ctx, st, request)
+
+	// A request naming another provider runs on it; the active provider is unchanged
+	if request.Provider != "" {
+		routed, err := st.withProvider(request.Provider)
+		if err != nil {
+			return nil, st, fmt.Errorf("failed to switch to provider %s: %w", request.Provider, err)
+		}
+		st = routed
+	}
+	request = c.resolveSnapshot(ctx, st, request)
+
+	request = c.withChatTags(st, request)
+	request, _ = c.shapeChatRequest(st, request)
+	request, err = c.applyToolAvailability(st, request)
+	if err != nil {
+		return nil, st, err
+	}
+	if err := c.moderateInput(ctx, st, request.Messages); err != nil {
+		return nil, st, err
+	}
+
+	// Upgrade to a larger-context model if the prompt does not fit
+	request, st, _, err = c.applyContextUpgrade(ctx, st, request)
+	if err != nil {
+		return nil, st, err
+	}
+	if err := c.checkCapabilities(ctx, st, request); err != nil {
+		return nil, st, err
+	}
+	request, clamp, err := c.clampOutputTokens(ctx, st, request)
+	if err != nil {
+		return nil, st, err
+	}
+	if clamp != nil {
+		c.Logger().Warn("clamped max output tokens", slog.String("model", clamp.model),
+			slog.Int("requested", clamp.requested), slog.Int("limit", clamp.limit))
+	}
+
+	cacheKey := c.cacheKey(st, "chat", request)
+	if cached, ok := c.cachedChatResponse(ctx, st, cacheKey); ok {
+		if cached.Stale {
+			c.revalidateChat(ctx, st, cacheKey, request)
 		}
+		return cached, st, nil
 	}
 
-	// Use current provider
-	return c.currentProvider.SendMessage(ctx, request)
+	// Use the request's provider, falling back to others on failure
+	resp, err := c.sendWithTimeout(ctx, st, request)
+	if err == nil {
+		c.cacheSet(ctx, st, cacheKey, resp)
+	}
+	if err == nil {
+		return resp, st, nil
+	}
+	if !c.fallbackEnabled(ctx, st, pinned, err) {
+		return nil, st, c.remediateError(ctx, st, request, err)
+	}
+	err = c.runFallback(ctx, st, request.Model, err, func(fallback *clientState, model string) error {
+		fallbackRequest := *request
+		fallbackRequest.Model = model
+		fallbackRequest.Provider = fallback.providerType
+		resp, err = c.sendWithTimeout(ctx, fallback, &fallbackRequest)
+		if err == nil {
+			// Cache under the provider that answered, never the primary's key
+			c.cacheSet(ctx, fallback, c.cacheKey(fallback, "chat", &fallbackRequest), resp)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, st, err
+	}
+	return resp, st, nil
 }
 
-// SendMessageStream sends a message and returns a stream of events with loop detection and session management
+// SendMessageStream sends a message and returns a stream of events with loop detection and session management.
+// An empty promptID is replaced by a generated one; every event carries the prompt ID in use.
 func (c *Client) SendMessageStream(ctx context.Context, request *gomini.ChatRequest, promptID string) <-chan gomini.StreamEvent {
+	if promptID == "" {
+		promptID = NewPromptID()
+	}
+	// The state is held until the stream ends
+	st, releaseState := c.holdState()
 	resultChan := make(chan gomini.StreamEvent, 10)
+	shaped, prepErr := c.applyPersona(st, request)
+	if prepErr == nil {
+		request = shaped
+	}
+	request = c.withChatTags(st, request)
+	request = c.applyModelAlias(st, request)
+	if prepErr == nil {
+		if shaped, err := c.applyRequirements(ctx, st, request); err != nil {
+			prepErr = err
+		} else {
+			request = shaped
+		}
+	}
+	request = c.applyTrafficSplit(st, request, promptID)
+	request, removedSystem := c.shapeChatRequest(st, request)
+	if prepErr == nil {
+		if available, err := c.applyToolAvailability(st, request); err != nil {
+			prepErr = err
+		} else {
+			request = available
+		}
+	}
 	
+	// The goroutine rewrites request and st, so log the ones the caller sent
+	logged := c.logStream(ctx, st, request, resultChan)
 	go func() {
 		defer close(resultChan)
+		defer releaseState()
+		
+		// Every event carries the prompt ID and updates its tracked state
+		emit := func(event gomini.StreamEvent) {
+			event.PromptID = promptID
+			c.prompts.record(promptID, event)
+			c.events.publish(event)
+			resultChan <- event
+		}
 		
-		// Session management and loop detection setup
-		if c.lastPromptID != promptID {
-			c.loopDetector.Reset(promptID)
-			c.lastPromptID = promptID
-			c.sessionTurnCount = 0 // Reset session turn count for new prompt
+		if prepErr != nil {
+			emit(gomini.NewErrorEvent(st.providerType, request.Model, prepErr, false))
+			return
+		}
+		if err := c.moderateInput(ctx, st, request.Messages); err != nil {
+			emit(gomini.NewErrorEvent(st.providerType, request.Model, err, false))
+			return
 		}
 		
-		c.sessionTurnCount++
+		// Each prompt ID has its own loop detector and turn count
+		loopDetector, turn := c.loopDetectors.startTurn(promptID)
+		c.prompts.update(promptID, func(state *PromptState) {
+			state.Turns++
+			state.Variant = request.Tags[TagSplitVariant]
+		})
 		
 		// Check session turn limits
-		if c.config.MaxSessionTurns > 0 && c.sessionTurnCount > c.config.MaxSessionTurns {
-			event := gomini.NewMaxSessionTurnsEvent(c.providerType, request.Model, 
-				c.sessionTurnCount, c.config.MaxSessionTurns, promptID)
-			resultChan <- event
+		if st.config.MaxSessionTurns > 0 && turn > st.config.MaxSessionTurns {
+			event := gomini.NewMaxSessionTurnsEvent(st.providerType, request.Model, 
+				turn, st.config.MaxSessionTurns, promptID)
+			emit(event)
 			return
 		}
 		
 		// Check for loop at turn start
-		if c.config.LoopDetectionEnabled {
-			if loopDetected := c.loopDetector.TurnStarted(ctx); loopDetected {
-				event := gomini.NewLoopDetectedEvent(c.providerType, request.Model, 
+		if st.config.LoopDetectionEnabled {
+			if loopDetected := loopDetector.TurnStarted(ctx, request.Messages); loopDetected {
+				event := gomini.NewLoopDetectedEvent(st.providerType, request.Model, 
 					gomini.LoopTypeLLMDetected, promptID, "LLM detected conversation loop", 
-					c.sessionTurnCount, 0)
-				resultChan <- event
+					turn, 0)
+				emit(event)
 				return
 			}
 		}
 		
-		// Provider switching
-		if request.Provider != "" && providers.ProviderType(request.Provider) != c.providerType {
-			if err := c.SwitchProvider(providers.ProviderType(request.Provider)); err != nil {
-				resultChan <- gomini.NewErrorEvent(c.providerType, request.Model, 
-					fmt.Errorf("failed to switch provider: %w", err), false)
+		// Provider routing; the active provider is unchanged
+		request = c.routeLeastLoaded(ctx, st, request)
+		request = c.routeByModel(ctx, st, request)
+		if request.Provider != "" {
+			routed, err := st.withProvider(request.Provider)
+			if err != nil {
+				emit(gomini.NewErrorEvent(st.providerType, request.Model, 
+					fmt.Errorf("failed to switch provider: %w", err), false))
 				return
 			}
+			st = routed
+		}
+		request = c.resolveSnapshot(ctx, st, request)
+
+		if removedSystem > 0 {
+			emit(newSystemDedupeEvent(st, request.Model, removedSystem))
+		}
+
+		// Upgrade to a larger-context model if the prompt does not fit
+		upgradedRequest, upgradedState, upgrade, err := c.applyContextUpgrade(ctx, st, request)
+		if err != nil {
+			emit(gomini.NewErrorEvent(st.providerType, request.Model, err, false))
+			return
+		}
+		request, st = upgradedRequest, upgradedState
+		if upgrade != nil {
+			emit(upgrade.event())
+		}
+		if err := c.checkCapabilities(ctx, st, request); err != nil {
+			emit(gomini.NewErrorEvent(st.providerType, request.Model, err, false))
+			return
+		}
+		clampedRequest, clamp, err := c.clampOutputTokens(ctx, st, request)
+		if err != nil {
+			emit(gomini.NewErrorEvent(st.providerType, request.Model, err, false))
+			return
+		}
+		request = clampedRequest
+		if clamp != nil {
+			emit(clamp.event(st))
 		}
 
-		// Stream from current provider with loop detection
-		providerChan := c.currentProvider.SendMessageStream(ctx, request)
-		for event := range providerChan {
-			// Convert provider StreamEvent to gomini StreamEvent
-			gominiEvent := gomini.StreamEvent{
-				Type:      gomini.EventType(event.Type),
-				Provider:  event.Provider,
-				Model:     event.Model,
-				Data:      c.convertEventData(event.Type, event.Data),
-				Error:     event.Error,
-				Timestamp: event.Timestamp,
-				RequestID: event.RequestID,
-				Metadata:  gomini.EventMeta{
-					FinishReason: event.Metadata.FinishReason,
-					Usage:        event.Metadata.Usage,
-				},
+		cacheKey := c.cacheKey(st, "chat", request)
+		if cached, ok := c.cachedChatResponse(ctx, st, cacheKey); ok {
+			if cached.Stale {
+				c.revalidateChat(ctx, st, cacheKey, request)
+			}
+			for _, event := range c.cachedStreamEvents(cached) {
+				emit(event)
 			}
+			return
+		}
+		var recorder *streamRecorder
+		if cacheKey != "" {
+			recorder = newStreamRecorder()
+			defer func() {
+				if resp := recorder.response(st.providerType); resp != nil {
+					c.cacheSet(ctx, st, cacheKey, resp)
+				}
+			}()
+		}
+
+		// Stream from the request's provider with loop detection
+		provider := st.provider
+		streamCtx, cancelStream := context.WithCancel(ctx)
+		defer cancelStream()
+		started := c.startStream(streamCtx, st, request.Model, func(ctx context.Context) <-chan providers.StreamEvent {
+			return provider.SendMessageStream(ctx, request)
+		})
+		providerChan := watchIdle(streamCtx, cancelStream, started, st.config.StreamIdleTimeout, st.providerType, request.Model)
+		// forward handles one provider event and reports whether the stream ends
+		forward := func(event providers.StreamEvent) bool {
+			gominiEvent := c.convertStreamEvent(event)
 			
 			// Check for loops in this event if loop detection is enabled
-			if c.config.LoopDetectionEnabled && c.loopDetector.AddAndCheck(gominiEvent) {
+			if st.config.LoopDetectionEnabled && loopDetector.AddAndCheck(gominiEvent) {
 				// Emit loop detected event
 				loopType := gomini.LoopTypeToolCall
 				description := "Tool call loop detected"
@@ -219,41 +591,167 @@ func (c *Client) SendMessageStream(ctx context.Context, request *gomini.ChatRequ
 					description = "Content repetition loop detected"
 				}
 				
-				loopEvent := gomini.NewLoopDetectedEvent(c.providerType, request.Model, 
-					loopType, promptID, description, c.sessionTurnCount, 0)
-				resultChan <- loopEvent
-				return
+				loopEvent := gomini.NewLoopDetectedEvent(st.providerType, request.Model, 
+					loopType, promptID, description, turn, 0)
+				emit(loopEvent)
+				return true
 			}
 			
+			// Report token usage ahead of the finished event
+			if usageEvent, ok := c.streamUsageEvent(ctx, st, gominiEvent, request.Model); ok {
+				emit(usageEvent)
+			}
+
 			// Forward the event
-			resultChan <- gominiEvent
+			emit(gominiEvent)
+			if recorder != nil {
+				recorder.add(gominiEvent)
+			}
 			
 			// Check for errors
-			if gominiEvent.Type == gomini.EventError {
+			return gominiEvent.Type == gomini.EventError
+		}
+		for {
+			// Hold a read slot while reading and forwarding each event so
+			// concurrent streams are served fairly
+			release, err := c.acquireStreamSlot(ctx)
+			if err != nil {
+				emit(gomini.NewErrorEvent(st.providerType, request.Model, err, false))
+				return
+			}
+			event, ok := <-providerChan
+			done := !ok || forward(event)
+			release()
+			if done {
 				return
 			}
 		}
 	}()
 	
-	return resultChan
+	return logged
+}
+
+// SendMessageStreamFunc streams a message like SendMessageStream but delivers
+// each event to fn. Returning an error from fn cancels the stream and that
+// error is returned; otherwise the error of a failed stream, if any, is returned.
+func (c *Client) SendMessageStreamFunc(ctx context.Context, request *gomini.ChatRequest, promptID string, fn func(gomini.StreamEvent) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := c.SendMessageStream(ctx, request, promptID)
+	var streamErr error
+	for event := range events {
+		if err := fn(event); err != nil {
+			cancel()
+			// Drain so the stream goroutine can exit
+			for range events {
+			}
+			return err
+		}
+		if event.Type == gomini.EventError && streamErr == nil {
+			streamErr = event.Error
+		}
+	}
+	return streamErr
 }
 
 // GenerateJSON generates structured JSON responses
 func (c *Client) GenerateJSON(ctx context.Context, request *gomini.JSONRequest) (*gomini.JSONResponse, error) {
-	// If request specifies a different provider, switch to it
-	if request.Provider != "" && providers.ProviderType(request.Provider) != c.providerType {
-		if err := c.SwitchProvider(providers.ProviderType(request.Provider)); err != nil {
+	st, release := c.holdState()
+	defer release()
+
+	start := time.Now()
+	c.logRequest(ctx, st, "json", request.Model, request)
+	resp, served, err := c.generateJSON(ctx, st, request)
+	c.logResponse(ctx, served, "json", request.Model, resp, err, time.Since(start))
+	var usage *gomini.Usage
+	if resp != nil {
+		usage = resp.Usage
+	}
+	c.publishResult(ctx, served, request.Model, usage, err)
+	return resp, err
+}
+
+// generateJSON generates a JSON response, falling back to other providers on
+// failure. It also returns the state of the provider the request named.
+func (c *Client) generateJSON(ctx context.Context, st *clientState, request *gomini.JSONRequest) (*gomini.JSONResponse, *clientState, error) {
+	pinned := request.Provider != ""
+
+	// A request naming another provider runs on it; the active provider is unchanged
+	if request.Provider != "" {
+		routed, err := st.withProvider(request.Provider)
+		if err != nil {
+			return nil, st, fmt.Errorf("failed to switch to provider %s: %w", request.Provider, err)
+		}
+		st = routed
+	}
+
+	if err := c.moderateInput(ctx, st, request.Messages); err != nil {
+		return nil, st, err
+	}
+
+	// Use the request's provider, falling back to others on failure
+	request = c.shapeJSONRequest(st, c.withJSONTags(st, request))
+	cacheKey := c.cacheKey(st, "json", request)
+	var cached gomini.JSONResponse
+	if storedAt, ok := c.cacheGet(ctx, cacheKey, &cached); ok {
+		cached.Cached = true
+		if cached.Stale = c.isStale(st, storedAt); cached.Stale {
+			c.revalidate(ctx, st, cacheKey, func(ctx context.Context) (interface{}, error) {
+				return c.generateJSONWithTimeout(ctx, st, request)
+			})
+		}
+		return &cached, st, nil
+	}
+	resp, err := c.generateJSONWithTimeout(ctx, st, request)
+	if err == nil {
+		c.cacheSet(ctx, st, cacheKey, resp)
+	}
+	if err == nil || !c.fallbackEnabled(ctx, st, pinned, err) {
+		return resp, st, err
+	}
+	err = c.runFallback(ctx, st, request.Model, err, func(fallback *clientState, model string) error {
+		fallbackRequest := *request
+		fallbackRequest.Model = model
+		fallbackRequest.Provider = fallback.providerType
+		resp, err = c.generateJSONWithTimeout(ctx, fallback, &fallbackRequest)
+		if err == nil {
+			c.cacheSet(ctx, fallback, c.cacheKey(fallback, "json", &fallbackRequest), resp)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, st, err
+	}
+	return resp, st, nil
+}
+
+// GenerateImage generates images with the current provider, or the one the
+// request names
+func (c *Client) GenerateImage(ctx context.Context, request *gomini.ImageRequest) (*gomini.ImageResponse, error) {
+	st, release := c.holdState()
+	defer release()
+
+	if request.Provider != "" {
+		routed, err := st.withProvider(request.Provider)
+		if err != nil {
 			return nil, fmt.Errorf("failed to switch to provider %s: %w", request.Provider, err)
 		}
+		st = routed
 	}
 
-	// Use current provider
-	return c.currentProvider.GenerateJSON(ctx, request)
+	generator, ok := st.provider.(providers.ImageGenerator)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support image generation", st.providerType)
+	}
+	return generator.GenerateImage(ctx, request)
 }
 
 // ListModels lists all available models from current provider
 func (c *Client) ListModels(ctx context.Context) ([]gomini.Model, error) {
-	return c.currentProvider.ListModels(ctx)
+	st, release := c.holdState()
+	defer release()
+	return st.provider.ListModels(ctx)
 }
 
 // GetEnabledProviders returns a list of enabled provider types (alias for GetAvailableProviders)
@@ -263,10 +761,11 @@ func (c *Client) GetEnabledProviders() []providers.ProviderType {
 
 // GetProvider returns the current provider if it matches the requested type
 func (c *Client) GetProvider(providerType providers.ProviderType) (providers.LLMProvider, error) {
-	if c.providerType == providerType {
-		return c.currentProvider, nil
+	st := c.currentState()
+	if st.providerType == providerType {
+		return st.provider, nil
 	}
-	return nil, fmt.Errorf("provider %s is not currently active (current: %s)", providerType, c.providerType)
+	return nil, fmt.Errorf("provider %s is not currently active (current: %s)", providerType, st.providerType)
 }
 
 // convertToGeminiConfig converts gomini.ProviderConfig to gemini.Config
@@ -278,6 +777,13 @@ func (c *Client) convertToGeminiConfig(pc *gomini.ProviderConfig) *gemini.Config
 		UseVertexAI:  pc.UseVertex,
 		DefaultModel: pc.DefaultModel,
 		ExtraHeaders: pc.ExtraHeaders,
+		ExtraQuery:   pc.ExtraQuery,
+		ExtraBody:    pc.ExtraBody,
+		BaseURL:      pc.Endpoint,
+
+		Capabilities:   pc.Capabilities,
+		DiscoverModels: pc.DiscoverModels,
+		ModelsTTL:      pc.ModelsTTL,
 	}
 	
 	// Use Gemini-specific config if available
@@ -301,6 +807,12 @@ func (c *Client) convertToOpenAIConfig(pc *gomini.ProviderConfig) *openai.Config
 		Project:      pc.Project,
 		DefaultModel: pc.DefaultModel,
 		ExtraHeaders: pc.ExtraHeaders,
+		ExtraQuery:   pc.ExtraQuery,
+		ExtraBody:    pc.ExtraBody,
+
+		Capabilities:   pc.Capabilities,
+		DiscoverModels: pc.DiscoverModels,
+		ModelsTTL:      pc.ModelsTTL,
 	}
 	
 	// Use OpenAI-specific config if available
@@ -317,6 +829,26 @@ func (c *Client) convertToOpenAIConfig(pc *gomini.ProviderConfig) *openai.Config
 	return config
 }
 
+// convertStreamEvent converts a provider StreamEvent to a gomini StreamEvent
+func (c *Client) convertStreamEvent(event providers.StreamEvent) gomini.StreamEvent {
+	return gomini.StreamEvent{
+		Type:      gomini.EventType(event.Type),
+		Provider:  event.Provider,
+		Model:     event.Model,
+		Data:      c.convertEventData(event.Type, event.Data),
+		Error:     event.Error,
+		Timestamp: event.Timestamp,
+		RequestID: event.RequestID,
+		Metadata: gomini.EventMeta{
+			FinishReason: event.Metadata.FinishReason,
+			Usage:        event.Metadata.Usage,
+			Safety:       event.Metadata.Safety,
+			SystemFingerprint: event.Metadata.SystemFingerprint,
+			Latency:      event.Metadata.Latency,
+		},
+	}
+}
+
 // convertEventData converts provider event data to gomini event data
 func (c *Client) convertEventData(eventType providers.EventType, data interface{}) interface{} {
 	switch eventType {
@@ -326,6 +858,7 @@ func (c *Client) convertEventData(eventType providers.EventType, data interface{
 				Text:     providerContentEvent.Text,
 				Delta:    providerContentEvent.Delta,
 				Complete: providerContentEvent.Complete,
+				Logprobs: providerContentEvent.Logprobs,
 			}
 		}
 	case providers.EventThought:
@@ -336,15 +869,62 @@ func (c *Client) convertEventData(eventType providers.EventType, data interface{
 				Text:        providerThoughtEvent.Text,
 			}
 		}
+	case providers.EventToolCall:
+		if toolCall, ok := data.(providers.ToolCall); ok {
+			return gomini.ToolCallEvent{
+				CallID:    toolCall.ID,
+				ToolName:  toolCall.Name,
+				Arguments: toolCall.Arguments,
+			}
+		}
+	case providers.EventToolCallDelta:
+		if delta, ok := data.(providers.ToolCallDeltaEvent); ok {
+			return gomini.ToolCallDeltaEvent{
+				Index:          delta.Index,
+				CallID:         delta.CallID,
+				ToolName:       delta.ToolName,
+				ArgumentsDelta: delta.ArgumentsDelta,
+			}
+		}
+	case providers.EventCitation:
+		if citationEvent, ok := data.(providers.CitationEvent); ok {
+			return gomini.CitationEvent{
+				Sources: convertCitations(citationEvent.Sources),
+				Raw:     convertCitations(citationEvent.Raw),
+			}
+		}
+	case providers.EventImage:
+		if providerImageEvent, ok := data.(providers.ImageEvent); ok {
+			return gomini.ImageEvent{
+				Image: providerImageEvent.Image,
+			}
+		}
+	case providers.EventAudio:
+		if providerAudioEvent, ok := data.(providers.AudioChunkEvent); ok {
+			return gomini.AudioChunkEvent{
+				Data:     providerAudioEvent.Data,
+				MIMEType: providerAudioEvent.MIMEType,
+				Sequence: providerAudioEvent.Sequence,
+			}
+		}
 	}
 	// For other event types or if conversion fails, return data as-is
 	return data
 }
 
-// Close closes the client and cleans up resources
-func (c *Client) Close() error {
-	if c.currentProvider != nil {
-		return c.currentProvider.Close()
+// convertCitations converts provider citations, numbering them in order
+func convertCitations(citations []providers.Citation) []gomini.Citation {
+	if citations == nil {
+		return nil
 	}
-	return nil
-}
\ No newline at end of file
+	converted := make([]gomini.Citation, 0, len(citations))
+	for i, citation := range citations {
+		converted = append(converted, gomini.Citation{
+			Title: citation.Title,
+			URI:   citation.URI,
+			Index: i,
+			Spans: citation.Spans,
+		})
+	}
+	return converted
+}
@@ -2,30 +2,43 @@ package core
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"gomini/pkg/gomini"
 	"gomini/pkg/gomini/providers"
+	"gomini/pkg/gomini/providers/chaos"
 )
 
 // MockProvider implements providers.LLMProvider for testing
 type MockProvider struct {
 	providerType providers.ProviderType
 	responses    []gomini.StreamEvent
+	models       []gomini.Model
+	lastRequest  *gomini.ChatRequest
+	jsonData     map[string]interface{}
+	lastJSON     *gomini.JSONRequest
+	chatResponse *gomini.ChatResponse
 	callCount    int
 }
 
 func (m *MockProvider) SendMessage(ctx context.Context, request *gomini.ChatRequest) (*gomini.ChatResponse, error) {
+	m.lastRequest = request
+	if m.chatResponse != nil {
+		return m.chatResponse, nil
+	}
 	return &gomini.ChatResponse{
 		Provider: m.providerType,
 		Model:    request.Model,
 		Choices: []gomini.Choice{
-			gomini.NewAssistantMessage("Mock response"),
+			{Message: gomini.NewAssistantMessage("Mock response")},
 		},
 	}, nil
 }
 
 func (m *MockProvider) SendMessageStream(ctx context.Context, request *gomini.ChatRequest) <-chan providers.StreamEvent {
+	m.lastRequest = request
 	resultChan := make(chan providers.StreamEvent, len(m.responses))
 	
 	go func() {
@@ -55,10 +68,14 @@ func (m *MockProvider) SendMessageStream(ctx context.Context, request *gomini.Ch
 }
 
 func (m *MockProvider) GenerateJSON(ctx context.Context, request *gomini.JSONRequest) (*gomini.JSONResponse, error) {
-	return &gomini.JSONResponse{}, nil
+	m.lastJSON = request
+	return &gomini.JSONResponse{Data: m.jsonData}, nil
 }
 
 func (m *MockProvider) ListModels(ctx context.Context) ([]gomini.Model, error) {
+	if m.models != nil {
+		return m.models, nil
+	}
 	return []gomini.Model{}, nil
 }
 
@@ -80,6 +97,34 @@ func (m *MockProvider) Close() error {
 	return nil
 }
 
+// useProvider makes provider the client's instance of its active provider type
+func useProvider(client *Client, provider providers.LLMProvider) {
+	useProviderAs(client, client.GetCurrentProviderType(), provider)
+}
+
+// useProviderAs makes provider the client's instance of providerType and
+// activates it
+func useProviderAs(client *Client, providerType providers.ProviderType, provider providers.LLMProvider) {
+	st := client.currentState()
+	st.providers.mu.Lock()
+	st.providers.instances[providerType] = provider
+	st.providers.mu.Unlock()
+	client.state.Store(&clientState{config: st.config, providerType: providerType, provider: provider, providers: st.providers})
+}
+
+// rebuildProviders drops the client's provider instances and rebuilds the
+// active one, e.g. after setting providerFactory
+func rebuildProviders(client *Client) error {
+	st := client.currentState()
+	set := client.newProviderSet(st.config)
+	provider, err := set.get(st.providerType)
+	if err != nil {
+		return err
+	}
+	client.state.Store(&clientState{config: st.config, providerType: st.providerType, provider: provider, providers: set})
+	return nil
+}
+
 func TestClient_SessionTurnLimits(t *testing.T) {
 	config := gomini.NewConfig()
 	config.MaxSessionTurns = 3 // Set low limit for testing
@@ -107,7 +152,7 @@ func TestClient_SessionTurnLimits(t *testing.T) {
 			},
 		},
 	}
-	client.currentProvider = mockProvider
+	useProvider(client, mockProvider)
 
 	// First three calls should work
 	for i := 0; i < 3; i++ {
@@ -183,7 +228,7 @@ func TestClient_PromptIDReset(t *testing.T) {
 			},
 		},
 	}
-	client.currentProvider = mockProvider
+	useProvider(client, mockProvider)
 
 	// First call with prompt ID 1
 	streamChan1 := client.SendMessageStream(context.Background(), &gomini.ChatRequest{
@@ -198,12 +243,8 @@ func TestClient_PromptIDReset(t *testing.T) {
 		// Consume all events
 	}
 
-	if client.lastPromptID != "prompt-1" {
-		t.Errorf("Expected last prompt ID to be 'prompt-1', got %s", client.lastPromptID)
-	}
-
-	if client.sessionTurnCount != 1 {
-		t.Errorf("Expected session turn count to be 1, got %d", client.sessionTurnCount)
+	if turns := client.loopDetectors.get("prompt-1").turns; turns != 1 {
+		t.Errorf("Expected session turn count to be 1, got %d", turns)
 	}
 
 	// Second call with same prompt ID should increment turn count
@@ -219,11 +260,11 @@ func TestClient_PromptIDReset(t *testing.T) {
 		// Consume all events
 	}
 
-	if client.sessionTurnCount != 2 {
-		t.Errorf("Expected session turn count to be 2, got %d", client.sessionTurnCount)
+	if turns := client.loopDetectors.get("prompt-1").turns; turns != 2 {
+		t.Errorf("Expected session turn count to be 2, got %d", turns)
 	}
 
-	// Call with new prompt ID should reset turn count
+	// Call with new prompt ID starts its own turn count
 	streamChan3 := client.SendMessageStream(context.Background(), &gomini.ChatRequest{
 		Messages: []gomini.Message{
 			gomini.NewUserMessage("Test message"),
@@ -236,12 +277,13 @@ func TestClient_PromptIDReset(t *testing.T) {
 		// Consume all events
 	}
 
-	if client.lastPromptID != "prompt-2" {
-		t.Errorf("Expected last prompt ID to be 'prompt-2', got %s", client.lastPromptID)
+	if turns := client.loopDetectors.get("prompt-2").turns; turns != 1 {
+		t.Errorf("Expected session turn count for prompt-2 to be 1, got %d", turns)
 	}
 
-	if client.sessionTurnCount != 1 {
-		t.Errorf("Expected session turn count to be reset to 1, got %d", client.sessionTurnCount)
+	// The first prompt keeps its count
+	if turns := client.loopDetectors.get("prompt-1").turns; turns != 2 {
+		t.Errorf("Expected prompt-1 to keep 2 turns, got %d", turns)
 	}
 }
 
@@ -277,7 +319,7 @@ func TestClient_LoopDetectionIntegration(t *testing.T) {
 		})
 	}
 
-	client.currentProvider = mockProvider
+	useProvider(client, mockProvider)
 
 	streamChan := client.SendMessageStream(context.Background(), &gomini.ChatRequest{
 		Messages: []gomini.Message{
@@ -346,7 +388,7 @@ func TestClient_DisabledLoopDetection(t *testing.T) {
 		})
 	}
 
-	client.currentProvider = mockProvider
+	useProvider(client, mockProvider)
 
 	streamChan := client.SendMessageStream(context.Background(), &gomini.ChatRequest{
 		Messages: []gomini.Message{
@@ -368,4 +410,279 @@ func TestClient_DisabledLoopDetection(t *testing.T) {
 	if eventCount != expectedEvents {
 		t.Errorf("Expected %d events, got %d", expectedEvents, eventCount)
 	}
-}
\ No newline at end of file
+}
+func TestClient_ContextUpgrade(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.Router.AutoUpgradeContext = true
+	config.Router.ContextUpgrades = map[string]gomini.ContextUpgradeRule{
+		"small-model": {Provider: providers.ProviderOpenAI, Model: "large-model"},
+	}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	mockProvider := &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		models: []gomini.Model{
+			{ID: "small-model", ContextSize: 10},
+			{ID: "large-model", ContextSize: 100000},
+		},
+		responses: []gomini.StreamEvent{{Type: gomini.EventFinished}},
+	}
+	useProvider(client, mockProvider)
+
+	longPrompt := strings.Repeat("word ", 100)
+	streamChan := client.SendMessageStream(context.Background(), &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage(longPrompt)},
+		Model:    "small-model",
+	}, "test-prompt")
+
+	var switchEvent *gomini.ProviderSwitchEvent
+	for event := range streamChan {
+		if event.Type == gomini.EventProviderSwitch {
+			data := event.Data.(gomini.ProviderSwitchEvent)
+			switchEvent = &data
+		}
+	}
+
+	if switchEvent == nil {
+		t.Fatal("Expected provider switch event for context upgrade")
+	}
+	if switchEvent.FromModel != "small-model" || switchEvent.ToModel != "large-model" || !switchEvent.Automatic {
+		t.Errorf("Unexpected switch event: %+v", switchEvent)
+	}
+	if mockProvider.lastRequest == nil || mockProvider.lastRequest.Model != "large-model" {
+		t.Errorf("Expected request to be sent to large-model, got %+v", mockProvider.lastRequest)
+	}
+
+	// A prompt that fits is sent unchanged
+	for range client.SendMessageStream(context.Background(), &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+		Model:    "small-model",
+	}, "test-prompt") {
+	}
+	if mockProvider.lastRequest.Model != "small-model" {
+		t.Errorf("Expected short prompt to keep small-model, got %s", mockProvider.lastRequest.Model)
+	}
+}
+
+func TestClient_DefaultTags(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.Tags = map[string]string{"team": "search", "env": "prod"}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	mockProvider := &MockProvider{providerType: providers.ProviderOpenAI}
+	useProvider(client, mockProvider)
+
+	request := &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+		Model:    "gpt-4o",
+		Tags:     map[string]string{"env": "staging"},
+	}
+	if _, err := client.SendMessage(context.Background(), request); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	tags := mockProvider.lastRequest.Tags
+	if tags["team"] != "search" || tags["env"] != "staging" {
+		t.Errorf("expected merged tags with request precedence, got %v", tags)
+	}
+	if len(request.Tags) != 1 {
+		t.Errorf("caller's request tags should not be modified, got %v", request.Tags)
+	}
+}
+
+type imageMockProvider struct {
+	MockProvider
+	lastImage *gomini.ImageRequest
+}
+
+func (m *imageMockProvider) GenerateImage(ctx context.Context, request *gomini.ImageRequest) (*gomini.ImageResponse, error) {
+	m.lastImage = request
+	return &gomini.ImageResponse{
+		Model:    request.Model,
+		Provider: m.providerType,
+		Images:   []gomini.GeneratedImage{{URL: "https://images.example/1.png"}},
+	}, nil
+}
+
+func TestClient_GenerateImage(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	useProvider(client, &MockProvider{providerType: providers.ProviderOpenAI})
+	if _, err := client.GenerateImage(context.Background(), &gomini.ImageRequest{Prompt: "a fox", Model: "dall-e-3"}); err == nil {
+		t.Error("Expected error from a provider without image generation")
+	}
+
+	mockProvider := &imageMockProvider{MockProvider: MockProvider{providerType: providers.ProviderOpenAI}}
+	useProvider(client, mockProvider)
+	resp, err := client.GenerateImage(context.Background(), &gomini.ImageRequest{Prompt: "a fox", Model: "dall-e-3"})
+	if err != nil {
+		t.Fatalf("GenerateImage failed: %v", err)
+	}
+	if mockProvider.lastImage == nil || len(resp.Images) != 1 {
+		t.Errorf("Expected the request to reach the provider, got %+v", resp)
+	}
+}
+
+func TestClient_MockScenarioProvider(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderMock] = &gomini.ProviderConfig{
+		Enabled:  true,
+		Scenario: "../gomini/providers/mock/testdata/weather.json",
+	}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	session := client.NewSession("session-1")
+	request := &gomini.ChatRequest{Messages: []gomini.Message{gomini.NewUserMessage("What's the weather?")}}
+	var toolCall *gomini.ToolCallEvent
+	for event := range client.SendSessionStream(context.Background(), session, request) {
+		if data, ok := event.Data.(gomini.ToolCallEvent); ok {
+			toolCall = &data
+		}
+	}
+
+	if toolCall == nil || toolCall.CallID != "call_1" || toolCall.ToolName != "get_weather" {
+		t.Fatalf("Expected scripted tool call event, got %+v", toolCall)
+	}
+	history := session.History()
+	last := history[len(history)-1].(map[string]interface{})
+	if calls, ok := last["tool_calls"].([]gomini.ToolCall); !ok || len(calls) != 1 {
+		t.Errorf("Expected the tool call to be recorded in the session, got %v", last)
+	}
+}
+
+func TestClient_SendMessageStreamFunc(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	useProvider(client, &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		responses: []gomini.StreamEvent{
+			{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "one", Delta: true}},
+			{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "two", Delta: true}},
+			{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "three", Delta: true}},
+			{Type: gomini.EventFinished},
+		},
+	})
+	request := &gomini.ChatRequest{Model: "test-model"}
+
+	// Returning an error stops the stream
+	stop := errors.New("stop")
+	calls := 0
+	err = client.SendMessageStreamFunc(context.Background(), request, "prompt-1", func(event gomini.StreamEvent) error {
+		calls++
+		if calls == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || calls != 2 {
+		t.Errorf("Expected callback error after 2 events, got %v after %d", err, calls)
+	}
+
+	// A full stream returns nil
+	var text strings.Builder
+	err = client.SendMessageStreamFunc(context.Background(), request, "prompt-2", func(event gomini.StreamEvent) error {
+		if content, ok := event.Data.(gomini.ContentEvent); ok {
+			text.WriteString(content.Text)
+		}
+		return nil
+	})
+	if err != nil || text.String() != "onetwothree" {
+		t.Errorf("Expected full stream, got %q (err %v)", text.String(), err)
+	}
+
+	// Error events are returned
+	failure := errors.New("provider failed")
+	useProvider(client, &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		responses:    []gomini.StreamEvent{{Type: gomini.EventError, Error: failure}},
+	})
+	err = client.SendMessageStreamFunc(context.Background(), request, "prompt-3", func(gomini.StreamEvent) error { return nil })
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected stream error, got %v", err)
+	}
+}
+
+func TestClient_ChaosWrapsProviders(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key"}
+	config.Chaos = &chaos.Config{RateLimit: 1}
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		return &MockProvider{providerType: providerType}, nil
+	}
+	if err := rebuildProviders(client); err != nil {
+		t.Fatalf("rebuildProviders failed: %v", err)
+	}
+	if _, ok := providers.Unwrap(client.GetCurrentProvider()).(*chaos.Provider); !ok {
+		t.Fatalf("Expected a chaos provider, got %T", client.GetCurrentProvider())
+	}
+
+	_, err = client.SendMessage(context.Background(), &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("Hello")},
+	})
+	if !errors.Is(err, chaos.ErrRateLimit) {
+		t.Fatalf("Expected the injected rate limit, got %v", err)
+	}
+	if code := gomini.WrapProviderError(err, providers.ProviderOpenAI, "gpt-4o").Code; code != gomini.ErrorRateLimit {
+		t.Errorf("Expected the error to classify as a rate limit, got %s", code)
+	}
+
+	// Wrapping keeps the optional features of the provider
+	if _, ok := client.GetCurrentProvider().(providers.Moderator); ok {
+		t.Error("Expected no moderation over a provider without it")
+	}
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		return &audioMockProvider{MockProvider{providerType: providerType}}, nil
+	}
+	if err := rebuildProviders(client); err != nil {
+		t.Fatalf("rebuildProviders failed: %v", err)
+	}
+	if _, err := client.Speech(context.Background(), &gomini.SpeechRequest{Input: "hi", Model: "tts-1"}); !errors.Is(err, chaos.ErrRateLimit) {
+		t.Errorf("Expected the injected rate limit on speech, got %v", err)
+	}
+}
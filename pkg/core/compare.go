@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// ModelRef names a model on a specific provider
+type ModelRef struct {
+	Provider providers.ProviderType `json:"provider"` // Defaults to the client's current provider
+	Model    string                 `json:"model"`
+}
+
+// String formats the reference as provider/model
+func (r ModelRef) String() string {
+	return string(r.Provider) + "/" + r.Model
+}
+
+// ComparisonResult is one model's outcome in Client.SendToAll
+type ComparisonResult struct {
+	Ref      ModelRef             `json:"ref"`
+	Response *gomini.ChatResponse `json:"response,omitempty"`
+	Err      error                `json:"-"`
+	Latency  time.Duration        `json:"latency"`
+	Usage    *gomini.Usage        `json:"usage,omitempty"`
+	Cost     float64              `json:"cost,omitempty"` // Estimated from the price table or catalog
+}
+
+// SendToAll sends the same request to every model concurrently, for evals and
+// A/B comparisons. Each model runs on its own provider without fallback, so
+// results are attributable. Failures are reported per model in
+// ComparisonResult.Err; the error return is only for unusable input.
+func (c *Client) SendToAll(ctx context.Context, request *gomini.ChatRequest, models []ModelRef) (map[ModelRef]*ComparisonResult, error) {
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no models to compare")
+	}
+
+	st, release := c.holdState()
+	defer release()
+	request, err := c.applyPersona(st, request)
+	if err != nil {
+		return nil, err
+	}
+	request = c.withChatTags(st, request)
+	request, _ = c.shapeChatRequest(st, request)
+	request, err = c.applyToolAvailability(st, request)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[ModelRef]*ComparisonResult, len(models))
+	states := make(map[providers.ProviderType]*clientState)
+	for _, ref := range models {
+		if ref.Provider == "" {
+			ref.Provider = st.providerType
+		}
+		results[ref] = &ComparisonResult{Ref: ref}
+		if _, ok := states[ref.Provider]; ok {
+			continue
+		}
+		refState, err := st.withProvider(ref.Provider)
+		if err != nil {
+			results[ref].Err = err
+		}
+		states[ref.Provider] = refState
+	}
+
+	var wg sync.WaitGroup
+	for ref, result := range results {
+		refState := states[ref.Provider]
+		if refState == nil {
+			if result.Err == nil {
+				result.Err = fmt.Errorf("provider %s is unavailable", ref.Provider)
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(ref ModelRef, result *ComparisonResult) {
+			defer wg.Done()
+			refRequest := *request
+			refRequest.Model = ref.Model
+			refRequest.Provider = ref.Provider
+
+			start := time.Now()
+			resp, err := c.sendWithTimeout(ctx, refState, &refRequest)
+			result.Latency = time.Since(start)
+			if err != nil {
+				result.Err = err
+				return
+			}
+			result.Response = resp
+			result.Usage = resp.Usage
+			if price, ok := c.modelPrice(ctx, st, refState.provider, ref.Model); ok {
+				result.Cost = price.Cost(resp.Usage)
+			}
+		}(ref, result)
+	}
+	wg.Wait()
+	return results, nil
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestClient_SendToAll(t *testing.T) {
+	client := newShutdownClient(t)
+	useProvider(client, &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		chatResponse: &gomini.ChatResponse{Model: "gpt-4o", Usage: &gomini.Usage{InputTokens: 1_000_000}},
+		models:       []gomini.Model{{ID: "gpt-4o", Cost: &providers.ModelCost{InputTokens: 2.5}}},
+	})
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		switch providerType {
+		case providers.ProviderGemini:
+			return &MockProvider{providerType: providerType}, nil
+		case providers.ProviderGroq:
+			return &failingMockProvider{MockProvider: MockProvider{providerType: providerType}, err: errors.New("503 service unavailable")}, nil
+		}
+		return nil, errors.New("not configured")
+	}
+
+	openai := ModelRef{Provider: providers.ProviderOpenAI, Model: "gpt-4o"}
+	gemini := ModelRef{Provider: providers.ProviderGemini, Model: "gemini-2.0-flash"}
+	groq := ModelRef{Provider: providers.ProviderGroq, Model: "llama-3.3-70b"}
+	deepseek := ModelRef{Provider: providers.ProviderDeepSeek, Model: "deepseek-chat"}
+
+	results, err := client.SendToAll(context.Background(), &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("Compare me")},
+	}, []ModelRef{openai, gemini, groq, deepseek})
+	if err != nil {
+		t.Fatalf("SendToAll failed: %v", err)
+	}
+
+	if r := results[openai]; r.Err != nil || r.Cost != 2.5 || r.Usage.InputTokens != 1_000_000 {
+		t.Errorf("Unexpected openai result %+v", r)
+	}
+	if r := results[gemini]; r.Err != nil || r.Response.Model != "gemini-2.0-flash" || r.Latency <= 0 {
+		t.Errorf("Unexpected gemini result %+v", r)
+	}
+	if r := results[groq]; r.Err == nil || r.Response != nil {
+		t.Errorf("Expected the groq request to fail, got %+v", r)
+	}
+	if r := results[deepseek]; r.Err == nil {
+		t.Errorf("Expected the unconfigured provider to fail, got %+v", r)
+	}
+	if client.GetCurrentProviderType() != providers.ProviderOpenAI {
+		t.Errorf("Expected the active provider to be unchanged, got %s", client.GetCurrentProviderType())
+	}
+
+	if _, err := client.SendToAll(context.Background(), &gomini.ChatRequest{}, nil); err == nil {
+		t.Error("Expected an error without models")
+	}
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// contextUpgrade describes an automatic switch to a larger-context model
+type contextUpgrade struct {
+	fromProvider    providers.ProviderType
+	toProvider      providers.ProviderType
+	fromModel       string
+	toModel         string
+	estimatedTokens int
+	contextSize     int
+}
+
+// event converts the upgrade into a provider switch event
+func (u *contextUpgrade) event() gomini.StreamEvent {
+	reason := fmt.Sprintf("prompt of ~%d tokens exceeds %s context window of %d tokens",
+		u.estimatedTokens, u.fromModel, u.contextSize)
+	event := gomini.NewProviderSwitchEvent(u.fromProvider, u.toProvider, reason, true)
+	event.Model = u.toModel
+	if data, ok := event.Data.(gomini.ProviderSwitchEvent); ok {
+		data.FromModel = u.fromModel
+		data.ToModel = u.toModel
+		event.Data = data
+	}
+	return event
+}
+
+// applyContextUpgrade switches the request to a larger-context model when the
+// estimated prompt size does not fit the requested model and the router has an
+// upgrade rule for it. It returns the (possibly rewritten) request, the state
+// of the provider it now runs on and a description of the upgrade, or nil if
+// none was needed.
+func (c *Client) applyContextUpgrade(ctx context.Context, st *clientState, request *gomini.ChatRequest) (*gomini.ChatRequest, *clientState, *contextUpgrade, error) {
+	router := st.config.Router
+	if router == nil || !router.AutoUpgradeContext || len(router.ContextUpgrades) == 0 {
+		return request, st, nil, nil
+	}
+
+	rule, exists := router.ContextUpgrades[request.Model]
+	if !exists {
+		return request, st, nil, nil
+	}
+
+	contextSize := c.modelContextSize(ctx, st.provider, request.Model)
+	if contextSize <= 0 {
+		return request, st, nil, nil
+	}
+
+	estimated := gomini.EstimateTokens(request.Messages)
+	if estimated <= contextSize {
+		return request, st, nil, nil
+	}
+
+	upgrade := &contextUpgrade{
+		fromProvider:    st.providerType,
+		toProvider:      rule.Provider,
+		fromModel:       request.Model,
+		toModel:         rule.Model,
+		estimatedTokens: estimated,
+		contextSize:     contextSize,
+	}
+	if upgrade.toProvider == "" {
+		upgrade.toProvider = st.providerType
+	}
+
+	upgradedState, err := st.withProvider(upgrade.toProvider)
+	if err != nil {
+		return nil, st, nil, fmt.Errorf("failed to switch to %s for context upgrade: %w", upgrade.toProvider, err)
+	}
+
+	upgraded := *request
+	upgraded.Model = rule.Model
+	upgraded.Provider = upgrade.toProvider
+	return &upgraded, upgradedState, upgrade, nil
+}
+
+// modelContextSize returns the context window of a model as reported by the provider (0 if unknown)
+func (c *Client) modelContextSize(ctx context.Context, provider providers.LLMProvider, model string) int {
+	if provider == nil {
+		return 0
+	}
+
+	models, err := provider.ListModels(ctx)
+	if err != nil {
+		return 0
+	}
+
+	for _, m := range models {
+		if m.ID == model {
+			return m.ContextSize
+		}
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SessionCost is one session's line in a CostReport
+type SessionCost struct {
+	ID        string    `json:"id"`
+	Turns     int       `json:"turns"`
+	Spent     float64   `json:"spent"`            // Estimated USD
+	Budget    float64   `json:"budget,omitempty"` // Ceiling, 0 when the session has no budget
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CostReport summarizes what stored sessions have spent
+type CostReport struct {
+	Sessions []SessionCost `json:"sessions"` // Most expensive first
+	Total    float64       `json:"total"`
+	Turns    int           `json:"turns"`
+}
+
+// SessionCostReport builds a CostReport from every session in store
+func SessionCostReport(ctx context.Context, store SessionStore) (*CostReport, error) {
+	ids, err := store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	report := &CostReport{Sessions: make([]SessionCost, 0, len(ids))}
+	for _, id := range ids {
+		session, err := store.Load(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session %s: %w", id, err)
+		}
+		data := session.Snapshot()
+		cost := SessionCost{
+			ID:        data.ID,
+			Turns:     data.TurnCount,
+			Spent:     data.Spent,
+			UpdatedAt: data.UpdatedAt,
+		}
+		if data.Budget != nil {
+			cost.Budget = data.Budget.Ceiling
+		}
+		report.Sessions = append(report.Sessions, cost)
+		report.Total += cost.Spent
+		report.Turns += cost.Turns
+	}
+
+	sort.SliceStable(report.Sessions, func(i, j int) bool {
+		if report.Sessions[i].Spent != report.Sessions[j].Spent {
+			return report.Sessions[i].Spent > report.Sessions[j].Spent
+		}
+		return report.Sessions[i].ID < report.Sessions[j].ID
+	})
+	return report, nil
+}
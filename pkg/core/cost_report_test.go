@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestSessionCostReport(t *testing.T) {
+	store := NewMemorySessionStore()
+	ctx := context.Background()
+
+	cheap := NewSession("cheap", 0)
+	cheap.BeginTurn()
+	cheap.AddCost(0.5)
+	expensive := NewSession("expensive", 0)
+	expensive.SetBudget(SessionBudget{Ceiling: 10})
+	expensive.BeginTurn()
+	expensive.BeginTurn()
+	expensive.AddCost(2.25)
+	for _, session := range []*Session{cheap, expensive, NewSession("idle", 0)} {
+		if err := store.Save(ctx, session); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	report, err := SessionCostReport(ctx, store)
+	if err != nil {
+		t.Fatalf("SessionCostReport() error = %v", err)
+	}
+	if report.Total != 2.75 || report.Turns != 3 {
+		t.Errorf("Expected total 2.75 over 3 turns, got %v over %d", report.Total, report.Turns)
+	}
+
+	want := []SessionCost{
+		{ID: "expensive", Turns: 2, Spent: 2.25, Budget: 10},
+		{ID: "cheap", Turns: 1, Spent: 0.5},
+		{ID: "idle"},
+	}
+	if len(report.Sessions) != len(want) {
+		t.Fatalf("Expected %d sessions, got %d", len(want), len(report.Sessions))
+	}
+	for i, w := range want {
+		got := report.Sessions[i]
+		got.UpdatedAt = w.UpdatedAt
+		if got != w {
+			t.Errorf("Session %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+}
+
+func TestClient_SendSessionStreamRecordsCostWithoutBudget(t *testing.T) {
+	client := newShutdownClient(t)
+	useProvider(client, &MockProvider{
+		providerType: client.GetCurrentProviderType(),
+		models:       []gomini.Model{{ID: "model", Cost: &providers.ModelCost{InputTokens: 1.0}}},
+		responses: []gomini.StreamEvent{
+			{Type: gomini.EventFinished, Metadata: gomini.EventMeta{Usage: &gomini.Usage{InputTokens: 500000}}},
+		},
+	})
+
+	session := client.NewSession("no-budget")
+	for event := range client.SendSessionStream(context.Background(), session, &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("Hi")},
+		Model:    "model",
+	}) {
+		if event.Type == gomini.EventBudget {
+			t.Errorf("Unexpected budget event without a budget: %+v", event)
+		}
+	}
+	if session.Spent() != 0.5 {
+		t.Errorf("Expected spent 0.5, got %v", session.Spent())
+	}
+}
@@ -0,0 +1,322 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gomini/pkg/gomini"
+)
+
+// DatasetFormat selects the tuning file layout written by ExportDataset
+type DatasetFormat string
+
+const (
+	// DatasetOpenAI is OpenAI chat fine-tuning JSONL: {"messages": [...]}
+	DatasetOpenAI DatasetFormat = "openai"
+
+	// DatasetGemini is Gemini supervised tuning JSONL: {"systemInstruction": ..., "contents": [...]}
+	DatasetGemini DatasetFormat = "gemini"
+)
+
+// Redactor removes personal data from text before it is exported
+type Redactor interface {
+	Redact(text string) string
+}
+
+// PIIRedactor replaces matches of its patterns with a placeholder naming the kind of data
+type PIIRedactor struct {
+	patterns []piiPattern
+}
+
+type piiPattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// NewPIIRedactor creates a redactor for email addresses, phone numbers,
+// payment card numbers, US social security numbers and IP addresses
+func NewPIIRedactor() *PIIRedactor {
+	return &PIIRedactor{patterns: []piiPattern{
+		{"EMAIL", regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+		{"CARD", regexp.MustCompile(`\b(?:\d[ -]?){13,19}\b`)},
+		{"SSN", regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+		{"PHONE", regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?\(?\d{2,4}\)?[ .-]\d{3,4}[ .-]\d{3,4}\b`)},
+		{"IP", regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)},
+	}}
+}
+
+// AddPattern redacts matches of re as [kind]
+func (r *PIIRedactor) AddPattern(kind string, re *regexp.Regexp) {
+	r.patterns = append(r.patterns, piiPattern{kind: kind, re: re})
+}
+
+// Redact implements Redactor
+func (r *PIIRedactor) Redact(text string) string {
+	for _, pattern := range r.patterns {
+		text = pattern.re.ReplaceAllString(text, "["+pattern.kind+"]")
+	}
+	return text
+}
+
+// DatasetOptions configures ExportDataset
+type DatasetOptions struct {
+	Format DatasetFormat
+
+	// Redactor is applied to all exported text; nil uses NewPIIRedactor
+	Redactor Redactor
+
+	// SkipTools drops tool calls and tool results, keeping plain text turns
+	SkipTools bool
+}
+
+// datasetMessage is a session message flattened to text and tool calls
+type datasetMessage struct {
+	role       string
+	text       string
+	toolCalls  []gomini.ToolCall
+	toolCallID string
+	toolName   string
+}
+
+// ExportDataset writes one tuning example per session to w and returns how
+// many were written. Sessions without an assistant turn teach nothing and are
+// skipped, as are image, audio and document parts, which tuning files do not carry.
+func ExportDataset(w io.Writer, sessions []*SessionData, opts DatasetOptions) (int, error) {
+	if opts.Format != DatasetOpenAI && opts.Format != DatasetGemini {
+		return 0, fmt.Errorf("unknown dataset format %q", opts.Format)
+	}
+	redactor := opts.Redactor
+	if redactor == nil {
+		redactor = NewPIIRedactor()
+	}
+
+	encoder := json.NewEncoder(w)
+	written := 0
+	for _, session := range sessions {
+		messages := flattenDatasetMessages(session.Messages, redactor, opts.SkipTools)
+		if !hasAssistantTurn(messages) {
+			continue
+		}
+
+		var example interface{}
+		if opts.Format == DatasetOpenAI {
+			example = openAIExample(messages)
+		} else {
+			example = geminiExample(messages)
+		}
+		if err := encoder.Encode(example); err != nil {
+			return written, fmt.Errorf("failed to write session %s: %w", session.ID, err)
+		}
+		written++
+	}
+	return written, nil
+}
+
+// ExportSessionStore exports every session in store with ExportDataset
+func ExportSessionStore(ctx context.Context, store SessionStore, w io.Writer, opts DatasetOptions) (int, error) {
+	ids, err := store.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	sessions := make([]*SessionData, 0, len(ids))
+	for _, id := range ids {
+		session, err := store.Load(ctx, id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load session %s: %w", id, err)
+		}
+		sessions = append(sessions, session.Snapshot())
+	}
+	return ExportDataset(w, sessions, opts)
+}
+
+func flattenDatasetMessages(messages []gomini.Message, redactor Redactor, skipTools bool) []datasetMessage {
+	var flattened []datasetMessage
+	for _, message := range messages {
+		msgMap, ok := message.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		role, _ := msgMap["role"].(string)
+		msg := datasetMessage{role: role, text: redactor.Redact(contentText(msgMap["content"]))}
+
+		if role == "tool" {
+			if skipTools {
+				continue
+			}
+			msg.toolCallID, _ = msgMap["tool_call_id"].(string)
+			msg.toolName, _ = msgMap["name"].(string)
+		}
+		if role == "assistant" && !skipTools {
+			msg.toolCalls = redactToolCalls(messageToolCalls(msgMap), redactor)
+		}
+		if msg.text == "" && len(msg.toolCalls) == 0 && role != "tool" {
+			continue
+		}
+		flattened = append(flattened, msg)
+	}
+	return flattened
+}
+
+func hasAssistantTurn(messages []datasetMessage) bool {
+	for _, msg := range messages {
+		if msg.role == "assistant" {
+			return true
+		}
+	}
+	return false
+}
+
+// contentText flattens string or multi-part content to its text parts
+func contentText(content interface{}) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []interface{}:
+		var texts []string
+		for _, item := range c {
+			part, ok := item.(map[string]interface{})
+			if !ok || part["type"] != "text" {
+				continue
+			}
+			if data, ok := part["data"].(map[string]interface{}); ok {
+				if text, ok := data["text"].(string); ok {
+					texts = append(texts, text)
+				}
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
+
+// messageToolCalls reads tool_calls whether they are live []gomini.ToolCall
+// values or were decoded from a stored session
+func messageToolCalls(msgMap map[string]interface{}) []gomini.ToolCall {
+	switch calls := msgMap["tool_calls"].(type) {
+	case []gomini.ToolCall:
+		return calls
+	case []interface{}:
+		data, err := json.Marshal(calls)
+		if err != nil {
+			return nil
+		}
+		var toolCalls []gomini.ToolCall
+		if json.Unmarshal(data, &toolCalls) != nil {
+			return nil
+		}
+		return toolCalls
+	}
+	return nil
+}
+
+// redactToolCalls redacts string arguments, returning copies
+func redactToolCalls(calls []gomini.ToolCall, redactor Redactor) []gomini.ToolCall {
+	redacted := make([]gomini.ToolCall, len(calls))
+	for i, call := range calls {
+		redacted[i] = call
+		redacted[i].Arguments = redactValue(call.Arguments, redactor).(map[string]interface{})
+	}
+	return redacted
+}
+
+func redactValue(value interface{}, redactor Redactor) interface{} {
+	switch v := value.(type) {
+	case string:
+		return redactor.Redact(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = redactValue(item, redactor)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = redactValue(item, redactor)
+		}
+		return out
+	}
+	return value
+}
+
+func openAIExample(messages []datasetMessage) map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		entry := map[string]interface{}{"role": msg.role, "content": msg.text}
+		switch {
+		case msg.role == "tool":
+			entry["tool_call_id"] = msg.toolCallID
+		case len(msg.toolCalls) > 0:
+			calls := make([]map[string]interface{}, len(msg.toolCalls))
+			for i, call := range msg.toolCalls {
+				arguments, _ := json.Marshal(call.Arguments)
+				calls[i] = map[string]interface{}{
+					"id":   call.ID,
+					"type": "function",
+					"function": map[string]interface{}{
+						"name":      call.Name,
+						"arguments": string(arguments),
+					},
+				}
+			}
+			entry["tool_calls"] = calls
+		}
+		out = append(out, entry)
+	}
+	return map[string]interface{}{"messages": out}
+}
+
+func geminiExample(messages []datasetMessage) map[string]interface{} {
+	example := map[string]interface{}{}
+	var system []string
+	var contents []map[string]interface{}
+
+	for _, msg := range messages {
+		var role string
+		var parts []map[string]interface{}
+		switch msg.role {
+		case "system":
+			system = append(system, msg.text)
+			continue
+		case "assistant":
+			role = "model"
+			if msg.text != "" {
+				parts = append(parts, map[string]interface{}{"text": msg.text})
+			}
+			for _, call := range msg.toolCalls {
+				parts = append(parts, map[string]interface{}{
+					"functionCall": map[string]interface{}{"name": call.Name, "args": call.Arguments},
+				})
+			}
+		case "tool":
+			role = "user"
+			var response interface{}
+			if json.Unmarshal([]byte(msg.text), &response) != nil {
+				response = msg.text
+			}
+			if _, ok := response.(map[string]interface{}); !ok {
+				response = map[string]interface{}{"result": response}
+			}
+			parts = append(parts, map[string]interface{}{
+				"functionResponse": map[string]interface{}{"name": msg.toolName, "response": response},
+			})
+		default:
+			role = "user"
+			parts = append(parts, map[string]interface{}{"text": msg.text})
+		}
+		contents = append(contents, map[string]interface{}{"role": role, "parts": parts})
+	}
+
+	if len(system) > 0 {
+		example["systemInstruction"] = map[string]interface{}{
+			"role":  "system",
+			"parts": []map[string]interface{}{{"text": strings.Join(system, "\n")}},
+		}
+	}
+	example["contents"] = contents
+	return example
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini"
+)
+
+func datasetSession(t *testing.T) *SessionData {
+	t.Helper()
+	session := NewSession("support-1", 0)
+	session.AddMessage(gomini.NewSystemMessage("You are a support agent."))
+	session.AddUserMessage("Hi, I'm jane@example.com, call me on +1 555-123-4567")
+	session.AddMessage(gomini.NewAssistantToolCallMessage("", []gomini.ToolCall{
+		{ID: "call_1", Name: "lookup_customer", Arguments: map[string]interface{}{"email": "jane@example.com"}},
+	}))
+	session.AddMessage(gomini.NewToolResultMessage("call_1", "lookup_customer", `{"plan":"pro"}`))
+	session.AddMessage(gomini.NewAssistantMessage("You are on the pro plan."))
+	return session.Snapshot()
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var examples []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var example map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &example); err != nil {
+			t.Fatalf("Invalid JSONL line %q: %v", line, err)
+		}
+		examples = append(examples, example)
+	}
+	return examples
+}
+
+func TestExportDataset_OpenAI(t *testing.T) {
+	userOnly := &SessionData{ID: "unanswered", Messages: []gomini.Message{gomini.NewUserMessage("Hello?")}}
+
+	var buf bytes.Buffer
+	written, err := ExportDataset(&buf, []*SessionData{datasetSession(t), userOnly}, DatasetOptions{Format: DatasetOpenAI})
+	if err != nil || written != 1 {
+		t.Fatalf("Expected 1 example, got %d, %v", written, err)
+	}
+	if strings.Contains(buf.String(), "jane@example.com") || strings.Contains(buf.String(), "555-123-4567") {
+		t.Errorf("Expected PII to be redacted: %s", buf.String())
+	}
+
+	messages := decodeLines(t, &buf)[0]["messages"].([]interface{})
+	if len(messages) != 5 {
+		t.Fatalf("Expected 5 messages, got %d", len(messages))
+	}
+	call := messages[2].(map[string]interface{})["tool_calls"].([]interface{})[0].(map[string]interface{})
+	function := call["function"].(map[string]interface{})
+	if function["name"] != "lookup_customer" || function["arguments"] != `{"email":"[EMAIL]"}` {
+		t.Errorf("Unexpected tool call %v", call)
+	}
+	if messages[3].(map[string]interface{})["tool_call_id"] != "call_1" {
+		t.Errorf("Expected the tool result to reference its call, got %v", messages[3])
+	}
+}
+
+func TestExportDataset_Gemini(t *testing.T) {
+	store := NewMemorySessionStore()
+	if err := store.Save(context.Background(), RestoreSession(datasetSession(t))); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := ExportSessionStore(context.Background(), store, &buf, DatasetOptions{Format: DatasetGemini}); err != nil {
+		t.Fatalf("ExportSessionStore failed: %v", err)
+	}
+	example := decodeLines(t, &buf)[0]
+
+	system := example["systemInstruction"].(map[string]interface{})["parts"].([]interface{})[0].(map[string]interface{})
+	if system["text"] != "You are a support agent." {
+		t.Errorf("Unexpected system instruction %v", system)
+	}
+	contents := example["contents"].([]interface{})
+	var roles []string
+	for _, content := range contents {
+		roles = append(roles, content.(map[string]interface{})["role"].(string))
+	}
+	if got := strings.Join(roles, ","); got != "user,model,user,model" {
+		t.Errorf("Unexpected roles %s", got)
+	}
+	response := contents[2].(map[string]interface{})["parts"].([]interface{})[0].(map[string]interface{})["functionResponse"].(map[string]interface{})
+	if response["name"] != "lookup_customer" || response["response"].(map[string]interface{})["plan"] != "pro" {
+		t.Errorf("Unexpected function response %v", response)
+	}
+
+	// Without tools only the text turns remain
+	buf.Reset()
+	ExportDataset(&buf, []*SessionData{datasetSession(t)}, DatasetOptions{Format: DatasetGemini, SkipTools: true})
+	if got := len(decodeLines(t, &buf)[0]["contents"].([]interface{})); got != 2 {
+		t.Errorf("Expected 2 contents without tools, got %d", got)
+	}
+}
+
+func TestPIIRedactor(t *testing.T) {
+	redactor := NewPIIRedactor()
+	tests := []struct {
+		in, want string
+	}{
+		{"mail bob.smith+x@corp.io now", "mail [EMAIL] now"},
+		{"card 4111 1111 1111 1111", "card [CARD]"},
+		{"ssn 123-45-6789", "ssn [SSN]"},
+		{"server 10.0.0.12 is down", "server [IP] is down"},
+		{"order 42 shipped", "order 42 shipped"},
+	}
+	for _, tt := range tests {
+		if got := redactor.Redact(tt.in); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ExportDataset(&bytes.Buffer{}, nil, DatasetOptions{Format: "csv"}); err == nil {
+		t.Error("Expected unknown formats to be rejected")
+	}
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// Doctor checks performed against each provider/model pair
+const (
+	CheckChat   = "chat"
+	CheckStream = "stream"
+	CheckJSON   = "json"
+)
+
+// DoctorChecks lists the live checks in the order they run
+var DoctorChecks = []string{CheckChat, CheckStream, CheckJSON}
+
+// defaultProbeModels are probed when a provider has no configured models
+var defaultProbeModels = map[providers.ProviderType]string{
+	providers.ProviderOpenAI: "gpt-4o-mini",
+	providers.ProviderGemini: "gemini-1.5-flash",
+}
+
+// DoctorOptions configures Client.Doctor
+type DoctorOptions struct {
+	Models   map[providers.ProviderType][]string // Models to probe; defaults to the configured models
+	Timeout  time.Duration                       // Per-check timeout, default 30s
+	SkipLive bool                                // Only run configuration diagnostics
+}
+
+// ProbeResult is the outcome of one live check
+type ProbeResult struct {
+	Provider providers.ProviderType `json:"provider"`
+	Model    string                 `json:"model"`
+	Check    string                 `json:"check"`
+	OK       bool                   `json:"ok"`
+	Latency  time.Duration          `json:"latency"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// DoctorReport combines configuration diagnostics with live probe results
+type DoctorReport struct {
+	Diagnostics []gomini.Diagnostic `json:"diagnostics"`
+	Results     []ProbeResult       `json:"results,omitempty"`
+}
+
+// Healthy reports whether there are no configuration errors and every probe passed
+func (r *DoctorReport) Healthy() bool {
+	if gomini.HasErrors(r.Diagnostics) {
+		return false
+	}
+	for _, result := range r.Results {
+		if !result.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Doctor diagnoses the configuration and, unless SkipLive is set, sends a tiny
+// chat, streaming and JSON request to every enabled provider/model pair
+func (c *Client) Doctor(ctx context.Context, opts DoctorOptions) *DoctorReport {
+	st, release := c.holdState()
+	defer release()
+	report := &DoctorReport{Diagnostics: st.config.Diagnose()}
+	if opts.SkipLive {
+		return report
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	providerTypes := st.config.GetEnabledProviders()
+	sort.Slice(providerTypes, func(i, j int) bool { return providerTypes[i] < providerTypes[j] })
+
+	for _, providerType := range providerTypes {
+		models := probeModels(st.config, providerType, opts.Models)
+
+		provider, err := st.providers.get(providerType)
+		if err != nil {
+			for _, model := range models {
+				for _, check := range DoctorChecks {
+					report.Results = append(report.Results, ProbeResult{
+						Provider: providerType, Model: model, Check: check, Error: err.Error(),
+					})
+				}
+			}
+			continue
+		}
+
+		for _, model := range models {
+			for _, check := range DoctorChecks {
+				report.Results = append(report.Results, runProbe(ctx, provider, providerType, model, check, opts.Timeout))
+			}
+		}
+	}
+
+	return report
+}
+
+// probeModels picks the models to probe for a provider
+func probeModels(config *gomini.Config, providerType providers.ProviderType, overrides map[providers.ProviderType][]string) []string {
+	if models := overrides[providerType]; len(models) > 0 {
+		return models
+	}
+	if config, err := config.GetProviderConfig(providerType); err == nil {
+		if len(config.Models) > 0 {
+			return config.Models
+		}
+		if config.DefaultModel != "" {
+			return []string{config.DefaultModel}
+		}
+	}
+	if model, ok := defaultProbeModels[providerType]; ok {
+		return []string{model}
+	}
+	return nil
+}
+
+// runProbe executes a single check with its own timeout
+func runProbe(ctx context.Context, provider providers.LLMProvider, providerType providers.ProviderType, model, check string, timeout time.Duration) ProbeResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	messages := []gomini.Message{gomini.NewUserMessage("Reply with the single word OK.")}
+	result := ProbeResult{Provider: providerType, Model: model, Check: check}
+	start := time.Now()
+
+	var err error
+	switch check {
+	case CheckChat:
+		var resp *gomini.ChatResponse
+		resp, err = provider.SendMessage(ctx, &gomini.ChatRequest{Messages: messages, Model: model})
+		if err == nil && len(resp.Choices) == 0 {
+			err = fmt.Errorf("response has no choices")
+		}
+	case CheckStream:
+		err = probeStream(provider.SendMessageStream(ctx, &gomini.ChatRequest{Messages: messages, Model: model}))
+	case CheckJSON:
+		var resp *gomini.JSONResponse
+		resp, err = provider.GenerateJSON(ctx, &gomini.JSONRequest{
+			Messages: []gomini.Message{gomini.NewUserMessage(`Respond with {"ok": true}.`)},
+			Model:    model,
+			Schema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"ok": map[string]interface{}{"type": "boolean"}},
+				"required":   []interface{}{"ok"},
+			},
+		})
+		if err == nil {
+			if _, ok := resp.Data["ok"]; !ok {
+				err = fmt.Errorf("response is missing the \"ok\" field")
+			}
+		}
+	default:
+		err = fmt.Errorf("unknown check %q", check)
+	}
+
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.OK = true
+	}
+	return result
+}
+
+// probeStream drains a stream and fails unless content arrives without errors
+func probeStream(stream <-chan providers.StreamEvent) error {
+	var gotContent bool
+	var streamErr error
+	for event := range stream {
+		switch event.Type {
+		case providers.EventContent:
+			gotContent = true
+		case providers.EventError:
+			if streamErr == nil {
+				streamErr = event.Error
+				if streamErr == nil {
+					streamErr = fmt.Errorf("stream reported an error")
+				}
+			}
+		}
+	}
+	if streamErr != nil {
+		return streamErr
+	}
+	if !gotContent {
+		return fmt.Errorf("stream produced no content")
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestClient_Doctor(t *testing.T) {
+	client, mockProvider := newGenerateTestClient(t, map[string]interface{}{"ok": true})
+	mockProvider.responses = []gomini.StreamEvent{
+		gomini.NewContentEvent(providers.ProviderOpenAI, "gpt-4o-mini", "OK", true),
+	}
+	client.currentState().config.Providers[providers.ProviderOpenAI].DefaultModel = "gpt-4o-mini"
+
+	report := client.Doctor(context.Background(), DoctorOptions{Timeout: time.Second})
+	if len(report.Results) != len(DoctorChecks) {
+		t.Fatalf("expected %d results, got %+v", len(DoctorChecks), report.Results)
+	}
+	for _, result := range report.Results {
+		if !result.OK || result.Model != "gpt-4o-mini" {
+			t.Errorf("expected %s check to pass for gpt-4o-mini, got %+v", result.Check, result)
+		}
+	}
+
+	// A JSON response without the expected field fails only that check
+	mockProvider.jsonData = map[string]interface{}{}
+	report = client.Doctor(context.Background(), DoctorOptions{
+		Models: map[providers.ProviderType][]string{providers.ProviderOpenAI: {"gpt-4o"}},
+	})
+	if report.Healthy() {
+		t.Error("expected unhealthy report")
+	}
+	for _, result := range report.Results {
+		if result.Model != "gpt-4o" {
+			t.Errorf("expected model override, got %s", result.Model)
+		}
+		if result.OK != (result.Check != CheckJSON) {
+			t.Errorf("unexpected result for %s: %+v", result.Check, result)
+		}
+	}
+}
+
+func TestClient_DoctorSkipLive(t *testing.T) {
+	client, _ := newGenerateTestClient(t, nil)
+	report := client.Doctor(context.Background(), DoctorOptions{SkipLive: true})
+	if len(report.Results) != 0 {
+		t.Errorf("expected no live results, got %+v", report.Results)
+	}
+}
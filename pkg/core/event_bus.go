@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"gomini/pkg/gomini"
+)
+
+// SubscriberBufferSize is the number of events buffered per subscriber.
+// Events for a subscriber whose buffer is full are dropped rather than
+// slowing down requests.
+const SubscriberBufferSize = 256
+
+// subscriber is one Subscribe call
+type subscriber struct {
+	events chan gomini.StreamEvent
+	types  map[gomini.EventType]bool // Empty matches every type
+}
+
+// eventBus fans events from all requests out to subscribers
+type eventBus struct {
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+}
+
+// subscribe registers a subscriber for types and returns its cancel function
+func (b *eventBus) subscribe(types []gomini.EventType) (*subscriber, func()) {
+	sub := &subscriber{
+		events: make(chan gomini.StreamEvent, SubscriberBufferSize),
+		types:  make(map[gomini.EventType]bool, len(types)),
+	}
+	for _, eventType := range types {
+		sub.types[eventType] = true
+	}
+
+	b.mu.Lock()
+	if b.subscribers == nil {
+		b.subscribers = make(map[*subscriber]struct{})
+	}
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+	return sub, cancel
+}
+
+// publish delivers event to every matching subscriber without blocking
+func (b *eventBus) publish(event gomini.StreamEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subscribers {
+		if len(sub.types) > 0 && !sub.types[event.Type] {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+// closeAll cancels every subscription
+func (b *eventBus) closeAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Subscribe returns a channel receiving the events of all requests made
+// through the client, limited to types when any are given: stream events,
+// usage of non-streaming calls, fallback provider switches, loop detections,
+// budget events, benched API keys and tool confirmations. Call cancel to unsubscribe and close the channel.
+// Events are dropped for subscribers that fall SubscriberBufferSize behind.
+func (c *Client) Subscribe(types ...gomini.EventType) (<-chan gomini.StreamEvent, func()) {
+	sub, cancel := c.events.subscribe(types)
+	return sub.events, cancel
+}
+
+// publishResult publishes the outcome of a non-streaming call: its usage and
+// cost on success, or an error event
+func (c *Client) publishResult(ctx context.Context, st *clientState, model string, usage *gomini.Usage, err error) {
+	if err != nil {
+		c.events.publish(gomini.NewErrorEvent(st.providerType, model, err, false))
+		return
+	}
+	if usage != nil {
+		c.events.publish(gomini.NewUsageEvent(st.providerType, model, usage, c.usageCost(ctx, st, model, usage)))
+	}
+}
+
+// publishStream forwards events to subscribers as they pass through
+func (c *Client) publishStream(events <-chan gomini.StreamEvent) <-chan gomini.StreamEvent {
+	resultChan := make(chan gomini.StreamEvent, 10)
+	go func() {
+		defer close(resultChan)
+		for event := range events {
+			c.events.publish(event)
+			resultChan <- event
+		}
+	}()
+	return resultChan
+}
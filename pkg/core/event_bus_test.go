@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// drain returns the events buffered on a subscription
+func drain(events <-chan gomini.StreamEvent) []gomini.StreamEvent {
+	var received []gomini.StreamEvent
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return received
+			}
+			received = append(received, event)
+		default:
+			return received
+		}
+	}
+}
+
+func TestClient_SubscribeStream(t *testing.T) {
+	client := newShutdownClient(t)
+	useProvider(client, &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		responses: []gomini.StreamEvent{
+			{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "hi"}},
+			{Type: gomini.EventFinished, Metadata: gomini.EventMeta{Usage: &gomini.Usage{TotalTokens: 3}}},
+		},
+	})
+
+	all, cancelAll := client.Subscribe()
+	defer cancelAll()
+	finished, cancelFinished := client.Subscribe(gomini.EventFinished)
+	defer cancelFinished()
+
+	var forwarded int
+	for range client.SendMessageStream(context.Background(), &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	}, "prompt-1") {
+		forwarded++
+	}
+
+	received := drain(all)
+	if len(received) != forwarded {
+		t.Fatalf("expected %d events on the bus, got %d", forwarded, len(received))
+	}
+	for _, event := range received {
+		if event.PromptID != "prompt-1" {
+			t.Errorf("expected bus events to carry the prompt ID, got %+v", event)
+		}
+	}
+
+	onlyFinished := drain(finished)
+	if len(onlyFinished) != 1 || onlyFinished[0].Type != gomini.EventFinished {
+		t.Errorf("expected only the finished event, got %+v", onlyFinished)
+	}
+}
+
+func TestClient_SubscribeSendMessage(t *testing.T) {
+	client := newShutdownClient(t)
+	mockProvider := &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		chatResponse: &gomini.ChatResponse{Usage: &gomini.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}},
+	}
+	useProvider(client, mockProvider)
+
+	events, cancel := client.Subscribe(gomini.EventUsage, gomini.EventError)
+	defer cancel()
+
+	request := &gomini.ChatRequest{Model: "gpt-4o", Messages: []gomini.Message{gomini.NewUserMessage("hi")}}
+	if _, err := client.SendMessage(context.Background(), request); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	useProvider(client, &failingMockProvider{
+		MockProvider: MockProvider{providerType: providers.ProviderOpenAI},
+		err:          errors.New("503 service unavailable"),
+	})
+	client.SendMessage(context.Background(), request)
+
+	received := drain(events)
+	if len(received) != 2 {
+		t.Fatalf("expected a usage and an error event, got %+v", received)
+	}
+	usage, ok := received[0].Data.(gomini.UsageEvent)
+	if received[0].Type != gomini.EventUsage || !ok || usage.Usage.TotalTokens != 15 {
+		t.Errorf("expected usage of 15 tokens, got %+v", received[0])
+	}
+	if received[1].Type != gomini.EventError || received[1].Error == nil {
+		t.Errorf("expected an error event, got %+v", received[1])
+	}
+}
+
+func TestClient_SubscribeCancel(t *testing.T) {
+	client := newShutdownClient(t)
+	events, cancel := client.Subscribe()
+
+	cancel()
+	cancel() // Safe to call twice
+	if _, ok := <-events; ok {
+		t.Error("expected the channel to be closed after cancel")
+	}
+
+	// Publishing to no subscribers is a no-op
+	client.events.publish(gomini.NewDebugEvent(providers.ProviderOpenAI, "info", "ignored", nil))
+
+	events, cancel = client.Subscribe()
+	defer cancel()
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if _, ok := <-events; ok {
+		t.Error("expected Shutdown to close subscriptions")
+	}
+}
+
+func TestClient_SlowSubscriberDoesNotBlock(t *testing.T) {
+	client := newShutdownClient(t)
+	events, cancel := client.Subscribe()
+	defer cancel()
+
+	for i := 0; i < SubscriberBufferSize*2; i++ {
+		client.events.publish(gomini.NewDebugEvent(providers.ProviderOpenAI, "info", "tick", nil))
+	}
+	if got := len(drain(events)); got != SubscriberBufferSize {
+		t.Errorf("expected %d buffered events, got %d", SubscriberBufferSize, got)
+	}
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultCleanupInterval is used when Config.CleanupInterval is unset
+const DefaultCleanupInterval = time.Minute
+
+// ExpiringStore is implemented by stores whose entries expire after a TTL and
+// can be soft-deleted. Invalidated entries are hidden immediately and removed
+// for good by the next Cleanup.
+type ExpiringStore interface {
+	// Invalidate soft-deletes an entry; invalidating a missing entry is not an error
+	Invalidate(ctx context.Context, id string) error
+
+	// Undelete restores an invalidated entry that has not been cleaned up yet
+	Undelete(ctx context.Context, id string) error
+
+	// Cleanup removes expired and invalidated entries, returning how many were removed
+	Cleanup(ctx context.Context) (int, error)
+}
+
+// janitor periodically cleans up the stores registered with a client
+type janitor struct {
+	mu       sync.Mutex
+	stores   []ExpiringStore
+	interval time.Duration
+	logger   func() *slog.Logger // The client's current logger
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newJanitor(interval time.Duration, logger func() *slog.Logger) *janitor {
+	if interval <= 0 {
+		interval = DefaultCleanupInterval
+	}
+	return &janitor{interval: interval, logger: logger}
+}
+
+// add registers a store, starting the cleanup goroutine on first use
+func (j *janitor) add(store ExpiringStore) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.stores = append(j.stores, store)
+	if j.stop == nil {
+		j.stop = make(chan struct{})
+		j.done = make(chan struct{})
+		go j.run(j.stop, j.done)
+	}
+}
+
+// remove unregisters a store; its entries are no longer cleaned up
+func (j *janitor) remove(store ExpiringStore) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for i, registered := range j.stores {
+		if registered == store {
+			j.stores = append(j.stores[:i:i], j.stores[i+1:]...)
+			return
+		}
+	}
+}
+
+func (j *janitor) run(stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			j.sweep()
+		}
+	}
+}
+
+// sweep runs Cleanup on every registered store once
+func (j *janitor) sweep() {
+	j.mu.Lock()
+	stores := append([]ExpiringStore(nil), j.stores...)
+	j.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), j.interval)
+	defer cancel()
+
+	for _, store := range stores {
+		removed, err := store.Cleanup(ctx)
+		if err != nil {
+			j.logger().Warn("store cleanup failed", slog.String("store", fmt.Sprintf("%T", store)), slog.String("error", err.Error()))
+		} else if removed > 0 {
+			j.logger().Debug("cleaned up expired entries", slog.String("store", fmt.Sprintf("%T", store)), slog.Int("removed", removed))
+		}
+	}
+}
+
+// close stops the cleanup goroutine and waits for an in-flight sweep to finish
+func (j *janitor) close() {
+	j.mu.Lock()
+	stop, done := j.stop, j.done
+	j.stop, j.done = nil, nil
+	j.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// RegisterExpiringStore adds a store to the client's background cleanup. The
+// cleanup goroutine runs every Config.CleanupInterval and stops on Close.
+func (c *Client) RegisterExpiringStore(store ExpiringStore) {
+	if c.janitor == nil {
+		c.janitor = newJanitor(c.currentState().config.CleanupInterval, c.Logger)
+	}
+	c.janitor.add(store)
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+)
+
+func TestSessionStores_TTLAndInvalidate(t *testing.T) {
+	fileStore, err := NewFileSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+	memoryStore := NewMemorySessionStore()
+
+	now := time.Now()
+	clock := func() time.Time { return now }
+	fileStore.now = clock
+	memoryStore.now = clock
+	fileStore.SetTTL(time.Hour)
+	memoryStore.SetTTL(time.Hour)
+
+	stores := map[string]interface {
+		SessionStore
+		ExpiringStore
+	}{
+		"memory": memoryStore,
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now = time.Now()
+
+			for _, id := range []string{"keep", "drop"} {
+				if err := store.Save(ctx, NewSession(id, 0)); err != nil {
+					t.Fatalf("Save failed: %v", err)
+				}
+			}
+
+			if err := store.Invalidate(ctx, "drop"); err != nil {
+				t.Fatalf("Invalidate failed: %v", err)
+			}
+			if _, err := store.Load(ctx, "drop"); !errors.Is(err, ErrSessionNotFound) {
+				t.Errorf("Expected invalidated session to be hidden, got %v", err)
+			}
+			if err := store.Undelete(ctx, "drop"); err != nil {
+				t.Fatalf("Undelete failed: %v", err)
+			}
+			if _, err := store.Load(ctx, "drop"); err != nil {
+				t.Errorf("Expected undeleted session to load, got %v", err)
+			}
+
+			store.Invalidate(ctx, "drop")
+			if removed, err := store.Cleanup(ctx); err != nil || removed != 1 {
+				t.Errorf("Expected cleanup to remove the invalidated session, got %d, %v", removed, err)
+			}
+			if err := store.Undelete(ctx, "drop"); !errors.Is(err, ErrSessionNotFound) {
+				t.Errorf("Expected cleaned up session to be gone, got %v", err)
+			}
+
+			now = now.Add(2 * time.Hour)
+			if _, err := store.Load(ctx, "keep"); !errors.Is(err, ErrSessionNotFound) {
+				t.Errorf("Expected expired session to be hidden, got %v", err)
+			}
+			if ids, _ := store.List(ctx); len(ids) != 0 {
+				t.Errorf("Expected no live sessions, got %v", ids)
+			}
+			if removed, err := store.Cleanup(ctx); err != nil || removed != 1 {
+				t.Errorf("Expected cleanup to remove the expired session, got %d, %v", removed, err)
+			}
+		})
+	}
+}
+
+type countingStore struct {
+	cleanups atomic.Int32
+}
+
+func (s *countingStore) Invalidate(ctx context.Context, id string) error { return nil }
+func (s *countingStore) Undelete(ctx context.Context, id string) error   { return nil }
+func (s *countingStore) Cleanup(ctx context.Context) (int, error) {
+	s.cleanups.Add(1)
+	return 0, nil
+}
+
+func TestClient_RegisterExpiringStore(t *testing.T) {
+	config := gomini.NewConfig()
+	config.CleanupInterval = 5 * time.Millisecond
+	client := &Client{streamScheduler: newStreamScheduler(streamSlots(0))}
+	client.state.Store(&clientState{config: config, providers: newProviderSet(nil)})
+
+	store := &countingStore{}
+	client.RegisterExpiringStore(store)
+
+	deadline := time.Now().Add(time.Second)
+	for store.cleanups.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if store.cleanups.Load() < 2 {
+		t.Fatalf("Expected periodic cleanups, got %d", store.cleanups.Load())
+	}
+
+	client.Close()
+	after := store.cleanups.Load()
+	time.Sleep(20 * time.Millisecond)
+	if store.cleanups.Load() != after {
+		t.Error("Expected cleanups to stop after Close")
+	}
+}
+
+// failingStore fails every cleanup
+type failingStore struct{ countingStore }
+
+func (s *failingStore) Cleanup(ctx context.Context) (int, error) {
+	return 0, errors.New("backend unavailable")
+}
+
+func TestJanitor_LogsCleanupFailures(t *testing.T) {
+	client := &Client{}
+	var logs bytes.Buffer
+	client.logger.Store(slog.New(slog.NewTextHandler(&logs, nil)))
+	j := newJanitor(time.Minute, client.Logger)
+	j.stores = []ExpiringStore{&failingStore{}}
+
+	j.sweep()
+	if !strings.Contains(logs.String(), "store cleanup failed") || !strings.Contains(logs.String(), "backend unavailable") {
+		t.Errorf("Expected the failure on the client's logger, got %q", logs.String())
+	}
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"context"
+	"sort"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// fallbackEnabled reports whether a failed request may be retried on other
+// providers. Fallback is opt-in with Config.EnableFallback and covers only
+// retryable errors, such as rate limits, timeouts and server errors; a
+// rejected request, bad key or blocked content would fail the same way
+// elsewhere. Requests pinned to a provider never fall back.
+func (c *Client) fallbackEnabled(ctx context.Context, st *clientState, pinned bool, err error) bool {
+	if pinned || !st.config.EnableFallback || ctx.Err() != nil {
+		return false
+	}
+	if st.config.Router != nil && !st.config.Router.FallbackOnError {
+		return false
+	}
+	return providers.ClassifyError(err, st.providerType, "").IsRetryable()
+}
+
+// fallbackProviders returns the providers to try after primary fails: the
+// configured fallback chain, or every other enabled provider, bounded by the
+// router's MaxFallbackAttempts
+func (c *Client) fallbackProviders(st *clientState, primary providers.ProviderType) []providers.ProviderType {
+	chain := st.config.FallbackChain
+	if len(chain) == 0 {
+		chain = st.config.GetEnabledProviders()
+		sort.Slice(chain, func(i, j int) bool { return chain[i] < chain[j] })
+	}
+
+	limit := len(chain)
+	if st.config.Router != nil && st.config.Router.MaxFallbackAttempts > 0 {
+		limit = st.config.Router.MaxFallbackAttempts
+	}
+
+	var candidates []providers.ProviderType
+	seen := map[providers.ProviderType]bool{primary: true}
+	for _, providerType := range chain {
+		if len(candidates) == limit {
+			break
+		}
+		if seen[providerType] || !st.config.HasProvider(providerType) {
+			continue
+		}
+		seen[providerType] = true
+		candidates = append(candidates, providerType)
+	}
+	return candidates
+}
+
+// defaultModel returns the model to use when falling back to a provider
+func (c *Client) defaultModel(st *clientState, providerType providers.ProviderType) string {
+	pc, err := st.config.GetProviderConfig(providerType)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case pc.OpenAI != nil && pc.OpenAI.DefaultModel != "":
+		return pc.OpenAI.DefaultModel
+	case pc.Gemini != nil && pc.Gemini.DefaultModel != "":
+		return pc.Gemini.DefaultModel
+	}
+	return pc.DefaultModel
+}
+
+// runFallback retries a request that failed on st with each fallback
+// provider's default model until attempt succeeds. When every candidate fails
+// it returns an AllProvidersFailedError wrapping each failure; with no
+// candidates it returns the primary error unchanged. Attempts run on their own
+// states, leaving the client's active provider unchanged.
+func (c *Client) runFallback(ctx context.Context, st *clientState, model string, primaryErr error, attempt func(fallback *clientState, model string) error) error {
+	primary := st.providerType
+	candidates := c.fallbackProviders(st, primary)
+	if len(candidates) == 0 {
+		return primaryErr
+	}
+
+	failures := []*gomini.LLMError{gomini.WrapProviderError(primaryErr, primary, model)}
+	for _, providerType := range candidates {
+		fallbackModel := c.defaultModel(st, providerType)
+		if fallbackModel == "" {
+			failures = append(failures, gomini.NewLLMError(gomini.ErrorInvalidModel,
+				"no default model configured for fallback", providerType, nil))
+			continue
+		}
+		fallback, err := st.withProvider(providerType)
+		if err != nil {
+			failures = append(failures, gomini.WrapProviderError(err, providerType, fallbackModel))
+			continue
+		}
+		switchEvent := gomini.NewProviderSwitchEvent(primary, providerType, "fallback after error", true)
+		switchEvent.Model = fallbackModel
+		c.events.publish(switchEvent)
+
+		err = attempt(fallback, fallbackModel)
+		if err == nil {
+			return nil
+		}
+		failures = append(failures, gomini.WrapProviderError(err, providerType, fallbackModel))
+		if !c.fallbackEnabled(ctx, fallback, false, err) {
+			break
+		}
+	}
+	return gomini.NewAllProvidersFailedError(failures)
+}
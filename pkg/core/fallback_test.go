@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// failingMockProvider fails every chat request with err
+type failingMockProvider struct {
+	MockProvider
+	err error
+}
+
+func (m *failingMockProvider) SendMessage(ctx context.Context, request *gomini.ChatRequest) (*gomini.ChatResponse, error) {
+	m.lastRequest = request
+	return nil, m.err
+}
+
+func newFallbackClient(t *testing.T, factory func(providers.ProviderType) (providers.LLMProvider, error)) *Client {
+	t.Helper()
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled:      true,
+		APIKey:       "test-key",
+		DefaultModel: "gpt-4o-mini",
+	}
+	config.Providers[providers.ProviderGemini] = &gomini.ProviderConfig{
+		Enabled:      true,
+		APIKey:       "test-key",
+		DefaultModel: "gemini-1.5-flash",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.EnableFallback = true
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.providerFactory = factory
+	if err := rebuildProviders(client); err != nil {
+		t.Fatalf("rebuildProviders failed: %v", err)
+	}
+	return client
+}
+
+func TestClient_SendMessage_AllProvidersFailed(t *testing.T) {
+	client := newFallbackClient(t, func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		return &failingMockProvider{
+			MockProvider: MockProvider{providerType: providerType},
+			err:          errors.New(string(providerType) + ": 429 rate limit exceeded"),
+		}, nil
+	})
+
+	_, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	})
+	if !errors.Is(err, gomini.ErrAllProvidersFailed) {
+		t.Fatalf("Expected ErrAllProvidersFailed, got %v", err)
+	}
+	if !errors.Is(err, gomini.ErrRateLimit) {
+		t.Errorf("Expected per-provider rate limit errors to be reachable, got %v", err)
+	}
+
+	var aggregate *gomini.AllProvidersFailedError
+	if !errors.As(err, &aggregate) {
+		t.Fatalf("Expected *AllProvidersFailedError, got %T", err)
+	}
+	if len(aggregate.Failures) != 2 {
+		t.Fatalf("Expected 2 failures, got %d", len(aggregate.Failures))
+	}
+	if aggregate.Failures[0].Provider != providers.ProviderOpenAI || aggregate.Failures[1].Provider != providers.ProviderGemini {
+		t.Errorf("Unexpected failure order: %v", err)
+	}
+	if aggregate.Failures[1].Model != "gemini-1.5-flash" {
+		t.Errorf("Expected fallback to the default model, got %q", aggregate.Failures[1].Model)
+	}
+	if client.GetCurrentProviderType() != providers.ProviderOpenAI {
+		t.Errorf("Expected the primary provider to be restored, got %s", client.GetCurrentProviderType())
+	}
+}
+
+func TestClient_SendMessage_Fallback(t *testing.T) {
+	client := newFallbackClient(t, func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		if providerType == providers.ProviderOpenAI {
+			return &failingMockProvider{
+				MockProvider: MockProvider{providerType: providerType},
+				err:          errors.New("503 service unavailable"),
+			}, nil
+		}
+		return &MockProvider{providerType: providerType}, nil
+	})
+
+	resp, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	})
+	if err != nil {
+		t.Fatalf("Expected fallback to succeed, got %v", err)
+	}
+	if resp.Provider != providers.ProviderGemini || resp.Model != "gemini-1.5-flash" {
+		t.Errorf("Expected a Gemini response, got %s/%s", resp.Provider, resp.Model)
+	}
+
+	// Pinned requests and content errors never fall back
+	_, err = client.SendMessage(context.Background(), &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Provider: providers.ProviderOpenAI,
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	})
+	if err == nil || errors.Is(err, gomini.ErrAllProvidersFailed) {
+		t.Errorf("Expected the pinned provider's error, got %v", err)
+	}
+}
+
+func TestClient_SendMessage_NoFallback(t *testing.T) {
+	failing := func(message string) func(providers.ProviderType) (providers.LLMProvider, error) {
+		return func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+			if providerType == providers.ProviderOpenAI {
+				return &failingMockProvider{
+					MockProvider: MockProvider{providerType: providerType},
+					err:          errors.New(message),
+				}, nil
+			}
+			return &MockProvider{providerType: providerType}, nil
+		}
+	}
+	request := &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	}
+
+	// Errors that would fail on any provider are returned as they are
+	for _, message := range []string{"401 invalid api key", "400 invalid request", "404 model not found"} {
+		client := newFallbackClient(t, failing(message))
+		if _, err := client.SendMessage(context.Background(), request); err == nil || errors.Is(err, gomini.ErrAllProvidersFailed) {
+			t.Errorf("Expected %q without fallback, got %v", message, err)
+		}
+	}
+
+	// Fallback is off unless enabled
+	client := newFallbackClient(t, failing("503 service unavailable"))
+	client.currentState().config.EnableFallback = false
+	if _, err := client.SendMessage(context.Background(), request); err == nil || errors.Is(err, gomini.ErrAllProvidersFailed) {
+		t.Errorf("Expected the primary error with fallback disabled, got %v", err)
+	}
+}
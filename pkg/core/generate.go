@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gomini/pkg/gomini"
+)
+
+// GenerateAs generates a JSON response and decodes it into T.
+// When the request has no schema, one is derived from T via gomini.SchemaFor.
+// The response is validated against the schema before decoding, so malformed
+// output surfaces as a *gomini.SchemaValidationError listing each bad field.
+func GenerateAs[T any](ctx context.Context, client *Client, req *gomini.JSONRequest) (T, error) {
+	var result T
+
+	request := *req
+	if request.Schema == nil {
+		schema, err := gomini.SchemaFor[T]()
+		if err != nil {
+			return result, fmt.Errorf("failed to derive schema for %T: %w", result, err)
+		}
+		if schema["type"] != "object" {
+			return result, fmt.Errorf("GenerateAs requires an object type, got %T", result)
+		}
+		request.Schema = schema
+	}
+
+	resp, err := client.GenerateJSON(ctx, &request)
+	if err != nil {
+		return result, err
+	}
+
+	if err := gomini.ValidateJSON(resp.Data, request.Schema); err != nil {
+		return result, err
+	}
+
+	raw, err := json.Marshal(resp.Data)
+	if err != nil {
+		return result, fmt.Errorf("failed to encode response data: %w", err)
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return result, &gomini.SchemaValidationError{Errors: []gomini.FieldError{{
+				Path:    "$." + typeErr.Field,
+				Message: fmt.Sprintf("cannot decode %s into %s", typeErr.Value, typeErr.Type),
+			}}}
+		}
+		return result, fmt.Errorf("failed to decode response into %T: %w", result, err)
+	}
+
+	return result, nil
+}
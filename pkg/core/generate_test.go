@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+type weatherReport struct {
+	City        string   `json:"city" description:"City name"`
+	Temperature float64  `json:"temperature"`
+	Conditions  string   `json:"conditions" enum:"sunny,cloudy,rain"`
+	Alerts      []string `json:"alerts,omitempty"`
+}
+
+func newGenerateTestClient(t *testing.T, data map[string]interface{}) (*Client, *MockProvider) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	mockProvider := &MockProvider{providerType: providers.ProviderOpenAI, jsonData: data}
+	useProvider(client, mockProvider)
+	return client, mockProvider
+}
+
+func TestGenerateAs(t *testing.T) {
+	client, mockProvider := newGenerateTestClient(t, map[string]interface{}{
+		"city":        "Taipei",
+		"temperature": 28.5,
+		"conditions":  "sunny",
+	})
+
+	report, err := GenerateAs[weatherReport](context.Background(), client, &gomini.JSONRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("Weather in Taipei?")},
+		Model:    "gpt-4o",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.City != "Taipei" || report.Temperature != 28.5 || report.Conditions != "sunny" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if mockProvider.lastJSON == nil || mockProvider.lastJSON.Schema["type"] != "object" {
+		t.Error("expected a schema derived from the target type")
+	}
+}
+
+func TestGenerateAs_ValidationErrors(t *testing.T) {
+	client, _ := newGenerateTestClient(t, map[string]interface{}{
+		"city":        "Taipei",
+		"temperature": "hot",
+		"conditions":  "snow",
+	})
+
+	_, err := GenerateAs[weatherReport](context.Background(), client, &gomini.JSONRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("Weather in Taipei?")},
+		Model:    "gpt-4o",
+	})
+
+	var validationErr *gomini.SchemaValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected SchemaValidationError, got %v", err)
+	}
+
+	paths := make(map[string]bool)
+	for _, fieldErr := range validationErr.Errors {
+		paths[fieldErr.Path] = true
+	}
+	if !paths["$.temperature"] || !paths["$.conditions"] {
+		t.Errorf("expected field errors for temperature and conditions, got %+v", validationErr.Errors)
+	}
+}
+
+func TestGenerateAs_RejectsNonObjectTypes(t *testing.T) {
+	client, _ := newGenerateTestClient(t, nil)
+
+	if _, err := GenerateAs[[]string](context.Background(), client, &gomini.JSONRequest{Model: "gpt-4o"}); err == nil {
+		t.Error("expected an error for a non-object target type")
+	}
+}
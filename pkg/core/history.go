@@ -0,0 +1,19 @@
+package core
+
+import (
+	"context"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/history"
+)
+
+// FitHistory trims messages with strategy to leave reserveOutputTokens of the
+// named model's context window for the reply, looking the window up in the
+// current provider's catalog. Models missing from the catalog leave messages
+// unchanged. See history.Fit.
+func (c *Client) FitHistory(ctx context.Context, messages []gomini.Message, model string, reserveOutputTokens int, strategy history.Strategy) ([]gomini.Message, error) {
+	st, release := c.holdState()
+	defer release()
+	contextSize := c.modelContextSize(ctx, st.provider, model)
+	return history.Fit(messages, gomini.Model{ID: model, Provider: st.providerType, ContextSize: contextSize}, reserveOutputTokens, strategy)
+}
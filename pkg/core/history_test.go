@@ -0,0 +1,32 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini"
+)
+
+func TestClient_FitHistory(t *testing.T) {
+	client, mockProvider := newGenerateTestClient(t, nil)
+	mockProvider.models = []gomini.Model{{ID: "small", ContextSize: 100}}
+	messages := []gomini.Message{
+		gomini.NewSystemMessage("Be brief."),
+		gomini.NewUserMessage(strings.Repeat("old ", 100)),
+		gomini.NewUserMessage("What now?"),
+	}
+
+	fitted, err := client.FitHistory(context.Background(), messages, "small", 20, nil)
+	if err != nil {
+		t.Fatalf("FitHistory failed: %v", err)
+	}
+	if len(fitted) != 2 || fitted[1].(map[string]interface{})["content"] != "What now?" {
+		t.Errorf("Expected the old turn to be dropped, got %v", fitted)
+	}
+
+	unknown, err := client.FitHistory(context.Background(), messages, "unlisted", 20, nil)
+	if err != nil || len(unknown) != 3 {
+		t.Errorf("Expected models missing from the catalog to leave messages alone, got %v, %v", unknown, err)
+	}
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// GenerateJSONStream streams structured output. Alongside the provider's events it
+// emits EventPartialJSON whenever the best-effort parse of the text received so far
+// changes, and a final complete PartialJSONEvent once generation finishes.
+// Providers that cannot stream JSON fall back to a single GenerateJSON call.
+func (c *Client) GenerateJSONStream(ctx context.Context, request *gomini.JSONRequest) <-chan gomini.StreamEvent {
+	// The state is held until the stream ends
+	st, releaseState := c.holdState()
+	resultChan := make(chan gomini.StreamEvent, 10)
+	request = c.shapeJSONRequest(st, c.withJSONTags(st, request))
+
+	go func() {
+		defer close(resultChan)
+		defer releaseState()
+
+		if request.Provider != "" {
+			routed, err := st.withProvider(providers.ProviderType(request.Provider))
+			if err != nil {
+				resultChan <- gomini.NewErrorEvent(st.providerType, request.Model,
+					fmt.Errorf("failed to switch provider: %w", err), false)
+				return
+			}
+			st = routed
+		}
+
+		streamer, ok := st.provider.(providers.JSONStreamer)
+		if !ok {
+			c.generateJSONOnce(ctx, st, request, resultChan)
+			return
+		}
+
+		var raw strings.Builder
+		var last interface{}
+		streamCtx, cancelStream := context.WithCancel(ctx)
+		defer cancelStream()
+		idleTimeout, providerType := st.config.StreamIdleTimeout, st.providerType
+		started := c.startStream(streamCtx, st, request.Model, func(ctx context.Context) <-chan providers.StreamEvent {
+			return streamer.GenerateJSONStream(ctx, request)
+		})
+		events := watchIdle(streamCtx, cancelStream, started, idleTimeout, providerType, request.Model)
+		// forward handles one provider event and reports whether the stream ends
+		forward := func(event providers.StreamEvent) bool {
+			gominiEvent := c.convertStreamEvent(event)
+
+			switch gominiEvent.Type {
+			case gomini.EventContent:
+				resultChan <- gominiEvent
+				if content, ok := gominiEvent.Data.(gomini.ContentEvent); ok {
+					raw.WriteString(content.Text)
+				}
+				if value, ok := gomini.ParsePartialJSON(raw.String()); ok && !reflect.DeepEqual(value, last) {
+					last = value
+					resultChan <- newPartialJSONEvent(gominiEvent.Provider, request.Model, value, raw.String(), false)
+				}
+			case gomini.EventFinished:
+				value, ok := gomini.ParsePartialJSON(raw.String())
+				if !ok {
+					resultChan <- gomini.NewErrorEvent(gominiEvent.Provider, request.Model,
+						fmt.Errorf("failed to parse JSON response: %q", raw.String()), false)
+					return true
+				}
+				resultChan <- newPartialJSONEvent(gominiEvent.Provider, request.Model, value, raw.String(), true)
+				if usageEvent, ok := c.streamUsageEvent(ctx, st, gominiEvent, request.Model); ok {
+					resultChan <- usageEvent
+				}
+				resultChan <- gominiEvent
+			case gomini.EventError:
+				resultChan <- gominiEvent
+				return true
+			default:
+				resultChan <- gominiEvent
+			}
+			return false
+		}
+		for {
+			// Hold a read slot while reading and forwarding each event so
+			// concurrent streams are served fairly
+			release, err := c.acquireStreamSlot(ctx)
+			if err != nil {
+				resultChan <- gomini.NewErrorEvent(st.providerType, request.Model, err, false)
+				return
+			}
+			event, ok := <-events
+			done := !ok || forward(event)
+			release()
+			if done {
+				return
+			}
+		}
+	}()
+
+	return c.publishStream(resultChan)
+}
+
+// generateJSONOnce emulates a JSON stream with a single non-streaming request
+func (c *Client) generateJSONOnce(ctx context.Context, st *clientState, request *gomini.JSONRequest, resultChan chan<- gomini.StreamEvent) {
+	resp, err := c.generateJSONWithTimeout(ctx, st, request)
+	if err != nil {
+		resultChan <- gomini.NewErrorEvent(st.providerType, request.Model, err, false)
+		return
+	}
+
+	raw, _ := json.Marshal(resp.Data)
+	resultChan <- newPartialJSONEvent(resp.Provider, request.Model, resp.Data, string(raw), true)
+	resultChan <- gomini.StreamEvent{
+		Type:      gomini.EventFinished,
+		Provider:  resp.Provider,
+		Model:     request.Model,
+		Timestamp: time.Now(),
+		Metadata: gomini.EventMeta{
+			FinishReason: providers.FinishReasonStop,
+			Usage:        resp.Usage,
+		},
+	}
+}
+
+func newPartialJSONEvent(provider providers.ProviderType, model string, value interface{}, raw string, complete bool) gomini.StreamEvent {
+	return gomini.StreamEvent{
+		Type:     gomini.EventPartialJSON,
+		Provider: provider,
+		Model:    model,
+		Data: gomini.PartialJSONEvent{
+			Data:     value,
+			Raw:      raw,
+			Complete: complete,
+		},
+		Timestamp: time.Now(),
+	}
+}
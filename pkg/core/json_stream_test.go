@@ -0,0 +1,84 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// mockJSONStreamer adds providers.JSONStreamer support to MockProvider
+type mockJSONStreamer struct {
+	*MockProvider
+}
+
+func (m *mockJSONStreamer) GenerateJSONStream(ctx context.Context, request *gomini.JSONRequest) <-chan providers.StreamEvent {
+	return m.SendMessageStream(ctx, &gomini.ChatRequest{Model: request.Model})
+}
+
+func collectPartialJSON(t *testing.T, events <-chan gomini.StreamEvent) ([]gomini.PartialJSONEvent, bool) {
+	t.Helper()
+
+	var partials []gomini.PartialJSONEvent
+	finished := false
+	for event := range events {
+		switch event.Type {
+		case gomini.EventPartialJSON:
+			partials = append(partials, event.Data.(gomini.PartialJSONEvent))
+		case gomini.EventFinished:
+			finished = true
+		case gomini.EventError:
+			t.Fatalf("unexpected error event: %v", event.Error)
+		}
+	}
+	return partials, finished
+}
+
+func TestClient_GenerateJSONStream(t *testing.T) {
+	client, mockProvider := newGenerateTestClient(t, nil)
+
+	chunks := []string{`{"city": "Tai`, `pei", "temp`, `erature": 28`, `.5}`}
+	for _, chunk := range chunks {
+		mockProvider.responses = append(mockProvider.responses, gomini.StreamEvent{
+			Type: gomini.EventContent,
+			Data: gomini.ContentEvent{Text: chunk, Delta: true},
+		})
+	}
+	mockProvider.responses = append(mockProvider.responses, gomini.StreamEvent{Type: gomini.EventFinished})
+	useProvider(client, &mockJSONStreamer{mockProvider})
+
+	partials, finished := collectPartialJSON(t, client.GenerateJSONStream(context.Background(), &gomini.JSONRequest{Model: "gpt-4o"}))
+	if !finished {
+		t.Error("expected finished event")
+	}
+	if len(partials) < 2 {
+		t.Fatalf("expected incremental partial events, got %d", len(partials))
+	}
+
+	first := partials[0].Data.(map[string]interface{})
+	if first["city"] != "Tai" || partials[0].Complete {
+		t.Errorf("unexpected first partial: %+v", partials[0])
+	}
+
+	final := partials[len(partials)-1]
+	data := final.Data.(map[string]interface{})
+	if !final.Complete || data["city"] != "Taipei" || data["temperature"] != 28.5 {
+		t.Errorf("unexpected final partial: %+v", final)
+	}
+}
+
+func TestClient_GenerateJSONStream_Fallback(t *testing.T) {
+	client, _ := newGenerateTestClient(t, map[string]interface{}{"city": "Taipei"})
+
+	partials, finished := collectPartialJSON(t, client.GenerateJSONStream(context.Background(), &gomini.JSONRequest{Model: "gpt-4o"}))
+	if !finished {
+		t.Error("expected finished event")
+	}
+	if len(partials) != 1 || !partials[0].Complete {
+		t.Fatalf("expected a single complete partial event, got %+v", partials)
+	}
+	if partials[0].Raw != `{"city":"Taipei"}` {
+		t.Errorf("unexpected raw JSON %q", partials[0].Raw)
+	}
+}
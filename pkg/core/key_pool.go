@@ -0,0 +1,371 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// DefaultKeyCooldown is how long a rate-limited pooled key is benched when
+// ProviderConfig.KeyCooldown is unset
+const DefaultKeyCooldown = time.Minute
+
+// pooledKey is one API key of a keyPool and the provider using it
+type pooledKey struct {
+	id            string // Masked key, safe to log
+	provider      providers.LLMProvider
+	benchedUntil  time.Time
+	revoked       bool // Rejected with 401; benched until the config reloads
+	lastThrottled time.Time
+}
+
+// keyPool spreads requests over one provider per API key. Keys that are
+// rate limited or rejected are benched and the request is retried on the
+// next key in rotation.
+type keyPool struct {
+	providerType providers.ProviderType
+	rotation     gomini.KeyRotation
+	cooldown     time.Duration
+	publish      func(gomini.StreamEvent)
+	now          func() time.Time
+
+	mu   sync.Mutex
+	keys []*pooledKey
+	next int
+}
+
+// newKeyPool creates a provider per key of providerConfig and pools them
+func (c *Client) newKeyPool(providerType providers.ProviderType, providerConfig *gomini.ProviderConfig, keys []string) (providers.LLMProvider, error) {
+	pooled := make([]*pooledKey, 0, len(keys))
+	for _, key := range keys {
+		keyed := *providerConfig
+		keyed.APIKey, keyed.APIKeys = key, nil
+		provider, err := c.buildProviderFrom(&keyed, providerType)
+		if err != nil {
+			for _, built := range pooled {
+				built.provider.Close()
+			}
+			return nil, err
+		}
+		pooled = append(pooled, &pooledKey{id: maskKey(key), provider: provider})
+	}
+	pool := newKeyPoolProvider(providerType, providerConfig.KeyRotation, providerConfig.KeyCooldown, pooled, c.events.publish)
+	// Every key has the same provider, so the first shows which features the pool supports
+	return providers.Expose(pool, pooled[0].provider), nil
+}
+
+func newKeyPoolProvider(providerType providers.ProviderType, rotation gomini.KeyRotation, cooldown time.Duration, keys []*pooledKey, publish func(gomini.StreamEvent)) *keyPool {
+	if cooldown <= 0 {
+		cooldown = DefaultKeyCooldown
+	}
+	return &keyPool{
+		providerType: providerType,
+		rotation:     rotation,
+		cooldown:     cooldown,
+		publish:      publish,
+		now:          time.Now,
+		keys:         keys,
+	}
+}
+
+// maskKey shortens a key to a recognizable, non-secret form. Keys too short
+// to show a part of safely are masked completely.
+func maskKey(key string) string {
+	if len(key) <= 8 {
+		return "..."
+	}
+	return key[:3] + "..." + key[len(key)-4:]
+}
+
+// acquire picks the next key that has not been tried. When every key is
+// benched, a first attempt uses the key that recovers soonest; retries give up.
+func (p *keyPool) acquire(tried map[*pooledKey]bool) (*pooledKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var chosen, soonest *pooledKey
+	chosenIndex := -1
+	revoked := 0
+	for i := 0; i < len(p.keys); i++ {
+		index := (p.next + i) % len(p.keys)
+		key := p.keys[index]
+		if key.revoked {
+			revoked++
+			continue
+		}
+		if tried[key] {
+			continue
+		}
+		if now.Before(key.benchedUntil) {
+			if soonest == nil || key.benchedUntil.Before(soonest.benchedUntil) {
+				soonest = key
+			}
+			continue
+		}
+		if chosen == nil || (p.rotation == gomini.RotateLeastThrottled && key.lastThrottled.Before(chosen.lastThrottled)) {
+			chosen, chosenIndex = key, index
+		}
+	}
+
+	if revoked == len(p.keys) {
+		return nil, fmt.Errorf("all %d API keys for %s were rejected", len(p.keys), p.providerType)
+	}
+	if chosen != nil {
+		p.next = chosenIndex + 1
+		return chosen, nil
+	}
+	if len(tried) == 0 {
+		return soonest, nil
+	}
+	return nil, nil
+}
+
+// report benches key if err shows it is throttled or rejected, and returns
+// whether the request should be retried on another key
+func (p *keyPool) report(key *pooledKey, model string, err error) bool {
+	if err == nil {
+		return false
+	}
+	var llmErr *gomini.LLMError
+	if !errors.As(err, &llmErr) {
+		llmErr = gomini.WrapProviderError(err, p.providerType, model)
+	}
+
+	var reason string
+	var until time.Time
+	p.mu.Lock()
+	switch {
+	case llmErr.IsRateLimit():
+		now := p.now()
+		key.lastThrottled = now
+		key.benchedUntil = now.Add(p.cooldown)
+		reason, until = "rate limited", key.benchedUntil
+	case llmErr.Code == gomini.ErrorInvalidAPIKey:
+		key.revoked = true
+		reason = "rejected as unauthorized"
+	default:
+		p.mu.Unlock()
+		return false
+	}
+	active := 0
+	now := p.now()
+	for _, k := range p.keys {
+		if !k.revoked && !now.Before(k.benchedUntil) {
+			active++
+		}
+	}
+	p.mu.Unlock()
+
+	if p.publish != nil {
+		p.publish(gomini.NewKeyBenchedEvent(p.providerType, model, key.id, reason, until, active))
+	}
+	return true
+}
+
+// poolCall runs call on pooled keys until one is not throttled or rejected
+func poolCall[T any](ctx context.Context, p *keyPool, model string, call func(providers.LLMProvider) (T, error)) (T, error) {
+	tried := make(map[*pooledKey]bool)
+	var zero T
+	var lastErr error
+	for {
+		key, err := p.acquire(tried)
+		if err != nil {
+			return zero, err
+		}
+		if key == nil {
+			return zero, lastErr
+		}
+		tried[key] = true
+		noteKey(ctx, key.id)
+		result, err := call(key.provider)
+		if !p.report(key, model, err) {
+			return result, err
+		}
+		lastErr = err
+	}
+}
+
+// SendMessage sends the request with the next key in rotation
+func (p *keyPool) SendMessage(ctx context.Context, req *providers.ChatRequest) (*providers.ChatResponse, error) {
+	return poolCall(ctx, p, req.Model, func(provider providers.LLMProvider) (*providers.ChatResponse, error) {
+		return provider.SendMessage(ctx, req)
+	})
+}
+
+// SendMessageStream streams with the next key in rotation. A stream that
+// fails before its first event is retried on another key.
+func (p *keyPool) SendMessageStream(ctx context.Context, req *providers.ChatRequest) <-chan providers.StreamEvent {
+	return poolStream(ctx, p, req.Model, func(provider providers.LLMProvider) <-chan providers.StreamEvent {
+		return provider.SendMessageStream(ctx, req)
+	})
+}
+
+// poolStream opens a stream on pooled keys until one does not fail with a
+// throttled or rejected key before its first event
+func poolStream(ctx context.Context, p *keyPool, model string, open func(providers.LLMProvider) <-chan providers.StreamEvent) <-chan providers.StreamEvent {
+	resultChan := make(chan providers.StreamEvent, 10)
+
+	go func() {
+		defer close(resultChan)
+
+		tried := make(map[*pooledKey]bool)
+		var lastErr providers.StreamEvent
+		for {
+			key, err := p.acquire(tried)
+			if err != nil {
+				resultChan <- providers.NewErrorEvent(p.providerType, model, err, false)
+				return
+			}
+			if key == nil {
+				resultChan <- lastErr
+				return
+			}
+			tried[key] = true
+			noteKey(ctx, key.id)
+
+			source := open(key.provider)
+			first, ok := <-source
+			if !ok {
+				return
+			}
+			if first.Type == providers.EventError && p.report(key, model, first.Error) {
+				lastErr = first
+				for range source {
+				}
+				continue
+			}
+
+			resultChan <- first
+			for event := range source {
+				if event.Type == providers.EventError {
+					p.report(key, model, event.Error)
+				}
+				select {
+				case resultChan <- event:
+				case <-ctx.Done():
+					for range source {
+					}
+					return
+				}
+			}
+			return
+		}
+	}()
+
+	return resultChan
+}
+
+// GenerateJSON generates JSON with the next key in rotation
+func (p *keyPool) GenerateJSON(ctx context.Context, req *providers.JSONRequest) (*providers.JSONResponse, error) {
+	return poolCall(ctx, p, req.Model, func(provider providers.LLMProvider) (*providers.JSONResponse, error) {
+		return provider.GenerateJSON(ctx, req)
+	})
+}
+
+// ListModels lists models with the next key in rotation
+func (p *keyPool) ListModels(ctx context.Context) ([]providers.Model, error) {
+	return poolCall(ctx, p, "", func(provider providers.LLMProvider) ([]providers.Model, error) {
+		return provider.ListModels(ctx)
+	})
+}
+
+// Moderate moderates with the next key in rotation
+func (p *keyPool) Moderate(ctx context.Context, text string) (*providers.ModerationResult, error) {
+	return poolCall(ctx, p, "", func(provider providers.LLMProvider) (*providers.ModerationResult, error) {
+		moderator, ok := provider.(providers.Moderator)
+		if !ok {
+			return nil, fmt.Errorf("provider %s does not support moderation", p.providerType)
+		}
+		return moderator.Moderate(ctx, text)
+	})
+}
+
+// GenerateImage generates images with the next key in rotation
+func (p *keyPool) GenerateImage(ctx context.Context, req *providers.ImageRequest) (*providers.ImageResponse, error) {
+	return poolCall(ctx, p, req.Model, func(provider providers.LLMProvider) (*providers.ImageResponse, error) {
+		generator, ok := provider.(providers.ImageGenerator)
+		if !ok {
+			return nil, fmt.Errorf("provider %s does not support image generation", p.providerType)
+		}
+		return generator.GenerateImage(ctx, req)
+	})
+}
+
+// GenerateJSONStream streams JSON with the next key in rotation
+func (p *keyPool) GenerateJSONStream(ctx context.Context, req *providers.JSONRequest) <-chan providers.StreamEvent {
+	return poolStream(ctx, p, req.Model, func(provider providers.LLMProvider) <-chan providers.StreamEvent {
+		streamer, ok := provider.(providers.JSONStreamer)
+		if !ok {
+			return unsupportedStream(p.providerType, req.Model, "JSON streaming")
+		}
+		return streamer.GenerateJSONStream(ctx, req)
+	})
+}
+
+// Speech synthesizes audio with the next key in rotation
+func (p *keyPool) Speech(ctx context.Context, req *providers.SpeechRequest) (*providers.SpeechResponse, error) {
+	return poolCall(ctx, p, req.Model, func(provider providers.LLMProvider) (*providers.SpeechResponse, error) {
+		audio, ok := provider.(providers.AudioProvider)
+		if !ok {
+			return nil, fmt.Errorf("provider %s does not support speech", p.providerType)
+		}
+		return audio.Speech(ctx, req)
+	})
+}
+
+// SpeechStream streams synthesized audio with the next key in rotation
+func (p *keyPool) SpeechStream(ctx context.Context, req *providers.SpeechRequest) <-chan providers.StreamEvent {
+	return poolStream(ctx, p, req.Model, func(provider providers.LLMProvider) <-chan providers.StreamEvent {
+		audio, ok := provider.(providers.AudioProvider)
+		if !ok {
+			return unsupportedStream(p.providerType, req.Model, "speech")
+		}
+		return audio.SpeechStream(ctx, req)
+	})
+}
+
+// Transcribe transcribes audio with the next key in rotation
+func (p *keyPool) Transcribe(ctx context.Context, req *providers.TranscriptionRequest) (*providers.TranscriptionResponse, error) {
+	return poolCall(ctx, p, req.Model, func(provider providers.LLMProvider) (*providers.TranscriptionResponse, error) {
+		audio, ok := provider.(providers.AudioProvider)
+		if !ok {
+			return nil, fmt.Errorf("provider %s does not support transcription", p.providerType)
+		}
+		return audio.Transcribe(ctx, req)
+	})
+}
+
+// unsupportedStream returns a stream holding only an error for feature
+func unsupportedStream(providerType providers.ProviderType, model, feature string) <-chan providers.StreamEvent {
+	resultChan := make(chan providers.StreamEvent, 1)
+	resultChan <- providers.NewErrorEvent(providerType, model, fmt.Errorf("provider %s does not support %s", providerType, feature), false)
+	close(resultChan)
+	return resultChan
+}
+
+// GetCapabilities returns the capabilities shared by the pooled providers
+func (p *keyPool) GetCapabilities() providers.ProviderCapabilities {
+	return p.keys[0].provider.GetCapabilities()
+}
+
+// GetProviderType returns the pooled provider type
+func (p *keyPool) GetProviderType() providers.ProviderType {
+	return p.providerType
+}
+
+// Close closes every pooled provider
+func (p *keyPool) Close() error {
+	var errs []error
+	for _, key := range p.keys {
+		if err := key.provider.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,266 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// keyedProvider answers as its key, failing with err when set
+type keyedProvider struct {
+	*MockProvider
+	key   string
+	err   error
+	calls int
+}
+
+func (p *keyedProvider) SendMessage(ctx context.Context, request *gomini.ChatRequest) (*gomini.ChatResponse, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &gomini.ChatResponse{
+		Choices: []gomini.Choice{{Message: gomini.NewAssistantMessage(p.key)}},
+		Usage:   &gomini.Usage{TotalTokens: 10},
+	}, nil
+}
+
+func newTestKeyPool(rotation gomini.KeyRotation, keys ...string) (*keyPool, []*keyedProvider, *[]gomini.StreamEvent, *time.Time) {
+	var events []gomini.StreamEvent
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	pooled := make([]*pooledKey, len(keys))
+	fakes := make([]*keyedProvider, len(keys))
+	for i, key := range keys {
+		fakes[i] = &keyedProvider{MockProvider: &MockProvider{providerType: providers.ProviderOpenAI}, key: key}
+		pooled[i] = &pooledKey{id: maskKey(key), provider: fakes[i]}
+	}
+	pool := newKeyPoolProvider(providers.ProviderOpenAI, rotation, time.Minute, pooled, func(event gomini.StreamEvent) {
+		events = append(events, event)
+	})
+	pool.now = func() time.Time { return now }
+	return pool, fakes, &events, &now
+}
+
+func sendPooled(t *testing.T, pool *keyPool) string {
+	t.Helper()
+	resp, err := pool.SendMessage(context.Background(), &gomini.ChatRequest{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	text, _, _ := responseText(resp)
+	return text
+}
+
+func TestKeyPool_RoundRobin(t *testing.T) {
+	pool, _, _, _ := newTestKeyPool(gomini.RotateRoundRobin, "sk-key-a-0001", "sk-key-b-0002", "sk-key-c-0003")
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, sendPooled(t, pool))
+	}
+	want := []string{"sk-key-a-0001", "sk-key-b-0002", "sk-key-c-0003", "sk-key-a-0001"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected rotation %v, got %v", want, got)
+		}
+	}
+}
+
+func TestKeyPool_BenchesThrottledKeys(t *testing.T) {
+	pool, fakes, events, now := newTestKeyPool(gomini.RotateRoundRobin, "sk-key-a-0001", "sk-key-b-0002")
+	fakes[0].err = errors.New("429 rate limit exceeded")
+
+	// The throttled key is benched and the request retried on the next one
+	if got := sendPooled(t, pool); got != "sk-key-b-0002" {
+		t.Errorf("Expected the retry on key b, got %s", got)
+	}
+	if len(*events) != 1 || (*events)[0].Type != gomini.EventKeyBenched {
+		t.Fatalf("Expected one key benched event, got %v", *events)
+	}
+	benched := (*events)[0].Data.(gomini.KeyBenchedEvent)
+	if benched.KeyID != "sk-...0001" || benched.Active != 1 || !benched.Until.Equal(now.Add(time.Minute)) {
+		t.Errorf("Unexpected benched event: %+v", benched)
+	}
+
+	fakes[0].err = nil
+	sendPooled(t, pool)
+	if fakes[0].calls != 1 {
+		t.Errorf("Expected the benched key to be skipped, got %d calls", fakes[0].calls)
+	}
+
+	*now = now.Add(time.Minute)
+	if got := sendPooled(t, pool); got != "sk-key-a-0001" {
+		t.Errorf("Expected key a back in rotation after the cooldown, got %s", got)
+	}
+}
+
+func TestKeyPool_RevokesRejectedKeys(t *testing.T) {
+	pool, fakes, _, now := newTestKeyPool(gomini.RotateRoundRobin, "sk-key-a-0001", "sk-key-b-0002")
+	fakes[0].err = errors.New("401 unauthorized")
+
+	if got := sendPooled(t, pool); got != "sk-key-b-0002" {
+		t.Errorf("Expected the retry on key b, got %s", got)
+	}
+	*now = now.Add(24 * time.Hour)
+	fakes[0].err = nil
+	for i := 0; i < 3; i++ {
+		sendPooled(t, pool)
+	}
+	if fakes[0].calls != 1 {
+		t.Errorf("Expected the rejected key to stay benched, got %d calls", fakes[0].calls)
+	}
+
+	fakes[1].err = errors.New("401 unauthorized")
+	if _, err := pool.SendMessage(context.Background(), &gomini.ChatRequest{}); err == nil {
+		t.Error("Expected an error once every key is rejected")
+	}
+}
+
+func TestKeyPool_AllThrottledReturnsLastError(t *testing.T) {
+	pool, fakes, _, _ := newTestKeyPool(gomini.RotateRoundRobin, "sk-key-a-0001", "sk-key-b-0002")
+	for _, fake := range fakes {
+		fake.err = errors.New("429 rate limit exceeded")
+	}
+
+	if _, err := pool.SendMessage(context.Background(), &gomini.ChatRequest{}); err == nil {
+		t.Fatal("Expected the rate limit error")
+	}
+	// With every key benched, the next request still goes to the key that recovers first
+	if _, err := pool.SendMessage(context.Background(), &gomini.ChatRequest{}); err == nil {
+		t.Fatal("Expected the rate limit error")
+	}
+	if fakes[0].calls != 2 || fakes[1].calls != 1 {
+		t.Errorf("Unexpected calls: a=%d b=%d", fakes[0].calls, fakes[1].calls)
+	}
+}
+
+func TestKeyPool_LeastThrottled(t *testing.T) {
+	pool, _, _, now := newTestKeyPool(gomini.RotateLeastThrottled, "sk-key-a-0001", "sk-key-b-0002", "sk-key-c-0003")
+	pool.keys[0].lastThrottled = now.Add(-time.Hour)
+	pool.keys[1].lastThrottled = now.Add(-2 * time.Hour)
+	pool.keys[2].lastThrottled = now.Add(-30 * time.Minute)
+
+	if got := sendPooled(t, pool); got != "sk-key-b-0002" {
+		t.Errorf("Expected the key throttled longest ago, got %s", got)
+	}
+}
+
+func TestClient_KeyPoolFromConfig(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "sk-key-a-0001",
+		APIKeys: []string{"sk-key-b-0002", "sk-key-a-0001", ""},
+	}
+	if keys := config.Providers[providers.ProviderOpenAI].Keys(); len(keys) != 2 {
+		t.Errorf("Expected duplicate and blank keys dropped, got %v", keys)
+	}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	pool, ok := providers.Unwrap(client.GetCurrentProvider()).(*keyPool)
+	if !ok {
+		t.Fatalf("Expected a key pool, got %T", client.GetCurrentProvider())
+	}
+	if len(pool.keys) != 2 || pool.GetProviderType() != providers.ProviderOpenAI {
+		t.Errorf("Unexpected pool: %d keys, type %s", len(pool.keys), pool.GetProviderType())
+	}
+
+	// The pool offers exactly the optional features of the provider it pools
+	provider := client.GetCurrentProvider()
+	if _, ok := provider.(providers.AudioProvider); !ok {
+		t.Error("Expected a pool of OpenAI keys to support audio")
+	}
+	if _, ok := provider.(providers.JSONStreamer); !ok {
+		t.Error("Expected a pool of OpenAI keys to stream JSON")
+	}
+	if _, ok := provider.(providers.Moderator); !ok {
+		t.Error("Expected a pool of OpenAI keys to moderate")
+	}
+
+	config.Providers[providers.ProviderOpenAI].KeyRotation = "random"
+	if err := config.Validate(); err == nil {
+		t.Error("Expected an unknown key rotation to be rejected")
+	}
+}
+
+func TestKeyPool_ExposesWrappedFeatures(t *testing.T) {
+	pool, _, _, _ := newTestKeyPool(gomini.RotateRoundRobin, "sk-key-a-0001", "sk-key-b-0002")
+	provider := providers.Expose(pool, pool.keys[0].provider)
+	if _, ok := provider.(providers.Moderator); ok {
+		t.Error("Expected no moderation from a pool of providers without it")
+	}
+	if _, ok := provider.(providers.AudioProvider); ok {
+		t.Error("Expected no audio from a pool of providers without it")
+	}
+	if providers.Unwrap(provider) != pool {
+		t.Error("Expected Unwrap to return the pool")
+	}
+}
+
+// keyedAudioProvider synthesizes its key, failing with err when set
+type keyedAudioProvider struct {
+	audioMockProvider
+	key   string
+	err   error
+	calls int
+}
+
+func (p *keyedAudioProvider) Speech(ctx context.Context, req *providers.SpeechRequest) (*providers.SpeechResponse, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &providers.SpeechResponse{Model: req.Model, Audio: []byte(p.key)}, nil
+}
+
+func TestKeyPool_SpeechRotatesKeys(t *testing.T) {
+	fakes := make([]*keyedAudioProvider, 2)
+	pooled := make([]*pooledKey, len(fakes))
+	for i, key := range []string{"sk-key-a-0001", "sk-key-b-0002"} {
+		fakes[i] = &keyedAudioProvider{audioMockProvider: audioMockProvider{MockProvider{providerType: providers.ProviderOpenAI}}, key: key}
+		pooled[i] = &pooledKey{id: maskKey(key), provider: fakes[i]}
+	}
+	fakes[0].err = errors.New("429 rate limit exceeded")
+	pool := newKeyPoolProvider(providers.ProviderOpenAI, gomini.RotateRoundRobin, time.Minute, pooled, nil)
+
+	audio, ok := providers.Expose(pool, pooled[0].provider).(providers.AudioProvider)
+	if !ok {
+		t.Fatal("Expected the pool to support audio")
+	}
+	resp, err := audio.Speech(context.Background(), &providers.SpeechRequest{Input: "hi", Model: "tts-1"})
+	if err != nil {
+		t.Fatalf("Speech failed: %v", err)
+	}
+	if string(resp.Audio) != "sk-key-b-0002" || fakes[0].calls != 1 {
+		t.Errorf("Expected the throttled key to be retried on key b, got %q", resp.Audio)
+	}
+
+	var audioBytes []byte
+	for event := range audio.SpeechStream(context.Background(), &providers.SpeechRequest{Input: "hi", Model: "tts-1"}) {
+		if chunk, ok := event.Data.(providers.AudioChunkEvent); ok {
+			audioBytes = append(audioBytes, chunk.Data...)
+		}
+	}
+	if string(audioBytes) != "ID3" {
+		t.Errorf("Expected the streamed audio, got %q", audioBytes)
+	}
+}
+
+func TestMaskKey(t *testing.T) {
+	if got := maskKey("sk-key-a-0001"); got != "sk-...0001" {
+		t.Errorf("Expected a long key to keep its prefix and suffix, got %s", got)
+	}
+	for _, key := range []string{"", "short", "12345678"} {
+		if got := maskKey(key); got != "..." {
+			t.Errorf("Expected %q to be masked completely, got %s", key, got)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// LatencyWindow is how many recent streams of each model LatencyStats covers
+const LatencyWindow = 100
+
+// LatencyStats summarizes the latency of a model's recent streams
+type LatencyStats struct {
+	Provider        providers.ProviderType `json:"provider"`
+	Model           string                 `json:"model"`
+	Streams         int                    `json:"streams"` // Streams in the window
+	MeanTTFT        time.Duration          `json:"mean_ttft"`
+	P95TTFT         time.Duration          `json:"p95_ttft"`
+	TokensPerSecond float64                `json:"tokens_per_second,omitempty"` // Mean over streams that reported output tokens
+}
+
+// latencyTracker keeps the latest LatencyWindow samples per model
+type latencyTracker struct {
+	mu      sync.Mutex
+	samples map[ModelRef][]gomini.StreamLatency
+}
+
+func (t *latencyTracker) record(ref ModelRef, latency gomini.StreamLatency) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.samples == nil {
+		t.samples = make(map[ModelRef][]gomini.StreamLatency)
+	}
+	samples := append(t.samples[ref], latency)
+	if len(samples) > LatencyWindow {
+		samples = samples[len(samples)-LatencyWindow:]
+	}
+	t.samples[ref] = samples
+}
+
+// stats summarizes the samples of ref
+func (t *latencyTracker) stats(ref ModelRef) (LatencyStats, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	samples := t.samples[ref]
+	if len(samples) == 0 {
+		return LatencyStats{}, false
+	}
+	return summarizeLatency(ref, samples), true
+}
+
+// percentile reports the time to first token of ref's recent streams at
+// percentile p
+func (t *latencyTracker) percentile(ref ModelRef, p float64) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	samples := t.samples[ref]
+	if len(samples) == 0 {
+		return 0, false
+	}
+	ttfts := make([]time.Duration, len(samples))
+	for i, sample := range samples {
+		ttfts[i] = sample.TimeToFirstToken
+	}
+	return percentile(ttfts, p), true
+}
+
+// all summarizes every tracked model, sorted by provider and model
+func (t *latencyTracker) all() []LatencyStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := make([]LatencyStats, 0, len(t.samples))
+	for ref, samples := range t.samples {
+		stats = append(stats, summarizeLatency(ref, samples))
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Provider != stats[j].Provider {
+			return stats[i].Provider < stats[j].Provider
+		}
+		return stats[i].Model < stats[j].Model
+	})
+	return stats
+}
+
+func summarizeLatency(ref ModelRef, samples []gomini.StreamLatency) LatencyStats {
+	stats := LatencyStats{Provider: ref.Provider, Model: ref.Model, Streams: len(samples)}
+	ttfts := make([]time.Duration, len(samples))
+	var total time.Duration
+	var tps float64
+	var rated int
+	for i, sample := range samples {
+		ttfts[i] = sample.TimeToFirstToken
+		total += sample.TimeToFirstToken
+		if sample.TokensPerSecond > 0 {
+			tps += sample.TokensPerSecond
+			rated++
+		}
+	}
+	stats.MeanTTFT = total / time.Duration(len(samples))
+	stats.P95TTFT = percentile(ttfts, 0.95)
+	if rated > 0 {
+		stats.TokensPerSecond = tps / float64(rated)
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p (0-1] of durations,
+// sorting them in place
+func percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	rank := int(math.Ceil(p*float64(len(durations)))) - 1
+	return durations[max(0, min(rank, len(durations)-1))]
+}
+
+// outputEvent reports whether a stream event carries generated output,
+// marking the stream's first token
+func outputEvent(eventType providers.EventType) bool {
+	switch eventType {
+	case providers.EventContent, providers.EventThought, providers.EventToolCall,
+		providers.EventToolCallDelta, providers.EventImage:
+		return true
+	}
+	return false
+}
+
+// ModelLatency reports the time to first token and tokens/second of a
+// model's recent streams. An empty ref.Provider means the current provider.
+func (c *Client) ModelLatency(ref ModelRef) (LatencyStats, bool) {
+	if ref.Provider == "" {
+		ref.Provider = c.currentState().providerType
+	}
+	return c.latency.stats(ref)
+}
+
+// LatencyReport summarizes the recent streams of every model that has
+// streamed, sorted by provider and model
+func (c *Client) LatencyReport() []LatencyStats {
+	return c.latency.all()
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestLatencyTracker_Stats(t *testing.T) {
+	var tracker latencyTracker
+	ref := ModelRef{Provider: providers.ProviderOpenAI, Model: "gpt-4o"}
+	for i := 1; i <= LatencyWindow+20; i++ {
+		tracker.record(ref, gomini.StreamLatency{TimeToFirstToken: time.Duration(i) * time.Millisecond, TokensPerSecond: float64(i % 2 * 40)})
+	}
+
+	stats, ok := tracker.stats(ref)
+	if !ok {
+		t.Fatal("Expected stats for a recorded model")
+	}
+	// The window holds samples 21 to 120
+	if stats.Streams != LatencyWindow || stats.MeanTTFT != 70500*time.Microsecond || stats.P95TTFT != 115*time.Millisecond {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if stats.TokensPerSecond != 40 {
+		t.Errorf("Expected the mean over streams with a rate, got %v", stats.TokensPerSecond)
+	}
+	if _, ok := tracker.stats(ModelRef{Provider: providers.ProviderGemini, Model: "gpt-4o"}); ok {
+		t.Error("Expected models to be tracked per provider")
+	}
+}
+
+func TestStartStream_Latency(t *testing.T) {
+	client, _ := newGenerateTestClient(t, nil)
+	source := make(chan providers.StreamEvent)
+	events := client.startStream(context.Background(), client.currentState(), "gpt-4o", func(ctx context.Context) <-chan providers.StreamEvent {
+		return source
+	})
+	go func() {
+		defer close(source)
+		time.Sleep(20 * time.Millisecond)
+		source <- providers.StreamEvent{Type: providers.EventContent, Data: providers.ContentEvent{Text: "hi", Delta: true}}
+		time.Sleep(20 * time.Millisecond)
+		source <- providers.StreamEvent{Type: providers.EventFinished, Metadata: providers.EventMeta{Usage: &providers.Usage{OutputTokens: 10}}}
+	}()
+
+	var finished providers.StreamEvent
+	for event := range events {
+		if event.Type == providers.EventFinished {
+			finished = event
+		}
+	}
+	latency := finished.Metadata.Latency
+	if latency == nil {
+		t.Fatal("Expected the finished event to carry the stream latency")
+	}
+	if latency.TimeToFirstToken < 20*time.Millisecond || latency.Duration < 40*time.Millisecond || latency.TimeToFirstToken >= latency.Duration {
+		t.Errorf("Unexpected latency %+v", latency)
+	}
+	if latency.TokensPerSecond <= 0 || latency.TokensPerSecond > 500 {
+		t.Errorf("Expected 10 tokens over at least 20ms, got %v tokens/s", latency.TokensPerSecond)
+	}
+
+	stats, ok := client.ModelLatency(ModelRef{Model: "gpt-4o"})
+	if !ok || stats.Streams != 1 || stats.P95TTFT != latency.TimeToFirstToken {
+		t.Errorf("Expected the stream to be recorded, got %+v", stats)
+	}
+	if report := client.LatencyReport(); len(report) != 1 || report[0] != stats {
+		t.Errorf("Expected the report to list the model, got %+v", report)
+	}
+
+	usageEvent, ok := client.streamUsageEvent(context.Background(), client.currentState(), client.convertStreamEvent(finished), "gpt-4o")
+	data, _ := usageEvent.Data.(gomini.UsageEvent)
+	if !ok || data.Efficiency != latency.TokensPerSecond || data.TimeToFirstToken != latency.TimeToFirstToken {
+		t.Errorf("Expected the usage event to report the latency, got %+v", data)
+	}
+}
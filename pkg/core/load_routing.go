@@ -0,0 +1,225 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// LoadWindow is how many recent calls per provider the latency and error
+// rate of ProviderLoad cover
+const LoadWindow = 50
+
+// ProviderLoad is the live load of a provider, which StrategyLeastLoaded
+// routes by
+type ProviderLoad struct {
+	Provider   providers.ProviderType `json:"provider"`
+	InFlight   int                    `json:"in_flight"`   // Calls queued or running
+	Calls      int                    `json:"calls"`       // Recent calls measured
+	P95Latency time.Duration          `json:"p95_latency"` // Of successful calls, to the response or a stream's first token
+	ErrorRate  float64                `json:"error_rate"`  // Share of recent calls failing on the provider's side
+}
+
+// score estimates how long a new request would wait: the calls in flight and
+// the new one each take the p95 latency, stretched by retries of failing
+// calls. Providers whose recent calls all failed score +Inf; those not yet
+// measured score by their calls in flight alone, so idle ones are tried first.
+func (l ProviderLoad) score() float64 {
+	if l.Calls == 0 {
+		return float64(l.InFlight)
+	}
+	if l.ErrorRate >= 1 {
+		return math.Inf(1)
+	}
+	return float64(l.InFlight+1) * float64(l.P95Latency) / (1 - l.ErrorRate)
+}
+
+// loadSample is the outcome of one call
+type loadSample struct {
+	latency time.Duration
+	failed  bool
+}
+
+// loadTracker counts calls in flight and keeps the latest LoadWindow
+// outcomes per provider
+type loadTracker struct {
+	mu       sync.Mutex
+	inFlight map[providers.ProviderType]int
+	samples  map[providers.ProviderType][]loadSample
+}
+
+// begin counts a call to provider as in flight until done reports its
+// latency and error. Errors on the caller's side, such as a cancelled
+// context or an invalid request, are not counted against the provider.
+func (t *loadTracker) begin(provider providers.ProviderType) (done func(latency time.Duration, err error)) {
+	t.mu.Lock()
+	if t.inFlight == nil {
+		t.inFlight = make(map[providers.ProviderType]int)
+		t.samples = make(map[providers.ProviderType][]loadSample)
+	}
+	t.inFlight[provider]++
+	t.mu.Unlock()
+
+	var once sync.Once
+	return func(latency time.Duration, err error) {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.inFlight[provider]--
+			failed := providerFailure(err, provider)
+			if err != nil && !failed {
+				return
+			}
+			samples := append(t.samples[provider], loadSample{latency: latency, failed: failed})
+			if len(samples) > LoadWindow {
+				samples = samples[len(samples)-LoadWindow:]
+			}
+			t.samples[provider] = samples
+		})
+	}
+}
+
+// load reports the current load of provider
+func (t *loadTracker) load(provider providers.ProviderType) ProviderLoad {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	load := ProviderLoad{Provider: provider, InFlight: t.inFlight[provider]}
+	samples := t.samples[provider]
+	load.Calls = len(samples)
+	latencies := t.latencies(provider)
+	if load.Calls > 0 {
+		load.ErrorRate = float64(load.Calls-len(latencies)) / float64(load.Calls)
+	}
+	load.P95Latency = percentile(latencies, 0.95)
+	return load
+}
+
+// percentile reports the latency of provider's recent successful calls at
+// percentile p
+func (t *loadTracker) percentile(provider providers.ProviderType, p float64) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	latencies := t.latencies(provider)
+	if len(latencies) == 0 {
+		return 0, false
+	}
+	return percentile(latencies, p), true
+}
+
+// latencies lists the latencies of provider's recent successful calls. The
+// caller holds t.mu.
+func (t *loadTracker) latencies(provider providers.ProviderType) []time.Duration {
+	samples := t.samples[provider]
+	latencies := make([]time.Duration, 0, len(samples))
+	for _, sample := range samples {
+		if !sample.failed {
+			latencies = append(latencies, sample.latency)
+		}
+	}
+	return latencies
+}
+
+// providerFailure reports whether err means the provider is unhealthy:
+// server errors, timeouts, network failures and rate limits, but not
+// cancellations, rejected requests or calls refused by the client's own
+// rate limit or circuit breaker
+func providerFailure(err error, provider providers.ProviderType) bool {
+	if err == nil || errors.Is(err, context.Canceled) || localRefusal(err) {
+		return false
+	}
+	return providers.ClassifyError(err, provider, "").IsRetryable()
+}
+
+// routeLeastLoaded sends a request naming no provider to the provider with
+// the lowest load score, under StrategyLeastLoaded, among those meeting
+// Router.LatencyTarget. Requests naming a model choose among the providers
+// whose catalogs list it, and are left to model routing when the model has a
+// ModelPreferences entry or no catalog lists it. Ties keep the current
+// provider.
+func (c *Client) routeLeastLoaded(ctx context.Context, st *clientState, request *gomini.ChatRequest) *gomini.ChatRequest {
+	router := st.config.Router
+	if router == nil || router.Strategy != gomini.StrategyLeastLoaded || request.Provider != "" {
+		return request
+	}
+
+	candidates := st.config.GetEnabledProviders()
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+	if request.Model != "" {
+		if _, preferred := router.ModelPreferences[request.Model]; preferred {
+			return request
+		}
+		candidates = c.modelIndex(ctx, st)[request.Model]
+	}
+	if len(candidates) == 0 {
+		return request
+	}
+	candidates = c.meetingLatencyTarget(st, request.Model, candidates)
+
+	// Candidates come in name order; the current provider, when it is one,
+	// scores first so that ties keep it
+	best, bestScore := providers.ProviderType(""), math.Inf(1)
+	for _, providerType := range candidates {
+		if providerType == st.providerType {
+			best, bestScore = providerType, c.load.load(providerType).score()
+		}
+	}
+	for _, providerType := range candidates {
+		if score := c.load.load(providerType).score(); best == "" || score < bestScore {
+			best, bestScore = providerType, score
+		}
+	}
+	if best == st.providerType {
+		return request
+	}
+	routed := *request
+	routed.Provider = best
+	return &routed
+}
+
+// meetingLatencyTarget narrows candidates to the providers meeting
+// Router.LatencyTarget, keeping them all when none does. A provider's latency
+// is taken from its recent streams of model, else from all its recent calls;
+// providers not yet measured are assumed to meet the target.
+func (c *Client) meetingLatencyTarget(st *clientState, model string, candidates []providers.ProviderType) []providers.ProviderType {
+	target := st.config.Router.LatencyTarget
+	if target == nil {
+		return candidates
+	}
+	var meeting []providers.ProviderType
+	for _, providerType := range candidates {
+		latency, measured := c.latency.percentile(ModelRef{Provider: providerType, Model: model}, target.Percentile)
+		if !measured {
+			latency, measured = c.load.percentile(providerType, target.Percentile)
+		}
+		if !measured || latency < target.Below {
+			meeting = append(meeting, providerType)
+		}
+	}
+	if len(meeting) == 0 {
+		return candidates
+	}
+	return meeting
+}
+
+// ProviderLoads reports the live load of each enabled provider, sorted by
+// provider
+func (c *Client) ProviderLoads() []ProviderLoad {
+	return c.providerLoads(c.currentState().config)
+}
+
+// providerLoads reports the live load of each provider enabled in config
+func (c *Client) providerLoads(config *gomini.Config) []ProviderLoad {
+	enabled := config.GetEnabledProviders()
+	sort.Slice(enabled, func(i, j int) bool { return enabled[i] < enabled[j] })
+	loads := make([]ProviderLoad, len(enabled))
+	for i, providerType := range enabled {
+		loads[i] = c.load.load(providerType)
+	}
+	return loads
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestLoadTracker(t *testing.T) {
+	var tracker loadTracker
+	openai := providers.ProviderOpenAI
+	serverError := gomini.NewLLMError(gomini.ErrorServerError, "overloaded", openai, nil)
+
+	for i := 1; i <= 20; i++ {
+		tracker.begin(openai)(time.Duration(i)*100*time.Millisecond, nil)
+	}
+	tracker.begin(openai)(50*time.Millisecond, serverError)
+	tracker.begin(openai)(time.Second, context.Canceled)
+	tracker.begin(openai)(time.Second, gomini.NewLLMError(gomini.ErrorInvalidRequest, "bad request", openai, nil))
+	running := tracker.begin(openai)
+	done := tracker.begin(openai)
+	done(time.Second, errors.New("ignored"))
+	done(time.Second, errors.New("ignored"))
+
+	load := tracker.load(openai)
+	if load.InFlight != 1 || load.Calls != 21 {
+		t.Errorf("Expected 1 call in flight and 21 measured, got %+v", load)
+	}
+	if load.P95Latency != 1900*time.Millisecond || load.ErrorRate != 1.0/21 {
+		t.Errorf("Expected the p95 of successful calls and the provider-side error rate, got %+v", load)
+	}
+	running(0, context.Canceled)
+	if load := tracker.load(openai); load.InFlight != 0 {
+		t.Errorf("Expected no calls in flight, got %d", load.InFlight)
+	}
+
+	for i := 0; i < LoadWindow; i++ {
+		tracker.begin(openai)(time.Millisecond, nil)
+	}
+	if load := tracker.load(openai); load.Calls != LoadWindow || load.ErrorRate != 0 {
+		t.Errorf("Expected only the latest %d calls to count, got %+v", LoadWindow, load)
+	}
+}
+
+func TestProviderLoad_Score(t *testing.T) {
+	idle := ProviderLoad{}
+	busyUnmeasured := ProviderLoad{InFlight: 2}
+	fast := ProviderLoad{Calls: 10, P95Latency: 100 * time.Millisecond}
+	fastBusy := ProviderLoad{Calls: 10, InFlight: 3, P95Latency: 100 * time.Millisecond}
+	slow := ProviderLoad{Calls: 10, P95Latency: time.Second}
+	flaky := ProviderLoad{Calls: 10, P95Latency: 100 * time.Millisecond, ErrorRate: 0.9}
+	down := ProviderLoad{Calls: 10, ErrorRate: 1}
+
+	ordered := []ProviderLoad{idle, busyUnmeasured, fast, fastBusy, slow, flaky, down}
+	for i := 1; i < len(ordered); i++ {
+		if ordered[i-1].score() >= ordered[i].score() {
+			t.Errorf("Expected %+v to score below %+v", ordered[i-1], ordered[i])
+		}
+	}
+	if !math.IsInf(down.score(), 1) {
+		t.Errorf("Expected a provider whose calls all failed to score +Inf, got %v", down.score())
+	}
+}
+
+func TestClient_RouteLeastLoaded(t *testing.T) {
+	config := gomini.NewConfig()
+	for _, providerType := range []providers.ProviderType{providers.ProviderOpenAI, providers.ProviderGemini, providers.ProviderGroq} {
+		config.Providers[providerType] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key"}
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.Router.Strategy = gomini.StrategyLeastLoaded
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	useProvider(client, &MockProvider{providerType: providers.ProviderOpenAI, models: []gomini.Model{{ID: "gpt-4o"}, {ID: "llama-3.3-70b"}}})
+	gemini := &MockProvider{providerType: providers.ProviderGemini, models: []gomini.Model{{ID: "gemini-2.0-flash"}, {ID: "llama-3.3-70b"}}}
+	groq := &MockProvider{providerType: providers.ProviderGroq, models: []gomini.Model{{ID: "llama-3.3-70b"}}}
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		if providerType == providers.ProviderGroq {
+			return groq, nil
+		}
+		return gemini, nil
+	}
+
+	record := func(provider providers.ProviderType, latency time.Duration, err error) {
+		client.load.begin(provider)(latency, err)
+	}
+	record(providers.ProviderOpenAI, time.Second, nil)
+	record(providers.ProviderGemini, 200*time.Millisecond, nil)
+	record(providers.ProviderGroq, 50*time.Millisecond, gomini.NewLLMError(gomini.ErrorServiceUnavailable, "down", providers.ProviderGroq, nil))
+
+	request := &gomini.ChatRequest{Messages: []gomini.Message{gomini.NewUserMessage("hi")}}
+	ctx := context.Background()
+	if routed := client.routeLeastLoaded(ctx, client.currentState(), request); routed.Provider != providers.ProviderGemini {
+		t.Errorf("Expected the least loaded provider, got %q (loads %+v)", routed.Provider, client.ProviderLoads())
+	}
+	if routed := client.routeLeastLoaded(ctx, client.currentState(), &gomini.ChatRequest{Model: "llama-3.3-70b"}); routed.Provider != providers.ProviderGemini {
+		t.Errorf("Expected the least loaded provider serving the model, got %q", routed.Provider)
+	}
+	if routed := client.routeLeastLoaded(ctx, client.currentState(), &gomini.ChatRequest{Model: "gpt-4o"}); routed.Provider != "" {
+		t.Errorf("Expected a model only the current provider serves to stay, got %q", routed.Provider)
+	}
+	if routed := client.routeLeastLoaded(ctx, client.currentState(), &gomini.ChatRequest{Model: "unknown"}); routed.Provider != "" {
+		t.Errorf("Expected models no catalog lists to be left to model routing, got %q", routed.Provider)
+	}
+	client.currentState().config.Router.ModelPreferences = map[string]providers.ProviderType{"llama-3.3-70b": providers.ProviderGroq}
+	if routed := client.routeLeastLoaded(ctx, client.currentState(), &gomini.ChatRequest{Model: "llama-3.3-70b"}); routed.Provider != "" {
+		t.Errorf("Expected model preferences to take precedence, got %q", routed.Provider)
+	}
+
+	if _, err := client.SendMessage(context.Background(), request); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if gemini.lastRequest == nil {
+		t.Fatal("Expected the request to be sent to gemini")
+	}
+	if client.GetCurrentProviderType() != providers.ProviderOpenAI {
+		t.Errorf("Expected the active provider to be unchanged, got %s", client.GetCurrentProviderType())
+	}
+	if load := client.load.load(providers.ProviderGemini); load.Calls != 2 || load.InFlight != 0 {
+		t.Errorf("Expected the call to be measured, got %+v", load)
+	}
+
+	// Requests stay while the current provider is the least loaded
+	onGemini, err := client.currentState().withProvider(providers.ProviderGemini)
+	if err != nil {
+		t.Fatalf("withProvider failed: %v", err)
+	}
+	if routed := client.routeLeastLoaded(ctx, onGemini, request); routed.Provider != "" {
+		t.Errorf("Expected gemini to stay, got %q", routed.Provider)
+	}
+
+	client.currentState().config.Router.Strategy = gomini.StrategyManual
+	record(providers.ProviderGemini, time.Minute, nil)
+	if routed := client.routeLeastLoaded(ctx, client.currentState(), request); routed.Provider != "" {
+		t.Errorf("Expected no routing under the manual strategy, got %q", routed.Provider)
+	}
+}
+
+func TestClient_RouteLeastLoadedConcurrent(t *testing.T) {
+	config := gomini.NewConfig()
+	for _, providerType := range []providers.ProviderType{providers.ProviderOpenAI, providers.ProviderGemini} {
+		config.Providers[providerType] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key"}
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.Router.Strategy = gomini.StrategyLeastLoaded
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	held := func(providerType providers.ProviderType) *heldSendProvider {
+		return &heldSendProvider{MockProvider: MockProvider{providerType: providerType}, entered: entered, release: release}
+	}
+	openai, gemini := held(providers.ProviderOpenAI), held(providers.ProviderGemini)
+	useProvider(client, openai)
+	var builds atomic.Int32
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		builds.Add(1)
+		return gemini, nil
+	}
+	client.load.begin(providers.ProviderOpenAI)(time.Second, nil)
+	client.load.begin(providers.ProviderGemini)(200*time.Millisecond, nil)
+
+	const calls = 4
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+				Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+			})
+			errs <- err
+		}()
+	}
+	for i := 0; i < calls; i++ {
+		<-entered
+	}
+
+	// Switching while calls are in flight closes neither provider
+	if err := client.SwitchProvider(providers.ProviderGemini); err != nil {
+		t.Fatalf("SwitchProvider failed: %v", err)
+	}
+	if err := client.SwitchProvider(providers.ProviderOpenAI); err != nil {
+		t.Fatalf("SwitchProvider failed: %v", err)
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if openai.closed.Load() || gemini.closed.Load() {
+		t.Error("Expected routing to leave every provider open")
+	}
+	if builds.Load() != 1 {
+		t.Errorf("Expected one live gemini instance, built %d", builds.Load())
+	}
+	if got := client.GetCurrentProviderType(); got != providers.ProviderOpenAI {
+		t.Errorf("Expected the active provider to be unchanged, got %s", got)
+	}
+	if load := client.load.load(providers.ProviderGemini); load.Calls < 2 {
+		t.Errorf("Expected requests to be routed to gemini, got %+v", load)
+	}
+}
+
+func TestClient_RouteLeastLoadedLatencyTarget(t *testing.T) {
+	config := gomini.NewConfig()
+	for _, providerType := range []providers.ProviderType{providers.ProviderOpenAI, providers.ProviderGemini} {
+		config.Providers[providerType] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key"}
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.Router.Strategy = gomini.StrategyLeastLoaded
+	config.Router.LatencyTarget = &gomini.LatencyTarget{Percentile: 0.95, Below: 2 * time.Second}
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	useProvider(client, &MockProvider{providerType: providers.ProviderOpenAI, models: []gomini.Model{{ID: "llama-3.3-70b"}}})
+	gemini := &MockProvider{providerType: providers.ProviderGemini, models: []gomini.Model{{ID: "llama-3.3-70b"}}}
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		return gemini, nil
+	}
+
+	// Gemini scores lower while openai is busy, but two calls in twenty miss
+	// the target
+	for i := 0; i < 3; i++ {
+		client.load.begin(providers.ProviderOpenAI)
+	}
+	for i := 0; i < 20; i++ {
+		client.load.begin(providers.ProviderOpenAI)(1500*time.Millisecond, nil)
+		latency := 100 * time.Millisecond
+		if i < 2 {
+			latency = 3 * time.Second
+		}
+		client.load.begin(providers.ProviderGemini)(latency, nil)
+	}
+	ctx := context.Background()
+	request := &gomini.ChatRequest{Messages: []gomini.Message{gomini.NewUserMessage("hi")}}
+	if routed := client.routeLeastLoaded(ctx, client.currentState(), request); routed.Provider != "" {
+		t.Errorf("Expected the provider meeting the target to be kept, got %q", routed.Provider)
+	}
+
+	// Per-model stream latency takes precedence over the provider's calls
+	client.latency.record(ModelRef{Provider: providers.ProviderGemini, Model: "llama-3.3-70b"}, gomini.StreamLatency{TimeToFirstToken: 300 * time.Millisecond})
+	client.latency.record(ModelRef{Provider: providers.ProviderOpenAI, Model: "llama-3.3-70b"}, gomini.StreamLatency{TimeToFirstToken: 4 * time.Second})
+	if routed := client.routeLeastLoaded(ctx, client.currentState(), &gomini.ChatRequest{Model: "llama-3.3-70b"}); routed.Provider != providers.ProviderGemini {
+		t.Errorf("Expected the provider streaming the model within the target, got %q", routed.Provider)
+	}
+
+	// When no provider meets the target, the least loaded is used
+	client.currentState().config.Router.LatencyTarget.Below = 10 * time.Millisecond
+	if routed := client.routeLeastLoaded(ctx, client.currentState(), request); routed.Provider != providers.ProviderGemini {
+		t.Errorf("Expected the least loaded provider, got %q", routed.Provider)
+	}
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gomini/pkg/gomini"
+)
+
+// DefaultLogRedactFields are credential fields always redacted from request logs
+var DefaultLogRedactFields = []string{
+	"api_key", "apikey", "x-api-key", "x-goog-api-key", "authorization",
+	"access_token", "refresh_token", "id_token", "client_secret", "password",
+}
+
+// maxLoggedStringLength truncates long strings, such as inline images, in request logs
+const maxLoggedStringLength = 512
+
+// redactedValue replaces sensitive values in request logs
+const redactedValue = "[REDACTED]"
+
+// parseLogLevel maps Config.LogLevel to a slog level; Debug lowers the default to debug
+func parseLogLevel(config *gomini.Config) slog.Level {
+	switch strings.ToLower(config.LogLevel) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	case "info":
+		return slog.LevelInfo
+	}
+	if config.Debug {
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// newLogger creates the default logger, writing text records to stderr at the configured level
+func (c *Client) newLogger(config *gomini.Config) *slog.Logger {
+	c.logLevel.Set(parseLogLevel(config))
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: &c.logLevel}))
+}
+
+// SetLogHandler routes the client's structured logs to handler. The handler's
+// own level applies instead of Config.LogLevel.
+func (c *Client) SetLogHandler(handler slog.Handler) {
+	logger := slog.New(handler)
+	c.logger.Store(logger)
+	c.loopDetectors.setLogger(logger)
+}
+
+// Logger returns the client's structured logger
+func (c *Client) Logger() *slog.Logger {
+	return c.logger.Load()
+}
+
+// logRequest records a sanitized request payload when Config.LogRequests is set
+func (c *Client) logRequest(ctx context.Context, st *clientState, operation, model string, request interface{}) {
+	if !st.config.LogRequests {
+		return
+	}
+	c.Logger().LogAttrs(ctx, slog.LevelInfo, "llm request",
+		slog.String("operation", operation),
+		slog.String("provider", string(st.providerType)),
+		slog.String("model", model),
+		slog.Any("request", c.sanitizePayload(st, request)),
+	)
+}
+
+// logResponse records a sanitized response payload or error when Config.LogRequests is set
+func (c *Client) logResponse(ctx context.Context, st *clientState, operation, model string, response interface{}, err error, elapsed time.Duration) {
+	if !st.config.LogRequests {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("operation", operation),
+		slog.String("provider", string(st.providerType)),
+		slog.String("model", model),
+		slog.Duration("duration", elapsed),
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", c.redactSecrets(st, err.Error())))
+	} else {
+		attrs = append(attrs, slog.Any("response", c.sanitizePayload(st, response)))
+	}
+	c.Logger().LogAttrs(ctx, level, "llm response", attrs...)
+}
+
+// logStream passes events through, logging the assembled response once the
+// stream ends. Streams are only wrapped when Config.LogRequests is set.
+func (c *Client) logStream(ctx context.Context, st *clientState, request *gomini.ChatRequest, events <-chan gomini.StreamEvent) <-chan gomini.StreamEvent {
+	if !st.config.LogRequests {
+		return events
+	}
+	c.logRequest(ctx, st, "chat_stream", request.Model, request)
+
+	start := time.Now()
+	resultChan := make(chan gomini.StreamEvent, 10)
+	go func() {
+		defer close(resultChan)
+
+		var text strings.Builder
+		var streamErr error
+		summary := map[string]interface{}{}
+		for event := range events {
+			switch event.Type {
+			case gomini.EventContent:
+				if content, ok := event.Data.(gomini.ContentEvent); ok {
+					text.WriteString(content.Text)
+				}
+			case gomini.EventError:
+				streamErr = event.Error
+			case gomini.EventFinished:
+				summary["finish_reason"] = event.Metadata.FinishReason
+				if event.Metadata.Usage != nil {
+					summary["usage"] = event.Metadata.Usage
+				}
+			}
+			resultChan <- event
+		}
+		summary["content"] = text.String()
+		c.logResponse(ctx, st, "chat_stream", request.Model, summary, streamErr, time.Since(start))
+	}()
+	return resultChan
+}
+
+// sanitizePayload converts v to generic JSON and redacts sensitive fields,
+// configured API keys and oversized strings
+func (c *Client) sanitizePayload(st *clientState, v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("<unloggable %T: %v>", v, err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return fmt.Sprintf("<unloggable %T: %v>", v, err)
+	}
+
+	redact := make(map[string]bool)
+	for _, field := range DefaultLogRedactFields {
+		redact[strings.ToLower(field)] = true
+	}
+	for _, field := range st.config.LogRedactFields {
+		redact[strings.ToLower(field)] = true
+	}
+	return c.sanitizeValue(st, generic, redact)
+}
+
+func (c *Client) sanitizeValue(st *clientState, value interface{}, redact map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if redact[strings.ToLower(key)] {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = c.sanitizeValue(st, item, redact)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = c.sanitizeValue(st, item, redact)
+		}
+		return v
+	case string:
+		v = c.redactSecrets(st, v)
+		if len(v) > maxLoggedStringLength {
+			// Cut on a rune boundary so the logged prefix stays valid UTF-8
+			cut := maxLoggedStringLength
+			for cut > 0 && !utf8.RuneStart(v[cut]) {
+				cut--
+			}
+			return fmt.Sprintf("%s...(%d bytes)", v[:cut], len(v))
+		}
+		return v
+	}
+	return value
+}
+
+// redactSecrets masks configured provider API keys wherever they appear in s
+func (c *Client) redactSecrets(st *clientState, s string) string {
+	for _, pc := range st.config.Providers {
+		if pc == nil {
+			continue
+		}
+		for _, key := range pc.Keys() {
+			s = strings.ReplaceAll(s, key, redactedValue)
+		}
+	}
+	return s
+}
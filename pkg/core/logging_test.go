@@ -0,0 +1,215 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// recordingHandler captures log records for assertions
+type recordingHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(ctx context.Context, level slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler { return h }
+
+func (h *recordingHandler) WithGroup(name string) slog.Handler { return h }
+
+// attr returns the JSON encoding of the named attribute on the i-th record
+func (h *recordingHandler) attr(t *testing.T, i int, key string) string {
+	t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var value string
+	h.records[i].Attrs(func(a slog.Attr) bool {
+		if a.Key == key {
+			raw, err := json.Marshal(a.Value.Any())
+			if err != nil {
+				t.Fatalf("Failed to encode %s: %v", key, err)
+			}
+			value = string(raw)
+			return false
+		}
+		return true
+	})
+	return value
+}
+
+func newLoggingClient(t *testing.T, logRequests bool) (*Client, *recordingHandler) {
+	t.Helper()
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "sk-secret-test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.LogRequests = logRequests
+	config.LogRedactFields = []string{"user_id"}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	useProvider(client, &MockProvider{providerType: providers.ProviderOpenAI})
+	handler := &recordingHandler{}
+	client.SetLogHandler(handler)
+	return client, handler
+}
+
+func TestClient_LogRequestsRedacts(t *testing.T) {
+	client, handler := newLoggingClient(t, true)
+
+	request := &gomini.ChatRequest{
+		Model: "gpt-4o",
+		Messages: []gomini.Message{
+			gomini.NewUserMessage("my key is sk-secret-test-key"),
+		},
+		Config: map[string]interface{}{
+			"api_key": "inline-key",
+			"user_id": "u-42",
+			"image":   strings.Repeat("A", 2000),
+		},
+	}
+	if _, err := client.SendMessage(context.Background(), request); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	if len(handler.records) != 2 {
+		t.Fatalf("Expected request and response records, got %d", len(handler.records))
+	}
+	if handler.records[0].Message != "llm request" || handler.records[1].Message != "llm response" {
+		t.Errorf("Unexpected messages %q, %q", handler.records[0].Message, handler.records[1].Message)
+	}
+
+	logged := handler.attr(t, 0, "request")
+	for _, leaked := range []string{"sk-secret-test-key", "inline-key", "u-42", strings.Repeat("A", 600)} {
+		if strings.Contains(logged, leaked) {
+			t.Errorf("Request log leaked %.20q: %s", leaked, logged)
+		}
+	}
+	if !strings.Contains(logged, redactedValue) || !strings.Contains(logged, "(2000 bytes)") {
+		t.Errorf("Expected redaction and truncation markers, got %s", logged)
+	}
+	if !strings.Contains(handler.attr(t, 1, "response"), "Mock response") {
+		t.Errorf("Expected the response payload, got %s", handler.attr(t, 1, "response"))
+	}
+}
+
+func TestClient_SanitizePayload(t *testing.T) {
+	client, _ := newLoggingClient(t, true)
+
+	sanitized := client.sanitizePayload(client.currentState(), map[string]interface{}{
+		"access_token": "ya29.secret",
+		"logprobs":     []interface{}{map[string]interface{}{"token": "Hello", "logprob": -0.1}},
+		"caption":      strings.Repeat("€", 300),
+	}).(map[string]interface{})
+
+	if sanitized["access_token"] != redactedValue {
+		t.Errorf("Expected the access token to be redacted, got %v", sanitized["access_token"])
+	}
+	logprob := sanitized["logprobs"].([]interface{})[0].(map[string]interface{})
+	if logprob["token"] != "Hello" {
+		t.Errorf("Expected logprob tokens to be kept, got %v", logprob["token"])
+	}
+	caption := sanitized["caption"].(string)
+	if !utf8.ValidString(caption) || !strings.HasSuffix(caption, "...(900 bytes)") {
+		t.Errorf("Expected a truncation on a rune boundary, got %q", caption)
+	}
+}
+
+func TestClient_SetLogHandlerConcurrently(t *testing.T) {
+	client, _ := newLoggingClient(t, true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.SetLogHandler(&recordingHandler{})
+		}()
+		go func() {
+			defer wg.Done()
+			client.SendMessage(context.Background(), &gomini.ChatRequest{
+				Model:    "gpt-4o",
+				Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+			})
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClient_LogRequestsStream(t *testing.T) {
+	client, handler := newLoggingClient(t, true)
+	useProvider(client, &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		responses: []gomini.StreamEvent{
+			gomini.NewContentEvent(providers.ProviderOpenAI, "gpt-4o", "Hello, ", true),
+			gomini.NewContentEvent(providers.ProviderOpenAI, "gpt-4o", "world", true),
+		},
+	})
+
+	stream := client.SendMessageStream(context.Background(), &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	}, "prompt-1")
+	for range stream {
+	}
+
+	if len(handler.records) != 2 {
+		t.Fatalf("Expected request and response records, got %d", len(handler.records))
+	}
+	if got := handler.attr(t, 1, "response"); !strings.Contains(got, "Hello, world") {
+		t.Errorf("Expected the assembled stream text, got %s", got)
+	}
+}
+
+func TestClient_LogRequestsDisabled(t *testing.T) {
+	client, handler := newLoggingClient(t, false)
+
+	if _, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if len(handler.records) != 0 {
+		t.Errorf("Expected no request logs, got %d", len(handler.records))
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		level string
+		debug bool
+		want  slog.Level
+	}{
+		{"", false, slog.LevelInfo},
+		{"", true, slog.LevelDebug},
+		{"WARN", false, slog.LevelWarn},
+		{"error", true, slog.LevelError},
+		{"debug", false, slog.LevelDebug},
+	}
+	for _, tt := range tests {
+		config := &gomini.Config{LogLevel: tt.level, Debug: tt.debug}
+		if got := parseLogLevel(config); got != tt.want {
+			t.Errorf("parseLogLevel(%q, debug=%v) = %v, want %v", tt.level, tt.debug, got, tt.want)
+		}
+	}
+}
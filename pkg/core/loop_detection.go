@@ -6,9 +6,12 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"regexp"
+	"math/rand"
 	"strings"
 	"sync"
+	"unicode"
 
 	"gomini/pkg/gomini"
 )
@@ -19,8 +22,13 @@ const (
 	CONTENT_LOOP_THRESHOLD   = 10
 	CONTENT_CHUNK_SIZE       = 50
 	MAX_HISTORY_LENGTH       = 1000
+
+	// Code-aware detection: a line repeated CODE_LINE_LOOP_THRESHOLD times
+	// within the last CODE_LINE_WINDOW lines of a code block is a loop
+	CODE_LINE_LOOP_THRESHOLD = 20
+	CODE_LINE_WINDOW         = 40
 	
-	// LLM-based loop detection constants (future use)
+	// LLM-based loop detection constants
 	LLM_LOOP_CHECK_HISTORY_COUNT = 20
 	LLM_CHECK_AFTER_TURNS        = 30
 	DEFAULT_LLM_CHECK_INTERVAL   = 3
@@ -46,10 +54,25 @@ type LoopDetectionService struct {
 	loopDetected            bool
 	inCodeBlock             bool
 
-	// LLM loop tracking (future use)
+	// Code block tracking (code-aware mode)
+	codeLineBuffer          string
+	codeLines               []string
+
+	// LLM loop tracking
 	turnsInCurrentPrompt    int
 	llmCheckInterval        int
 	lastCheckTurn           int
+	judge                   LoopJudge
+	jitter                  func(n int) int // rand.Intn; replaceable in tests
+
+	// Detections and failed LLM checks are logged at debug and warn level
+	logger *slog.Logger
+
+	// Telemetry
+	telemetry               LoopTelemetrySink
+	metrics                 LoopDetectionMetrics
+	pendingTelemetry        []LoopTelemetryEvent
+	nearMissReported        map[string]bool
 }
 
 // NewLoopDetectionService creates a new loop detection service
@@ -58,9 +81,19 @@ func NewLoopDetectionService(config *gomini.Config) *LoopDetectionService {
 		config:              config,
 		contentStats:        make(map[string][]int),
 		llmCheckInterval:    DEFAULT_LLM_CHECK_INTERVAL,
+		jitter:              rand.Intn,
+		nearMissReported:    make(map[string]bool),
+		logger:              slog.Default(),
 	}
 }
 
+// setConfig swaps in a reloaded configuration
+func (l *LoopDetectionService) setConfig(config *gomini.Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = config
+}
+
 // Reset clears all loop detection state for a new prompt
 func (l *LoopDetectionService) Reset(promptID string) {
 	l.mu.Lock()
@@ -69,13 +102,18 @@ func (l *LoopDetectionService) Reset(promptID string) {
 	l.promptID = promptID
 	l.resetToolCallCount()
 	l.resetContentTracking(true)
+	l.resetCodeTracking()
+	l.inCodeBlock = false
 	l.resetLLMCheckTracking()
 	l.loopDetected = false
+	l.nearMissReported = make(map[string]bool)
 }
 
 // AddAndCheck processes a stream event and checks for loop conditions
 // Returns true if a loop is detected
 func (l *LoopDetectionService) AddAndCheck(event gomini.StreamEvent) bool {
+	defer l.flushTelemetry()
+
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	
@@ -100,19 +138,28 @@ func (l *LoopDetectionService) AddAndCheck(event gomini.StreamEvent) bool {
 	return l.loopDetected
 }
 
-// TurnStarted signals the start of a new turn in the conversation
-// Returns true if a loop is detected (future LLM-based detection)
-func (l *LoopDetectionService) TurnStarted(ctx context.Context) bool {
+// TurnStarted signals the start of a new turn in the conversation.
+// After LLM_CHECK_AFTER_TURNS turns it periodically asks the judge model
+// whether history shows a cognitive loop. Returns true if a loop is detected.
+func (l *LoopDetectionService) TurnStarted(ctx context.Context, history []gomini.Message) bool {
+	defer l.flushTelemetry()
+
 	l.mu.Lock()
-	defer l.mu.Unlock()
-	
 	l.turnsInCurrentPrompt++
-	
-	// TODO: Implement LLM-based loop detection when needed
-	// This would involve calling an LLM to analyze conversation history
-	// for cognitive loops, similar to the TypeScript implementation
-	
-	return false
+	if l.loopDetected {
+		l.mu.Unlock()
+		return true
+	}
+	check := l.judge != nil && l.config.LoopJudgeModel != "" &&
+		l.turnsInCurrentPrompt >= LLM_CHECK_AFTER_TURNS &&
+		l.turnsInCurrentPrompt-l.lastCheckTurn >= l.llmCheckInterval
+	if check {
+		l.lastCheckTurn = l.turnsInCurrentPrompt
+	}
+	l.mu.Unlock()
+
+	// The judge is called without holding the lock
+	return check && l.checkForLoopWithLLM(ctx, history)
 }
 
 // IsLoopDetected returns whether a loop has been detected
@@ -145,13 +192,28 @@ func (l *LoopDetectionService) checkToolCallLoop(toolCall gomini.ToolCallEvent)
 	}
 	
 	if l.toolCallRepetitionCount >= TOOL_CALL_LOOP_THRESHOLD {
-		// Log loop detection (placeholder for future telemetry)
-		if l.config.Debug {
-			fmt.Printf("Tool call loop detected: %s repeated %d times\n", 
-				toolCall.ToolName, l.toolCallRepetitionCount)
-		}
+		l.recordTelemetry(LoopTelemetryEvent{
+			LoopType:    gomini.LoopTypeToolCall,
+			Severity:    LoopTelemetryTriggered,
+			ToolName:    toolCall.ToolName,
+			RepeatCount: l.toolCallRepetitionCount,
+			Threshold:   TOOL_CALL_LOOP_THRESHOLD,
+		})
+		l.logger.Debug("tool call loop detected", slog.String("prompt_id", l.promptID),
+			slog.String("tool", toolCall.ToolName), slog.Int("repeats", l.toolCallRepetitionCount))
 		return true
 	}
+
+	if l.toolCallRepetitionCount >= nearMissCount(TOOL_CALL_LOOP_THRESHOLD) && !l.nearMissReported[key] {
+		l.nearMissReported[key] = true
+		l.recordTelemetry(LoopTelemetryEvent{
+			LoopType:    gomini.LoopTypeToolCall,
+			Severity:    LoopTelemetryNearMiss,
+			ToolName:    toolCall.ToolName,
+			RepeatCount: l.toolCallRepetitionCount,
+			Threshold:   TOOL_CALL_LOOP_THRESHOLD,
+		})
+	}
 	
 	return false
 }
@@ -176,7 +238,10 @@ func (l *LoopDetectionService) checkContentLoop(content string) bool {
 
 	wasInCodeBlock := l.inCodeBlock
 	l.inCodeBlock = (numFences%2 == 0) == l.inCodeBlock // Toggle if odd number of fences
-	if wasInCodeBlock || l.inCodeBlock || isDivider {
+	if wasInCodeBlock || l.inCodeBlock {
+		return l.config.LoopDetectionCodeAware && l.checkCodeLoop(content, wasInCodeBlock)
+	}
+	if isDivider {
 		return false
 	}
 
@@ -185,6 +250,79 @@ func (l *LoopDetectionService) checkContentLoop(content string) bool {
 	return l.analyzeContentChunksForLoop()
 }
 
+// checkCodeLoop applies line-based repetition detection to the parts of content
+// that fall inside code blocks. Code legitimately repeats short fragments, so
+// only whole lines are compared and lines without letters or digits are ignored.
+func (l *LoopDetectionService) checkCodeLoop(content string, inCode bool) bool {
+	for i, segment := range strings.Split(content, "```") {
+		if i > 0 {
+			// Each fence opens or closes a block
+			inCode = !inCode
+			l.resetCodeTracking()
+		}
+		if !inCode {
+			continue
+		}
+
+		l.codeLineBuffer += segment
+		for {
+			newline := strings.IndexByte(l.codeLineBuffer, '\n')
+			if newline < 0 {
+				break
+			}
+			line := strings.TrimSpace(l.codeLineBuffer[:newline])
+			l.codeLineBuffer = l.codeLineBuffer[newline+1:]
+			if l.addCodeLine(line) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addCodeLine records a complete code line and reports whether it is looping
+func (l *LoopDetectionService) addCodeLine(line string) bool {
+	if strings.IndexFunc(line, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return false
+	}
+
+	l.codeLines = append(l.codeLines, line)
+	if len(l.codeLines) > CODE_LINE_WINDOW {
+		l.codeLines = l.codeLines[len(l.codeLines)-CODE_LINE_WINDOW:]
+	}
+
+	repeats := 0
+	for _, previous := range l.codeLines {
+		if previous == line {
+			repeats++
+		}
+	}
+
+	if repeats >= CODE_LINE_LOOP_THRESHOLD {
+		l.recordCodeTelemetry(LoopTelemetryTriggered, repeats)
+		l.logger.Debug("code loop detected", slog.String("prompt_id", l.promptID),
+			slog.String("line", line), slog.Int("repeats", repeats))
+		return true
+	}
+
+	if repeats >= nearMissCount(CODE_LINE_LOOP_THRESHOLD) && !l.nearMissReported["code:"+line] {
+		l.nearMissReported["code:"+line] = true
+		l.recordCodeTelemetry(LoopTelemetryNearMiss, repeats)
+	}
+	return false
+}
+
+// recordCodeTelemetry records a content loop event found inside a code block
+func (l *LoopDetectionService) recordCodeTelemetry(severity LoopTelemetrySeverity, repeatCount int) {
+	l.recordTelemetry(LoopTelemetryEvent{
+		LoopType:    gomini.LoopTypeContent,
+		Severity:    severity,
+		RepeatCount: repeatCount,
+		Threshold:   CODE_LINE_LOOP_THRESHOLD,
+		CodeBlock:   true,
+	})
+}
+
 // truncateAndUpdate manages content history size
 func (l *LoopDetectionService) truncateAndUpdate() {
 	if len(l.streamContentHistory) <= MAX_HISTORY_LENGTH {
@@ -227,9 +365,8 @@ func (l *LoopDetectionService) analyzeContentChunksForLoop() bool {
 		chunkHash := l.hashChunk(currentChunk)
 
 		if l.isLoopDetectedForChunk(currentChunk, chunkHash) {
-			if l.config.Debug {
-				fmt.Printf("Content loop detected: chunk repeated %d+ times\n", CONTENT_LOOP_THRESHOLD)
-			}
+			l.logger.Debug("content loop detected", slog.String("prompt_id", l.promptID),
+				slog.Int("threshold", CONTENT_LOOP_THRESHOLD))
 			return true
 		}
 
@@ -269,16 +406,55 @@ func (l *LoopDetectionService) isLoopDetectedForChunk(chunk, hash string) bool {
 	l.contentStats[hash] = existingIndices
 
 	if len(existingIndices) < CONTENT_LOOP_THRESHOLD {
+		l.checkContentNearMiss(hash, existingIndices)
 		return false
 	}
 
 	// Analyze the most recent occurrences to see if they're clustered closely together
-	recentIndices := existingIndices[len(existingIndices)-CONTENT_LOOP_THRESHOLD:]
+	averageDistance, clustered := chunkClustering(existingIndices, CONTENT_LOOP_THRESHOLD)
+	if clustered {
+		l.recordContentTelemetry(LoopTelemetryTriggered, len(existingIndices), averageDistance)
+	}
+
+	return clustered
+}
+
+// checkContentNearMiss reports a chunk that is repeating close to the loop threshold
+func (l *LoopDetectionService) checkContentNearMiss(hash string, indices []int) {
+	nearMiss := nearMissCount(CONTENT_LOOP_THRESHOLD)
+	if len(indices) < nearMiss || l.nearMissReported[hash] {
+		return
+	}
+
+	if averageDistance, clustered := chunkClustering(indices, nearMiss); clustered {
+		l.nearMissReported[hash] = true
+		l.recordContentTelemetry(LoopTelemetryNearMiss, len(indices), averageDistance)
+	}
+}
+
+// recordContentTelemetry records a content loop event with chunk statistics
+func (l *LoopDetectionService) recordContentTelemetry(severity LoopTelemetrySeverity, repeatCount int, averageDistance float64) {
+	l.recordTelemetry(LoopTelemetryEvent{
+		LoopType:        gomini.LoopTypeContent,
+		Severity:        severity,
+		RepeatCount:     repeatCount,
+		Threshold:       CONTENT_LOOP_THRESHOLD,
+		ChunkSize:       CONTENT_CHUNK_SIZE,
+		DistinctChunks:  len(l.contentStats),
+		HistoryLength:   len(l.streamContentHistory),
+		AverageDistance: averageDistance,
+	})
+}
+
+// chunkClustering reports the average distance between the last window occurrences
+// and whether they are clustered closely enough to indicate a loop
+func chunkClustering(indices []int, window int) (float64, bool) {
+	recentIndices := indices[len(indices)-window:]
 	totalDistance := recentIndices[len(recentIndices)-1] - recentIndices[0]
-	averageDistance := float64(totalDistance) / float64(CONTENT_LOOP_THRESHOLD-1)
+	averageDistance := float64(totalDistance) / float64(window-1)
 	maxAllowedDistance := float64(CONTENT_CHUNK_SIZE) * 1.5
 
-	return averageDistance <= maxAllowedDistance
+	return averageDistance, averageDistance <= maxAllowedDistance
 }
 
 // isActualContentMatch verifies that two chunks with the same hash actually contain identical content
@@ -306,6 +482,12 @@ func (l *LoopDetectionService) resetContentTracking(resetHistory bool) {
 	l.lastContentIndex = 0
 }
 
+// resetCodeTracking clears line tracking for the current code block
+func (l *LoopDetectionService) resetCodeTracking() {
+	l.codeLineBuffer = ""
+	l.codeLines = nil
+}
+
 // resetLLMCheckTracking resets LLM-based loop tracking
 func (l *LoopDetectionService) resetLLMCheckTracking() {
 	l.turnsInCurrentPrompt = 0
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"gomini/pkg/gomini"
@@ -13,7 +14,7 @@ func TestLoopDetectionService_Reset(t *testing.T) {
 	
 	// Set some state by manually calling methods (fields are private)
 	service.Reset("initial-prompt")
-	service.TurnStarted(context.Background()) // Increment turn count
+	service.TurnStarted(context.Background(), nil) // Increment turn count
 	
 	// Add some content to trigger internal state
 	contentEvent := gomini.StreamEvent{
@@ -26,7 +27,7 @@ func TestLoopDetectionService_Reset(t *testing.T) {
 	service.Reset("new-prompt-id")
 	
 	// Test that reset worked by checking behavior
-	if !service.TurnStarted(context.Background()) == false {
+	if !service.TurnStarted(context.Background(), nil) == false {
 		// TurnStarted should return false (no loop detected) and increment internal counter
 	}
 	
@@ -107,7 +108,7 @@ func TestLoopDetectionService_TurnStarted(t *testing.T) {
 	ctx := context.Background()
 	
 	// First turn should not detect a loop
-	if service.TurnStarted(ctx) {
+	if service.TurnStarted(ctx, nil) {
 		t.Error("Expected no loop detection on first turn")
 	}
 	
@@ -117,7 +118,7 @@ func TestLoopDetectionService_TurnStarted(t *testing.T) {
 	
 	// Multiple turns should increment the counter
 	for i := 0; i < 5; i++ {
-		service.TurnStarted(ctx)
+		service.TurnStarted(ctx, nil)
 	}
 	
 	if service.turnsInCurrentPrompt != 6 {
@@ -208,4 +209,101 @@ func TestLoopDetectionService_CodeBlockHandling(t *testing.T) {
 	if service.AddAndCheck(codeBlockEnd) {
 		t.Error("Loop detected on code block end")
 	}
-}
\ No newline at end of file
+}
+
+func TestLoopDetectionService_CodeAwareLoop(t *testing.T) {
+	content := func(text string) gomini.StreamEvent {
+		return gomini.StreamEvent{
+			Type: gomini.EventContent,
+			Data: gomini.ContentEvent{Text: text, Delta: true},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		codeAware bool
+		chunks    []string
+		want      bool
+	}{
+		{
+			name:      "repeated print inside code block",
+			codeAware: true,
+			chunks:    append([]string{"```python\n"}, repeatChunks(CODE_LINE_LOOP_THRESHOLD, "print('hello')\n")...),
+			want:      true,
+		},
+		{
+			name:      "lines split across chunks",
+			codeAware: true,
+			chunks:    append([]string{"```python\n"}, repeatChunks(CODE_LINE_LOOP_THRESHOLD, "print(", "'hello')\n")...),
+			want:      true,
+		},
+		{
+			name:      "mode disabled",
+			codeAware: false,
+			chunks:    append([]string{"```python\n"}, repeatChunks(CODE_LINE_LOOP_THRESHOLD, "print('hello')\n")...),
+			want:      false,
+		},
+		{
+			name:      "punctuation-only lines",
+			codeAware: true,
+			chunks:    append([]string{"```go\n"}, repeatChunks(CODE_LINE_LOOP_THRESHOLD*2, "}\n")...),
+			want:      false,
+		},
+		{
+			name:      "repetition below threshold",
+			codeAware: true,
+			chunks:    append([]string{"```go\n"}, repeatChunks(CODE_LINE_LOOP_THRESHOLD-1, "x++\n")...),
+			want:      false,
+		},
+		{
+			name:      "distinct lines",
+			codeAware: true,
+			chunks:    append([]string{"```python\n"}, distinctLines(CODE_LINE_LOOP_THRESHOLD*2)...),
+			want:      false,
+		},
+		{
+			name:      "closing fence resets tracking",
+			codeAware: true,
+			chunks: append(append(append([]string{"```python\n"},
+				repeatChunks(CODE_LINE_LOOP_THRESHOLD-1, "print('hello')\n")...),
+				"```\n", "Again:\n", "```python\n"),
+				repeatChunks(CODE_LINE_LOOP_THRESHOLD-1, "print('hello')\n")...),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := gomini.NewConfig()
+			config.LoopDetectionCodeAware = tt.codeAware
+			service := NewLoopDetectionService(config)
+			service.Reset("test-prompt")
+
+			got := false
+			for _, chunk := range tt.chunks {
+				got = service.AddAndCheck(content(chunk))
+			}
+			if got != tt.want {
+				t.Errorf("AddAndCheck() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// repeatChunks repeats the given chunk sequence n times
+func repeatChunks(n int, sequence ...string) []string {
+	var chunks []string
+	for i := 0; i < n; i++ {
+		chunks = append(chunks, sequence...)
+	}
+	return chunks
+}
+
+// distinctLines returns n different lines of code
+func distinctLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("print(%d)\n", i)
+	}
+	return lines
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"container/list"
+	"log/slog"
+	"sync"
+
+	"gomini/pkg/gomini"
+)
+
+// MaxLoopDetectors bounds how many prompts keep loop detection state; the
+// least recently active prompt's state is dropped first
+const MaxLoopDetectors = 256
+
+// promptLoopState is the loop detector and turn count of one prompt
+type promptLoopState struct {
+	promptID string
+	detector *LoopDetectionService
+	turns    int
+}
+
+// loopDetectorPool keeps a loop detector per prompt ID so interleaved
+// prompts do not reset each other's state
+type loopDetectorPool struct {
+	mu        sync.Mutex
+	config    *gomini.Config
+	judge     LoopJudge
+	logger    *slog.Logger
+	telemetry LoopTelemetrySink
+	entries   map[string]*list.Element
+	order     *list.List           // Front is most recently used
+	retired   LoopDetectionMetrics // Counters of evicted detectors
+}
+
+// newLoopDetectorPool creates an empty pool
+func newLoopDetectorPool(config *gomini.Config) *loopDetectorPool {
+	return &loopDetectorPool{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the state of promptID, creating it and evicting the least
+// recently used prompt if needed
+func (p *loopDetectorPool) get(promptID string) *promptLoopState {
+	p.mu.Lock()
+	state, evicted := p.getLocked(promptID)
+	p.mu.Unlock()
+	flushDetectors(evicted)
+	return state
+}
+
+// getLocked implements get and returns the evicted detectors, whose queued
+// telemetry the caller flushes after unlocking; must hold p.mu
+func (p *loopDetectorPool) getLocked(promptID string) (*promptLoopState, []*LoopDetectionService) {
+	if elem, ok := p.entries[promptID]; ok {
+		p.order.MoveToFront(elem)
+		return elem.Value.(*promptLoopState), nil
+	}
+
+	var evicted []*LoopDetectionService
+	for p.order.Len() >= MaxLoopDetectors {
+		oldest := p.order.Back()
+		state := oldest.Value.(*promptLoopState)
+		p.retired = p.retired.add(state.detector.Metrics())
+		p.order.Remove(oldest)
+		delete(p.entries, state.promptID)
+		evicted = append(evicted, state.detector)
+	}
+
+	detector := NewLoopDetectionService(p.config)
+	detector.SetLoopJudge(p.judge)
+	if p.logger != nil {
+		detector.SetLogger(p.logger)
+	}
+	detector.SetTelemetrySink(p.telemetry)
+	detector.Reset(promptID)
+	state := &promptLoopState{promptID: promptID, detector: detector}
+	p.entries[promptID] = p.order.PushFront(state)
+	return state, evicted
+}
+
+// startTurn counts a new turn of promptID and returns its detector and turn number
+func (p *loopDetectorPool) startTurn(promptID string) (*LoopDetectionService, int) {
+	p.mu.Lock()
+	state, evicted := p.getLocked(promptID)
+	state.turns++
+	detector, turns := state.detector, state.turns
+	p.mu.Unlock()
+	flushDetectors(evicted)
+	return detector, turns
+}
+
+// eachLocked calls fn for every live detector; must hold p.mu
+func (p *loopDetectorPool) eachLocked(fn func(*LoopDetectionService)) {
+	for elem := p.order.Front(); elem != nil; elem = elem.Next() {
+		fn(elem.Value.(*promptLoopState).detector)
+	}
+}
+
+// setConfig swaps in a reloaded configuration
+func (p *loopDetectorPool) setConfig(config *gomini.Config) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = config
+	p.eachLocked(func(d *LoopDetectionService) { d.setConfig(config) })
+}
+
+// setJudge sets the LLM loop judge of current and future detectors
+func (p *loopDetectorPool) setJudge(judge LoopJudge) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.judge = judge
+	p.eachLocked(func(d *LoopDetectionService) { d.SetLoopJudge(judge) })
+}
+
+// setLogger sets the logger of current and future detectors
+func (p *loopDetectorPool) setLogger(logger *slog.Logger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logger = logger
+	p.eachLocked(func(d *LoopDetectionService) { d.SetLogger(logger) })
+}
+
+// setTelemetrySink sets the telemetry sink of current and future detectors
+func (p *loopDetectorPool) setTelemetrySink(sink LoopTelemetrySink) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.telemetry = sink
+	p.eachLocked(func(d *LoopDetectionService) { d.SetTelemetrySink(sink) })
+}
+
+// telemetrySink returns the configured telemetry sink, if any
+func (p *loopDetectorPool) telemetrySink() LoopTelemetrySink {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.telemetry
+}
+
+// flushTelemetry delivers events queued by any detector. Sinks run outside
+// the pool lock so they may read metrics.
+func (p *loopDetectorPool) flushTelemetry() {
+	p.mu.Lock()
+	var detectors []*LoopDetectionService
+	p.eachLocked(func(d *LoopDetectionService) { detectors = append(detectors, d) })
+	p.mu.Unlock()
+	flushDetectors(detectors)
+}
+
+// flushDetectors delivers the queued telemetry of each detector
+func flushDetectors(detectors []*LoopDetectionService) {
+	for _, detector := range detectors {
+		detector.flushTelemetry()
+	}
+}
+
+// metrics sums the counters of all detectors, including evicted ones
+func (p *loopDetectorPool) metrics() LoopDetectionMetrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	total := p.retired
+	p.eachLocked(func(d *LoopDetectionService) { total = total.add(d.Metrics()) })
+	return total
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"gomini/pkg/gomini"
+)
+
+func toolCallEvent(name string) gomini.StreamEvent {
+	return gomini.StreamEvent{
+		Type: gomini.EventToolCall,
+		Data: gomini.ToolCallEvent{ToolName: name, Arguments: map[string]interface{}{"path": "a.txt"}},
+	}
+}
+
+func TestLoopDetectorPool_InterleavedPrompts(t *testing.T) {
+	pool := newLoopDetectorPool(gomini.NewConfig())
+
+	// Prompt A repeats a tool call while prompt B makes progress in between
+	for i := 1; i < TOOL_CALL_LOOP_THRESHOLD; i++ {
+		a, _ := pool.startTurn("prompt-a")
+		if a.AddAndCheck(toolCallEvent("read_file")) {
+			t.Fatalf("unexpected loop on call %d", i)
+		}
+		b, _ := pool.startTurn("prompt-b")
+		b.AddAndCheck(toolCallEvent(fmt.Sprintf("tool_%d", i)))
+	}
+
+	a, turns := pool.startTurn("prompt-a")
+	if turns != TOOL_CALL_LOOP_THRESHOLD {
+		t.Errorf("expected prompt-a at turn %d, got %d", TOOL_CALL_LOOP_THRESHOLD, turns)
+	}
+	if !a.AddAndCheck(toolCallEvent("read_file")) {
+		t.Error("expected prompt-a's loop to be detected despite interleaving")
+	}
+	if pool.get("prompt-b").detector.IsLoopDetected() {
+		t.Error("expected prompt-b to be unaffected")
+	}
+	if metrics := pool.metrics(); metrics.ToolCallTriggers != 1 {
+		t.Errorf("expected 1 tool call trigger, got %+v", metrics)
+	}
+}
+
+func TestLoopDetectorPool_Eviction(t *testing.T) {
+	pool := newLoopDetectorPool(gomini.NewConfig())
+
+	looping, _ := pool.startTurn("looping")
+	for i := 0; i < TOOL_CALL_LOOP_THRESHOLD; i++ {
+		looping.AddAndCheck(toolCallEvent("read_file"))
+	}
+	for i := 0; i < MaxLoopDetectors; i++ {
+		pool.startTurn(fmt.Sprintf("prompt-%d", i))
+	}
+
+	if pool.order.Len() != MaxLoopDetectors {
+		t.Errorf("expected %d detectors, got %d", MaxLoopDetectors, pool.order.Len())
+	}
+	if _, ok := pool.entries["looping"]; ok {
+		t.Error("expected the least recently used prompt to be evicted")
+	}
+	if metrics := pool.metrics(); metrics.ToolCallTriggers != 1 {
+		t.Errorf("expected evicted counters to be kept, got %+v", metrics)
+	}
+
+	// An evicted prompt starts over
+	if _, turns := pool.startTurn("looping"); turns != 1 {
+		t.Errorf("expected an evicted prompt to restart at turn 1, got %d", turns)
+	}
+}
+
+func TestLoopDetectorPool_TelemetrySink(t *testing.T) {
+	pool := newLoopDetectorPool(gomini.NewConfig())
+	existing, _ := pool.startTurn("existing")
+
+	var events []LoopTelemetryEvent
+	pool.setTelemetrySink(LoopTelemetrySinkFunc(func(event LoopTelemetryEvent) {
+		events = append(events, event)
+	}))
+	created, _ := pool.startTurn("created")
+
+	for i := 0; i < TOOL_CALL_LOOP_THRESHOLD; i++ {
+		existing.AddAndCheck(toolCallEvent("read_file"))
+		created.AddAndCheck(toolCallEvent("write_file"))
+	}
+
+	triggered := map[string]bool{}
+	for _, event := range events {
+		if event.Severity == LoopTelemetryTriggered {
+			triggered[event.PromptID] = true
+		}
+	}
+	if !triggered["existing"] || !triggered["created"] {
+		t.Errorf("expected both prompts to report to the sink, got %+v", events)
+	}
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"gomini/pkg/gomini"
+)
+
+// LLM_LOOP_CONFIDENCE_THRESHOLD is the judge confidence above which a loop is reported
+const LLM_LOOP_CONFIDENCE_THRESHOLD = 0.9
+
+// loopJudgeSystemPrompt instructs the judge model, following the TypeScript implementation
+const loopJudgeSystemPrompt = `You are a sophisticated AI diagnostic agent specializing in identifying when a conversational AI is stuck in an unproductive state. Your task is to analyze the provided conversation history and determine if the assistant has ceased to make meaningful progress.
+
+An unproductive state is characterized by one or more of the following patterns over the last 5 or more assistant turns:
+
+Repetitive Actions: The assistant repeats the same tool calls or conversational responses a decent number of times. This includes simple loops (e.g., tool_A, tool_A, tool_A) and alternating patterns (e.g., tool_A, tool_B, tool_A, tool_B, ...).
+
+Cognitive Loop: The assistant seems unable to determine the next logical step. It might express confusion, repeatedly ask the same questions, or generate responses that don't logically follow from the previous turns, indicating it's stuck and not advancing the task.
+
+Crucially, differentiate between a true unproductive state and legitimate, incremental progress. For example, a series of edits to different parts of the same file or the same tool called with different arguments is forward progress and NOT a loop.`
+
+// loopJudgeTaskPrompt is appended to the history as the final user turn
+const loopJudgeTaskPrompt = `Analyze the conversation history above to determine if the assistant is stuck in an unproductive loop. Respond with your reasoning and a confidence between 0.0 (making progress) and 1.0 (certainly stuck).`
+
+// loopJudgeSchema is the JSON shape the judge answers with
+var loopJudgeSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"reasoning":  map[string]interface{}{"type": "string"},
+		"confidence": map[string]interface{}{"type": "number"},
+	},
+	"required": []interface{}{"reasoning", "confidence"},
+}
+
+// LoopJudge sends a loop check request to the judge model
+type LoopJudge func(ctx context.Context, request *gomini.JSONRequest) (*gomini.JSONResponse, error)
+
+// SetLoopJudge sets the function used for LLM-based loop checks. Checks also
+// require Config.LoopJudgeModel; Client sets a judge on creation.
+func (l *LoopDetectionService) SetLoopJudge(judge LoopJudge) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.judge = judge
+}
+
+// SetLogger sets the logger detections are reported to, slog.Default() until
+// set. Client sets its own logger.
+func (l *LoopDetectionService) SetLogger(logger *slog.Logger) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logger = logger
+}
+
+// checkForLoopWithLLM asks the judge whether the recent history shows a loop
+// and schedules the next check sooner the more confident it is
+func (l *LoopDetectionService) checkForLoopWithLLM(ctx context.Context, history []gomini.Message) bool {
+	l.mu.RLock()
+	judge, model, promptID, logger := l.judge, l.config.LoopJudgeModel, l.promptID, l.logger
+	l.mu.RUnlock()
+
+	messages := []gomini.Message{gomini.NewSystemMessage(loopJudgeSystemPrompt)}
+	messages = append(messages, recentLoopHistory(history)...)
+	messages = append(messages, gomini.NewUserMessage(loopJudgeTaskPrompt))
+
+	resp, err := judge(ctx, &gomini.JSONRequest{
+		Messages: messages,
+		Model:    model,
+		Schema:   loopJudgeSchema,
+	})
+	if err != nil {
+		// A failed check must not interrupt the conversation
+		logger.Warn("llm loop check failed", slog.String("error", err.Error()))
+		return false
+	}
+	confidence, _ := resp.Data["confidence"].(float64)
+	reasoning, _ := resp.Data["reasoning"].(string)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.promptID != promptID {
+		// The prompt was reset while the judge was running
+		return false
+	}
+
+	if confidence > LLM_LOOP_CONFIDENCE_THRESHOLD {
+		l.loopDetected = true
+		l.recordTelemetry(LoopTelemetryEvent{
+			LoopType:   gomini.LoopTypeLLMDetected,
+			Severity:   LoopTelemetryTriggered,
+			Confidence: confidence,
+			Reasoning:  reasoning,
+		})
+		logger.Debug("llm loop detected", slog.String("prompt_id", promptID),
+			slog.Float64("confidence", confidence), slog.String("reasoning", reasoning))
+		return true
+	}
+
+	l.llmCheckInterval = l.nextLLMCheckInterval(confidence)
+	return false
+}
+
+// nextLLMCheckInterval maps the judge's confidence to the number of turns
+// until the next check, jittered so concurrent prompts do not check in step;
+// must hold l.mu
+func (l *LoopDetectionService) nextLLMCheckInterval(confidence float64) int {
+	confidence = math.Max(0, math.Min(1, confidence))
+	interval := int(math.Round(MIN_LLM_CHECK_INTERVAL + (MAX_LLM_CHECK_INTERVAL-MIN_LLM_CHECK_INTERVAL)*(1-confidence)))
+	interval += l.jitter(DEFAULT_LLM_CHECK_INTERVAL) - DEFAULT_LLM_CHECK_INTERVAL/2
+	return min(MAX_LLM_CHECK_INTERVAL, max(MIN_LLM_CHECK_INTERVAL, interval))
+}
+
+// recentLoopHistory returns the last LLM_LOOP_CHECK_HISTORY_COUNT messages,
+// dropping leading tool results whose calls were cut off
+func recentLoopHistory(history []gomini.Message) []gomini.Message {
+	if len(history) > LLM_LOOP_CHECK_HISTORY_COUNT {
+		history = history[len(history)-LLM_LOOP_CHECK_HISTORY_COUNT:]
+	}
+	for len(history) > 0 {
+		msg, ok := history[0].(map[string]interface{})
+		if !ok || msg["role"] != "tool" {
+			break
+		}
+		history = history[1:]
+	}
+	return history
+}
+
+// judgeLoop runs a loop check on Config.LoopJudgeProvider, or on the current
+// provider when unset. It bypasses the cache, fallback and request logging.
+func (c *Client) judgeLoop(ctx context.Context, request *gomini.JSONRequest) (*gomini.JSONResponse, error) {
+	st, release := c.holdState()
+	defer release()
+	judgeProvider := st.config.LoopJudgeProvider
+	if judgeProvider == "" || judgeProvider == st.providerType {
+		return st.provider.GenerateJSON(ctx, request)
+	}
+	provider, err := st.providers.get(judgeProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create loop judge provider %s: %w", judgeProvider, err)
+	}
+	request.Provider = judgeProvider
+	return provider.GenerateJSON(ctx, request)
+}
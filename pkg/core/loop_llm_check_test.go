@@ -0,0 +1,182 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// newJudgedLoopService returns a service whose judge answers with confidence
+// and counts its calls; jitter is disabled
+func newJudgedLoopService(confidence float64, err error, calls *int) *LoopDetectionService {
+	config := gomini.NewConfig()
+	config.LoopJudgeModel = "judge-model"
+	service := NewLoopDetectionService(config)
+	service.jitter = func(n int) int { return n / 2 }
+	service.SetLoopJudge(func(ctx context.Context, request *gomini.JSONRequest) (*gomini.JSONResponse, error) {
+		*calls++
+		if err != nil {
+			return nil, err
+		}
+		return &gomini.JSONResponse{Data: map[string]interface{}{"confidence": confidence, "reasoning": "repeating"}}, nil
+	})
+	service.Reset("prompt")
+	return service
+}
+
+func TestLoopDetectionService_LLMCheckSchedule(t *testing.T) {
+	calls := 0
+	service := newJudgedLoopService(0, nil, &calls)
+	ctx := context.Background()
+
+	for turn := 1; turn < LLM_CHECK_AFTER_TURNS; turn++ {
+		service.TurnStarted(ctx, nil)
+	}
+	if calls != 0 {
+		t.Fatalf("expected no judge calls before turn %d, got %d", LLM_CHECK_AFTER_TURNS, calls)
+	}
+
+	if service.TurnStarted(ctx, nil) {
+		t.Fatal("expected no loop at zero confidence")
+	}
+	if calls != 1 {
+		t.Fatalf("expected a judge call at turn %d, got %d", LLM_CHECK_AFTER_TURNS, calls)
+	}
+
+	// Zero confidence backs off to the maximum interval
+	for i := 1; i < MAX_LLM_CHECK_INTERVAL; i++ {
+		service.TurnStarted(ctx, nil)
+	}
+	if calls != 1 {
+		t.Fatalf("expected no judge call within the interval, got %d", calls)
+	}
+	service.TurnStarted(ctx, nil)
+	if calls != 2 {
+		t.Errorf("expected a judge call after %d turns, got %d", MAX_LLM_CHECK_INTERVAL, calls)
+	}
+}
+
+func TestLoopDetectionService_LLMLoopDetected(t *testing.T) {
+	calls := 0
+	service := newJudgedLoopService(0.95, nil, &calls)
+	var events []LoopTelemetryEvent
+	service.SetTelemetrySink(LoopTelemetrySinkFunc(func(event LoopTelemetryEvent) {
+		events = append(events, event)
+	}))
+
+	history := []gomini.Message{gomini.NewUserMessage("fix the bug")}
+	detected := false
+	for turn := 1; turn <= LLM_CHECK_AFTER_TURNS && !detected; turn++ {
+		detected = service.TurnStarted(context.Background(), history)
+	}
+	if !detected || !service.IsLoopDetected() {
+		t.Fatal("expected an LLM-detected loop")
+	}
+	if len(events) != 1 || events[0].LoopType != gomini.LoopTypeLLMDetected || events[0].Confidence != 0.95 {
+		t.Errorf("expected one LLM telemetry event, got %+v", events)
+	}
+	if service.Metrics().LLMTriggers != 1 {
+		t.Errorf("expected 1 LLM trigger, got %+v", service.Metrics())
+	}
+}
+
+func TestLoopDetectionService_LLMJudgeError(t *testing.T) {
+	calls := 0
+	service := newJudgedLoopService(0, errors.New("judge unavailable"), &calls)
+	var logs bytes.Buffer
+	service.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	for turn := 1; turn <= LLM_CHECK_AFTER_TURNS; turn++ {
+		if service.TurnStarted(context.Background(), nil) {
+			t.Fatal("expected a failed judge call not to report a loop")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected one judge call, got %d", calls)
+	}
+	if !strings.Contains(logs.String(), "llm loop check failed") || !strings.Contains(logs.String(), "judge unavailable") {
+		t.Errorf("expected the failure on the service's logger, got %q", logs.String())
+	}
+}
+
+func TestLoopDetectionService_NextLLMCheckInterval(t *testing.T) {
+	service := NewLoopDetectionService(gomini.NewConfig())
+
+	tests := []struct {
+		confidence float64
+		jitter     int
+		want       int
+	}{
+		{confidence: 0, jitter: 1, want: MAX_LLM_CHECK_INTERVAL},
+		{confidence: 0.5, jitter: 1, want: 10},
+		{confidence: 0.5, jitter: 0, want: 9},
+		{confidence: 0.5, jitter: 2, want: 11},
+		{confidence: 0, jitter: 2, want: MAX_LLM_CHECK_INTERVAL},
+		{confidence: 1, jitter: 0, want: MIN_LLM_CHECK_INTERVAL},
+	}
+	for _, tt := range tests {
+		service.jitter = func(int) int { return tt.jitter }
+		if got := service.nextLLMCheckInterval(tt.confidence); got != tt.want {
+			t.Errorf("confidence %.1f, jitter %d: expected %d, got %d", tt.confidence, tt.jitter, tt.want, got)
+		}
+	}
+}
+
+func TestRecentLoopHistory(t *testing.T) {
+	var history []gomini.Message
+	for i := 0; i < LLM_LOOP_CHECK_HISTORY_COUNT; i++ {
+		history = append(history, gomini.NewUserMessage("question"))
+	}
+	history = append(history, gomini.NewToolResultMessage("call_1", "read_file", "data"))
+
+	recent := recentLoopHistory(history)
+	if len(recent) != LLM_LOOP_CHECK_HISTORY_COUNT {
+		t.Errorf("expected %d messages, got %d", LLM_LOOP_CHECK_HISTORY_COUNT, len(recent))
+	}
+
+	orphaned := []gomini.Message{
+		gomini.NewToolResultMessage("call_1", "read_file", "data"),
+		gomini.NewUserMessage("next"),
+	}
+	if recent := recentLoopHistory(orphaned); len(recent) != 1 {
+		t.Errorf("expected the leading tool result to be dropped, got %d messages", len(recent))
+	}
+}
+
+func TestClient_LLMLoopDetectionStream(t *testing.T) {
+	client := newShutdownClient(t)
+	client.currentState().config.LoopDetectionEnabled = true
+	client.currentState().config.LoopJudgeModel = "judge-model"
+	mockProvider := &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		jsonData:     map[string]interface{}{"confidence": 0.99, "reasoning": "asking the same question"},
+		responses:    []gomini.StreamEvent{{Type: gomini.EventFinished}},
+	}
+	useProvider(client, mockProvider)
+
+	// Continue a prompt that is one turn short of the first check
+	client.loopDetectors.get("long-prompt").detector.turnsInCurrentPrompt = LLM_CHECK_AFTER_TURNS - 1
+
+	var loop *gomini.LoopDetectedEvent
+	for event := range client.SendMessageStream(context.Background(), &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("try again")},
+	}, "long-prompt") {
+		if event.Type == gomini.EventLoopDetected {
+			data := event.Data.(gomini.LoopDetectedEvent)
+			loop = &data
+		}
+	}
+
+	if loop == nil || loop.LoopType != gomini.LoopTypeLLMDetected {
+		t.Fatalf("expected an LLM-detected loop event, got %+v", loop)
+	}
+	if mockProvider.lastJSON == nil || mockProvider.lastJSON.Model != "judge-model" {
+		t.Errorf("expected the judge model to be asked, got %+v", mockProvider.lastJSON)
+	}
+}
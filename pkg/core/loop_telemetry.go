@@ -0,0 +1,191 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"time"
+
+	"gomini/pkg/gomini"
+)
+
+// LOOP_NEAR_MISS_RATIO is the fraction of a loop threshold at which a near miss is reported
+const LOOP_NEAR_MISS_RATIO = 0.8
+
+// LoopTelemetrySeverity distinguishes triggered loops from near misses
+type LoopTelemetrySeverity string
+
+const (
+	LoopTelemetryTriggered LoopTelemetrySeverity = "triggered"
+	LoopTelemetryNearMiss  LoopTelemetrySeverity = "near_miss"
+)
+
+// LoopTelemetryEvent is a structured record emitted when loop detection triggers or nearly triggers
+type LoopTelemetryEvent struct {
+	PromptID    string                `json:"prompt_id"`
+	LoopType    gomini.LoopType       `json:"loop_type"`
+	Severity    LoopTelemetrySeverity `json:"severity"`
+	RepeatCount int                   `json:"repeat_count"`
+	Threshold   int                   `json:"threshold"`
+	Turn        int                   `json:"turn"`
+	Timestamp   time.Time             `json:"timestamp"`
+
+	// Tool call loops
+	ToolName string `json:"tool_name,omitempty"`
+
+	// Content loops
+	ChunkSize       int     `json:"chunk_size,omitempty"`
+	DistinctChunks  int     `json:"distinct_chunks,omitempty"`
+	HistoryLength   int     `json:"history_length,omitempty"`
+	AverageDistance float64 `json:"average_distance,omitempty"`
+	CodeBlock       bool    `json:"code_block,omitempty"`
+
+	// LLM-detected loops
+	Confidence float64 `json:"confidence,omitempty"`
+	Reasoning  string  `json:"reasoning,omitempty"`
+}
+
+// LoopTelemetrySink receives loop detection telemetry
+type LoopTelemetrySink interface {
+	RecordLoopTelemetry(event LoopTelemetryEvent)
+}
+
+// LoopTelemetrySinkFunc adapts a function to LoopTelemetrySink
+type LoopTelemetrySinkFunc func(event LoopTelemetryEvent)
+
+// RecordLoopTelemetry implements LoopTelemetrySink
+func (f LoopTelemetrySinkFunc) RecordLoopTelemetry(event LoopTelemetryEvent) {
+	f(event)
+}
+
+// NewSlogLoopTelemetrySink logs loop telemetry as structured log records
+func NewSlogLoopTelemetrySink(logger *slog.Logger) LoopTelemetrySink {
+	return LoopTelemetrySinkFunc(func(event LoopTelemetryEvent) {
+		level := slog.LevelInfo
+		if event.Severity == LoopTelemetryTriggered {
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("prompt_id", event.PromptID),
+			slog.String("loop_type", string(event.LoopType)),
+			slog.String("severity", string(event.Severity)),
+			slog.Int("repeat_count", event.RepeatCount),
+			slog.Int("threshold", event.Threshold),
+			slog.Int("turn", event.Turn),
+		}
+		if event.ToolName != "" {
+			attrs = append(attrs, slog.String("tool_name", event.ToolName))
+		}
+		if event.LoopType == gomini.LoopTypeContent {
+			attrs = append(attrs,
+				slog.Int("chunk_size", event.ChunkSize),
+				slog.Int("distinct_chunks", event.DistinctChunks),
+				slog.Int("history_length", event.HistoryLength),
+				slog.Float64("average_distance", event.AverageDistance),
+			)
+		}
+
+		if event.LoopType == gomini.LoopTypeLLMDetected {
+			attrs = append(attrs,
+				slog.Float64("confidence", event.Confidence),
+				slog.String("reasoning", event.Reasoning),
+			)
+		}
+
+		logger.LogAttrs(context.Background(), level, "loop detection", attrs...)
+	})
+}
+
+// LoopDetectionMetrics holds cumulative loop detection counters
+type LoopDetectionMetrics struct {
+	ToolCallTriggers   int64 `json:"tool_call_triggers"`
+	ToolCallNearMisses int64 `json:"tool_call_near_misses"`
+	ContentTriggers    int64 `json:"content_triggers"`
+	ContentNearMisses  int64 `json:"content_near_misses"`
+	LLMTriggers        int64 `json:"llm_triggers"`
+}
+
+// add returns the sum of two sets of counters
+func (m LoopDetectionMetrics) add(other LoopDetectionMetrics) LoopDetectionMetrics {
+	return LoopDetectionMetrics{
+		ToolCallTriggers:   m.ToolCallTriggers + other.ToolCallTriggers,
+		ToolCallNearMisses: m.ToolCallNearMisses + other.ToolCallNearMisses,
+		ContentTriggers:    m.ContentTriggers + other.ContentTriggers,
+		ContentNearMisses:  m.ContentNearMisses + other.ContentNearMisses,
+		LLMTriggers:        m.LLMTriggers + other.LLMTriggers,
+	}
+}
+
+// nearMissCount returns the repeat count at which a near miss is reported
+func nearMissCount(threshold int) int {
+	return int(math.Ceil(float64(threshold) * LOOP_NEAR_MISS_RATIO))
+}
+
+// SetTelemetrySink sets the sink that receives loop telemetry events
+func (l *LoopDetectionService) SetTelemetrySink(sink LoopTelemetrySink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.telemetry = sink
+}
+
+// telemetrySink returns the configured telemetry sink, if any
+func (l *LoopDetectionService) telemetrySink() LoopTelemetrySink {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.telemetry
+}
+
+// Metrics returns a snapshot of the loop detection counters
+func (l *LoopDetectionService) Metrics() LoopDetectionMetrics {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.metrics
+}
+
+// recordTelemetry updates counters and queues an event for dispatch; must hold l.mu
+func (l *LoopDetectionService) recordTelemetry(event LoopTelemetryEvent) {
+	event.PromptID = l.promptID
+	event.Turn = l.turnsInCurrentPrompt
+	event.Timestamp = time.Now()
+
+	switch {
+	case event.LoopType == gomini.LoopTypeLLMDetected:
+		l.metrics.LLMTriggers++
+	case event.LoopType == gomini.LoopTypeToolCall && event.Severity == LoopTelemetryTriggered:
+		l.metrics.ToolCallTriggers++
+	case event.LoopType == gomini.LoopTypeToolCall:
+		l.metrics.ToolCallNearMisses++
+	case event.Severity == LoopTelemetryTriggered:
+		l.metrics.ContentTriggers++
+	default:
+		l.metrics.ContentNearMisses++
+	}
+
+	if l.telemetry != nil {
+		l.pendingTelemetry = append(l.pendingTelemetry, event)
+	}
+}
+
+// flushTelemetry delivers queued events outside the lock so sinks may call back into the service
+func (l *LoopDetectionService) flushTelemetry() {
+	l.mu.Lock()
+	pending := l.pendingTelemetry
+	sink := l.telemetry
+	l.pendingTelemetry = nil
+	l.mu.Unlock()
+
+	for _, event := range pending {
+		sink.RecordLoopTelemetry(event)
+	}
+}
+
+// SetLoopTelemetrySink sets the sink that receives loop detection telemetry
+func (c *Client) SetLoopTelemetrySink(sink LoopTelemetrySink) {
+	c.loopDetectors.setTelemetrySink(sink)
+}
+
+// LoopDetectionMetrics returns loop detection counters summed over all prompts
+func (c *Client) LoopDetectionMetrics() LoopDetectionMetrics {
+	return c.loopDetectors.metrics()
+}
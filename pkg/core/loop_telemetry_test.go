@@ -0,0 +1,113 @@
+package core
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini"
+)
+
+func TestLoopDetectionService_ToolCallTelemetry(t *testing.T) {
+	service := NewLoopDetectionService(gomini.NewConfig())
+	service.Reset("telemetry-prompt")
+
+	var events []LoopTelemetryEvent
+	service.SetTelemetrySink(LoopTelemetrySinkFunc(func(event LoopTelemetryEvent) {
+		// Sinks run outside the lock and may query the service
+		service.IsLoopDetected()
+		events = append(events, event)
+	}))
+
+	toolCallEvent := gomini.StreamEvent{
+		Type: gomini.EventToolCall,
+		Data: gomini.ToolCallEvent{
+			ToolName:  "read_file",
+			Arguments: map[string]interface{}{"path": "main.go"},
+		},
+	}
+	for i := 0; i < TOOL_CALL_LOOP_THRESHOLD; i++ {
+		service.AddAndCheck(toolCallEvent)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected near miss and trigger events, got %d", len(events))
+	}
+
+	nearMiss := events[0]
+	if nearMiss.Severity != LoopTelemetryNearMiss || nearMiss.RepeatCount != nearMissCount(TOOL_CALL_LOOP_THRESHOLD) {
+		t.Errorf("unexpected near miss event: %+v", nearMiss)
+	}
+	if nearMiss.ToolName != "read_file" || nearMiss.PromptID != "telemetry-prompt" {
+		t.Errorf("expected tool name and prompt ID, got %+v", nearMiss)
+	}
+
+	triggered := events[1]
+	if triggered.Severity != LoopTelemetryTriggered || triggered.RepeatCount != TOOL_CALL_LOOP_THRESHOLD {
+		t.Errorf("unexpected trigger event: %+v", triggered)
+	}
+
+	metrics := service.Metrics()
+	if metrics.ToolCallNearMisses != 1 || metrics.ToolCallTriggers != 1 {
+		t.Errorf("unexpected metrics: %+v", metrics)
+	}
+}
+
+func TestLoopDetectionService_ContentTelemetry(t *testing.T) {
+	service := NewLoopDetectionService(gomini.NewConfig())
+	service.Reset("content-prompt")
+
+	var events []LoopTelemetryEvent
+	service.SetTelemetrySink(LoopTelemetrySinkFunc(func(event LoopTelemetryEvent) {
+		events = append(events, event)
+	}))
+
+	repeatingText := strings.Repeat("a", CONTENT_CHUNK_SIZE)
+	for i := 0; i < CONTENT_LOOP_THRESHOLD*2 && !service.IsLoopDetected(); i++ {
+		service.AddAndCheck(gomini.StreamEvent{
+			Type: gomini.EventContent,
+			Data: gomini.ContentEvent{Text: repeatingText, Delta: true},
+		})
+	}
+
+	if !service.IsLoopDetected() {
+		t.Fatal("expected content loop to be detected")
+	}
+	if len(events) < 2 {
+		t.Fatalf("expected near miss and trigger events, got %d", len(events))
+	}
+
+	if events[0].Severity != LoopTelemetryNearMiss || events[0].LoopType != gomini.LoopTypeContent {
+		t.Errorf("expected content near miss first, got %+v", events[0])
+	}
+	last := events[len(events)-1]
+	if last.Severity != LoopTelemetryTriggered || last.ChunkSize != CONTENT_CHUNK_SIZE || last.HistoryLength == 0 {
+		t.Errorf("unexpected trigger event: %+v", last)
+	}
+
+	metrics := service.Metrics()
+	if metrics.ContentTriggers != 1 || metrics.ContentNearMisses == 0 {
+		t.Errorf("unexpected metrics: %+v", metrics)
+	}
+}
+
+func TestSlogLoopTelemetrySink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewSlogLoopTelemetrySink(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	sink.RecordLoopTelemetry(LoopTelemetryEvent{
+		LoopType:    gomini.LoopTypeToolCall,
+		Severity:    LoopTelemetryTriggered,
+		ToolName:    "search",
+		RepeatCount: 5,
+		Threshold:   5,
+	})
+
+	output := buf.String()
+	for _, want := range []string{`"level":"WARN"`, `"tool_name":"search"`, `"repeat_count":5`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %s in log output: %s", want, output)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"strings"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// TagModelAlias is the request tag naming the model alias a request used
+const TagModelAlias = "model_alias"
+
+// applyModelAlias replaces a Config.ModelAliases name in request.Model with
+// the model it stands for. An alias naming a provider overrides the
+// request's. Aliases do not refer to other aliases.
+func (c *Client) applyModelAlias(st *clientState, request *gomini.ChatRequest) *gomini.ChatRequest {
+	alias, ok := st.config.ModelAliases[request.Model]
+	if !ok {
+		return request
+	}
+
+	aliased := *request
+	aliased.Model = alias.Model
+	if alias.Provider != "" {
+		aliased.Provider = alias.Provider
+	}
+	aliased.Tags = mergeTags(request.Tags, map[string]string{TagModelAlias: request.Model})
+	return &aliased
+}
+
+// resolveSnapshot replaces a model ending in "@latest" with the newest dated
+// snapshot in the catalog of st's provider, or with the bare model name when
+// the catalog has none or cannot be listed
+func (c *Client) resolveSnapshot(ctx context.Context, st *clientState, request *gomini.ChatRequest) *gomini.ChatRequest {
+	if !strings.HasSuffix(request.Model, providers.LatestSnapshotSuffix) {
+		return request
+	}
+	resolved := *request
+	resolved.Model, _ = latestSnapshot(ctx, st.provider, request.Model)
+	return &resolved
+}
+
+// ResolveModel reports the provider and model a request for name would use,
+// following Config.ModelAliases and "@latest" snapshots
+func (c *Client) ResolveModel(ctx context.Context, name string) (ModelRef, error) {
+	st, release := c.holdState()
+	defer release()
+
+	request := c.applyModelAlias(st, &gomini.ChatRequest{Model: name})
+	ref := ModelRef{Provider: request.Provider, Model: request.Model}
+	if ref.Provider == "" {
+		ref.Provider = st.providerType
+	}
+	if !strings.HasSuffix(ref.Model, providers.LatestSnapshotSuffix) {
+		return ref, nil
+	}
+
+	provider, err := st.providers.get(ref.Provider)
+	if err != nil {
+		return ModelRef{}, err
+	}
+	ref.Model, err = latestSnapshot(ctx, provider, ref.Model)
+	return ref, err
+}
+
+// latestSnapshot resolves an "@latest" model against provider's catalog. The
+// bare model name is returned along with any error listing the catalog.
+func latestSnapshot(ctx context.Context, provider providers.LLMProvider, model string) (string, error) {
+	base := strings.TrimSuffix(model, providers.LatestSnapshotSuffix)
+	if provider == nil {
+		return base, nil
+	}
+	models, err := provider.ListModels(ctx)
+	if err != nil {
+		return base, err
+	}
+	if snapshot, ok := providers.LatestSnapshot(models, base); ok {
+		return snapshot, nil
+	}
+	return base, nil
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestClient_ModelAliases(t *testing.T) {
+	client, mockProvider := newGenerateTestClient(t, nil)
+	mockProvider.models = []gomini.Model{
+		{ID: "gpt-4o"},
+		{ID: "gpt-4o-2024-08-06"},
+		{ID: "gpt-4o-2024-11-20"},
+		{ID: "gpt-4o-mini"},
+	}
+	client.currentState().config.ModelAliases = map[string]gomini.ModelAlias{
+		"fast":  {Model: "gpt-4o-mini"},
+		"smart": {Provider: providers.ProviderOpenAI, Model: "gpt-4o@latest"},
+	}
+
+	tests := []struct {
+		model string
+		want  string
+		alias string
+	}{
+		{model: "fast", want: "gpt-4o-mini", alias: "fast"},
+		{model: "smart", want: "gpt-4o-2024-11-20", alias: "smart"},
+		{model: "gpt-4o@latest", want: "gpt-4o-2024-11-20"},
+		{model: "gpt-4o-mini@latest", want: "gpt-4o-mini"},
+		{model: "gpt-4o", want: "gpt-4o"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			_, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+				Model:    tt.model,
+				Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+			})
+			if err != nil {
+				t.Fatalf("SendMessage failed: %v", err)
+			}
+			sent := mockProvider.lastRequest
+			if sent.Model != tt.want {
+				t.Errorf("Expected model %s, got %s", tt.want, sent.Model)
+			}
+			if sent.Tags[TagModelAlias] != tt.alias {
+				t.Errorf("Expected alias tag %q, got %q", tt.alias, sent.Tags[TagModelAlias])
+			}
+
+			ref, err := client.ResolveModel(context.Background(), tt.model)
+			if err != nil {
+				t.Fatalf("ResolveModel failed: %v", err)
+			}
+			if ref.Model != tt.want || ref.Provider != providers.ProviderOpenAI {
+				t.Errorf("Expected ResolveModel to report openai/%s, got %s", tt.want, ref)
+			}
+		})
+	}
+}
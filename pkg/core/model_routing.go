@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// modelPrefixes name the provider serving models missing from every catalog
+var modelPrefixes = []struct {
+	prefix   string
+	provider providers.ProviderType
+}{
+	{"gemini-", providers.ProviderGemini},
+	{"models/", providers.ProviderGemini},
+	{"imagen-", providers.ProviderGemini},
+	{"gpt-", providers.ProviderOpenAI},
+	{"chatgpt-", providers.ProviderOpenAI},
+	{"o1", providers.ProviderOpenAI},
+	{"o3", providers.ProviderOpenAI},
+	{"o4", providers.ProviderOpenAI},
+	{"dall-e-", providers.ProviderOpenAI},
+	{"tts-", providers.ProviderOpenAI},
+	{"whisper-", providers.ProviderOpenAI},
+	{"text-embedding-", providers.ProviderOpenAI},
+	{"deepseek-", providers.ProviderDeepSeek},
+}
+
+// routeByModel sets the provider of a request that names a model but no
+// provider, per Router.DisableModelRouting. Requests the current provider
+// can serve are left alone.
+func (c *Client) routeByModel(ctx context.Context, st *clientState, request *gomini.ChatRequest) *gomini.ChatRequest {
+	router := st.config.Router
+	if request.Provider != "" || request.Model == "" || (router != nil && router.DisableModelRouting) {
+		return request
+	}
+	providerType, ok := c.modelProvider(ctx, st, request.Model)
+	if !ok || providerType == st.providerType {
+		return request
+	}
+
+	routed := *request
+	routed.Provider = providerType
+	return &routed
+}
+
+// modelProvider picks the enabled provider for model: its ModelPreferences
+// entry, the current provider if its catalog lists the model, the first
+// other provider whose catalog does, or the provider its name prefix belongs to
+func (c *Client) modelProvider(ctx context.Context, st *clientState, model string) (providers.ProviderType, bool) {
+	if router := st.config.Router; router != nil {
+		if preferred, ok := router.ModelPreferences[model]; ok && st.config.HasProvider(preferred) {
+			return preferred, true
+		}
+	}
+	if models, err := st.provider.ListModels(ctx); err == nil {
+		if _, ok := providers.FindModel(models, model); ok {
+			return st.providerType, true
+		}
+	}
+	if listed := c.modelIndex(ctx, st)[model]; len(listed) > 0 {
+		return listed[0], true
+	}
+	for _, entry := range modelPrefixes {
+		if strings.HasPrefix(model, entry.prefix) && st.config.HasProvider(entry.provider) {
+			return entry.provider, true
+		}
+	}
+	return "", false
+}
+
+// modelIndex maps the model IDs in the catalogs of the enabled providers to
+// the providers listing them, in name order. It is built once per config;
+// providers that fail to list are left out and retried on the next call.
+func (c *Client) modelIndex(ctx context.Context, st *clientState) map[string][]providers.ProviderType {
+	c.modelIndexMu.Lock()
+	defer c.modelIndexMu.Unlock()
+	if c.modelProviders != nil {
+		return c.modelProviders
+	}
+
+	providerTypes := st.config.GetEnabledProviders()
+	sort.Slice(providerTypes, func(i, j int) bool { return providerTypes[i] < providerTypes[j] })
+
+	index := make(map[string][]providers.ProviderType)
+	complete := true
+	for _, providerType := range providerTypes {
+		provider, err := st.providers.get(providerType)
+		if err != nil {
+			complete = false
+			continue
+		}
+		models, err := provider.ListModels(ctx)
+		if err != nil {
+			complete = false
+			continue
+		}
+		for _, model := range models {
+			if listed := index[model.ID]; len(listed) == 0 || listed[len(listed)-1] != providerType {
+				index[model.ID] = append(listed, providerType)
+			}
+		}
+	}
+	if complete {
+		c.modelProviders = index
+	}
+	return index
+}
+
+// resetModelIndex drops the model index, e.g. after a config reload
+func (c *Client) resetModelIndex() {
+	c.modelIndexMu.Lock()
+	c.modelProviders = nil
+	c.modelIndexMu.Unlock()
+}
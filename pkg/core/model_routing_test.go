@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestClient_RouteByModel(t *testing.T) {
+	config := gomini.NewConfig()
+	for _, providerType := range []providers.ProviderType{providers.ProviderOpenAI, providers.ProviderGemini, providers.ProviderGroq} {
+		config.Providers[providerType] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key"}
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	useProvider(client, &MockProvider{providerType: providers.ProviderOpenAI, models: []gomini.Model{{ID: "gpt-4o"}}})
+	gemini := &MockProvider{providerType: providers.ProviderGemini, models: []gomini.Model{{ID: "gemini-2.0-flash"}}}
+	groq := &MockProvider{providerType: providers.ProviderGroq, models: []gomini.Model{{ID: "llama-3.3-70b"}}}
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		if providerType == providers.ProviderGemini {
+			return gemini, nil
+		}
+		return groq, nil
+	}
+
+	tests := []struct {
+		model string
+		want  providers.ProviderType
+	}{
+		{"gpt-4o", ""},
+		{"llama-3.3-70b", providers.ProviderGroq},
+		{"gemini-2.0-flash", providers.ProviderGemini},
+		{"gemini-2.5-pro", providers.ProviderGemini},
+		{"gpt-4.1", ""},
+		{"deepseek-chat", ""},
+		{"unknown-model", ""},
+	}
+	for _, tt := range tests {
+		routed := client.routeByModel(context.Background(), client.currentState(), &gomini.ChatRequest{Model: tt.model})
+		if routed.Provider != tt.want {
+			t.Errorf("Expected %s to route to %q, got %q", tt.model, tt.want, routed.Provider)
+		}
+	}
+
+	client.currentState().config.Router.DisableModelRouting = true
+	if routed := client.routeByModel(context.Background(), client.currentState(), &gomini.ChatRequest{Model: "gemini-2.0-flash"}); routed.Provider != "" {
+		t.Errorf("Expected no routing when disabled, got %q", routed.Provider)
+	}
+	client.currentState().config.Router.DisableModelRouting = false
+
+	if _, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+		Model:    "gemini-2.0-flash",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	}); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if gemini.lastRequest == nil {
+		t.Error("Expected the request to be sent to gemini")
+	}
+	if client.GetCurrentProviderType() != providers.ProviderOpenAI {
+		t.Errorf("Expected the active provider to be unchanged, got %s", client.GetCurrentProviderType())
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// ModelListing is a model of an enabled provider with its effective price
+type ModelListing struct {
+	gomini.Model
+	Price  gomini.ModelPrice `json:"price"`
+	Priced bool              `json:"priced"` // False when no price is known for the model
+}
+
+// ListAllModels lists the models of every enabled provider, sorted by provider
+// and model ID, priced like ModelPrice. Providers that cannot be created or
+// listed are skipped and their errors joined into the returned error.
+func (c *Client) ListAllModels(ctx context.Context) ([]ModelListing, error) {
+	st, release := c.holdState()
+	defer release()
+	return c.listAllModels(ctx, st)
+}
+
+// listAllModels lists the models of the providers enabled in st
+func (c *Client) listAllModels(ctx context.Context, st *clientState) ([]ModelListing, error) {
+	providerTypes := st.config.GetEnabledProviders()
+	sort.Slice(providerTypes, func(i, j int) bool { return providerTypes[i] < providerTypes[j] })
+
+	var listings []ModelListing
+	var errs []error
+	for _, providerType := range providerTypes {
+		provider, err := st.providers.get(providerType)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", providerType, err))
+			continue
+		}
+		models, err := provider.ListModels(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", providerType, err))
+			continue
+		}
+
+		sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+		for _, model := range models {
+			if model.Provider == "" {
+				model.Provider = providerType
+			}
+			listing := ModelListing{Model: model}
+			// Overrides and the price table first, then this provider's catalog
+			listing.Price, listing.Priced = c.modelPrice(ctx, st, nil, model.ID)
+			if !listing.Priced && model.Cost != nil {
+				listing.Price, listing.Priced = providers.PriceFromCost(model.Cost), true
+			}
+			listings = append(listings, listing)
+		}
+	}
+	return listings, errors.Join(errs...)
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestClient_ListAllModels(t *testing.T) {
+	client := newShutdownClient(t)
+	client.currentState().config.PriceOverrides = map[string]gomini.ModelPrice{"b-model": {Input: 9, Output: 18}}
+	useProvider(client, &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		models: []gomini.Model{
+			{ID: "c-model"},
+			{ID: "b-model", Cost: &providers.ModelCost{InputTokens: 1, OutputTokens: 2}},
+			{ID: "a-model", Cost: &providers.ModelCost{InputTokens: 0.5, OutputTokens: 1}},
+		},
+	})
+
+	listings, err := client.ListAllModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllModels() error = %v", err)
+	}
+
+	tests := []struct {
+		id     string
+		price  gomini.ModelPrice
+		priced bool
+	}{
+		{id: "a-model", price: gomini.ModelPrice{Input: 0.5, Output: 1}, priced: true},
+		{id: "b-model", price: gomini.ModelPrice{Input: 9, Output: 18}, priced: true},
+		{id: "c-model"},
+	}
+	if len(listings) != len(tests) {
+		t.Fatalf("Expected %d models, got %d", len(tests), len(listings))
+	}
+	for i, tt := range tests {
+		got := listings[i]
+		if got.ID != tt.id || got.Provider != providers.ProviderOpenAI {
+			t.Errorf("Listing %d: expected openai/%s, got %s/%s", i, tt.id, got.Provider, got.ID)
+		}
+		if got.Priced != tt.priced || got.Price != tt.price {
+			t.Errorf("%s: expected price %+v (priced %v), got %+v (priced %v)", tt.id, tt.price, tt.priced, got.Price, got.Priced)
+		}
+	}
+}
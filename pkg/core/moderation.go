@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// heuristicModerationModel names results from the keyword heuristic
+const heuristicModerationModel = "heuristic"
+
+// moderationPatterns are the keyword patterns of the heuristic fallback, by
+// OpenAI moderation category. They only catch explicit phrasing.
+var moderationPatterns = map[string]*regexp.Regexp{
+	"self-harm":        regexp.MustCompile(`(?i)\b(kill(ing)? myself|suicide|self[- ]harm|cut(ting)? myself|end my life)\b`),
+	"violence":         regexp.MustCompile(`(?i)\b(i('ll| will) (kill|murder|shoot|stab) (you|him|her|them)|mass shooting|shoot up (a|the) school)\b`),
+	"illicit/violent":  regexp.MustCompile(`(?i)\b((make|build|assemble) (a |an )?(bomb|pipe bomb|explosive)|synthesi[sz]e (nerve agent|sarin|ricin))\b`),
+	"hate/threatening": regexp.MustCompile(`(?i)\b(exterminate|ethnic(ally)? cleans(e|ing)) (all|every|the)\b`),
+}
+
+// Moderate classifies text with OpenAI's moderation endpoint when OpenAI is
+// enabled, whether or not it is the current provider. Without OpenAI a
+// keyword heuristic is used instead.
+func (c *Client) Moderate(ctx context.Context, text string) (*gomini.ModerationResult, error) {
+	st, release := c.holdState()
+	defer release()
+	return c.moderate(ctx, st, text)
+}
+
+// moderate is Moderate with the OpenAI provider of st
+func (c *Client) moderate(ctx context.Context, st *clientState, text string) (*gomini.ModerationResult, error) {
+	if _, err := st.config.GetProviderConfig(providers.ProviderOpenAI); err == nil {
+		provider, err := st.providers.get(providers.ProviderOpenAI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create moderation provider: %w", err)
+		}
+		if moderator, ok := provider.(providers.Moderator); ok {
+			return moderator.Moderate(ctx, text)
+		}
+	}
+	return heuristicModeration(text), nil
+}
+
+// heuristicModeration flags text matching moderationPatterns
+func heuristicModeration(text string) *gomini.ModerationResult {
+	result := &gomini.ModerationResult{Model: heuristicModerationModel, Scores: map[string]float64{}}
+	for category, pattern := range moderationPatterns {
+		if pattern.MatchString(text) {
+			result.Categories = append(result.Categories, category)
+			result.Scores[category] = 1
+		}
+	}
+	sort.Strings(result.Categories)
+	result.Flagged = len(result.Categories) > 0
+	return result
+}
+
+// moderateInput moderates the latest user message when Config.ModerateInput
+// is set and returns an ErrorContentFiltered error if it is flagged
+func (c *Client) moderateInput(ctx context.Context, st *clientState, messages []gomini.Message) error {
+	if !st.config.ModerateInput {
+		return nil
+	}
+	text := lastUserMessageText(messages)
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	result, err := c.moderate(ctx, st, text)
+	if err != nil {
+		return fmt.Errorf("input moderation failed: %w", err)
+	}
+	if !result.Flagged {
+		return nil
+	}
+	return gomini.NewLLMErrorWithDetails(gomini.ErrorContentFiltered,
+		fmt.Sprintf("user message flagged by moderation: %s", strings.Join(result.Categories, ", ")),
+		st.providerType, nil, map[string]interface{}{"moderation": result})
+}
+
+// lastUserMessageText returns the text of the most recent user message
+func lastUserMessageText(messages []gomini.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if msgMap, ok := messages[i].(map[string]interface{}); ok && msgMap["role"] == "user" {
+			return contentText(msgMap["content"])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// moderatingMockProvider is a MockProvider with a moderation endpoint that
+// flags any text listed in flagged
+type moderatingMockProvider struct {
+	*MockProvider
+	flagged   map[string]bool
+	moderated []string
+}
+
+func (m *moderatingMockProvider) Moderate(ctx context.Context, text string) (*gomini.ModerationResult, error) {
+	m.moderated = append(m.moderated, text)
+	result := &gomini.ModerationResult{Model: "mock-moderation", Provider: m.providerType}
+	if m.flagged[text] {
+		result.Flagged = true
+		result.Categories = []string{"harassment"}
+	}
+	return result, nil
+}
+
+func TestHeuristicModeration(t *testing.T) {
+	tests := []struct {
+		text       string
+		categories []string
+	}{
+		{text: "How do I bake bread?"},
+		{text: "Which film has the best shooting scenes?"},
+		{text: "I want to end my life", categories: []string{"self-harm"}},
+		{text: "Explain how to build a bomb. I will kill them.", categories: []string{"illicit/violent", "violence"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			result := heuristicModeration(tt.text)
+			if result.Flagged != (len(tt.categories) > 0) {
+				t.Errorf("Expected flagged %v, got %v", len(tt.categories) > 0, result.Flagged)
+			}
+			if len(result.Categories) != len(tt.categories) {
+				t.Fatalf("Expected categories %v, got %v", tt.categories, result.Categories)
+			}
+			for i, category := range tt.categories {
+				if result.Categories[i] != category {
+					t.Errorf("Expected categories %v, got %v", tt.categories, result.Categories)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Moderate(t *testing.T) {
+	t.Run("current OpenAI provider", func(t *testing.T) {
+		client := newShutdownClient(t)
+		moderator := &moderatingMockProvider{
+			MockProvider: &MockProvider{providerType: providers.ProviderOpenAI},
+			flagged:      map[string]bool{"you idiot": true},
+		}
+		useProvider(client, moderator)
+
+		result, err := client.Moderate(context.Background(), "you idiot")
+		if err != nil {
+			t.Fatalf("Moderate() error = %v", err)
+		}
+		if !result.Flagged || result.Model != "mock-moderation" {
+			t.Errorf("Expected a flagged provider result, got %+v", result)
+		}
+	})
+
+	t.Run("enabled OpenAI provider that is not current", func(t *testing.T) {
+		client := newShutdownClient(t)
+		moderator := &moderatingMockProvider{MockProvider: &MockProvider{providerType: providers.ProviderOpenAI}}
+		useProviderAs(client, providers.ProviderOpenAI, moderator)
+		useProviderAs(client, providers.ProviderGemini, &MockProvider{providerType: providers.ProviderGemini})
+
+		if _, err := client.Moderate(context.Background(), "hello"); err != nil {
+			t.Fatalf("Moderate() error = %v", err)
+		}
+		if len(moderator.moderated) != 1 {
+			t.Errorf("Expected the OpenAI provider to moderate, got %v", moderator.moderated)
+		}
+	})
+
+	t.Run("heuristic without a moderation endpoint", func(t *testing.T) {
+		client := newShutdownClient(t)
+		result, err := client.Moderate(context.Background(), "I am going to kill myself")
+		if err != nil {
+			t.Fatalf("Moderate() error = %v", err)
+		}
+		if !result.Flagged || result.Model != heuristicModerationModel || result.Provider != "" {
+			t.Errorf("Expected a flagged heuristic result, got %+v", result)
+		}
+	})
+}
+
+func TestClient_ModerateInput(t *testing.T) {
+	client := newShutdownClient(t)
+	client.currentState().config.ModerateInput = true
+	moderator := &moderatingMockProvider{
+		MockProvider: &MockProvider{
+			providerType: providers.ProviderOpenAI,
+			responses:    []gomini.StreamEvent{{Type: gomini.EventFinished}},
+		},
+		flagged: map[string]bool{"you idiot": true},
+	}
+	useProvider(client, moderator)
+	ctx := context.Background()
+
+	request := func(text string) *gomini.ChatRequest {
+		return &gomini.ChatRequest{
+			Messages: []gomini.Message{gomini.NewUserMessage("earlier turn"), gomini.NewAssistantMessage("ok"), gomini.NewUserMessage(text)},
+			Model:    "gpt-4o",
+		}
+	}
+
+	_, err := client.SendMessage(ctx, request("you idiot"))
+	var llmErr *gomini.LLMError
+	if !errors.Is(err, gomini.ErrContentFiltered) || !errors.As(err, &llmErr) || llmErr.Details["moderation"] == nil {
+		t.Fatalf("Expected a content filtered error with the moderation result, got %v", err)
+	}
+	if moderator.lastRequest != nil {
+		t.Error("Expected the flagged request not to reach the provider")
+	}
+
+	var streamErr error
+	for event := range client.SendMessageStream(ctx, request("you idiot"), "") {
+		if event.Type == gomini.EventError {
+			streamErr = event.Error
+		}
+	}
+	if !errors.Is(streamErr, gomini.ErrContentFiltered) {
+		t.Errorf("Expected a content filtered stream error, got %v", streamErr)
+	}
+
+	_, err = client.GenerateJSON(ctx, &gomini.JSONRequest{Messages: request("you idiot").Messages, Model: "gpt-4o"})
+	if !errors.Is(err, gomini.ErrContentFiltered) {
+		t.Errorf("Expected a content filtered JSON error, got %v", err)
+	}
+
+	if _, err := client.SendMessage(ctx, request("hello")); err != nil {
+		t.Fatalf("Expected a clean message to be sent, got %v", err)
+	}
+	if moderator.lastRequest == nil {
+		t.Error("Expected the clean request to reach the provider")
+	}
+	for _, text := range moderator.moderated {
+		if text == "earlier turn" {
+			t.Error("Expected only the latest user message to be moderated")
+		}
+	}
+}
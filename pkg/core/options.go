@@ -0,0 +1,220 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// SendOption customizes a request built by Client.Send and Client.SendJSON
+type SendOption func(*sendOptions)
+
+type sendOptions struct {
+	request gomini.ChatRequest
+	config  map[string]interface{}
+	schema  map[string]interface{}
+}
+
+// WithModel selects the model
+func WithModel(model string) SendOption {
+	return func(o *sendOptions) { o.request.Model = model }
+}
+
+// WithProvider pins the request to a provider, disabling fallback
+func WithProvider(provider gomini.ProviderType) SendOption {
+	return func(o *sendOptions) { o.request.Provider = provider }
+}
+
+// WithTools offers tools to the model; repeated calls add to the list
+func WithTools(tools ...gomini.Tool) SendOption {
+	return func(o *sendOptions) { o.request.Tools = append(o.request.Tools, tools...) }
+}
+
+// WithToolChoice sets the tool choice: "auto", "none", "required", or a
+// tool name (or gomini.ForceTool) to force that tool
+func WithToolChoice(choice interface{}) SendOption {
+	return func(o *sendOptions) { o.request.ToolChoice = choice }
+}
+
+// WithParallelToolCalls allows or forbids several tool calls in one turn on
+// OpenAI-compatible providers
+func WithParallelToolCalls(enabled bool) SendOption {
+	return WithConfig(providers.ConfigParallelToolCalls, enabled)
+}
+
+// WithPersona applies the named Config.Personas preset
+func WithPersona(name string) SendOption {
+	return func(o *sendOptions) { o.request.Persona = name }
+}
+
+// WithDisabledTools withholds the named tools for this request
+func WithDisabledTools(names ...string) SendOption {
+	return func(o *sendOptions) { o.request.DisabledTools = append(o.request.DisabledTools, names...) }
+}
+
+// WithThinking enables thinking mode with the given token budget
+func WithThinking(budget int) SendOption {
+	return WithConfig("thinking_config", map[string]interface{}{
+		"include_thoughts": true,
+		"thinking_budget":  budget,
+	})
+}
+
+// WithReasoning sets the reasoning effort or thinking budget portably; it
+// maps to reasoning_effort on OpenAI and the thinking config on Gemini
+func WithReasoning(reasoning gomini.Reasoning) SendOption {
+	return WithConfig(providers.ConfigReasoning, reasoning)
+}
+
+// WithSafetySettings overrides the provider's safety settings for this request
+func WithSafetySettings(settings ...gomini.SafetySetting) SendOption {
+	return WithConfig("safety_settings", settings)
+}
+
+// WithTemperature sets the sampling temperature
+func WithTemperature(temperature float64) SendOption {
+	return WithConfig("temperature", temperature)
+}
+
+// WithMaxTokens caps the number of output tokens
+func WithMaxTokens(maxTokens int) SendOption {
+	return WithConfig("max_output_tokens", maxTokens)
+}
+
+// WithStop ends generation at the first of the given sequences, which are
+// left out of the output
+func WithStop(sequences ...string) SendOption {
+	return WithConfig(providers.ConfigStop, sequences)
+}
+
+// WithFrequencyPenalty discourages tokens in proportion to how often they
+// have appeared so far; OpenAI accepts -2.0 to 2.0
+func WithFrequencyPenalty(penalty float64) SendOption {
+	return WithConfig(providers.ConfigFrequencyPenalty, penalty)
+}
+
+// WithPresencePenalty discourages any token that has already appeared;
+// OpenAI accepts -2.0 to 2.0
+func WithPresencePenalty(penalty float64) SendOption {
+	return WithConfig(providers.ConfigPresencePenalty, penalty)
+}
+
+// WithSeed asks for best-effort deterministic sampling; pair it with
+// ChatResponse.SystemFingerprint to tell when a backend change breaks
+// reproducibility. Providers without seed support fail with
+// ErrorUnsupportedFeature.
+func WithSeed(seed int) SendOption {
+	return WithConfig(providers.ConfigSeed, seed)
+}
+
+// WithLogprobs asks for the log probability of every generated token and,
+// when topLogprobs is positive, that many likeliest alternatives per token.
+// They arrive on Choice.Logprobs and streamed ContentEvent.Logprobs; only
+// OpenAI-compatible providers return them.
+func WithLogprobs(topLogprobs int) SendOption {
+	return func(o *sendOptions) {
+		o.config[providers.ConfigLogprobs] = true
+		if topLogprobs > 0 {
+			o.config[providers.ConfigTopLogprobs] = topLogprobs
+		}
+	}
+}
+
+// WithTags attaches usage attribution tags; repeated calls merge
+func WithTags(tags map[string]string) SendOption {
+	return func(o *sendOptions) {
+		if o.request.Tags == nil {
+			o.request.Tags = make(map[string]string, len(tags))
+		}
+		for key, value := range tags {
+			o.request.Tags[key] = value
+		}
+	}
+}
+
+// WithJSONSchema requests structured output matching schema
+func WithJSONSchema(schema map[string]interface{}) SendOption {
+	return func(o *sendOptions) { o.schema = schema }
+}
+
+// WithConfig sets a raw request config key for settings without a dedicated option
+func WithConfig(key string, value interface{}) SendOption {
+	return func(o *sendOptions) { o.config[key] = value }
+}
+
+// buildSendOptions applies opts over the given messages
+func buildSendOptions(messages []gomini.Message, opts []SendOption) *sendOptions {
+	o := &sendOptions{
+		request: gomini.ChatRequest{Messages: messages},
+		config:  make(map[string]interface{}),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if len(o.config) > 0 {
+		o.request.Config = o.config
+	}
+	return o
+}
+
+// NewChatRequest builds a ChatRequest from messages and options
+func NewChatRequest(messages []gomini.Message, opts ...SendOption) *gomini.ChatRequest {
+	return &buildSendOptions(messages, opts).request
+}
+
+// Send sends messages configured by opts. With WithJSONSchema the request is
+// made through GenerateJSON and the choice message holds the JSON document as
+// its content.
+func (c *Client) Send(ctx context.Context, messages []gomini.Message, opts ...SendOption) (*gomini.ChatResponse, error) {
+	o := buildSendOptions(messages, opts)
+	if o.schema == nil {
+		return c.SendMessage(ctx, &o.request)
+	}
+
+	resp, err := c.GenerateJSON(ctx, o.jsonRequest())
+	if err != nil {
+		return nil, err
+	}
+	content, err := json.Marshal(resp.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON response: %w", err)
+	}
+
+	return &gomini.ChatResponse{
+		ID:       resp.ID,
+		Model:    resp.Model,
+		Provider: resp.Provider,
+		Choices: []gomini.Choice{{
+			Message:      gomini.NewAssistantMessage(string(content)),
+			FinishReason: providers.FinishReasonStop,
+		}},
+		Usage:    resp.Usage,
+		Created:  resp.Created,
+		Metadata: resp.Metadata,
+	}, nil
+}
+
+// SendJSON sends messages configured by opts and returns structured output.
+// A schema must be given with WithJSONSchema.
+func (c *Client) SendJSON(ctx context.Context, messages []gomini.Message, opts ...SendOption) (*gomini.JSONResponse, error) {
+	o := buildSendOptions(messages, opts)
+	if o.schema == nil {
+		return nil, fmt.Errorf("SendJSON requires a schema (use WithJSONSchema)")
+	}
+	return c.GenerateJSON(ctx, o.jsonRequest())
+}
+
+// jsonRequest converts the built chat request into a JSONRequest
+func (o *sendOptions) jsonRequest() *gomini.JSONRequest {
+	return &gomini.JSONRequest{
+		Messages: o.request.Messages,
+		Model:    o.request.Model,
+		Provider: o.request.Provider,
+		Schema:   o.schema,
+		Config:   o.request.Config,
+		Tags:     o.request.Tags,
+	}
+}
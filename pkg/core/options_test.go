@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestNewChatRequest(t *testing.T) {
+	messages := []gomini.Message{gomini.NewUserMessage("hi")}
+	tool := gomini.FunctionTool{Name: "lookup"}
+	safety := gomini.SafetySetting{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_NONE"}
+
+	tests := []struct {
+		name string
+		opts []SendOption
+		want gomini.ChatRequest
+	}{
+		{
+			name: "no options",
+			want: gomini.ChatRequest{Messages: messages},
+		},
+		{
+			name: "model, provider and tools",
+			opts: []SendOption{WithModel("gpt-4o"), WithProvider(gomini.ProviderOpenAI), WithTools(tool), WithToolChoice("auto")},
+			want: gomini.ChatRequest{
+				Messages:   messages,
+				Model:      "gpt-4o",
+				Provider:   gomini.ProviderOpenAI,
+				Tools:      []gomini.Tool{tool},
+				ToolChoice: "auto",
+			},
+		},
+		{
+			name: "config options",
+			opts: []SendOption{WithThinking(1024), WithSafetySettings(safety), WithTemperature(0.2), WithMaxTokens(256)},
+			want: gomini.ChatRequest{
+				Messages: messages,
+				Config: map[string]interface{}{
+					"thinking_config":   map[string]interface{}{"include_thoughts": true, "thinking_budget": 1024},
+					"safety_settings":   []gomini.SafetySetting{safety},
+					"temperature":       0.2,
+					"max_output_tokens": 256,
+				},
+			},
+		},
+		{
+			name: "logprobs",
+			opts: []SendOption{WithLogprobs(3)},
+			want: gomini.ChatRequest{
+				Messages: messages,
+				Config:   map[string]interface{}{"logprobs": true, "top_logprobs": 3},
+			},
+		},
+		{
+			name: "stop and penalties",
+			opts: []SendOption{WithStop("\n\n", "END"), WithFrequencyPenalty(0.5), WithPresencePenalty(-0.25)},
+			want: gomini.ChatRequest{
+				Messages: messages,
+				Config: map[string]interface{}{
+					"stop":              []string{"\n\n", "END"},
+					"frequency_penalty": 0.5,
+					"presence_penalty":  -0.25,
+				},
+			},
+		},
+		{
+			name: "parallel tool calls",
+			opts: []SendOption{WithParallelToolCalls(false)},
+			want: gomini.ChatRequest{
+				Messages: messages,
+				Config:   map[string]interface{}{"parallel_tool_calls": false},
+			},
+		},
+		{
+			name: "persona",
+			opts: []SendOption{WithPersona("support")},
+			want: gomini.ChatRequest{Messages: messages, Persona: "support"},
+		},
+		{
+			name: "seed",
+			opts: []SendOption{WithSeed(42)},
+			want: gomini.ChatRequest{
+				Messages: messages,
+				Config:   map[string]interface{}{"seed": 42},
+			},
+		},
+		{
+			name: "tags merge",
+			opts: []SendOption{WithTags(map[string]string{"team": "search"}), WithTags(map[string]string{"feature": "faq"})},
+			want: gomini.ChatRequest{
+				Messages: messages,
+				Tags:     map[string]string{"team": "search", "feature": "faq"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewChatRequest(messages, tt.opts...); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("NewChatRequest() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Send(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	mockProvider := &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		jsonData:     map[string]interface{}{"answer": "42"},
+	}
+	useProvider(client, mockProvider)
+
+	messages := []gomini.Message{gomini.NewUserMessage("hi")}
+	if _, err := client.Send(context.Background(), messages, WithModel("gpt-4o"), WithTemperature(0)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if mockProvider.lastRequest.Model != "gpt-4o" || mockProvider.lastRequest.Config.(map[string]interface{})["temperature"] != 0.0 {
+		t.Errorf("Unexpected request %+v", mockProvider.lastRequest)
+	}
+
+	schema := map[string]interface{}{"type": "object"}
+	resp, err := client.Send(context.Background(), messages, WithModel("gpt-4o"), WithJSONSchema(schema))
+	if err != nil {
+		t.Fatalf("Send with schema failed: %v", err)
+	}
+	if mockProvider.lastJSON == nil || mockProvider.lastJSON.Model != "gpt-4o" {
+		t.Fatalf("Expected a JSON request, got %+v", mockProvider.lastJSON)
+	}
+	if content := resp.Choices[0].Content(); content != `{"answer":"42"}` {
+		t.Errorf("Unexpected JSON content %v", content)
+	}
+
+	if _, err := client.SendJSON(context.Background(), messages, WithModel("gpt-4o")); err == nil {
+		t.Error("Expected SendJSON to require a schema")
+	}
+}
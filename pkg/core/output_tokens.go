@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// outputClamp records a max output tokens setting lowered to the model limit
+type outputClamp struct {
+	model     string
+	requested int
+	limit     int
+}
+
+// event reports the clamp as a warning debug event
+func (o *outputClamp) event(st *clientState) gomini.StreamEvent {
+	event := gomini.NewDebugEvent(st.providerType, "warn", o.message(),
+		map[string]interface{}{"requested_output_tokens": o.requested, "max_output_tokens": o.limit})
+	event.Model = o.model
+	return event
+}
+
+func (o *outputClamp) message() string {
+	return fmt.Sprintf("max output tokens %d exceeds the %d-token limit of %s", o.requested, o.limit, o.model)
+}
+
+// clampOutputTokens lowers a max output tokens setting above the model's
+// catalog limit to that limit, or rejects the request with
+// ErrorInvalidParameters when Config.StrictOutputTokens is set. Models
+// missing from the catalog, or without a known limit, are left for the
+// provider to judge.
+func (c *Client) clampOutputTokens(ctx context.Context, st *clientState, request *gomini.ChatRequest) (*gomini.ChatRequest, *outputClamp, error) {
+	requested, ok := providers.MaxOutputTokensFromConfig(request.Config)
+	if !ok {
+		return request, nil, nil
+	}
+	models, err := st.provider.ListModels(ctx)
+	if err != nil {
+		return request, nil, nil
+	}
+	model, ok := providers.FindModel(models, request.Model)
+	if !ok || model.MaxOutputTokens <= 0 || requested <= model.MaxOutputTokens {
+		return request, nil, nil
+	}
+
+	clamp := &outputClamp{model: request.Model, requested: requested, limit: model.MaxOutputTokens}
+	if st.config.StrictOutputTokens {
+		llmErr := gomini.NewLLMError(gomini.ErrorInvalidParameters, clamp.message(), st.providerType, nil)
+		llmErr.Model = request.Model
+		llmErr.Details = map[string]interface{}{"max_output_tokens": model.MaxOutputTokens}
+		return nil, nil, llmErr
+	}
+
+	config := make(map[string]interface{})
+	for key, value := range request.Config.(map[string]interface{}) {
+		config[key] = value
+	}
+	delete(config, providers.ConfigMaxTokens)
+	config[providers.ConfigMaxOutputTokens] = model.MaxOutputTokens
+
+	clamped := *request
+	clamped.Config = config
+	return &clamped, clamp, nil
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestClient_ClampOutputTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   string
+		config  map[string]interface{}
+		strict  bool
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "within limit", model: "small", config: map[string]interface{}{"max_output_tokens": 1000}, want: 1000},
+		{name: "clamped", model: "small", config: map[string]interface{}{"max_output_tokens": 9000}, want: 4096},
+		{name: "clamped openai key", model: "small", config: map[string]interface{}{"max_tokens": 9000}, want: 4096},
+		{name: "unknown limit", model: "unlimited", config: map[string]interface{}{"max_output_tokens": 9000}, want: 9000},
+		{name: "strict", model: "small", config: map[string]interface{}{"max_output_tokens": 9000}, strict: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mockProvider := newGenerateTestClient(t, nil)
+			mockProvider.models = []gomini.Model{{ID: "small", MaxOutputTokens: 4096}, {ID: "unlimited"}}
+			client.currentState().config.StrictOutputTokens = tt.strict
+
+			request := &gomini.ChatRequest{Model: tt.model, Config: tt.config}
+			shaped, _, err := client.clampOutputTokens(context.Background(), client.currentState(), request)
+			if tt.wantErr {
+				if !errors.Is(err, gomini.ErrInvalidParameters) {
+					t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("clampOutputTokens failed: %v", err)
+			}
+			if got, _ := providers.MaxOutputTokensFromConfig(shaped.Config); got != tt.want {
+				t.Errorf("Expected max output tokens %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClient_SendMessageStream_ClampEvent(t *testing.T) {
+	client, mockProvider := newGenerateTestClient(t, nil)
+	mockProvider.models = []gomini.Model{{ID: "small", MaxOutputTokens: 4096}}
+
+	var clamped bool
+	for event := range client.SendMessageStream(context.Background(), &gomini.ChatRequest{
+		Model:    "small",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+		Config:   map[string]interface{}{"max_output_tokens": 9000},
+	}, "") {
+		if debug, ok := event.Data.(gomini.DebugEvent); ok && debug.Data["max_output_tokens"] == 4096 {
+			clamped = true
+		}
+	}
+	if !clamped {
+		t.Error("Expected a debug event reporting the clamp")
+	}
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// outputFeedback is sent after rejected output to ask for a corrected answer
+const outputFeedback = "Your previous answer was rejected: %v. Reply again with an answer that fixes this, without commentary."
+
+// OutputValidator checks a response before SendMessage returns it. It may
+// rewrite the response in place, e.g. with SetResponseText; returning an
+// error rejects the output.
+type OutputValidator interface {
+	ValidateOutput(resp *gomini.ChatResponse) error
+}
+
+// OutputValidatorFunc adapts a function to OutputValidator
+type OutputValidatorFunc func(resp *gomini.ChatResponse) error
+
+// ValidateOutput implements OutputValidator
+func (f OutputValidatorFunc) ValidateOutput(resp *gomini.ChatResponse) error {
+	return f(resp)
+}
+
+// AddOutputValidator registers validators that run, in order, on every
+// SendMessage response. Rejected output is retried once with the validation
+// error as feedback; if the retry is rejected too, SendMessage fails with
+// ErrorValidation. Streams are not validated.
+func (c *Client) AddOutputValidator(validators ...OutputValidator) {
+	c.validatorsMu.Lock()
+	defer c.validatorsMu.Unlock()
+	c.validators = append(c.validators, validators...)
+}
+
+// validateOutput runs the output validators on resp, retrying rejected
+// output once. The returned response carries the usage of both attempts.
+func (c *Client) validateOutput(ctx context.Context, st *clientState, request *gomini.ChatRequest, resp *gomini.ChatResponse) (*gomini.ChatResponse, error) {
+	c.validatorsMu.RLock()
+	validators := c.validators
+	c.validatorsMu.RUnlock()
+	if len(validators) == 0 {
+		return resp, nil
+	}
+
+	rejection := runOutputValidators(validators, resp)
+	if rejection == nil {
+		return resp, nil
+	}
+
+	rejected, _, _ := responseText(resp)
+	retry := *request
+	retry.Messages = append(append([]gomini.Message{}, request.Messages...),
+		gomini.NewAssistantMessage(rejected),
+		gomini.NewUserMessage(fmt.Sprintf(outputFeedback, rejection)),
+	)
+	retryResp, _, err := c.sendMessage(ctx, st, &retry)
+	if err != nil {
+		return nil, err
+	}
+	retryResp.Usage = addUsage(resp.Usage, retryResp.Usage)
+
+	if err := runOutputValidators(validators, retryResp); err != nil {
+		llmErr := gomini.NewLLMError(gomini.ErrorValidation,
+			fmt.Sprintf("output rejected after retry: %v", err), retryResp.Provider, err)
+		llmErr.Model = retryResp.Model
+		return nil, llmErr
+	}
+	return retryResp, nil
+}
+
+// runOutputValidators returns the first rejection
+func runOutputValidators(validators []OutputValidator, resp *gomini.ChatResponse) error {
+	for _, validator := range validators {
+		if err := validator.ValidateOutput(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetResponseText replaces the text of a response's first choice
+func SetResponseText(resp *gomini.ChatResponse, text string) error {
+	if len(resp.Choices) == 0 {
+		return fmt.Errorf("no choices in response")
+	}
+	resp.Choices[0].SetContent(text)
+	return nil
+}
+
+// MaxLengthValidator rejects output longer than maxChars characters
+func MaxLengthValidator(maxChars int) OutputValidator {
+	return OutputValidatorFunc(func(resp *gomini.ChatResponse) error {
+		text, _, err := responseText(resp)
+		if err != nil {
+			return err
+		}
+		if length := utf8.RuneCountInString(text); length > maxChars {
+			return fmt.Errorf("answer is %d characters long, the limit is %d", length, maxChars)
+		}
+		return nil
+	})
+}
+
+// BannedPhraseValidator rejects output containing any of the phrases, ignoring case
+func BannedPhraseValidator(phrases ...string) OutputValidator {
+	return OutputValidatorFunc(func(resp *gomini.ChatResponse) error {
+		text, _, err := responseText(resp)
+		if err != nil {
+			return err
+		}
+		lower := strings.ToLower(text)
+		for _, phrase := range phrases {
+			if phrase != "" && strings.Contains(lower, strings.ToLower(phrase)) {
+				return fmt.Errorf("answer contains the banned phrase %q", phrase)
+			}
+		}
+		return nil
+	})
+}
+
+// RedactPhraseValidator rewrites output, replacing each of the phrases,
+// ignoring case, with replacement. It never rejects.
+func RedactPhraseValidator(replacement string, phrases ...string) OutputValidator {
+	patterns := make([]*regexp.Regexp, 0, len(phrases))
+	for _, phrase := range phrases {
+		if phrase != "" {
+			patterns = append(patterns, regexp.MustCompile("(?i)"+regexp.QuoteMeta(phrase)))
+		}
+	}
+	return OutputValidatorFunc(func(resp *gomini.ChatResponse) error {
+		text, _, err := responseText(resp)
+		if err != nil {
+			return nil
+		}
+		redacted := text
+		for _, pattern := range patterns {
+			redacted = pattern.ReplaceAllLiteralString(redacted, replacement)
+		}
+		if redacted != text {
+			return SetResponseText(resp, redacted)
+		}
+		return nil
+	})
+}
+
+// JSONFieldsValidator rejects output that is not a JSON object with all of
+// the given top-level fields. Code fences and minor syntax slips are repaired.
+func JSONFieldsValidator(fields ...string) OutputValidator {
+	return OutputValidatorFunc(func(resp *gomini.ChatResponse) error {
+		text, _, err := responseText(resp)
+		if err != nil {
+			return err
+		}
+		var object map[string]interface{}
+		if _, err := providers.UnmarshalJSONWithRepair(text, &object); err != nil || object == nil {
+			return fmt.Errorf("answer is not a JSON object")
+		}
+		var missing []string
+		for _, field := range fields {
+			if _, ok := object[field]; !ok {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("answer is missing the JSON fields %s", strings.Join(missing, ", "))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// scriptedChatProvider answers successive SendMessage calls with replies in order
+type scriptedChatProvider struct {
+	*MockProvider
+	replies  []string
+	requests []*gomini.ChatRequest
+}
+
+func (s *scriptedChatProvider) SendMessage(ctx context.Context, request *gomini.ChatRequest) (*gomini.ChatResponse, error) {
+	s.requests = append(s.requests, request)
+	reply := s.replies[min(len(s.requests), len(s.replies))-1]
+	return &gomini.ChatResponse{
+		Provider: s.providerType,
+		Model:    request.Model,
+		Choices:  []gomini.Choice{{Message: gomini.NewAssistantMessage(reply)}},
+		Usage:    &gomini.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+	}, nil
+}
+
+func TestClient_OutputValidators(t *testing.T) {
+	tests := []struct {
+		name       string
+		validators []OutputValidator
+		replies    []string
+		wantText   string
+		wantCalls  int
+		wantErr    bool
+	}{
+		{
+			name:       "accepted output",
+			validators: []OutputValidator{MaxLengthValidator(10)},
+			replies:    []string{"short"},
+			wantText:   "short",
+			wantCalls:  1,
+		},
+		{
+			name:       "rejected then fixed",
+			validators: []OutputValidator{BannedPhraseValidator("As an AI")},
+			replies:    []string{"as an ai model, no", "Sure."},
+			wantText:   "Sure.",
+			wantCalls:  2,
+		},
+		{
+			name:       "rejected twice",
+			validators: []OutputValidator{MaxLengthValidator(3)},
+			replies:    []string{"too long", "still too long"},
+			wantCalls:  2,
+			wantErr:    true,
+		},
+		{
+			name:       "rewritten output",
+			validators: []OutputValidator{RedactPhraseValidator("[redacted]", "secret"), MaxLengthValidator(30)},
+			replies:    []string{"the Secret is out"},
+			wantText:   "the [redacted] is out",
+			wantCalls:  1,
+		},
+		{
+			name:       "JSON fields",
+			validators: []OutputValidator{JSONFieldsValidator("name", "age")},
+			replies:    []string{`{"name": "Ann"}`, "```json\n{\"name\": \"Ann\", \"age\": 3}\n```"},
+			wantText:   "```json\n{\"name\": \"Ann\", \"age\": 3}\n```",
+			wantCalls:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newShutdownClient(t)
+			provider := &scriptedChatProvider{
+				MockProvider: &MockProvider{providerType: providers.ProviderOpenAI},
+				replies:      tt.replies,
+			}
+			useProvider(client, provider)
+			client.AddOutputValidator(tt.validators...)
+
+			resp, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+				Messages: []gomini.Message{gomini.NewUserMessage("question")},
+				Model:    "gpt-4o",
+			})
+			if len(provider.requests) != tt.wantCalls {
+				t.Errorf("Expected %d provider calls, got %d", tt.wantCalls, len(provider.requests))
+			}
+			if tt.wantErr {
+				var llmErr *gomini.LLMError
+				if !errors.As(err, &llmErr) || llmErr.Code != gomini.ErrorValidation {
+					t.Fatalf("Expected a validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SendMessage() error = %v", err)
+			}
+			if text, _, _ := responseText(resp); text != tt.wantText {
+				t.Errorf("Expected text %q, got %q", tt.wantText, text)
+			}
+			if resp.Usage.TotalTokens != 15*tt.wantCalls {
+				t.Errorf("Expected usage of %d calls, got %+v", tt.wantCalls, resp.Usage)
+			}
+		})
+	}
+}
+
+func TestClient_OutputValidatorFeedback(t *testing.T) {
+	client := newShutdownClient(t)
+	provider := &scriptedChatProvider{
+		MockProvider: &MockProvider{providerType: providers.ProviderOpenAI},
+		replies:      []string{"bad", "good"},
+	}
+	useProvider(client, provider)
+	client.AddOutputValidator(OutputValidatorFunc(func(resp *gomini.ChatResponse) error {
+		if text, _, _ := responseText(resp); text == "bad" {
+			return errors.New("answer must not be bad")
+		}
+		return nil
+	}))
+
+	if _, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("question")},
+	}); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	retry := provider.requests[1].Messages
+	if len(retry) != 3 {
+		t.Fatalf("Expected the retry to carry the rejected answer and feedback, got %v", retry)
+	}
+	if assistant := retry[1].(map[string]interface{}); assistant["role"] != "assistant" || assistant["content"] != "bad" {
+		t.Errorf("Expected the rejected answer, got %v", assistant)
+	}
+	if feedback := retry[2].(map[string]interface{})["content"].(string); !strings.Contains(feedback, "answer must not be bad") {
+		t.Errorf("Expected the validation error as feedback, got %q", feedback)
+	}
+	if len(provider.requests[0].Messages) != 1 {
+		t.Error("Expected the original request to be left unchanged")
+	}
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"fmt"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// applyPersona applies the request's Config.Personas preset: its system
+// prompt goes first, its model, provider and config fill what the request
+// leaves unset, and tools outside its AllowedTools are disabled for the turn.
+// The caller's request is not modified.
+func (c *Client) applyPersona(st *clientState, request *gomini.ChatRequest) (*gomini.ChatRequest, error) {
+	if request.Persona == "" {
+		return request, nil
+	}
+	persona, ok := st.config.Personas[request.Persona]
+	if !ok || persona == nil {
+		return nil, gomini.NewLLMError(gomini.ErrorInvalidRequest,
+			fmt.Sprintf("unknown persona %q", request.Persona), st.providerType, nil)
+	}
+
+	shaped := *request
+	shaped.Persona = ""
+	if shaped.Model == "" {
+		shaped.Model = persona.Model
+	}
+	if shaped.Provider == "" {
+		shaped.Provider = persona.Provider
+	}
+	if persona.SystemPrompt != "" {
+		shaped.Messages = append([]gomini.Message{gomini.NewSystemMessage(persona.SystemPrompt)}, request.Messages...)
+	}
+
+	config := make(map[string]interface{}, len(persona.Config)+1)
+	for key, value := range persona.Config {
+		config[key] = value
+	}
+	if persona.Temperature != nil {
+		config["temperature"] = *persona.Temperature
+	}
+	if requestConfig, ok := request.Config.(map[string]interface{}); ok {
+		for key, value := range requestConfig {
+			config[key] = value
+		}
+	}
+	if len(config) > 0 {
+		shaped.Config = config
+	}
+
+	if len(persona.AllowedTools) > 0 {
+		allowed := make(map[string]bool, len(persona.AllowedTools))
+		for _, name := range persona.AllowedTools {
+			allowed[name] = true
+		}
+		shaped.DisabledTools = append([]string(nil), request.DisabledTools...)
+		for _, tool := range request.Tools {
+			if fn, err := providers.AsFunctionTool(tool); err == nil && !allowed[fn.Name] {
+				shaped.DisabledTools = append(shaped.DisabledTools, fn.Name)
+			}
+		}
+	}
+	return &shaped, nil
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"gomini/pkg/gomini"
+)
+
+func TestClient_ApplyPersona(t *testing.T) {
+	client, _ := newGenerateTestClient(t, nil)
+	temperature := 0.2
+	client.currentState().config.Personas = map[string]*gomini.Persona{
+		"support": {
+			SystemPrompt: "You are a patient support agent.",
+			Model:        "gpt-4o-mini",
+			Temperature:  &temperature,
+			Config:       map[string]interface{}{"max_output_tokens": 256},
+			AllowedTools: []string{"lookup_order"},
+		},
+	}
+
+	request := &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("Where is my order?")},
+		Tools:    []gomini.Tool{gomini.FunctionTool{Name: "lookup_order"}, gomini.FunctionTool{Name: "refund"}},
+		Config:   map[string]interface{}{"max_output_tokens": 64},
+		Persona:  "support",
+	}
+	shaped, err := client.applyPersona(client.currentState(), request)
+	if err != nil {
+		t.Fatalf("applyPersona failed: %v", err)
+	}
+
+	if shaped.Model != "gpt-4o-mini" {
+		t.Errorf("Expected the persona's model, got %q", shaped.Model)
+	}
+	if len(shaped.Messages) != 2 || !reflect.DeepEqual(shaped.Messages[0], gomini.NewSystemMessage("You are a patient support agent.")) {
+		t.Fatalf("Expected the persona's system prompt first, got %+v", shaped.Messages)
+	}
+	wantConfig := map[string]interface{}{"temperature": 0.2, "max_output_tokens": 64}
+	if !reflect.DeepEqual(shaped.Config, wantConfig) {
+		t.Errorf("Expected config %v, got %v", wantConfig, shaped.Config)
+	}
+	if !reflect.DeepEqual(shaped.DisabledTools, []string{"refund"}) {
+		t.Errorf("Expected tools outside the persona to be disabled, got %v", shaped.DisabledTools)
+	}
+	if shaped.Persona != "" || request.Persona != "support" || len(request.Messages) != 1 {
+		t.Error("Expected a shaped copy, leaving the caller's request alone")
+	}
+
+	request.Model = "gpt-4o"
+	if shaped, _ := client.applyPersona(client.currentState(), request); shaped.Model != "gpt-4o" {
+		t.Errorf("Expected the request's model to win, got %q", shaped.Model)
+	}
+
+	request.Persona = "sales"
+	if _, err := client.applyPersona(client.currentState(), request); !errors.Is(err, gomini.ErrInvalidRequest) {
+		t.Errorf("Expected ErrInvalidRequest for an unknown persona, got %v", err)
+	}
+}
+
+func TestClient_SendWithPersona(t *testing.T) {
+	client, mockProvider := newGenerateTestClient(t, nil)
+	client.currentState().config.Personas = map[string]*gomini.Persona{
+		"pirate": {SystemPrompt: "Answer like a pirate."},
+	}
+
+	if _, err := client.Send(context.Background(), []gomini.Message{gomini.NewUserMessage("Hello")}, WithPersona("pirate")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	sent := mockProvider.lastRequest
+	if len(sent.Messages) == 0 || !reflect.DeepEqual(sent.Messages[0], gomini.NewSystemMessage("Answer like a pirate.")) {
+		t.Errorf("Expected the persona's system prompt to be sent, got %+v", sent.Messages)
+	}
+	if sent.Persona != "" {
+		t.Errorf("Expected the persona to be resolved before the provider, got %q", sent.Persona)
+	}
+}
+
+func TestClient_SendMessageStream_UnknownPersona(t *testing.T) {
+	client, _ := newGenerateTestClient(t, nil)
+
+	var streamErr error
+	for event := range client.SendMessageStream(context.Background(), &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("Hello")},
+		Persona:  "pirate",
+	}, "") {
+		if event.Type == gomini.EventError {
+			streamErr = event.Error
+		}
+	}
+	if !errors.Is(streamErr, gomini.ErrInvalidRequest) {
+		t.Errorf("Expected an ErrInvalidRequest event, got %v", streamErr)
+	}
+}
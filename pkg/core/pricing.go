@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// pricingLoadTimeout bounds fetching a remote price table
+const pricingLoadTimeout = 10 * time.Second
+
+// loadPricing loads config.PricingSource. A remote table that cannot be
+// fetched is logged and skipped so an outage of the pricing host does not
+// stop the client; RefreshPricing can retry it later.
+func (c *Client) loadPricing(config *gomini.Config) (*gomini.PriceTable, error) {
+	if config.PricingSource == "" {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pricingLoadTimeout)
+	defer cancel()
+
+	table, err := gomini.LoadPriceTable(ctx, config.PricingSource)
+	if err == nil {
+		return table, nil
+	}
+	if strings.HasPrefix(config.PricingSource, "http://") || strings.HasPrefix(config.PricingSource, "https://") {
+		c.Logger().Warn("using catalog prices", slog.String("pricing_source", config.PricingSource), slog.String("error", err.Error()))
+		return nil, nil
+	}
+	return nil, err
+}
+
+// RefreshPricing reloads the price table from Config.PricingSource
+func (c *Client) RefreshPricing(ctx context.Context) error {
+	config := c.currentState().config
+	if config.PricingSource == "" {
+		return fmt.Errorf("no pricing source configured")
+	}
+	table, err := gomini.LoadPriceTable(ctx, config.PricingSource)
+	if err != nil {
+		return err
+	}
+	c.SetPriceTable(table)
+	return nil
+}
+
+// SetPriceTable replaces the price table used for cost tracking
+func (c *Client) SetPriceTable(table *gomini.PriceTable) {
+	c.pricingMu.Lock()
+	defer c.pricingMu.Unlock()
+	c.prices = table
+}
+
+// ModelPrice returns the price of a model: a Config.PriceOverrides entry, then
+// the loaded price table, then the active provider's model catalog
+func (c *Client) ModelPrice(ctx context.Context, model string) (gomini.ModelPrice, bool) {
+	st, release := c.holdState()
+	defer release()
+	return c.modelPrice(ctx, st, st.provider, model)
+}
+
+// modelPrice is ModelPrice with the overrides of st and the catalog of the
+// given provider
+func (c *Client) modelPrice(ctx context.Context, st *clientState, provider providers.LLMProvider, model string) (gomini.ModelPrice, bool) {
+	if price, ok := st.config.PriceOverrides[model]; ok {
+		return price, true
+	}
+
+	c.pricingMu.RLock()
+	table := c.prices
+	c.pricingMu.RUnlock()
+	if price, ok := table.Lookup(model); ok {
+		return price, true
+	}
+
+	if provider != nil {
+		models, _ := provider.ListModels(ctx)
+		if entry, ok := providers.FindModel(models, model); ok && entry.Cost != nil {
+			return providers.PriceFromCost(entry.Cost), true
+		}
+	}
+	return gomini.ModelPrice{}, false
+}
+
+// streamUsageEvent returns the usage event reported alongside a stream's
+// finished event, if the provider sent token counts, with the stream's
+// tokens/second as its Efficiency
+func (c *Client) streamUsageEvent(ctx context.Context, st *clientState, finished gomini.StreamEvent, model string) (gomini.StreamEvent, bool) {
+	usage := finished.Metadata.Usage
+	if finished.Type != gomini.EventFinished || usage == nil {
+		return gomini.StreamEvent{}, false
+	}
+	event := gomini.NewUsageEvent(finished.Provider, model, usage, c.usageCost(ctx, st, model, usage))
+	event.RequestID = finished.RequestID
+	if latency := finished.Metadata.Latency; latency != nil {
+		data := event.Data.(gomini.UsageEvent)
+		data.Efficiency = latency.TokensPerSecond
+		data.TimeToFirstToken = latency.TimeToFirstToken
+		event.Data = data
+	}
+	return event, true
+}
+
+// UsageCost estimates the cost of usage on a model; unknown models cost 0
+func (c *Client) UsageCost(ctx context.Context, model string, usage *gomini.Usage) float64 {
+	st, release := c.holdState()
+	defer release()
+	return c.usageCost(ctx, st, model, usage)
+}
+
+// usageCost is UsageCost priced with st's provider
+func (c *Client) usageCost(ctx context.Context, st *clientState, model string, usage *gomini.Usage) float64 {
+	price, ok := c.modelPrice(ctx, st, st.provider, model)
+	if !ok {
+		return 0
+	}
+	return price.Cost(usage)
+}
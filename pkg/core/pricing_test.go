@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func newPricingConfig(source string) *gomini.Config {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.PricingSource = source
+	return config
+}
+
+func TestClient_ModelPricePrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	if err := os.WriteFile(path, []byte(`{"models": {"gpt-4o": {"input": 1, "output": 4}, "gpt-4o-mini": {"input": 0.1, "output": 0.4}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := newPricingConfig(path)
+	config.PriceOverrides = map[string]gomini.ModelPrice{"gpt-4o-mini": {Input: 0.05, Output: 0.2}}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	useProvider(client, &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		models: []gomini.Model{
+			{ID: "gpt-4o", Cost: &providers.ModelCost{InputTokens: 2.5, OutputTokens: 10}},
+			{ID: "o3", Cost: &providers.ModelCost{InputTokens: 10, OutputTokens: 40, CachedInputTokens: 2.5}},
+		},
+	})
+
+	ctx := context.Background()
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{"gpt-4o-mini", 0.05}, // override
+		{"gpt-4o", 1},         // price table beats the catalog
+		{"o3", 10},            // catalog
+	}
+	for _, tt := range tests {
+		price, ok := client.ModelPrice(ctx, tt.model)
+		if !ok || price.Input != tt.want {
+			t.Errorf("ModelPrice(%s) = %+v, %v; want input %v", tt.model, price, ok, tt.want)
+		}
+	}
+	if _, ok := client.ModelPrice(ctx, "unknown"); ok {
+		t.Error("Expected unknown models to have no price")
+	}
+	if got := client.UsageCost(ctx, "gpt-4o", &gomini.Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000}); got != 5 {
+		t.Errorf("Expected UsageCost 5, got %v", got)
+	}
+	// Prompt cache hits are billed at the catalog's cached input price
+	if got := client.UsageCost(ctx, "o3", &gomini.Usage{InputTokens: 1_000_000, CachedInputTokens: 400_000}); got != 6+1 {
+		t.Errorf("Expected cached input to be discounted to 7, got %v", got)
+	}
+}
+
+func TestClient_PricingSources(t *testing.T) {
+	if _, err := NewClient(newPricingConfig(filepath.Join(t.TempDir(), "missing.json"))); err == nil {
+		t.Error("Expected a missing pricing file to fail client creation")
+	}
+
+	available := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"models": {"gpt-4o": {"input": 3, "output": 12}}}`))
+	}))
+	defer server.Close()
+
+	// An unreachable pricing host falls back to the catalog
+	client, err := NewClient(newPricingConfig(server.URL))
+	if err != nil {
+		t.Fatalf("Expected remote pricing failures to be tolerated, got %v", err)
+	}
+	client.SetLogHandler(&recordingHandler{})
+	useProvider(client, &MockProvider{providerType: providers.ProviderOpenAI})
+	if _, ok := client.ModelPrice(context.Background(), "gpt-4o"); ok {
+		t.Error("Expected no price before the table is fetched")
+	}
+
+	available = true
+	if err := client.RefreshPricing(context.Background()); err != nil {
+		t.Fatalf("RefreshPricing failed: %v", err)
+	}
+	if price, ok := client.ModelPrice(context.Background(), "gpt-4o"); !ok || price.Input != 3 {
+		t.Errorf("Expected the remote price, got %+v, %v", price, ok)
+	}
+}
+
+func TestClient_StreamUsageEvent(t *testing.T) {
+	client := newShutdownClient(t)
+	client.currentState().config.PriceOverrides = map[string]gomini.ModelPrice{"gpt-4o": {Input: 2, Output: 8}}
+	usage := &gomini.Usage{InputTokens: 1_000_000, OutputTokens: 500_000, TotalTokens: 1_500_000}
+	useProvider(client, &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		responses: []gomini.StreamEvent{
+			{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "hi"}},
+			{Type: gomini.EventFinished, Metadata: gomini.EventMeta{Usage: usage}},
+		},
+	})
+
+	var types []gomini.EventType
+	var reported gomini.UsageEvent
+	for event := range client.SendMessageStream(context.Background(), &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	}, "prompt-1") {
+		types = append(types, event.Type)
+		if event.Type == gomini.EventUsage {
+			reported = event.Data.(gomini.UsageEvent)
+		}
+	}
+
+	if len(types) < 2 || types[len(types)-2] != gomini.EventUsage || types[len(types)-1] != gomini.EventFinished {
+		t.Fatalf("expected a usage event right before finished, got %v", types)
+	}
+	if reported.Usage != usage || reported.Cost != 6 {
+		t.Errorf("expected the finished usage priced at 6, got %+v", reported)
+	}
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gomini/pkg/gomini"
+)
+
+// MaxTrackedPrompts bounds how many prompts PromptState remembers; the least
+// recently active prompts are forgotten first
+const MaxTrackedPrompts = 1000
+
+// PromptState summarizes the streams sent under one prompt ID
+type PromptState struct {
+	PromptID         string          `json:"prompt_id"`
+	Turns            int             `json:"turns"`
+	Usage            gomini.Usage    `json:"usage"`
+	LoopDetected     bool            `json:"loop_detected,omitempty"`
+	LoopType         gomini.LoopType `json:"loop_type,omitempty"`
+	TurnLimitReached bool            `json:"turn_limit_reached,omitempty"`
+	Variant          string          `json:"variant,omitempty"` // Traffic split variant (provider/model), if any
+	Started          time.Time       `json:"started"`
+	Updated          time.Time       `json:"updated"`
+}
+
+// promptTracker records PromptState per prompt ID
+type promptTracker struct {
+	mu     sync.Mutex
+	states map[string]*PromptState
+}
+
+// NewPromptID generates a random (version 4) UUID for use as a prompt ID
+func NewPromptID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms
+		return fmt.Sprintf("prompt-%d", time.Now().UnixNano())
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// update applies fn to the state of promptID, creating it if needed
+func (t *promptTracker) update(promptID string, fn func(*PromptState)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	state, ok := t.states[promptID]
+	if !ok {
+		if t.states == nil {
+			t.states = make(map[string]*PromptState)
+		}
+		t.evictLocked()
+		state = &PromptState{PromptID: promptID, Started: now}
+		t.states[promptID] = state
+	}
+	fn(state)
+	state.Updated = now
+}
+
+// evictLocked makes room for one more prompt
+func (t *promptTracker) evictLocked() {
+	if len(t.states) < MaxTrackedPrompts {
+		return
+	}
+	states := make([]*PromptState, 0, len(t.states))
+	for _, state := range t.states {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Updated.Before(states[j].Updated) })
+	for _, state := range states[:len(states)-MaxTrackedPrompts+1] {
+		delete(t.states, state.PromptID)
+	}
+}
+
+// get returns a copy of the state of promptID
+func (t *promptTracker) get(promptID string) (PromptState, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.states[promptID]
+	if !ok {
+		return PromptState{}, false
+	}
+	return *state, true
+}
+
+// record updates the prompt's state from an event emitted on its stream
+func (t *promptTracker) record(promptID string, event gomini.StreamEvent) {
+	switch event.Type {
+	case gomini.EventFinished:
+		if usage := event.Metadata.Usage; usage != nil {
+			t.update(promptID, func(state *PromptState) {
+				state.Usage.InputTokens += usage.InputTokens
+				state.Usage.OutputTokens += usage.OutputTokens
+				state.Usage.TotalTokens += usage.TotalTokens
+				state.Usage.CachedInputTokens += usage.CachedInputTokens
+			})
+		}
+	case gomini.EventLoopDetected:
+		if data, ok := event.Data.(gomini.LoopDetectedEvent); ok {
+			t.update(promptID, func(state *PromptState) {
+				state.LoopDetected = true
+				state.LoopType = data.LoopType
+			})
+		}
+	case gomini.EventMaxSessionTurns:
+		t.update(promptID, func(state *PromptState) { state.TurnLimitReached = true })
+	}
+}
+
+// PromptState returns the turns, token usage and loop status recorded for a
+// prompt ID by SendMessageStream
+func (c *Client) PromptState(promptID string) (PromptState, bool) {
+	return c.prompts.get(promptID)
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestNewPromptID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := NewPromptID()
+		if !uuid.MatchString(id) {
+			t.Fatalf("Expected a version 4 UUID, got %q", id)
+		}
+		if seen[id] {
+			t.Fatalf("Duplicate prompt ID %q", id)
+		}
+		seen[id] = true
+	}
+
+	if session := NewSession("", 0); session.ID() == "" {
+		t.Error("Expected a generated session ID")
+	}
+}
+
+func TestClient_PromptState(t *testing.T) {
+	client := newShutdownClient(t)
+	client.currentState().config.MaxSessionTurns = 2
+	useProvider(client, &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		responses: []gomini.StreamEvent{
+			gomini.NewContentEvent(providers.ProviderOpenAI, "test-model", "Hi", true),
+			{Type: gomini.EventFinished, Metadata: gomini.EventMeta{Usage: &gomini.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}}},
+		},
+	})
+	request := &gomini.ChatRequest{Model: "test-model", Messages: []gomini.Message{gomini.NewUserMessage("Hello")}}
+
+	// An empty prompt ID is generated and stamped on every event
+	var promptID string
+	for event := range client.SendMessageStream(context.Background(), request, "") {
+		if event.PromptID == "" {
+			t.Fatalf("Event %s has no prompt ID", event.Type)
+		}
+		if promptID != "" && event.PromptID != promptID {
+			t.Fatalf("Expected one prompt ID per stream, got %q and %q", promptID, event.PromptID)
+		}
+		promptID = event.PromptID
+	}
+
+	for i := 0; i < 2; i++ {
+		for range client.SendMessageStream(context.Background(), request, promptID) {
+		}
+	}
+
+	state, ok := client.PromptState(promptID)
+	if !ok {
+		t.Fatalf("Expected state for prompt %s", promptID)
+	}
+	if state.Turns != 3 || !state.TurnLimitReached {
+		t.Errorf("Expected 3 turns with the limit reached, got %+v", state)
+	}
+	if state.Usage.TotalTokens != 30 || state.Usage.InputTokens != 20 {
+		t.Errorf("Expected usage from two completed turns, got %+v", state.Usage)
+	}
+	if state.LoopDetected {
+		t.Error("Expected no loop")
+	}
+
+	if _, ok := client.PromptState("unknown"); ok {
+		t.Error("Expected no state for an unknown prompt")
+	}
+}
+
+func TestPromptTracker_Evicts(t *testing.T) {
+	var tracker promptTracker
+	for i := 0; i < MaxTrackedPrompts+10; i++ {
+		tracker.update(fmt.Sprintf("prompt-%d", i), func(state *PromptState) { state.Turns++ })
+	}
+	if len(tracker.states) != MaxTrackedPrompts {
+		t.Errorf("Expected %d tracked prompts, got %d", MaxTrackedPrompts, len(tracker.states))
+	}
+	if _, ok := tracker.get(fmt.Sprintf("prompt-%d", MaxTrackedPrompts+9)); !ok {
+		t.Error("Expected the newest prompt to be kept")
+	}
+}
@@ -0,0 +1,416 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// Sliding windows tracked for every provider key, matching the per-minute
+// and per-day limits providers publish
+const (
+	QuotaMinute = time.Minute
+	QuotaDay    = 24 * time.Hour
+)
+
+// quotaSlots is how many slots each window is counted in. A window sums the
+// slots it overlaps, so it is accurate to one slot (5 seconds of a minute).
+const quotaSlots = 12
+
+// QuotaUsage is the requests and tokens consumed in a window
+type QuotaUsage struct {
+	Requests int64 `json:"requests"`
+	Tokens   int64 `json:"tokens"`
+}
+
+// QuotaStore holds the counters behind quota tracking. Each key names one
+// time slot of a provider key and must expire after ttl. A store shared
+// between processes, such as RedisQuotaStore, lets every instance using the
+// same API keys see their combined consumption.
+type QuotaStore interface {
+	// Add adds usage to the counters under key
+	Add(ctx context.Context, key string, usage QuotaUsage, ttl time.Duration) error
+
+	// Get returns the counters under keys, zero for missing keys
+	Get(ctx context.Context, keys []string) ([]QuotaUsage, error)
+}
+
+// MemoryQuotaStore is an in-process QuotaStore
+type MemoryQuotaStore struct {
+	mu       sync.Mutex
+	counters map[string]*memoryQuotaCounter
+	now      func() time.Time
+}
+
+type memoryQuotaCounter struct {
+	usage   QuotaUsage
+	expires time.Time
+}
+
+// NewMemoryQuotaStore creates an empty MemoryQuotaStore
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{counters: make(map[string]*memoryQuotaCounter), now: time.Now}
+}
+
+// Add implements QuotaStore
+func (m *MemoryQuotaStore) Add(ctx context.Context, key string, usage QuotaUsage, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for k, counter := range m.counters {
+		if now.After(counter.expires) {
+			delete(m.counters, k)
+		}
+	}
+	counter, ok := m.counters[key]
+	if !ok {
+		counter = &memoryQuotaCounter{}
+		m.counters[key] = counter
+	}
+	counter.usage.Requests += usage.Requests
+	counter.usage.Tokens += usage.Tokens
+	counter.expires = now.Add(ttl)
+	return nil
+}
+
+// Get implements QuotaStore
+func (m *MemoryQuotaStore) Get(ctx context.Context, keys []string) ([]QuotaUsage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	usage := make([]QuotaUsage, len(keys))
+	for i, key := range keys {
+		if counter, ok := m.counters[key]; ok && !now.After(counter.expires) {
+			usage[i] = counter.usage
+		}
+	}
+	return usage, nil
+}
+
+// RedisCounterClient is the subset of a Redis client RedisQuotaStore needs,
+// so any client library can be adapted without gomini depending on it
+type RedisCounterClient interface {
+	// IncrBy adds delta to the integer at key and sets its expiry to ttl
+	// (INCRBY then EXPIRE)
+	IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) error
+
+	// MGet returns the integers at keys, 0 for missing keys
+	MGet(ctx context.Context, keys ...string) ([]int64, error)
+}
+
+// RedisQuotaStore is a QuotaStore shared between processes through Redis
+type RedisQuotaStore struct {
+	client RedisCounterClient
+	prefix string
+}
+
+// NewRedisQuotaStore creates a RedisQuotaStore keeping counters under prefix (default "gomini:quota:")
+func NewRedisQuotaStore(client RedisCounterClient, prefix string) *RedisQuotaStore {
+	if prefix == "" {
+		prefix = "gomini:quota:"
+	}
+	return &RedisQuotaStore{client: client, prefix: prefix}
+}
+
+// Add implements QuotaStore
+func (r *RedisQuotaStore) Add(ctx context.Context, key string, usage QuotaUsage, ttl time.Duration) error {
+	if err := r.client.IncrBy(ctx, r.prefix+key+":requests", usage.Requests, ttl); err != nil {
+		return err
+	}
+	if usage.Tokens == 0 {
+		return nil
+	}
+	return r.client.IncrBy(ctx, r.prefix+key+":tokens", usage.Tokens, ttl)
+}
+
+// Get implements QuotaStore
+func (r *RedisQuotaStore) Get(ctx context.Context, keys []string) ([]QuotaUsage, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		names = append(names, r.prefix+key+":requests", r.prefix+key+":tokens")
+	}
+	values, err := r.client.MGet(ctx, names...)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(names) {
+		return nil, fmt.Errorf("redis returned %d values for %d keys", len(values), len(names))
+	}
+	usage := make([]QuotaUsage, len(keys))
+	for i := range keys {
+		usage[i] = QuotaUsage{Requests: values[2*i], Tokens: values[2*i+1]}
+	}
+	return usage, nil
+}
+
+// KeyQuota is what one provider key consumed over the tracked windows
+type KeyQuota struct {
+	Provider providers.ProviderType `json:"provider"`
+	KeyID    string                 `json:"key_id,omitempty"` // Masked key; empty for providers without one
+	Minute   QuotaWindow            `json:"minute"`
+	Day      QuotaWindow            `json:"day"`
+}
+
+// QuotaWindow is a key's usage over a sliding window and the limits that
+// apply to it
+type QuotaWindow struct {
+	Window time.Duration `json:"window"`
+	QuotaUsage
+	RequestLimit int64 `json:"request_limit,omitempty"` // 0 when unlimited
+	TokenLimit   int64 `json:"token_limit,omitempty"`   // 0 when unlimited
+}
+
+// Headroom returns the fraction left of the tighter of the window's limits:
+// 1 with no limits, 0 once a limit is reached
+func (w QuotaWindow) Headroom() float64 {
+	headroom := 1.0
+	for _, pair := range [][2]int64{{w.Requests, w.RequestLimit}, {w.Tokens, w.TokenLimit}} {
+		used, limit := pair[0], pair[1]
+		if limit <= 0 {
+			continue
+		}
+		left := 1 - float64(used)/float64(limit)
+		if left < 0 {
+			left = 0
+		}
+		if left < headroom {
+			headroom = left
+		}
+	}
+	return headroom
+}
+
+// quotaBucket identifies the counters of one provider key
+type quotaBucket struct {
+	provider providers.ProviderType
+	keyID    string
+}
+
+// quotaTracker counts provider requests and tokens per key in a QuotaStore
+type quotaTracker struct {
+	mu    sync.RWMutex
+	store QuotaStore
+	seen  map[quotaBucket]bool
+	now   func() time.Time
+}
+
+func newQuotaTracker() *quotaTracker {
+	return &quotaTracker{store: NewMemoryQuotaStore(), seen: make(map[quotaBucket]bool), now: time.Now}
+}
+
+// slotKey names the slot of window that holds t
+func (b quotaBucket) slotKey(window time.Duration, t time.Time) string {
+	slot := window / quotaSlots
+	return fmt.Sprintf("%s:%s:%d:%d", b.provider, b.keyID, int64(window/time.Second), t.UnixNano()/int64(slot))
+}
+
+// record adds usage to the current slot of every window
+func (q *quotaTracker) record(ctx context.Context, bucket quotaBucket, usage QuotaUsage) error {
+	q.mu.Lock()
+	q.seen[bucket] = true
+	store := q.store
+	q.mu.Unlock()
+
+	now := q.now()
+	for _, window := range []time.Duration{QuotaMinute, QuotaDay} {
+		if err := store.Add(ctx, bucket.slotKey(window, now), usage, window+window/quotaSlots); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// window sums the slots of bucket overlapping the window ending now
+func (q *quotaTracker) window(ctx context.Context, bucket quotaBucket, window time.Duration) (QuotaUsage, error) {
+	q.mu.RLock()
+	store := q.store
+	q.mu.RUnlock()
+
+	now := q.now()
+	keys := make([]string, quotaSlots)
+	for i := range keys {
+		keys[i] = bucket.slotKey(window, now.Add(-time.Duration(i)*(window/quotaSlots)))
+	}
+	slots, err := store.Get(ctx, keys)
+	if err != nil {
+		return QuotaUsage{}, err
+	}
+	var total QuotaUsage
+	for _, slot := range slots {
+		total.Requests += slot.Requests
+		total.Tokens += slot.Tokens
+	}
+	return total, nil
+}
+
+// counterStore returns the store quota, rate limits and circuit breakers count in
+func (q *quotaTracker) counterStore() QuotaStore {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.store
+}
+
+// buckets returns the buckets recorded by this tracker
+func (q *quotaTracker) buckets() []quotaBucket {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	buckets := make([]quotaBucket, 0, len(q.seen))
+	for bucket := range q.seen {
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// keyNoteKey carries a *keyNote in a request context
+type keyNoteKey struct{}
+
+// keyNote collects the pooled keys a provider call tried, in order
+type keyNote struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+// withKeyNote returns a context in which key pools note the keys they try
+func withKeyNote(ctx context.Context) (context.Context, *keyNote) {
+	note := &keyNote{}
+	return context.WithValue(ctx, keyNoteKey{}, note), note
+}
+
+// noteKey records that the call in ctx tried the key keyID
+func noteKey(ctx context.Context, keyID string) {
+	if note, ok := ctx.Value(keyNoteKey{}).(*keyNote); ok {
+		note.mu.Lock()
+		note.keys = append(note.keys, keyID)
+		note.mu.Unlock()
+	}
+}
+
+func (n *keyNote) attempts() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.keys...)
+}
+
+// SetQuotaStore replaces the in-memory quota counters, e.g. with a
+// RedisQuotaStore so instances sharing API keys see each other's usage.
+// Provider rate limits and circuit breakers count in the same store, so they
+// are enforced across those instances too.
+func (c *Client) SetQuotaStore(store QuotaStore) {
+	c.quota.mu.Lock()
+	defer c.quota.mu.Unlock()
+	c.quota.store = store
+}
+
+// QuotaStatus returns the requests and tokens each provider key consumed
+// over the last minute and day, with the limits from ProviderConfig.Quota.
+// It covers the keys of enabled providers and any used since the client
+// started, sorted by provider and key.
+func (c *Client) QuotaStatus(ctx context.Context) ([]KeyQuota, error) {
+	buckets := make(map[quotaBucket]bool)
+	for _, bucket := range c.quota.buckets() {
+		buckets[bucket] = true
+	}
+	config := c.currentState().config
+	for _, providerType := range config.GetEnabledProviders() {
+		providerConfig, err := config.GetProviderConfig(providerType)
+		if err != nil {
+			continue
+		}
+		keys := providerConfig.Keys()
+		if len(keys) == 0 {
+			buckets[quotaBucket{provider: providerType}] = true
+		}
+		for _, key := range keys {
+			buckets[quotaBucket{provider: providerType, keyID: maskKey(key)}] = true
+		}
+	}
+
+	status := make([]KeyQuota, 0, len(buckets))
+	for bucket := range buckets {
+		var limits gomini.QuotaLimits
+		if providerConfig, err := config.GetProviderConfig(bucket.provider); err == nil && providerConfig.Quota != nil {
+			limits = *providerConfig.Quota
+		}
+		minute, err := c.quota.window(ctx, bucket, QuotaMinute)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read quota of %s: %w", bucket.provider, err)
+		}
+		day, err := c.quota.window(ctx, bucket, QuotaDay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read quota of %s: %w", bucket.provider, err)
+		}
+		status = append(status, KeyQuota{
+			Provider: bucket.provider,
+			KeyID:    bucket.keyID,
+			Minute:   QuotaWindow{Window: QuotaMinute, QuotaUsage: minute, RequestLimit: limits.RequestsPerMinute, TokenLimit: limits.TokensPerMinute},
+			Day:      QuotaWindow{Window: QuotaDay, QuotaUsage: day, RequestLimit: limits.RequestsPerDay, TokenLimit: limits.TokensPerDay},
+		})
+	}
+
+	sort.Slice(status, func(i, j int) bool {
+		if status[i].Provider != status[j].Provider {
+			return status[i].Provider < status[j].Provider
+		}
+		return status[i].KeyID < status[j].KeyID
+	})
+	return status, nil
+}
+
+// recordQuota counts a provider call against the keys it tried: one request
+// per key, with the tokens of usage charged to the key that answered. Calls
+// that went through no key pool are charged to the provider's single key.
+func (c *Client) recordQuota(ctx context.Context, st *clientState, note *keyNote, usage *gomini.Usage) {
+	providerType := st.providerType
+	keys := note.attempts()
+	if len(keys) == 0 {
+		keys = []string{c.singleKeyID(st, providerType)}
+	}
+	// Count the call even if the request was cancelled
+	ctx = context.WithoutCancel(ctx)
+	for i, keyID := range keys {
+		counted := QuotaUsage{Requests: 1}
+		if i == len(keys)-1 {
+			counted.Tokens = usageTokens(usage)
+		}
+		if err := c.quota.record(ctx, quotaBucket{provider: providerType, keyID: keyID}, counted); err != nil {
+			c.Logger().Warn("quota tracking failed", slog.String("provider", string(providerType)), slog.String("error", err.Error()))
+			return
+		}
+	}
+}
+
+// singleKeyID returns the masked key of an unpooled provider, "" if it has none
+func (c *Client) singleKeyID(st *clientState, providerType providers.ProviderType) string {
+	providerConfig, err := st.config.GetProviderConfig(providerType)
+	if err != nil {
+		return ""
+	}
+	if keys := providerConfig.Keys(); len(keys) == 1 {
+		return maskKey(keys[0])
+	}
+	return ""
+}
+
+// usageTokens returns the total tokens of usage
+func usageTokens(usage *gomini.Usage) int64 {
+	switch {
+	case usage == nil:
+		return 0
+	case usage.TotalTokens > 0:
+		return int64(usage.TotalTokens)
+	case usage.InputTokens+usage.OutputTokens > 0:
+		return int64(usage.InputTokens + usage.OutputTokens)
+	}
+	return int64(usage.PromptTokens + usage.CompletionTokens)
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// fakeRedisCounter is an in-memory RedisCounterClient
+type fakeRedisCounter struct {
+	values map[string]int64
+	ttls   map[string]time.Duration
+}
+
+func (f *fakeRedisCounter) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) error {
+	f.values[key] += delta
+	f.ttls[key] = ttl
+	return nil
+}
+
+func (f *fakeRedisCounter) MGet(ctx context.Context, keys ...string) ([]int64, error) {
+	values := make([]int64, len(keys))
+	for i, key := range keys {
+		values[i] = f.values[key]
+	}
+	return values, nil
+}
+
+func TestMemoryQuotaStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryQuotaStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	store.Add(ctx, "a", QuotaUsage{Requests: 1, Tokens: 10}, time.Minute)
+	store.Add(ctx, "a", QuotaUsage{Requests: 1, Tokens: 5}, time.Minute)
+	store.Add(ctx, "b", QuotaUsage{Requests: 1}, time.Second)
+
+	usage, _ := store.Get(ctx, []string{"a", "b", "missing"})
+	want := []QuotaUsage{{Requests: 2, Tokens: 15}, {Requests: 1}, {}}
+	for i := range want {
+		if usage[i] != want[i] {
+			t.Errorf("Get()[%d] = %+v, want %+v", i, usage[i], want[i])
+		}
+	}
+
+	now = now.Add(2 * time.Second)
+	if usage, _ := store.Get(ctx, []string{"b"}); usage[0] != (QuotaUsage{}) {
+		t.Errorf("Expected b to expire, got %+v", usage[0])
+	}
+}
+
+func TestRedisQuotaStore(t *testing.T) {
+	ctx := context.Background()
+	backend := &fakeRedisCounter{values: make(map[string]int64), ttls: make(map[string]time.Duration)}
+	store := NewRedisQuotaStore(backend, "")
+
+	store.Add(ctx, "slot", QuotaUsage{Requests: 1, Tokens: 40}, time.Minute)
+	store.Add(ctx, "slot", QuotaUsage{Requests: 1}, time.Minute)
+	if backend.values["gomini:quota:slot:requests"] != 2 || backend.ttls["gomini:quota:slot:tokens"] != time.Minute {
+		t.Errorf("Unexpected redis state: %v %v", backend.values, backend.ttls)
+	}
+
+	usage, err := store.Get(ctx, []string{"slot", "other"})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if usage[0] != (QuotaUsage{Requests: 2, Tokens: 40}) || usage[1] != (QuotaUsage{}) {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+}
+
+func TestQuotaWindow_Headroom(t *testing.T) {
+	tests := []struct {
+		name   string
+		window QuotaWindow
+		want   float64
+	}{
+		{"no limits", QuotaWindow{QuotaUsage: QuotaUsage{Requests: 50}}, 1},
+		{"requests", QuotaWindow{QuotaUsage: QuotaUsage{Requests: 2}, RequestLimit: 10}, 0.8},
+		{"tighter tokens", QuotaWindow{QuotaUsage: QuotaUsage{Requests: 2, Tokens: 60}, RequestLimit: 10, TokenLimit: 100}, 0.4},
+		{"exceeded", QuotaWindow{QuotaUsage: QuotaUsage{Tokens: 150}, TokenLimit: 100}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.Headroom(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Headroom() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_QuotaStatus(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "sk-test-key-0001",
+		Quota:   &gomini.QuotaLimits{RequestsPerMinute: 10, TokensPerMinute: 100},
+	}
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		return &MockProvider{providerType: providerType, chatResponse: &gomini.ChatResponse{
+			Choices: []gomini.Choice{{Message: gomini.NewAssistantMessage("ok")}},
+			Usage:   &gomini.Usage{TotalTokens: 30},
+		}}, nil
+	}
+	if err := rebuildProviders(client); err != nil {
+		t.Fatalf("rebuildProviders failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+			Model:    "gpt-4o",
+			Messages: []gomini.Message{gomini.NewUserMessage("Hello")},
+		}); err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+	}
+
+	status, err := client.QuotaStatus(context.Background())
+	if err != nil {
+		t.Fatalf("QuotaStatus failed: %v", err)
+	}
+	if len(status) != 1 || status[0].KeyID != "sk-...0001" {
+		t.Fatalf("Expected one openai key, got %+v", status)
+	}
+	minute := status[0].Minute
+	if minute.Requests != 2 || minute.Tokens != 60 || minute.RequestLimit != 10 {
+		t.Errorf("Unexpected minute window: %+v", minute)
+	}
+	if math.Abs(minute.Headroom()-0.4) > 1e-9 {
+		t.Errorf("Expected 40%% headroom, got %v", minute.Headroom())
+	}
+	if status[0].Day.Requests != 2 || status[0].Day.Window != QuotaDay {
+		t.Errorf("Unexpected day window: %+v", status[0].Day)
+	}
+}
+
+func TestClient_QuotaChargesPooledKeys(t *testing.T) {
+	client := newShutdownClient(t)
+	pool, fakes, _, _ := newTestKeyPool(gomini.RotateRoundRobin, "sk-key-a-0001", "sk-key-b-0002")
+	fakes[0].err = errors.New("429 rate limit exceeded")
+
+	useProvider(client, pool)
+	if _, err := client.sendWithTimeout(context.Background(), client.currentState(), &gomini.ChatRequest{}); err != nil {
+		t.Fatalf("sendWithTimeout failed: %v", err)
+	}
+
+	status, err := client.QuotaStatus(context.Background())
+	if err != nil {
+		t.Fatalf("QuotaStatus failed: %v", err)
+	}
+	used := make(map[string]QuotaUsage)
+	for _, key := range status {
+		if key.Provider == providers.ProviderOpenAI {
+			used[key.KeyID] = key.Minute.QuotaUsage
+		}
+	}
+	// The throttled attempt costs a request; the tokens go to the key that answered
+	if used["sk-...0001"] != (QuotaUsage{Requests: 1}) || used["sk-...0002"] != (QuotaUsage{Requests: 1, Tokens: 10}) {
+		t.Errorf("Unexpected usage per key: %+v", used)
+	}
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// ErrRateLimited is the cause of the errors returned for calls beyond a
+// provider's RateLimit
+var ErrRateLimited = errors.New("provider rate limit reached")
+
+// ErrCircuitOpen is the cause of the errors returned while a provider's
+// circuit breaker is open
+var ErrCircuitOpen = errors.New("provider circuit breaker is open")
+
+// Defaults of gomini.CircuitBreaker
+const (
+	DefaultBreakerWindow   = time.Minute
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// Rate limits and circuit breakers count in the client's QuotaStore, so with
+// a RedisQuotaStore they hold across every instance sharing it. Windows are
+// fixed rather than sliding: a limit resets when its window ends.
+
+// windowKey names the counter of the fixed window of length that holds t
+func windowKey(name string, providerType providers.ProviderType, length time.Duration, t time.Time) (string, time.Time) {
+	index := t.UnixNano() / int64(length)
+	end := time.Unix(0, (index+1)*int64(length))
+	return fmt.Sprintf("%s:%s:%d:%d", name, providerType, int64(length/time.Second), index), end
+}
+
+// countCall adds one to the counter of the window of length holding now and
+// returns the new count with the end of the window
+func countCall(ctx context.Context, store QuotaStore, name string, providerType providers.ProviderType, length time.Duration, now time.Time) (int64, time.Time, error) {
+	key, end := windowKey(name, providerType, length, now)
+	if err := store.Add(ctx, key, QuotaUsage{Requests: 1}, length); err != nil {
+		return 0, end, err
+	}
+	counts, err := store.Get(ctx, []string{key})
+	if err != nil || len(counts) != 1 {
+		return 0, end, err
+	}
+	return counts[0].Requests, end, nil
+}
+
+// admitCall refuses a call to st's provider while its circuit breaker is
+// open or its RateLimit is spent, and otherwise counts the call against the
+// RateLimit. When the store fails the call is let through.
+func (c *Client) admitCall(ctx context.Context, st *clientState) error {
+	providerConfig, err := st.config.GetProviderConfig(st.providerType)
+	if err != nil || (providerConfig.CircuitBreaker == nil && providerConfig.RateLimit == nil) {
+		return nil
+	}
+	store, now := c.quota.counterStore(), c.quota.now()
+
+	if providerConfig.CircuitBreaker != nil {
+		open, err := store.Get(ctx, []string{breakerKey(st.providerType)})
+		if err != nil {
+			c.Logger().Warn("circuit breaker check failed", slog.String("provider", string(st.providerType)), slog.String("error", err.Error()))
+		} else if len(open) == 1 && open[0].Requests > 0 {
+			return providers.NewLLMError(providers.ErrorServiceUnavailable,
+				fmt.Sprintf("%s: %v", st.providerType, ErrCircuitOpen), st.providerType, ErrCircuitOpen)
+		}
+	}
+
+	if limits := providerConfig.RateLimit; limits != nil {
+		for _, window := range []struct {
+			length time.Duration
+			limit  int
+		}{{QuotaDay, limits.RequestsPerDay}, {QuotaMinute, limits.RequestsPerMinute}} {
+			if window.limit <= 0 {
+				continue
+			}
+			count, end, err := countCall(ctx, store, "ratelimit", st.providerType, window.length, now)
+			if err != nil {
+				c.Logger().Warn("rate limit check failed", slog.String("provider", string(st.providerType)), slog.String("error", err.Error()))
+				return nil
+			}
+			if count > int64(window.limit) {
+				retryAfter := end.Sub(now)
+				limited := providers.NewLLMError(providers.ErrorRateLimit,
+					fmt.Sprintf("%s: %v (%d requests per %s)", st.providerType, ErrRateLimited, window.limit, window.length),
+					st.providerType, ErrRateLimited)
+				limited.RetryAfter = &retryAfter
+				return limited
+			}
+		}
+	}
+	return nil
+}
+
+// breakerKey names the counter that is set while providerType's breaker is open
+func breakerKey(providerType providers.ProviderType) string {
+	return fmt.Sprintf("breaker:%s:open", providerType)
+}
+
+// recordBreaker counts a failed call to st's provider and opens its circuit
+// breaker once the failures in the current window reach the threshold. A
+// failure after the cooldown, within the same window, opens it again at
+// once. Calls refused by admitCall and failures on the caller's side are not
+// counted.
+func (c *Client) recordBreaker(ctx context.Context, st *clientState, err error) {
+	if !providerFailure(err, st.providerType) {
+		return
+	}
+	providerConfig, configErr := st.config.GetProviderConfig(st.providerType)
+	if configErr != nil || providerConfig.CircuitBreaker == nil {
+		return
+	}
+	breaker := providerConfig.CircuitBreaker
+	window, cooldown := breaker.Window, breaker.Cooldown
+	if window <= 0 {
+		window = DefaultBreakerWindow
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	store := c.quota.counterStore()
+	failures, _, countErr := countCall(ctx, store, "breaker", st.providerType, window, c.quota.now())
+	if countErr == nil && failures >= int64(breaker.Failures) {
+		countErr = store.Add(ctx, breakerKey(st.providerType), QuotaUsage{Requests: 1}, cooldown)
+		if countErr == nil {
+			c.Logger().Warn("circuit breaker opened", slog.String("provider", string(st.providerType)),
+				slog.Int64("failures", failures), slog.Duration("cooldown", cooldown))
+		}
+	}
+	if countErr != nil {
+		c.Logger().Warn("circuit breaker update failed", slog.String("provider", string(st.providerType)), slog.String("error", countErr.Error()))
+	}
+}
+
+// beginCall counts a call to st's provider toward its load until the
+// returned function reports the outcome, which also feeds the provider's
+// circuit breaker
+func (c *Client) beginCall(ctx context.Context, st *clientState) func(latency time.Duration, err error) {
+	done := c.load.begin(st.providerType)
+	return func(latency time.Duration, err error) {
+		done(latency, err)
+		c.recordBreaker(ctx, st, err)
+	}
+}
+
+// localRefusal reports whether err is a call admitCall refused
+func localRefusal(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrCircuitOpen)
+}
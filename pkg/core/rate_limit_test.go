@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestClient_RateLimitSharedAcrossClients(t *testing.T) {
+	backend := &fakeRedisCounter{values: make(map[string]int64), ttls: make(map[string]time.Duration)}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newClient := func() *Client {
+		config := gomini.NewConfig()
+		config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+			Enabled:   true,
+			APIKey:    "test-key",
+			RateLimit: &providers.RateLimit{RequestsPerMinute: 3},
+		}
+		client, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		t.Cleanup(func() { client.Close() })
+		useProvider(client, &MockProvider{providerType: providers.ProviderOpenAI})
+		client.SetQuotaStore(NewRedisQuotaStore(backend, ""))
+		client.quota.now = func() time.Time { return now }
+		return client
+	}
+	clients := []*Client{newClient(), newClient()}
+	send := func(i int) error {
+		_, err := clients[i%2].sendWithTimeout(context.Background(), clients[i%2].currentState(), &gomini.ChatRequest{Model: "gpt-4o"})
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := send(i); err != nil {
+			t.Fatalf("Request %d failed: %v", i+1, err)
+		}
+	}
+	err := send(3)
+	if !errors.Is(err, ErrRateLimited) || !errors.Is(err, gomini.ErrRateLimit) {
+		t.Fatalf("Expected the fourth request to be rate limited across clients, got %v", err)
+	}
+	var llmErr *gomini.LLMError
+	if !errors.As(err, &llmErr) || llmErr.RetryAfter == nil || *llmErr.RetryAfter != time.Minute {
+		t.Errorf("Expected a retry after the minute ends, got %+v", llmErr)
+	}
+	if load := clients[1].load.load(providers.ProviderOpenAI); load.ErrorRate != 0 {
+		t.Errorf("Expected refused requests not to count as provider failures, got %+v", load)
+	}
+
+	now = now.Add(time.Minute)
+	if err := send(4); err != nil {
+		t.Errorf("Expected the limit to reset with the next window, got %v", err)
+	}
+}
+
+// countingFailingProvider fails chat requests with err, when set, and counts them
+type countingFailingProvider struct {
+	MockProvider
+	calls int
+	err   error
+}
+
+func (p *countingFailingProvider) SendMessage(ctx context.Context, request *gomini.ChatRequest) (*gomini.ChatResponse, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.MockProvider.SendMessage(ctx, request)
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	openai := &countingFailingProvider{
+		MockProvider: MockProvider{providerType: providers.ProviderOpenAI},
+		err:          providers.NewLLMError(providers.ErrorServerError, "500 internal error", providers.ProviderOpenAI, nil),
+	}
+	client := newFallbackClient(t, func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		if providerType == providers.ProviderOpenAI {
+			return openai, nil
+		}
+		return &MockProvider{providerType: providerType}, nil
+	})
+	st := client.currentState()
+	st.config.Providers[providers.ProviderOpenAI].CircuitBreaker = &gomini.CircuitBreaker{Failures: 2, Cooldown: 30 * time.Second}
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	store := NewMemoryQuotaStore()
+	store.now = clock
+	client.SetQuotaStore(store)
+	client.quota.now = clock
+
+	send := func() *gomini.ChatResponse {
+		t.Helper()
+		resp, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+			Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+		})
+		if err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+		return resp
+	}
+
+	for i := 0; i < 2; i++ {
+		if resp := send(); resp.Provider != providers.ProviderGemini {
+			t.Fatalf("Expected a fallback answer, got one from %s", resp.Provider)
+		}
+	}
+	if openai.calls != 2 {
+		t.Fatalf("Expected 2 calls to the failing provider, got %d", openai.calls)
+	}
+
+	// Open: openai is skipped without being called
+	if resp := send(); resp.Provider != providers.ProviderGemini || openai.calls != 2 {
+		t.Errorf("Expected the open breaker to skip openai, got %s after %d calls", resp.Provider, openai.calls)
+	}
+	_, err := client.sendWithTimeout(context.Background(), st, &gomini.ChatRequest{})
+	if !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, gomini.ErrServiceUnavailable) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+
+	// Closed again after the cooldown
+	now = now.Add(31 * time.Second)
+	openai.err = nil
+	if resp := send(); resp.Provider != providers.ProviderOpenAI {
+		t.Errorf("Expected openai to answer after the cooldown, got %s", resp.Provider)
+	}
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"gomini/pkg/gomini"
+)
+
+// DefaultConfigWatchInterval is how often WatchConfigFile checks the file for changes
+const DefaultConfigWatchInterval = 2 * time.Second
+
+// ReloadConfig validates config and swaps it into the running client.
+// Provider credentials, router settings, pricing, loop detection and limits such as
+// MaxSessionTurns and MaxConcurrentStreams apply from the next request; the
+// cleanup interval keeps its original value. The active provider is rebuilt
+// from the new credentials, or replaced by the default when it is no longer
+// enabled. Requests and streams already in flight finish on the config and
+// providers they started with, which are closed once they are done. On error
+// the client is unchanged.
+func (c *Client) ReloadConfig(config *gomini.Config) error {
+	if config == nil {
+		return fmt.Errorf("config is nil")
+	}
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	c.closeMu.Lock()
+	closed := c.closed
+	c.closeMu.Unlock()
+	if closed {
+		return fmt.Errorf("client is closed")
+	}
+
+	old := c.currentState()
+	providerType := old.providerType
+	if _, err := config.GetProviderConfig(providerType); err != nil {
+		providerType = config.DefaultProvider
+		if providerType == "" {
+			enabledProviders := config.GetEnabledProviders()
+			if len(enabledProviders) == 0 {
+				return fmt.Errorf("no providers enabled")
+			}
+			providerType = enabledProviders[0]
+		}
+	}
+
+	set := c.newProviderSet(config)
+	provider, err := set.get(providerType)
+	if err != nil {
+		return err
+	}
+
+	c.pricingMu.RLock()
+	prices := c.prices
+	c.pricingMu.RUnlock()
+	if config.PricingSource != old.config.PricingSource {
+		if prices, err = c.loadPricing(config); err != nil {
+			set.retire()
+			return fmt.Errorf("failed to load pricing: %w", err)
+		}
+	}
+
+	c.reloadCache(old.config, config)
+	c.state.Store(&clientState{config: config, providerType: providerType, provider: provider, providers: set})
+	c.resetModelIndex()
+	c.logLevel.Set(parseLogLevel(config))
+	c.loopDetectors.setConfig(config)
+	c.SetPriceTable(prices)
+	c.streamScheduler.resize(streamSlots(config.MaxConcurrentStreams))
+	old.providers.retire()
+
+	c.Logger().Info("config reloaded", slog.String("provider", string(providerType)))
+	return nil
+}
+
+// WatchConfigFile reloads the client whenever the JSON config file at path
+// changes, polling every interval (DefaultConfigWatchInterval when zero).
+// Each reload starts from NewConfig, loads the file and then applies
+// environment variables, as NewClientFromEnv does. Failed reloads leave the
+// client unchanged and are passed to onError if set. Watching stops when ctx
+// is done.
+func (c *Client) WatchConfigFile(ctx context.Context, path string, interval time.Duration, onError func(error)) error {
+	if interval <= 0 {
+		interval = DefaultConfigWatchInterval
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		lastMod, lastSize := info.ModTime(), info.Size()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				if onError != nil {
+					onError(fmt.Errorf("failed to stat config file: %w", err))
+				}
+				continue
+			}
+			if info.ModTime().Equal(lastMod) && info.Size() == lastSize {
+				continue
+			}
+			lastMod, lastSize = info.ModTime(), info.Size()
+
+			if err := c.reloadConfigFile(path); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}()
+	return nil
+}
+
+// reloadConfigFile builds a fresh config from path and the environment and reloads it
+func (c *Client) reloadConfigFile(path string) error {
+	config := gomini.NewConfig()
+	if err := config.LoadFromFile(path); err != nil {
+		return err
+	}
+	if err := config.LoadFromEnv(); err != nil {
+		return fmt.Errorf("failed to load config from environment: %w", err)
+	}
+	return c.ReloadConfig(config)
+}
@@ -0,0 +1,301 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// heldStreamProvider streams whatever is sent on events and records Close
+type heldStreamProvider struct {
+	MockProvider
+	events  chan providers.StreamEvent
+	started chan struct{}
+	closed  atomic.Bool
+}
+
+func newHeldStreamProvider() *heldStreamProvider {
+	return &heldStreamProvider{
+		MockProvider: MockProvider{providerType: providers.ProviderOpenAI},
+		events:       make(chan providers.StreamEvent),
+		started:      make(chan struct{}),
+	}
+}
+
+func (m *heldStreamProvider) SendMessageStream(ctx context.Context, request *gomini.ChatRequest) <-chan providers.StreamEvent {
+	close(m.started)
+	return m.events
+}
+
+func (m *heldStreamProvider) Close() error {
+	m.closed.Store(true)
+	return nil
+}
+
+// heldSendProvider blocks SendMessage until release is closed and fails
+// calls made after Close
+type heldSendProvider struct {
+	MockProvider
+	entered chan struct{}
+	release chan struct{}
+	closed  atomic.Bool
+}
+
+func (m *heldSendProvider) SendMessage(ctx context.Context, request *gomini.ChatRequest) (*gomini.ChatResponse, error) {
+	m.entered <- struct{}{}
+	<-m.release
+	if m.closed.Load() {
+		return nil, fmt.Errorf("provider closed during the call")
+	}
+	return &gomini.ChatResponse{
+		Provider: m.providerType,
+		Model:    request.Model,
+		Choices:  []gomini.Choice{{Message: gomini.NewAssistantMessage("ok")}},
+	}, nil
+}
+
+func (m *heldSendProvider) Close() error {
+	m.closed.Store(true)
+	return nil
+}
+
+func newReloadConfig(maxTurns int, enabled ...providers.ProviderType) *gomini.Config {
+	config := gomini.NewConfig()
+	config.MaxSessionTurns = maxTurns
+	for _, providerType := range enabled {
+		config.Providers[providerType] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key"}
+	}
+	return config
+}
+
+func TestClient_ReloadConfig(t *testing.T) {
+	client, err := NewClient(newReloadConfig(100, providers.ProviderOpenAI))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	old := newHeldStreamProvider()
+	useProvider(client, old)
+
+	var built []providers.ProviderType
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		built = append(built, providerType)
+		return &MockProvider{providerType: providerType}, nil
+	}
+
+	stream := client.SendMessageStream(context.Background(), &gomini.ChatRequest{Model: "test-model"}, "prompt-1")
+	<-old.started
+
+	// Invalid configs leave the client untouched
+	if err := client.ReloadConfig(gomini.NewConfig()); err == nil {
+		t.Fatal("Expected reload without providers to fail")
+	}
+	if client.GetCurrentProvider() != old || client.currentState().config.MaxSessionTurns != 100 {
+		t.Fatal("Expected failed reload to keep the client unchanged")
+	}
+
+	if err := client.ReloadConfig(newReloadConfig(5, providers.ProviderOpenAI)); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if client.GetCurrentProvider() == old || client.currentState().config.MaxSessionTurns != 5 {
+		t.Fatal("Expected provider and limits to be swapped")
+	}
+	if old.closed.Load() {
+		t.Fatal("Expected old provider to stay open while a stream is in flight")
+	}
+
+	// The in-flight stream finishes on the old provider
+	old.events <- providers.NewContentEvent(providers.ProviderOpenAI, "test-model", "still here", true)
+	close(old.events)
+	var texts []string
+	for event := range stream {
+		if content, ok := event.Data.(gomini.ContentEvent); ok {
+			texts = append(texts, content.Text)
+		}
+	}
+	if len(texts) != 1 || texts[0] != "still here" {
+		t.Errorf("Expected in-flight stream to complete, got %v", texts)
+	}
+	if !old.closed.Load() {
+		t.Error("Expected old provider to be closed after its stream finished")
+	}
+
+	// A disabled active provider is replaced by an enabled one
+	if err := client.ReloadConfig(newReloadConfig(5, providers.ProviderGemini)); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if client.GetCurrentProviderType() != providers.ProviderGemini {
+		t.Errorf("Expected switch to gemini, got %s", client.GetCurrentProviderType())
+	}
+	if len(built) != 2 || built[0] != providers.ProviderOpenAI || built[1] != providers.ProviderGemini {
+		t.Errorf("Unexpected providers built: %v", built)
+	}
+}
+
+func TestClient_ReloadConfigDuringSendMessage(t *testing.T) {
+	client, err := NewClient(newReloadConfig(100, providers.ProviderOpenAI))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	old := &heldSendProvider{
+		MockProvider: MockProvider{providerType: providers.ProviderOpenAI},
+		entered:      make(chan struct{}),
+		release:      make(chan struct{}),
+	}
+	useProvider(client, old)
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		return &MockProvider{providerType: providerType}, nil
+	}
+
+	const calls = 4
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+				Model:    "gpt-4o",
+				Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+			})
+			errs <- err
+		}()
+	}
+	for i := 0; i < calls; i++ {
+		<-old.entered
+	}
+
+	// Reloads run while the calls are in flight and readers load the state
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(maxTurns int) {
+			defer wg.Done()
+			if err := client.ReloadConfig(newReloadConfig(maxTurns, providers.ProviderOpenAI)); err != nil {
+				errs <- err
+			}
+			_ = client.GetCurrentProviderType()
+			_ = client.NewSession("reader").MaxTurns()
+		}(i + 1)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if old.closed.Load() {
+		t.Fatal("Expected the old provider to stay open while calls are in flight")
+	}
+
+	close(old.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if !old.closed.Load() {
+		t.Error("Expected the old provider to be closed after its calls finished")
+	}
+}
+
+func TestClient_WatchConfigFile(t *testing.T) {
+	t.Setenv("GOMINI_CONFIG", "")
+	t.Setenv("GOMINI_PROFILE", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	path := filepath.Join(t.TempDir(), "gomini.json")
+	write := func(maxTurns int) {
+		t.Helper()
+		body := fmt.Sprintf(`{"providers":{"openai":{"enabled":true,"api_key":"file-key"}},"max_session_turns":%d}`, maxTurns)
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(3)
+
+	client, err := NewClient(newReloadConfig(100, providers.ProviderOpenAI))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		return &MockProvider{providerType: providerType}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 10)
+	if err := client.WatchConfigFile(ctx, path, 5*time.Millisecond, func(err error) { errs <- err }); err != nil {
+		t.Fatalf("WatchConfigFile failed: %v", err)
+	}
+
+	write(7)
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		maxTurns := client.currentState().config.MaxSessionTurns
+		if maxTurns == 7 {
+			return
+		}
+		select {
+		case err := <-errs:
+			t.Fatalf("Reload failed: %v", err)
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	t.Fatal("Expected config file change to be reloaded")
+}
+
+func TestClient_ReloadConfigDuringStreams(t *testing.T) {
+	client, err := NewClient(newReloadConfig(100, providers.ProviderOpenAI))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	provider := &pacedStreamProvider{MockProvider: MockProvider{providerType: providers.ProviderOpenAI}, events: 200}
+	useProvider(client, provider)
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		return &MockProvider{providerType: providerType}, nil
+	}
+
+	// Streams read while reloads set, change and lift MaxConcurrentStreams
+	const streams = 4
+	var wg sync.WaitGroup
+	finished := make(chan bool, streams)
+	for i := 0; i < streams; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done := false
+			for event := range client.SendMessageStream(context.Background(), &gomini.ChatRequest{
+				Model:    "gpt-4o",
+				Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+			}, "reload") {
+				done = done || event.Type == gomini.EventFinished
+			}
+			finished <- done
+		}()
+	}
+	for i := 0; i < streams; i++ {
+		for provider.sentBy(i) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	for _, limit := range []int{1, 2, 0, 1} {
+		config := newReloadConfig(100, providers.ProviderOpenAI)
+		config.MaxConcurrentStreams = limit
+		if err := client.ReloadConfig(config); err != nil {
+			t.Fatalf("ReloadConfig failed: %v", err)
+		}
+	}
+	wg.Wait()
+	close(finished)
+	for done := range finished {
+		if !done {
+			t.Error("Expected every stream to finish")
+		}
+	}
+}
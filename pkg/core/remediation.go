@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// checkCapabilities rejects a request whose input or settings the model's
+// catalog entry says it cannot accept, with hints on how to fix it. Models
+// missing from the catalog are left for the provider to judge.
+func (c *Client) checkCapabilities(ctx context.Context, st *clientState, request *gomini.ChatRequest) error {
+	hasAudio := providers.HasAudioInput(request.Messages)
+	_, hasReasoning := providers.ReasoningFromConfig(request.Config)
+	if !hasAudio && !hasReasoning {
+		return nil
+	}
+	models, err := st.provider.ListModels(ctx)
+	if err != nil {
+		return nil
+	}
+	model, ok := providers.FindModel(models, request.Model)
+	if !ok {
+		return nil
+	}
+
+	if hasAudio && !model.Capabilities.AudioInput {
+		llmErr := c.capabilityError(st, models, request.Model, "does not accept audio input", "accepts audio input",
+			func(capabilities providers.ModelCapabilities) bool { return capabilities.AudioInput })
+		return llmErr.WithRemediation(gomini.Remediation{
+			Action: gomini.RemediationRemoveInput,
+			Input:  "audio",
+			Hint:   "remove the audio parts from the messages",
+		})
+	}
+	if hasReasoning && !model.Capabilities.Reasoning {
+		llmErr := c.capabilityError(st, models, request.Model, "does not accept reasoning settings", "accepts reasoning settings",
+			func(capabilities providers.ModelCapabilities) bool { return capabilities.Reasoning })
+		return llmErr.WithRemediation(gomini.Remediation{
+			Action: gomini.RemediationRemoveInput,
+			Input:  providers.ConfigReasoning,
+			Hint:   "remove the reasoning setting from the request",
+		})
+	}
+	return nil
+}
+
+// capabilityError returns an unsupported feature error for model, hinting at
+// the first catalog model that has the capability
+func (c *Client) capabilityError(st *clientState, models []providers.Model, model, problem, reason string, has func(providers.ModelCapabilities) bool) *gomini.LLMError {
+	llmErr := gomini.NewLLMError(gomini.ErrorUnsupportedFeature,
+		fmt.Sprintf("model %s %s", model, problem), st.providerType, nil)
+	llmErr.Model = model
+	for _, candidate := range models {
+		if has(candidate.Capabilities) {
+			llmErr.WithRemediation(c.switchModelRemediation(st, candidate.ID, reason))
+			break
+		}
+	}
+	return llmErr
+}
+
+// remediateError attaches hints to a failed request when the provider
+// rejected it for exceeding the model's context window. Other errors are
+// returned unchanged.
+func (c *Client) remediateError(ctx context.Context, st *clientState, request *gomini.ChatRequest, err error) error {
+	var allFailed *gomini.AllProvidersFailedError
+	if err == nil || errors.As(err, &allFailed) {
+		return err
+	}
+	llmErr := gomini.WrapProviderError(err, st.providerType, request.Model)
+	if llmErr.Code != gomini.ErrorTokenLimitExceeded {
+		return err
+	}
+	if len(gomini.Remediations(llmErr)) > 0 {
+		return llmErr
+	}
+
+	estimated := gomini.EstimateTokens(request.Messages)
+	contextSize := c.modelContextSize(ctx, st.provider, request.Model)
+	reduce := gomini.Remediation{
+		Action: gomini.RemediationReducePrompt,
+		Hint:   "shorten the prompt",
+	}
+	if contextSize > 0 && estimated > contextSize {
+		reduce.Tokens = estimated - contextSize
+		reduce.Hint = fmt.Sprintf("shorten the prompt by about %d tokens", reduce.Tokens)
+	}
+	if larger := c.largerContextModel(ctx, st, request.Model, max(estimated, contextSize+1)); larger != nil {
+		llmErr.WithRemediation(c.switchModelRemediation(st, larger.ID,
+			fmt.Sprintf("has a %d-token context window", larger.ContextSize)))
+	}
+	return llmErr.WithRemediation(reduce)
+}
+
+// largerContextModel returns the catalog model with the smallest context
+// window of at least minTokens, or nil if none fits
+func (c *Client) largerContextModel(ctx context.Context, st *clientState, exclude string, minTokens int) *providers.Model {
+	models, err := st.provider.ListModels(ctx)
+	if err != nil {
+		return nil
+	}
+	var best *providers.Model
+	for i, model := range models {
+		if model.ID == exclude || model.ContextSize < minTokens {
+			continue
+		}
+		if best == nil || model.ContextSize < best.ContextSize {
+			best = &models[i]
+		}
+	}
+	return best
+}
+
+// switchModelRemediation suggests retrying on another model of st's provider
+func (c *Client) switchModelRemediation(st *clientState, model, reason string) gomini.Remediation {
+	return gomini.Remediation{
+		Action:   gomini.RemediationSwitchModel,
+		Provider: st.providerType,
+		Model:    model,
+		Hint:     fmt.Sprintf("switch to %s, which %s", model, reason),
+	}
+}
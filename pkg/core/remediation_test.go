@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestClient_AudioInputRemediation(t *testing.T) {
+	client := newShutdownClient(t)
+	useProvider(client, &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		models: []gomini.Model{
+			{ID: "text-model"},
+			{ID: "audio-model", Capabilities: gomini.ModelCapabilities{AudioInput: true}},
+		},
+	})
+
+	request := &gomini.ChatRequest{
+		Model: "text-model",
+		Messages: []gomini.Message{
+			gomini.NewAudioMessage("transcribe", gomini.AudioPart{MIMEType: "audio/wav", Data: []byte("x")}),
+		},
+	}
+	_, err := client.SendMessage(context.Background(), request)
+
+	var llmErr *gomini.LLMError
+	if !errors.As(err, &llmErr) || llmErr.Code != gomini.ErrorUnsupportedFeature {
+		t.Fatalf("Expected unsupported_feature error, got %v", err)
+	}
+	remediations := gomini.Remediations(err)
+	if len(remediations) != 2 {
+		t.Fatalf("Expected 2 remediations, got %+v", remediations)
+	}
+	if remediations[0].Action != gomini.RemediationSwitchModel || remediations[0].Model != "audio-model" {
+		t.Errorf("Expected switch to audio-model, got %+v", remediations[0])
+	}
+	if remediations[1].Action != gomini.RemediationRemoveInput || remediations[1].Input != "audio" {
+		t.Errorf("Expected audio removal, got %+v", remediations[1])
+	}
+
+	// The same request on a capable model goes through
+	request.Model = "audio-model"
+	if _, err := client.SendMessage(context.Background(), request); err != nil {
+		t.Errorf("Expected audio-model to accept audio, got %v", err)
+	}
+}
+
+func TestClient_ReasoningCapability(t *testing.T) {
+	client := newShutdownClient(t)
+	mockProvider := &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		models: []gomini.Model{
+			{ID: "gpt-4o"},
+			{ID: "o3-mini", Capabilities: gomini.ModelCapabilities{Reasoning: true}},
+		},
+	}
+	useProvider(client, mockProvider)
+	ctx := context.Background()
+	messages := []gomini.Message{gomini.NewUserMessage("prove it")}
+	reasoning := WithReasoning(gomini.Reasoning{Effort: gomini.ReasoningEffortHigh})
+
+	_, err := client.Send(ctx, messages, WithModel("gpt-4o"), reasoning)
+	remediations := gomini.Remediations(err)
+	if len(remediations) != 2 || remediations[0].Model != "o3-mini" || remediations[1].Input != providers.ConfigReasoning {
+		t.Fatalf("Expected switch and removal hints, got %v (%+v)", err, remediations)
+	}
+
+	if _, err := client.Send(ctx, messages, WithModel("o3-mini"), reasoning); err != nil {
+		t.Fatalf("Expected o3-mini to accept reasoning, got %v", err)
+	}
+	config := mockProvider.lastRequest.Config.(map[string]interface{})
+	if got, _ := providers.ReasoningFromConfig(config); got.Effort != gomini.ReasoningEffortHigh {
+		t.Errorf("Expected the reasoning setting to reach the provider, got %+v", config)
+	}
+
+	// Models missing from the catalog are left to the provider
+	if _, err := client.Send(ctx, messages, WithModel("o5"), reasoning); err != nil {
+		t.Errorf("Expected an unknown model to pass, got %v", err)
+	}
+}
+
+func TestClient_ContextOverflowRemediation(t *testing.T) {
+	client := newShutdownClient(t)
+	useProvider(client, &failingMockProvider{
+		MockProvider: MockProvider{
+			providerType: providers.ProviderOpenAI,
+			models: []gomini.Model{
+				{ID: "small-model", ContextSize: 10},
+				{ID: "huge-model", ContextSize: 1000000},
+				{ID: "large-model", ContextSize: 100000},
+			},
+		},
+		err: errors.New("400 Bad Request: maximum context length is 10 tokens"),
+	})
+
+	request := &gomini.ChatRequest{
+		Model:    "small-model",
+		Messages: []gomini.Message{gomini.NewUserMessage(strings.Repeat("word ", 100))},
+	}
+	_, err := client.SendMessage(context.Background(), request)
+
+	var llmErr *gomini.LLMError
+	if !errors.As(err, &llmErr) || llmErr.Code != gomini.ErrorTokenLimitExceeded {
+		t.Fatalf("Expected token_limit_exceeded error, got %v", err)
+	}
+	remediations := llmErr.Remediations()
+	if len(remediations) != 2 {
+		t.Fatalf("Expected 2 remediations, got %+v", remediations)
+	}
+	if remediations[0].Action != gomini.RemediationSwitchModel || remediations[0].Model != "large-model" {
+		t.Errorf("Expected switch to the smallest model that fits, got %+v", remediations[0])
+	}
+	excess := gomini.EstimateTokens(request.Messages) - 10
+	if remediations[1].Action != gomini.RemediationReducePrompt || remediations[1].Tokens != excess {
+		t.Errorf("Expected to reduce the prompt by %d tokens, got %+v", excess, remediations[1])
+	}
+}
+
+func TestClient_OtherErrorsUnchanged(t *testing.T) {
+	client := newShutdownClient(t)
+	providerErr := errors.New("503 service unavailable")
+	useProvider(client, &failingMockProvider{
+		MockProvider: MockProvider{providerType: providers.ProviderOpenAI},
+		err:          providerErr,
+	})
+
+	_, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	})
+	if err != providerErr {
+		t.Errorf("Expected the provider error unchanged, got %v", err)
+	}
+}
+
+func TestToolDisabledRemediation(t *testing.T) {
+	client := newShutdownClient(t)
+	_, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+		Model:         "gpt-4o",
+		Messages:      []gomini.Message{gomini.NewUserMessage("hi")},
+		Tools:         []gomini.Tool{weatherTool},
+		ToolChoice:    "required",
+		DisabledTools: []string{"get_weather", "unused"},
+	})
+
+	remediations := gomini.Remediations(err)
+	if len(remediations) != 1 || remediations[0].Action != gomini.RemediationEnableTool || remediations[0].Input != "get_weather" {
+		t.Errorf("Expected a hint to enable get_weather, got %+v", remediations)
+	}
+}
+
+func TestLLMError_RemediationsFromJSON(t *testing.T) {
+	llmErr := gomini.NewLLMError(gomini.ErrorBudgetExceeded, "over budget", "", nil)
+	llmErr.Details = map[string]interface{}{
+		gomini.DetailRemediations: []interface{}{
+			map[string]interface{}{"action": "raise_budget", "hint": "raise it"},
+		},
+	}
+
+	remediations := llmErr.Remediations()
+	if len(remediations) != 1 || remediations[0].Action != gomini.RemediationRaiseBudget {
+		t.Errorf("Expected decoded raise_budget hint, got %+v", remediations)
+	}
+	if gomini.Remediations(errors.New("plain")) != nil {
+		t.Error("Expected no remediations on a plain error")
+	}
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// requestQueues caps the requests in flight to each provider at its
+// ProviderConfig.MaxConcurrentRequests. A request holds its slot for the
+// whole call, or until its stream ends; excess requests wait by priority,
+// then arrival, until a slot frees or their context is done.
+type requestQueues struct {
+	mu     sync.Mutex
+	queues map[providers.ProviderType]*streamScheduler
+	limits map[providers.ProviderType]int
+	closed bool
+}
+
+// queue returns the queue of providerType sized to limit, or nil when the
+// provider has never been limited
+func (q *requestQueues) queue(providerType providers.ProviderType, limit int) *streamScheduler {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queue, ok := q.queues[providerType]
+	if !ok {
+		if limit <= 0 {
+			return nil
+		}
+		if q.queues == nil {
+			q.queues = make(map[providers.ProviderType]*streamScheduler)
+			q.limits = make(map[providers.ProviderType]int)
+		}
+		queue = newStreamScheduler(limit)
+		if q.closed {
+			queue.close()
+		}
+		q.queues[providerType], q.limits[providerType] = queue, limit
+		return queue
+	}
+	if limit != q.limits[providerType] {
+		// Requests may still hold slots, so a removed limit is lifted
+		// rather than the queue dropped
+		if limit <= 0 {
+			queue.resize(math.MaxInt)
+		} else {
+			queue.resize(limit)
+		}
+		q.limits[providerType] = limit
+	}
+	return queue
+}
+
+// close wakes every queued request with errSchedulerClosed
+func (q *requestQueues) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	for _, queue := range q.queues {
+		queue.close()
+	}
+}
+
+// acquireRequestSlot waits until st's provider has room for another request
+// and returns the function that frees the slot. The priority set with
+// WithStreamPriority decides which queued request goes next. Requests the
+// provider's rate limit or circuit breaker refuses fail without queueing.
+func (c *Client) acquireRequestSlot(ctx context.Context, st *clientState) (func(), error) {
+	if err := c.admitCall(ctx, st); err != nil {
+		return nil, err
+	}
+	limit := 0
+	if providerConfig, err := st.config.GetProviderConfig(st.providerType); err == nil {
+		limit = providerConfig.MaxConcurrentRequests
+	}
+	queue := c.requests.queue(st.providerType, limit)
+	if queue == nil {
+		return func() {}, nil
+	}
+	if err := queue.acquire(ctx, streamPriorityFrom(ctx)); err != nil {
+		return nil, err
+	}
+	return queue.release, nil
+}
+
+// startStream starts a stream on st's provider once a request slot is free.
+// Until the stream ends it holds the slot and counts toward the provider's
+// load; the keys tried are then counted against the provider's quota. The
+// finished event gains the stream's latency, which is also recorded for
+// ModelLatency.
+func (c *Client) startStream(ctx context.Context, st *clientState, model string, start func(context.Context) <-chan providers.StreamEvent) <-chan providers.StreamEvent {
+	providerType := st.providerType
+	queued, done := time.Now(), c.beginCall(ctx, st)
+	release, err := c.acquireRequestSlot(ctx, st)
+	if err != nil {
+		done(time.Since(queued), err)
+		failed := make(chan providers.StreamEvent, 1)
+		failed <- providers.NewErrorEvent(providerType, model, err, false)
+		close(failed)
+		return failed
+	}
+	noteCtx, note := withKeyNote(ctx)
+	started := time.Now()
+	events := start(noteCtx)
+
+	out := make(chan providers.StreamEvent, 10)
+	go func() {
+		defer close(out)
+		defer release()
+		var usage *providers.Usage
+		defer func() { c.recordQuota(ctx, st, note, usage) }()
+		var firstToken time.Time
+		var streamErr error
+		defer func() {
+			// The stream's load latency is its wait for the first token
+			answered := firstToken
+			if answered.IsZero() {
+				answered = time.Now()
+			}
+			if streamErr == nil {
+				streamErr = ctx.Err()
+			}
+			done(answered.Sub(queued), streamErr)
+		}()
+		for event := range events {
+			if firstToken.IsZero() && outputEvent(event.Type) {
+				firstToken = time.Now()
+			}
+			if event.Type == providers.EventError && streamErr == nil {
+				streamErr = event.Error
+			}
+			if event.Type == providers.EventFinished {
+				if event.Metadata.Usage != nil {
+					usage = event.Metadata.Usage
+				}
+				event.Metadata.Latency = providers.NewStreamLatency(started, firstToken, time.Now(), event.Metadata.Usage)
+				c.latency.record(ModelRef{Provider: providerType, Model: model}, *event.Metadata.Latency)
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				// Keep the slot until the provider has stopped
+				for range events {
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// gatedProvider reports each request's model on entered and answers once
+// release is closed or receives
+type gatedProvider struct {
+	*MockProvider
+	entered chan string
+	release chan struct{}
+}
+
+func (p *gatedProvider) SendMessage(ctx context.Context, request *gomini.ChatRequest) (*gomini.ChatResponse, error) {
+	p.entered <- request.Model
+	<-p.release
+	return &gomini.ChatResponse{Choices: []gomini.Choice{{Message: gomini.NewAssistantMessage("ok")}}}, nil
+}
+
+func newQueueClient(t *testing.T, limit int) (*Client, *gatedProvider) {
+	t.Helper()
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key", MaxConcurrentRequests: limit}
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	provider := &gatedProvider{
+		MockProvider: &MockProvider{providerType: providers.ProviderOpenAI},
+		entered:      make(chan string, 10),
+		release:      make(chan struct{}),
+	}
+	useProvider(client, provider)
+	return client, provider
+}
+
+func expectEntered(t *testing.T, provider *gatedProvider, want string) {
+	t.Helper()
+	select {
+	case got := <-provider.entered:
+		if got != want {
+			t.Fatalf("Expected %s to reach the provider, got %s", want, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for %s", want)
+	}
+}
+
+func TestRequestQueue_LimitsAndPriorities(t *testing.T) {
+	client, provider := newQueueClient(t, 1)
+	send := func(ctx context.Context, model string) {
+		go client.sendWithTimeout(ctx, client.currentState(), &gomini.ChatRequest{Model: model})
+	}
+
+	send(context.Background(), "first")
+	expectEntered(t, provider, "first")
+
+	send(WithStreamPriority(context.Background(), PriorityBatch), "batch")
+	time.Sleep(20 * time.Millisecond)
+	send(WithStreamPriority(context.Background(), PriorityInteractive), "interactive")
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case model := <-provider.entered:
+		t.Fatalf("Expected %s to wait for a slot", model)
+	default:
+	}
+
+	// Freed slots go to the interactive request ahead of the earlier batch one
+	provider.release <- struct{}{}
+	expectEntered(t, provider, "interactive")
+	provider.release <- struct{}{}
+	expectEntered(t, provider, "batch")
+	provider.release <- struct{}{}
+}
+
+func TestRequestQueue_CancelWhileQueued(t *testing.T) {
+	client, provider := newQueueClient(t, 1)
+	go client.sendWithTimeout(context.Background(), client.currentState(), &gomini.ChatRequest{Model: "first"})
+	expectEntered(t, provider, "first")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.sendWithTimeout(ctx, client.currentState(), &gomini.ChatRequest{Model: "queued"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the queued request to give up with its context, got %v", err)
+	}
+
+	// The cancelled request left no slot behind
+	provider.release <- struct{}{}
+	go client.sendWithTimeout(context.Background(), client.currentState(), &gomini.ChatRequest{Model: "next"})
+	expectEntered(t, provider, "next")
+	provider.release <- struct{}{}
+}
+
+func TestRequestQueue_StreamsHoldSlots(t *testing.T) {
+	client, provider := newQueueClient(t, 1)
+	source := make(chan providers.StreamEvent)
+	events := client.startStream(context.Background(), client.currentState(), "stream", func(ctx context.Context) <-chan providers.StreamEvent {
+		return source
+	})
+
+	go client.sendWithTimeout(context.Background(), client.currentState(), &gomini.ChatRequest{Model: "queued"})
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-provider.entered:
+		t.Fatal("Expected the request to wait for the stream to end")
+	default:
+	}
+
+	close(source)
+	for range events {
+	}
+	expectEntered(t, provider, "queued")
+	provider.release <- struct{}{}
+}
+
+func TestRequestQueue_Shutdown(t *testing.T) {
+	client, provider := newQueueClient(t, 1)
+	go client.sendWithTimeout(context.Background(), client.currentState(), &gomini.ChatRequest{Model: "first"})
+	expectEntered(t, provider, "first")
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.sendWithTimeout(context.Background(), client.currentState(), &gomini.ChatRequest{Model: "queued"})
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	client.requests.close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, errSchedulerClosed) {
+			t.Errorf("Expected errSchedulerClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the queued request to be released")
+	}
+	close(provider.release)
+}
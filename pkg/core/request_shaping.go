@@ -0,0 +1,94 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"gomini/pkg/gomini"
+)
+
+// dedupeSystemMessages drops repeated system messages whose text is identical
+// after trimming whitespace, keeping the first occurrence in place, and trims
+// the ones it keeps. It returns the original slice when nothing changed.
+func dedupeSystemMessages(messages []gomini.Message) ([]gomini.Message, int) {
+	seen := make(map[string]bool)
+	shaped := make([]gomini.Message, 0, len(messages))
+	removed := 0
+	changed := false
+
+	for _, message := range messages {
+		msgMap, ok := message.(map[string]interface{})
+		text, isText := msgMap["content"].(string)
+		if !ok || msgMap["role"] != "system" || !isText {
+			shaped = append(shaped, message)
+			continue
+		}
+
+		normalized := strings.TrimSpace(text)
+		if seen[normalized] {
+			removed++
+			changed = true
+			continue
+		}
+		seen[normalized] = true
+
+		if normalized != text {
+			trimmed := make(map[string]interface{}, len(msgMap))
+			for key, value := range msgMap {
+				trimmed[key] = value
+			}
+			trimmed["content"] = normalized
+			message = trimmed
+			changed = true
+		}
+		shaped = append(shaped, message)
+	}
+
+	if !changed {
+		return messages, 0
+	}
+	return shaped, removed
+}
+
+// shapeChatRequest returns the request with its history normalized, plus the
+// number of duplicate system messages removed. The caller's request is not modified.
+func (c *Client) shapeChatRequest(st *clientState, request *gomini.ChatRequest) (*gomini.ChatRequest, int) {
+	messages, removed := dedupeSystemMessages(request.Messages)
+	if len(messages) == 0 || &messages[0] == &request.Messages[0] {
+		return request, 0
+	}
+
+	shaped := *request
+	shaped.Messages = messages
+	c.logSystemDedupe(removed)
+	return &shaped, removed
+}
+
+// shapeJSONRequest is shapeChatRequest for JSON requests
+func (c *Client) shapeJSONRequest(st *clientState, request *gomini.JSONRequest) *gomini.JSONRequest {
+	messages, removed := dedupeSystemMessages(request.Messages)
+	if len(messages) == 0 || &messages[0] == &request.Messages[0] {
+		return request
+	}
+
+	shaped := *request
+	shaped.Messages = messages
+	c.logSystemDedupe(removed)
+	return &shaped
+}
+
+func (c *Client) logSystemDedupe(removed int) {
+	if removed > 0 {
+		c.Logger().Debug("removed duplicate system messages from request history", slog.Int("removed", removed))
+	}
+}
+
+// newSystemDedupeEvent notes a history correction on the event stream
+func newSystemDedupeEvent(st *clientState, model string, removed int) gomini.StreamEvent {
+	event := gomini.NewDebugEvent(st.providerType, "info",
+		fmt.Sprintf("removed %d duplicate system message(s) from request history", removed),
+		map[string]interface{}{"removed_system_messages": removed})
+	event.Model = model
+	return event
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestDedupeSystemMessages(t *testing.T) {
+	messages := []gomini.Message{
+		gomini.NewSystemMessage("You are helpful."),
+		gomini.NewUserMessage("Hi"),
+		gomini.NewSystemMessage("  You are helpful.\n"),
+		gomini.NewSystemMessage("Answer in French."),
+		gomini.NewAssistantMessage("Bonjour"),
+		gomini.NewSystemMessage("You are helpful."),
+	}
+
+	shaped, removed := dedupeSystemMessages(messages)
+	if removed != 2 {
+		t.Errorf("Expected 2 duplicates removed, got %d", removed)
+	}
+	if len(shaped) != 4 {
+		t.Fatalf("Expected 4 messages, got %d: %v", len(shaped), shaped)
+	}
+	if shaped[2].(map[string]interface{})["content"] != "Answer in French." {
+		t.Errorf("Expected distinct system message to be kept in place, got %v", shaped[2])
+	}
+	if len(messages) != 6 {
+		t.Error("Input slice should not be modified")
+	}
+
+	clean := []gomini.Message{gomini.NewSystemMessage("Be brief."), gomini.NewUserMessage("Hi")}
+	if shaped, removed := dedupeSystemMessages(clean); removed != 0 || &shaped[0] != &clean[0] {
+		t.Error("Expected clean history to be returned unchanged")
+	}
+
+	padded := []gomini.Message{gomini.NewSystemMessage(" Be brief. ")}
+	shaped, removed = dedupeSystemMessages(padded)
+	if removed != 0 || shaped[0].(map[string]interface{})["content"] != "Be brief." {
+		t.Errorf("Expected system message to be trimmed, got %v", shaped)
+	}
+	if padded[0].(map[string]interface{})["content"] != " Be brief. " {
+		t.Error("Caller's message should not be modified")
+	}
+}
+
+func TestClient_SendMessageStream_SystemDedupe(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	mockProvider := &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		responses:    []gomini.StreamEvent{{Type: gomini.EventFinished}},
+	}
+	useProvider(client, mockProvider)
+
+	request := &gomini.ChatRequest{
+		Messages: []gomini.Message{
+			gomini.NewSystemMessage("You are helpful."),
+			gomini.NewSystemMessage("You are helpful."),
+			gomini.NewUserMessage("Hi"),
+		},
+		Model: "gpt-4o",
+	}
+
+	var debugEvents int
+	for event := range client.SendMessageStream(context.Background(), request, "dedupe") {
+		if event.Type == gomini.EventDebug {
+			debugEvents++
+		}
+	}
+
+	if debugEvents != 1 {
+		t.Errorf("Expected one debug event, got %d", debugEvents)
+	}
+	if len(mockProvider.lastRequest.Messages) != 2 {
+		t.Errorf("Expected deduplicated history to reach the provider, got %v", mockProvider.lastRequest.Messages)
+	}
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gomini/pkg/gomini"
+)
+
+// applyRequirements handles ChatRequest.Require. A request without a model
+// goes to the cheapest enabled model meeting the requirements, priced as in
+// ListAllModels with unpriced models last; a request whose model is in a
+// catalog but falls short is rejected with ErrorUnsupportedFeature and a hint
+// naming the cheapest model that fits.
+func (c *Client) applyRequirements(ctx context.Context, st *clientState, request *gomini.ChatRequest) (*gomini.ChatRequest, error) {
+	if request.Require == nil {
+		return request, nil
+	}
+	require := *request.Require
+	// Providers that cannot be listed simply offer no candidates
+	listings, _ := c.listAllModels(ctx, st)
+
+	var candidates []ModelListing
+	for _, listing := range listings {
+		if len(require.Missing(listing.Model)) == 0 {
+			candidates = append(candidates, listing)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Priced != candidates[j].Priced {
+			return candidates[i].Priced
+		}
+		return candidates[i].Price.Input+candidates[i].Price.Output < candidates[j].Price.Input+candidates[j].Price.Output
+	})
+
+	if request.Model != "" {
+		for _, listing := range listings {
+			if listing.ID != request.Model || (request.Provider != "" && listing.Provider != request.Provider) {
+				continue
+			}
+			missing := require.Missing(listing.Model)
+			if len(missing) == 0 {
+				return request, nil
+			}
+			llmErr := gomini.NewLLMError(gomini.ErrorUnsupportedFeature,
+				fmt.Sprintf("model %s lacks required %s", request.Model, strings.Join(missing, ", ")), listing.Provider, nil)
+			llmErr.Model = request.Model
+			if len(candidates) > 0 {
+				best := candidates[0]
+				llmErr.WithRemediation(gomini.Remediation{
+					Action:   gomini.RemediationSwitchModel,
+					Provider: best.Provider,
+					Model:    best.ID,
+					Hint:     fmt.Sprintf("switch to %s, the cheapest model meeting the requirements", best.ID),
+				})
+			}
+			return nil, llmErr
+		}
+		// Models missing from the catalogs are left for the provider to judge
+		return request, nil
+	}
+
+	if len(candidates) == 0 {
+		wanted := require.Missing(gomini.Model{})
+		return nil, gomini.NewLLMError(gomini.ErrorUnsupportedFeature,
+			fmt.Sprintf("no enabled model has %s", strings.Join(wanted, ", ")), "", nil)
+	}
+	routed := *request
+	routed.Model = candidates[0].ID
+	routed.Provider = candidates[0].Provider
+	return &routed, nil
+}
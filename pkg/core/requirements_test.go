@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestClient_ApplyRequirements(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key"}
+	config.Providers[providers.ProviderGemini] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key"}
+	config.DefaultProvider = providers.ProviderOpenAI
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	vision := gomini.ModelCapabilities{TextGeneration: true, ImageInput: true, FunctionCalling: true}
+	useProvider(client, &MockProvider{providerType: providers.ProviderOpenAI, models: []gomini.Model{
+		{ID: "big-vision", Capabilities: vision, ContextSize: 128000, Cost: &providers.ModelCost{InputTokens: 2.5, OutputTokens: 10}},
+		{ID: "small-vision", Capabilities: vision, ContextSize: 128000, Cost: &providers.ModelCost{InputTokens: 0.15, OutputTokens: 0.6}},
+		{ID: "text-only", Capabilities: gomini.ModelCapabilities{TextGeneration: true}, ContextSize: 16384, Cost: &providers.ModelCost{InputTokens: 0.1, OutputTokens: 0.1}},
+		{ID: "unpriced-vision", Capabilities: vision, ContextSize: 128000},
+	}})
+	client.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		return &MockProvider{providerType: providerType, models: []gomini.Model{
+			{ID: "long-vision", Capabilities: vision, ContextSize: 2000000, Cost: &providers.ModelCost{InputTokens: 1.25, OutputTokens: 5}},
+		}}, nil
+	}
+
+	tests := []struct {
+		name         string
+		model        string
+		require      gomini.Requirements
+		wantModel    string
+		wantProvider providers.ProviderType
+		wantErr      bool
+		wantHint     string
+	}{
+		{name: "cheapest vision", require: gomini.Requirements{Vision: true}, wantModel: "small-vision", wantProvider: providers.ProviderOpenAI},
+		{name: "long context", require: gomini.Requirements{Vision: true, MinContext: 200000}, wantModel: "long-vision", wantProvider: providers.ProviderGemini},
+		{name: "cheapest of all", wantModel: "text-only", wantProvider: providers.ProviderOpenAI},
+		{name: "unmet", require: gomini.Requirements{Reasoning: true}, wantErr: true},
+		{name: "model meets", model: "big-vision", require: gomini.Requirements{Vision: true}, wantModel: "big-vision"},
+		{name: "model falls short", model: "text-only", require: gomini.Requirements{Vision: true}, wantErr: true, wantHint: "small-vision"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := tt.require
+			request := &gomini.ChatRequest{Model: tt.model, Require: &require}
+			routed, err := client.applyRequirements(context.Background(), client.currentState(), request)
+			if tt.wantErr {
+				if !errors.Is(err, gomini.ErrUnsupportedFeature) {
+					t.Fatalf("Expected ErrUnsupportedFeature, got %v", err)
+				}
+				if tt.wantHint != "" {
+					if fixes := gomini.Remediations(err); len(fixes) == 0 || fixes[0].Model != tt.wantHint {
+						t.Errorf("Expected a hint to switch to %s, got %+v", tt.wantHint, fixes)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyRequirements failed: %v", err)
+			}
+			if routed.Model != tt.wantModel || routed.Provider != tt.wantProvider {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.wantProvider, tt.wantModel, routed.Provider, routed.Model)
+			}
+		})
+	}
+}
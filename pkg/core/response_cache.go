@@ -0,0 +1,525 @@
+package core
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gomini/pkg/gomini"
+)
+
+const (
+	// DefaultCacheTTL is used when CacheConfig.TTL is unset
+	DefaultCacheTTL = time.Hour
+
+	// DefaultCacheMaxEntries is used when CacheConfig.MaxEntries is unset
+	DefaultCacheMaxEntries = 1000
+)
+
+// ErrCacheMiss is returned by ResponseCache.Get for keys it does not hold
+var ErrCacheMiss = errors.New("cache miss")
+
+// ResponseCache stores encoded responses by request hash
+type ResponseCache interface {
+	// Get returns the value stored under key, or ErrCacheMiss
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores value under key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the value stored under key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryCache is an in-process ResponseCache that evicts the least recently
+// used entries. It is an ExpiringStore, so the client's cleanup janitor
+// removes expired entries that are never read again.
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // Front is most recently used
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+	deleted bool
+}
+
+// live reports whether entry can still be served
+func (e *memoryCacheEntry) live() bool {
+	return !e.deleted && time.Now().Before(e.expires)
+}
+
+// NewMemoryCache creates a MemoryCache holding up to capacity entries
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity <= 0 {
+		capacity = DefaultCacheMaxEntries
+	}
+	return &MemoryCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get implements ResponseCache
+func (m *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	entry := element.Value.(*memoryCacheEntry)
+	if !entry.live() {
+		if !entry.deleted {
+			m.remove(element)
+		}
+		return nil, ErrCacheMiss
+	}
+	m.order.MoveToFront(element)
+	return entry.value, nil
+}
+
+// Set implements ResponseCache
+func (m *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memoryCacheEntry{key: key, value: value, expires: time.Now().Add(ttl)}
+	if element, ok := m.entries[key]; ok {
+		element.Value = entry
+		m.order.MoveToFront(element)
+		return nil
+	}
+	m.entries[key] = m.order.PushFront(entry)
+	for m.order.Len() > m.capacity {
+		m.remove(m.order.Back())
+	}
+	return nil
+}
+
+// Delete implements ResponseCache
+func (m *MemoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+	return nil
+}
+
+// Purge removes every entry
+func (m *MemoryCache) Purge(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = make(map[string]*list.Element)
+	m.order.Init()
+	return nil
+}
+
+// Invalidate implements ExpiringStore.Invalidate
+func (m *MemoryCache) Invalidate(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.entries[key]; ok {
+		element.Value.(*memoryCacheEntry).deleted = true
+	}
+	return nil
+}
+
+// Undelete implements ExpiringStore.Undelete
+func (m *MemoryCache) Undelete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	element, ok := m.entries[key]
+	if !ok || time.Now().After(element.Value.(*memoryCacheEntry).expires) {
+		return ErrCacheMiss
+	}
+	element.Value.(*memoryCacheEntry).deleted = false
+	return nil
+}
+
+// Cleanup implements ExpiringStore.Cleanup
+func (m *MemoryCache) Cleanup(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := 0
+	for element := m.order.Front(); element != nil; {
+		next := element.Next()
+		if !element.Value.(*memoryCacheEntry).live() {
+			m.remove(element)
+			removed++
+		}
+		element = next
+	}
+	return removed, nil
+}
+
+// Len returns the number of entries held, including expired and invalidated
+// ones not yet cleaned up
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// remove drops element; m.mu must be held
+func (m *MemoryCache) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryCacheEntry).key)
+}
+
+// RedisClient is the subset of a Redis client RedisCache needs, so any
+// client library can be adapted without gomini depending on it. Get must
+// return ErrCacheMiss for missing keys (redis.Nil in go-redis).
+type RedisClient interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Del(ctx context.Context, key string) error
+}
+
+// RedisCache is a ResponseCache shared between processes through Redis
+type RedisCache struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisCache creates a RedisCache storing entries under prefix (default "gomini:cache:")
+func NewRedisCache(client RedisClient, prefix string) *RedisCache {
+	if prefix == "" {
+		prefix = "gomini:cache:"
+	}
+	return &RedisCache{client: client, prefix: prefix}
+}
+
+// Get implements ResponseCache
+func (r *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return r.client.Get(ctx, r.prefix+key)
+}
+
+// Set implements ResponseCache
+func (r *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl)
+}
+
+// Delete implements ResponseCache
+func (r *RedisCache) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key)
+}
+
+// SetResponseCache enables response caching with the given backend, replacing
+// the in-memory cache created from Config.Cache. A nil cache disables caching.
+func (c *Client) SetResponseCache(cache ResponseCache) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	c.replaceCache(cache)
+	c.customCache = true
+}
+
+// InvalidateCachedResponse removes the cache entry with the given key, as
+// reported in ChatResponse.CacheKey or JSONResponse.CacheKey
+func (c *Client) InvalidateCachedResponse(ctx context.Context, key string) error {
+	cache := c.responseCache()
+	if key == "" || cache == nil {
+		return nil
+	}
+	return cache.Delete(ctx, key)
+}
+
+// PurgeResponseCache removes every cached response. The backend must have a
+// Purge(context.Context) error method, as MemoryCache does.
+func (c *Client) PurgeResponseCache(ctx context.Context) error {
+	cache := c.responseCache()
+	if cache == nil {
+		return nil
+	}
+	purger, ok := cache.(interface{ Purge(context.Context) error })
+	if !ok {
+		return fmt.Errorf("response cache %T does not support purging", cache)
+	}
+	return purger.Purge(ctx)
+}
+
+// replaceCache swaps in cache, moving background cleanup from the previous
+// backend to it; c.cacheMu must be held
+func (c *Client) replaceCache(cache ResponseCache) {
+	if store, ok := c.cache.(ExpiringStore); ok && c.janitor != nil {
+		c.janitor.remove(store)
+	}
+	c.cache = cache
+	if store, ok := cache.(ExpiringStore); ok {
+		c.RegisterExpiringStore(store)
+	}
+}
+
+// reloadCache rebuilds the in-memory cache when Config.Cache changes, unless
+// a backend was set with SetResponseCache
+func (c *Client) reloadCache(previous, config *gomini.Config) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+	if c.customCache {
+		return
+	}
+	var before, after gomini.CacheConfig
+	if previous.Cache != nil {
+		before = *previous.Cache
+	}
+	if config.Cache != nil {
+		after = *config.Cache
+	}
+	if before != after {
+		c.replaceCache(newConfiguredCache(config))
+	}
+}
+
+// responseCache returns the active cache backend, if any
+func (c *Client) responseCache() ResponseCache {
+	c.cacheMu.RLock()
+	defer c.cacheMu.RUnlock()
+	return c.cache
+}
+
+// newConfiguredCache creates the in-memory cache requested by config
+func newConfiguredCache(config *gomini.Config) ResponseCache {
+	if config.Cache == nil || !config.Cache.Enabled {
+		return nil
+	}
+	return NewMemoryCache(config.Cache.MaxEntries)
+}
+
+func (c *Client) cacheTTL(st *clientState) time.Duration {
+	if st.config.Cache != nil && st.config.Cache.TTL > 0 {
+		return st.config.Cache.TTL
+	}
+	return DefaultCacheTTL
+}
+
+// cacheKey hashes the normalized request together with st's provider, which
+// will serve it. Tags only attribute usage, so they do not affect the key.
+// It returns "" when caching is off.
+func (c *Client) cacheKey(st *clientState, kind string, request interface{}) string {
+	if c.responseCache() == nil {
+		return ""
+	}
+	var normalized interface{}
+	switch r := request.(type) {
+	case *gomini.ChatRequest:
+		keyed := *r
+		keyed.Tags = nil
+		keyed.Provider = st.providerType
+		normalized = keyed
+	case *gomini.JSONRequest:
+		keyed := *r
+		keyed.Tags = nil
+		keyed.Provider = st.providerType
+		normalized = keyed
+	}
+	// Maps marshal with sorted keys, so equal requests encode identically
+	raw, err := json.Marshal(normalized)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return kind + ":" + hex.EncodeToString(sum[:])
+}
+
+// cachedValue is the encoding of a cache entry
+type cachedValue struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Response json.RawMessage `json:"response"`
+}
+
+// cacheGet decodes the entry stored under key into v, returning when it was stored
+func (c *Client) cacheGet(ctx context.Context, key string, v interface{}) (time.Time, bool) {
+	cache := c.responseCache()
+	if key == "" || cache == nil {
+		return time.Time{}, false
+	}
+	raw, err := cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrCacheMiss) {
+			c.Logger().Warn("response cache read failed", slog.String("error", err.Error()))
+		}
+		return time.Time{}, false
+	}
+	var entry cachedValue
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return time.Time{}, false
+	}
+	if err := json.Unmarshal(entry.Response, v); err != nil {
+		return time.Time{}, false
+	}
+	return entry.StoredAt, true
+}
+
+// cacheSet stores resp under key, recording the key in it; failures only
+// cost a future cache hit
+func (c *Client) cacheSet(ctx context.Context, st *clientState, key string, resp interface{}) {
+	cache := c.responseCache()
+	if key == "" || cache == nil {
+		return
+	}
+	switch r := resp.(type) {
+	case *gomini.ChatResponse:
+		r.CacheKey = key
+	case *gomini.JSONResponse:
+		r.CacheKey = key
+	}
+	response, err := json.Marshal(resp)
+	var raw []byte
+	if err == nil {
+		raw, err = json.Marshal(cachedValue{StoredAt: time.Now(), Response: response})
+	}
+	if err == nil {
+		err = cache.Set(ctx, key, raw, c.cacheTTL(st))
+	}
+	if err != nil {
+		c.Logger().Warn("response cache write failed", slog.String("error", err.Error()))
+	}
+}
+
+// isStale reports whether an entry stored at storedAt is older than
+// Config.Cache.SoftTTL
+func (c *Client) isStale(st *clientState, storedAt time.Time) bool {
+	return st.config.Cache != nil && st.config.Cache.SoftTTL > 0 && time.Since(storedAt) > st.config.Cache.SoftTTL
+}
+
+// revalidate refreshes the entry under key in the background with fetch,
+// unless a refresh of it is already running. The stale entry keeps being
+// served until fetch succeeds; a failed refresh is only logged.
+func (c *Client) revalidate(ctx context.Context, st *clientState, key string, fetch func(ctx context.Context) (interface{}, error)) {
+	if _, running := c.revalidating.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+	c.closeMu.Lock()
+	release, ok := st.providers.hold()
+	if c.closed || !ok {
+		c.closeMu.Unlock()
+		if ok {
+			release()
+		}
+		c.revalidating.Delete(key)
+		return
+	}
+	c.revalidations.Add(1)
+	c.closeMu.Unlock()
+
+	// The refresh outlives the request that found the stale entry
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer c.revalidations.Done()
+		defer c.revalidating.Delete(key)
+		defer release()
+
+		resp, err := fetch(ctx)
+		if err != nil {
+			c.Logger().Warn("response cache refresh failed", slog.String("error", err.Error()))
+			return
+		}
+		c.cacheSet(ctx, st, key, resp)
+	}()
+}
+
+// cachedChatResponse returns the cached response for key with Cached set,
+// and Stale when it is past the soft TTL
+func (c *Client) cachedChatResponse(ctx context.Context, st *clientState, key string) (*gomini.ChatResponse, bool) {
+	var resp gomini.ChatResponse
+	storedAt, ok := c.cacheGet(ctx, key, &resp)
+	if !ok {
+		return nil, false
+	}
+	restoreToolCalls(resp.Choices)
+	resp.Cached = true
+	resp.Stale = c.isStale(st, storedAt)
+	return &resp, true
+}
+
+// revalidateChat refreshes a stale chat entry from st's provider
+func (c *Client) revalidateChat(ctx context.Context, st *clientState, key string, request *gomini.ChatRequest) {
+	c.revalidate(ctx, st, key, func(ctx context.Context) (interface{}, error) {
+		return c.sendWithTimeout(ctx, st, request)
+	})
+}
+
+// restoreToolCalls turns decoded tool_calls back into []gomini.ToolCall, the
+// shape providers return
+func restoreToolCalls(choices []gomini.Choice) {
+	for _, choice := range choices {
+		message, ok := choice.Message.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		raw, ok := message["tool_calls"]
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(raw)
+		if err != nil {
+			continue
+		}
+		var toolCalls []gomini.ToolCall
+		if json.Unmarshal(data, &toolCalls) == nil {
+			message["tool_calls"] = toolCalls
+		}
+	}
+}
+
+// cachedStreamEvents replays a cached response as a stream
+func (c *Client) cachedStreamEvents(resp *gomini.ChatResponse) []gomini.StreamEvent {
+	var text string
+	var reason gomini.FinishReason
+	var logprobs []gomini.TokenLogprob
+	if len(resp.Choices) > 0 {
+		text, reason, logprobs = resp.Choices[0].Content(), resp.Choices[0].FinishReason, resp.Choices[0].Logprobs
+	}
+
+	content := gomini.NewContentEvent(resp.Provider, resp.Model, text, false)
+	content.Data = gomini.ContentEvent{Text: text, Complete: true, Logprobs: logprobs}
+	finished := gomini.NewFinishedEvent(resp.Provider, resp.Model, reason, resp.Usage)
+	finished.Metadata.Cached = true
+	finished.Metadata.Stale = resp.Stale
+	return []gomini.StreamEvent{content, finished}
+}
+
+// streamRecorder assembles a text-only stream into a cacheable response.
+// Streams with tool calls, thoughts or images are not cached.
+type streamRecorder struct {
+	collector *gomini.StreamCollector
+	cacheable bool
+}
+
+func newStreamRecorder() *streamRecorder {
+	return &streamRecorder{collector: gomini.NewStreamCollector(), cacheable: true}
+}
+
+func (r *streamRecorder) add(event gomini.StreamEvent) {
+	switch event.Type {
+	case gomini.EventContent, gomini.EventFinished, gomini.EventDebug, gomini.EventUsage:
+		r.collector.Add(event)
+	default:
+		r.cacheable = false
+	}
+}
+
+// response returns the assembled response, or nil if the stream is not cacheable
+func (r *streamRecorder) response(provider gomini.ProviderType) *gomini.ChatResponse {
+	if !r.cacheable || !r.collector.Finished() {
+		return nil
+	}
+	resp := r.collector.Response()
+	resp.ID = ""
+	resp.Provider = provider
+	return resp
+}
@@ -0,0 +1,329 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// fakeRedis is an in-memory RedisClient
+type fakeRedis struct {
+	values map[string][]byte
+}
+
+func (f *fakeRedis) Get(ctx context.Context, key string) ([]byte, error) {
+	value, ok := f.values[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (f *fakeRedis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	f.values[key] = value
+	return nil
+}
+
+func (f *fakeRedis) Del(ctx context.Context, key string) error {
+	delete(f.values, key)
+	return nil
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(2)
+
+	cache.Set(ctx, "a", []byte("1"), time.Hour)
+	cache.Set(ctx, "b", []byte("2"), time.Hour)
+	cache.Get(ctx, "a") // b is now least recently used
+	cache.Set(ctx, "c", []byte("3"), time.Hour)
+
+	if _, err := cache.Get(ctx, "b"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Expected b to be evicted, got %v", err)
+	}
+	if value, err := cache.Get(ctx, "a"); err != nil || string(value) != "1" {
+		t.Errorf("Expected a to be kept, got %q, %v", value, err)
+	}
+
+	cache.Set(ctx, "expired", []byte("4"), -time.Second)
+	if _, err := cache.Get(ctx, "expired"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Expected expired entries to miss, got %v", err)
+	}
+	// Storing the expired entry evicted c, and reading it dropped it
+	if cache.Len() != 1 {
+		t.Errorf("Expected 1 entry, got %d", cache.Len())
+	}
+}
+
+func TestMemoryCache_Expiry(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(10)
+	cache.Set(ctx, "a", []byte("1"), time.Hour)
+	cache.Set(ctx, "b", []byte("2"), time.Hour)
+	cache.Set(ctx, "expired", []byte("3"), -time.Second)
+
+	cache.Invalidate(ctx, "a")
+	if _, err := cache.Get(ctx, "a"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Expected an invalidated entry to miss, got %v", err)
+	}
+	if err := cache.Undelete(ctx, "a"); err != nil {
+		t.Fatalf("Undelete failed: %v", err)
+	}
+	if value, err := cache.Get(ctx, "a"); err != nil || string(value) != "1" {
+		t.Errorf("Expected the undeleted entry back, got %q, %v", value, err)
+	}
+
+	cache.Invalidate(ctx, "b")
+	if removed, err := cache.Cleanup(ctx); err != nil || removed != 2 {
+		t.Errorf("Expected the invalidated and expired entries removed, got %d, %v", removed, err)
+	}
+	if err := cache.Undelete(ctx, "b"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("Expected a cleaned up entry to stay deleted, got %v", err)
+	}
+
+	cache.Delete(ctx, "a")
+	cache.Set(ctx, "c", []byte("4"), time.Hour)
+	cache.Purge(ctx)
+	if cache.Len() != 0 {
+		t.Errorf("Expected an empty cache, got %d entries", cache.Len())
+	}
+}
+
+func TestClient_ResponseCacheJanitor(t *testing.T) {
+	client, _ := newCachingClient(t)
+	defer client.Close()
+	cache, ok := client.responseCache().(*MemoryCache)
+	if !ok {
+		t.Fatalf("Expected a memory cache, got %T", client.responseCache())
+	}
+	cache.Set(context.Background(), "expired", []byte("1"), -time.Second)
+
+	client.janitor.sweep()
+	if cache.Len() != 0 {
+		t.Errorf("Expected the janitor to remove expired entries, got %d", cache.Len())
+	}
+
+	// A replaced cache is no longer swept
+	client.SetResponseCache(NewRedisCache(&fakeRedis{values: make(map[string][]byte)}, ""))
+	cache.Set(context.Background(), "expired", []byte("1"), -time.Second)
+	client.janitor.sweep()
+	if cache.Len() != 1 {
+		t.Errorf("Expected the replaced cache to be unregistered, got %d entries", cache.Len())
+	}
+}
+
+func newCachingClient(t *testing.T) (*Client, *MockProvider) {
+	t.Helper()
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.Cache = &gomini.CacheConfig{Enabled: true}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	mock := &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		jsonData:     map[string]interface{}{"answer": 42.0},
+		responses: []gomini.StreamEvent{
+			gomini.NewContentEvent(providers.ProviderOpenAI, "gpt-4o", "Hello, ", true),
+			gomini.NewContentEvent(providers.ProviderOpenAI, "gpt-4o", "world", true),
+			gomini.NewFinishedEvent(providers.ProviderOpenAI, "gpt-4o", providers.FinishReasonStop, &gomini.Usage{TotalTokens: 7}),
+		},
+	}
+	useProvider(client, mock)
+	return client, mock
+}
+
+func TestClient_ResponseCacheSendMessage(t *testing.T) {
+	client, mock := newCachingClient(t)
+	ctx := context.Background()
+	request := func(text string, tags map[string]string) *gomini.ChatRequest {
+		return &gomini.ChatRequest{Model: "gpt-4o", Messages: []gomini.Message{gomini.NewUserMessage(text)}, Tags: tags}
+	}
+
+	first, err := client.SendMessage(ctx, request("Hi", nil))
+	if err != nil || first.Cached {
+		t.Fatalf("Expected a fresh response, got %+v, %v", first, err)
+	}
+
+	// Tags do not affect the key
+	mock.lastRequest = nil
+	second, err := client.SendMessage(ctx, request("Hi", map[string]string{"team": "qa"}))
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if !second.Cached || mock.lastRequest != nil {
+		t.Errorf("Expected a cache hit without calling the provider")
+	}
+	if second.Choices[0].Content() != "Mock response" {
+		t.Errorf("Unexpected cached choice %v", second.Choices[0])
+	}
+
+	if third, _ := client.SendMessage(ctx, request("Bye", nil)); third.Cached || mock.lastRequest == nil {
+		t.Error("Expected a different prompt to miss the cache")
+	}
+
+	// JSON requests are cached too
+	jsonRequest := &gomini.JSONRequest{Model: "gpt-4o", Messages: []gomini.Message{gomini.NewUserMessage("Answer")}}
+	client.GenerateJSON(ctx, jsonRequest)
+	mock.lastJSON = nil
+	resp, err := client.GenerateJSON(ctx, jsonRequest)
+	if err != nil || !resp.Cached || mock.lastJSON != nil || resp.Data["answer"] != 42.0 {
+		t.Errorf("Expected a cached JSON response, got %+v, %v", resp, err)
+	}
+}
+
+func TestClient_ResponseCacheStream(t *testing.T) {
+	client, mock := newCachingClient(t)
+	backend := &fakeRedis{values: make(map[string][]byte)}
+	client.SetResponseCache(NewRedisCache(backend, ""))
+	ctx := context.Background()
+	request := &gomini.ChatRequest{Model: "gpt-4o", Messages: []gomini.Message{gomini.NewUserMessage("Greet me")}}
+
+	for range client.SendMessageStream(ctx, request, "p1") {
+	}
+	if len(backend.values) != 1 {
+		t.Fatalf("Expected the stream to be cached, got %d entries", len(backend.values))
+	}
+	for key := range backend.values {
+		if key[:len("gomini:cache:chat:")] != "gomini:cache:chat:" {
+			t.Errorf("Unexpected key %q", key)
+		}
+	}
+
+	var text string
+	var finished gomini.StreamEvent
+	for event := range client.SendMessageStream(ctx, request, "p2") {
+		switch event.Type {
+		case gomini.EventContent:
+			text += event.Data.(gomini.ContentEvent).Text
+		case gomini.EventFinished:
+			finished = event
+		}
+	}
+	if mock.callCount != 1 {
+		t.Errorf("Expected the replay to skip the provider, got %d calls", mock.callCount)
+	}
+	if text != "Hello, world" || !finished.Metadata.Cached || finished.Metadata.Usage.TotalTokens != 7 {
+		t.Errorf("Unexpected replay %q, %+v", text, finished.Metadata)
+	}
+
+	// The streamed answer also serves SendMessage
+	if resp, err := client.SendMessage(ctx, request); err != nil || !resp.Cached {
+		t.Errorf("Expected SendMessage to hit the stream's entry, got %+v, %v", resp, err)
+	}
+}
+
+func TestClient_InvalidateCachedResponse(t *testing.T) {
+	client, mock := newCachingClient(t)
+	ctx := context.Background()
+	request := &gomini.ChatRequest{Model: "gpt-4o", Messages: []gomini.Message{gomini.NewUserMessage("Hi")}}
+
+	first, err := client.SendMessage(ctx, request)
+	if err != nil || first.CacheKey == "" {
+		t.Fatalf("Expected a cache key on the fresh response, got %+v, %v", first, err)
+	}
+	if err := client.InvalidateCachedResponse(ctx, first.CacheKey); err != nil {
+		t.Fatalf("InvalidateCachedResponse failed: %v", err)
+	}
+	mock.lastRequest = nil
+	if resp, _ := client.SendMessage(ctx, request); resp.Cached || mock.lastRequest == nil {
+		t.Error("Expected the invalidated entry to miss")
+	}
+
+	if resp, _ := client.SendMessage(ctx, request); !resp.Cached || resp.CacheKey != first.CacheKey {
+		t.Errorf("Expected a hit under the same key, got %+v", resp)
+	}
+	if err := client.PurgeResponseCache(ctx); err != nil {
+		t.Fatalf("PurgeResponseCache failed: %v", err)
+	}
+	if resp, _ := client.SendMessage(ctx, request); resp.Cached {
+		t.Error("Expected a purged cache to miss")
+	}
+
+	client.SetResponseCache(NewRedisCache(&fakeRedis{values: make(map[string][]byte)}, ""))
+	if err := client.PurgeResponseCache(ctx); err == nil {
+		t.Error("Expected an error purging a backend without Purge")
+	}
+}
+
+func TestClient_ResponseCacheFallback(t *testing.T) {
+	primary := &failingMockProvider{
+		MockProvider: MockProvider{providerType: providers.ProviderOpenAI},
+		err:          errors.New("503 service unavailable"),
+	}
+	client := newFallbackClient(t, func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		if providerType == providers.ProviderOpenAI {
+			return primary, nil
+		}
+		return &MockProvider{providerType: providerType}, nil
+	})
+	cache := NewMemoryCache(10)
+	client.SetResponseCache(cache)
+	ctx := context.Background()
+	request := &gomini.ChatRequest{Model: "gpt-4o", Messages: []gomini.Message{gomini.NewUserMessage("hi")}}
+
+	first, err := client.SendMessage(ctx, request)
+	if err != nil || first.Provider != providers.ProviderGemini {
+		t.Fatalf("Expected a Gemini fallback response, got %+v, %v", first, err)
+	}
+
+	// The fallback's answer is cached for Gemini, so the primary is asked again
+	primary.lastRequest = nil
+	second, err := client.SendMessage(ctx, request)
+	if err != nil || second.Cached || primary.lastRequest == nil {
+		t.Errorf("Expected the primary to be retried instead of a cached fallback answer, got %+v, %v", second, err)
+	}
+	if _, err := cache.Get(ctx, first.CacheKey); err != nil || cache.Len() != 1 {
+		t.Errorf("Expected one entry under the fallback's key, got %d, %v", cache.Len(), err)
+	}
+}
+
+func TestClient_ResponseCacheStaleWhileRevalidate(t *testing.T) {
+	client, mock := newCachingClient(t)
+	client.currentState().config.Cache.SoftTTL = time.Nanosecond
+	ctx := context.Background()
+	request := &gomini.ChatRequest{Model: "gpt-4o", Messages: []gomini.Message{gomini.NewUserMessage("FAQ")}}
+
+	if first, err := client.SendMessage(ctx, request); err != nil || first.Cached || first.Stale {
+		t.Fatalf("Expected a fresh response, got %+v, %v", first, err)
+	}
+
+	// The stale answer is served at once while the provider is asked again
+	mock.chatResponse = &gomini.ChatResponse{Choices: []gomini.Choice{{Message: gomini.NewAssistantMessage("Updated")}}}
+	stale, err := client.SendMessage(ctx, request)
+	if err != nil || !stale.Cached || !stale.Stale || stale.Choices[0].Content() != "Mock response" {
+		t.Fatalf("Expected the stale cached answer, got %+v, %v", stale, err)
+	}
+	client.revalidations.Wait()
+
+	var text string
+	var finished gomini.StreamEvent
+	for event := range client.SendMessageStream(ctx, request, "p1") {
+		switch event.Type {
+		case gomini.EventContent:
+			text += event.Data.(gomini.ContentEvent).Text
+		case gomini.EventFinished:
+			finished = event
+		}
+	}
+	client.revalidations.Wait()
+	if text != "Updated" || !finished.Metadata.Cached || !finished.Metadata.Stale {
+		t.Errorf("Expected the refreshed answer replayed as stale, got %q, %+v", text, finished.Metadata)
+	}
+
+	// Without a soft TTL entries are never stale
+	client.currentState().config.Cache.SoftTTL = 0
+	if resp, _ := client.SendMessage(ctx, request); !resp.Cached || resp.Stale {
+		t.Errorf("Expected a fresh cache hit, got %+v", resp)
+	}
+}
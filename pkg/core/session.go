@@ -0,0 +1,367 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gomini/pkg/gomini"
+)
+
+// Session owns the message history of a single conversation. Assistant and
+// tool turns are appended automatically from stream events, and the number of
+// turns is bounded by MaxTurns.
+type Session struct {
+	mu        sync.RWMutex
+	id        string
+	messages  []gomini.Message
+	turnCount int
+	maxTurns  int
+	metadata  map[string]interface{}
+	created   time.Time
+	updated   time.Time
+
+	// Assistant turn being assembled from stream events
+	pendingText      strings.Builder
+	pendingToolCalls []gomini.ToolCall
+	pendingProvider  gomini.ProviderType
+	pendingModel     string
+
+	// Provider, model and usage of recorded assistant messages, by index
+	info          map[int]MessageInfo
+	lastAssistant int
+
+	// Cost tracking against an optional budget
+	budget *SessionBudget
+	spent  float64
+
+	// Tools withheld from every turn, e.g. write tools in read-only mode
+	disabledTools map[string]bool
+
+	// Results reused by RunSessionTools for repeated tool calls
+	toolResults toolResultCache
+}
+
+// SessionData is the serializable snapshot of a session used by SessionStore implementations
+type SessionData struct {
+	ID            string                 `json:"id"`
+	Messages      []gomini.Message       `json:"messages"`
+	TurnCount     int                    `json:"turn_count"`
+	MaxTurns      int                    `json:"max_turns,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Budget        *SessionBudget         `json:"budget,omitempty"`
+	Spent         float64                `json:"spent,omitempty"`
+	DisabledTools []string               `json:"disabled_tools,omitempty"`
+	MessageInfo   map[int]MessageInfo    `json:"message_info,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+// MessageInfo records which provider and model produced an assistant message
+// and the usage of its turn
+type MessageInfo struct {
+	Provider gomini.ProviderType `json:"provider,omitempty"`
+	Model    string              `json:"model,omitempty"`
+	Usage    *gomini.Usage       `json:"usage,omitempty"`
+}
+
+// NewSession creates an empty session. A maxTurns of 0 disables the turn limit
+// and an empty id is replaced by a generated prompt ID.
+func NewSession(id string, maxTurns int) *Session {
+	if id == "" {
+		id = NewPromptID()
+	}
+	now := time.Now()
+	return &Session{
+		id:       id,
+		maxTurns: maxTurns,
+		metadata: make(map[string]interface{}),
+		info:     make(map[int]MessageInfo),
+		created:  now,
+		updated:  now,
+
+		lastAssistant: -1,
+	}
+}
+
+// RestoreSession rebuilds a session from a snapshot
+func RestoreSession(data *SessionData) *Session {
+	session := NewSession(data.ID, data.MaxTurns)
+	session.messages = append(session.messages, data.Messages...)
+	session.turnCount = data.TurnCount
+	session.budget = data.Budget
+	session.spent = data.Spent
+	session.DisableTools(data.DisabledTools...)
+	for index, info := range data.MessageInfo {
+		session.info[index] = info
+	}
+	if data.Metadata != nil {
+		session.metadata = data.Metadata
+	}
+	if !data.CreatedAt.IsZero() {
+		session.created = data.CreatedAt
+	}
+	if !data.UpdatedAt.IsZero() {
+		session.updated = data.UpdatedAt
+	}
+	return session
+}
+
+// ID returns the session identifier (also used as the prompt ID for loop detection)
+func (s *Session) ID() string {
+	return s.id
+}
+
+// TurnCount returns the number of turns started in this session
+func (s *Session) TurnCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.turnCount
+}
+
+// MaxTurns returns the turn limit of this session (0 = unlimited)
+func (s *Session) MaxTurns() int {
+	return s.maxTurns
+}
+
+// History returns a copy of the message history
+func (s *Session) History() []gomini.Message {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	history := make([]gomini.Message, len(s.messages))
+	copy(history, s.messages)
+	return history
+}
+
+// AddMessage appends a message to the history
+func (s *Session) AddMessage(msg gomini.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+	s.updated = time.Now()
+}
+
+// AddUserMessage appends a user text message to the history
+func (s *Session) AddUserMessage(content string) {
+	s.AddMessage(gomini.NewUserMessage(content))
+}
+
+// SetMetadata stores an arbitrary value alongside the session
+func (s *Session) SetMetadata(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metadata[key] = value
+	s.updated = time.Now()
+}
+
+// GetMetadata returns a metadata value previously stored with SetMetadata
+func (s *Session) GetMetadata(key string) (interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.metadata[key]
+	return value, ok
+}
+
+// MessageInfo returns the provider, model and usage recorded for the message
+// at index, if it was produced by a stream the session recorded
+func (s *Session) MessageInfo(index int) (MessageInfo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	info, ok := s.info[index]
+	return info, ok
+}
+
+// BeginTurn starts a new turn, or returns an error without starting one once
+// MaxTurns turns have been taken
+func (s *Session) BeginTurn() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxTurns > 0 && s.turnCount >= s.maxTurns {
+		return fmt.Errorf("session %s exceeded max turns (%d)", s.id, s.maxTurns)
+	}
+	s.turnCount++
+	s.resetPending()
+	s.lastAssistant = -1
+	return nil
+}
+
+// RecordEvent updates the history from a stream event. Content deltas and
+// tool calls are buffered and committed as one assistant message when the
+// turn finishes; tool responses are appended as tool messages.
+func (s *Session) RecordEvent(event gomini.StreamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch event.Type {
+	case gomini.EventContent:
+		if data, ok := event.Data.(gomini.ContentEvent); ok {
+			s.pendingText.WriteString(data.Text)
+			s.pendingProvider, s.pendingModel = event.Provider, event.Model
+		}
+	case gomini.EventToolCall:
+		if data, ok := event.Data.(gomini.ToolCallEvent); ok {
+			s.pendingProvider, s.pendingModel = event.Provider, event.Model
+			s.pendingToolCalls = append(s.pendingToolCalls, gomini.ToolCall{
+				ID:        data.CallID,
+				Name:      data.ToolName,
+				Arguments: data.Arguments,
+			})
+		}
+	case gomini.EventToolResponse:
+		if data, ok := event.Data.(gomini.ToolResponseEvent); ok {
+			s.commitPending()
+			s.messages = append(s.messages, gomini.NewToolResultMessage(data.CallID, data.ToolName, stringifyToolResult(data.Result)))
+			s.updated = time.Now()
+		}
+	case gomini.EventFinished:
+		s.commitPending()
+		if event.Metadata.Usage != nil && s.lastAssistant >= 0 {
+			info := s.info[s.lastAssistant]
+			info.Usage = event.Metadata.Usage
+			s.info[s.lastAssistant] = info
+		}
+	}
+}
+
+// Snapshot returns a serializable copy of the session
+func (s *Session) Snapshot() *SessionData {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	messages := make([]gomini.Message, len(s.messages))
+	copy(messages, s.messages)
+	metadata := make(map[string]interface{}, len(s.metadata))
+	for key, value := range s.metadata {
+		metadata[key] = value
+	}
+	var info map[int]MessageInfo
+	if len(s.info) > 0 {
+		info = make(map[int]MessageInfo, len(s.info))
+		for index, messageInfo := range s.info {
+			info[index] = messageInfo
+		}
+	}
+
+	return &SessionData{
+		ID:            s.id,
+		Messages:      messages,
+		TurnCount:     s.turnCount,
+		MaxTurns:      s.maxTurns,
+		Metadata:      metadata,
+		Budget:        s.budget,
+		Spent:         s.spent,
+		DisabledTools: s.disabledToolsLocked(),
+		MessageInfo:   info,
+		CreatedAt:     s.created,
+		UpdatedAt:     s.updated,
+	}
+}
+
+// commitPending appends the buffered assistant turn, if any
+func (s *Session) commitPending() {
+	if s.pendingText.Len() == 0 && len(s.pendingToolCalls) == 0 {
+		return
+	}
+
+	if len(s.pendingToolCalls) > 0 {
+		s.messages = append(s.messages, gomini.NewAssistantToolCallMessage(s.pendingText.String(), s.pendingToolCalls))
+	} else {
+		s.messages = append(s.messages, gomini.NewAssistantMessage(s.pendingText.String()))
+	}
+	s.lastAssistant = len(s.messages) - 1
+	s.info[s.lastAssistant] = MessageInfo{Provider: s.pendingProvider, Model: s.pendingModel}
+	s.updated = time.Now()
+	s.resetPending()
+}
+
+// resetPending discards the buffered assistant turn
+func (s *Session) resetPending() {
+	s.pendingText.Reset()
+	s.pendingToolCalls = nil
+	s.pendingProvider, s.pendingModel = "", ""
+}
+
+// stringifyToolResult converts a tool result into message content
+func stringifyToolResult(result interface{}) string {
+	if str, ok := result.(string); ok {
+		return str
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf("%v", result)
+	}
+	return string(data)
+}
+
+// NewSession creates a session bounded by the client's MaxSessionTurns setting
+func (c *Client) NewSession(id string) *Session {
+	return NewSession(id, c.currentState().config.MaxSessionTurns)
+}
+
+// SendSessionStream streams a response using the session history as the
+// request messages, recording assistant and tool turns back into the session.
+// Messages already set on the request are appended to the session first.
+// Tools disabled on the session are withheld in addition to the request's own.
+func (c *Client) SendSessionStream(ctx context.Context, session *Session, request *gomini.ChatRequest) <-chan gomini.StreamEvent {
+	// The state is held until the stream ends
+	st, releaseState := c.holdState()
+	resultChan := make(chan gomini.StreamEvent, 10)
+
+	go func() {
+		defer close(resultChan)
+		defer releaseState()
+
+		// Session events are published here; stream events by SendMessageStream
+		send := func(event gomini.StreamEvent) {
+			c.events.publish(event)
+			resultChan <- event
+		}
+
+		budgeted, downgrade, err := c.applyBudget(st, session, request)
+		if err != nil {
+			send(budgetEvent(st, session, request.Model, gomini.BudgetLevelExceeded))
+			errEvent := gomini.NewErrorEvent(st.providerType, request.Model, err, false)
+			errEvent.PromptID = session.ID()
+			send(errEvent)
+			return
+		}
+		if downgrade != nil {
+			send(*downgrade)
+		}
+
+		// Refused turns leave the history untouched
+		if err := session.BeginTurn(); err != nil {
+			event := gomini.NewMaxSessionTurnsEvent(st.providerType, request.Model,
+				session.TurnCount()+1, session.MaxTurns(), session.ID())
+			event.PromptID = session.ID()
+			send(event)
+			return
+		}
+		for _, msg := range request.Messages {
+			session.AddMessage(msg)
+		}
+
+		sessionRequest := *budgeted
+		sessionRequest.Messages = session.History()
+		if disabled := session.DisabledTools(); len(disabled) > 0 {
+			sessionRequest.DisabledTools = append(disabled, sessionRequest.DisabledTools...)
+		}
+
+		for event := range c.SendMessageStream(ctx, &sessionRequest, session.ID()) {
+			session.RecordEvent(event)
+			resultChan <- event
+
+			if event.Type == gomini.EventFinished {
+				for _, budgetEvent := range c.recordSessionCost(ctx, st, session, sessionRequest.Model, event.Metadata.Usage) {
+					send(budgetEvent)
+				}
+			}
+		}
+	}()
+
+	return resultChan
+}
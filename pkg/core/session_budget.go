@@ -0,0 +1,160 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// Default budget thresholds as fractions of the ceiling
+const (
+	DefaultBudgetWarnRatio      = 0.75
+	DefaultBudgetDowngradeRatio = 0.9
+)
+
+// SessionBudget caps what a session may spend. Past WarnRatio of the ceiling
+// a warning is emitted, past DowngradeRatio requests move to cheaper models
+// via Router.CostDowngrades, and at the ceiling requests are refused.
+type SessionBudget struct {
+	Ceiling        float64 `json:"ceiling"` // USD; 0 disables the budget
+	WarnRatio      float64 `json:"warn_ratio,omitempty"`
+	DowngradeRatio float64 `json:"downgrade_ratio,omitempty"`
+}
+
+// budgetLevels lists the thresholds from lowest to highest
+var budgetLevels = []gomini.BudgetLevel{gomini.BudgetLevelWarning, gomini.BudgetLevelDowngrade, gomini.BudgetLevelExceeded}
+
+// threshold returns the spend at which level is reached
+func (b *SessionBudget) threshold(level gomini.BudgetLevel) float64 {
+	switch level {
+	case gomini.BudgetLevelWarning:
+		if b.WarnRatio > 0 {
+			return b.Ceiling * b.WarnRatio
+		}
+		return b.Ceiling * DefaultBudgetWarnRatio
+	case gomini.BudgetLevelDowngrade:
+		if b.DowngradeRatio > 0 {
+			return b.Ceiling * b.DowngradeRatio
+		}
+		return b.Ceiling * DefaultBudgetDowngradeRatio
+	}
+	return b.Ceiling
+}
+
+// reached reports whether spent has reached level
+func (b *SessionBudget) reached(spent float64, level gomini.BudgetLevel) bool {
+	return b != nil && b.Ceiling > 0 && spent >= b.threshold(level)
+}
+
+// SetBudget sets the session's cost ceiling
+func (s *Session) SetBudget(budget SessionBudget) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.budget = &budget
+}
+
+// Spent returns the estimated cost of the session so far
+func (s *Session) Spent() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.spent
+}
+
+// AddCost records spend and returns the budget thresholds it crossed, lowest first
+func (s *Session) AddCost(cost float64) []gomini.BudgetLevel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := s.spent
+	s.spent += cost
+	s.updated = time.Now()
+
+	var crossed []gomini.BudgetLevel
+	for _, level := range budgetLevels {
+		if !s.budget.reached(before, level) && s.budget.reached(s.spent, level) {
+			crossed = append(crossed, level)
+		}
+	}
+	return crossed
+}
+
+// budgetReached reports whether the session has reached a budget threshold
+func (s *Session) budgetReached(level gomini.BudgetLevel) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.budget.reached(s.spent, level)
+}
+
+// budgetCeiling returns the session ceiling, 0 when unset
+func (s *Session) budgetCeiling() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.budget == nil {
+		return 0
+	}
+	return s.budget.Ceiling
+}
+
+// applyBudget enforces the session budget before a request. It refuses the
+// request once the ceiling is reached and past the downgrade threshold moves
+// it to the cheapest model reachable through Router.CostDowngrades.
+func (c *Client) applyBudget(st *clientState, session *Session, request *gomini.ChatRequest) (*gomini.ChatRequest, *gomini.StreamEvent, error) {
+	if session.budgetReached(gomini.BudgetLevelExceeded) {
+		return nil, nil, gomini.NewLLMError(gomini.ErrorBudgetExceeded,
+			fmt.Sprintf("session %s spent $%.4f of its $%.4f budget", session.ID(), session.Spent(), session.budgetCeiling()),
+			st.providerType, nil).WithRemediation(gomini.Remediation{
+			Action: gomini.RemediationRaiseBudget,
+			Hint:   fmt.Sprintf("raise the session budget above $%.4f with SetBudget", session.Spent()),
+		})
+	}
+	if !session.budgetReached(gomini.BudgetLevelDowngrade) || st.config.Router == nil {
+		return request, nil, nil
+	}
+
+	downgraded := *request
+	// Follow the chain of cheaper siblings, guarding against cycles
+	for i := 0; i < len(st.config.Router.CostDowngrades); i++ {
+		rule, ok := st.config.Router.CostDowngrades[downgraded.Model]
+		if !ok {
+			break
+		}
+		downgraded.Model = rule.Model
+		if rule.Provider != "" {
+			downgraded.Provider = rule.Provider
+		}
+	}
+	if downgraded.Model == request.Model {
+		return request, nil, nil
+	}
+
+	event := budgetEvent(st, session, downgraded.Model, gomini.BudgetLevelDowngrade)
+	data := event.Data.(gomini.BudgetEvent)
+	data.FromModel = request.Model
+	data.ToModel = downgraded.Model
+	event.Data = data
+	return &downgraded, &event, nil
+}
+
+// recordSessionCost adds the cost of a finished turn to the session, with or
+// without a budget, and returns an event for each budget threshold it crossed
+func (c *Client) recordSessionCost(ctx context.Context, st *clientState, session *Session, model string, usage *providers.Usage) []gomini.StreamEvent {
+	if usage == nil {
+		return nil
+	}
+
+	var events []gomini.StreamEvent
+	for _, level := range session.AddCost(c.usageCost(ctx, st, model, usage)) {
+		events = append(events, budgetEvent(st, session, model, level))
+	}
+	return events
+}
+
+// budgetEvent creates a budget event for the session's current spend
+func budgetEvent(st *clientState, session *Session, model string, level gomini.BudgetLevel) gomini.StreamEvent {
+	event := gomini.NewBudgetEvent(st.providerType, model, level, session.Spent(), session.budgetCeiling(), session.ID())
+	event.PromptID = session.ID()
+	return event
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestClient_SendSessionStreamBudget(t *testing.T) {
+	config := gomini.NewConfig()
+	config.MaxSessionTurns = 10
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.Router.CostDowngrades = map[string]gomini.ContextUpgradeRule{
+		"big-model": {Model: "small-model"},
+	}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	mock := &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		models: []gomini.Model{
+			{ID: "big-model", Cost: &providers.ModelCost{InputTokens: 1.0}},
+			{ID: "small-model", Cost: &providers.ModelCost{InputTokens: 0.25}},
+		},
+		responses: []gomini.StreamEvent{
+			{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "ok", Delta: true}},
+			{Type: gomini.EventFinished, Metadata: gomini.EventMeta{Usage: &gomini.Usage{InputTokens: 4000000}}},
+		},
+	}
+	useProvider(client, mock)
+
+	session := client.NewSession("session-1")
+	session.SetBudget(SessionBudget{Ceiling: 10, DowngradeRatio: 0.8})
+
+	// big-model costs $4 per turn, small-model $1
+	turns := []struct {
+		wantModel  string
+		wantLevels []gomini.BudgetLevel
+		wantSpent  float64
+		wantErr    bool
+	}{
+		{wantModel: "big-model", wantSpent: 4},
+		{wantModel: "big-model", wantSpent: 8, wantLevels: []gomini.BudgetLevel{gomini.BudgetLevelWarning, gomini.BudgetLevelDowngrade}},
+		{wantModel: "small-model", wantSpent: 9, wantLevels: []gomini.BudgetLevel{gomini.BudgetLevelDowngrade}},
+		{wantModel: "small-model", wantSpent: 10, wantLevels: []gomini.BudgetLevel{gomini.BudgetLevelDowngrade, gomini.BudgetLevelExceeded}},
+		{wantSpent: 10, wantLevels: []gomini.BudgetLevel{gomini.BudgetLevelExceeded}, wantErr: true},
+	}
+
+	for i, turn := range turns {
+		mock.lastRequest = nil
+		request := &gomini.ChatRequest{
+			Messages: []gomini.Message{gomini.NewUserMessage("Hi")},
+			Model:    "big-model",
+		}
+
+		var levels []gomini.BudgetLevel
+		var streamErr error
+		for event := range client.SendSessionStream(context.Background(), session, request) {
+			switch event.Type {
+			case gomini.EventBudget:
+				budget := event.Data.(gomini.BudgetEvent)
+				levels = append(levels, budget.Level)
+				if budget.Level == gomini.BudgetLevelDowngrade && budget.ToModel != "" && budget.FromModel != "big-model" {
+					t.Errorf("turn %d: expected downgrade from big-model, got %q", i, budget.FromModel)
+				}
+			case gomini.EventError:
+				streamErr = event.Error
+			}
+		}
+
+		if turn.wantErr {
+			if !errors.Is(streamErr, gomini.ErrBudgetExceeded) {
+				t.Errorf("turn %d: expected budget exceeded error, got %v", i, streamErr)
+			}
+			if mock.lastRequest != nil {
+				t.Errorf("turn %d: expected request to be refused before reaching the provider", i)
+			}
+		} else if mock.lastRequest == nil || mock.lastRequest.Model != turn.wantModel {
+			t.Errorf("turn %d: expected model %s, got %v", i, turn.wantModel, mock.lastRequest)
+		}
+		if !equalLevels(levels, turn.wantLevels) {
+			t.Errorf("turn %d: expected budget events %v, got %v", i, turn.wantLevels, levels)
+		}
+		if session.Spent() != turn.wantSpent {
+			t.Errorf("turn %d: expected spent %v, got %v", i, turn.wantSpent, session.Spent())
+		}
+	}
+}
+
+func TestSession_BudgetSnapshot(t *testing.T) {
+	session := NewSession("session-1", 0)
+	session.SetBudget(SessionBudget{Ceiling: 2})
+	if crossed := session.AddCost(1.6); !equalLevels(crossed, []gomini.BudgetLevel{gomini.BudgetLevelWarning}) {
+		t.Errorf("Expected warning threshold, got %v", crossed)
+	}
+
+	restored := RestoreSession(session.Snapshot())
+	if restored.Spent() != 1.6 {
+		t.Errorf("Expected spent to survive restore, got %v", restored.Spent())
+	}
+	if crossed := restored.AddCost(0.4); !equalLevels(crossed, []gomini.BudgetLevel{gomini.BudgetLevelDowngrade, gomini.BudgetLevelExceeded}) {
+		t.Errorf("Expected downgrade and exceeded thresholds, got %v", crossed)
+	}
+}
+
+func equalLevels(a, b []gomini.BudgetLevel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
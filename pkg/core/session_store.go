@@ -0,0 +1,378 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSessionNotFound is returned by SessionStore.Load when no session exists for the ID
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionStore persists sessions between process restarts
+type SessionStore interface {
+	// Save stores the current state of a session, replacing any previous version
+	Save(ctx context.Context, session *Session) error
+
+	// Load restores a session by ID, returning ErrSessionNotFound if it does not exist
+	Load(ctx context.Context, id string) (*Session, error)
+
+	// Delete removes a session; deleting a missing session is not an error
+	Delete(ctx context.Context, id string) error
+
+	// List returns the IDs of all stored sessions
+	List(ctx context.Context) ([]string, error)
+}
+
+// MemorySessionStore keeps session snapshots in memory
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*memorySession
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// memorySession is a stored snapshot with its bookkeeping for expiry
+type memorySession struct {
+	data    *SessionData
+	savedAt time.Time
+	deleted bool
+}
+
+// NewMemorySessionStore creates an empty in-memory session store
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		sessions: make(map[string]*memorySession),
+		now:      time.Now,
+	}
+}
+
+// SetTTL makes sessions expire ttl after they were last saved. Zero disables expiry.
+func (m *MemorySessionStore) SetTTL(ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ttl = ttl
+}
+
+// Save implements SessionStore.Save. Saving an invalidated session revives it.
+func (m *MemorySessionStore) Save(ctx context.Context, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID()] = &memorySession{data: session.Snapshot(), savedAt: m.now()}
+	return nil
+}
+
+// Load implements SessionStore.Load
+func (m *MemorySessionStore) Load(ctx context.Context, id string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, exists := m.sessions[id]
+	if !exists || !m.live(entry) {
+		return nil, ErrSessionNotFound
+	}
+	return RestoreSession(entry.data), nil
+}
+
+// Delete implements SessionStore.Delete
+func (m *MemorySessionStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+// List implements SessionStore.List
+func (m *MemorySessionStore) List(ctx context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]string, 0, len(m.sessions))
+	for id, entry := range m.sessions {
+		if m.live(entry) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Invalidate implements ExpiringStore.Invalidate
+func (m *MemorySessionStore) Invalidate(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry, exists := m.sessions[id]; exists {
+		entry.deleted = true
+	}
+	return nil
+}
+
+// Undelete implements ExpiringStore.Undelete
+func (m *MemorySessionStore) Undelete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, exists := m.sessions[id]
+	if !exists || m.expired(entry.savedAt) {
+		return ErrSessionNotFound
+	}
+	entry.deleted = false
+	return nil
+}
+
+// Cleanup implements ExpiringStore.Cleanup
+func (m *MemorySessionStore) Cleanup(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for id, entry := range m.sessions {
+		if !m.live(entry) {
+			delete(m.sessions, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// live reports whether an entry is neither invalidated nor expired
+func (m *MemorySessionStore) live(entry *memorySession) bool {
+	return !entry.deleted && !m.expired(entry.savedAt)
+}
+
+// expired reports whether something saved at savedAt has outlived the TTL
+func (m *MemorySessionStore) expired(savedAt time.Time) bool {
+	return m.ttl > 0 && m.now().Sub(savedAt) > m.ttl
+}
+
+// FileSessionStore stores each session as a JSON file in a directory
+type FileSessionStore struct {
+	mu  sync.Mutex
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// Invalidated sessions are renamed with this suffix until Cleanup removes them
+const deletedSessionSuffix = ".deleted"
+
+// Session IDs are used as file names, so only a safe subset of characters is allowed
+var validSessionID = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+$`)
+
+// NewFileSessionStore creates a file-backed store, creating dir if needed
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	return &FileSessionStore{dir: dir, now: time.Now}, nil
+}
+
+// SetTTL makes sessions expire ttl after their file was last written. Zero disables expiry.
+func (f *FileSessionStore) SetTTL(ttl time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ttl = ttl
+}
+
+// Save implements SessionStore.Save. Files are written atomically via rename,
+// and saving an invalidated session revives it.
+func (f *FileSessionStore) Save(ctx context.Context, session *Session) error {
+	path, err := f.path(session.ID())
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(session.Snapshot(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session %s: %w", session.ID(), err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write session %s: %w", session.ID(), err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write session %s: %w", session.ID(), err)
+	}
+	os.Remove(path + deletedSessionSuffix)
+	return nil
+}
+
+// Load implements SessionStore.Load
+func (f *FileSessionStore) Load(ctx context.Context, id string) (*Session, error) {
+	path, err := f.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	raw, err := f.readLive(path)
+	f.mu.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %w", id, err)
+	}
+
+	var data SessionData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode session %s: %w", id, err)
+	}
+	return RestoreSession(&data), nil
+}
+
+// Delete implements SessionStore.Delete
+func (f *FileSessionStore) Delete(ctx context.Context, id string) error {
+	path, err := f.path(id)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range []string{path, path + deletedSessionSuffix} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete session %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// List implements SessionStore.List
+func (f *FileSessionStore) List(ctx context.Context) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		if info, err := entry.Info(); err != nil || f.expired(info.ModTime()) {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".json"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// path returns the file path for a session ID
+func (f *FileSessionStore) path(id string) (string, error) {
+	if !validSessionID.MatchString(id) || id == "." || id == ".." {
+		return "", fmt.Errorf("invalid session id %q", id)
+	}
+	return filepath.Join(f.dir, id+".json"), nil
+}
+
+// Invalidate implements ExpiringStore.Invalidate by renaming the session file
+// aside so it can be undeleted until the next Cleanup
+func (f *FileSessionStore) Invalidate(ctx context.Context, id string) error {
+	path, err := f.path(id)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.Rename(path, path+deletedSessionSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to invalidate session %s: %w", id, err)
+	}
+	return nil
+}
+
+// Undelete implements ExpiringStore.Undelete
+func (f *FileSessionStore) Undelete(ctx context.Context, id string) error {
+	path, err := f.path(id)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, err := os.Stat(path + deletedSessionSuffix)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && f.expired(info.ModTime())) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to undelete session %s: %w", id, err)
+	}
+	if err := os.Rename(path+deletedSessionSuffix, path); err != nil {
+		return fmt.Errorf("failed to undelete session %s: %w", id, err)
+	}
+	return nil
+}
+
+// Cleanup implements ExpiringStore.Cleanup, removing invalidated and expired
+// session files along with temp files left by interrupted saves
+func (f *FileSessionStore) Cleanup(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+
+		stale := strings.HasSuffix(name, deletedSessionSuffix)
+		if strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.tmp") {
+			info, err := entry.Info()
+			stale = err == nil && f.expired(info.ModTime())
+		}
+		if !stale {
+			continue
+		}
+		if err := os.Remove(filepath.Join(f.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove session file %s: %w", name, err)
+		}
+		if !strings.HasSuffix(name, ".tmp") {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// readLive reads a session file, treating expired files as missing
+func (f *FileSessionStore) readLive(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if f.expired(info.ModTime()) {
+		return nil, os.ErrNotExist
+	}
+	return os.ReadFile(path)
+}
+
+// expired reports whether a file written at modTime has outlived the TTL
+func (f *FileSessionStore) expired(modTime time.Time) bool {
+	return f.ttl > 0 && f.now().Sub(modTime) > f.ttl
+}
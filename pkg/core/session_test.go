@@ -0,0 +1,256 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestSession_RecordEvent(t *testing.T) {
+	session := NewSession("session-1", 0)
+	session.AddUserMessage("What's the weather?")
+
+	events := []gomini.StreamEvent{
+		{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "Let me ", Delta: true}},
+		{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "check.", Delta: true}},
+		{Type: gomini.EventToolCall, Data: gomini.ToolCallEvent{
+			CallID:    "call-1",
+			ToolName:  "get_weather",
+			Arguments: map[string]interface{}{"city": "Taipei"},
+		}},
+		{Type: gomini.EventToolResponse, Data: gomini.ToolResponseEvent{
+			CallID:   "call-1",
+			ToolName: "get_weather",
+			Result:   map[string]interface{}{"temp": 30},
+			Success:  true,
+		}},
+		{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "It's 30 degrees.", Delta: true}},
+		{Type: gomini.EventFinished},
+	}
+	for _, event := range events {
+		session.RecordEvent(event)
+	}
+
+	history := session.History()
+	if len(history) != 4 {
+		t.Fatalf("Expected 4 messages, got %d: %v", len(history), history)
+	}
+
+	toolCallMsg := history[1].(map[string]interface{})
+	if toolCallMsg["content"] != "Let me check." {
+		t.Errorf("Expected buffered content, got %v", toolCallMsg["content"])
+	}
+	if calls := toolCallMsg["tool_calls"].([]gomini.ToolCall); len(calls) != 1 || calls[0].Name != "get_weather" {
+		t.Errorf("Expected get_weather tool call, got %v", calls)
+	}
+
+	toolResultMsg := history[2].(map[string]interface{})
+	if toolResultMsg["role"] != "tool" || toolResultMsg["content"] != `{"temp":30}` {
+		t.Errorf("Unexpected tool result message: %v", toolResultMsg)
+	}
+
+	finalMsg := history[3].(map[string]interface{})
+	if finalMsg["role"] != "assistant" || finalMsg["content"] != "It's 30 degrees." {
+		t.Errorf("Unexpected final message: %v", finalMsg)
+	}
+}
+
+func TestSession_BeginTurnLimit(t *testing.T) {
+	session := NewSession("session-1", 2)
+
+	for i := 0; i < 2; i++ {
+		if err := session.BeginTurn(); err != nil {
+			t.Fatalf("Unexpected error on turn %d: %v", i+1, err)
+		}
+	}
+
+	if err := session.BeginTurn(); err == nil {
+		t.Error("Expected error when exceeding max turns")
+	}
+	if session.TurnCount() != 2 {
+		t.Errorf("Expected the refused turn not to be counted, got %d", session.TurnCount())
+	}
+}
+
+func TestSessionStores_RoundTrip(t *testing.T) {
+	fileStore, err := NewFileSessionStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create file store: %v", err)
+	}
+
+	stores := map[string]SessionStore{
+		"memory": NewMemorySessionStore(),
+		"file":   fileStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			session := NewSession("chat-42", 10)
+			session.AddUserMessage("Hello")
+			session.AddMessage(gomini.NewAssistantMessage("Hi there"))
+			session.SetMetadata("user", "alice")
+			session.BeginTurn()
+
+			if err := store.Save(ctx, session); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+
+			loaded, err := store.Load(ctx, "chat-42")
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if len(loaded.History()) != 2 {
+				t.Errorf("Expected 2 messages, got %d", len(loaded.History()))
+			}
+			if loaded.TurnCount() != 1 || loaded.MaxTurns() != 10 {
+				t.Errorf("Expected turn state to be restored, got %d/%d", loaded.TurnCount(), loaded.MaxTurns())
+			}
+			if user, _ := loaded.GetMetadata("user"); user != "alice" {
+				t.Errorf("Expected metadata to be restored, got %v", user)
+			}
+
+			ids, err := store.List(ctx)
+			if err != nil || len(ids) != 1 || ids[0] != "chat-42" {
+				t.Errorf("Unexpected List result: %v, %v", ids, err)
+			}
+
+			if err := store.Delete(ctx, "chat-42"); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if _, err := store.Load(ctx, "chat-42"); !errors.Is(err, ErrSessionNotFound) {
+				t.Errorf("Expected ErrSessionNotFound after delete, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_SendSessionStream(t *testing.T) {
+	config := gomini.NewConfig()
+	config.MaxSessionTurns = 1
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	useProvider(client, &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		responses: []gomini.StreamEvent{
+			{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "Hello!", Delta: true}},
+			{Type: gomini.EventFinished},
+		},
+	})
+
+	session := client.NewSession("session-1")
+	request := &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("Hi")},
+		Model:    "test-model",
+	}
+
+	for range client.SendSessionStream(context.Background(), session, request) {
+	}
+
+	history := session.History()
+	if len(history) != 2 {
+		t.Fatalf("Expected user and assistant messages, got %v", history)
+	}
+	if history[1].(map[string]interface{})["content"] != "Hello!" {
+		t.Errorf("Expected assistant reply to be recorded, got %v", history[1])
+	}
+
+	// Second turn exceeds the limit of 1
+	foundMaxTurns := false
+	for event := range client.SendSessionStream(context.Background(), session, request) {
+		if event.Type == gomini.EventMaxSessionTurns {
+			foundMaxTurns = true
+			if data := event.Data.(gomini.MaxSessionTurnsEvent); data.CurrentTurns != 2 || data.MaxTurns != 1 {
+				t.Errorf("Expected turn 2 of 1, got %d of %d", data.CurrentTurns, data.MaxTurns)
+			}
+		}
+	}
+	if !foundMaxTurns {
+		t.Error("Expected max session turns event")
+	}
+	if history := session.History(); len(history) != 2 {
+		t.Errorf("Expected the refused turn's messages to stay out of the history, got %v", history)
+	}
+}
+
+func TestClient_SendSessionStreamToolTurns(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"1","object":"chat.completion.chunk","created":1,"model":"gpt-4o","choices":[{"index":0,"delta":{"content":"Sunny."}}]}`,
+			`{"id":"1","object":"chat.completion.chunk","created":1,"model":"gpt-4o","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+		OpenAI:  &gomini.OpenAIConfig{BaseURL: server.URL + "/v1"},
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	// A previous turn called a tool and recorded its result
+	session := client.NewSession("session-tools")
+	session.AddMessage(gomini.NewUserMessage("Weather in Taipei?"))
+	session.RecordEvent(gomini.StreamEvent{Type: gomini.EventToolCall, Data: gomini.ToolCallEvent{
+		CallID: "call_1", ToolName: "get_weather", Arguments: map[string]interface{}{"city": "Taipei"},
+	}})
+	session.RecordEvent(gomini.StreamEvent{Type: gomini.EventToolResponse, Data: gomini.ToolResponseEvent{
+		CallID: "call_1", ToolName: "get_weather", Result: "sunny", Success: true,
+	}})
+
+	for event := range client.SendSessionStream(context.Background(), session, &gomini.ChatRequest{Model: "gpt-4o"}) {
+		if event.Type == gomini.EventError {
+			t.Fatalf("Unexpected error event: %+v", event.Data)
+		}
+	}
+
+	var sent struct {
+		Messages []map[string]interface{} `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("Failed to decode the request: %v", err)
+	}
+	if len(sent.Messages) != 3 {
+		t.Fatalf("Expected user, tool call and tool result messages, got %s", body)
+	}
+	if calls, _ := sent.Messages[1]["tool_calls"].([]interface{}); len(calls) != 1 {
+		t.Errorf("Expected the tool call to be sent, got %v", sent.Messages[1])
+	}
+	if sent.Messages[2]["role"] != "tool" || sent.Messages[2]["tool_call_id"] != "call_1" {
+		t.Errorf("Expected the tool result to be sent, got %v", sent.Messages[2])
+	}
+	if history := session.History(); len(history) != 4 {
+		t.Errorf("Expected the reply to be recorded after the tool turns, got %v", history)
+	}
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// DefaultShutdownTimeout bounds how long Close waits for components to stop
+const DefaultShutdownTimeout = 10 * time.Second
+
+// ErrShutdownTimeout is reported for components still running at the shutdown deadline
+var ErrShutdownTimeout = errors.New("did not stop before the shutdown deadline")
+
+// TelemetryFlusher is implemented by telemetry sinks that buffer events and
+// must deliver them before the client shuts down
+type TelemetryFlusher interface {
+	Flush(ctx context.Context) error
+}
+
+// ShutdownFailure records a component that failed to stop cleanly
+type ShutdownFailure struct {
+	Component string
+	Err       error
+}
+
+// ShutdownError is returned by Shutdown when any component failed to stop
+type ShutdownError struct {
+	Failures []ShutdownFailure
+}
+
+// Error lists each component that failed and why
+func (e *ShutdownError) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		parts = append(parts, fmt.Sprintf("%s: %v", failure.Component, failure.Err))
+	}
+	return "shutdown incomplete: " + strings.Join(parts, "; ")
+}
+
+// Unwrap returns the per-component errors
+func (e *ShutdownError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
+	}
+	return errs
+}
+
+// Close shuts the client down, waiting at most DefaultShutdownTimeout
+func (c *Client) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+	defer cancel()
+	return c.Shutdown(ctx)
+}
+
+// Shutdown stops background goroutines, releases streams waiting for a slot,
+// flushes loop telemetry and closes the providers it has opened. Components
+// that fail or are still running when ctx is done are reported in a
+// *ShutdownError. Calling Shutdown again after it has run is a no-op.
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.closeMu.Lock()
+	if c.closed {
+		c.closeMu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.closeMu.Unlock()
+
+	var failures []ShutdownFailure
+	stop := func(component string, fn func(ctx context.Context) error) {
+		if err := stopWithin(ctx, fn); err != nil {
+			failures = append(failures, ShutdownFailure{Component: component, Err: err})
+		}
+	}
+
+	if c.janitor != nil {
+		stop("cleanup janitor", func(context.Context) error {
+			c.janitor.close()
+			return nil
+		})
+	}
+
+	stop("stream scheduler", func(context.Context) error {
+		c.streamScheduler.close()
+		return nil
+	})
+
+	stop("request queues", func(context.Context) error {
+		c.requests.close()
+		return nil
+	})
+
+	stop("cache refreshes", func(context.Context) error {
+		c.revalidations.Wait()
+		return nil
+	})
+
+	if c.loopDetectors != nil {
+		stop("loop telemetry", func(ctx context.Context) error {
+			c.loopDetectors.flushTelemetry()
+			if flusher, ok := c.loopDetectors.telemetrySink().(TelemetryFlusher); ok {
+				return flusher.Flush(ctx)
+			}
+			return nil
+		})
+	}
+
+	// Under stateMu so a concurrent reload cannot publish providers after this
+	c.stateMu.Lock()
+	st := c.currentState()
+	types, instances := st.providers.close()
+	c.stateMu.Unlock()
+	for _, providerType := range types {
+		stop(fmt.Sprintf("provider %s", providerType), func(context.Context) error {
+			return instances[providerType].Close()
+		})
+	}
+
+	// Closing subscriptions cannot block, so it happens even past the deadline
+	c.events.closeAll()
+
+	if len(failures) > 0 {
+		for _, failure := range failures {
+			c.Logger().Warn("shutdown step failed", slog.String("component", failure.Component),
+				slog.String("error", failure.Err.Error()))
+		}
+		return &ShutdownError{Failures: failures}
+	}
+	return nil
+}
+
+// stopWithin runs fn, giving up with ErrShutdownTimeout once ctx is done. A
+// component that times out keeps stopping in the background.
+func stopWithin(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Err() != nil {
+		return ErrShutdownTimeout
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ErrShutdownTimeout
+	}
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// closeErrorMockProvider fails to close
+type closeErrorMockProvider struct {
+	MockProvider
+}
+
+func (m *closeErrorMockProvider) Close() error {
+	return errors.New("connection still in use")
+}
+
+// flushingSink records loop telemetry and blocks Flush until release is closed
+type flushingSink struct {
+	flushed chan struct{}
+	release chan struct{}
+}
+
+func (s *flushingSink) RecordLoopTelemetry(event LoopTelemetryEvent) {}
+
+func (s *flushingSink) Flush(ctx context.Context) error {
+	close(s.flushed)
+	<-s.release
+	return nil
+}
+
+func newShutdownClient(t *testing.T) *Client {
+	t.Helper()
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.MaxConcurrentStreams = 1
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	useProvider(client, &MockProvider{providerType: providers.ProviderOpenAI})
+	return client
+}
+
+func TestClient_Shutdown(t *testing.T) {
+	client := newShutdownClient(t)
+
+	// A stream waiting for a slot is released on shutdown
+	if _, err := client.acquireStreamSlot(context.Background()); err != nil {
+		t.Fatalf("acquireStreamSlot failed: %v", err)
+	}
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := client.acquireStreamSlot(context.Background())
+		waiting <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	select {
+	case err := <-waiting:
+		if err == nil {
+			t.Error("Expected the waiting stream to be rejected")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Waiting stream was not released")
+	}
+	if _, err := client.acquireStreamSlot(context.Background()); err == nil {
+		t.Error("Expected new streams to be rejected after shutdown")
+	}
+
+	// Closing twice is a no-op
+	if err := client.Close(); err != nil {
+		t.Errorf("Expected second Close to succeed, got %v", err)
+	}
+}
+
+func TestClient_ShutdownReportsFailures(t *testing.T) {
+	client := newShutdownClient(t)
+	useProvider(client, &closeErrorMockProvider{MockProvider{providerType: providers.ProviderOpenAI}})
+
+	sink := &flushingSink{flushed: make(chan struct{}), release: make(chan struct{})}
+	defer close(sink.release)
+	client.SetLoopTelemetrySink(sink)
+	var logs bytes.Buffer
+	client.SetLogHandler(slog.NewTextHandler(&logs, nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := client.Shutdown(ctx)
+	if !strings.Contains(logs.String(), "component=\"loop telemetry\"") {
+		t.Errorf("Expected the failed components to be logged, got %q", logs.String())
+	}
+
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Fatalf("Expected *ShutdownError, got %v", err)
+	}
+	if !errors.Is(err, ErrShutdownTimeout) {
+		t.Errorf("Expected the stuck telemetry flush to time out, got %v", err)
+	}
+	select {
+	case <-sink.flushed:
+	default:
+		t.Error("Expected the telemetry sink to be flushed")
+	}
+
+	var components []string
+	for _, failure := range shutdownErr.Failures {
+		components = append(components, failure.Component)
+	}
+	if got := strings.Join(components, ","); got != "loop telemetry,provider openai" {
+		t.Errorf("Unexpected failed components %q", got)
+	}
+}
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// DefaultVerifyInstruction asks the verifying model to return the draft
+// unchanged when it is good and a corrected answer otherwise
+const DefaultVerifyInstruction = "Review your previous answer for correctness and completeness. " +
+	"If it is correct, repeat it exactly. Otherwise reply with a corrected answer only, without commentary."
+
+// SpeculativeOptions configures Client.SendSpeculative. A draft is escalated to
+// the verify model when any enabled heuristic flags it; with no heuristics set
+// only truncated or empty drafts are escalated.
+type SpeculativeOptions struct {
+	DraftModel     string
+	DraftProvider  providers.ProviderType // Optional, defaults to the request's provider
+	VerifyModel    string
+	VerifyProvider providers.ProviderType // Optional, defaults to the request's provider
+
+	MinLength int                // Escalate drafts shorter than this many characters
+	MaxLength int                // Escalate drafts longer than this many characters
+	Validator func(string) error // Escalate drafts the validator rejects
+
+	// Judge scores a draft between 0 and 1; drafts below JudgeThreshold are escalated
+	Judge          func(ctx context.Context, messages []gomini.Message, draft string) (float64, error)
+	JudgeThreshold float64
+
+	VerifyInstruction string // Defaults to DefaultVerifyInstruction
+}
+
+// SpeculativeResult is the outcome of Client.SendSpeculative
+type SpeculativeResult struct {
+	Text        string        `json:"text"`
+	Draft       string        `json:"draft"`
+	Model       string        `json:"model"`            // Model that produced Text
+	Verified    bool          `json:"verified"`         // Whether the verify model was called
+	Reason      string        `json:"reason,omitempty"` // Why the draft was escalated
+	Usage       *gomini.Usage `json:"usage,omitempty"`  // Combined usage of both calls
+	DraftUsage  *gomini.Usage `json:"draft_usage,omitempty"`
+	VerifyUsage *gomini.Usage `json:"verify_usage,omitempty"`
+}
+
+// SendSpeculative drafts an answer with a fast, cheap model and only calls the
+// stronger verify model, which sees the draft and may edit it, when a heuristic
+// says the draft needs it.
+func (c *Client) SendSpeculative(ctx context.Context, request *gomini.ChatRequest, opts SpeculativeOptions) (*SpeculativeResult, error) {
+	if opts.DraftModel == "" || opts.VerifyModel == "" {
+		return nil, fmt.Errorf("speculative mode requires both a draft and a verify model")
+	}
+
+	draftRequest := *request
+	draftRequest.Model = opts.DraftModel
+	if opts.DraftProvider != "" {
+		draftRequest.Provider = opts.DraftProvider
+	}
+	draftResp, err := c.SendMessage(ctx, &draftRequest)
+	if err != nil {
+		return nil, fmt.Errorf("draft failed: %w", err)
+	}
+	draft, finishReason, err := responseText(draftResp)
+	if err != nil {
+		return nil, fmt.Errorf("draft failed: %w", err)
+	}
+
+	result := &SpeculativeResult{
+		Text:       draft,
+		Draft:      draft,
+		Model:      opts.DraftModel,
+		Usage:      addUsage(nil, draftResp.Usage),
+		DraftUsage: draftResp.Usage,
+	}
+
+	reason, err := escalationReason(ctx, request.Messages, draft, finishReason, opts)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		return result, nil
+	}
+
+	instruction := opts.VerifyInstruction
+	if instruction == "" {
+		instruction = DefaultVerifyInstruction
+	}
+	verifyRequest := *request
+	verifyRequest.Model = opts.VerifyModel
+	if opts.VerifyProvider != "" {
+		verifyRequest.Provider = opts.VerifyProvider
+	}
+	verifyRequest.Messages = append(append([]gomini.Message{}, request.Messages...),
+		gomini.NewAssistantMessage(draft),
+		gomini.NewUserMessage(instruction),
+	)
+
+	verifyResp, err := c.SendMessage(ctx, &verifyRequest)
+	if err != nil {
+		return nil, fmt.Errorf("verification failed: %w", err)
+	}
+	text, _, err := responseText(verifyResp)
+	if err != nil {
+		return nil, fmt.Errorf("verification failed: %w", err)
+	}
+
+	result.Text = text
+	result.Model = opts.VerifyModel
+	result.Verified = true
+	result.Reason = reason
+	result.VerifyUsage = verifyResp.Usage
+	result.Usage = addUsage(result.Usage, verifyResp.Usage)
+	return result, nil
+}
+
+// escalationReason returns why a draft should be verified, or "" to accept it
+func escalationReason(ctx context.Context, messages []gomini.Message, draft string, finishReason gomini.FinishReason, opts SpeculativeOptions) (string, error) {
+	trimmed := strings.TrimSpace(draft)
+	switch {
+	case trimmed == "":
+		return "empty draft", nil
+	case finishReason == providers.FinishReasonLength:
+		return "draft was truncated", nil
+	case opts.MinLength > 0 && len(trimmed) < opts.MinLength:
+		return fmt.Sprintf("draft shorter than %d characters", opts.MinLength), nil
+	case opts.MaxLength > 0 && len(trimmed) > opts.MaxLength:
+		return fmt.Sprintf("draft longer than %d characters", opts.MaxLength), nil
+	}
+
+	if opts.Validator != nil {
+		if err := opts.Validator(draft); err != nil {
+			return fmt.Sprintf("validator rejected draft: %v", err), nil
+		}
+	}
+
+	if opts.Judge != nil {
+		score, err := opts.Judge(ctx, messages, draft)
+		if err != nil {
+			return "", fmt.Errorf("judge failed: %w", err)
+		}
+		if score < opts.JudgeThreshold {
+			return fmt.Sprintf("judge score %.2f below %.2f", score, opts.JudgeThreshold), nil
+		}
+	}
+	return "", nil
+}
+
+// responseText returns the text and finish reason of a response's first choice
+func responseText(resp *gomini.ChatResponse) (string, gomini.FinishReason, error) {
+	if len(resp.Choices) == 0 {
+		return "", "", fmt.Errorf("no choices in response")
+	}
+	return resp.Choices[0].Content(), resp.Choices[0].FinishReason, nil
+}
+
+// addUsage returns the sum of two usages without modifying either
+func addUsage(a, b *gomini.Usage) *gomini.Usage {
+	if a == nil && b == nil {
+		return nil
+	}
+	sum := &gomini.Usage{}
+	for _, u := range []*gomini.Usage{a, b} {
+		if u == nil {
+			continue
+		}
+		sum.InputTokens += u.InputTokens
+		sum.OutputTokens += u.OutputTokens
+		sum.TotalTokens += u.TotalTokens
+		sum.PromptTokens += u.PromptTokens
+		sum.CompletionTokens += u.CompletionTokens
+	}
+	return sum
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// modelMockProvider answers each model with a fixed text
+type modelMockProvider struct {
+	MockProvider
+	answers  map[string]string
+	requests []*gomini.ChatRequest
+}
+
+func (m *modelMockProvider) SendMessage(ctx context.Context, request *gomini.ChatRequest) (*gomini.ChatResponse, error) {
+	m.requests = append(m.requests, request)
+	return &gomini.ChatResponse{
+		Model: request.Model,
+		Choices: []gomini.Choice{{
+			Message:      map[string]interface{}{"role": "assistant", "content": m.answers[request.Model]},
+			FinishReason: providers.FinishReasonStop,
+		}},
+		Usage: &gomini.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+	}, nil
+}
+
+func TestClient_SendSpeculative(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	request := &gomini.ChatRequest{Messages: []gomini.Message{gomini.NewUserMessage("What is 2+2?")}}
+
+	tests := []struct {
+		name         string
+		opts         SpeculativeOptions
+		expectText   string
+		expectVerify bool
+	}{
+		{
+			name:       "draft accepted",
+			opts:       SpeculativeOptions{MinLength: 1},
+			expectText: "4",
+		},
+		{
+			name:         "too short",
+			opts:         SpeculativeOptions{MinLength: 5},
+			expectText:   "2+2 equals 4.",
+			expectVerify: true,
+		},
+		{
+			name: "validator rejects",
+			opts: SpeculativeOptions{Validator: func(draft string) error {
+				return errors.New("not a sentence")
+			}},
+			expectText:   "2+2 equals 4.",
+			expectVerify: true,
+		},
+		{
+			name: "judge score below threshold",
+			opts: SpeculativeOptions{
+				Judge: func(ctx context.Context, messages []gomini.Message, draft string) (float64, error) {
+					return 0.3, nil
+				},
+				JudgeThreshold: 0.7,
+			},
+			expectText:   "2+2 equals 4.",
+			expectVerify: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProvider := &modelMockProvider{
+				MockProvider: MockProvider{providerType: providers.ProviderOpenAI},
+				answers:      map[string]string{"gpt-4o-mini": "4", "gpt-4o": "2+2 equals 4."},
+			}
+			useProvider(client, mockProvider)
+
+			tt.opts.DraftModel = "gpt-4o-mini"
+			tt.opts.VerifyModel = "gpt-4o"
+			result, err := client.SendSpeculative(context.Background(), request, tt.opts)
+			if err != nil {
+				t.Fatalf("SendSpeculative failed: %v", err)
+			}
+
+			if result.Text != tt.expectText || result.Verified != tt.expectVerify {
+				t.Errorf("Expected %q (verified=%v), got %q (verified=%v)", tt.expectText, tt.expectVerify, result.Text, result.Verified)
+			}
+			if result.Draft != "4" {
+				t.Errorf("Expected draft to be kept, got %q", result.Draft)
+			}
+
+			expectedCalls, expectedTokens := 1, 15
+			if tt.expectVerify {
+				expectedCalls, expectedTokens = 2, 30
+				if result.Reason == "" {
+					t.Error("Expected an escalation reason")
+				}
+				verifyMessages := mockProvider.requests[1].Messages
+				if len(verifyMessages) != 3 {
+					t.Errorf("Expected the draft and instruction to be appended, got %v", verifyMessages)
+				}
+			}
+			if len(mockProvider.requests) != expectedCalls {
+				t.Errorf("Expected %d calls, got %d", expectedCalls, len(mockProvider.requests))
+			}
+			if result.Usage.TotalTokens != expectedTokens {
+				t.Errorf("Expected %d combined tokens, got %d", expectedTokens, result.Usage.TotalTokens)
+			}
+			if len(request.Messages) != 1 {
+				t.Errorf("Caller's messages should not be modified, got %v", request.Messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// Speech synthesizes text into audio with the current provider, or with the
+// one the request names
+func (c *Client) Speech(ctx context.Context, request *gomini.SpeechRequest) (*gomini.SpeechResponse, error) {
+	st, release := c.holdState()
+	defer release()
+	audio, err := audioProvider(st, request.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return audio.Speech(ctx, request)
+}
+
+// SpeechStream synthesizes text into audio delivered as EventAudio chunks,
+// ending with EventFinished or EventError
+func (c *Client) SpeechStream(ctx context.Context, request *gomini.SpeechRequest) <-chan gomini.StreamEvent {
+	// The state is held until the stream ends
+	st, releaseState := c.holdState()
+	resultChan := make(chan gomini.StreamEvent, 10)
+
+	go func() {
+		defer close(resultChan)
+		defer releaseState()
+
+		audio, err := audioProvider(st, request.Provider)
+		if err != nil {
+			resultChan <- gomini.NewErrorEvent(st.providerType, request.Model, err, false)
+			return
+		}
+
+		for event := range audio.SpeechStream(ctx, request) {
+			resultChan <- c.convertStreamEvent(event)
+		}
+	}()
+
+	return c.publishStream(resultChan)
+}
+
+// Transcribe converts speech audio into text with the current provider, or
+// with the one the request names
+func (c *Client) Transcribe(ctx context.Context, request *gomini.TranscriptionRequest) (*gomini.TranscriptionResponse, error) {
+	st, release := c.holdState()
+	defer release()
+	audio, err := audioProvider(st, request.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return audio.Transcribe(ctx, request)
+}
+
+// audioProvider returns the requested provider, or the state's provider when
+// none is named, as an AudioProvider
+func audioProvider(st *clientState, providerType providers.ProviderType) (providers.AudioProvider, error) {
+	if providerType != "" {
+		routed, err := st.withProvider(providerType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to switch to provider %s: %w", providerType, err)
+		}
+		st = routed
+	}
+
+	audio, ok := st.provider.(providers.AudioProvider)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support speech", st.providerType)
+	}
+	return audio, nil
+}
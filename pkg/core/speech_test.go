@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+type audioMockProvider struct {
+	MockProvider
+}
+
+func (m *audioMockProvider) Speech(ctx context.Context, req *providers.SpeechRequest) (*providers.SpeechResponse, error) {
+	return &providers.SpeechResponse{Model: req.Model, Audio: []byte("ID3"), MIMEType: "audio/mpeg"}, nil
+}
+
+func (m *audioMockProvider) SpeechStream(ctx context.Context, req *providers.SpeechRequest) <-chan providers.StreamEvent {
+	events := make(chan providers.StreamEvent, 3)
+	events <- providers.NewAudioChunkEvent(m.providerType, req.Model, []byte("ID"), "audio/mpeg", 0)
+	events <- providers.NewAudioChunkEvent(m.providerType, req.Model, []byte("3"), "audio/mpeg", 1)
+	events <- providers.StreamEvent{Type: providers.EventFinished, Provider: m.providerType}
+	close(events)
+	return events
+}
+
+func (m *audioMockProvider) Transcribe(ctx context.Context, req *providers.TranscriptionRequest) (*providers.TranscriptionResponse, error) {
+	return &providers.TranscriptionResponse{Model: req.Model, Text: "hello world"}, nil
+}
+
+func TestClient_Speech(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	useProvider(client, &MockProvider{providerType: providers.ProviderOpenAI})
+	if _, err := client.Transcribe(context.Background(), &gomini.TranscriptionRequest{}); err == nil {
+		t.Error("Expected error from a provider without speech support")
+	}
+
+	useProvider(client, &audioMockProvider{MockProvider{providerType: providers.ProviderOpenAI}})
+
+	var audio []byte
+	var finished bool
+	for event := range client.SpeechStream(context.Background(), &gomini.SpeechRequest{Input: "hi", Model: "tts-1"}) {
+		switch event.Type {
+		case gomini.EventAudio:
+			chunk, ok := event.Data.(gomini.AudioChunkEvent)
+			if !ok {
+				t.Fatalf("Expected AudioChunkEvent, got %T", event.Data)
+			}
+			audio = append(audio, chunk.Data...)
+		case gomini.EventFinished:
+			finished = true
+		}
+	}
+	if string(audio) != "ID3" || !finished {
+		t.Errorf("Expected all chunks followed by finished, got %q (finished=%v)", audio, finished)
+	}
+
+	transcript, err := client.Transcribe(context.Background(), &gomini.TranscriptionRequest{
+		Audio: gomini.AudioPart{MIMEType: "audio/wav", Data: []byte("RIFF")},
+		Model: "whisper-1",
+	})
+	if err != nil || transcript.Text != "hello world" {
+		t.Errorf("Unexpected transcription %+v, %v", transcript, err)
+	}
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// clientState is a consistent view of the client's configuration and active
+// provider. It is never modified: ReloadConfig and SwitchProvider publish a
+// new state instead, so a request loads it once and runs against a single
+// configuration even while the client is reconfigured.
+type clientState struct {
+	config       *gomini.Config
+	providerType providers.ProviderType
+	provider     providers.LLMProvider // Live instance of providerType
+	providers    *providerSet          // Live instances built from config
+}
+
+// withProvider returns the state with providerType as its provider, building
+// the provider's instance on first use. The receiver is unchanged, so a
+// request can move to another provider without affecting other requests.
+func (s *clientState) withProvider(providerType providers.ProviderType) (*clientState, error) {
+	if providerType == s.providerType {
+		return s, nil
+	}
+	provider, err := s.providers.get(providerType)
+	if err != nil {
+		return nil, err
+	}
+	return &clientState{config: s.config, providerType: providerType, provider: provider, providers: s.providers}, nil
+}
+
+// currentState returns the client's current state
+func (c *Client) currentState() *clientState {
+	return c.state.Load()
+}
+
+// holdState returns the current state and keeps its providers open until
+// release is called. Every call to a provider runs under a hold, so one
+// retired by ReloadConfig is closed only after the calls using it finish.
+func (c *Client) holdState() (*clientState, func()) {
+	for {
+		st := c.state.Load()
+		if release, ok := st.providers.hold(); ok {
+			return st, release
+		}
+		// Retired by a reload that has already published its successor
+	}
+}
+
+// providerSet holds one live instance per provider type, built from one
+// config on first use and shared by every request on that config
+type providerSet struct {
+	build func(providers.ProviderType) (providers.LLMProvider, error)
+
+	mu        sync.Mutex
+	instances map[providers.ProviderType]providers.LLMProvider
+	holds     int
+	retired   bool // Replaced by a reload; closed once holds reaches 0
+	closed    bool // Closed by Shutdown
+}
+
+func newProviderSet(build func(providers.ProviderType) (providers.LLMProvider, error)) *providerSet {
+	return &providerSet{build: build, instances: make(map[providers.ProviderType]providers.LLMProvider)}
+}
+
+// get returns the instance of providerType, building it on first use
+func (s *providerSet) get(providerType providers.ProviderType) (providers.LLMProvider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if provider, ok := s.instances[providerType]; ok {
+		return provider, nil
+	}
+	if s.closed {
+		return nil, fmt.Errorf("client is closed")
+	}
+	provider, err := s.build(providerType)
+	if err != nil {
+		return nil, err
+	}
+	s.instances[providerType] = provider
+	return provider, nil
+}
+
+// hold keeps the instances open until release is called. It fails once the
+// set is retired.
+func (s *providerSet) hold() (release func(), ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.retired {
+		return nil, false
+	}
+	s.holds++
+
+	var once sync.Once
+	return func() { once.Do(s.release) }, true
+}
+
+// release ends a hold, closing the instances if it was the last one on a retired set
+func (s *providerSet) release() {
+	s.mu.Lock()
+	s.holds--
+	var closing []providers.LLMProvider
+	if s.holds == 0 && s.retired {
+		closing = s.take()
+	}
+	s.mu.Unlock()
+
+	for _, provider := range closing {
+		provider.Close()
+	}
+}
+
+// retire stops new holds and closes the instances now, or after the calls
+// holding the set when there are any
+func (s *providerSet) retire() {
+	s.mu.Lock()
+	s.retired = true
+	if s.holds > 0 {
+		s.mu.Unlock()
+		return
+	}
+	closing := s.take()
+	s.mu.Unlock()
+
+	for _, provider := range closing {
+		provider.Close()
+	}
+}
+
+// close hands the instances to Shutdown to close, with their provider types
+// in sorted order. Later requests fail instead of building new instances.
+func (s *providerSet) close() ([]providers.ProviderType, map[providers.ProviderType]providers.LLMProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	types := make([]providers.ProviderType, 0, len(s.instances))
+	for providerType := range s.instances {
+		types = append(types, providerType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types, s.instances
+}
+
+// take empties the set, returning the instances to close. Callers hold s.mu.
+func (s *providerSet) take() []providers.LLMProvider {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	closing := make([]providers.LLMProvider, 0, len(s.instances))
+	for _, provider := range s.instances {
+		closing = append(closing, provider)
+	}
+	return closing
+}
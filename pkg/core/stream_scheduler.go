@@ -0,0 +1,198 @@
+package core
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math"
+	"sync"
+)
+
+// StreamPriority orders streams competing for read slots when
+// Config.MaxConcurrentStreams is set, and requests queued for a provider's
+// MaxConcurrentRequests. Higher priorities are served first.
+type StreamPriority int
+
+const (
+	PriorityBatch       StreamPriority = -1
+	PriorityNormal      StreamPriority = 0
+	PriorityInteractive StreamPriority = 1
+)
+
+type streamPriorityKey struct{}
+
+// WithStreamPriority returns a context that schedules streams started with it at priority
+func WithStreamPriority(ctx context.Context, priority StreamPriority) context.Context {
+	return context.WithValue(ctx, streamPriorityKey{}, priority)
+}
+
+// streamPriorityFrom returns the stream priority stored in ctx, defaulting to PriorityNormal
+func streamPriorityFrom(ctx context.Context) StreamPriority {
+	if priority, ok := ctx.Value(streamPriorityKey{}).(StreamPriority); ok {
+		return priority
+	}
+	return PriorityNormal
+}
+
+// streamScheduler hands out a fixed number of read slots. A stream holds a
+// slot while it reads a single event from its provider and forwards it, then
+// queues again behind other waiters, so streams of equal priority take turns
+// event by event and a long generation cannot starve shorter ones. While a
+// stream waits nothing reads its provider channel, so the provider is
+// throttled by backpressure; a stream whose consumer falls behind keeps its
+// slot until the event is taken.
+type streamScheduler struct {
+	mu      sync.Mutex
+	slots   int
+	active  int
+	seq     uint64
+	waiters waiterQueue
+	closed  bool
+}
+
+type streamWaiter struct {
+	priority StreamPriority
+	seq      uint64
+	ready    chan struct{}
+	index    int
+	err      error // Set when woken by close instead of granted a slot
+}
+
+// errSchedulerClosed is returned to streams waiting for a slot when the client closes
+var errSchedulerClosed = errors.New("client is closed")
+
+func newStreamScheduler(slots int) *streamScheduler {
+	return &streamScheduler{slots: slots}
+}
+
+// acquire blocks until a read slot is granted or ctx is done
+func (s *streamScheduler) acquire(ctx context.Context, priority StreamPriority) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errSchedulerClosed
+	}
+	if s.active < s.slots && len(s.waiters) == 0 {
+		s.active++
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.seq++
+	waiter := &streamWaiter{priority: priority, seq: s.seq, ready: make(chan struct{})}
+	heap.Push(&s.waiters, waiter)
+	s.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return waiter.err
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-waiter.ready:
+			// The slot was granted while cancelling; pass it on
+			if waiter.err == nil {
+				s.releaseLocked()
+			}
+		default:
+			heap.Remove(&s.waiters, waiter.index)
+		}
+		return ctx.Err()
+	}
+}
+
+// release returns a read slot, granting it to the next waiter if any
+func (s *streamScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.releaseLocked()
+}
+
+func (s *streamScheduler) releaseLocked() {
+	if len(s.waiters) > 0 && s.active <= s.slots {
+		// Hand the slot over directly so active stays constant
+		waiter := heap.Pop(&s.waiters).(*streamWaiter)
+		close(waiter.ready)
+		return
+	}
+	s.active--
+}
+
+// resize changes the number of read slots, granting new ones to waiters.
+// When shrinking, busy slots drain as they are released.
+func (s *streamScheduler) resize(slots int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.slots = slots
+	for s.active < s.slots && len(s.waiters) > 0 {
+		waiter := heap.Pop(&s.waiters).(*streamWaiter)
+		s.active++
+		close(waiter.ready)
+	}
+}
+
+// close rejects new acquires and wakes every waiting stream with errSchedulerClosed
+func (s *streamScheduler) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for len(s.waiters) > 0 {
+		waiter := heap.Pop(&s.waiters).(*streamWaiter)
+		waiter.err = errSchedulerClosed
+		close(waiter.ready)
+	}
+}
+
+// waiterQueue is a heap ordered by priority, then arrival
+type waiterQueue []*streamWaiter
+
+func (q waiterQueue) Len() int { return len(q) }
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x interface{}) {
+	waiter := x.(*streamWaiter)
+	waiter.index = len(*q)
+	*q = append(*q, waiter)
+}
+
+func (q *waiterQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	waiter := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return waiter
+}
+
+// streamSlots is the number of read slots for a MaxConcurrentStreams limit;
+// zero or less means unlimited
+func streamSlots(limit int) int {
+	if limit <= 0 {
+		return math.MaxInt
+	}
+	return limit
+}
+
+// acquireStreamSlot waits for a read slot and returns the function that gives
+// it back
+func (c *Client) acquireStreamSlot(ctx context.Context) (func(), error) {
+	if err := c.streamScheduler.acquire(ctx, streamPriorityFrom(ctx)); err != nil {
+		return nil, err
+	}
+	return c.streamScheduler.release, nil
+}
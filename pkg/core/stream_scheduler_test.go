@@ -0,0 +1,269 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// waitForWaiters blocks until n streams are queued on the scheduler
+func waitForWaiters(t *testing.T, s *streamScheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		queued := len(s.waiters)
+		s.mu.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d waiters", n)
+}
+
+// waitForIdle blocks until every slot has been released
+func waitForIdle(t *testing.T, s *streamScheduler) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		active := s.active
+		s.mu.Unlock()
+		if active == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("expected all slots to be released")
+}
+
+func TestStreamScheduler_PriorityThenArrival(t *testing.T) {
+	scheduler := newStreamScheduler(1)
+	ctx := context.Background()
+
+	if err := scheduler.acquire(ctx, PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan string, 3)
+	enqueue := func(name string, priority StreamPriority, queued int) {
+		go func() {
+			if err := scheduler.acquire(ctx, priority); err != nil {
+				t.Error(err)
+				return
+			}
+			order <- name
+			scheduler.release()
+		}()
+		waitForWaiters(t, scheduler, queued)
+	}
+
+	enqueue("long-1", PriorityNormal, 1)
+	enqueue("batch", PriorityBatch, 2)
+	enqueue("interactive", PriorityInteractive, 3)
+
+	scheduler.release()
+
+	expected := []string{"interactive", "long-1", "batch"}
+	for _, name := range expected {
+		select {
+		case got := <-order:
+			if got != name {
+				t.Fatalf("expected %s, got %s", name, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s", name)
+		}
+	}
+
+	waitForIdle(t, scheduler)
+}
+
+func TestStreamScheduler_Cancel(t *testing.T) {
+	scheduler := newStreamScheduler(1)
+	if err := scheduler.acquire(context.Background(), PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- scheduler.acquire(ctx, PriorityNormal) }()
+	waitForWaiters(t, scheduler, 1)
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	waitForWaiters(t, scheduler, 0)
+
+	scheduler.release()
+	waitForIdle(t, scheduler)
+}
+
+func TestStreamPriorityFromContext(t *testing.T) {
+	if priority := streamPriorityFrom(context.Background()); priority != PriorityNormal {
+		t.Errorf("expected default priority, got %d", priority)
+	}
+	ctx := WithStreamPriority(context.Background(), PriorityInteractive)
+	if priority := streamPriorityFrom(ctx); priority != PriorityInteractive {
+		t.Errorf("expected interactive priority, got %d", priority)
+	}
+}
+
+// pacedStreamProvider streams events on unbuffered channels and counts the
+// events each stream's reader has taken, in the order streams were started
+type pacedStreamProvider struct {
+	MockProvider
+	events int
+	mu     sync.Mutex
+	sent   []*atomic.Int32
+}
+
+func (p *pacedStreamProvider) SendMessageStream(ctx context.Context, request *gomini.ChatRequest) <-chan providers.StreamEvent {
+	sent := &atomic.Int32{}
+	p.mu.Lock()
+	p.sent = append(p.sent, sent)
+	p.mu.Unlock()
+
+	events := make(chan providers.StreamEvent)
+	go func() {
+		defer close(events)
+		for i := 0; i < p.events; i++ {
+			select {
+			case events <- providers.NewContentEvent(p.providerType, request.Model, fmt.Sprintf("token%d ", i), true):
+				sent.Add(1)
+			case <-ctx.Done():
+				return
+			}
+		}
+		events <- providers.StreamEvent{Type: providers.EventFinished, Provider: p.providerType, Model: request.Model}
+	}()
+	return events
+}
+
+// sentBy reports how many events the nth stream has handed over
+func (p *pacedStreamProvider) sentBy(n int) int32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n >= len(p.sent) {
+		return 0
+	}
+	return p.sent[n].Load()
+}
+
+// waitForSteady waits until read stops changing and returns its last value
+func waitForSteady(read func() int32) int32 {
+	last := read()
+	for {
+		time.Sleep(20 * time.Millisecond)
+		current := read()
+		if current == last {
+			return current
+		}
+		last = current
+	}
+}
+
+func TestClient_StreamSlotThrottlesProviderReads(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{
+		Enabled: true,
+		APIKey:  "test-key",
+	}
+	config.DefaultProvider = providers.ProviderOpenAI
+	config.MaxConcurrentStreams = 1
+	config.LoopDetectionEnabled = false
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+	provider := &pacedStreamProvider{MockProvider: MockProvider{providerType: providers.ProviderOpenAI}, events: 100}
+	useProvider(client, provider)
+
+	request := func() *gomini.ChatRequest {
+		return &gomini.ChatRequest{Messages: []gomini.Message{gomini.NewUserMessage("Hello")}, Model: "test-model"}
+	}
+
+	// Nobody consumes stream A, so it stops once its buffers are full while
+	// holding the only read slot
+	slow := client.SendMessageStream(context.Background(), request(), "slow")
+	if sent := waitForSteady(func() int32 { return provider.sentBy(0) }); sent >= 100 {
+		t.Fatalf("Expected the unconsumed stream to stall, it sent all %d events", sent)
+	}
+
+	// Stream B's consumer is eager, but its provider is read only through
+	// buffers until A's consumer catches up
+	var received atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range client.SendMessageStream(context.Background(), request(), "fast") {
+			received.Add(1)
+		}
+	}()
+	sent := waitForSteady(func() int32 { return provider.sentBy(1) })
+	if sent >= 100 || received.Load() != 0 {
+		t.Fatalf("Expected stream B to wait for the slot, provider sent %d and consumer got %d", sent, received.Load())
+	}
+
+	for range slow {
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stream B did not finish after stream A drained")
+	}
+	if sent := provider.sentBy(1); sent != 100 {
+		t.Errorf("Expected stream B to send all events, got %d", sent)
+	}
+}
+
+func TestStreamScheduler_Resize(t *testing.T) {
+	scheduler := newStreamScheduler(2)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := scheduler.acquire(ctx, PriorityNormal); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	granted := make(chan struct{}, 1)
+	go func() {
+		if err := scheduler.acquire(ctx, PriorityNormal); err != nil {
+			t.Error(err)
+			return
+		}
+		granted <- struct{}{}
+	}()
+	waitForWaiters(t, scheduler, 1)
+
+	// Shrinking holds the waiter until active streams drop below the new limit
+	scheduler.resize(1)
+	scheduler.release()
+	select {
+	case <-granted:
+		t.Fatal("waiter granted a slot above the new limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Growing grants queued waiters straight away
+	scheduler.resize(3)
+	select {
+	case <-granted:
+	case <-time.After(time.Second):
+		t.Fatal("waiter not granted after growing")
+	}
+
+	scheduler.release()
+	scheduler.release()
+	waitForIdle(t, scheduler)
+}
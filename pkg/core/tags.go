@@ -0,0 +1,43 @@
+package core
+
+import (
+	"gomini/pkg/gomini"
+)
+
+// mergeTags overlays request tags on the configured defaults; request tags win
+func mergeTags(defaults, tags map[string]string) map[string]string {
+	if len(defaults) == 0 {
+		return tags
+	}
+
+	merged := make(map[string]string, len(defaults)+len(tags))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range tags {
+		merged[key] = value
+	}
+	return merged
+}
+
+// withChatTags returns the request with the client's default tags applied
+func (c *Client) withChatTags(st *clientState, request *gomini.ChatRequest) *gomini.ChatRequest {
+	if len(st.config.Tags) == 0 {
+		return request
+	}
+
+	tagged := *request
+	tagged.Tags = mergeTags(st.config.Tags, request.Tags)
+	return &tagged
+}
+
+// withJSONTags returns the request with the client's default tags applied
+func (c *Client) withJSONTags(st *clientState, request *gomini.JSONRequest) *gomini.JSONRequest {
+	if len(st.config.Tags) == 0 {
+		return request
+	}
+
+	tagged := *request
+	tagged.Tags = mergeTags(st.config.Tags, request.Tags)
+	return &tagged
+}
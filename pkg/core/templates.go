@@ -0,0 +1,60 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/prompts"
+)
+
+// Prompts returns the client's template library used by SendTemplate. Add
+// templates to it directly or load them with LoadDir.
+func (c *Client) Prompts() *prompts.Library {
+	c.templatesMu.Lock()
+	defer c.templatesMu.Unlock()
+	if c.templates == nil {
+		c.templates = prompts.NewLibrary()
+	}
+	return c.templates
+}
+
+// SetPrompts replaces the client's template library, e.g. with one shared
+// between clients
+func (c *Client) SetPrompts(library *prompts.Library) {
+	c.templatesMu.Lock()
+	defer c.templatesMu.Unlock()
+	c.templates = library
+}
+
+// SendTemplate renders the named template with vars and sends it. The
+// template's model and config are defaults that opts override. Variable
+// problems fail with ErrorMissingField, other rendering errors with
+// ErrorValidation.
+func (c *Client) SendTemplate(ctx context.Context, name string, vars map[string]interface{}, opts ...SendOption) (*gomini.ChatResponse, error) {
+	rendered, err := c.Prompts().Render(name, vars)
+	if err != nil {
+		code := gomini.ErrorValidation
+		var varErr *prompts.VariableError
+		if errors.As(err, &varErr) {
+			code = gomini.ErrorMissingField
+		}
+		return nil, gomini.NewLLMError(code, fmt.Sprintf("failed to render prompt: %v", err), c.currentState().providerType, err)
+	}
+
+	var messages []gomini.Message
+	if rendered.System != "" {
+		messages = append(messages, gomini.NewSystemMessage(rendered.System))
+	}
+	messages = append(messages, gomini.NewUserMessage(rendered.User))
+
+	defaults := make([]SendOption, 0, len(rendered.Config)+1)
+	if rendered.Model != "" {
+		defaults = append(defaults, WithModel(rendered.Model))
+	}
+	for key, value := range rendered.Config {
+		defaults = append(defaults, WithConfig(key, value))
+	}
+	return c.Send(ctx, messages, append(defaults, opts...)...)
+}
@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/prompts"
+)
+
+func TestClient_SendTemplate(t *testing.T) {
+	client, mockProvider := newGenerateTestClient(t, nil)
+	client.Prompts().Add(prompts.Template{
+		Name:      "summarize",
+		System:    "You write summaries.",
+		User:      "Summarize: {{.text}}",
+		Variables: []prompts.Variable{{Name: "text", Required: true}},
+		Model:     "gpt-4o-mini",
+		Config:    map[string]interface{}{"temperature": 0.2, "max_output_tokens": 100},
+	})
+
+	_, err := client.SendTemplate(context.Background(), "summarize", map[string]interface{}{"text": "a long report"}, WithTemperature(0.7))
+	if err != nil {
+		t.Fatalf("SendTemplate failed: %v", err)
+	}
+	request := mockProvider.lastRequest
+	if request.Model != "gpt-4o-mini" || len(request.Messages) != 2 {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	if content := request.Messages[1].(map[string]interface{})["content"]; content != "Summarize: a long report" {
+		t.Errorf("unexpected user message %v", content)
+	}
+	config := request.Config.(map[string]interface{})
+	if config["temperature"] != 0.7 || config["max_output_tokens"] != 100 {
+		t.Errorf("expected options to override template config, got %v", config)
+	}
+
+	_, err = client.SendTemplate(context.Background(), "summarize", nil)
+	if !errors.Is(err, gomini.ErrMissingField) {
+		t.Errorf("expected ErrMissingField, got %v", err)
+	}
+	if _, err := client.SendTemplate(context.Background(), "translate", nil); !errors.Is(err, gomini.ErrValidation) {
+		t.Errorf("expected ErrValidation for an unknown template, got %v", err)
+	}
+}
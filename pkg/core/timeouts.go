@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// withRequestTimeout bounds a single non-streaming provider call by
+// Config.RequestTimeout. Fallback attempts each get their own timeout.
+func (c *Client) withRequestTimeout(ctx context.Context, st *clientState) (context.Context, context.CancelFunc) {
+	if st.config.RequestTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, st.config.RequestTimeout)
+}
+
+// sendWithTimeout calls SendMessage on st's provider once a request slot is
+// free, under the request timeout, and counts the call against the
+// provider's quota, load and circuit breaker
+func (c *Client) sendWithTimeout(ctx context.Context, st *clientState, request *gomini.ChatRequest) (resp *gomini.ChatResponse, err error) {
+	started, done := time.Now(), c.beginCall(ctx, st)
+	defer func() { done(time.Since(started), err) }()
+	release, err := c.acquireRequestSlot(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	ctx, cancel := c.withRequestTimeout(ctx, st)
+	defer cancel()
+	ctx, note := withKeyNote(ctx)
+	resp, err = st.provider.SendMessage(ctx, request)
+	var usage *gomini.Usage
+	if resp != nil {
+		usage = resp.Usage
+	}
+	c.recordQuota(ctx, st, note, usage)
+	return resp, err
+}
+
+// generateJSONWithTimeout calls GenerateJSON on st's provider once a request
+// slot is free, under the request timeout, and counts the call against the
+// provider's quota, load and circuit breaker
+func (c *Client) generateJSONWithTimeout(ctx context.Context, st *clientState, request *gomini.JSONRequest) (resp *gomini.JSONResponse, err error) {
+	started, done := time.Now(), c.beginCall(ctx, st)
+	defer func() { done(time.Since(started), err) }()
+	release, err := c.acquireRequestSlot(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	ctx, cancel := c.withRequestTimeout(ctx, st)
+	defer cancel()
+	ctx, note := withKeyNote(ctx)
+	resp, err = st.provider.GenerateJSON(ctx, request)
+	var usage *gomini.Usage
+	if resp != nil {
+		usage = resp.Usage
+	}
+	c.recordQuota(ctx, st, note, usage)
+	return resp, err
+}
+
+// watchIdle forwards a provider stream started with ctx until it closes. If
+// no event arrives within idle, the provider call is cancelled, a timeout
+// error event is sent and the stream ends. Once ctx is done or the stream
+// times out, the rest of events is drained in the background so the provider
+// can exit. An idle of 0 disables the timeout.
+func watchIdle(ctx context.Context, cancel context.CancelFunc, events <-chan providers.StreamEvent, idle time.Duration, provider providers.ProviderType, model string) <-chan providers.StreamEvent {
+	if idle <= 0 {
+		return events
+	}
+
+	out := make(chan providers.StreamEvent)
+	drain := func() {
+		go func() {
+			for range events {
+			}
+		}()
+	}
+	go func() {
+		defer close(out)
+		timer := time.NewTimer(idle)
+		defer timer.Stop()
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					drain()
+					return
+				}
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(idle)
+			case <-timer.C:
+				err := gomini.NewLLMError(gomini.ErrorTimeout,
+					fmt.Sprintf("stream idle for %s", idle), provider, context.DeadlineExceeded)
+				err.Model = model
+				select {
+				case out <- providers.StreamEvent{
+					Type:      providers.EventError,
+					Provider:  provider,
+					Model:     model,
+					Error:     err,
+					Timestamp: time.Now(),
+				}:
+				case <-ctx.Done():
+				}
+				cancel()
+				drain()
+				return
+			}
+		}
+	}()
+	return out
+}
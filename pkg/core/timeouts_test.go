@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// stallingMockProvider sends its scripted stream events, then stalls until
+// the request context ends. Non-streaming calls stall the same way.
+type stallingMockProvider struct {
+	MockProvider
+	cancelled chan struct{}
+}
+
+func (m *stallingMockProvider) SendMessage(ctx context.Context, request *gomini.ChatRequest) (*gomini.ChatResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (m *stallingMockProvider) SendMessageStream(ctx context.Context, request *gomini.ChatRequest) <-chan providers.StreamEvent {
+	events := make(chan providers.StreamEvent)
+	go func() {
+		defer close(events)
+		for _, event := range m.responses {
+			events <- providers.StreamEvent{Type: providers.EventType(event.Type), Provider: event.Provider, Data: event.Data}
+		}
+		<-ctx.Done()
+		close(m.cancelled)
+	}()
+	return events
+}
+
+func TestClient_RequestTimeout(t *testing.T) {
+	client := newShutdownClient(t)
+	client.currentState().config.RequestTimeout = 20 * time.Millisecond
+	useProvider(client, &stallingMockProvider{MockProvider: MockProvider{providerType: providers.ProviderOpenAI}})
+
+	start := time.Now()
+	_, err := client.SendMessage(context.Background(), &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+		Model:    "test-model",
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the timeout to cut the call short, took %s", elapsed)
+	}
+}
+
+func TestClient_StreamIdleTimeout(t *testing.T) {
+	client := newShutdownClient(t)
+	client.currentState().config.StreamIdleTimeout = 20 * time.Millisecond
+	provider := &stallingMockProvider{
+		MockProvider: MockProvider{
+			providerType: providers.ProviderOpenAI,
+			responses: []gomini.StreamEvent{
+				{Type: gomini.EventContent, Provider: providers.ProviderOpenAI, Data: providers.ContentEvent{Text: "Hel"}},
+			},
+		},
+		cancelled: make(chan struct{}),
+	}
+	useProvider(client, provider)
+
+	var events []gomini.StreamEvent
+	for event := range client.SendMessageStream(context.Background(), &gomini.ChatRequest{
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+		Model:    "test-model",
+	}, "") {
+		events = append(events, event)
+	}
+
+	if len(events) != 2 || events[0].Type != gomini.EventContent || events[1].Type != gomini.EventError {
+		t.Fatalf("expected content then a timeout error, got %+v", events)
+	}
+	if !errors.Is(events[1].Error, gomini.ErrTimeout) {
+		t.Errorf("expected a timeout error, got %v", events[1].Error)
+	}
+	select {
+	case <-provider.cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the provider stream to be cancelled")
+	}
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+// DisableTools withholds the named tools from every turn of the session
+func (s *Session) DisableTools(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.disabledTools == nil {
+		s.disabledTools = make(map[string]bool)
+	}
+	for _, name := range names {
+		s.disabledTools[name] = true
+	}
+	s.updated = time.Now()
+}
+
+// EnableTools makes previously disabled tools available again
+func (s *Session) EnableTools(names ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		delete(s.disabledTools, name)
+	}
+	s.updated = time.Now()
+}
+
+// ToolEnabled reports whether the named tool may be offered in this session
+func (s *Session) ToolEnabled(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.disabledTools[name]
+}
+
+// DisabledTools returns the names of the session's disabled tools, sorted
+func (s *Session) DisabledTools() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.disabledToolsLocked()
+}
+
+func (s *Session) disabledToolsLocked() []string {
+	if len(s.disabledTools) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(s.disabledTools))
+	for name := range s.disabledTools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyToolAvailability removes the request's disabled tools from the schema
+// sent to the model. Forcing a disabled tool through ToolChoice, or requiring
+// a tool when none remain, is rejected. The caller's request is not modified.
+func (c *Client) applyToolAvailability(st *clientState, request *gomini.ChatRequest) (*gomini.ChatRequest, error) {
+	if len(request.DisabledTools) == 0 {
+		return request, nil
+	}
+	disabled := make(map[string]bool, len(request.DisabledTools))
+	for _, name := range request.DisabledTools {
+		disabled[name] = true
+	}
+
+	if name := toolChoiceName(request.ToolChoice); name != "" && disabled[name] {
+		return nil, gomini.NewLLMError(gomini.ErrorToolDisabled,
+			fmt.Sprintf("tool choice %q names a disabled tool", name), st.providerType, nil).
+			WithRemediation(gomini.Remediation{
+				Action: gomini.RemediationEnableTool,
+				Input:  name,
+				Hint:   fmt.Sprintf("enable tool %s or drop it from the tool choice", name),
+			})
+	}
+
+	tools := make([]gomini.Tool, 0, len(request.Tools))
+	var removed []string
+	for _, tool := range request.Tools {
+		fn, err := providers.AsFunctionTool(tool)
+		if err == nil && disabled[fn.Name] {
+			removed = append(removed, fn.Name)
+			continue
+		}
+		tools = append(tools, tool)
+	}
+
+	shaped := *request
+	shaped.Tools = tools
+	shaped.DisabledTools = nil
+	if len(tools) == 0 {
+		shaped.Tools = nil
+		if parsed, _ := providers.ParseToolChoice(request.ToolChoice); parsed.Mode == providers.ToolChoiceRequired {
+			llmErr := gomini.NewLLMError(gomini.ErrorToolDisabled,
+				"tool choice requires a tool but every tool is disabled", st.providerType, nil)
+			for _, name := range removed {
+				llmErr.WithRemediation(gomini.Remediation{
+					Action: gomini.RemediationEnableTool,
+					Input:  name,
+					Hint:   fmt.Sprintf("enable tool %s", name),
+				})
+			}
+			return nil, llmErr
+		}
+		shaped.ToolChoice = nil
+	}
+	return &shaped, nil
+}
+
+// toolChoiceName returns the tool a ToolChoice forces, if any
+func toolChoiceName(choice interface{}) string {
+	parsed, err := providers.ParseToolChoice(choice)
+	if err != nil || parsed.Mode != providers.ToolChoiceFunction {
+		return ""
+	}
+	return parsed.Name
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func toolNames(t *testing.T, tools []gomini.Tool) []string {
+	t.Helper()
+	var names []string
+	for _, tool := range tools {
+		fn, err := providers.AsFunctionTool(tool)
+		if err != nil {
+			t.Fatalf("AsFunctionTool failed: %v", err)
+		}
+		names = append(names, fn.Name)
+	}
+	return names
+}
+
+func TestClient_ApplyToolAvailability(t *testing.T) {
+	client := newShutdownClient(t)
+	tools := []gomini.Tool{
+		gomini.FunctionTool{Name: "read_file"},
+		map[string]interface{}{"name": "write_file"},
+	}
+
+	tests := []struct {
+		name       string
+		disabled   []string
+		toolChoice interface{}
+		wantTools  []string
+		wantErr    bool
+	}{
+		{name: "nothing disabled", wantTools: []string{"read_file", "write_file"}},
+		{name: "write disabled", disabled: []string{"write_file"}, toolChoice: "auto", wantTools: []string{"read_file"}},
+		{name: "forced disabled tool", disabled: []string{"write_file"}, toolChoice: map[string]interface{}{"type": "function", "function": map[string]interface{}{"name": "write_file"}}, wantErr: true},
+		{name: "required with none left", disabled: []string{"read_file", "write_file"}, toolChoice: "required", wantErr: true},
+		{name: "all disabled", disabled: []string{"read_file", "write_file"}, toolChoice: "auto"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &gomini.ChatRequest{Tools: tools, ToolChoice: tt.toolChoice, DisabledTools: tt.disabled}
+			shaped, err := client.applyToolAvailability(client.currentState(), request)
+			if tt.wantErr {
+				if !errors.Is(err, gomini.ErrToolDisabled) {
+					t.Fatalf("Expected ErrToolDisabled, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyToolAvailability failed: %v", err)
+			}
+			if got := toolNames(t, shaped.Tools); len(got) != len(tt.wantTools) || (len(got) > 0 && got[0] != tt.wantTools[0]) {
+				t.Errorf("Expected tools %v, got %v", tt.wantTools, got)
+			}
+			if len(shaped.Tools) == 0 && shaped.ToolChoice != nil {
+				t.Errorf("Expected tool choice to be dropped with no tools, got %v", shaped.ToolChoice)
+			}
+			if len(request.Tools) != 2 {
+				t.Error("Caller's request was modified")
+			}
+		})
+	}
+}
+
+func TestClient_SessionDisabledTools(t *testing.T) {
+	client := newShutdownClient(t)
+	mock := &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		responses:    []gomini.StreamEvent{{Type: gomini.EventFinished}},
+	}
+	useProvider(client, mock)
+
+	session := client.NewSession("read-only")
+	session.DisableTools("write_file")
+
+	request := &gomini.ChatRequest{
+		Model:         "test-model",
+		Messages:      []gomini.Message{gomini.NewUserMessage("Tidy up the notes")},
+		Tools:         []gomini.Tool{gomini.FunctionTool{Name: "read_file"}, gomini.FunctionTool{Name: "write_file"}, gomini.FunctionTool{Name: "delete_file"}},
+		DisabledTools: []string{"delete_file"},
+	}
+	for range client.SendSessionStream(context.Background(), session, request) {
+	}
+
+	if got := toolNames(t, mock.lastRequest.Tools); len(got) != 1 || got[0] != "read_file" {
+		t.Errorf("Expected only read_file to reach the provider, got %v", got)
+	}
+
+	restored := RestoreSession(session.Snapshot())
+	if restored.ToolEnabled("write_file") || !restored.ToolEnabled("read_file") {
+		t.Errorf("Expected disabled tools to survive a snapshot, got %v", restored.DisabledTools())
+	}
+	restored.EnableTools("write_file")
+	if len(restored.DisabledTools()) != 0 {
+		t.Errorf("Expected no disabled tools after EnableTools, got %v", restored.DisabledTools())
+	}
+}
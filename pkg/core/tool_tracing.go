@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gomini/pkg/gomini"
+)
+
+// toolTracerName is the instrumentation scope of tool call spans
+const toolTracerName = "gomini/pkg/core"
+
+// SetTracerProvider sets the OpenTelemetry provider of the spans RunTools
+// records for each tool call. Until it is set, the global provider is used.
+func (c *Client) SetTracerProvider(provider trace.TracerProvider) {
+	c.toolsMu.Lock()
+	defer c.toolsMu.Unlock()
+	c.tracerProvider = provider
+}
+
+// startToolSpan starts an "execute_tool" span for invocation, following the
+// OpenTelemetry GenAI conventions, as a child of the span in ctx. Tools get
+// the returned context, so spans they start nest below it.
+func (c *Client) startToolSpan(ctx context.Context, invocation ToolInvocation) (context.Context, trace.Span) {
+	c.toolsMu.RLock()
+	provider := c.tracerProvider
+	c.toolsMu.RUnlock()
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(toolTracerName).Start(ctx, "execute_tool "+invocation.Name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("gen_ai.operation.name", "execute_tool"),
+			attribute.String("gen_ai.tool.name", invocation.Name),
+			attribute.String("gen_ai.tool.call.id", invocation.ID),
+		),
+	)
+}
+
+// endToolSpan records the outcome of a tool call on its span and ends it.
+// Unsuccessful calls carry their error text as the span status.
+func endToolSpan(span trace.Span, response gomini.ToolResponseEvent) {
+	span.SetAttributes(
+		attribute.Bool("gomini.tool.success", response.Success),
+		attribute.Bool("gomini.tool.cached", response.Cached),
+		attribute.Int("gomini.tool.result_bytes", len(stringifyToolResult(response.Result))),
+	)
+	if !response.Success {
+		span.SetStatus(codes.Error, stringifyToolResult(response.Result))
+	}
+	span.End()
+}
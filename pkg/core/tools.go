@@ -0,0 +1,283 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"gomini/pkg/gomini"
+)
+
+// defaultToolWorkers bounds RunTools when Config.ToolWorkers is unset
+const defaultToolWorkers = 4
+
+// ToolFunc executes one tool call. Its result is sent back to the model as
+// is when it is a string, otherwise as JSON.
+type ToolFunc func(ctx context.Context, invocation ToolInvocation) (interface{}, error)
+
+// Risk levels of a registered tool, reported on ToolConfirmEvent.Risk
+const (
+	ToolRiskLow    = "low"
+	ToolRiskMedium = "medium"
+	ToolRiskHigh   = "high"
+)
+
+// registeredTool is a tool function with its RegisterTool options
+type registeredTool struct {
+	fn       ToolFunc
+	risk     string
+	confirm  bool
+	impact   string
+	cacheTTL time.Duration
+}
+
+// ToolOption configures a tool in RegisterTool
+type ToolOption func(*registeredTool)
+
+// WithToolRisk sets the tool's risk level, e.g. ToolRiskHigh
+func WithToolRisk(risk string) ToolOption {
+	return func(t *registeredTool) { t.risk = risk }
+}
+
+// WithToolConfirmation makes every call of the tool wait for
+// Client.ConfirmToolCall; impact describes what running it changes
+func WithToolConfirmation(impact string) ToolOption {
+	return func(t *registeredTool) {
+		t.confirm = true
+		t.impact = impact
+	}
+}
+
+// WithToolCacheTTL lets RunSessionTools reuse a successful result of the
+// tool for ttl when a session repeats a call with the same arguments; use it
+// for expensive, side-effect free tools such as HTTP fetches or queries
+func WithToolCacheTTL(ttl time.Duration) ToolOption {
+	return func(t *registeredTool) { t.cacheTTL = ttl }
+}
+
+// RegisterTool sets the function RunTools uses to execute the named tool,
+// replacing any earlier one
+func (c *Client) RegisterTool(name string, fn ToolFunc, opts ...ToolOption) {
+	tool := &registeredTool{fn: fn}
+	for _, opt := range opts {
+		opt(tool)
+	}
+
+	c.toolsMu.Lock()
+	defer c.toolsMu.Unlock()
+	if c.tools == nil {
+		c.tools = make(map[string]*registeredTool)
+	}
+	c.tools[name] = tool
+}
+
+// ConfirmToolCall approves or denies a tool call waiting for confirmation in
+// RunTools. It fails when no call with callID is waiting.
+func (c *Client) ConfirmToolCall(callID string, approved bool) error {
+	c.toolsMu.Lock()
+	decision, ok := c.confirmations[callID]
+	delete(c.confirmations, callID)
+	c.toolsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("no tool call %q is waiting for confirmation", callID)
+	}
+	decision <- approved
+	return nil
+}
+
+// awaitConfirmation publishes an EventToolConfirm for invocation and waits
+// for ConfirmToolCall or the end of ctx
+func (c *Client) awaitConfirmation(ctx context.Context, tool *registeredTool, invocation ToolInvocation) (bool, error) {
+	decision := make(chan bool, 1)
+	c.toolsMu.Lock()
+	if c.confirmations == nil {
+		c.confirmations = make(map[string]chan bool)
+	}
+	c.confirmations[invocation.ID] = decision
+	c.toolsMu.Unlock()
+	defer func() {
+		c.toolsMu.Lock()
+		delete(c.confirmations, invocation.ID)
+		c.toolsMu.Unlock()
+	}()
+
+	args, _ := json.Marshal(invocation.Arguments)
+	c.events.publish(gomini.NewToolConfirmEvent(c.currentState().providerType, gomini.ToolConfirmEvent{
+		CallID:      invocation.ID,
+		ToolName:    invocation.Name,
+		Arguments:   invocation.Arguments,
+		Description: fmt.Sprintf("Run %s with %s", invocation.Name, args),
+		Risk:        tool.risk,
+		Impact:      tool.impact,
+	}))
+
+	select {
+	case approved := <-decision:
+		return approved, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// RunTools executes the tool calls of one turn concurrently, at most
+// Config.ToolWorkers at a time, and returns their responses in call order
+// with the calls' IDs. A failed or unregistered tool yields an unsuccessful
+// response carrying the error text, so the model can see what went wrong.
+// Tools registered WithToolConfirmation first publish an EventToolConfirm
+// (see Subscribe) and run only once ConfirmToolCall approves them.
+func (c *Client) RunTools(ctx context.Context, invocations []ToolInvocation) []gomini.ToolResponseEvent {
+	return c.runTools(ctx, invocations, nil)
+}
+
+// RunSessionTools is RunTools within session: results of tools registered
+// WithToolCacheTTL are reused for identical calls of the session, flagged
+// Cached, and every response is recorded in the session as a tool message.
+func (c *Client) RunSessionTools(ctx context.Context, session *Session, invocations []ToolInvocation) []gomini.ToolResponseEvent {
+	responses := c.runTools(ctx, invocations, &session.toolResults)
+	for _, response := range responses {
+		session.RecordEvent(gomini.StreamEvent{
+			Type:      gomini.EventToolResponse,
+			Provider:  c.currentState().providerType,
+			Data:      response,
+			Timestamp: time.Now(),
+		})
+	}
+	return responses
+}
+
+// runTools executes invocations on a worker pool, consulting cache when set
+func (c *Client) runTools(ctx context.Context, invocations []ToolInvocation, cache *toolResultCache) []gomini.ToolResponseEvent {
+	responses := make([]gomini.ToolResponseEvent, len(invocations))
+	workers := c.currentState().config.ToolWorkers
+	if workers <= 0 {
+		workers = defaultToolWorkers
+	}
+	if workers > len(invocations) {
+		workers = len(invocations)
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				responses[i] = c.runTool(ctx, invocations[i], cache)
+			}
+		}()
+	}
+	for i := range invocations {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return responses
+}
+
+// runTool executes a single invocation, or answers it from cache, within a
+// tool span
+func (c *Client) runTool(ctx context.Context, invocation ToolInvocation, cache *toolResultCache) (response gomini.ToolResponseEvent) {
+	ctx, span := c.startToolSpan(ctx, invocation)
+	defer func() { endToolSpan(span, response) }()
+
+	response = gomini.ToolResponseEvent{CallID: invocation.ID, ToolName: invocation.Name}
+
+	c.toolsMu.RLock()
+	tool, ok := c.tools[invocation.Name]
+	c.toolsMu.RUnlock()
+	if !ok {
+		response.Result = fmt.Sprintf("unknown tool %q", invocation.Name)
+		return response
+	}
+	cacheable := cache != nil && tool.cacheTTL > 0
+	key := toolCallKey(invocation)
+	if cacheable {
+		if result, ok := cache.get(key); ok {
+			response.Result, response.Success, response.Cached = result, true, true
+			return response
+		}
+	}
+	if tool.confirm {
+		approved, err := c.awaitConfirmation(ctx, tool, invocation)
+		if err != nil {
+			response.Result = err.Error()
+			return response
+		}
+		if !approved {
+			response.Result = fmt.Sprintf("the user declined to run %s", invocation.Name)
+			return response
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		response.Result = err.Error()
+		return response
+	}
+
+	start := time.Now()
+	result, err := tool.fn(ctx, invocation)
+	response.Duration = time.Since(start)
+	if err != nil {
+		response.Result = err.Error()
+		return response
+	}
+	response.Result, response.Success = result, true
+	if cacheable {
+		cache.put(key, result, tool.cacheTTL)
+	}
+	return response
+}
+
+// toolResultCache holds the successful tool results of a session by call
+// signature. It is not part of session snapshots.
+type toolResultCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedToolResult
+}
+
+type cachedToolResult struct {
+	result  interface{}
+	expires time.Time
+}
+
+// toolCallKey identifies a call by tool name and arguments; map keys are
+// marshalled in sorted order, so equal arguments give equal keys
+func toolCallKey(invocation ToolInvocation) string {
+	args, _ := json.Marshal(invocation.Arguments)
+	return invocation.Name + "\x00" + string(args)
+}
+
+func (c *toolResultCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.result, true
+}
+
+func (c *toolResultCache) put(key string, result interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]cachedToolResult)
+	}
+	c.entries[key] = cachedToolResult{result: result, expires: time.Now().Add(ttl)}
+}
+
+// ToolResultMessages converts tool responses to the tool messages that
+// answer the calls in the next turn
+func ToolResultMessages(responses []gomini.ToolResponseEvent) []gomini.Message {
+	messages := make([]gomini.Message, len(responses))
+	for i, response := range responses {
+		messages[i] = gomini.NewToolResultMessage(response.CallID, response.ToolName, stringifyToolResult(response.Result))
+	}
+	return messages
+}
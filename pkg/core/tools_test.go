@@ -0,0 +1,223 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gomini/pkg/gomini"
+)
+
+func TestClient_RunTools(t *testing.T) {
+	client, _ := newGenerateTestClient(t, nil)
+	client.currentState().config.ToolWorkers = 2
+
+	var running, peak int32
+	client.RegisterTool("get_weather", func(ctx context.Context, invocation ToolInvocation) (interface{}, error) {
+		now := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			seen := atomic.LoadInt32(&peak)
+			if now <= seen || atomic.CompareAndSwapInt32(&peak, seen, now) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		city, _ := invocation.Arguments["city"].(string)
+		if city == "Atlantis" {
+			return nil, errors.New("city not found")
+		}
+		return map[string]interface{}{"city": city, "forecast": "rain"}, nil
+	})
+
+	cities := []string{"Taipei", "Tokyo", "Atlantis", "Paris", "Lima"}
+	invocations := make([]ToolInvocation, 0, len(cities)+1)
+	for i, city := range cities {
+		invocations = append(invocations, ToolInvocation{ID: fmt.Sprintf("call_%d", i), Name: "get_weather", Arguments: map[string]interface{}{"city": city}})
+	}
+	invocations = append(invocations, ToolInvocation{ID: "call_x", Name: "delete_files"})
+
+	responses := client.RunTools(context.Background(), invocations)
+	if len(responses) != len(invocations) {
+		t.Fatalf("expected %d responses, got %d", len(invocations), len(responses))
+	}
+	for i, response := range responses {
+		if response.CallID != invocations[i].ID || response.ToolName != invocations[i].Name {
+			t.Errorf("response %d out of order: %+v", i, response)
+		}
+	}
+	if !responses[0].Success || responses[0].Result.(map[string]interface{})["city"] != "Taipei" {
+		t.Errorf("unexpected response %+v", responses[0])
+	}
+	if responses[2].Success || responses[2].Result != "city not found" {
+		t.Errorf("expected the failure to be reported, got %+v", responses[2])
+	}
+	if responses[5].Success {
+		t.Errorf("expected an unregistered tool to fail, got %+v", responses[5])
+	}
+	if peak > 2 {
+		t.Errorf("expected at most 2 tools at once, saw %d", peak)
+	}
+
+	messages := ToolResultMessages(responses[:1])
+	message := messages[0].(map[string]interface{})
+	if message["tool_call_id"] != "call_0" || message["content"] != `{"city":"Taipei","forecast":"rain"}` {
+		t.Errorf("unexpected tool message %+v", message)
+	}
+}
+
+func TestClient_RunTools_Confirmation(t *testing.T) {
+	client, _ := newGenerateTestClient(t, nil)
+	var deleted []string
+	client.RegisterTool("delete_file", func(ctx context.Context, invocation ToolInvocation) (interface{}, error) {
+		deleted = append(deleted, invocation.Arguments["path"].(string))
+		return "deleted", nil
+	}, WithToolRisk(ToolRiskHigh), WithToolConfirmation("removes the file from disk"))
+
+	confirms, cancel := client.Subscribe(gomini.EventToolConfirm)
+	defer cancel()
+	go func() {
+		for event := range confirms {
+			confirm := event.Data.(gomini.ToolConfirmEvent)
+			if confirm.Risk != ToolRiskHigh || confirm.Impact != "removes the file from disk" {
+				t.Errorf("unexpected confirmation %+v", confirm)
+			}
+			client.ConfirmToolCall(confirm.CallID, confirm.Arguments["path"] == "tmp.txt")
+		}
+	}()
+
+	responses := client.RunTools(context.Background(), []ToolInvocation{
+		{ID: "call_1", Name: "delete_file", Arguments: map[string]interface{}{"path": "tmp.txt"}},
+		{ID: "call_2", Name: "delete_file", Arguments: map[string]interface{}{"path": "main.go"}},
+	})
+	if !responses[0].Success || responses[1].Success {
+		t.Errorf("expected only the approved call to run, got %+v", responses)
+	}
+	if len(deleted) != 1 || deleted[0] != "tmp.txt" {
+		t.Errorf("expected only tmp.txt deleted, got %v", deleted)
+	}
+
+	if err := client.ConfirmToolCall("call_1", true); err == nil {
+		t.Error("expected an error confirming a call that is not waiting")
+	}
+
+	// An unanswered confirmation gives up with the context
+	ctx, stop := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer stop()
+	cancel()
+	responses = client.RunTools(ctx, []ToolInvocation{{ID: "call_3", Name: "delete_file", Arguments: map[string]interface{}{"path": "tmp.txt"}}})
+	if responses[0].Success || len(deleted) != 1 {
+		t.Errorf("expected the unconfirmed call not to run, got %+v", responses[0])
+	}
+}
+
+func TestClient_RunSessionTools_Cache(t *testing.T) {
+	client, _ := newGenerateTestClient(t, nil)
+	var fetches, queries int32
+	client.RegisterTool("fetch", func(ctx context.Context, invocation ToolInvocation) (interface{}, error) {
+		atomic.AddInt32(&fetches, 1)
+		return "<html>", nil
+	}, WithToolCacheTTL(time.Minute))
+	client.RegisterTool("query", func(ctx context.Context, invocation ToolInvocation) (interface{}, error) {
+		atomic.AddInt32(&queries, 1)
+		return 42, nil
+	}, WithToolCacheTTL(time.Millisecond))
+	client.RegisterTool("now", func(ctx context.Context, invocation ToolInvocation) (interface{}, error) {
+		return time.Now().String(), nil
+	})
+
+	session := client.NewSession("")
+	fetch := ToolInvocation{ID: "call_1", Name: "fetch", Arguments: map[string]interface{}{"url": "https://a.example", "depth": 1}}
+	client.RunSessionTools(context.Background(), session, []ToolInvocation{fetch})
+
+	// Argument order does not matter, but the values do
+	same := ToolInvocation{ID: "call_2", Name: "fetch", Arguments: map[string]interface{}{"depth": 1, "url": "https://a.example"}}
+	other := ToolInvocation{ID: "call_3", Name: "fetch", Arguments: map[string]interface{}{"url": "https://b.example", "depth": 1}}
+	responses := client.RunSessionTools(context.Background(), session, []ToolInvocation{same, other, {ID: "call_4", Name: "now"}})
+	if !responses[0].Cached || responses[0].CallID != "call_2" || responses[0].Result != "<html>" {
+		t.Errorf("expected a cached result for the repeated call, got %+v", responses[0])
+	}
+	if responses[1].Cached || responses[2].Cached || fetches != 2 {
+		t.Errorf("expected new arguments and uncached tools to run, got %+v after %d fetches", responses, fetches)
+	}
+
+	// Results expire with the tool's TTL
+	query := ToolInvocation{ID: "call_5", Name: "query", Arguments: map[string]interface{}{"sql": "select 42"}}
+	client.RunSessionTools(context.Background(), session, []ToolInvocation{query})
+	time.Sleep(5 * time.Millisecond)
+	if responses := client.RunSessionTools(context.Background(), session, []ToolInvocation{query}); responses[0].Cached || queries != 2 {
+		t.Errorf("expected the expired result to be recomputed, got %+v", responses[0])
+	}
+
+	// The cache belongs to the session, and RunTools skips it
+	if responses := client.RunSessionTools(context.Background(), client.NewSession(""), []ToolInvocation{fetch}); responses[0].Cached {
+		t.Error("expected another session not to share the cache")
+	}
+	if responses := client.RunTools(context.Background(), []ToolInvocation{fetch}); responses[0].Cached {
+		t.Error("expected RunTools not to use a cache")
+	}
+
+	if history := session.History(); len(history) != 6 {
+		t.Errorf("expected six tool messages in the session, got %d", len(history))
+	}
+}
+
+func TestClient_RunTools_Spans(t *testing.T) {
+	client, _ := newGenerateTestClient(t, nil)
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client.SetTracerProvider(tracerProvider)
+
+	client.RegisterTool("lookup", func(ctx context.Context, invocation ToolInvocation) (interface{}, error) {
+		_, child := tracerProvider.Tracer("test").Start(ctx, "http get")
+		child.End()
+		if invocation.Arguments["fail"] == true {
+			return nil, errors.New("not found")
+		}
+		return "ok", nil
+	})
+
+	ctx, parent := tracerProvider.Tracer("test").Start(context.Background(), "agent turn")
+	client.RunTools(ctx, []ToolInvocation{
+		{ID: "call_1", Name: "lookup"},
+		{ID: "call_2", Name: "lookup", Arguments: map[string]interface{}{"fail": true}},
+	})
+	parent.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	var children int
+	for _, span := range recorder.Ended() {
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, kv := range span.Attributes() {
+			attrs[kv.Key] = kv.Value
+		}
+		switch span.Name() {
+		case "execute_tool lookup":
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("Expected tool spans to be children of the caller's span")
+			}
+			if attrs["gen_ai.operation.name"].AsString() != "execute_tool" || attrs["gen_ai.tool.name"].AsString() != "lookup" {
+				t.Errorf("Expected GenAI attributes, got %v", span.Attributes())
+			}
+			spans[attrs["gen_ai.tool.call.id"].AsString()] = span
+		case "http get":
+			children++
+		}
+	}
+	if len(spans) != 2 || children != 2 {
+		t.Fatalf("Expected 2 tool spans with a child each, got %d and %d", len(spans), children)
+	}
+	if status := spans["call_1"].Status(); status.Code != codes.Unset {
+		t.Errorf("Expected the successful call to leave the status unset, got %+v", status)
+	}
+	if status := spans["call_2"].Status(); status.Code != codes.Error || status.Description != "not found" {
+		t.Errorf("Expected the failed call to carry its error, got %+v", status)
+	}
+}
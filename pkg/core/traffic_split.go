@@ -0,0 +1,65 @@
+package core
+
+import (
+	"hash/fnv"
+
+	"gomini/pkg/gomini"
+)
+
+// TagSplitVariant is the request tag naming the traffic split variant
+// (provider/model) a request was assigned to, for comparing variants by usage
+const TagSplitVariant = "split_variant"
+
+// splitVariant picks the variant for key from weighted splits. The same key
+// always maps to the same variant while the splits are unchanged.
+func splitVariant(splits []gomini.TrafficSplit, key string) (gomini.TrafficSplit, bool) {
+	total := 0
+	for _, split := range splits {
+		if split.Weight > 0 {
+			total += split.Weight
+		}
+	}
+	if total == 0 {
+		return gomini.TrafficSplit{}, false
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	bucket := int(hash.Sum32() % uint32(total))
+	for _, split := range splits {
+		if split.Weight <= 0 {
+			continue
+		}
+		if bucket < split.Weight {
+			return split, true
+		}
+		bucket -= split.Weight
+	}
+	return gomini.TrafficSplit{}, false
+}
+
+// applyTrafficSplit assigns the request to a variant of its model's traffic
+// split, keyed by prompt ID. The variant is recorded in the split_variant tag.
+// The caller's request is not modified.
+func (c *Client) applyTrafficSplit(st *clientState, request *gomini.ChatRequest, promptID string) *gomini.ChatRequest {
+	router := st.config.Router
+	if router == nil || len(router.TrafficSplits) == 0 {
+		return request
+	}
+	variant, ok := splitVariant(router.TrafficSplits[request.Model], promptID)
+	if !ok {
+		return request
+	}
+
+	split := *request
+	split.Model = variant.Model
+	if variant.Provider != "" {
+		split.Provider = variant.Provider
+	}
+	ref := ModelRef{Provider: split.Provider, Model: split.Model}
+	if ref.Provider == "" {
+		ref.Provider = st.providerType
+	}
+	split.Tags = mergeTags(request.Tags, map[string]string{TagSplitVariant: ref.String()})
+	return &split
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestSplitVariant(t *testing.T) {
+	splits := []gomini.TrafficSplit{
+		{Model: "gpt-4o-mini", Weight: 90},
+		{Provider: providers.ProviderGemini, Model: "gemini-2.0-flash", Weight: 10},
+		{Model: "disabled", Weight: 0},
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("prompt-%d", i)
+		variant, ok := splitVariant(splits, key)
+		if !ok {
+			t.Fatalf("expected a variant for %s", key)
+		}
+		again, _ := splitVariant(splits, key)
+		if again != variant {
+			t.Fatalf("expected %s to map to the same variant, got %+v and %+v", key, variant, again)
+		}
+		counts[variant.Model]++
+	}
+
+	if counts["disabled"] != 0 {
+		t.Errorf("expected zero-weight variant to get no traffic, got %d", counts["disabled"])
+	}
+	if share := counts["gemini-2.0-flash"]; share < 800 || share > 1200 {
+		t.Errorf("expected about 10%% of traffic on gemini-2.0-flash, got %d of 10000", share)
+	}
+
+	if _, ok := splitVariant([]gomini.TrafficSplit{{Model: "x", Weight: 0}}, "key"); ok {
+		t.Error("expected no variant without positive weights")
+	}
+}
+
+func TestClient_TrafficSplitStream(t *testing.T) {
+	client := newShutdownClient(t)
+	client.currentState().config.Router = &gomini.RouterConfig{
+		TrafficSplits: map[string][]gomini.TrafficSplit{
+			"gpt-4o-mini": {{Model: "variant-a", Weight: 50}, {Model: "variant-b", Weight: 50}},
+		},
+	}
+	mockProvider := &MockProvider{
+		providerType: providers.ProviderOpenAI,
+		responses:    []gomini.StreamEvent{{Type: gomini.EventFinished}},
+	}
+	useProvider(client, mockProvider)
+
+	request := &gomini.ChatRequest{
+		Model:    "gpt-4o-mini",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	}
+	expected, _ := splitVariant(client.currentState().config.Router.TrafficSplits["gpt-4o-mini"], "prompt-1")
+
+	// Every turn of a prompt lands on the same variant
+	for turn := 0; turn < 3; turn++ {
+		for range client.SendMessageStream(context.Background(), request, "prompt-1") {
+		}
+		if mockProvider.lastRequest.Model != expected.Model {
+			t.Fatalf("turn %d: expected %s, got %s", turn, expected.Model, mockProvider.lastRequest.Model)
+		}
+	}
+	if tag := mockProvider.lastRequest.Tags[TagSplitVariant]; tag != "openai/"+expected.Model {
+		t.Errorf("expected split_variant tag openai/%s, got %q", expected.Model, tag)
+	}
+	if request.Model != "gpt-4o-mini" || request.Tags != nil {
+		t.Errorf("expected caller's request to be unchanged, got %+v", request)
+	}
+
+	state, ok := client.PromptState("prompt-1")
+	if !ok || state.Variant != "openai/"+expected.Model || state.Turns != 3 {
+		t.Errorf("expected prompt state for variant %s over 3 turns, got %+v", expected.Model, state)
+	}
+
+	// Models without a split are untouched
+	for range client.SendMessageStream(context.Background(), &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("hi")},
+	}, "prompt-2") {
+	}
+	if mockProvider.lastRequest.Model != "gpt-4o" || mockProvider.lastRequest.Tags[TagSplitVariant] != "" {
+		t.Errorf("expected unsplit request, got %+v", mockProvider.lastRequest)
+	}
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gomini/pkg/gomini"
+)
+
+// Transcript format identifiers. Readers accept any version up to
+// TranscriptVersion and reject newer ones.
+const (
+	TranscriptFormat  = "gomini.transcript"
+	TranscriptVersion = 1
+)
+
+// Transcript is the portable, versioned form of a conversation written by
+// Session.Export. Messages are provider-agnostic, so a transcript recorded
+// on one provider can be replayed on another.
+type Transcript struct {
+	Format    string                 `json:"format"`
+	Version   int                    `json:"version"`
+	ID        string                 `json:"id"`
+	TurnCount int                    `json:"turn_count,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Messages  []TranscriptMessage    `json:"messages"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// TranscriptMessage is one message of a Transcript. Provider, Model and Usage
+// are set on assistant messages recorded from a stream.
+type TranscriptMessage struct {
+	Role       string              `json:"role"`
+	Content    interface{}         `json:"content,omitempty"` // Text, or content parts
+	ToolCalls  []gomini.ToolCall   `json:"tool_calls,omitempty"`
+	ToolCallID string              `json:"tool_call_id,omitempty"` // Tool results: the call answered
+	Name       string              `json:"name,omitempty"`         // Tool results: the tool name
+	Provider   gomini.ProviderType `json:"provider,omitempty"`
+	Model      string              `json:"model,omitempty"`
+	Usage      *gomini.Usage       `json:"usage,omitempty"`
+}
+
+// Transcript returns the session in the portable transcript format
+func (s *Session) Transcript() (*Transcript, error) {
+	data := s.Snapshot()
+	transcript := &Transcript{
+		Format:    TranscriptFormat,
+		Version:   TranscriptVersion,
+		ID:        data.ID,
+		TurnCount: data.TurnCount,
+		Metadata:  data.Metadata,
+		Messages:  make([]TranscriptMessage, 0, len(data.Messages)),
+		CreatedAt: data.CreatedAt,
+		UpdatedAt: data.UpdatedAt,
+	}
+	if len(transcript.Metadata) == 0 {
+		transcript.Metadata = nil
+	}
+
+	for i, message := range data.Messages {
+		msgMap, err := messageMap(message)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		entry := TranscriptMessage{
+			Content:   msgMap["content"],
+			ToolCalls: messageToolCalls(msgMap),
+		}
+		entry.Role, _ = msgMap["role"].(string)
+		entry.ToolCallID, _ = msgMap["tool_call_id"].(string)
+		entry.Name, _ = msgMap["name"].(string)
+		if info, ok := data.MessageInfo[i]; ok {
+			entry.Provider, entry.Model, entry.Usage = info.Provider, info.Model, info.Usage
+		}
+		transcript.Messages = append(transcript.Messages, entry)
+	}
+	return transcript, nil
+}
+
+// Export writes the session to w as an indented JSON transcript
+func (s *Session) Export(w io.Writer) error {
+	transcript, err := s.Transcript()
+	if err != nil {
+		return fmt.Errorf("failed to export session %s: %w", s.ID(), err)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(transcript); err != nil {
+		return fmt.Errorf("failed to export session %s: %w", s.ID(), err)
+	}
+	return nil
+}
+
+// ImportSession reads a transcript written by Session.Export and rebuilds the
+// session. The session has no turn limit or budget; set them on the result.
+func ImportSession(r io.Reader) (*Session, error) {
+	var transcript Transcript
+	if err := json.NewDecoder(r).Decode(&transcript); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return SessionFromTranscript(&transcript)
+}
+
+// SessionFromTranscript rebuilds a session from a transcript
+func SessionFromTranscript(transcript *Transcript) (*Session, error) {
+	if transcript.Format != TranscriptFormat {
+		return nil, fmt.Errorf("unknown transcript format %q", transcript.Format)
+	}
+	if transcript.Version < 1 || transcript.Version > TranscriptVersion {
+		return nil, fmt.Errorf("unsupported transcript version %d (supported: 1-%d)", transcript.Version, TranscriptVersion)
+	}
+
+	data := &SessionData{
+		ID:        transcript.ID,
+		TurnCount: transcript.TurnCount,
+		Metadata:  transcript.Metadata,
+		Messages:  make([]gomini.Message, 0, len(transcript.Messages)),
+		CreatedAt: transcript.CreatedAt,
+		UpdatedAt: transcript.UpdatedAt,
+	}
+	for i, entry := range transcript.Messages {
+		if entry.Role == "" {
+			return nil, fmt.Errorf("transcript message %d has no role", i)
+		}
+		message := map[string]interface{}{"role": entry.Role, "content": entry.Content}
+		if entry.Content == nil {
+			message["content"] = ""
+		}
+		if len(entry.ToolCalls) > 0 {
+			message["tool_calls"] = entry.ToolCalls
+		}
+		if entry.ToolCallID != "" {
+			message["tool_call_id"] = entry.ToolCallID
+		}
+		if entry.Name != "" {
+			message["name"] = entry.Name
+		}
+		data.Messages = append(data.Messages, message)
+
+		if entry.Provider != "" || entry.Model != "" || entry.Usage != nil {
+			if data.MessageInfo == nil {
+				data.MessageInfo = make(map[int]MessageInfo)
+			}
+			data.MessageInfo[i] = MessageInfo{Provider: entry.Provider, Model: entry.Model, Usage: entry.Usage}
+		}
+	}
+	return RestoreSession(data), nil
+}
+
+// messageMap returns a message as a map, converting typed messages through JSON
+func messageMap(message gomini.Message) (map[string]interface{}, error) {
+	if msgMap, ok := message.(map[string]interface{}); ok {
+		return msgMap, nil
+	}
+	raw, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	var msgMap map[string]interface{}
+	if err := json.Unmarshal(raw, &msgMap); err != nil {
+		return nil, fmt.Errorf("unsupported message type %T", message)
+	}
+	return msgMap, nil
+}
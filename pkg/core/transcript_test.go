@@ -0,0 +1,106 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestSession_ExportImport(t *testing.T) {
+	session := NewSession("session-1", 0)
+	session.SetMetadata("user", "alice")
+	session.AddUserMessage("What's the weather?")
+	if err := session.BeginTurn(); err != nil {
+		t.Fatalf("BeginTurn failed: %v", err)
+	}
+
+	usage := &gomini.Usage{InputTokens: 12, OutputTokens: 8, TotalTokens: 20}
+	events := []gomini.StreamEvent{
+		{Type: gomini.EventToolCall, Provider: providers.ProviderOpenAI, Model: "gpt-4o", Data: gomini.ToolCallEvent{
+			CallID:    "call-1",
+			ToolName:  "get_weather",
+			Arguments: map[string]interface{}{"city": "Taipei"},
+		}},
+		{Type: gomini.EventToolResponse, Data: gomini.ToolResponseEvent{
+			CallID:   "call-1",
+			ToolName: "get_weather",
+			Result:   map[string]interface{}{"temp": 30},
+			Success:  true,
+		}},
+		{Type: gomini.EventContent, Provider: providers.ProviderOpenAI, Model: "gpt-4o", Data: gomini.ContentEvent{Text: "It's 30 degrees."}},
+		{Type: gomini.EventFinished, Metadata: gomini.EventMeta{Usage: usage}},
+	}
+	for _, event := range events {
+		session.RecordEvent(event)
+	}
+
+	var buf bytes.Buffer
+	if err := session.Export(&buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"format": "gomini.transcript"`) {
+		t.Errorf("Expected transcript format marker, got %s", buf.String())
+	}
+
+	imported, err := ImportSession(&buf)
+	if err != nil {
+		t.Fatalf("ImportSession failed: %v", err)
+	}
+	if imported.ID() != "session-1" || imported.TurnCount() != 1 {
+		t.Errorf("Unexpected session: id %s, turns %d", imported.ID(), imported.TurnCount())
+	}
+	if user, _ := imported.GetMetadata("user"); user != "alice" {
+		t.Errorf("Expected metadata to round-trip, got %v", user)
+	}
+
+	history := imported.History()
+	if len(history) != 4 {
+		t.Fatalf("Expected 4 messages, got %d: %v", len(history), history)
+	}
+	calls := messageToolCalls(history[1].(map[string]interface{}))
+	if len(calls) != 1 || calls[0].ID != "call-1" || calls[0].Arguments["city"] != "Taipei" {
+		t.Errorf("Unexpected tool calls: %v", calls)
+	}
+	toolResult := history[2].(map[string]interface{})
+	if toolResult["role"] != "tool" || toolResult["tool_call_id"] != "call-1" || toolResult["content"] != `{"temp":30}` {
+		t.Errorf("Unexpected tool result: %v", toolResult)
+	}
+	if final := history[3].(map[string]interface{}); final["content"] != "It's 30 degrees." {
+		t.Errorf("Unexpected final message: %v", final)
+	}
+
+	info, ok := imported.MessageInfo(3)
+	if !ok || info.Provider != providers.ProviderOpenAI || info.Model != "gpt-4o" {
+		t.Errorf("Expected provider and model on the final message, got %+v", info)
+	}
+	if info.Usage == nil || info.Usage.TotalTokens != 20 {
+		t.Errorf("Expected usage on the final message, got %+v", info.Usage)
+	}
+	if _, ok := imported.MessageInfo(0); ok {
+		t.Error("Expected no info on the user message")
+	}
+}
+
+func TestImportSession_Rejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"invalid JSON", `{`},
+		{"unknown format", `{"format":"other","version":1,"messages":[]}`},
+		{"missing version", `{"format":"gomini.transcript","messages":[]}`},
+		{"newer version", `{"format":"gomini.transcript","version":99,"messages":[]}`},
+		{"message without role", `{"format":"gomini.transcript","version":1,"messages":[{"content":"hi"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ImportSession(strings.NewReader(tt.input)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+	"gomini/pkg/gomini/providers/vcr"
+)
+
+// CassettePath returns the cassette file used for a provider under Config.VCR
+func CassettePath(dir string, providerType providers.ProviderType) string {
+	return filepath.Join(dir, string(providerType)+".json")
+}
+
+// newVCRProvider wraps the provider in a recorder, or replaces it with a
+// player, according to Config.VCR. Providers created for the same cassette
+// share it, so fallbacks and temporary providers land in one file.
+func (c *Client) newVCRProvider(config *gomini.Config, providerType providers.ProviderType) (providers.LLMProvider, error) {
+	path := CassettePath(config.VCR.Dir, providerType)
+
+	if config.VCR.Mode == gomini.VCRReplay {
+		providerConfig, err := config.GetProviderConfig(providerType)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s not found in config: %w", providerType, err)
+		}
+		if !providerConfig.Enabled {
+			return nil, fmt.Errorf("provider %s is not enabled", providerType)
+		}
+		cassette, err := c.cassette(config.VCR.Mode, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize %s replay: %w", providerType, err)
+		}
+		return providers.ExposeFeatures(vcr.NewPlayer(cassette), cassette.Features), nil
+	}
+
+	provider, err := c.buildProvider(config, providerType)
+	if err != nil {
+		return nil, err
+	}
+	cassette, err := c.cassette(config.VCR.Mode, path)
+	if err != nil {
+		provider.Close()
+		return nil, err
+	}
+	return providers.Expose(vcr.NewRecorder(provider, cassette), provider), nil
+}
+
+// cassette returns the shared cassette for path, loading it when replaying
+func (c *Client) cassette(mode gomini.VCRMode, path string) (*vcr.Cassette, error) {
+	c.vcrMu.Lock()
+	defer c.vcrMu.Unlock()
+
+	key := string(mode) + ":" + path
+	if cassette, ok := c.cassettes[key]; ok {
+		return cassette, nil
+	}
+
+	cassette := vcr.NewCassette(path)
+	if mode == gomini.VCRReplay {
+		loaded, err := vcr.LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		cassette = loaded
+	}
+	if c.cassettes == nil {
+		c.cassettes = make(map[string]*vcr.Cassette)
+	}
+	c.cassettes[key] = cassette
+	return cassette, nil
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestClient_VCRRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	request := &gomini.ChatRequest{
+		Model:    "gpt-4o",
+		Messages: []gomini.Message{gomini.NewUserMessage("Hello")},
+	}
+
+	recordConfig := gomini.NewConfig()
+	recordConfig.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key"}
+	recordConfig.VCR = &gomini.VCRConfig{Mode: gomini.VCRRecord, Dir: dir}
+	recorder, err := NewClient(recordConfig)
+	if err != nil {
+		t.Fatalf("Failed to create recording client: %v", err)
+	}
+	recorder.providerFactory = func(providerType providers.ProviderType) (providers.LLMProvider, error) {
+		return &MockProvider{providerType: providerType}, nil
+	}
+	if err := rebuildProviders(recorder); err != nil {
+		t.Fatalf("rebuildProviders failed: %v", err)
+	}
+	if _, err := recorder.SendMessage(context.Background(), request); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Replay needs neither an API key nor the original provider
+	replayConfig := gomini.NewConfig()
+	replayConfig.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{Enabled: true}
+	replayConfig.VCR = &gomini.VCRConfig{Mode: gomini.VCRReplay, Dir: dir}
+	player, err := NewClient(replayConfig)
+	if err != nil {
+		t.Fatalf("Failed to create replaying client: %v", err)
+	}
+	defer player.Close()
+
+	resp, err := player.SendMessage(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Replayed SendMessage failed: %v", err)
+	}
+	if text, _, _ := responseText(resp); text != "Mock response" {
+		t.Errorf("Expected the recorded response, got %q", text)
+	}
+
+	unrecorded := &gomini.ChatRequest{Model: "gpt-4o", Messages: []gomini.Message{gomini.NewUserMessage("Goodbye")}}
+	if _, err := player.SendMessage(context.Background(), unrecorded); err == nil {
+		t.Error("Expected an error for an unrecorded request")
+	}
+}
+
+func TestConfig_ValidateVCR(t *testing.T) {
+	tests := []struct {
+		name    string
+		vcr     *gomini.VCRConfig
+		wantErr bool
+	}{
+		{"record", &gomini.VCRConfig{Mode: gomini.VCRRecord, Dir: "cassettes"}, false},
+		{"unknown mode", &gomini.VCRConfig{Mode: "rewind", Dir: "cassettes"}, true},
+		{"missing dir", &gomini.VCRConfig{Mode: gomini.VCRReplay}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := gomini.NewConfig()
+			config.Providers[providers.ProviderOpenAI] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key"}
+			config.VCR = tt.vcr
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package gomini
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// audioExtensions covers common audio types missing from the system MIME table
+var audioExtensions = map[string]string{
+	".wav":  "audio/wav",
+	".mp3":  "audio/mpeg",
+	".flac": "audio/flac",
+	".ogg":  "audio/ogg",
+	".aac":  "audio/aac",
+	".aiff": "audio/aiff",
+	".m4a":  "audio/mp4",
+}
+
+// NewAudioMessage creates a user message with optional text and an audio clip
+func NewAudioMessage(text string, audio AudioPart) Message {
+	parts := make([]interface{}, 0, 2)
+	if text != "" {
+		parts = append(parts, NewTextPart(text))
+	}
+	parts = append(parts, audio)
+
+	return map[string]interface{}{
+		"role":    "user",
+		"content": parts,
+	}
+}
+
+// NewAudioMessageFromFile creates a user message with text and audio loaded from
+// path; the MIME type is taken from the file extension
+func NewAudioMessageFromFile(text, path string) (Message, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	mimeType, _, _ := mime.ParseMediaType(mime.TypeByExtension(ext))
+	if mimeType == "" {
+		mimeType = audioExtensions[ext]
+	}
+	if mimeType == "" {
+		return nil, fmt.Errorf("cannot determine audio type of %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+	return NewAudioMessage(text, AudioPart{MIMEType: mimeType, Data: data}), nil
+}
@@ -0,0 +1,34 @@
+package gomini
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewAudioMessageFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "clip.wav")
+	if err := os.WriteFile(path, []byte("RIFF....WAVE"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := NewAudioMessageFromFile("transcribe this", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parts := msg.(map[string]interface{})["content"].([]interface{})
+	if len(parts) != 2 {
+		t.Fatalf("expected text and audio parts, got %v", parts)
+	}
+	audio, ok := parts[1].(AudioPart)
+	if !ok {
+		t.Fatalf("expected AudioPart, got %T", parts[1])
+	}
+	if audio.MIMEType != "audio/wav" || string(audio.Data) != "RIFF....WAVE" {
+		t.Errorf("unexpected audio part %+v", audio)
+	}
+
+	if _, err := NewAudioMessageFromFile("", filepath.Join(t.TempDir(), "clip.unknownext")); err == nil {
+		t.Error("expected error for unknown audio type")
+	}
+}
@@ -0,0 +1,194 @@
+package gomini
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// StreamCollector accumulates stream events into a ChatResponse. Use Collect
+// to drain a whole stream, or Add events one at a time while forwarding them.
+type StreamCollector struct {
+	choices     map[int]*collectedChoice
+	usage       *Usage
+	safety      *SafetyInfo
+	fingerprint string
+	model       string
+	provider    ProviderType
+	id          string
+	finished    bool
+	err         error
+}
+
+// collectedChoice is the state of one choice index
+type collectedChoice struct {
+	text      strings.Builder
+	toolCalls []ToolCall
+	citations []providers.Citation
+	logprobs  []TokenLogprob
+	reason    FinishReason
+}
+
+// NewStreamCollector creates an empty collector
+func NewStreamCollector() *StreamCollector {
+	return &StreamCollector{choices: make(map[int]*collectedChoice)}
+}
+
+// choice returns the state of a choice index, creating it if needed
+func (c *StreamCollector) choice(index int) *collectedChoice {
+	choice, ok := c.choices[index]
+	if !ok {
+		choice = &collectedChoice{}
+		c.choices[index] = choice
+	}
+	return choice
+}
+
+// Add folds one event into the response. Content and its log probabilities
+// are concatenated per choice, tool call events sharing a call ID (or without
+// one, continuing the previous call) are merged, citations are gathered onto
+// the message, and usage and finish reason are taken from the stream's final
+// events. The first error event is kept.
+func (c *StreamCollector) Add(event StreamEvent) {
+	if event.Model != "" {
+		c.model = event.Model
+	}
+	if event.Provider != "" {
+		c.provider = event.Provider
+	}
+	if c.id == "" {
+		c.id = event.RequestID
+	}
+
+	switch event.Type {
+	case EventContent:
+		if content, ok := event.Data.(ContentEvent); ok {
+			choice := c.choice(event.Metadata.ChoiceIndex)
+			choice.text.WriteString(content.Text)
+			choice.logprobs = append(choice.logprobs, content.Logprobs...)
+		}
+	case EventToolCall:
+		if call, ok := event.Data.(ToolCallEvent); ok {
+			c.choice(event.Metadata.ChoiceIndex).addToolCall(call)
+		}
+	case EventCitation:
+		if data, ok := event.Data.(CitationEvent); ok {
+			choice := c.choice(event.Metadata.ChoiceIndex)
+			for _, source := range data.Sources {
+				choice.citations = append(choice.citations, providers.Citation{Title: source.Title, URI: source.URI, Spans: source.Spans})
+			}
+		}
+	case EventUsage:
+		if data, ok := event.Data.(UsageEvent); ok && data.Usage != nil {
+			c.usage = data.Usage
+		}
+	case EventFinished:
+		c.finished = true
+		c.choice(event.Metadata.ChoiceIndex).reason = event.Metadata.FinishReason
+		if event.Metadata.Usage != nil {
+			c.usage = event.Metadata.Usage
+		}
+		if event.Metadata.Safety != nil {
+			c.safety = event.Metadata.Safety
+		}
+		if event.Metadata.SystemFingerprint != "" {
+			c.fingerprint = event.Metadata.SystemFingerprint
+		}
+	case EventError:
+		if c.err == nil {
+			c.err = event.Error
+			if c.err == nil {
+				c.err = fmt.Errorf("stream reported an error")
+			}
+		}
+	}
+}
+
+// addToolCall merges a tool call event into the choice's calls
+func (c *collectedChoice) addToolCall(event ToolCallEvent) {
+	var call *ToolCall
+	if n := len(c.toolCalls); n > 0 && (event.CallID == "" || event.CallID == c.toolCalls[n-1].ID) {
+		call = &c.toolCalls[n-1]
+	} else {
+		for i := range c.toolCalls {
+			if event.CallID != "" && c.toolCalls[i].ID == event.CallID {
+				call = &c.toolCalls[i]
+				break
+			}
+		}
+	}
+	if call == nil {
+		c.toolCalls = append(c.toolCalls, ToolCall{ID: event.CallID})
+		call = &c.toolCalls[len(c.toolCalls)-1]
+	}
+
+	if call.Name == "" {
+		call.Name = event.ToolName
+	}
+	if len(event.Arguments) > 0 && call.Arguments == nil {
+		call.Arguments = make(map[string]interface{}, len(event.Arguments))
+	}
+	for key, value := range event.Arguments {
+		call.Arguments[key] = value
+	}
+}
+
+// Finished reports whether a finished event was seen
+func (c *StreamCollector) Finished() bool {
+	return c.finished
+}
+
+// Err returns the first error reported by the stream
+func (c *StreamCollector) Err() error {
+	return c.err
+}
+
+// Response returns the response assembled so far
+func (c *StreamCollector) Response() *ChatResponse {
+	indices := make([]int, 0, len(c.choices))
+	for index := range c.choices {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	resp := &ChatResponse{
+		ID:                c.id,
+		Model:             c.model,
+		Provider:          c.provider,
+		Choices:           make([]Choice, 0, len(indices)),
+		Usage:             c.usage,
+		Created:           time.Now().Unix(),
+		Safety:            c.safety,
+		SystemFingerprint: c.fingerprint,
+	}
+	for _, index := range indices {
+		choice := c.choices[index]
+		message := NewAssistantMessage(choice.text.String())
+		if len(choice.toolCalls) > 0 {
+			message = NewAssistantToolCallMessage(choice.text.String(), choice.toolCalls)
+		}
+		if len(choice.citations) > 0 {
+			message.(map[string]interface{})["citations"] = choice.citations
+		}
+		resp.Choices = append(resp.Choices, Choice{
+			Index:        index,
+			Message:      message,
+			FinishReason: choice.reason,
+			Logprobs:     choice.logprobs,
+		})
+	}
+	return resp
+}
+
+// Collect drains stream and assembles the full response. If the stream
+// reports an error, the partial response is returned along with it.
+func Collect(stream <-chan StreamEvent) (*ChatResponse, error) {
+	collector := NewStreamCollector()
+	for event := range stream {
+		collector.Add(event)
+	}
+	return collector.Response(), collector.Err()
+}
@@ -0,0 +1,133 @@
+package gomini
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// streamOf returns a closed channel carrying events
+func streamOf(events ...StreamEvent) <-chan StreamEvent {
+	stream := make(chan StreamEvent, len(events))
+	for _, event := range events {
+		stream <- event
+	}
+	close(stream)
+	return stream
+}
+
+// collectedMessage returns the message of a collected choice
+func collectedMessage(t *testing.T, resp *ChatResponse, i int) map[string]interface{} {
+	t.Helper()
+	message, ok := resp.Choices[i].Message.(map[string]interface{})
+	if !ok {
+		t.Fatalf("unexpected message %+v", resp.Choices[i].Message)
+	}
+	return message
+}
+
+func TestCollect_Content(t *testing.T) {
+	usage := &Usage{InputTokens: 4, OutputTokens: 2, TotalTokens: 6}
+	finished := NewFinishedEvent(ProviderOpenAI, "gpt-4o", providers.FinishReasonStop, usage)
+	finished.Metadata.SystemFingerprint = "fp_1"
+	resp, err := Collect(streamOf(
+		StreamEvent{Type: EventContent, Provider: ProviderOpenAI, Model: "gpt-4o", RequestID: "req-1", Data: ContentEvent{Text: "Hel"}},
+		StreamEvent{Type: EventContent, Data: ContentEvent{Text: "lo"}},
+		StreamEvent{Type: EventDebug},
+		finished,
+	))
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	if resp.ID != "req-1" || resp.Model != "gpt-4o" || resp.Provider != ProviderOpenAI || resp.Usage != usage || resp.SystemFingerprint != "fp_1" {
+		t.Errorf("unexpected response metadata %+v", resp)
+	}
+	if len(resp.Choices) != 1 {
+		t.Fatalf("expected one choice, got %d", len(resp.Choices))
+	}
+	if content := collectedMessage(t, resp, 0)["content"]; content != "Hello" {
+		t.Errorf("expected concatenated content, got %q", content)
+	}
+	if reason := resp.Choices[0].FinishReason; reason != providers.FinishReasonStop {
+		t.Errorf("expected finish reason stop, got %v", reason)
+	}
+}
+
+func TestCollect_Citations(t *testing.T) {
+	span := CitationSpan{StartIndex: 0, EndIndex: 5, Text: "Hello"}
+	resp, err := Collect(streamOf(
+		StreamEvent{Type: EventContent, Data: ContentEvent{Text: "Hello"}},
+		StreamEvent{Type: EventCitation, Data: CitationEvent{Sources: []Citation{{Title: "Example", URI: "https://example.com", Spans: []CitationSpan{span}}}}},
+		NewFinishedEvent(ProviderGemini, "gemini-2.0-flash", providers.FinishReasonStop, nil),
+	))
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	want := []providers.Citation{{Title: "Example", URI: "https://example.com", Spans: []CitationSpan{span}}}
+	if citations := collectedMessage(t, resp, 0)["citations"]; !reflect.DeepEqual(citations, want) {
+		t.Errorf("expected the streamed citations on the message, got %+v", citations)
+	}
+}
+
+func TestCollect_ToolCalls(t *testing.T) {
+	resp, err := Collect(streamOf(
+		StreamEvent{Type: EventToolCall, Data: ToolCallEvent{CallID: "call_1", ToolName: "get_weather"}},
+		StreamEvent{Type: EventToolCall, Data: ToolCallEvent{Arguments: map[string]interface{}{"city": "Paris"}}},
+		StreamEvent{Type: EventToolCall, Data: ToolCallEvent{CallID: "call_2", ToolName: "get_time", Arguments: map[string]interface{}{"tz": "CET"}}},
+		StreamEvent{Type: EventToolCall, Data: ToolCallEvent{CallID: "call_1", Arguments: map[string]interface{}{"unit": "C"}}},
+		StreamEvent{Type: EventFinished, Metadata: EventMeta{FinishReason: providers.FinishReasonToolCalls}},
+		NewUsageEvent(ProviderOpenAI, "gpt-4o", &Usage{TotalTokens: 9}, 0),
+	))
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	want := []ToolCall{
+		{ID: "call_1", Name: "get_weather", Arguments: map[string]interface{}{"city": "Paris", "unit": "C"}},
+		{ID: "call_2", Name: "get_time", Arguments: map[string]interface{}{"tz": "CET"}},
+	}
+	if got := collectedMessage(t, resp, 0)["tool_calls"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected merged tool calls %+v, got %+v", want, got)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 9 {
+		t.Errorf("expected usage from the usage event, got %+v", resp.Usage)
+	}
+}
+
+func TestCollect_MultipleChoicesAndError(t *testing.T) {
+	streamErr := errors.New("connection reset")
+	second := StreamEvent{Type: EventContent, Data: ContentEvent{Text: "B"}, Metadata: EventMeta{ChoiceIndex: 1}}
+	resp, err := Collect(streamOf(
+		second,
+		StreamEvent{Type: EventContent, Data: ContentEvent{Text: "A"}},
+		NewErrorEvent(ProviderOpenAI, "gpt-4o", streamErr, false),
+		NewErrorEvent(ProviderOpenAI, "gpt-4o", errors.New("later"), false),
+	))
+	if err != streamErr {
+		t.Errorf("expected the first stream error, got %v", err)
+	}
+	if len(resp.Choices) != 2 {
+		t.Fatalf("expected the partial response to keep both choices, got %d", len(resp.Choices))
+	}
+	if collectedMessage(t, resp, 0)["content"] != "A" || collectedMessage(t, resp, 1)["content"] != "B" {
+		t.Errorf("expected choices ordered by index, got %+v", resp.Choices)
+	}
+}
+
+func TestCollect_Logprobs(t *testing.T) {
+	resp, err := Collect(streamOf(
+		StreamEvent{Type: EventContent, Data: ContentEvent{Text: "Hel", Logprobs: []TokenLogprob{{Token: "Hel", Logprob: -0.1}}}},
+		StreamEvent{Type: EventContent, Data: ContentEvent{Text: "lo", Logprobs: []TokenLogprob{{Token: "lo", Logprob: -0.2}}}},
+		NewFinishedEvent(ProviderOpenAI, "gpt-4o", providers.FinishReasonStop, nil),
+	))
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if logprobs := resp.Choices[0].Logprobs; len(logprobs) != 2 || logprobs[0].Token != "Hel" || logprobs[1].Token != "lo" {
+		t.Errorf("expected the tokens' logprobs in order, got %+v", logprobs)
+	}
+}
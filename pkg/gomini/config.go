@@ -2,12 +2,14 @@ package gomini
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 	
 	"gomini/pkg/gomini/providers"
+	"gomini/pkg/gomini/providers/chaos"
 )
 
 // Config holds configuration for the unified LLM client
@@ -20,26 +22,78 @@ type Config struct {
 	EnableFallback  bool         `json:"enable_fallback"`
 	FallbackChain   []providers.ProviderType `json:"fallback_chain,omitempty"`
 	
+	// Environment profiles; the active one is chosen by Profile or GOMINI_PROFILE
+	Profile  string              `json:"profile,omitempty"`
+	Profiles map[string]*Profile `json:"profiles,omitempty"`
+	
+	// Config file last read by LoadFromFile, reported by Diagnose
+	ConfigFile string `json:"-"`
+
+	// Named request presets selected with ChatRequest.Persona
+	Personas map[string]*Persona `json:"personas,omitempty"`
+	
+	// Stable model names, such as "fast" or "smart", that requests use in
+	// place of provider model IDs so they can be re-pointed in config
+	ModelAliases map[string]ModelAlias `json:"model_aliases,omitempty"`
+	
 	// Routing settings
 	Router *RouterConfig `json:"router,omitempty"`
 	
 	// Global request defaults
 	DefaultConfig RequestConfig `json:"default_config,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"` // Applied to every request; request tags take precedence
+	
+	// Pricing used for cost tracking: a JSON price table file or http(s) URL,
+	// plus per-model overrides. Both take precedence over the built-in catalog.
+	PricingSource  string                `json:"pricing_source,omitempty"`
+	PriceOverrides map[string]ModelPrice `json:"price_overrides,omitempty"`
+	
+	// Opt-in response cache for repeated identical requests
+	Cache *CacheConfig `json:"cache,omitempty"`
+	
+	// Record provider traffic to cassette files, or replay it without
+	// credentials or network access
+	VCR *VCRConfig `json:"vcr,omitempty"`
+	
+	// Inject 429s, 500s, timeouts, disconnects and malformed JSON into
+	// provider calls, for resilience testing
+	Chaos *chaos.Config `json:"chaos,omitempty"`
+	
+	// ModerateInput runs Client.Moderate on the latest user message before
+	// sending and refuses flagged requests with ErrorContentFiltered
+	ModerateInput bool `json:"moderate_input,omitempty"`
+	
+	// Requests asking for more output tokens than the model's catalog limit
+	// are clamped to it; StrictOutputTokens rejects them instead
+	StrictOutputTokens bool `json:"strict_output_tokens,omitempty"`
 	
 	// Timeouts and limits
-	RequestTimeout  time.Duration `json:"request_timeout,omitempty"`
+	RequestTimeout  time.Duration `json:"request_timeout,omitempty"` // Bounds each non-streaming provider call
+	StreamIdleTimeout time.Duration `json:"stream_idle_timeout,omitempty"` // Aborts a stream when no event arrives for this long
 	MaxRetries      int           `json:"max_retries,omitempty"`
 	RetryDelay      time.Duration `json:"retry_delay,omitempty"`
+	MaxConcurrentStreams int      `json:"max_concurrent_streams,omitempty"` // Streams processed at once; waiting streams take turns by priority
+	CleanupInterval time.Duration `json:"cleanup_interval,omitempty"` // How often expiring stores are swept; defaults to one minute
 	
 	// Debug and logging
 	Debug       bool   `json:"debug,omitempty"`
 	LogLevel    string `json:"log_level,omitempty"`
 	LogRequests bool   `json:"log_requests,omitempty"`
+	LogRedactFields []string `json:"log_redact_fields,omitempty"` // Payload fields masked in request logs, on top of the defaults
 	
 	// Session management and loop detection
 	MaxSessionTurns       int  `json:"max_session_turns,omitempty"`
 	SkipNextSpeakerCheck  bool `json:"skip_next_speaker_check,omitempty"`
 	LoopDetectionEnabled  bool `json:"loop_detection_enabled,omitempty"`
+	// LoopDetectionCodeAware applies line-based repetition detection inside code blocks
+	LoopDetectionCodeAware bool `json:"loop_detection_code_aware,omitempty"`
+	// LoopJudgeModel enables LLM-based loop detection on long prompts; the judge
+	// runs on LoopJudgeProvider, defaulting to the current provider
+	LoopJudgeModel    string                 `json:"loop_judge_model,omitempty"`
+	LoopJudgeProvider providers.ProviderType `json:"loop_judge_provider,omitempty"`
+	
+	// Tool calls of one turn that Client.RunTools executes at once; defaults to 4
+	ToolWorkers int `json:"tool_workers,omitempty"`
 }
 
 // ProviderConfig holds configuration for a specific provider
@@ -48,10 +102,12 @@ type ProviderConfig struct {
 	
 	// Authentication
 	APIKey    string `json:"api_key,omitempty"`
+	APIKeys   []string `json:"api_keys,omitempty"` // Extra keys pooled with APIKey; see KeyRotation
 	Endpoint  string `json:"endpoint,omitempty"`
 	Project   string `json:"project,omitempty"`   // Gemini/Vertex AI
 	Location  string `json:"location,omitempty"`  // Gemini/Vertex AI
 	UseVertex bool   `json:"use_vertex,omitempty"` // Use Vertex AI instead of Gemini API
+	Scenario  string `json:"scenario,omitempty"`   // Mock provider scenario file
 	
 	// Request settings
 	DefaultModel string                 `json:"default_model,omitempty"`
@@ -60,14 +116,82 @@ type ProviderConfig struct {
 	ExtraQuery   map[string]string      `json:"extra_query,omitempty"`
 	ExtraBody    map[string]interface{} `json:"extra_body,omitempty"`
 	
-	// Rate limiting
+	// HTTP connection settings; Client, when set, is used as is
+	Transport *TransportConfig `json:"transport,omitempty"`
+	Client    *http.Client     `json:"-"`
+	
+	// Rate limiting: RequestsPerMinute and RequestsPerDay are enforced
+	// before each call, across every client sharing a quota store (see
+	// core.SetQuotaStore); calls beyond them fail with ErrorRateLimit
 	RateLimit *providers.RateLimit `json:"rate_limit,omitempty"`
 	
+	// Circuit breaker: calls fail fast while a provider keeps failing
+	CircuitBreaker *CircuitBreaker `json:"circuit_breaker,omitempty"`
+	
+	// Requests in flight at once; excess requests queue by priority (see
+	// core.WithStreamPriority) until a slot frees or their context ends
+	MaxConcurrentRequests int `json:"max_concurrent_requests,omitempty"`
+	
+	// Key pools: with several keys, requests rotate across them and keys
+	// that hit 429 are benched for KeyCooldown (default one minute); keys
+	// rejected with 401 are benched until the config is reloaded
+	KeyRotation KeyRotation   `json:"key_rotation,omitempty"`
+	KeyCooldown time.Duration `json:"key_cooldown,omitempty"`
+	
+	// Published rate limits of each key, reported as headroom by QuotaStatus
+	Quota *QuotaLimits `json:"quota,omitempty"`
+	
+	// Model catalog: Capabilities override the built-in capability table,
+	// and DiscoverModels merges the provider's model listing into the
+	// catalog, refreshed every ModelsTTL (default one hour)
+	Capabilities   CapabilityTable `json:"capabilities,omitempty"`
+	DiscoverModels bool            `json:"discover_models,omitempty"`
+	ModelsTTL      time.Duration   `json:"models_ttl,omitempty"`
+	
 	// Provider-specific settings
 	OpenAI *OpenAIConfig `json:"openai,omitempty"`
 	Gemini *GeminiConfig `json:"gemini,omitempty"`
 }
 
+// KeyRotation selects the next key of a provider's key pool
+type KeyRotation string
+
+const (
+	RotateRoundRobin     KeyRotation = "round_robin"     // Take keys in turn (default)
+	RotateLeastThrottled KeyRotation = "least_throttled" // Prefer the key that was rate limited longest ago
+)
+
+// CircuitBreaker opens after Failures provider failures (server errors,
+// timeouts, network errors and rate limits) within Window, default one
+// minute. While open, calls fail with ErrorServiceUnavailable for Cooldown,
+// default 30 seconds.
+type CircuitBreaker struct {
+	Failures int           `json:"failures"`
+	Window   time.Duration `json:"window,omitempty"`
+	Cooldown time.Duration `json:"cooldown,omitempty"`
+}
+
+// QuotaLimits are a provider's rate limits for one API key; 0 means no limit
+type QuotaLimits struct {
+	RequestsPerMinute int64 `json:"requests_per_minute,omitempty"`
+	TokensPerMinute   int64 `json:"tokens_per_minute,omitempty"`
+	RequestsPerDay    int64 `json:"requests_per_day,omitempty"`
+	TokensPerDay      int64 `json:"tokens_per_day,omitempty"`
+}
+
+// Keys returns APIKey and APIKeys without blanks or duplicates
+func (p *ProviderConfig) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range append([]string{p.APIKey}, p.APIKeys...) {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
 // OpenAIConfig holds OpenAI-specific configuration
 type OpenAIConfig struct {
 	Organization   string `json:"organization,omitempty"`
@@ -100,24 +224,97 @@ type RouterConfig struct {
 	CapabilityRouting  bool             `json:"capability_routing,omitempty"`
 	FallbackOnError    bool             `json:"fallback_on_error,omitempty"`
 	MaxFallbackAttempts int             `json:"max_fallback_attempts,omitempty"`
+
+	// Requests naming a model but no provider go to its ModelPreferences
+	// entry, else to the enabled provider whose catalog lists the model or
+	// whose model names it matches, e.g. "gemini-" or "gpt-". Set
+	// DisableModelRouting to keep the current provider.
+	DisableModelRouting bool `json:"disable_model_routing,omitempty"`
+
+	// Context window handling: when the estimated prompt size exceeds the
+	// selected model's context window, switch to the model named in ContextUpgrades
+	AutoUpgradeContext bool                          `json:"auto_upgrade_context,omitempty"`
+	ContextUpgrades    map[string]ContextUpgradeRule `json:"context_upgrades,omitempty"` // model -> larger-context sibling
+
+	// Cheaper models used once a session nears its cost ceiling
+	CostDowngrades map[string]ContextUpgradeRule `json:"cost_downgrades,omitempty"` // model -> cheaper sibling
+
+	// Weighted A/B splits: requests for a model are spread across variants by
+	// a stable hash of the prompt ID, e.g. to canary a new model
+	TrafficSplits map[string][]TrafficSplit `json:"traffic_splits,omitempty"` // model -> variants
+
+	// Under StrategyLeastLoaded, only providers meeting the target are
+	// considered, unless none does
+	LatencyTarget *LatencyTarget `json:"latency_target,omitempty"`
+}
+
+// LatencyTarget is a latency goal for routing, such as a p95 under two
+// seconds. Latency is measured to the response or a stream's first token.
+type LatencyTarget struct {
+	Percentile float64       `json:"percentile"` // In (0, 1], e.g. 0.95
+	Below      time.Duration `json:"below"`
+}
+
+// TrafficSplit is one weighted variant of a traffic split
+type TrafficSplit struct {
+	Provider providers.ProviderType `json:"provider,omitempty"` // Defaults to the current provider
+	Model    string                 `json:"model"`
+	Weight   int                    `json:"weight"` // Relative share, e.g. 90 and 10
+}
+
+// CacheConfig enables the response cache. Requests are matched exactly after
+// normalization; a custom backend such as Redis can be set on the client.
+type CacheConfig struct {
+	Enabled    bool          `json:"enabled"`
+	TTL        time.Duration `json:"ttl,omitempty"`         // Defaults to one hour
+	MaxEntries int           `json:"max_entries,omitempty"` // Size of the in-memory LRU; defaults to 1000
+	SoftTTL    time.Duration `json:"soft_ttl,omitempty"`    // Older entries are still served, marked Stale, and refreshed in the background; off when zero
+}
+
+// VCRMode selects whether provider traffic is recorded or replayed
+type VCRMode string
+
+const (
+	VCRRecord VCRMode = "record"
+	VCRReplay VCRMode = "replay"
+)
+
+// VCRConfig records each provider's traffic to <Dir>/<provider>.json, or
+// serves requests from those files. Replay needs no API keys.
+type VCRConfig struct {
+	Mode VCRMode `json:"mode"`
+	Dir  string  `json:"dir"`
+}
+
+// ModelAlias is the model a Config.ModelAliases name stands for. A Model
+// ending in "@latest" resolves to the newest dated snapshot in the provider's
+// catalog.
+type ModelAlias struct {
+	Provider providers.ProviderType `json:"provider,omitempty"` // Defaults to the current provider
+	Model    string                 `json:"model"`
+}
+
+// ContextUpgradeRule names the larger-context model to use when a prompt does not fit
+type ContextUpgradeRule struct {
+	Provider providers.ProviderType `json:"provider"`
+	Model    string                 `json:"model"`
 }
 
 // RouterStrategy defines routing strategies
 type RouterStrategy string
 
 const (
-	StrategyRoundRobin    RouterStrategy = "round_robin"
-	StrategyLeastLoaded   RouterStrategy = "least_loaded"
-	StrategyLowestCost    RouterStrategy = "lowest_cost"
+	StrategyRoundRobin     RouterStrategy = "round_robin"
+	StrategyLeastLoaded    RouterStrategy = "least_loaded" // Requests naming no provider go to the least loaded, healthiest provider serving their model
+	StrategyLowestCost     RouterStrategy = "lowest_cost"
 	StrategyBestCapability RouterStrategy = "best_capability"
-	StrategyManual        RouterStrategy = "manual"
+	StrategyManual         RouterStrategy = "manual"
 )
 
 // NewConfig creates a new configuration with defaults
 func NewConfig() *Config {
 	return &Config{
 		Providers:      make(map[providers.ProviderType]*ProviderConfig),
-		EnableFallback: true,
 		RequestTimeout: 30 * time.Second,
 		MaxRetries:     3,
 		RetryDelay:     1 * time.Second,
@@ -133,8 +330,27 @@ func NewConfig() *Config {
 	}
 }
 
-// LoadFromEnv loads configuration from environment variables
+// compatibleProviderEnvKeys maps OpenAI-compatible providers to the
+// environment variable holding their API key
+var compatibleProviderEnvKeys = map[providers.ProviderType]string{
+	ProviderGroq:     "GROQ_API_KEY",
+	ProviderDeepSeek: "DEEPSEEK_API_KEY",
+}
+
+// LoadFromEnv loads configuration from environment variables. Unless a config
+// file was already loaded with LoadFromFile, GOMINI_CONFIG names a JSON config
+// file loaded first; Diagnose reports which file is in use. GOMINI_PROFILE
+// selects a profile from it that is applied last.
 func (c *Config) LoadFromEnv() error {
+	if path := os.Getenv("GOMINI_CONFIG"); path != "" && c.ConfigFile == "" {
+		if err := c.LoadFromFile(path); err != nil {
+			return err
+		}
+	}
+	if c.Providers == nil {
+		c.Providers = make(map[providers.ProviderType]*ProviderConfig)
+	}
+	
 	// OpenAI configuration
 	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
 		if c.Providers[ProviderOpenAI] == nil {
@@ -176,6 +392,15 @@ func (c *Config) LoadFromEnv() error {
 		c.Providers[ProviderGemini].APIKey = apiKey
 	}
 	
+	// Scripted mock provider for QA scenarios
+	if scenario := os.Getenv("GOMINI_MOCK_SCENARIO"); scenario != "" {
+		if c.Providers[ProviderMock] == nil {
+			c.Providers[ProviderMock] = &ProviderConfig{}
+		}
+		c.Providers[ProviderMock].Enabled = true
+		c.Providers[ProviderMock].Scenario = scenario
+	}
+	
 	// Vertex AI configuration
 	if useVertex := os.Getenv("GOOGLE_GENAI_USE_VERTEXAI"); useVertex != "" {
 		if c.Providers[ProviderGemini] == nil {
@@ -193,6 +418,17 @@ func (c *Config) LoadFromEnv() error {
 		}
 	}
 	
+	// OpenAI-compatible providers
+	for providerType, envKey := range compatibleProviderEnvKeys {
+		if apiKey := os.Getenv(envKey); apiKey != "" {
+			if c.Providers[providerType] == nil {
+				c.Providers[providerType] = &ProviderConfig{}
+			}
+			c.Providers[providerType].Enabled = true
+			c.Providers[providerType].APIKey = apiKey
+		}
+	}
+	
 	// Default provider
 	if provider := os.Getenv("GOMINI_DEFAULT_PROVIDER"); provider != "" {
 		c.DefaultProvider = providers.ProviderType(provider)
@@ -219,6 +455,43 @@ func (c *Config) LoadFromEnv() error {
 		c.Debug = strings.ToLower(debug) == "true"
 	}
 	
+	if cache := os.Getenv("GOMINI_CACHE_RESPONSES"); cache != "" {
+		if c.Cache == nil {
+			c.Cache = &CacheConfig{}
+		}
+		c.Cache.Enabled = strings.ToLower(cache) == "true"
+	}
+	
+	if mode := os.Getenv("GOMINI_VCR_MODE"); mode != "" {
+		if c.VCR == nil {
+			c.VCR = &VCRConfig{}
+		}
+		c.VCR.Mode = VCRMode(strings.ToLower(mode))
+	}
+	
+	if dir := os.Getenv("GOMINI_VCR_DIR"); dir != "" {
+		if c.VCR == nil {
+			c.VCR = &VCRConfig{}
+		}
+		c.VCR.Dir = dir
+	}
+	
+	if moderate := os.Getenv("GOMINI_MODERATE_INPUT"); moderate != "" {
+		c.ModerateInput = strings.ToLower(moderate) == "true"
+	}
+	
+	if pricing := os.Getenv("GOMINI_PRICING_SOURCE"); pricing != "" {
+		c.PricingSource = pricing
+	}
+	
+	if logLevel := os.Getenv("GOMINI_LOG_LEVEL"); logLevel != "" {
+		c.LogLevel = logLevel
+	}
+	
+	if logRequests := os.Getenv("GOMINI_LOG_REQUESTS"); logRequests != "" {
+		c.LogRequests = strings.ToLower(logRequests) == "true"
+	}
+	
 	// Request timeout
 	if timeout := os.Getenv("GOMINI_REQUEST_TIMEOUT"); timeout != "" {
 		if duration, err := time.ParseDuration(timeout); err == nil {
@@ -226,6 +499,12 @@ func (c *Config) LoadFromEnv() error {
 		}
 	}
 	
+	if idle := os.Getenv("GOMINI_STREAM_IDLE_TIMEOUT"); idle != "" {
+		if duration, err := time.ParseDuration(idle); err == nil {
+			c.StreamIdleTimeout = duration
+		}
+	}
+	
 	// Max retries
 	if retries := os.Getenv("GOMINI_MAX_RETRIES"); retries != "" {
 		if maxRetries, err := strconv.Atoi(retries); err == nil {
@@ -233,6 +512,18 @@ func (c *Config) LoadFromEnv() error {
 		}
 	}
 	
+	if streams := os.Getenv("GOMINI_MAX_CONCURRENT_STREAMS"); streams != "" {
+		if maxStreams, err := strconv.Atoi(streams); err == nil {
+			c.MaxConcurrentStreams = maxStreams
+		}
+	}
+	
+	if interval := os.Getenv("GOMINI_CLEANUP_INTERVAL"); interval != "" {
+		if duration, err := time.ParseDuration(interval); err == nil {
+			c.CleanupInterval = duration
+		}
+	}
+	
 	// Session management settings
 	if maxTurns := os.Getenv("GOMINI_MAX_SESSION_TURNS"); maxTurns != "" {
 		if turns, err := strconv.Atoi(maxTurns); err == nil {
@@ -248,6 +539,28 @@ func (c *Config) LoadFromEnv() error {
 		c.LoopDetectionEnabled = strings.ToLower(loopDetection) == "true"
 	}
 	
+	if codeAware := os.Getenv("GOMINI_LOOP_DETECTION_CODE_AWARE"); codeAware != "" {
+		c.LoopDetectionCodeAware = strings.ToLower(codeAware) == "true"
+	}
+	
+	if judgeModel := os.Getenv("GOMINI_LOOP_JUDGE_MODEL"); judgeModel != "" {
+		c.LoopJudgeModel = judgeModel
+	}
+	
+	if judgeProvider := os.Getenv("GOMINI_LOOP_JUDGE_PROVIDER"); judgeProvider != "" {
+		c.LoopJudgeProvider = providers.ProviderType(judgeProvider)
+	}
+	
+	profile := c.Profile
+	if envProfile := os.Getenv("GOMINI_PROFILE"); envProfile != "" {
+		profile = envProfile
+	}
+	if profile != "" {
+		if err := c.ApplyProfile(profile); err != nil {
+			return err
+		}
+	}
+	
 	return nil
 }
 
@@ -257,6 +570,33 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("no providers configured")
 	}
 	
+	if c.VCR != nil && c.VCR.Mode != "" {
+		if c.VCR.Mode != VCRRecord && c.VCR.Mode != VCRReplay {
+			return fmt.Errorf("unknown vcr mode %q (want %q or %q)", c.VCR.Mode, VCRRecord, VCRReplay)
+		}
+		if c.VCR.Dir == "" {
+			return fmt.Errorf("vcr mode %s requires a cassette directory", c.VCR.Mode)
+		}
+	}
+	
+	if c.Chaos != nil {
+		if err := c.Chaos.Validate(); err != nil {
+			return err
+		}
+	}
+	
+	if c.Router != nil && c.Router.LatencyTarget != nil {
+		if target := c.Router.LatencyTarget; target.Percentile <= 0 || target.Percentile > 1 || target.Below <= 0 {
+			return fmt.Errorf("latency target needs a percentile in (0, 1] and a positive bound")
+		}
+	}
+	
+	for name, alias := range c.ModelAliases {
+		if alias.Model == "" {
+			return fmt.Errorf("model alias %s names no model", name)
+		}
+	}
+	
 	enabledProviders := 0
 	for providerType, config := range c.Providers {
 		if !config.Enabled {
@@ -264,14 +604,46 @@ func (c *Config) Validate() error {
 		}
 		enabledProviders++
 		
+		switch config.KeyRotation {
+		case "", RotateRoundRobin, RotateLeastThrottled:
+		default:
+			return fmt.Errorf("%s: unknown key rotation %q", providerType, config.KeyRotation)
+		}
+		if q := config.Quota; q != nil && (q.RequestsPerMinute < 0 || q.TokensPerMinute < 0 || q.RequestsPerDay < 0 || q.TokensPerDay < 0) {
+			return fmt.Errorf("%s: quota limits cannot be negative", providerType)
+		}
+		if config.MaxConcurrentRequests < 0 {
+			return fmt.Errorf("%s: max concurrent requests cannot be negative", providerType)
+		}
+		
+		if b := config.CircuitBreaker; b != nil && (b.Failures <= 0 || b.Window < 0 || b.Cooldown < 0) {
+			return fmt.Errorf("%s: circuit breaker needs a positive failure count and non-negative durations", providerType)
+		}
+		if r := config.RateLimit; r != nil && (r.RequestsPerMinute < 0 || r.RequestsPerDay < 0) {
+			return fmt.Errorf("%s: rate limits cannot be negative", providerType)
+		}
+		
+		// Replayed providers need no credentials
+		if c.Replaying() {
+			continue
+		}
+		
 		// Validate provider-specific config
 		switch providerType {
 		case ProviderOpenAI:
-			if config.APIKey == "" {
+			if len(config.Keys()) == 0 {
 				return fmt.Errorf("OpenAI API key is required")
 			}
+		case ProviderGroq, ProviderDeepSeek:
+			if len(config.Keys()) == 0 {
+				return fmt.Errorf("%s API key is required", providerType)
+			}
+		case ProviderMock:
+			if config.Scenario == "" {
+				return fmt.Errorf("mock provider requires a scenario file")
+			}
 		case ProviderGemini:
-			if !config.UseVertex && config.APIKey == "" {
+			if !config.UseVertex && len(config.Keys()) == 0 {
 				return fmt.Errorf("Gemini API key is required (unless using Vertex AI)")
 			}
 			if config.UseVertex && (config.Project == "" || config.Location == "") {
@@ -284,6 +656,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("no enabled providers found")
 	}
 	
+	if c.Cache != nil && (c.Cache.TTL < 0 || c.Cache.SoftTTL < 0) {
+		return fmt.Errorf("cache ttl and soft_ttl cannot be negative")
+	}
+	
 	// Set default provider if not specified
 	if c.DefaultProvider == "" {
 		for providerType, config := range c.Providers {
@@ -302,6 +678,11 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// Replaying reports whether provider traffic is served from cassettes
+func (c *Config) Replaying() bool {
+	return c.VCR != nil && c.VCR.Mode == VCRReplay
+}
+
 // GetProviderConfig returns the configuration for a specific provider
 func (c *Config) GetProviderConfig(provider providers.ProviderType) (*ProviderConfig, error) {
 	config, exists := c.Providers[provider]
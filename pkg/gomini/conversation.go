@@ -0,0 +1,109 @@
+package gomini
+
+// Conversation builds a message list one typed call at a time, in place of
+// hand-built role maps:
+//
+//	messages, err := gomini.NewConversation().
+//		System("You are a vision assistant.").
+//		User("What is in this picture?").
+//		UserImageFile("cat.png").
+//		Build()
+//
+// Image calls attach to the user message before them, or start a new one.
+// The first error, such as an unreadable image, is kept and returned by
+// Build; later calls are ignored.
+type Conversation struct {
+	messages []Message
+	err      error
+}
+
+// NewConversation starts an empty conversation
+func NewConversation() *Conversation {
+	return &Conversation{}
+}
+
+// System appends a system message
+func (c *Conversation) System(content string) *Conversation {
+	return c.add(NewSystemMessage(content))
+}
+
+// User appends a user message
+func (c *Conversation) User(content string) *Conversation {
+	return c.add(NewUserMessage(content))
+}
+
+// Assistant appends an assistant message
+func (c *Conversation) Assistant(content string) *Conversation {
+	return c.add(NewAssistantMessage(content))
+}
+
+// AssistantToolCalls appends an assistant message carrying tool calls
+func (c *Conversation) AssistantToolCalls(content string, toolCalls ...ToolCall) *Conversation {
+	return c.add(NewAssistantToolCallMessage(content, toolCalls))
+}
+
+// ToolResult appends the result of a tool call
+func (c *Conversation) ToolResult(callID, toolName, content string) *Conversation {
+	return c.add(NewToolResultMessage(callID, toolName, content))
+}
+
+// UserImageURL attaches a remote image to the current user message
+func (c *Conversation) UserImageURL(imageURL string) *Conversation {
+	return c.attach(NewImageURLPart(imageURL))
+}
+
+// UserImageFile attaches an image loaded from path to the current user message
+func (c *Conversation) UserImageFile(path string) *Conversation {
+	if c.err != nil {
+		return c
+	}
+	msg, err := NewImageMessageFromFile("", path)
+	if err != nil {
+		c.err = err
+		return c
+	}
+	parts := msg.(map[string]interface{})["content"].([]interface{})
+	return c.attach(parts[0].(map[string]interface{}))
+}
+
+// Build returns the messages, or the first error a call ran into
+func (c *Conversation) Build() ([]Message, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return append([]Message(nil), c.messages...), nil
+}
+
+func (c *Conversation) add(msg Message) *Conversation {
+	if c.err == nil {
+		c.messages = append(c.messages, msg)
+	}
+	return c
+}
+
+// attach adds part to the trailing user message, turning plain text content
+// into parts, or appends a new user message holding only part
+func (c *Conversation) attach(part map[string]interface{}) *Conversation {
+	if c.err != nil {
+		return c
+	}
+	if n := len(c.messages); n > 0 {
+		if last, ok := c.messages[n-1].(map[string]interface{}); ok && last["role"] == "user" {
+			var parts []interface{}
+			switch content := last["content"].(type) {
+			case string:
+				if content != "" {
+					parts = append(parts, NewTextPart(content))
+				}
+			case []interface{}:
+				parts = append(parts, content...)
+			}
+			c.messages[n-1] = map[string]interface{}{
+				"role":    "user",
+				"content": append(parts, part),
+			}
+			return c
+		}
+	}
+	return c.add(newImageMessage("", part))
+}
@@ -0,0 +1,52 @@
+package gomini
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConversation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cat.png")
+	if err := os.WriteFile(path, testPNG, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := NewConversation().
+		System("You are a vision assistant.").
+		User("What is in this picture?").
+		UserImageFile(path).
+		Assistant("A cat.").
+		UserImageURL("https://example.com/dog.png").
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(messages) != 4 {
+		t.Fatalf("expected 4 messages, got %d: %v", len(messages), messages)
+	}
+	if !reflect.DeepEqual(messages[0], NewSystemMessage("You are a vision assistant.")) {
+		t.Errorf("unexpected system message %v", messages[0])
+	}
+	if data := imageData(t, messages[1]); data["mime_type"] != "image/png" {
+		t.Errorf("expected the image to join the user text, got %v", messages[1])
+	}
+	if !reflect.DeepEqual(messages[2], NewAssistantMessage("A cat.")) {
+		t.Errorf("unexpected assistant message %v", messages[2])
+	}
+	if !reflect.DeepEqual(messages[3], newImageMessage("", NewImageURLPart("https://example.com/dog.png"))) {
+		t.Errorf("expected an image after an assistant turn to start a user message, got %v", messages[3])
+	}
+}
+
+func TestConversation_Error(t *testing.T) {
+	_, err := NewConversation().
+		User("What is this?").
+		UserImageFile(filepath.Join(t.TempDir(), "missing.png")).
+		User("Hello?").
+		Build()
+	if err == nil {
+		t.Fatal("expected the missing image to fail Build")
+	}
+}
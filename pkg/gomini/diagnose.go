@@ -0,0 +1,176 @@
+package gomini
+
+import (
+	"fmt"
+	"sort"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// DiagnosticSeverity classifies a configuration finding
+type DiagnosticSeverity string
+
+const (
+	SeverityError   DiagnosticSeverity = "error"   // The client cannot work as configured
+	SeverityWarning DiagnosticSeverity = "warning" // Likely misconfiguration
+	SeverityInfo    DiagnosticSeverity = "info"
+)
+
+// Diagnostic is a single configuration finding reported by Config.Diagnose
+type Diagnostic struct {
+	Severity DiagnosticSeverity     `json:"severity"`
+	Provider providers.ProviderType `json:"provider,omitempty"`
+	Message  string                 `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	if d.Provider != "" {
+		return fmt.Sprintf("[%s] %s: %s", d.Severity, d.Provider, d.Message)
+	}
+	return fmt.Sprintf("[%s] %s", d.Severity, d.Message)
+}
+
+// Diagnose inspects the configuration without making network calls and
+// reports every problem found, unlike Validate which stops at the first error
+func (c *Config) Diagnose() []Diagnostic {
+	var diagnostics []Diagnostic
+	add := func(severity DiagnosticSeverity, provider providers.ProviderType, format string, args ...interface{}) {
+		diagnostics = append(diagnostics, Diagnostic{Severity: severity, Provider: provider, Message: fmt.Sprintf(format, args...)})
+	}
+
+	providerTypes := make([]providers.ProviderType, 0, len(c.Providers))
+	for providerType := range c.Providers {
+		providerTypes = append(providerTypes, providerType)
+	}
+	sort.Slice(providerTypes, func(i, j int) bool { return providerTypes[i] < providerTypes[j] })
+
+	enabled := 0
+	for _, providerType := range providerTypes {
+		config := c.Providers[providerType]
+		if config == nil || !config.Enabled {
+			add(SeverityInfo, providerType, "provider is configured but disabled")
+			continue
+		}
+		enabled++
+
+		switch providerType {
+		case ProviderOpenAI:
+			if len(config.Keys()) == 0 {
+				add(SeverityError, providerType, "API key is missing (set OPENAI_API_KEY)")
+			}
+		case ProviderGroq, ProviderDeepSeek:
+			if len(config.Keys()) == 0 {
+				add(SeverityError, providerType, "API key is missing (set %s)", compatibleProviderEnvKeys[providerType])
+			}
+		case ProviderMock:
+			if config.Scenario == "" {
+				add(SeverityError, providerType, "scenario file is missing (set GOMINI_MOCK_SCENARIO)")
+			}
+		case ProviderGemini:
+			if config.UseVertex {
+				if config.Project == "" || config.Location == "" {
+					add(SeverityError, providerType, "Vertex AI requires project and location (set GOOGLE_CLOUD_PROJECT and GOOGLE_CLOUD_LOCATION)")
+				}
+			} else if len(config.Keys()) == 0 {
+				add(SeverityError, providerType, "API key is missing (set GEMINI_API_KEY or enable Vertex AI)")
+			}
+		default:
+			add(SeverityError, providerType, "unsupported provider type")
+		}
+
+		if config.DefaultModel == "" && len(config.Models) == 0 {
+			add(SeverityInfo, providerType, "no default model configured; requests must name a model")
+		}
+	}
+
+	if enabled == 0 {
+		add(SeverityError, "", "no providers enabled")
+	}
+
+	if c.DefaultProvider != "" && !c.HasProvider(c.DefaultProvider) {
+		add(SeverityError, c.DefaultProvider, "default provider is not enabled")
+	}
+
+	if c.EnableFallback {
+		if enabled < 2 {
+			add(SeverityWarning, "", "fallback is enabled but fewer than two providers are enabled")
+		}
+		for _, providerType := range c.FallbackChain {
+			if !c.HasProvider(providerType) {
+				add(SeverityWarning, providerType, "fallback chain references a provider that is not enabled")
+			}
+		}
+	}
+
+	for name, alias := range c.ModelAliases {
+		if alias.Model == "" {
+			add(SeverityError, alias.Provider, "model alias %s names no model", name)
+		}
+		if alias.Provider != "" && !c.HasProvider(alias.Provider) {
+			add(SeverityWarning, alias.Provider, "model alias %s targets a provider that is not enabled", name)
+		}
+	}
+	if c.Router != nil {
+		switch c.Router.Strategy {
+		case "", StrategyRoundRobin, StrategyLeastLoaded, StrategyLowestCost, StrategyBestCapability, StrategyManual:
+		default:
+			add(SeverityError, "", "unknown router strategy %q", c.Router.Strategy)
+		}
+		for model, rule := range c.Router.ContextUpgrades {
+			if rule.Provider != "" && !c.HasProvider(rule.Provider) {
+				add(SeverityWarning, rule.Provider, "context upgrade for %s targets a provider that is not enabled", model)
+			}
+		}
+		for model, splits := range c.Router.TrafficSplits {
+			total := 0
+			for _, split := range splits {
+				if split.Weight < 0 {
+					add(SeverityError, split.Provider, "traffic split for %s has a negative weight for %s", model, split.Model)
+				}
+				if split.Model == "" {
+					add(SeverityError, split.Provider, "traffic split for %s has a variant without a model", model)
+				}
+				if split.Provider != "" && !c.HasProvider(split.Provider) {
+					add(SeverityWarning, split.Provider, "traffic split for %s targets a provider that is not enabled", model)
+				}
+				total += split.Weight
+			}
+			if total <= 0 {
+				add(SeverityWarning, "", "traffic split for %s has no positive weights and is ignored", model)
+			}
+		}
+	}
+
+	if c.ConfigFile != "" {
+		add(SeverityInfo, "", "loaded config file %s", c.ConfigFile)
+	}
+	if c.Profile != "" {
+		if _, exists := c.Profiles[c.Profile]; exists {
+			add(SeverityInfo, "", "using profile %q", c.Profile)
+		} else {
+			add(SeverityError, "", "unknown profile %q", c.Profile)
+		}
+	}
+
+	if c.RequestTimeout < 0 {
+		add(SeverityError, "", "request timeout must not be negative")
+	}
+	if c.StreamIdleTimeout < 0 {
+		add(SeverityError, "", "stream idle timeout must not be negative")
+	}
+	if c.MaxRetries < 0 {
+		add(SeverityError, "", "max retries must not be negative")
+	}
+
+	return diagnostics
+}
+
+// HasErrors reports whether any diagnostic has error severity
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
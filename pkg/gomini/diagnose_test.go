@@ -0,0 +1,58 @@
+package gomini
+
+import (
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+)
+
+func TestConfig_Diagnose(t *testing.T) {
+	config := NewConfig()
+	config.Providers[ProviderOpenAI] = &ProviderConfig{Enabled: true}
+	config.Providers[ProviderGemini] = &ProviderConfig{Enabled: true, UseVertex: true, Project: "p"}
+	config.EnableFallback = true
+	config.FallbackChain = []providers.ProviderType{ProviderOpenAI, "anthropic"}
+	config.Router.Strategy = "fastest"
+	config.Router.TrafficSplits = map[string][]TrafficSplit{
+		"gpt-4o-mini": {{Model: "gpt-4o-mini", Weight: 90}, {Provider: "anthropic", Model: "claude", Weight: -10}},
+	}
+	config.ModelAliases = map[string]ModelAlias{"smart": {Provider: "anthropic", Model: "claude"}, "fast": {}}
+
+	diagnostics := config.Diagnose()
+	if !HasErrors(diagnostics) {
+		t.Fatal("expected errors")
+	}
+
+	expected := []string{
+		"openai: API key is missing",
+		"gemini: Vertex AI requires project and location",
+		"anthropic: fallback chain references a provider that is not enabled",
+		`unknown router strategy "fastest"`,
+		"anthropic: traffic split for gpt-4o-mini has a negative weight for claude",
+		"anthropic: traffic split for gpt-4o-mini targets a provider that is not enabled",
+		"model alias fast names no model",
+		"anthropic: model alias smart targets a provider that is not enabled",
+	}
+	var all []string
+	for _, d := range diagnostics {
+		all = append(all, d.String())
+	}
+	joined := strings.Join(all, "\n")
+	for _, message := range expected {
+		if !strings.Contains(joined, message) {
+			t.Errorf("expected diagnostic %q in:\n%s", message, joined)
+		}
+	}
+}
+
+func TestConfig_DiagnoseHealthy(t *testing.T) {
+	config := NewConfig()
+	config.Providers[ProviderOpenAI] = &ProviderConfig{Enabled: true, APIKey: "key", DefaultModel: "gpt-4o-mini"}
+	config.Providers[ProviderGemini] = &ProviderConfig{Enabled: true, APIKey: "key", DefaultModel: "gemini-1.5-flash"}
+	config.DefaultProvider = ProviderOpenAI
+
+	if diagnostics := config.Diagnose(); len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diagnostics)
+	}
+}
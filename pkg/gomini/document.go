@@ -0,0 +1,81 @@
+package gomini
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// documentExtensions covers document types missing from the system MIME table
+var documentExtensions = map[string]string{
+	".pdf":  "application/pdf",
+	".txt":  "text/plain",
+	".md":   "text/markdown",
+	".csv":  "text/csv",
+	".html": "text/html",
+	".xml":  "text/xml",
+	".json": "application/json",
+	".py":   "text/x-python",
+	".js":   "text/javascript",
+}
+
+// NewDocumentPart creates a document part from bytes, validating type and size.
+// An empty mimeType is detected from the content.
+func NewDocumentPart(name, mimeType string, data []byte) (DocumentPart, error) {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return DocumentPart{}, fmt.Errorf("invalid document type %q: %w", mimeType, err)
+	}
+
+	doc := DocumentPart{MIMEType: mediaType, Data: data, Name: name}
+	if err := doc.Validate(); err != nil {
+		return DocumentPart{}, err
+	}
+	return doc, nil
+}
+
+// NewDocumentPartFromFile loads a document from path; the MIME type is taken
+// from the file extension, falling back to content detection
+func NewDocumentPartFromFile(path string) (DocumentPart, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return DocumentPart{}, fmt.Errorf("failed to open document: %w", err)
+	}
+	if info.Size() > providers.MaxDocumentBytes {
+		return DocumentPart{}, fmt.Errorf("document exceeds %d bytes", providers.MaxDocumentBytes)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return DocumentPart{}, fmt.Errorf("failed to read document: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	mimeType := documentExtensions[ext]
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(ext)
+	}
+	return NewDocumentPart(filepath.Base(path), mimeType, data)
+}
+
+// NewDocumentMessage creates a user message with optional text and a document
+func NewDocumentMessage(text string, doc DocumentPart) Message {
+	parts := make([]interface{}, 0, 2)
+	if text != "" {
+		parts = append(parts, NewTextPart(text))
+	}
+	parts = append(parts, doc)
+
+	return map[string]interface{}{
+		"role":    "user",
+		"content": parts,
+	}
+}
@@ -0,0 +1,47 @@
+package gomini
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewDocumentPartFromFile(t *testing.T) {
+	dir := t.TempDir()
+
+	pdf := filepath.Join(dir, "report.pdf")
+	if err := os.WriteFile(pdf, []byte("%PDF-1.7"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := NewDocumentPartFromFile(pdf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc.MIMEType != "application/pdf" || doc.Name != "report.pdf" {
+		t.Errorf("unexpected document %+v", doc)
+	}
+
+	archive := filepath.Join(dir, "archive.zip")
+	if err := os.WriteFile(archive, []byte("PK\x03\x04"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDocumentPartFromFile(archive); err == nil {
+		t.Error("expected error for unsupported document type")
+	}
+}
+
+func TestNewDocumentPart_DetectsType(t *testing.T) {
+	doc, err := NewDocumentPart("", "", []byte("%PDF-1.7 ..."))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc.MIMEType != "application/pdf" {
+		t.Errorf("expected detected application/pdf, got %s", doc.MIMEType)
+	}
+
+	msg := NewDocumentMessage("summarize", doc)
+	parts := msg.(map[string]interface{})["content"].([]interface{})
+	if _, ok := parts[1].(DocumentPart); !ok || len(parts) != 2 {
+		t.Errorf("expected text and document parts, got %v", parts)
+	}
+}
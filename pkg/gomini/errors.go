@@ -1,355 +1,169 @@
 package gomini
 
 import (
+	"errors"
 	"fmt"
-	"net/http"
 	"strings"
-	"time"
 	
 	"gomini/pkg/gomini/providers"
 )
 
-// ErrorCode represents different types of errors
-type ErrorCode string
+type (
+	// ErrorCode represents different types of errors
+	ErrorCode = providers.ErrorCode
+
+	// LLMError represents a unified error from any LLM provider. Providers
+	// return it too, so errors.Is and errors.As work from the adapter that
+	// failed through to the caller.
+	LLMError = providers.LLMError
+)
 
 const (
 	// Authentication errors
-	ErrorInvalidAPIKey      ErrorCode = "invalid_api_key"
-	ErrorInvalidAuth        ErrorCode = "invalid_auth"
-	ErrorAuthRequired       ErrorCode = "auth_required"
-	
-	// Request errors  
-	ErrorInvalidRequest     ErrorCode = "invalid_request"
-	ErrorInvalidModel       ErrorCode = "invalid_model"
-	ErrorInvalidParameters  ErrorCode = "invalid_parameters"
-	ErrorRequestTooLarge    ErrorCode = "request_too_large"
-	ErrorUnsupportedFeature ErrorCode = "unsupported_feature"
-	
+	ErrorInvalidAPIKey = providers.ErrorInvalidAPIKey
+	ErrorInvalidAuth   = providers.ErrorInvalidAuth
+	ErrorAuthRequired  = providers.ErrorAuthRequired
+
+	// Request errors
+	ErrorInvalidRequest     = providers.ErrorInvalidRequest
+	ErrorInvalidModel       = providers.ErrorInvalidModel
+	ErrorInvalidParameters  = providers.ErrorInvalidParameters
+	ErrorRequestTooLarge    = providers.ErrorRequestTooLarge
+	ErrorUnsupportedFeature = providers.ErrorUnsupportedFeature
+	ErrorToolDisabled       = providers.ErrorToolDisabled
+
 	// Rate limiting errors
-	ErrorRateLimit          ErrorCode = "rate_limit"
-	ErrorQuotaExceeded      ErrorCode = "quota_exceeded"
-	ErrorTooManyRequests    ErrorCode = "too_many_requests"
-	
+	ErrorRateLimit       = providers.ErrorRateLimit
+	ErrorQuotaExceeded   = providers.ErrorQuotaExceeded
+	ErrorTooManyRequests = providers.ErrorTooManyRequests
+
 	// Server errors
-	ErrorServerError        ErrorCode = "server_error"
-	ErrorServiceUnavailable ErrorCode = "service_unavailable"
-	ErrorTimeout            ErrorCode = "timeout"
-	ErrorInternalError      ErrorCode = "internal_error"
-	
+	ErrorServerError        = providers.ErrorServerError
+	ErrorServiceUnavailable = providers.ErrorServiceUnavailable
+	ErrorTimeout            = providers.ErrorTimeout
+	ErrorInternalError      = providers.ErrorInternalError
+
 	// Content errors
-	ErrorContentFiltered    ErrorCode = "content_filtered"
-	ErrorSafetyViolation    ErrorCode = "safety_violation"
-	ErrorTokenLimitExceeded ErrorCode = "token_limit_exceeded"
-	
+	ErrorContentFiltered    = providers.ErrorContentFiltered
+	ErrorSafetyViolation    = providers.ErrorSafetyViolation
+	ErrorTokenLimitExceeded = providers.ErrorTokenLimitExceeded
+
 	// Provider errors
-	ErrorProviderNotFound   ErrorCode = "provider_not_found"
-	ErrorProviderDisabled   ErrorCode = "provider_disabled"
-	ErrorProviderSwitch     ErrorCode = "provider_switch"
-	ErrorAllProvidersFailed ErrorCode = "all_providers_failed"
-	
+	ErrorProviderNotFound   = providers.ErrorProviderNotFound
+	ErrorProviderDisabled   = providers.ErrorProviderDisabled
+	ErrorProviderSwitch     = providers.ErrorProviderSwitch
+	ErrorAllProvidersFailed = providers.ErrorAllProvidersFailed
+	ErrorBudgetExceeded     = providers.ErrorBudgetExceeded
+
 	// Network errors
-	ErrorNetworkError       ErrorCode = "network_error"
-	ErrorConnectionFailed   ErrorCode = "connection_failed"
-	ErrorDNSError          ErrorCode = "dns_error"
-	
+	ErrorNetworkError     = providers.ErrorNetworkError
+	ErrorConnectionFailed = providers.ErrorConnectionFailed
+	ErrorDNSError         = providers.ErrorDNSError
+
 	// Validation errors
-	ErrorValidation        ErrorCode = "validation_error"
-	ErrorMissingField      ErrorCode = "missing_field"
-	ErrorInvalidFormat     ErrorCode = "invalid_format"
-	
+	ErrorValidation    = providers.ErrorValidation
+	ErrorMissingField  = providers.ErrorMissingField
+	ErrorInvalidFormat = providers.ErrorInvalidFormat
+
 	// Unknown errors
-	ErrorUnknown           ErrorCode = "unknown_error"
+	ErrorUnknown = providers.ErrorUnknown
 )
 
-// LLMError represents a unified error from any LLM provider
-type LLMError struct {
-	Code        ErrorCode              `json:"code"`
-	Message     string                 `json:"message"`
-	Provider    providers.ProviderType           `json:"provider,omitempty"`
-	Model       string                 `json:"model,omitempty"`
-	HTTPStatus  int                    `json:"http_status,omitempty"`
-	Details     map[string]interface{} `json:"details,omitempty"`
-	Retryable   bool                   `json:"retryable"`
-	RetryAfter  *time.Duration         `json:"retry_after,omitempty"`
-	Cause       error                  `json:"-"` // Original error
-	Timestamp   time.Time              `json:"timestamp"`
-	RequestID   string                 `json:"request_id,omitempty"`
-}
-
-// Error implements the error interface
-func (e *LLMError) Error() string {
-	if e.Provider != "" {
-		return fmt.Sprintf("[%s:%s] %s", e.Provider, e.Code, e.Message)
-	}
-	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
-}
-
-// Unwrap returns the underlying error
-func (e *LLMError) Unwrap() error {
-	return e.Cause
-}
-
-// Is checks if the error matches the target error type
-func (e *LLMError) Is(target error) bool {
-	if t, ok := target.(*LLMError); ok {
-		return e.Code == t.Code
-	}
-	return false
-}
-
-// IsRetryable returns true if the error is retryable
-func (e *LLMError) IsRetryable() bool {
-	return e.Retryable
-}
-
-// IsRateLimit returns true if the error is due to rate limiting
-func (e *LLMError) IsRateLimit() bool {
-	return e.Code == ErrorRateLimit || e.Code == ErrorQuotaExceeded || e.Code == ErrorTooManyRequests
-}
-
-// IsAuthError returns true if the error is authentication-related
-func (e *LLMError) IsAuthError() bool {
-	return e.Code == ErrorInvalidAPIKey || e.Code == ErrorInvalidAuth || e.Code == ErrorAuthRequired
-}
-
-// IsContentError returns true if the error is content-related (filtering, safety)
-func (e *LLMError) IsContentError() bool {
-	return e.Code == ErrorContentFiltered || e.Code == ErrorSafetyViolation
-}
-
-// IsProviderError returns true if the error is provider-related
-func (e *LLMError) IsProviderError() bool {
-	return e.Code == ErrorProviderNotFound || e.Code == ErrorProviderDisabled || 
-		   e.Code == ErrorProviderSwitch || e.Code == ErrorAllProvidersFailed
-}
-
 // NewLLMError creates a new LLMError
 func NewLLMError(code ErrorCode, message string, provider providers.ProviderType, cause error) *LLMError {
-	return &LLMError{
-		Code:      code,
-		Message:   message,
-		Provider:  provider,
-		Cause:     cause,
-		Timestamp: time.Now(),
-		Retryable: isRetryableErrorCode(code),
-	}
+	return providers.NewLLMError(code, message, provider, cause)
 }
 
 // NewLLMErrorWithDetails creates a new LLMError with additional details
 func NewLLMErrorWithDetails(code ErrorCode, message string, provider providers.ProviderType, cause error, details map[string]interface{}) *LLMError {
-	return &LLMError{
-		Code:      code,
-		Message:   message,
-		Provider:  provider,
-		Cause:     cause,
-		Details:   details,
-		Timestamp: time.Now(),
-		Retryable: isRetryableErrorCode(code),
-	}
+	return providers.NewLLMErrorWithDetails(code, message, provider, cause, details)
 }
 
-// WrapProviderError wraps a provider-specific error into a unified LLMError
+// WrapProviderError wraps a provider-specific error into a unified LLMError.
+// An LLMError already in err's chain, such as one returned by a provider,
+// keeps its code.
 func WrapProviderError(err error, provider providers.ProviderType, model string) *LLMError {
-	if err == nil {
-		return nil
-	}
-	
-	// If it's already an LLMError, just update the provider
-	if llmErr, ok := err.(*LLMError); ok {
-		llmErr.Provider = provider
-		llmErr.Model = model
-		return llmErr
-	}
-	
-	// Map provider-specific errors to unified error codes
-	code, message, httpStatus, retryable := classifyError(err, provider)
-	
-	return &LLMError{
-		Code:       code,
-		Message:    message,
-		Provider:   provider,
-		Model:      model,
-		HTTPStatus: httpStatus,
-		Cause:      err,
-		Retryable:  retryable,
-		Timestamp:  time.Now(),
-	}
+	return providers.ClassifyError(err, provider, model)
 }
 
-// classifyError attempts to classify a provider-specific error
-func classifyError(err error, provider providers.ProviderType) (ErrorCode, string, int, bool) {
-	errStr := strings.ToLower(err.Error())
-	
-	// Common HTTP status-based classification
-	if strings.Contains(errStr, "401") || strings.Contains(errStr, "unauthorized") {
-		return ErrorInvalidAPIKey, "Invalid API key or unauthorized", 401, false
-	}
-	
-	if strings.Contains(errStr, "400") || strings.Contains(errStr, "bad request") {
-		return ErrorInvalidRequest, "Bad request", 400, false
-	}
-	
-	if strings.Contains(errStr, "403") || strings.Contains(errStr, "forbidden") {
-		return ErrorInvalidAuth, "Forbidden access", 403, false
-	}
-	
-	if strings.Contains(errStr, "404") || strings.Contains(errStr, "not found") {
-		return ErrorInvalidModel, "Model or resource not found", 404, false
-	}
-	
-	if strings.Contains(errStr, "429") || strings.Contains(errStr, "rate limit") || strings.Contains(errStr, "quota") {
-		return ErrorRateLimit, "Rate limit or quota exceeded", 429, true
-	}
-	
-	if strings.Contains(errStr, "500") || strings.Contains(errStr, "internal server error") {
-		return ErrorServerError, "Server error", 500, true
-	}
-	
-	if strings.Contains(errStr, "502") || strings.Contains(errStr, "bad gateway") {
-		return ErrorServerError, "Bad gateway", 502, true
-	}
-	
-	if strings.Contains(errStr, "503") || strings.Contains(errStr, "service unavailable") {
-		return ErrorServiceUnavailable, "Service unavailable", 503, true
-	}
-	
-	if strings.Contains(errStr, "504") || strings.Contains(errStr, "timeout") {
-		return ErrorTimeout, "Request timeout", 504, true
-	}
-	
-	// Content-related errors
-	if strings.Contains(errStr, "content filter") || strings.Contains(errStr, "safety") {
-		return ErrorContentFiltered, "Content filtered for safety", 400, false
-	}
-	
-	if strings.Contains(errStr, "token limit") || strings.Contains(errStr, "too long") {
-		return ErrorTokenLimitExceeded, "Token limit exceeded", 400, false
-	}
-	
-	// Network errors
-	if strings.Contains(errStr, "connection") || strings.Contains(errStr, "network") {
-		return ErrorNetworkError, "Network connection error", 0, true
-	}
-	
-	if strings.Contains(errStr, "dns") {
-		return ErrorDNSError, "DNS resolution error", 0, true
-	}
-	
-	// Provider-specific error handling
-	switch provider {
-	case ProviderOpenAI:
-		return classifyOpenAIError(errStr)
-	case ProviderGemini:
-		return classifyGeminiError(errStr)
-	}
-	
-	// Default to unknown error
-	return ErrorUnknown, err.Error(), 0, false
+// HTTPStatusToErrorCode maps HTTP status codes to error codes
+func HTTPStatusToErrorCode(status int) ErrorCode {
+	return providers.HTTPStatusToErrorCode(status)
 }
 
-// classifyOpenAIError handles OpenAI-specific error classification
-func classifyOpenAIError(errStr string) (ErrorCode, string, int, bool) {
-	if strings.Contains(errStr, "insufficient_quota") {
-		return ErrorQuotaExceeded, "OpenAI quota exceeded", 429, true
-	}
-	
-	if strings.Contains(errStr, "model_not_found") {
-		return ErrorInvalidModel, "OpenAI model not found", 404, false
-	}
-	
-	if strings.Contains(errStr, "invalid_request_error") {
-		return ErrorInvalidRequest, "OpenAI invalid request", 400, false
-	}
-	
-	if strings.Contains(errStr, "rate_limit_exceeded") {
-		return ErrorRateLimit, "OpenAI rate limit exceeded", 429, true
-	}
-	
-	return ErrorUnknown, errStr, 0, false
+// AllProvidersFailedError is returned when fallback exhausts every provider. It
+// wraps each provider's error, so errors.Is and errors.As see every failure.
+type AllProvidersFailedError struct {
+	Failures []*LLMError `json:"failures"`
 }
 
-// classifyGeminiError handles Gemini-specific error classification  
-func classifyGeminiError(errStr string) (ErrorCode, string, int, bool) {
-	if strings.Contains(errStr, "recitation") || strings.Contains(errStr, "blocked") {
-		return ErrorContentFiltered, "Gemini content blocked", 400, false
-	}
-	
-	if strings.Contains(errStr, "safety") {
-		return ErrorSafetyViolation, "Gemini safety violation", 400, false
-	}
-	
-	if strings.Contains(errStr, "resource_exhausted") {
-		return ErrorQuotaExceeded, "Gemini resource exhausted", 429, true
-	}
-	
-	if strings.Contains(errStr, "invalid_argument") {
-		return ErrorInvalidParameters, "Gemini invalid argument", 400, false
-	}
-	
-	return ErrorUnknown, errStr, 0, false
+// NewAllProvidersFailedError creates an error from the per-provider failures, in attempt order
+func NewAllProvidersFailedError(failures []*LLMError) *AllProvidersFailedError {
+	return &AllProvidersFailedError{Failures: failures}
 }
 
-// isRetryableErrorCode determines if an error code is retryable
-func isRetryableErrorCode(code ErrorCode) bool {
-	retryableCodes := []ErrorCode{
-		ErrorRateLimit,
-		ErrorQuotaExceeded,
-		ErrorTooManyRequests,
-		ErrorServerError,
-		ErrorServiceUnavailable,
-		ErrorTimeout,
-		ErrorNetworkError,
-		ErrorConnectionFailed,
-		ErrorDNSError,
-	}
-	
-	for _, retryable := range retryableCodes {
-		if code == retryable {
-			return true
+// Error lists why each provider failed
+func (e *AllProvidersFailedError) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		if failure.Model != "" {
+			parts = append(parts, fmt.Sprintf("%s (%s): %s", failure.Provider, failure.Model, failure.Message))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s: %s", failure.Provider, failure.Message))
 		}
 	}
-	return false
+	return fmt.Sprintf("[%s] all providers failed: %s", ErrorAllProvidersFailed, strings.Join(parts, "; "))
 }
 
-// HTTPStatusToErrorCode maps HTTP status codes to error codes
-func HTTPStatusToErrorCode(status int) ErrorCode {
-	switch status {
-	case http.StatusBadRequest:
-		return ErrorInvalidRequest
-	case http.StatusUnauthorized:
-		return ErrorInvalidAPIKey
-	case http.StatusForbidden:
-		return ErrorInvalidAuth
-	case http.StatusNotFound:
-		return ErrorInvalidModel
-	case http.StatusTooManyRequests:
-		return ErrorRateLimit
-	case http.StatusInternalServerError:
-		return ErrorServerError
-	case http.StatusBadGateway:
-		return ErrorServerError
-	case http.StatusServiceUnavailable:
-		return ErrorServiceUnavailable
-	case http.StatusGatewayTimeout:
-		return ErrorTimeout
-	default:
-		if status >= 500 {
-			return ErrorServerError
-		}
-		return ErrorUnknown
+// Unwrap returns the per-provider errors
+func (e *AllProvidersFailedError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure
 	}
+	return errs
+}
+
+// Is matches ErrAllProvidersFailed and any LLMError with the all_providers_failed code
+func (e *AllProvidersFailedError) Is(target error) bool {
+	t, ok := target.(*LLMError)
+	return ok && t.Code == ErrorAllProvidersFailed
 }
 
-// Predefined error instances for common cases
+// Sentinel errors, one per ErrorCode, shared with the providers package.
+// errors.Is matches any LLMError with the same code.
 var (
-	ErrProviderNotFound   = NewLLMError(ErrorProviderNotFound, "Provider not found", "", nil)
-	ErrProviderDisabled   = NewLLMError(ErrorProviderDisabled, "Provider is disabled", "", nil)
-	ErrAllProvidersFailed = NewLLMError(ErrorAllProvidersFailed, "All providers failed", "", nil)
-	ErrInvalidAPIKey      = NewLLMError(ErrorInvalidAPIKey, "Invalid API key", "", nil)
-	ErrInvalidRequest     = NewLLMError(ErrorInvalidRequest, "Invalid request", "", nil)
-	ErrRateLimit          = NewLLMError(ErrorRateLimit, "Rate limit exceeded", "", nil)
-	ErrServerError        = NewLLMError(ErrorServerError, "Server error", "", nil)
-	ErrTimeout            = NewLLMError(ErrorTimeout, "Request timeout", "", nil)
+	ErrInvalidAPIKey      = providers.ErrInvalidAPIKey
+	ErrInvalidAuth        = providers.ErrInvalidAuth
+	ErrAuthRequired       = providers.ErrAuthRequired
+	ErrInvalidRequest     = providers.ErrInvalidRequest
+	ErrInvalidModel       = providers.ErrInvalidModel
+	ErrInvalidParameters  = providers.ErrInvalidParameters
+	ErrRequestTooLarge    = providers.ErrRequestTooLarge
+	ErrUnsupportedFeature = providers.ErrUnsupportedFeature
+	ErrToolDisabled       = providers.ErrToolDisabled
+	ErrRateLimit          = providers.ErrRateLimit
+	ErrQuotaExceeded      = providers.ErrQuotaExceeded
+	ErrTooManyRequests    = providers.ErrTooManyRequests
+	ErrServerError        = providers.ErrServerError
+	ErrServiceUnavailable = providers.ErrServiceUnavailable
+	ErrTimeout            = providers.ErrTimeout
+	ErrInternalError      = providers.ErrInternalError
+	ErrContentFiltered    = providers.ErrContentFiltered
+	ErrSafetyViolation    = providers.ErrSafetyViolation
+	ErrTokenLimitExceeded = providers.ErrTokenLimitExceeded
+	ErrProviderNotFound   = providers.ErrProviderNotFound
+	ErrProviderDisabled   = providers.ErrProviderDisabled
+	ErrProviderSwitch     = providers.ErrProviderSwitch
+	ErrAllProvidersFailed = providers.ErrAllProvidersFailed
+	ErrBudgetExceeded     = providers.ErrBudgetExceeded
+	ErrNetworkError       = providers.ErrNetworkError
+	ErrConnectionFailed   = providers.ErrConnectionFailed
+	ErrDNSError           = providers.ErrDNSError
+	ErrValidation         = providers.ErrValidation
+	ErrMissingField       = providers.ErrMissingField
+	ErrInvalidFormat      = providers.ErrInvalidFormat
+	ErrUnknown            = providers.ErrUnknown
 )
 
 // ErrorMatcher provides utility functions for error matching
@@ -357,7 +171,8 @@ type ErrorMatcher struct{}
 
 // IsTemporary checks if an error is temporary and should be retried
 func (ErrorMatcher) IsTemporary(err error) bool {
-	if llmErr, ok := err.(*LLMError); ok {
+	var llmErr *LLMError
+	if errors.As(err, &llmErr) {
 		return llmErr.IsRetryable()
 	}
 	return false
@@ -365,7 +180,8 @@ func (ErrorMatcher) IsTemporary(err error) bool {
 
 // IsAuthError checks if an error is authentication-related
 func (ErrorMatcher) IsAuthError(err error) bool {
-	if llmErr, ok := err.(*LLMError); ok {
+	var llmErr *LLMError
+	if errors.As(err, &llmErr) {
 		return llmErr.IsAuthError()
 	}
 	return false
@@ -373,7 +189,8 @@ func (ErrorMatcher) IsAuthError(err error) bool {
 
 // IsRateLimit checks if an error is rate limit-related
 func (ErrorMatcher) IsRateLimit(err error) bool {
-	if llmErr, ok := err.(*LLMError); ok {
+	var llmErr *LLMError
+	if errors.As(err, &llmErr) {
 		return llmErr.IsRateLimit()
 	}
 	return false
@@ -381,7 +198,8 @@ func (ErrorMatcher) IsRateLimit(err error) bool {
 
 // IsContentError checks if an error is content-related
 func (ErrorMatcher) IsContentError(err error) bool {
-	if llmErr, ok := err.(*LLMError); ok {
+	var llmErr *LLMError
+	if errors.As(err, &llmErr) {
 		return llmErr.IsContentError()
 	}
 	return false
@@ -0,0 +1,100 @@
+package gomini
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+)
+
+func TestWrapProviderError_APIErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  providers.APIError
+		want ErrorCode
+	}{
+		{"openai quota", providers.APIError{StatusCode: 429, Type: "insufficient_quota", Code: "insufficient_quota"}, ErrorQuotaExceeded},
+		{"openai rate limit", providers.APIError{StatusCode: 429, Type: "requests", Code: "rate_limit_exceeded"}, ErrorRateLimit},
+		{"openai context", providers.APIError{StatusCode: 400, Type: "invalid_request_error", Code: "context_length_exceeded"}, ErrorTokenLimitExceeded},
+		{"openai bad key", providers.APIError{StatusCode: 401, Code: "invalid_api_key"}, ErrorInvalidAPIKey},
+		{"gemini bad key", providers.APIError{StatusCode: 400, Type: "INVALID_ARGUMENT", Code: "API_KEY_INVALID"}, ErrorInvalidAPIKey},
+		{"gemini exhausted", providers.APIError{StatusCode: 429, Type: "RESOURCE_EXHAUSTED"}, ErrorRateLimit},
+		{"gemini argument", providers.APIError{StatusCode: 400, Type: "INVALID_ARGUMENT", Message: "Unknown name \"foo\""}, ErrorInvalidParameters},
+		{"status only", providers.APIError{StatusCode: 503}, ErrorServiceUnavailable},
+		{"too large", providers.APIError{StatusCode: 413}, ErrorRequestTooLarge},
+		// Text that would fool string matching no longer decides the code
+		{"misleading message", providers.APIError{StatusCode: 400, Message: "rate limit docs: 429 timeout"}, ErrorInvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := tt.err
+			err := providers.WrapProviderError(&apiErr, providers.ProviderOpenAI, "gpt-4o")
+			if got := WrapProviderError(err, providers.ProviderOpenAI, "gpt-4o").Code; got != tt.want {
+				t.Errorf("Code = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapProviderError_APIErrorDetails(t *testing.T) {
+	sdkErr := fmt.Errorf("sdk failure")
+	err := WrapProviderError(&providers.APIError{
+		StatusCode: 429,
+		Type:       "tokens",
+		Code:       "rate_limit_exceeded",
+		Message:    "Rate limit reached for gpt-4o",
+		RetryAfter: 2 * time.Second,
+		RequestID:  "req_123",
+		Err:        sdkErr,
+	}, providers.ProviderOpenAI, "gpt-4o")
+
+	if err.HTTPStatus != http.StatusTooManyRequests || !err.Retryable || err.Message != "Rate limit reached for gpt-4o" {
+		t.Errorf("Unexpected error: %+v", err)
+	}
+	if err.RetryAfter == nil || *err.RetryAfter != 2*time.Second || err.RequestID != "req_123" {
+		t.Errorf("Expected the retry hint and request ID, got %v and %q", err.RetryAfter, err.RequestID)
+	}
+	if err.Details["type"] != "tokens" || err.Details["code"] != "rate_limit_exceeded" || err.Details["status_code"] != 429 {
+		t.Errorf("Unexpected details: %v", err.Details)
+	}
+	if !errors.Is(err, sdkErr) {
+		t.Error("Expected the SDK error in the chain")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"none", http.Header{}, 0},
+		{"milliseconds", http.Header{"Retry-After-Ms": {"250"}, "Retry-After": {"1"}}, 250 * time.Millisecond},
+		{"seconds", http.Header{"Retry-After": {"3"}}, 3 * time.Second},
+		{"invalid", http.Header{"Retry-After": {"soon"}}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := providers.ParseRetryAfter(tt.header); got != tt.want {
+				t.Errorf("ParseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorMatcher_WrappedProviderErrors(t *testing.T) {
+	err := fmt.Errorf("send failed: %w", providers.WrapProviderError(fmt.Errorf("429 rate limit exceeded"), providers.ProviderOpenAI, "gpt-4o"))
+
+	if !errors.Is(err, ErrRateLimit) {
+		t.Error("Expected the gomini sentinel to match a provider error")
+	}
+	if !Errors.IsRateLimit(err) || !Errors.IsTemporary(err) || Errors.IsAuthError(err) {
+		t.Errorf("Unexpected matches for %v", err)
+	}
+	if got := WrapProviderError(err, providers.ProviderOpenAI, "gpt-4o"); got.Code != ErrorRateLimit {
+		t.Errorf("Expected the provider's code to be kept, got %s", got.Code)
+	}
+}
@@ -14,9 +14,15 @@ const (
 	EventContent  EventType = "content"  // Text content chunk
 	EventThought  EventType = "thought"  // Thinking content (Gemini)
 	EventCitation EventType = "citation" // Source citation
+	EventImage    EventType = "image"    // Image generated by the model
+	EventAudio    EventType = "audio"    // Synthesized speech chunk
+
+	// Structured output events
+	EventPartialJSON EventType = "partial_json" // Best-effort parse of streamed JSON
 	
 	// Tool/Function calling events
 	EventToolCall     EventType = "tool_call"     // Assistant wants to call a tool
+	EventToolCallDelta EventType = "tool_call_delta" // Fragment of a tool call still streaming
 	EventToolResponse EventType = "tool_response" // Tool call response
 	EventToolConfirm  EventType = "tool_confirm"  // Tool call needs confirmation
 	
@@ -26,12 +32,14 @@ const (
 	EventRetry          EventType = "retry"           // Retrying request
 	EventProviderSwitch EventType = "provider_switch" // Switched to different provider
 	EventRateLimit      EventType = "rate_limit"      // Hit rate limit
+	EventKeyBenched     EventType = "key_benched"     // A pooled API key was taken out of rotation
 	EventCancel         EventType = "cancel"          // Request was cancelled
 	
 	// Loop detection and session management events
 	EventLoopDetected     EventType = "loop_detected"     // Loop detected in conversation
 	EventMaxSessionTurns  EventType = "max_session_turns" // Session turn limit reached
 	EventChatCompressed   EventType = "chat_compressed"   // Chat history was compressed
+	EventBudget           EventType = "budget"            // Session cost crossed a budget threshold
 	
 	// Meta events
 	EventUsage    EventType = "usage"    // Token usage information
@@ -48,6 +56,7 @@ type StreamEvent struct {
 	Error     error        `json:"error,omitempty"`
 	Timestamp time.Time    `json:"timestamp"`
 	RequestID string       `json:"request_id,omitempty"`
+	PromptID  string       `json:"prompt_id,omitempty"` // Prompt the event was streamed under
 	Metadata  EventMeta    `json:"metadata,omitempty"`
 }
 
@@ -57,6 +66,11 @@ type EventMeta struct {
 	FinishReason   providers.FinishReason      `json:"finish_reason,omitempty"`
 	Usage          *providers.Usage            `json:"usage,omitempty"`
 	ExtraData      map[string]interface{} `json:"extra_data,omitempty"`
+	Cached         bool                   `json:"cached,omitempty"` // Replayed from the response cache
+	Stale          bool                   `json:"stale,omitempty"`  // Replayed past the cache's soft TTL while a refresh runs
+	Safety         *providers.SafetyInfo  `json:"safety,omitempty"` // Safety ratings and block reasons on finished events
+	SystemFingerprint string              `json:"system_fingerprint,omitempty"` // Backend configuration, on finished events where the provider reports it
+	Latency        *providers.StreamLatency `json:"latency,omitempty"` // Time to first token and tokens/second, on finished events
 }
 
 // ContentEvent represents text content data
@@ -64,6 +78,7 @@ type ContentEvent struct {
 	Text     string `json:"text"`
 	Delta    bool   `json:"delta"`    // True if this is a delta (partial) update
 	Complete bool   `json:"complete"` // True if this completes the content
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"` // Log probabilities of the tokens in Text, when requested
 }
 
 // ThoughtEvent represents thinking content (Gemini-specific)
@@ -73,16 +88,37 @@ type ThoughtEvent struct {
 	Text        string `json:"text,omitempty"` // Raw thought text
 }
 
+// ImageEvent represents an image generated inline by the model
+type ImageEvent struct {
+	Image ImagePart `json:"image"`
+}
+
+// AudioChunkEvent carries a piece of synthesized speech during Client.SpeechStream
+type AudioChunkEvent struct {
+	Data     []byte `json:"data"`
+	MIMEType string `json:"mime_type"`
+	Sequence int    `json:"sequence"` // Chunks are numbered from 0 in playback order
+}
+
+// PartialJSONEvent carries the structured output parsed so far during GenerateJSONStream
+type PartialJSONEvent struct {
+	Data     interface{} `json:"data"`     // Best-effort parsed value; incomplete fields are omitted
+	Raw      string      `json:"raw"`      // Raw JSON text received so far
+	Complete bool        `json:"complete"` // True once the full document has been received and parsed
+}
+
 // CitationEvent represents source citations
 type CitationEvent struct {
 	Sources []Citation `json:"sources"`
+	Raw     []Citation `json:"raw,omitempty"` // Unprocessed sources, with include_raw_citations
 }
 
 // Citation represents a single citation
 type Citation struct {
-	Title string `json:"title,omitempty"`
-	URI   string `json:"uri"`
-	Index int    `json:"index,omitempty"`
+	Title string         `json:"title,omitempty"`
+	URI   string         `json:"uri"`
+	Index int            `json:"index,omitempty"`
+	Spans []CitationSpan `json:"spans,omitempty"` // Answer text attributed to the source
 }
 
 // ToolCallEvent represents a tool/function call request
@@ -94,6 +130,15 @@ type ToolCallEvent struct {
 	Confidence float64                `json:"confidence,omitempty"` // Confidence in the call
 }
 
+// ToolCallDeltaEvent represents a fragment of a streamed tool call. The
+// complete call follows as an EventToolCall.
+type ToolCallDeltaEvent struct {
+	Index          int    `json:"index"`                     // Position of the call in the response
+	CallID         string `json:"call_id,omitempty"`
+	ToolName       string `json:"tool_name,omitempty"`
+	ArgumentsDelta string `json:"arguments_delta,omitempty"` // Raw JSON fragment of the arguments
+}
+
 // ToolResponseEvent represents the response from a tool call
 type ToolResponseEvent struct {
 	CallID    string      `json:"call_id"`
@@ -136,6 +181,8 @@ type RetryEvent struct {
 type ProviderSwitchEvent struct {
 	FromProvider providers.ProviderType `json:"from_provider"`
 	ToProvider   providers.ProviderType `json:"to_provider"`
+	FromModel    string       `json:"from_model,omitempty"`
+	ToModel      string       `json:"to_model,omitempty"`
 	Reason       string       `json:"reason"`
 	Automatic    bool         `json:"automatic"` // True if switch was automatic
 }
@@ -149,11 +196,21 @@ type RateLimitEvent struct {
 	Limit      int           `json:"limit,omitempty"`
 }
 
+// KeyBenchedEvent reports a pooled API key taken out of rotation. KeyID is
+// the masked key; Until is zero for keys benched until the config reloads.
+type KeyBenchedEvent struct {
+	KeyID  string    `json:"key_id"`
+	Reason string    `json:"reason"`
+	Until  time.Time `json:"until,omitempty"`
+	Active int       `json:"active"` // Keys still in rotation
+}
+
 // UsageEvent represents token usage information
 type UsageEvent struct {
 	Usage       *providers.Usage  `json:"usage"`
 	Cost        float64 `json:"cost,omitempty"`        // Estimated cost in USD
 	Efficiency  float64 `json:"efficiency,omitempty"`  // Tokens per second
+	TimeToFirstToken time.Duration `json:"ttft,omitempty"` // Streams only
 	Cumulative  *providers.Usage  `json:"cumulative,omitempty"`  // Session cumulative usage
 }
 
@@ -197,6 +254,25 @@ type ChatCompressedEvent struct {
 	PromptID       string  `json:"prompt_id"`
 }
 
+// BudgetLevel is a session cost threshold
+type BudgetLevel string
+
+const (
+	BudgetLevelWarning   BudgetLevel = "warning"   // Approaching the ceiling
+	BudgetLevelDowngrade BudgetLevel = "downgrade" // Requests move to cheaper models
+	BudgetLevelExceeded  BudgetLevel = "exceeded"  // Requests are refused
+)
+
+// BudgetEvent reports session spend against its cost ceiling
+type BudgetEvent struct {
+	Level     BudgetLevel `json:"level"`
+	Spent     float64     `json:"spent"`
+	Ceiling   float64     `json:"ceiling"`
+	PromptID  string      `json:"prompt_id"`
+	FromModel string      `json:"from_model,omitempty"` // Set when a request was downgraded
+	ToModel   string      `json:"to_model,omitempty"`
+}
+
 // Helper functions for creating events
 
 // NewContentEvent creates a content event
@@ -236,6 +312,16 @@ func NewToolCallEvent(provider providers.ProviderType, model, callID, toolName s
 	}
 }
 
+// NewToolConfirmEvent creates an event asking to approve a tool call
+func NewToolConfirmEvent(provider providers.ProviderType, confirm ToolConfirmEvent) StreamEvent {
+	return StreamEvent{
+		Type:      EventToolConfirm,
+		Provider:  provider,
+		Data:      confirm,
+		Timestamp: time.Now(),
+	}
+}
+
 // NewErrorEvent creates an error event
 func NewErrorEvent(provider providers.ProviderType, model string, err error, retryable bool) StreamEvent {
 	return StreamEvent{
@@ -340,6 +426,38 @@ func NewMaxSessionTurnsEvent(provider providers.ProviderType, model string, curr
 	}
 }
 
+// NewBudgetEvent creates a session budget event
+func NewBudgetEvent(provider providers.ProviderType, model string, level BudgetLevel, spent, ceiling float64, promptID string) StreamEvent {
+	return StreamEvent{
+		Type:     EventBudget,
+		Provider: provider,
+		Model:    model,
+		Data: BudgetEvent{
+			Level:    level,
+			Spent:    spent,
+			Ceiling:  ceiling,
+			PromptID: promptID,
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// NewKeyBenchedEvent creates a key benched event
+func NewKeyBenchedEvent(provider providers.ProviderType, model, keyID, reason string, until time.Time, active int) StreamEvent {
+	return StreamEvent{
+		Type:     EventKeyBenched,
+		Provider: provider,
+		Model:    model,
+		Data: KeyBenchedEvent{
+			KeyID:  keyID,
+			Reason: reason,
+			Until:  until,
+			Active: active,
+		},
+		Timestamp: time.Now(),
+	}
+}
+
 // NewChatCompressedEvent creates a chat compressed event
 func NewChatCompressedEvent(provider providers.ProviderType, model string, originalTokens, newTokens int, promptID string) StreamEvent {
 	compressionRatio := 0.0
@@ -0,0 +1,143 @@
+// Package history trims conversations to fit a model's context window before
+// they are sent, so long sessions degrade predictably instead of failing with
+// ErrorTokenLimitExceeded. Token counts are gomini.EstimateTokens estimates.
+package history
+
+import (
+	"fmt"
+
+	"gomini/pkg/gomini"
+)
+
+// Strategy trims messages to at most budget estimated tokens. It is only
+// called when the messages do not already fit, and must not modify them.
+type Strategy interface {
+	Trim(messages []gomini.Message, budget int) ([]gomini.Message, error)
+}
+
+// StrategyFunc adapts a function to a Strategy
+type StrategyFunc func(messages []gomini.Message, budget int) ([]gomini.Message, error)
+
+// Trim calls f
+func (f StrategyFunc) Trim(messages []gomini.Message, budget int) ([]gomini.Message, error) {
+	return f(messages, budget)
+}
+
+// Fit returns messages trimmed by strategy to leave reserveOutputTokens of
+// model's context window for the reply. Messages that already fit, and models
+// without a known ContextSize, are returned unchanged; a nil strategy is
+// KeepSystemAndRecent. When the trimmed messages still do not fit, or keep
+// nothing but system prompts, the error is ErrorTokenLimitExceeded.
+func Fit(messages []gomini.Message, model gomini.Model, reserveOutputTokens int, strategy Strategy) ([]gomini.Message, error) {
+	if model.ContextSize <= 0 {
+		return messages, nil
+	}
+	budget := model.ContextSize - reserveOutputTokens
+	estimated := gomini.EstimateTokens(messages)
+	if estimated <= budget {
+		return messages, nil
+	}
+	if strategy == nil {
+		strategy = KeepSystemAndRecent()
+	}
+
+	fitted, err := strategy.Trim(messages, budget)
+	if err != nil {
+		return nil, err
+	}
+	if _, rest := splitSystem(fitted); len(rest) == 0 || gomini.EstimateTokens(fitted) > budget {
+		llmErr := gomini.NewLLMError(gomini.ErrorTokenLimitExceeded,
+			fmt.Sprintf("~%d prompt tokens cannot be trimmed to fit %d tokens of %s context", estimated, budget, model.ID), model.Provider, nil)
+		llmErr.Model = model.ID
+		return nil, llmErr
+	}
+	return fitted, nil
+}
+
+// DropOldest removes messages from the start of the conversation, system
+// prompts included, until the rest fits
+func DropOldest() Strategy {
+	return StrategyFunc(func(messages []gomini.Message, budget int) ([]gomini.Message, error) {
+		return recent(messages, budget), nil
+	})
+}
+
+// KeepSystemAndRecent keeps every system message and as many of the most
+// recent other messages as fit
+func KeepSystemAndRecent() Strategy {
+	return StrategyFunc(func(messages []gomini.Message, budget int) ([]gomini.Message, error) {
+		system, rest := splitSystem(messages)
+		tail := recent(rest, budget-gomini.EstimateTokens(system))
+		return append(system, tail...), nil
+	})
+}
+
+// Summarizer condenses messages into a short text, typically with a call to a
+// cheap model
+type Summarizer func(messages []gomini.Message) (string, error)
+
+// SummarizeMiddle keeps the system messages, the first other message (usually
+// the task) and the most recent messages that fit in half the budget, and
+// replaces everything between with a system message holding summarize's
+// summary. Recent messages are dropped further if the summary leaves too
+// little room.
+func SummarizeMiddle(summarize Summarizer) Strategy {
+	return StrategyFunc(func(messages []gomini.Message, budget int) ([]gomini.Message, error) {
+		system, rest := splitSystem(messages)
+		if len(rest) < 3 {
+			return KeepSystemAndRecent().Trim(messages, budget)
+		}
+		head := append(system, rest[0])
+		tail := recent(rest[1:], (budget-gomini.EstimateTokens(head))/2)
+		middle := rest[1 : len(rest)-len(tail)]
+		if len(middle) == 0 {
+			return KeepSystemAndRecent().Trim(messages, budget)
+		}
+
+		summary, err := summarize(middle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize %d messages: %w", len(middle), err)
+		}
+		head = append(head, gomini.NewSystemMessage("Summary of the earlier conversation: "+summary))
+		return append(head, recent(tail, budget-gomini.EstimateTokens(head))...), nil
+	})
+}
+
+// splitSystem separates system messages from the others, keeping order
+func splitSystem(messages []gomini.Message) (system, rest []gomini.Message) {
+	for _, msg := range messages {
+		if role(msg) == "system" {
+			system = append(system, msg)
+		} else {
+			rest = append(rest, msg)
+		}
+	}
+	return system, rest
+}
+
+// recent returns the longest suffix of messages within budget tokens. Tool
+// results whose calling assistant message was cut are dropped too, since
+// providers reject them.
+func recent(messages []gomini.Message, budget int) []gomini.Message {
+	start, used := len(messages), 0
+	for start > 0 {
+		tokens := gomini.EstimateMessageTokens(messages[start-1])
+		if used+tokens > budget {
+			break
+		}
+		used += tokens
+		start--
+	}
+	for start < len(messages) && role(messages[start]) == "tool" {
+		start++
+	}
+	return append([]gomini.Message(nil), messages[start:]...)
+}
+
+func role(msg gomini.Message) string {
+	if msgMap, ok := msg.(map[string]interface{}); ok {
+		role, _ := msgMap["role"].(string)
+		return role
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini"
+)
+
+// turn is a message of about 31 estimated tokens
+func turn(role string, i int) gomini.Message {
+	return map[string]interface{}{"role": role, "content": fmt.Sprintf("%s message %d %s", role, i, strings.Repeat("x", 90))}
+}
+
+func contents(messages []gomini.Message) []string {
+	var out []string
+	for _, msg := range messages {
+		content, _ := msg.(map[string]interface{})["content"].(string)
+		out = append(out, strings.Fields(content)[0]+" "+strings.Fields(content)[len(strings.Fields(content))-2])
+	}
+	return out
+}
+
+func TestFit(t *testing.T) {
+	conversation := []gomini.Message{
+		turn("system", 0),
+		turn("user", 1),
+		turn("assistant", 2),
+		turn("user", 3),
+		turn("assistant", 4),
+		turn("user", 5),
+	}
+	model := gomini.Model{ID: "small", ContextSize: 130}
+
+	tests := []struct {
+		name     string
+		strategy Strategy
+		reserve  int
+		want     []string
+	}{
+		{name: "fits unchanged", reserve: -1000, want: []string{"system 0", "user 1", "assistant 2", "user 3", "assistant 4", "user 5"}},
+		{name: "drop oldest", strategy: DropOldest(), reserve: 30, want: []string{"user 3", "assistant 4", "user 5"}},
+		{name: "keep system and recent", strategy: KeepSystemAndRecent(), reserve: 30, want: []string{"system 0", "assistant 4", "user 5"}},
+		{name: "default strategy", reserve: 30, want: []string{"system 0", "assistant 4", "user 5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Fit(conversation, model, tt.reserve, tt.strategy)
+			if err != nil {
+				t.Fatalf("Fit failed: %v", err)
+			}
+			if !reflect.DeepEqual(contents(got), tt.want) {
+				t.Errorf("Fit() = %v, want %v", contents(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestFit_SummarizeMiddle(t *testing.T) {
+	var conversation []gomini.Message
+	conversation = append(conversation, turn("system", 0))
+	for i := 1; i <= 8; i++ {
+		role := "user"
+		if i%2 == 0 {
+			role = "assistant"
+		}
+		conversation = append(conversation, turn(role, i))
+	}
+
+	var summarized int
+	strategy := SummarizeMiddle(func(messages []gomini.Message) (string, error) {
+		summarized = len(messages)
+		return "they talked", nil
+	})
+	got, err := Fit(conversation, gomini.Model{ID: "small", ContextSize: 200}, 0, strategy)
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+
+	if len(got) < 4 || !reflect.DeepEqual(got[:2], conversation[:2]) {
+		t.Fatalf("Expected the system prompt and task to stay first, got %v", got)
+	}
+	if !reflect.DeepEqual(got[2], gomini.NewSystemMessage("Summary of the earlier conversation: they talked")) {
+		t.Errorf("Expected the summary after the task, got %v", got[2])
+	}
+	if !reflect.DeepEqual(got[len(got)-1], conversation[len(conversation)-1]) {
+		t.Errorf("Expected the latest message to be kept, got %v", got[len(got)-1])
+	}
+	if kept := len(got) - 3; summarized+kept != 7 {
+		t.Errorf("Expected every middle message to be summarized or kept, summarized %d and kept %d", summarized, kept)
+	}
+	if gomini.EstimateTokens(got) > 200 {
+		t.Errorf("Expected the result to fit, got ~%d tokens", gomini.EstimateTokens(got))
+	}
+}
+
+func TestFit_DropsOrphanedToolResults(t *testing.T) {
+	conversation := []gomini.Message{
+		turn("user", 1),
+		gomini.NewAssistantToolCallMessage(strings.Repeat("x", 200), nil),
+		gomini.NewToolResultMessage("call_1", "lookup", "42"),
+		turn("assistant", 2),
+	}
+	got, err := Fit(conversation, gomini.Model{ContextSize: 50}, 0, DropOldest())
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, conversation[3:]) {
+		t.Errorf("Expected the tool result to go with its call, got %v", got)
+	}
+}
+
+func TestFit_TooLarge(t *testing.T) {
+	conversation := []gomini.Message{turn("system", 0), turn("user", 1)}
+	_, err := Fit(conversation, gomini.Model{ID: "tiny", ContextSize: 40}, 0, KeepSystemAndRecent())
+	if !errors.Is(err, gomini.ErrTokenLimitExceeded) {
+		t.Errorf("Expected ErrTokenLimitExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+// Package httpstream serves gomini streams to HTTP clients as Server-Sent Events
+package httpstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gomini/pkg/gomini"
+)
+
+// DefaultHeartbeat is how often a keep-alive comment is sent while the stream
+// is quiet, so proxies do not close the idle connection
+const DefaultHeartbeat = 15 * time.Second
+
+// Options controls WriteSSEWithOptions
+type Options struct {
+	Heartbeat time.Duration   // Interval between keep-alives; 0 uses DefaultHeartbeat, negative disables them
+	Done      <-chan struct{} // Closed when the client goes away, e.g. r.Context().Done()
+}
+
+// sseEvent is the JSON payload of one SSE message. Errors are sent as text
+// since error values do not marshal.
+type sseEvent struct {
+	Type      gomini.EventType    `json:"type"`
+	Provider  gomini.ProviderType `json:"provider,omitempty"`
+	Model     string              `json:"model,omitempty"`
+	Data      interface{}         `json:"data,omitempty"`
+	Error     string              `json:"error,omitempty"`
+	Timestamp time.Time           `json:"timestamp"`
+	RequestID string              `json:"request_id,omitempty"`
+	PromptID  string              `json:"prompt_id,omitempty"`
+	Metadata  gomini.EventMeta    `json:"metadata"`
+}
+
+// WriteSSE writes every event of ch to w as Server-Sent Events until ch
+// closes. If the client cannot be written to, the rest of ch is drained in
+// the background and the write error is returned.
+func WriteSSE(w http.ResponseWriter, ch <-chan gomini.StreamEvent) error {
+	return WriteSSEWithOptions(w, ch, Options{})
+}
+
+// WriteSSEWithOptions is WriteSSE with a heartbeat interval and a channel
+// signalling that the client disconnected
+func WriteSSEWithOptions(w http.ResponseWriter, ch <-chan gomini.StreamEvent, opts Options) error {
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // Disable nginx response buffering
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	flush := func() error {
+		if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+	if err := flush(); err != nil {
+		drain(ch)
+		return err
+	}
+
+	var heartbeat <-chan time.Time
+	interval := opts.Heartbeat
+	if interval == 0 {
+		interval = DefaultHeartbeat
+	}
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	id := 0
+	for {
+		var err error
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				select {
+				case <-opts.Done: // The stream ended because the client left
+					return context.Canceled
+				default:
+					return nil
+				}
+			}
+			id++
+			err = writeEvent(w, id, event)
+		case <-heartbeat:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+		case <-opts.Done:
+			drain(ch)
+			return context.Canceled
+		}
+		if err == nil {
+			err = flush()
+		}
+		if err != nil {
+			drain(ch)
+			return err
+		}
+	}
+}
+
+// Serve streams the events produced by start to the client of r. start is
+// given a context that is cancelled when the client disconnects, so the
+// upstream request stops with it.
+func Serve(w http.ResponseWriter, r *http.Request, start func(ctx context.Context) <-chan gomini.StreamEvent) error {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	return WriteSSEWithOptions(w, start(ctx), Options{Done: ctx.Done()})
+}
+
+// writeEvent writes one SSE message named after the event type
+func writeEvent(w http.ResponseWriter, id int, event gomini.StreamEvent) error {
+	payload := sseEvent{
+		Type:      event.Type,
+		Provider:  event.Provider,
+		Model:     event.Model,
+		Data:      event.Data,
+		Timestamp: event.Timestamp,
+		RequestID: event.RequestID,
+		PromptID:  event.PromptID,
+		Metadata:  event.Metadata,
+	}
+	if event.Error != nil {
+		payload.Error = event.Error.Error()
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", event.Type, err)
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event.Type, data)
+	return err
+}
+
+// drain consumes the rest of ch so its producer can finish
+func drain(ch <-chan gomini.StreamEvent) {
+	go func() {
+		for range ch {
+		}
+	}()
+}
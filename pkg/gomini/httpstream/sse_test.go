@@ -0,0 +1,116 @@
+package httpstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini"
+	"gomini/pkg/gomini/providers"
+)
+
+func streamOf(events ...gomini.StreamEvent) <-chan gomini.StreamEvent {
+	ch := make(chan gomini.StreamEvent, len(events))
+	for _, event := range events {
+		ch <- event
+	}
+	close(ch)
+	return ch
+}
+
+func TestWriteSSE(t *testing.T) {
+	tests := []struct {
+		name     string
+		events   []gomini.StreamEvent
+		contains []string
+	}{
+		{
+			name: "content and finished events",
+			events: []gomini.StreamEvent{
+				{Type: gomini.EventContent, Model: "m", Data: gomini.ContentEvent{Text: "hi", Delta: true}},
+				{Type: gomini.EventFinished, Metadata: gomini.EventMeta{FinishReason: providers.FinishReasonStop}},
+			},
+			contains: []string{
+				"id: 1\nevent: content\ndata: {\"type\":\"content\",\"model\":\"m\",\"data\":{\"text\":\"hi\",\"delta\":true,\"complete\":false}",
+				"id: 2\nevent: finished\ndata: ",
+				"\"finish_reason\":\"stop\"",
+			},
+		},
+		{
+			name: "error is sent as text",
+			events: []gomini.StreamEvent{
+				{Type: gomini.EventError, Error: errors.New("upstream failed")},
+			},
+			contains: []string{"event: error\n", "\"error\":\"upstream failed\""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if err := WriteSSE(rec, streamOf(tt.events...)); err != nil {
+				t.Fatalf("WriteSSE() error = %v", err)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if !rec.Flushed {
+				t.Error("response was not flushed")
+			}
+			body := rec.Body.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("body missing %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteSSE_Heartbeat(t *testing.T) {
+	ch := make(chan gomini.StreamEvent)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(ch)
+	}()
+
+	rec := httptest.NewRecorder()
+	if err := WriteSSEWithOptions(rec, ch, Options{Heartbeat: 10 * time.Millisecond}); err != nil {
+		t.Fatalf("WriteSSEWithOptions() error = %v", err)
+	}
+	if !strings.Contains(rec.Body.String(), ": keep-alive\n\n") {
+		t.Errorf("no heartbeat in body: %q", rec.Body.String())
+	}
+}
+
+func TestServe_ClientDisconnect(t *testing.T) {
+	reqCtx, disconnect := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/chat", nil).WithContext(reqCtx)
+
+	upstreamDone := make(chan struct{})
+	start := func(ctx context.Context) <-chan gomini.StreamEvent {
+		ch := make(chan gomini.StreamEvent)
+		go func() {
+			defer close(ch)
+			defer close(upstreamDone)
+			ch <- gomini.StreamEvent{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "a"}}
+			disconnect()
+			<-ctx.Done()
+		}()
+		return ch
+	}
+
+	err := Serve(httptest.NewRecorder(), req, start)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Serve() error = %v, want context.Canceled", err)
+	}
+	select {
+	case <-upstreamDone:
+	case <-time.After(time.Second):
+		t.Fatal("upstream stream was not cancelled")
+	}
+}
@@ -0,0 +1,87 @@
+package gomini
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// NewTextPart creates a text content part for multi-part messages
+func NewTextPart(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "text",
+		"data": map[string]interface{}{"text": text},
+	}
+}
+
+// NewImageURLPart creates an image content part referencing a remote URL
+func NewImageURLPart(imageURL string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "image_url",
+		"data": map[string]interface{}{"url": imageURL},
+	}
+}
+
+// NewImageURLPartWithDetail creates a remote image part with a vision detail
+// level ("low", "high" or "auto"); providers without detail control ignore it
+func NewImageURLPartWithDetail(imageURL, detail string) map[string]interface{} {
+	part := NewImageURLPart(imageURL)
+	part["data"].(map[string]interface{})["detail"] = detail
+	return part
+}
+
+// NewImageDataPart creates an image content part carrying inline image bytes
+func NewImageDataPart(mimeType string, data []byte) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "image_url",
+		"data": map[string]interface{}{
+			"base64":    base64.StdEncoding.EncodeToString(data),
+			"mime_type": mimeType,
+		},
+	}
+}
+
+// NewImageMessageFromURL creates a user message with text and a remote image
+func NewImageMessageFromURL(text, imageURL string) Message {
+	return newImageMessage(text, NewImageURLPart(imageURL))
+}
+
+// NewImageMessageFromReader creates a user message with text and an image read from r.
+// An empty mimeType is detected from the image content.
+func NewImageMessageFromReader(text string, r io.Reader, mimeType string) (Message, error) {
+	mimeType, data, err := providers.ReadImage(r, mimeType, providers.DefaultImageLimits())
+	if err != nil {
+		return nil, err
+	}
+	return newImageMessage(text, NewImageDataPart(mimeType, data)), nil
+}
+
+// NewImageMessageFromFile creates a user message with text and an image loaded from path
+func NewImageMessageFromFile(text, path string) (Message, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	defer file.Close()
+
+	mimeType, _, _ := mime.ParseMediaType(mime.TypeByExtension(filepath.Ext(path)))
+	return NewImageMessageFromReader(text, file, mimeType)
+}
+
+func newImageMessage(text string, image map[string]interface{}) Message {
+	parts := make([]interface{}, 0, 2)
+	if text != "" {
+		parts = append(parts, NewTextPart(text))
+	}
+	parts = append(parts, image)
+
+	return map[string]interface{}{
+		"role":    "user",
+		"content": parts,
+	}
+}
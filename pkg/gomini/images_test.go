@@ -0,0 +1,70 @@
+package gomini
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func imageData(t *testing.T, msg Message) map[string]interface{} {
+	t.Helper()
+	parts, ok := msg.(map[string]interface{})["content"].([]interface{})
+	if !ok || len(parts) != 2 {
+		t.Fatalf("expected text and image parts, got %v", msg)
+	}
+	image := parts[1].(map[string]interface{})
+	if image["type"] != "image_url" {
+		t.Fatalf("expected image_url part, got %v", image["type"])
+	}
+	return image["data"].(map[string]interface{})
+}
+
+func TestNewImageMessageFromURL(t *testing.T) {
+	msg := NewImageMessageFromURL("what is this?", "https://example.com/cat.png")
+	if role := msg.(map[string]interface{})["role"]; role != "user" {
+		t.Errorf("expected user role, got %v", role)
+	}
+	if url := imageData(t, msg)["url"]; url != "https://example.com/cat.png" {
+		t.Errorf("unexpected url %v", url)
+	}
+}
+
+func TestNewImageMessageFromReader(t *testing.T) {
+	msg, err := NewImageMessageFromReader("what is this?", bytes.NewReader(testPNG), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := imageData(t, msg)
+	if data["mime_type"] != "image/png" {
+		t.Errorf("expected sniffed image/png, got %v", data["mime_type"])
+	}
+	if data["base64"] == "" {
+		t.Error("expected base64 image data")
+	}
+
+	if _, err := NewImageMessageFromReader("", bytes.NewReader([]byte("just text")), ""); err == nil {
+		t.Error("expected error for non-image content")
+	}
+}
+
+func TestNewImageMessageFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cat.png")
+	if err := os.WriteFile(path, testPNG, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := NewImageMessageFromFile("what is this?", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mimeType := imageData(t, msg)["mime_type"]; mimeType != "image/png" {
+		t.Errorf("expected image/png, got %v", mimeType)
+	}
+
+	if _, err := NewImageMessageFromFile("", filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,157 @@
+package gomini
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Container parse states for ParsePartialJSON
+const (
+	partialExpectKey = iota
+	partialExpectColon
+	partialExpectValue
+	partialAfterValue
+)
+
+type partialFrame struct {
+	closer byte
+	state  int
+}
+
+// ParsePartialJSON parses a possibly truncated JSON document, as produced mid-stream,
+// by cutting it back to the last complete value and closing any open containers.
+// An unterminated string value is kept and closed. Leading prose or a markdown code
+// fence before the first '{' or '[' is ignored. It returns false when nothing usable
+// has arrived yet.
+func ParsePartialJSON(text string) (interface{}, bool) {
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return nil, false
+	}
+	text = text[start:]
+
+	var (
+		stack       []partialFrame
+		inString    bool
+		isKey       bool
+		escaped     bool
+		stringStart int
+		safeEnd     = -1
+		safeClosers string
+	)
+
+	closers := func() string {
+		var b strings.Builder
+		for i := len(stack) - 1; i >= 0; i-- {
+			b.WriteByte(stack[i].closer)
+		}
+		return b.String()
+	}
+
+	// valueCompleted records a position where the document can be cut and closed
+	valueCompleted := func(end int) {
+		if len(stack) > 0 {
+			stack[len(stack)-1].state = partialAfterValue
+		}
+		safeEnd = end
+		safeClosers = closers()
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+				if isKey {
+					stack[len(stack)-1].state = partialExpectColon
+				} else {
+					valueCompleted(i + 1)
+				}
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\n', '\r':
+		case '{':
+			stack = append(stack, partialFrame{closer: '}', state: partialExpectKey})
+			safeEnd, safeClosers = i+1, closers()
+		case '[':
+			stack = append(stack, partialFrame{closer: ']', state: partialExpectValue})
+			safeEnd, safeClosers = i+1, closers()
+		case '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1].closer != c {
+				return nil, false
+			}
+			stack = stack[:len(stack)-1]
+			valueCompleted(i + 1)
+			if len(stack) == 0 {
+				// Ignore anything after the top-level value, such as a closing fence
+				return decodePartial(text[:i+1])
+			}
+		case '"':
+			inString = true
+			isKey = len(stack) > 0 && stack[len(stack)-1].closer == '}' && stack[len(stack)-1].state == partialExpectKey
+			stringStart = i
+		case ':':
+			if len(stack) > 0 {
+				stack[len(stack)-1].state = partialExpectValue
+			}
+		case ',':
+			if len(stack) > 0 {
+				if stack[len(stack)-1].closer == '}' {
+					stack[len(stack)-1].state = partialExpectKey
+				} else {
+					stack[len(stack)-1].state = partialExpectValue
+				}
+			}
+		default:
+			// Numbers and literals run until the next delimiter
+			end := i
+			for end < len(text) && !strings.ContainsRune(" \t\n\r,:]}", rune(text[end])) {
+				end++
+			}
+			token := text[i:end]
+			if end < len(text) || json.Valid([]byte(token)) {
+				if !json.Valid([]byte(token)) {
+					return nil, false
+				}
+				valueCompleted(end)
+			}
+			i = end - 1
+		}
+	}
+
+	// Close an unterminated string value so partially streamed text is visible
+	if inString && !isKey {
+		partial := text
+		if escaped {
+			partial = partial[:len(partial)-1]
+		}
+		if idx := strings.LastIndex(partial, `\u`); idx > stringStart && len(partial)-idx < 6 {
+			partial = partial[:idx]
+		}
+		if value, ok := decodePartial(partial + `"` + closers()); ok {
+			return value, true
+		}
+	}
+
+	if safeEnd < 0 {
+		return nil, false
+	}
+	return decodePartial(text[:safeEnd] + safeClosers)
+}
+
+func decodePartial(candidate string) (interface{}, bool) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(candidate), &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
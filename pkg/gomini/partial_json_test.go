@@ -0,0 +1,51 @@
+package gomini
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePartialJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+		ok       bool
+	}{
+		{"nothing yet", ``, nil, false},
+		{"prose only", `Here is`, nil, false},
+		{"open object", `{`, map[string]interface{}{}, true},
+		{"dangling key", `{"name`, map[string]interface{}{}, true},
+		{"key without value", `{"name":`, map[string]interface{}{}, true},
+		{"partial string value", `{"name": "Ada Lov`, map[string]interface{}{"name": "Ada Lov"}, true},
+		{"partial escape", `{"name": "a\`, map[string]interface{}{"name": "a"}, true},
+		{"partial unicode escape", `{"name": "a\u00`, map[string]interface{}{"name": "a"}, true},
+		{"complete field then comma", `{"age": 36,`, map[string]interface{}{"age": 36.0}, true},
+		{"partial literal", `{"ok": tr`, map[string]interface{}{}, true},
+		{"partial number kept", `{"n": 12`, map[string]interface{}{"n": 12.0}, true},
+		{
+			"nested containers",
+			`{"items": [{"id": 1}, {"id": 2, "tags": ["a", "b`,
+			map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"id": 1.0},
+				map[string]interface{}{"id": 2.0, "tags": []interface{}{"a", "b"}},
+			}},
+			true,
+		},
+		{"fenced complete", "```json\n{\"a\": 1}\n```", map[string]interface{}{"a": 1.0}, true},
+		{"top-level array", `[1, 2, `, []interface{}{1.0, 2.0}, true},
+		{"mismatched brackets", `{"a": ]`, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := ParsePartialJSON(tt.input)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v (value %v)", tt.ok, ok, value)
+			}
+			if !reflect.DeepEqual(value, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, value)
+			}
+		})
+	}
+}
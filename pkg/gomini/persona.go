@@ -0,0 +1,17 @@
+package gomini
+
+import (
+	"gomini/pkg/gomini/providers"
+)
+
+// Persona is a named request preset, selected per request with
+// ChatRequest.Persona, so products with several assistants share one client.
+// Its model, provider and config are defaults the request overrides.
+type Persona struct {
+	SystemPrompt string                 `json:"system_prompt,omitempty"` // Sent ahead of the request's messages
+	Model        string                 `json:"model,omitempty"`
+	Provider     providers.ProviderType `json:"provider,omitempty"`
+	Temperature  *float64               `json:"temperature,omitempty"`
+	Config       map[string]interface{} `json:"config,omitempty"`        // Other request config defaults
+	AllowedTools []string               `json:"allowed_tools,omitempty"` // Tools the persona may use; empty allows all
+}
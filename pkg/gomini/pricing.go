@@ -0,0 +1,45 @@
+package gomini
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// maxPriceTableSize bounds remote price tables
+const maxPriceTableSize = 4 << 20
+
+// LoadPriceTable loads a price table from a file path or an http(s) URL
+func LoadPriceTable(ctx context.Context, source string) (*PriceTable, error) {
+	var data []byte
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch price table: %w", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch price table: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch price table: %s returned %s", source, resp.Status)
+		}
+		data, err = io.ReadAll(io.LimitReader(resp.Body, maxPriceTableSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch price table: %w", err)
+		}
+	} else {
+		var err error
+		data, err = os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read price table: %w", err)
+		}
+	}
+	return providers.ParsePriceTable(data)
+}
@@ -0,0 +1,220 @@
+package gomini
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// Profile holds per-environment overrides (e.g. dev, staging, prod) applied on
+// top of the base provider configuration
+type Profile struct {
+	Providers map[providers.ProviderType]*ProviderProfile `json:"providers,omitempty"`
+}
+
+// ProviderProfile overrides connection settings for one provider. Empty fields
+// keep the base value; ExtraHeaders are merged over the base headers.
+type ProviderProfile struct {
+	Enabled      *bool             `json:"enabled,omitempty"`
+	APIKey       string            `json:"api_key,omitempty"`
+	APIKeyEnv    string            `json:"api_key_env,omitempty"` // Read the key from this variable instead of the file
+	Endpoint     string            `json:"endpoint,omitempty"`
+	DefaultModel string            `json:"default_model,omitempty"`
+	ExtraHeaders map[string]string `json:"extra_headers,omitempty"`
+}
+
+// LoadFromFile loads a JSON configuration file over the current settings and
+// records it as ConfigFile. Durations may be written as strings such as
+// "30s" or "1m30s"; plain numbers are nanoseconds, as encoding/json reads
+// time.Duration.
+func (c *Config) LoadFromFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	document, err = parseDurations(document, reflect.TypeOf(c).Elem())
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if raw, err = json.Marshal(document); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(raw, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	c.ConfigFile = path
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// parseDurations replaces the duration strings in a decoded JSON document
+// with the nanosecond counts the time.Duration fields of t decode from
+func parseDurations(value interface{}, t reflect.Type) (interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == durationType:
+		text, ok := value.(string)
+		if !ok {
+			return value, nil
+		}
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return nil, err
+		}
+		return int64(d), nil
+	case t.Kind() == reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		for key, item := range object {
+			field, ok := jsonField(t, key)
+			if !ok {
+				continue
+			}
+			parsed, err := parseDurations(item, field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			object[key] = parsed
+		}
+		return object, nil
+	case t.Kind() == reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		for key, item := range object {
+			parsed, err := parseDurations(item, t.Elem())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			object[key] = parsed
+		}
+		return object, nil
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return value, nil
+		}
+		for i, item := range items {
+			parsed, err := parseDurations(item, t.Elem())
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i, err)
+			}
+			items[i] = parsed
+		}
+		return items, nil
+	}
+	return value, nil
+}
+
+// jsonField finds the field of struct type t that encoding/json decodes key
+// into, matching names case-insensitively as it does
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := field.Name
+		if tag, _, _ := strings.Cut(field.Tag.Get("json"), ","); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// ApplyProfile applies the named profile's overrides and records it as the
+// active profile. Providers that only appear in the profile are added enabled.
+func (c *Config) ApplyProfile(name string) error {
+	profile, exists := c.Profiles[name]
+	if !exists {
+		return fmt.Errorf("unknown profile %q (available: %v)", name, c.ProfileNames())
+	}
+
+	if c.Providers == nil {
+		c.Providers = make(map[providers.ProviderType]*ProviderConfig)
+	}
+	for providerType, override := range profile.Providers {
+		if override == nil {
+			continue
+		}
+		pc, exists := c.Providers[providerType]
+		if !exists {
+			pc = &ProviderConfig{Enabled: true}
+			c.Providers[providerType] = pc
+		}
+		if err := override.apply(pc); err != nil {
+			return fmt.Errorf("profile %s: %s: %w", name, providerType, err)
+		}
+	}
+
+	c.Profile = name
+	return nil
+}
+
+// ProfileNames returns the configured profile names in sorted order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply copies the non-empty overrides onto a provider config
+func (o *ProviderProfile) apply(pc *ProviderConfig) error {
+	if o.Enabled != nil {
+		pc.Enabled = *o.Enabled
+	}
+	if o.APIKeyEnv != "" {
+		key := os.Getenv(o.APIKeyEnv)
+		if key == "" {
+			return fmt.Errorf("environment variable %s is not set", o.APIKeyEnv)
+		}
+		pc.APIKey = key
+	} else if o.APIKey != "" {
+		pc.APIKey = o.APIKey
+	}
+	if o.Endpoint != "" {
+		pc.Endpoint = o.Endpoint
+	}
+	if o.DefaultModel != "" {
+		pc.DefaultModel = o.DefaultModel
+	}
+	if len(o.ExtraHeaders) > 0 {
+		headers := make(map[string]string, len(pc.ExtraHeaders)+len(o.ExtraHeaders))
+		for key, value := range pc.ExtraHeaders {
+			headers[key] = value
+		}
+		for key, value := range o.ExtraHeaders {
+			headers[key] = value
+		}
+		pc.ExtraHeaders = headers
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package gomini
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const profileConfig = `{
+	"providers": {
+		"openai": {"enabled": true, "api_key": "base-key", "extra_headers": {"X-Team": "core"}}
+	},
+	"profiles": {
+		"staging": {
+			"providers": {
+				"openai": {"endpoint": "https://staging.example/v1", "api_key_env": "STAGING_OPENAI_KEY", "extra_headers": {"X-Env": "staging"}},
+				"gemini": {"api_key": "gemini-staging"}
+			}
+		},
+		"prod": {}
+	}
+}`
+
+func TestConfig_LoadFromEnvProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gomini.json")
+	if err := os.WriteFile(path, []byte(profileConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+	t.Setenv("GOMINI_CONFIG", path)
+	t.Setenv("GOMINI_PROFILE", "staging")
+	t.Setenv("STAGING_OPENAI_KEY", "staging-key")
+
+	config := NewConfig()
+	if err := config.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+
+	openai := config.Providers[ProviderOpenAI]
+	if openai.APIKey != "staging-key" || openai.Endpoint != "https://staging.example/v1" {
+		t.Errorf("Expected staging overrides, got %+v", openai)
+	}
+	if openai.ExtraHeaders["X-Team"] != "core" || openai.ExtraHeaders["X-Env"] != "staging" {
+		t.Errorf("Expected merged headers, got %v", openai.ExtraHeaders)
+	}
+	if gemini := config.Providers[ProviderGemini]; gemini == nil || !gemini.Enabled || gemini.APIKey != "gemini-staging" {
+		t.Errorf("Expected profile to add gemini, got %+v", gemini)
+	}
+	if config.Profile != "staging" {
+		t.Errorf("Expected active profile staging, got %q", config.Profile)
+	}
+}
+
+func TestConfig_ApplyProfileErrors(t *testing.T) {
+	config := NewConfig()
+	config.Profiles = map[string]*Profile{
+		"dev": {Providers: map[ProviderType]*ProviderProfile{
+			ProviderOpenAI: {APIKeyEnv: "GOMINI_TEST_MISSING_KEY"},
+		}},
+	}
+	t.Setenv("GOMINI_TEST_MISSING_KEY", "")
+
+	if err := config.ApplyProfile("qa"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+	if err := config.ApplyProfile("dev"); err == nil {
+		t.Error("Expected error for unset key variable")
+	}
+}
+
+func TestConfig_LoadFromFileDurations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gomini.json")
+	raw := `{
+		"request_timeout": "30s",
+		"retry_delay": 1500000000,
+		"providers": {"openai": {"enabled": true, "key_cooldown": "1m30s"}},
+		"router": {"latency_target": {"percentile": 0.95, "below": "2s"}}
+	}`
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := NewConfig()
+	if err := config.LoadFromFile(path); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if config.RequestTimeout != 30*time.Second || config.RetryDelay != 1500*time.Millisecond {
+		t.Errorf("Expected 30s and 1.5s, got %v and %v", config.RequestTimeout, config.RetryDelay)
+	}
+	if cooldown := config.Providers[ProviderOpenAI].KeyCooldown; cooldown != 90*time.Second {
+		t.Errorf("Expected nested duration 1m30s, got %v", cooldown)
+	}
+	if below := config.Router.LatencyTarget.Below; below != 2*time.Second {
+		t.Errorf("Expected latency target 2s, got %v", below)
+	}
+	if config.ConfigFile != path {
+		t.Errorf("Expected the file to be recorded, got %q", config.ConfigFile)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"request_timeout": "soon"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewConfig().LoadFromFile(path); err == nil || !strings.Contains(err.Error(), "request_timeout") {
+		t.Errorf("Expected an error naming the invalid duration, got %v", err)
+	}
+}
+
+func TestConfig_LoadFromEnvKeepsLoadedFile(t *testing.T) {
+	dir := t.TempDir()
+	loaded := filepath.Join(dir, "loaded.json")
+	fromEnv := filepath.Join(dir, "env.json")
+	if err := os.WriteFile(loaded, []byte(`{"request_timeout": "10s"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fromEnv, []byte(`{"request_timeout": "20s"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOMINI_CONFIG", fromEnv)
+	t.Setenv("GOMINI_PROFILE", "")
+
+	config := NewConfig()
+	if err := config.LoadFromFile(loaded); err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if err := config.LoadFromEnv(); err != nil {
+		t.Fatalf("LoadFromEnv failed: %v", err)
+	}
+	if config.RequestTimeout != 10*time.Second || config.ConfigFile != loaded {
+		t.Errorf("Expected GOMINI_CONFIG not to override %s, got %v from %s", loaded, config.RequestTimeout, config.ConfigFile)
+	}
+}
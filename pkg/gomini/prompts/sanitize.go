@@ -0,0 +1,178 @@
+package prompts
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SanitizeOptions controls how user-supplied variables are normalized before
+// they are interpolated into a prompt template
+type SanitizeOptions struct {
+	// StripControlTokens removes chat-template control tokens (<|im_start|>, [INST], <<SYS>>, ...)
+	// that could be used to forge role boundaries inside interpolated content
+	StripControlTokens bool `json:"strip_control_tokens"`
+
+	// MaxLength limits the length of a single variable in runes after escaping (0 = unlimited)
+	MaxLength int `json:"max_length,omitempty"`
+
+	// TruncationMarker is appended when a value is cut to MaxLength
+	TruncationMarker string `json:"truncation_marker,omitempty"`
+
+	// EscapeHTML escapes <, >, &, ' and " so content cannot open HTML/XML-style tags
+	EscapeHTML bool `json:"escape_html,omitempty"`
+
+	// EscapeMarkdown neutralizes markdown structure (code fences, headings, links)
+	EscapeMarkdown bool `json:"escape_markdown,omitempty"`
+}
+
+// DefaultSanitizeOptions returns the options used when a template does not override them
+func DefaultSanitizeOptions() SanitizeOptions {
+	return SanitizeOptions{
+		StripControlTokens: true,
+		MaxLength:          8000,
+		TruncationMarker:   "…",
+	}
+}
+
+// Control token patterns used by common chat templates
+var controlTokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`<\|[a-zA-Z0-9_\-]{1,32}\|>`),                                                   // ChatML / OpenAI: <|im_start|>, <|endoftext|>
+	regexp.MustCompile(`(?i)\[/?INST\]`),                                                               // Llama 2 / Mistral
+	regexp.MustCompile(`(?i)<</?SYS>>`),                                                                // Llama 2 system block
+	regexp.MustCompile(`(?i)</?(start_of_turn|end_of_turn|bos|eos)>`),                                  // Gemma
+	regexp.MustCompile(`(?i)</?s>`),                                                                    // Sentencepiece BOS/EOS
+	regexp.MustCompile(`(?i)<\|?(begin_of_text|end_of_text|start_header_id|end_header_id|eot_id)\|?>`), // Llama 3
+}
+
+// Markdown characters that start structural elements
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"`", "\\`",
+	"*", "\\*",
+	"_", "\\_",
+	"#", "\\#",
+	"[", "\\[",
+	"]", "\\]",
+	">", "\\>",
+	"|", "\\|",
+)
+
+// SanitizeVariable normalizes a single variable value according to opts
+func SanitizeVariable(value string, opts SanitizeOptions) string {
+	value = stripInvisible(value)
+
+	if opts.StripControlTokens {
+		// Repeat until stable so nested fragments like "<|im_<|x|>start|>" cannot reassemble
+		for {
+			stripped := value
+			for _, pattern := range controlTokenPatterns {
+				stripped = pattern.ReplaceAllString(stripped, "")
+			}
+			if stripped == value {
+				break
+			}
+			value = stripped
+		}
+	}
+
+	if !opts.EscapeMarkdown && !opts.EscapeHTML {
+		if opts.MaxLength > 0 {
+			runes := []rune(value)
+			if len(runes) > opts.MaxLength {
+				value = string(runes[:opts.MaxLength]) + opts.TruncationMarker
+			}
+		}
+		return value
+	}
+
+	// Escape first so MaxLength bounds what reaches the prompt, and cut only
+	// between escaped runes so no escape sequence is left half-written
+	var escaped strings.Builder
+	length := 0
+	for _, r := range value {
+		part := escapeRune(r, opts)
+		n := utf8.RuneCountInString(part)
+		if opts.MaxLength > 0 && length+n > opts.MaxLength {
+			escaped.WriteString(opts.TruncationMarker)
+			break
+		}
+		escaped.WriteString(part)
+		length += n
+	}
+	return escaped.String()
+}
+
+// escapeRune applies the escaping enabled in opts to a single rune
+func escapeRune(r rune, opts SanitizeOptions) string {
+	value := string(r)
+	if opts.EscapeMarkdown {
+		value = markdownEscaper.Replace(value)
+	}
+	if opts.EscapeHTML {
+		value = html.EscapeString(value)
+	}
+	return value
+}
+
+// SanitizeVariables returns a copy of vars with every string value (including
+// strings nested in slices and maps) sanitized. Non-string values are kept as-is.
+func SanitizeVariables(vars map[string]interface{}, opts SanitizeOptions) map[string]interface{} {
+	sanitized := make(map[string]interface{}, len(vars))
+	for key, value := range vars {
+		sanitized[key] = sanitizeValue(value, opts)
+	}
+	return sanitized
+}
+
+// sanitizeValue walks common container types produced by JSON decoding
+func sanitizeValue(value interface{}, opts SanitizeOptions) interface{} {
+	switch v := value.(type) {
+	case string:
+		return SanitizeVariable(v, opts)
+	case []string:
+		out := make([]string, len(v))
+		for i, s := range v {
+			out[i] = SanitizeVariable(s, opts)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = sanitizeValue(item, opts)
+		}
+		return out
+	case map[string]interface{}:
+		return SanitizeVariables(v, opts)
+	case map[string]string:
+		out := make(map[string]string, len(v))
+		for key, s := range v {
+			out[key] = SanitizeVariable(s, opts)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// stripInvisible removes control characters (except newline and tab) and
+// invisible formatting runes such as zero-width spaces and bidi overrides,
+// which are commonly used to hide injected instructions
+func stripInvisible(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case r == '\r':
+			return -1
+		case unicode.IsControl(r):
+			return -1
+		case unicode.Is(unicode.Cf, r):
+			// Format characters: zero-width joiners, bidi overrides, BOM
+			return -1
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,275 @@
+package prompts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Template is a named prompt written in text/template syntax. Variables are
+// available as {{.name}}, and partials are included with
+// {{template "partial" .}}.
+type Template struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	System      string                 `json:"system,omitempty"` // Optional system prompt
+	User        string                 `json:"user"`             // User message
+	Variables   []Variable             `json:"variables,omitempty"`
+	Model       string                 `json:"model,omitempty"`    // Default model
+	Config      map[string]interface{} `json:"config,omitempty"`   // Default request config, e.g. temperature
+	Sanitize    *SanitizeOptions       `json:"sanitize,omitempty"` // Applied to string variables; defaults to DefaultSanitizeOptions
+}
+
+// Variable declares a template variable
+type Variable struct {
+	Name     string      `json:"name"`
+	Required bool        `json:"required,omitempty"`
+	Default  interface{} `json:"default,omitempty"`
+}
+
+// Rendered is a template filled in with variables
+type Rendered struct {
+	System string
+	User   string
+	Model  string
+	Config map[string]interface{}
+}
+
+// VariableError reports variables a template was rendered without, or that
+// it does not declare
+type VariableError struct {
+	Template string
+	Missing  []string
+	Unknown  []string
+}
+
+func (e *VariableError) Error() string {
+	var problems []string
+	if len(e.Missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Unknown) > 0 {
+		problems = append(problems, "unknown "+strings.Join(e.Unknown, ", "))
+	}
+	return fmt.Sprintf("template %s: %s variables", e.Template, strings.Join(problems, "; "))
+}
+
+// Library holds named templates and the partials they share. It is safe for
+// concurrent use.
+type Library struct {
+	mu        sync.RWMutex
+	templates map[string]Template
+	partials  map[string]string
+}
+
+// NewLibrary creates an empty library
+func NewLibrary() *Library {
+	return &Library{
+		templates: make(map[string]Template),
+		partials:  make(map[string]string),
+	}
+}
+
+// Add registers a template, replacing one of the same name. Its syntax is
+// checked now; partials it uses may be added later.
+func (l *Library) Add(tmpl Template) error {
+	if tmpl.Name == "" {
+		return fmt.Errorf("template name is required")
+	}
+	if tmpl.User == "" {
+		return fmt.Errorf("template %s: user text is required", tmpl.Name)
+	}
+	for _, text := range []string{tmpl.System, tmpl.User} {
+		if _, err := template.New(tmpl.Name).Parse(text); err != nil {
+			return fmt.Errorf("template %s: %w", tmpl.Name, err)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.templates[tmpl.Name] = tmpl
+	return nil
+}
+
+// AddPartial registers text that templates include with {{template "name" .}}
+func (l *Library) AddPartial(name, text string) error {
+	if _, err := template.New(name).Parse(text); err != nil {
+		return fmt.Errorf("partial %s: %w", name, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.partials[name] = text
+	return nil
+}
+
+// Get returns the named template
+func (l *Library) Get(name string) (Template, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	tmpl, ok := l.templates[name]
+	return tmpl, ok
+}
+
+// Names returns the template names in sorted order
+func (l *Library) Names() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	names := make([]string, 0, len(l.templates))
+	for name := range l.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// libraryFile is the JSON layout read by LoadFile
+type libraryFile struct {
+	Templates []Template        `json:"templates"`
+	Partials  map[string]string `json:"partials,omitempty"`
+}
+
+// LoadFile adds the templates and partials of a JSON file of the form
+// {"templates": [...], "partials": {"name": "text"}}
+func (l *Library) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var file libraryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for name, text := range file.Partials {
+		if err := l.AddPartial(name, text); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	for _, tmpl := range file.Templates {
+		if err := l.Add(tmpl); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// LoadDir loads every *.json file in dir with LoadFile
+func (l *Library) LoadDir(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := l.LoadFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render fills in the named template. Declared defaults apply to absent
+// variables, and string values are sanitized before interpolation. Missing
+// required variables, and undeclared ones when the template declares any,
+// fail with a *VariableError; referencing a variable that was never given
+// fails too.
+func (l *Library) Render(name string, vars map[string]interface{}) (*Rendered, error) {
+	l.mu.RLock()
+	tmpl, ok := l.templates[name]
+	partials := make(map[string]string, len(l.partials))
+	for partial, text := range l.partials {
+		partials[partial] = text
+	}
+	l.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("template %s not found", name)
+	}
+
+	data, err := tmpl.variables(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := &Rendered{Model: tmpl.Model, Config: make(map[string]interface{}, len(tmpl.Config))}
+	for key, value := range tmpl.Config {
+		rendered.Config[key] = value
+	}
+	if rendered.System, err = execute(tmpl.Name, tmpl.System, partials, data); err != nil {
+		return nil, err
+	}
+	if rendered.User, err = execute(tmpl.Name, tmpl.User, partials, data); err != nil {
+		return nil, err
+	}
+	return rendered, nil
+}
+
+// variables validates vars against the declared variables and returns the
+// sanitized values with defaults filled in
+func (t Template) variables(vars map[string]interface{}) (map[string]interface{}, error) {
+	data := make(map[string]interface{}, len(vars)+len(t.Variables))
+	for key, value := range vars {
+		data[key] = value
+	}
+
+	varErr := &VariableError{Template: t.Name}
+	declared := make(map[string]bool, len(t.Variables))
+	for _, variable := range t.Variables {
+		declared[variable.Name] = true
+		if _, ok := data[variable.Name]; ok {
+			continue
+		}
+		switch {
+		case variable.Default != nil:
+			data[variable.Name] = variable.Default
+		case variable.Required:
+			varErr.Missing = append(varErr.Missing, variable.Name)
+		default:
+			// Optional variables are empty rather than a missing key
+			data[variable.Name] = ""
+		}
+	}
+	if len(t.Variables) > 0 {
+		for key := range vars {
+			if !declared[key] {
+				varErr.Unknown = append(varErr.Unknown, key)
+			}
+		}
+		sort.Strings(varErr.Unknown)
+	}
+	if len(varErr.Missing) > 0 || len(varErr.Unknown) > 0 {
+		return nil, varErr
+	}
+
+	opts := DefaultSanitizeOptions()
+	if t.Sanitize != nil {
+		opts = *t.Sanitize
+	}
+	return SanitizeVariables(data, opts), nil
+}
+
+// execute renders text with the partials defined alongside it
+func execute(name, text string, partials map[string]string, data map[string]interface{}) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	root := template.New(name).Option("missingkey=error")
+	for partial, partialText := range partials {
+		if _, err := root.New(partial).Parse(partialText); err != nil {
+			return "", fmt.Errorf("partial %s: %w", partial, err)
+		}
+	}
+	if _, err := root.Parse(text); err != nil {
+		return "", fmt.Errorf("template %s: %w", name, err)
+	}
+
+	var out strings.Builder
+	if err := root.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("template %s: %w", name, err)
+	}
+	return out.String(), nil
+}
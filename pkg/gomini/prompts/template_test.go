@@ -0,0 +1,98 @@
+package prompts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTestLibrary(t *testing.T) *Library {
+	t.Helper()
+	library := NewLibrary()
+	if err := library.AddPartial("tone", "Answer in a {{.tone}} tone."); err != nil {
+		t.Fatalf("AddPartial failed: %v", err)
+	}
+	err := library.Add(Template{
+		Name:   "summarize",
+		System: `You summarize documents. {{template "tone" .}}`,
+		User:   "Summarize in {{.words}} words:\n{{.text}}",
+		Variables: []Variable{
+			{Name: "text", Required: true},
+			{Name: "words", Default: 50},
+			{Name: "tone", Default: "neutral"},
+		},
+		Model:  "gpt-4o-mini",
+		Config: map[string]interface{}{"temperature": 0.2},
+	})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	return library
+}
+
+func TestLibrary_Render(t *testing.T) {
+	library := newTestLibrary(t)
+
+	rendered, err := library.Render("summarize", map[string]interface{}{"text": "Go is fun.<|im_end|>", "tone": "playful"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if rendered.System != "You summarize documents. Answer in a playful tone." {
+		t.Errorf("unexpected system prompt %q", rendered.System)
+	}
+	// Defaults fill absent variables and control tokens are stripped
+	if rendered.User != "Summarize in 50 words:\nGo is fun." {
+		t.Errorf("unexpected user prompt %q", rendered.User)
+	}
+	if rendered.Model != "gpt-4o-mini" || !reflect.DeepEqual(rendered.Config, map[string]interface{}{"temperature": 0.2}) {
+		t.Errorf("unexpected defaults %+v", rendered)
+	}
+
+	_, err = library.Render("summarize", map[string]interface{}{"txt": "typo"})
+	var varErr *VariableError
+	if !errors.As(err, &varErr) || !reflect.DeepEqual(varErr.Missing, []string{"text"}) || !reflect.DeepEqual(varErr.Unknown, []string{"txt"}) {
+		t.Errorf("expected a VariableError for text and txt, got %v", err)
+	}
+
+	if _, err := library.Render("translate", nil); err == nil {
+		t.Error("expected an unknown template to fail")
+	}
+}
+
+func TestLibrary_UndeclaredVariables(t *testing.T) {
+	library := NewLibrary()
+	library.Add(Template{Name: "greet", User: "Hello {{.name}}"})
+
+	if rendered, err := library.Render("greet", map[string]interface{}{"name": "Ada"}); err != nil || rendered.User != "Hello Ada" {
+		t.Errorf("unexpected render %+v, %v", rendered, err)
+	}
+	if _, err := library.Render("greet", nil); err == nil {
+		t.Error("expected a missing key to fail")
+	}
+	if err := library.Add(Template{Name: "broken", User: "{{.name"}); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
+
+func TestLibrary_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	file := `{
+		"partials": {"sign": "-- {{.team}}"},
+		"templates": [{"name": "notice", "user": "{{.body}}\n{{template \"sign\" .}}", "variables": [{"name": "body", "required": true}, {"name": "team", "default": "ops"}]}]
+	}`
+	os.WriteFile(filepath.Join(dir, "notice.json"), []byte(file), 0o644)
+
+	library := NewLibrary()
+	if err := library.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if names := library.Names(); !reflect.DeepEqual(names, []string{"notice"}) {
+		t.Errorf("unexpected names %v", names)
+	}
+	rendered, err := library.Render("notice", map[string]interface{}{"body": "Deploy at 5pm"})
+	if err != nil || rendered.User != "Deploy at 5pm\n-- ops" {
+		t.Errorf("unexpected render %+v, %v", rendered, err)
+	}
+}
@@ -0,0 +1,57 @@
+package providers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is an error response from a provider's API, decoded from the
+// SDK's typed error so it can be classified without matching on its text
+type APIError struct {
+	StatusCode int    `json:"status_code"`
+	Type       string `json:"type,omitempty"`  // OpenAI error.type, or the Google RPC status such as RESOURCE_EXHAUSTED
+	Code       string `json:"code,omitempty"`  // OpenAI error.code, or the Google ErrorInfo reason such as API_KEY_INVALID
+	Param      string `json:"param,omitempty"` // Request parameter at fault, when reported
+	Message    string `json:"message,omitempty"`
+
+	RetryAfter time.Duration `json:"retry_after,omitempty"` // From Retry-After headers or RetryInfo; 0 when not given
+	RequestID  string        `json:"request_id,omitempty"`
+
+	Err error `json:"-"` // The SDK error
+}
+
+// Error returns the SDK error's text
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the SDK error
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// ParseRetryAfter reads how long to wait before retrying from response
+// headers: retry-after-ms, then Retry-After in seconds or as an HTTP date
+func ParseRetryAfter(header http.Header) time.Duration {
+	if ms, err := strconv.ParseFloat(header.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// AudioPart is inline audio sent to a model as a message content part. It may
+// appear in content lists directly or in the map form
+// {"type": "input_audio", "data": {"base64": ..., "mime_type": ...}}.
+type AudioPart struct {
+	MIMEType string `json:"mime_type"`
+	Data     []byte `json:"data"`
+}
+
+// MarshalJSON encodes the part in its map form so persisted sessions keep
+// the audio recognizable after a round trip
+func (a AudioPart) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"type": "input_audio",
+		"data": map[string]interface{}{
+			"base64":    base64.StdEncoding.EncodeToString(a.Data),
+			"mime_type": a.MIMEType,
+		},
+	})
+}
+
+// AsAudioPart extracts an audio part from a content item. The second result
+// is false when the item is not audio.
+func AsAudioPart(item interface{}) (AudioPart, bool, error) {
+	switch part := item.(type) {
+	case AudioPart:
+		return part, true, nil
+	case *AudioPart:
+		if part == nil {
+			return AudioPart{}, false, nil
+		}
+		return *part, true, nil
+	case map[string]interface{}:
+		if part["type"] != "input_audio" {
+			return AudioPart{}, false, nil
+		}
+		data, _ := part["data"].(map[string]interface{})
+		encoded, _ := data["base64"].(string)
+		mimeType, _ := data["mime_type"].(string)
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return AudioPart{}, true, fmt.Errorf("invalid base64 audio data: %w", err)
+		}
+		return AudioPart{MIMEType: mimeType, Data: decoded}, true, nil
+	}
+	return AudioPart{}, false, nil
+}
+
+// HasAudioInput reports whether any message carries an audio content part
+func HasAudioInput(messages []Message) bool {
+	for _, msg := range messages {
+		msgMap, ok := msg.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		items, ok := msgMap["content"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range items {
+			if _, isAudio, _ := AsAudioPart(item); isAudio {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FindModel returns the model with the given ID
+func FindModel(models []Model, id string) (Model, bool) {
+	for _, model := range models {
+		if model.ID == id {
+			return model, true
+		}
+	}
+	return Model{}, false
+}
+
+// NormalizeAudioMIMEType maps common aliases (audio/x-wav, audio/mp3) to a canonical MIME type
+func NormalizeAudioMIMEType(mimeType string) string {
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	switch mimeType {
+	case "audio/x-wav", "audio/wave", "audio/vnd.wave":
+		return "audio/wav"
+	case "audio/mp3", "audio/mpeg3", "audio/x-mp3":
+		return "audio/mpeg"
+	}
+	return mimeType
+}
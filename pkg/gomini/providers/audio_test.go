@@ -0,0 +1,74 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAsAudioPart(t *testing.T) {
+	tests := []struct {
+		name        string
+		item        interface{}
+		isAudio     bool
+		expectError bool
+	}{
+		{name: "struct", item: AudioPart{MIMEType: "audio/wav", Data: []byte("x")}, isAudio: true},
+		{name: "pointer", item: &AudioPart{MIMEType: "audio/wav"}, isAudio: true},
+		{
+			name:    "map",
+			item:    map[string]interface{}{"type": "input_audio", "data": map[string]interface{}{"base64": "eA==", "mime_type": "audio/mpeg"}},
+			isAudio: true,
+		},
+		{
+			name:        "invalid base64",
+			item:        map[string]interface{}{"type": "input_audio", "data": map[string]interface{}{"base64": "!!"}},
+			isAudio:     true,
+			expectError: true,
+		},
+		{name: "text part", item: map[string]interface{}{"type": "text"}},
+		{name: "string", item: "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, isAudio, err := AsAudioPart(tt.item)
+			if isAudio != tt.isAudio {
+				t.Errorf("expected isAudio=%v, got %v", tt.isAudio, isAudio)
+			}
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error=%v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestHasAudioInput(t *testing.T) {
+	text := map[string]interface{}{"role": "user", "content": "hi"}
+	audio := map[string]interface{}{"role": "user", "content": []interface{}{AudioPart{MIMEType: "audio/wav"}}}
+
+	if HasAudioInput([]Message{text}) {
+		t.Error("expected no audio in text-only messages")
+	}
+	if !HasAudioInput([]Message{text, audio}) {
+		t.Error("expected audio to be detected")
+	}
+}
+
+func TestAudioPart_JSONRoundTrip(t *testing.T) {
+	raw, err := json.Marshal([]interface{}{AudioPart{MIMEType: "audio/wav", Data: []byte("wav")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var items []interface{}
+	if err := json.Unmarshal(raw, &items); err != nil {
+		t.Fatal(err)
+	}
+	audio, isAudio, err := AsAudioPart(items[0])
+	if !isAudio || err != nil {
+		t.Fatalf("expected audio after round trip, got %v (err %v)", items[0], err)
+	}
+	if audio.MIMEType != "audio/wav" || string(audio.Data) != "wav" {
+		t.Errorf("unexpected audio part %+v", audio)
+	}
+}
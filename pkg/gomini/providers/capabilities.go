@@ -0,0 +1,103 @@
+package providers
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultModelsTTL is how long a discovered model catalog is served before
+// the provider's listing endpoint is asked again
+const DefaultModelsTTL = time.Hour
+
+// ModelProfile describes the models matching Pattern: an exact model ID, or
+// an ID prefix ending in "*". A lone "*" matches every model.
+type ModelProfile struct {
+	Pattern         string            `json:"pattern"`
+	Capabilities    ModelCapabilities `json:"capabilities"`
+	ContextSize     int               `json:"context_size,omitempty"`
+	MaxOutputTokens int               `json:"max_output_tokens,omitempty"`
+}
+
+// match reports whether the profile covers id and how specifically: an exact
+// ID beats any prefix, and a longer prefix beats a shorter one
+func (p ModelProfile) match(id string) (int, bool) {
+	if prefix, ok := strings.CutSuffix(p.Pattern, "*"); ok {
+		return len(prefix), strings.HasPrefix(id, prefix)
+	}
+	return len(p.Pattern) + 1, p.Pattern == id
+}
+
+// CapabilityTable holds what is known about models whose listing endpoint
+// does not report capabilities
+type CapabilityTable []ModelProfile
+
+// Lookup returns the most specific profile matching id; among equally
+// specific ones the earliest wins. A "models/" prefix on id is ignored.
+func (t CapabilityTable) Lookup(id string) (ModelProfile, bool) {
+	id = strings.TrimPrefix(id, "models/")
+	best, found, bestScore := ModelProfile{}, false, -1
+	for _, profile := range t {
+		if score, ok := profile.match(id); ok && score > bestScore {
+			best, found, bestScore = profile, true, score
+		}
+	}
+	return best, found
+}
+
+// Apply replaces the capabilities of model with those of its profile and
+// takes the limits the profile sets. Models without a profile are returned
+// unchanged.
+func (t CapabilityTable) Apply(model Model) Model {
+	profile, ok := t.Lookup(model.ID)
+	if !ok {
+		return model
+	}
+	model.Capabilities = profile.Capabilities
+	if profile.ContextSize > 0 {
+		model.ContextSize = profile.ContextSize
+	}
+	if profile.MaxOutputTokens > 0 {
+		model.MaxOutputTokens = profile.MaxOutputTokens
+	}
+	return model
+}
+
+// ApplyAll returns a copy of models with Apply run on each
+func (t CapabilityTable) ApplyAll(models []Model) []Model {
+	applied := make([]Model, len(models))
+	for i, model := range models {
+		applied[i] = t.Apply(model)
+	}
+	return applied
+}
+
+// MergeModels adds models discovered from a listing endpoint to a catalog.
+// Catalog entries keep their capabilities and cost, taking only the name and
+// limits they lack; discovered models missing from the catalog are appended.
+func MergeModels(catalog, discovered []Model) []Model {
+	merged := make([]Model, len(catalog), len(catalog)+len(discovered))
+	copy(merged, catalog)
+	index := make(map[string]int, len(merged))
+	for i, model := range merged {
+		index[model.ID] = i
+	}
+
+	for _, model := range discovered {
+		i, ok := index[model.ID]
+		if !ok {
+			index[model.ID] = len(merged)
+			merged = append(merged, model)
+			continue
+		}
+		if merged[i].Name == "" {
+			merged[i].Name = model.Name
+		}
+		if merged[i].ContextSize == 0 {
+			merged[i].ContextSize = model.ContextSize
+		}
+		if merged[i].MaxOutputTokens == 0 {
+			merged[i].MaxOutputTokens = model.MaxOutputTokens
+		}
+	}
+	return merged
+}
@@ -0,0 +1,80 @@
+package providers
+
+import "testing"
+
+func TestCapabilityTable_Lookup(t *testing.T) {
+	table := CapabilityTable{
+		{Pattern: "*", ContextSize: 1},
+		{Pattern: "gpt-4*", ContextSize: 2},
+		{Pattern: "gpt-4o*", ContextSize: 3},
+		{Pattern: "gpt-4o-2024-05-13", ContextSize: 4},
+		{Pattern: "gpt-4o*", ContextSize: 5}, // Shadowed by the earlier entry
+	}
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{"gpt-4o-2024-05-13", 4},
+		{"gpt-4o-mini", 3},
+		{"gpt-4-turbo", 2},
+		{"models/gpt-4o", 3},
+		{"llama-3", 1},
+	}
+	for _, tt := range tests {
+		profile, ok := table.Lookup(tt.id)
+		if !ok || profile.ContextSize != tt.want {
+			t.Errorf("Lookup(%s) = %d, %v, want %d", tt.id, profile.ContextSize, ok, tt.want)
+		}
+	}
+
+	if _, ok := table[1:].Lookup("llama-3"); ok {
+		t.Error("Expected no profile without a catch-all entry")
+	}
+}
+
+func TestCapabilityTable_Apply(t *testing.T) {
+	table := CapabilityTable{{Pattern: "gpt-4o", Capabilities: ModelCapabilities{ImageInput: true}, ContextSize: 128000}}
+	cost := &ModelCost{InputTokens: 1}
+
+	model := table.Apply(Model{ID: "gpt-4o", MaxOutputTokens: 16384, Cost: cost, Capabilities: ModelCapabilities{AudioInput: true}})
+	if !model.Capabilities.ImageInput || model.Capabilities.AudioInput {
+		t.Errorf("Expected the profile's capabilities to replace the model's, got %+v", model.Capabilities)
+	}
+	if model.ContextSize != 128000 || model.MaxOutputTokens != 16384 || model.Cost != cost {
+		t.Errorf("Expected profile limits to apply and the rest to be kept, got %+v", model)
+	}
+
+	unknown := Model{ID: "o3", ContextSize: 7}
+	if got := table.Apply(unknown); got != unknown {
+		t.Errorf("Expected a model without a profile to be unchanged, got %+v", got)
+	}
+}
+
+func TestMergeModels(t *testing.T) {
+	cost := &ModelCost{InputTokens: 1}
+	catalog := []Model{
+		{ID: "gpt-4o", Name: "GPT-4o", ContextSize: 128000, Cost: cost, Capabilities: ModelCapabilities{ImageInput: true}},
+		{ID: "gpt-4o-mini"},
+	}
+	discovered := []Model{
+		{ID: "gpt-4o", Name: "gpt-4o", ContextSize: 8192, MaxOutputTokens: 16384},
+		{ID: "gpt-4o-mini", Name: "gpt-4o-mini", ContextSize: 128000},
+		{ID: "o3", Name: "o3", ContextSize: 200000},
+	}
+
+	merged := MergeModels(catalog, discovered)
+	if len(merged) != 3 || merged[2].ID != "o3" {
+		t.Fatalf("Expected the catalog followed by new models, got %+v", merged)
+	}
+	if got := merged[0]; got.Name != "GPT-4o" || got.ContextSize != 128000 || got.MaxOutputTokens != 16384 ||
+		got.Cost != cost || !got.Capabilities.ImageInput {
+		t.Errorf("Expected the catalog entry to keep its values and gain missing limits, got %+v", got)
+	}
+	if got := merged[1]; got.Name != "gpt-4o-mini" || got.ContextSize != 128000 {
+		t.Errorf("Expected empty catalog fields to be filled, got %+v", got)
+	}
+	if catalog[1].ContextSize != 0 {
+		t.Error("Expected the catalog to be left unchanged")
+	}
+}
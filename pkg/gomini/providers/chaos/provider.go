@@ -0,0 +1,379 @@
+// Package chaos wraps a provider and injects failures at configured
+// probabilities, to exercise retry and fallback paths in integration tests
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// DefaultTimeoutAfter is how long an injected timeout hangs when the request
+// context has no earlier deadline
+const DefaultTimeoutAfter = 10 * time.Second
+
+// Fault is a kind of injected failure
+type Fault string
+
+const (
+	FaultRateLimit     Fault = "rate_limit"
+	FaultServerError   Fault = "server_error"
+	FaultTimeout       Fault = "timeout"
+	FaultDisconnect    Fault = "disconnect"
+	FaultMalformedJSON Fault = "malformed_json"
+)
+
+// Injected errors. Their messages carry the HTTP status a real provider
+// would report, so gomini classifies them like the real thing.
+var (
+	ErrRateLimit     = errors.New("chaos: 429 rate limit exceeded")
+	ErrServerError   = errors.New("chaos: 500 internal server error")
+	ErrTimeout       = errors.New("chaos: 504 gateway timeout")
+	ErrDisconnect    = errors.New("chaos: connection reset mid-stream")
+	ErrMalformedJSON = errors.New("chaos: failed to parse JSON response: unexpected end of JSON input")
+)
+
+// Config sets the probability, from 0 to 1, that a request suffers each
+// fault. At most one fault is injected per request, so the probabilities
+// must add up to 1 or less.
+type Config struct {
+	RateLimit   float64 `json:"rate_limit,omitempty"`   // Fail with a 429
+	ServerError float64 `json:"server_error,omitempty"` // Fail with a 500
+	Timeout     float64 `json:"timeout,omitempty"`      // Hang, then fail with a 504 or the context error
+	Disconnect  float64 `json:"disconnect,omitempty"`   // Streams: cut off after a few events
+	// GenerateJSON fails to parse; SendMessage returns its text truncated
+	// partway, like a model cut off mid-object
+	MalformedJSON float64 `json:"malformed_json,omitempty"`
+
+	TimeoutAfter time.Duration `json:"timeout_after,omitempty"` // Defaults to DefaultTimeoutAfter
+	Seed         int64         `json:"seed,omitempty"`          // Makes fault selection repeatable; 0 seeds randomly
+}
+
+// Validate checks that the probabilities are in range
+func (c *Config) Validate() error {
+	total := 0.0
+	for fault, probability := range c.probabilities() {
+		if probability < 0 || probability > 1 {
+			return fmt.Errorf("chaos %s probability %v is not between 0 and 1", fault, probability)
+		}
+		total += probability
+	}
+	if total > 1 {
+		return fmt.Errorf("chaos probabilities add up to %v, more than 1", total)
+	}
+	return nil
+}
+
+func (c *Config) probabilities() map[Fault]float64 {
+	return map[Fault]float64{
+		FaultRateLimit:     c.RateLimit,
+		FaultServerError:   c.ServerError,
+		FaultTimeout:       c.Timeout,
+		FaultDisconnect:    c.Disconnect,
+		FaultMalformedJSON: c.MalformedJSON,
+	}
+}
+
+// faultOrder fixes the order faults are rolled in, so seeded runs repeat
+var faultOrder = []Fault{FaultRateLimit, FaultServerError, FaultTimeout, FaultDisconnect, FaultMalformedJSON}
+
+// Provider wraps a provider and injects faults into its requests. It
+// implements every optional provider interface; pass it through
+// providers.Expose so callers see only those the wrapped provider has.
+type Provider struct {
+	providers.LLMProvider
+	config   Config
+	disabled atomic.Bool
+
+	mu       sync.Mutex
+	rng      *rand.Rand
+	injected map[Fault]int
+}
+
+// NewProvider wraps provider with fault injection
+func NewProvider(provider providers.LLMProvider, config *Config) (*Provider, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Provider{
+		LLMProvider: provider,
+		config:      *config,
+		rng:         rand.New(rand.NewSource(seed)),
+		injected:    make(map[Fault]int),
+	}, nil
+}
+
+// SetEnabled turns fault injection on or off; it starts on
+func (p *Provider) SetEnabled(enabled bool) {
+	p.disabled.Store(!enabled)
+}
+
+// Injected returns how many times each fault has been injected
+func (p *Provider) Injected() map[Fault]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	counts := make(map[Fault]int, len(p.injected))
+	for fault, count := range p.injected {
+		counts[fault] = count
+	}
+	return counts
+}
+
+// SendMessage forwards the request unless a fault is injected
+func (p *Provider) SendMessage(ctx context.Context, req *providers.ChatRequest) (*providers.ChatResponse, error) {
+	fault := p.roll(FaultRateLimit, FaultServerError, FaultTimeout, FaultMalformedJSON)
+	if fault != "" && fault != FaultMalformedJSON {
+		return nil, p.fail(ctx, fault)
+	}
+
+	resp, err := p.LLMProvider.SendMessage(ctx, req)
+	if err != nil || fault != FaultMalformedJSON {
+		return resp, err
+	}
+	return truncateResponse(resp), nil
+}
+
+// SendMessageStream forwards the stream unless a fault is injected. A
+// disconnect ends the stream with ErrDisconnect after a few events.
+func (p *Provider) SendMessageStream(ctx context.Context, req *providers.ChatRequest) <-chan providers.StreamEvent {
+	return p.stream(ctx, req.Model, func() <-chan providers.StreamEvent {
+		return p.LLMProvider.SendMessageStream(ctx, req)
+	})
+}
+
+// stream forwards the stream opened by open unless a fault is injected
+func (p *Provider) stream(ctx context.Context, model string, open func() <-chan providers.StreamEvent) <-chan providers.StreamEvent {
+	fault := p.roll(FaultRateLimit, FaultServerError, FaultTimeout, FaultDisconnect)
+	if fault == "" {
+		return open()
+	}
+
+	resultChan := make(chan providers.StreamEvent, 10)
+	if fault != FaultDisconnect {
+		go func() {
+			defer close(resultChan)
+			resultChan <- providers.NewErrorEvent(p.GetProviderType(), model, p.fail(ctx, fault), true)
+		}()
+		return resultChan
+	}
+
+	p.mu.Lock()
+	cutAfter := 1 + p.rng.Intn(3)
+	p.mu.Unlock()
+	source := open()
+
+	go func() {
+		defer func() {
+			for range source {
+			}
+		}()
+		defer close(resultChan)
+
+		forwarded := 0
+		for event := range source {
+			if forwarded == cutAfter || event.Type == providers.EventFinished {
+				break
+			}
+			select {
+			case resultChan <- event:
+			case <-ctx.Done():
+				return
+			}
+			if event.Type == providers.EventError {
+				return
+			}
+			forwarded++
+		}
+		resultChan <- providers.NewErrorEvent(p.GetProviderType(), model, ErrDisconnect, true)
+	}()
+	return resultChan
+}
+
+// GenerateJSON forwards the request unless a fault is injected
+func (p *Provider) GenerateJSON(ctx context.Context, req *providers.JSONRequest) (*providers.JSONResponse, error) {
+	if fault := p.roll(FaultRateLimit, FaultServerError, FaultTimeout, FaultMalformedJSON); fault != "" {
+		return nil, p.fail(ctx, fault)
+	}
+	return p.LLMProvider.GenerateJSON(ctx, req)
+}
+
+// GenerateJSONStream forwards the JSON stream unless a fault is injected
+func (p *Provider) GenerateJSONStream(ctx context.Context, req *providers.JSONRequest) <-chan providers.StreamEvent {
+	streamer, ok := p.LLMProvider.(providers.JSONStreamer)
+	if !ok {
+		return p.unsupportedStream(req.Model, "JSON streaming")
+	}
+	return p.stream(ctx, req.Model, func() <-chan providers.StreamEvent {
+		return streamer.GenerateJSONStream(ctx, req)
+	})
+}
+
+// Moderate forwards the check unless a fault is injected
+func (p *Provider) Moderate(ctx context.Context, text string) (*providers.ModerationResult, error) {
+	moderator, ok := p.LLMProvider.(providers.Moderator)
+	if !ok {
+		return nil, p.unsupported("moderation")
+	}
+	if fault := p.roll(FaultRateLimit, FaultServerError, FaultTimeout); fault != "" {
+		return nil, p.fail(ctx, fault)
+	}
+	return moderator.Moderate(ctx, text)
+}
+
+// GenerateImage forwards the request unless a fault is injected
+func (p *Provider) GenerateImage(ctx context.Context, req *providers.ImageRequest) (*providers.ImageResponse, error) {
+	generator, ok := p.LLMProvider.(providers.ImageGenerator)
+	if !ok {
+		return nil, p.unsupported("image generation")
+	}
+	if fault := p.roll(FaultRateLimit, FaultServerError, FaultTimeout); fault != "" {
+		return nil, p.fail(ctx, fault)
+	}
+	return generator.GenerateImage(ctx, req)
+}
+
+// Speech forwards the request unless a fault is injected
+func (p *Provider) Speech(ctx context.Context, req *providers.SpeechRequest) (*providers.SpeechResponse, error) {
+	audio, ok := p.LLMProvider.(providers.AudioProvider)
+	if !ok {
+		return nil, p.unsupported("speech")
+	}
+	if fault := p.roll(FaultRateLimit, FaultServerError, FaultTimeout); fault != "" {
+		return nil, p.fail(ctx, fault)
+	}
+	return audio.Speech(ctx, req)
+}
+
+// SpeechStream forwards the audio stream unless a fault is injected
+func (p *Provider) SpeechStream(ctx context.Context, req *providers.SpeechRequest) <-chan providers.StreamEvent {
+	audio, ok := p.LLMProvider.(providers.AudioProvider)
+	if !ok {
+		return p.unsupportedStream(req.Model, "speech")
+	}
+	return p.stream(ctx, req.Model, func() <-chan providers.StreamEvent {
+		return audio.SpeechStream(ctx, req)
+	})
+}
+
+// Transcribe forwards the request unless a fault is injected
+func (p *Provider) Transcribe(ctx context.Context, req *providers.TranscriptionRequest) (*providers.TranscriptionResponse, error) {
+	audio, ok := p.LLMProvider.(providers.AudioProvider)
+	if !ok {
+		return nil, p.unsupported("transcription")
+	}
+	if fault := p.roll(FaultRateLimit, FaultServerError, FaultTimeout); fault != "" {
+		return nil, p.fail(ctx, fault)
+	}
+	return audio.Transcribe(ctx, req)
+}
+
+// unsupported reports a feature the wrapped provider lacks
+func (p *Provider) unsupported(feature string) error {
+	return providers.NewLLMError(providers.ErrorUnsupportedFeature,
+		fmt.Sprintf("provider %s does not support %s", p.GetProviderType(), feature), p.GetProviderType(), nil)
+}
+
+func (p *Provider) unsupportedStream(model, feature string) <-chan providers.StreamEvent {
+	resultChan := make(chan providers.StreamEvent, 1)
+	resultChan <- providers.NewErrorEvent(p.GetProviderType(), model, p.unsupported(feature), false)
+	close(resultChan)
+	return resultChan
+}
+
+// roll picks the fault to inject from those applicable, or "" for none
+func (p *Provider) roll(applicable ...Fault) Fault {
+	if p.disabled.Load() {
+		return ""
+	}
+	probabilities := p.config.probabilities()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r := p.rng.Float64()
+	for _, fault := range faultOrder {
+		if !containsFault(applicable, fault) {
+			continue
+		}
+		if r < probabilities[fault] {
+			p.injected[fault]++
+			return fault
+		}
+		r -= probabilities[fault]
+	}
+	return ""
+}
+
+// fail returns the error for fault, first hanging for timeouts
+func (p *Provider) fail(ctx context.Context, fault Fault) error {
+	switch fault {
+	case FaultRateLimit:
+		return ErrRateLimit
+	case FaultServerError:
+		return ErrServerError
+	case FaultTimeout:
+		after := p.config.TimeoutAfter
+		if after <= 0 {
+			after = DefaultTimeoutAfter
+		}
+		timer := time.NewTimer(after)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return ErrTimeout
+		}
+	case FaultMalformedJSON:
+		return ErrMalformedJSON
+	}
+	return ErrDisconnect
+}
+
+// truncateResponse cuts the text of the first choice in half
+func truncateResponse(resp *providers.ChatResponse) *providers.ChatResponse {
+	if resp == nil || len(resp.Choices) == 0 {
+		return resp
+	}
+	choice := resp.Choices[0]
+	message, ok := choice.Message.(map[string]interface{})
+	if !ok {
+		return resp
+	}
+	text, ok := message["content"].(string)
+	if !ok {
+		return resp
+	}
+
+	truncated := *resp
+	truncated.Choices = append([]providers.Choice{}, resp.Choices...)
+	copiedMessage := make(map[string]interface{}, len(message))
+	for key, value := range message {
+		copiedMessage[key] = value
+	}
+	runes := []rune(text)
+	copiedMessage["content"] = string(runes[:len(runes)/2])
+	truncated.Choices[0].Message = copiedMessage
+	return &truncated
+}
+
+func containsFault(faults []Fault, fault Fault) bool {
+	for _, f := range faults {
+		if f == fault {
+			return true
+		}
+	}
+	return false
+}
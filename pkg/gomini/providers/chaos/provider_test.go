@@ -0,0 +1,244 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+	"gomini/pkg/gomini/providers/mock"
+)
+
+func newMockProvider(t *testing.T, steps ...mock.Step) *mock.Provider {
+	t.Helper()
+	if len(steps) == 0 {
+		steps = []mock.Step{{Type: mock.StepContent, Text: `{"city": "Taipei"}`}}
+	}
+	provider, err := mock.NewProvider(&mock.Config{Scenario: &mock.Scenario{Rules: []mock.Rule{{Steps: steps}}}})
+	if err != nil {
+		t.Fatalf("mock.NewProvider failed: %v", err)
+	}
+	return provider
+}
+
+func userRequest() *providers.ChatRequest {
+	return &providers.ChatRequest{Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "hi"}}}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"none", Config{}, false},
+		{"mixed", Config{RateLimit: 0.2, ServerError: 0.3, Disconnect: 0.5}, false},
+		{"negative", Config{Timeout: -0.1}, true},
+		{"above one", Config{ServerError: 1.5}, true},
+		{"sum above one", Config{RateLimit: 0.6, MalformedJSON: 0.6}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProvider_InjectsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   error
+	}{
+		{"rate limit", Config{RateLimit: 1}, ErrRateLimit},
+		{"server error", Config{ServerError: 1}, ErrServerError},
+		{"timeout", Config{Timeout: 1, TimeoutAfter: time.Millisecond}, ErrTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(newMockProvider(t), &tt.config)
+			if err != nil {
+				t.Fatalf("NewProvider failed: %v", err)
+			}
+			if _, err := provider.SendMessage(context.Background(), userRequest()); !errors.Is(err, tt.want) {
+				t.Errorf("SendMessage: expected %v, got %v", tt.want, err)
+			}
+			if _, err := provider.GenerateJSON(context.Background(), &providers.JSONRequest{Messages: userRequest().Messages}); !errors.Is(err, tt.want) {
+				t.Errorf("GenerateJSON: expected %v, got %v", tt.want, err)
+			}
+			var last providers.StreamEvent
+			for event := range provider.SendMessageStream(context.Background(), userRequest()) {
+				last = event
+			}
+			if last.Type != providers.EventError || !errors.Is(last.Error, tt.want) {
+				t.Errorf("SendMessageStream: expected %v, got %+v", tt.want, last)
+			}
+		})
+	}
+}
+
+func TestProvider_TimeoutHonorsContext(t *testing.T) {
+	provider, err := NewProvider(newMockProvider(t), &Config{Timeout: 1})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := provider.SendMessage(ctx, userRequest()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context deadline, got %v", err)
+	}
+}
+
+func TestProvider_Disconnect(t *testing.T) {
+	steps := make([]mock.Step, 6)
+	for i := range steps {
+		steps[i] = mock.Step{Type: mock.StepContent, Text: "chunk "}
+	}
+	provider, err := NewProvider(newMockProvider(t, steps...), &Config{Disconnect: 1})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	var content int
+	var last providers.StreamEvent
+	for event := range provider.SendMessageStream(context.Background(), userRequest()) {
+		if event.Type == providers.EventContent {
+			content++
+		}
+		last = event
+	}
+	if content < 1 || content > 3 {
+		t.Errorf("expected 1-3 events before the disconnect, got %d", content)
+	}
+	if last.Type != providers.EventError || !errors.Is(last.Error, ErrDisconnect) {
+		t.Errorf("expected a disconnect error, got %+v", last)
+	}
+
+	// Disconnects only apply to streams
+	if _, err := provider.SendMessage(context.Background(), userRequest()); err != nil {
+		t.Errorf("expected SendMessage to pass through, got %v", err)
+	}
+}
+
+func TestProvider_MalformedJSON(t *testing.T) {
+	provider, err := NewProvider(newMockProvider(t), &Config{MalformedJSON: 1})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	resp, err := provider.SendMessage(context.Background(), userRequest())
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if content := resp.Choices[0].Content(); content != `{"city": ` {
+		t.Errorf("expected truncated content, got %q", content)
+	}
+	if _, err := provider.GenerateJSON(context.Background(), &providers.JSONRequest{Messages: userRequest().Messages}); !errors.Is(err, ErrMalformedJSON) {
+		t.Errorf("expected ErrMalformedJSON, got %v", err)
+	}
+}
+
+func TestProvider_SeedAndToggle(t *testing.T) {
+	outcomes := func() []bool {
+		provider, err := NewProvider(newMockProvider(t), &Config{ServerError: 0.5, Seed: 42})
+		if err != nil {
+			t.Fatalf("NewProvider failed: %v", err)
+		}
+		var failed []bool
+		for i := 0; i < 20; i++ {
+			_, err := provider.SendMessage(context.Background(), userRequest())
+			failed = append(failed, err != nil)
+		}
+		if provider.Injected()[FaultServerError] == 0 {
+			t.Error("expected some injected server errors")
+		}
+		return failed
+	}
+	first, second := outcomes(), outcomes()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected seeded runs to repeat, differed at request %d", i)
+		}
+	}
+
+	provider, err := NewProvider(newMockProvider(t), &Config{RateLimit: 1})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	provider.SetEnabled(false)
+	if _, err := provider.SendMessage(context.Background(), userRequest()); err != nil {
+		t.Errorf("expected no faults while disabled, got %v", err)
+	}
+	if len(provider.Injected()) != 0 {
+		t.Errorf("expected no injected faults, got %v", provider.Injected())
+	}
+}
+
+// audioProvider adds text-to-speech to a mock provider
+type audioProvider struct {
+	*mock.Provider
+	chunks int
+}
+
+func (p *audioProvider) Speech(ctx context.Context, req *providers.SpeechRequest) (*providers.SpeechResponse, error) {
+	return &providers.SpeechResponse{Audio: []byte("ID3")}, nil
+}
+
+func (p *audioProvider) SpeechStream(ctx context.Context, req *providers.SpeechRequest) <-chan providers.StreamEvent {
+	events := make(chan providers.StreamEvent, p.chunks+1)
+	for i := 0; i < p.chunks; i++ {
+		events <- providers.NewAudioChunkEvent(providers.ProviderMock, req.Model, []byte("a"), "audio/mpeg", i)
+	}
+	events <- providers.StreamEvent{Type: providers.EventFinished}
+	close(events)
+	return events
+}
+
+func (p *audioProvider) Transcribe(ctx context.Context, req *providers.TranscriptionRequest) (*providers.TranscriptionResponse, error) {
+	return &providers.TranscriptionResponse{Text: "hi"}, nil
+}
+
+func TestProvider_OptionalFeatures(t *testing.T) {
+	wrapped := &audioProvider{Provider: newMockProvider(t), chunks: 6}
+	chaotic, err := NewProvider(wrapped, &Config{RateLimit: 1})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+
+	exposed := providers.Expose(chaotic, wrapped)
+	audio, ok := exposed.(providers.AudioProvider)
+	if !ok {
+		t.Fatal("expected audio to be exposed")
+	}
+	if _, ok := exposed.(providers.Moderator); ok {
+		t.Error("expected moderation to be hidden")
+	}
+	if _, err := audio.Speech(context.Background(), &providers.SpeechRequest{Input: "hi"}); !errors.Is(err, ErrRateLimit) {
+		t.Errorf("Speech: expected %v, got %v", ErrRateLimit, err)
+	}
+	if _, err := audio.Transcribe(context.Background(), &providers.TranscriptionRequest{}); !errors.Is(err, ErrRateLimit) {
+		t.Errorf("Transcribe: expected %v, got %v", ErrRateLimit, err)
+	}
+	if _, err := chaotic.Moderate(context.Background(), "hi"); err == nil {
+		t.Error("expected moderation to fail over a provider without it")
+	}
+
+	disconnecting, err := NewProvider(wrapped, &Config{Disconnect: 1})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	var chunks int
+	var last providers.StreamEvent
+	for event := range disconnecting.SpeechStream(context.Background(), &providers.SpeechRequest{Input: "hi"}) {
+		if event.Type == providers.EventAudio {
+			chunks++
+		}
+		last = event
+	}
+	if chunks < 1 || chunks > 3 || !errors.Is(last.Error, ErrDisconnect) {
+		t.Errorf("expected the audio stream to disconnect after 1-3 chunks, got %d chunks and %+v", chunks, last)
+	}
+}
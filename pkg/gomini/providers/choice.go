@@ -0,0 +1,58 @@
+package providers
+
+// Choice is one candidate answer of a ChatResponse
+type Choice struct {
+	Index        int          `json:"index"`
+	Message      Message      `json:"message"` // Assistant message: role, content and any tool_calls, images, reasoning or citations
+	FinishReason FinishReason `json:"finish_reason,omitempty"`
+	Refusal      string       `json:"refusal,omitempty"` // Why the model declined to answer, where the provider says (OpenAI)
+	Safety       *SafetyInfo  `json:"safety,omitempty"`  // Safety ratings and block details of this candidate (Gemini)
+
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"` // Per-token log probabilities, when requested with logprobs
+}
+
+// messageMap returns the choice's message as a map, nil if it is not one
+func (c Choice) messageMap() map[string]interface{} {
+	message, _ := c.Message.(map[string]interface{})
+	return message
+}
+
+// Content returns the text of the choice's message
+func (c Choice) Content() string {
+	content, _ := c.messageMap()["content"].(string)
+	return content
+}
+
+// SetContent replaces the text of the choice's message
+func (c *Choice) SetContent(text string) {
+	message := c.messageMap()
+	if message == nil {
+		message = map[string]interface{}{"role": "assistant"}
+		c.Message = message
+	}
+	message["content"] = text
+}
+
+// ToolCalls returns the tool calls the model requested
+func (c Choice) ToolCalls() []ToolCall {
+	toolCalls, _ := c.messageMap()["tool_calls"].([]ToolCall)
+	return toolCalls
+}
+
+// Reasoning returns the model's reasoning, where the provider exposes it
+func (c Choice) Reasoning() string {
+	reasoning, _ := c.messageMap()["reasoning"].(string)
+	return reasoning
+}
+
+// Citations returns the sources the answer cites
+func (c Choice) Citations() []Citation {
+	citations, _ := c.messageMap()["citations"].([]Citation)
+	return citations
+}
+
+// Filtered reports whether the answer is missing or cut short because the
+// provider refused or filtered it
+func (c Choice) Filtered() bool {
+	return c.Refusal != "" || c.FinishReason == FinishReasonContentFilter
+}
@@ -0,0 +1,55 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChoice_Accessors(t *testing.T) {
+	choice := Choice{
+		Message: map[string]interface{}{
+			"role":       "assistant",
+			"content":    "Checking.",
+			"reasoning":  "The user wants the weather.",
+			"tool_calls": []ToolCall{{ID: "call_1", Name: "get_weather"}},
+		},
+		FinishReason: FinishReasonToolCalls,
+	}
+	if choice.Content() != "Checking." || choice.Reasoning() != "The user wants the weather." {
+		t.Errorf("unexpected message accessors: %q, %q", choice.Content(), choice.Reasoning())
+	}
+	if calls := choice.ToolCalls(); len(calls) != 1 || calls[0].Name != "get_weather" {
+		t.Errorf("unexpected tool calls %+v", calls)
+	}
+	if choice.Filtered() {
+		t.Error("expected a tool call choice not to be filtered")
+	}
+
+	var empty Choice
+	if empty.Content() != "" || empty.ToolCalls() != nil || empty.Citations() != nil {
+		t.Error("expected an empty choice to report nothing")
+	}
+	empty.SetContent("filled")
+	if empty.Content() != "filled" {
+		t.Errorf("expected SetContent to create the message, got %+v", empty.Message)
+	}
+}
+
+func TestChoice_JSON(t *testing.T) {
+	data, err := json.Marshal(Choice{
+		Message:      map[string]interface{}{"role": "assistant", "content": ""},
+		FinishReason: FinishReasonContentFilter,
+		Refusal:      "I can't help with that.",
+	})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded Choice
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.FinishReason != FinishReasonContentFilter || decoded.Refusal != "I can't help with that." || !decoded.Filtered() {
+		t.Errorf("unexpected round trip %+v", decoded)
+	}
+}
@@ -0,0 +1,196 @@
+package providers
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxCitations caps the citations kept on each choice when the request
+// does not set max_citations
+const DefaultMaxCitations = 5
+
+// Citation is a source a response was grounded on, such as a web search result
+type Citation struct {
+	Title  string         `json:"title,omitempty"`
+	URI    string         `json:"uri"`
+	Domain string         `json:"domain,omitempty"`
+	Score  float64        `json:"score,omitempty"` // Relevance, 0 to 1
+	Spans  []CitationSpan `json:"spans,omitempty"` // Parts of the answer attributed to this source
+}
+
+// CitationSpan is a range of the response text supported by a citation
+type CitationSpan struct {
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
+	Text       string `json:"text,omitempty"`
+}
+
+// CitationEvent carries the sources of a streamed answer as they arrive
+type CitationEvent struct {
+	Sources []Citation `json:"sources"`
+	Raw     []Citation `json:"raw,omitempty"` // Unprocessed sources, with include_raw_citations
+}
+
+// CitationOptions controls how grounding citations are shaped on a response
+type CitationOptions struct {
+	MaxCitations int  // Citations kept per choice; negative keeps all
+	IncludeRaw   bool // Expose the unprocessed list as response metadata "raw_citations"
+}
+
+// CitationOptionsFromConfig reads max_citations and include_raw_citations from
+// a request config map
+func CitationOptionsFromConfig(config RequestConfig) CitationOptions {
+	options := CitationOptions{MaxCitations: DefaultMaxCitations}
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return options
+	}
+
+	switch max := configMap["max_citations"].(type) {
+	case int:
+		options.MaxCitations = max
+	case float64:
+		options.MaxCitations = int(max)
+	}
+	if includeRaw, ok := configMap["include_raw_citations"].(bool); ok {
+		options.IncludeRaw = includeRaw
+	}
+	return options
+}
+
+// RankCitations dedupes citations by URL and then by domain, keeping the
+// highest scoring entry of each, and returns them ordered by score, capped at
+// max. Citations with equal scores keep their original order.
+func RankCitations(citations []Citation, max int) []Citation {
+	ranked := dedupeCitations(citations, func(c Citation) string { return normalizeCitationURL(c.URI) })
+	ranked = dedupeCitations(ranked, func(c Citation) string { return citationDomain(c) })
+
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+	if max >= 0 && len(ranked) > max {
+		ranked = ranked[:max]
+	}
+	return ranked
+}
+
+// ShapeCitations ranks the "citations" on each choice message of resp and,
+// when requested, records the unprocessed citations under the response
+// metadata key "raw_citations"
+func ShapeCitations(resp *ChatResponse, options CitationOptions) {
+	var raw []Citation
+	for _, choice := range resp.Choices {
+		citations := choice.Citations()
+		if citations == nil {
+			continue
+		}
+
+		raw = append(raw, citations...)
+		choice.messageMap()["citations"] = RankCitations(citations, options.MaxCitations)
+	}
+
+	if options.IncludeRaw && len(raw) > 0 {
+		if resp.Metadata == nil {
+			resp.Metadata = make(map[string]interface{})
+		}
+		resp.Metadata["raw_citations"] = raw
+	}
+}
+
+// CitationStream shapes the citations of a streamed answer the way
+// ShapeCitations shapes a response. Citation events are held back and sent
+// as one ranked EventCitation just ahead of the finished event; with
+// IncludeRaw, that event also carries the unprocessed citations as Raw.
+type CitationStream struct {
+	options CitationOptions
+	pending *StreamEvent // First held-back citation event, the template for the ranked one
+	raw     []Citation
+}
+
+// NewCitationStream creates a CitationStream applying options
+func NewCitationStream(options CitationOptions) *CitationStream {
+	return &CitationStream{options: options}
+}
+
+// Shape returns the events to send in place of event
+func (s *CitationStream) Shape(event StreamEvent) []StreamEvent {
+	switch event.Type {
+	case EventCitation:
+		if citation, ok := event.Data.(CitationEvent); ok {
+			if s.pending == nil {
+				s.pending = &event
+			}
+			s.raw = append(s.raw, citation.Sources...)
+			return nil
+		}
+	case EventFinished:
+		return append(s.Flush(), event)
+	}
+	return []StreamEvent{event}
+}
+
+// Flush returns the ranked citation event held back for a stream that ends
+// without a finished event, if any
+func (s *CitationStream) Flush() []StreamEvent {
+	if s.pending == nil {
+		return nil
+	}
+	event := *s.pending
+	citations := CitationEvent{Sources: RankCitations(s.raw, s.options.MaxCitations)}
+	if s.options.IncludeRaw {
+		citations.Raw = s.raw
+	}
+	event.Data = citations
+	s.pending, s.raw = nil, nil
+	return []StreamEvent{event}
+}
+
+// dedupeCitations keeps the highest scoring citation for each key, at the
+// position of its first occurrence, with the spans of all its duplicates.
+// Citations with an empty key are kept.
+func dedupeCitations(citations []Citation, key func(Citation) string) []Citation {
+	result := make([]Citation, 0, len(citations))
+	positions := make(map[string]int)
+	for _, citation := range citations {
+		k := key(citation)
+		if k == "" {
+			result = append(result, citation)
+			continue
+		}
+		if i, seen := positions[k]; seen {
+			spans := append(append([]CitationSpan(nil), result[i].Spans...), citation.Spans...)
+			if citation.Score > result[i].Score {
+				result[i] = citation
+			}
+			result[i].Spans = spans
+			continue
+		}
+		positions[k] = len(result)
+		result = append(result, citation)
+	}
+	return result
+}
+
+// normalizeCitationURL makes URLs that differ only in scheme case, host case,
+// fragment or trailing slash compare equal
+func normalizeCitationURL(uri string) string {
+	parsed, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || parsed.Host == "" {
+		return strings.TrimSpace(uri)
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Fragment = ""
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+	return parsed.String()
+}
+
+// citationDomain returns the citation's domain, derived from its URL when unset
+func citationDomain(citation Citation) string {
+	domain := citation.Domain
+	if domain == "" {
+		if parsed, err := url.Parse(citation.URI); err == nil {
+			domain = parsed.Hostname()
+		}
+	}
+	return strings.TrimPrefix(strings.ToLower(domain), "www.")
+}
@@ -0,0 +1,155 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRankCitations(t *testing.T) {
+	tests := []struct {
+		name      string
+		citations []Citation
+		max       int
+		want      []string
+	}{
+		{
+			name: "orders by score",
+			citations: []Citation{
+				{URI: "https://a.example/1", Score: 0.2},
+				{URI: "https://b.example/1", Score: 0.9},
+				{URI: "https://c.example/1", Score: 0.5},
+			},
+			max:  -1,
+			want: []string{"https://b.example/1", "https://c.example/1", "https://a.example/1"},
+		},
+		{
+			name: "dedupes equivalent URLs keeping the best score",
+			citations: []Citation{
+				{URI: "https://A.example/page/", Score: 0.3},
+				{URI: "https://a.example/page#section", Score: 0.8},
+				{URI: "https://b.example/", Score: 0.5},
+			},
+			max:  -1,
+			want: []string{"https://a.example/page#section", "https://b.example/"},
+		},
+		{
+			name: "dedupes by domain",
+			citations: []Citation{
+				{URI: "https://www.a.example/one", Score: 0.4},
+				{URI: "https://a.example/two", Score: 0.6},
+				{URI: "https://redirect.example/x", Domain: "c.example", Score: 0.5},
+				{URI: "https://redirect.example/y", Domain: "d.example", Score: 0.5},
+			},
+			max:  -1,
+			want: []string{"https://a.example/two", "https://redirect.example/x", "https://redirect.example/y"},
+		},
+		{
+			name: "caps the list",
+			citations: []Citation{
+				{URI: "https://a.example/", Score: 0.1},
+				{URI: "https://b.example/", Score: 0.2},
+				{URI: "https://c.example/", Score: 0.3},
+			},
+			max:  2,
+			want: []string{"https://c.example/", "https://b.example/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, citation := range RankCitations(tt.citations, tt.max) {
+				got = append(got, citation.URI)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RankCitations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankCitations_MergesSpans(t *testing.T) {
+	first := CitationSpan{StartIndex: 0, EndIndex: 4}
+	second := CitationSpan{StartIndex: 10, EndIndex: 20}
+	ranked := RankCitations([]Citation{
+		{URI: "https://a.example/page", Score: 0.2, Spans: []CitationSpan{first}},
+		{URI: "https://a.example/page/", Score: 0.8, Spans: []CitationSpan{second}},
+	}, -1)
+
+	if len(ranked) != 1 || ranked[0].Score != 0.8 {
+		t.Fatalf("expected the best duplicate, got %+v", ranked)
+	}
+	if !reflect.DeepEqual(ranked[0].Spans, []CitationSpan{first, second}) {
+		t.Errorf("expected the spans of both duplicates, got %+v", ranked[0].Spans)
+	}
+}
+
+func TestShapeCitations(t *testing.T) {
+	raw := []Citation{
+		{URI: "https://a.example/1", Score: 0.2},
+		{URI: "https://a.example/1/", Score: 0.4},
+		{URI: "https://b.example/1", Score: 0.9},
+	}
+	newResponse := func() *ChatResponse {
+		return &ChatResponse{Choices: []Choice{{
+			Message: map[string]interface{}{"role": "assistant", "citations": raw},
+		}}}
+	}
+
+	resp := newResponse()
+	ShapeCitations(resp, CitationOptionsFromConfig(map[string]interface{}{"max_citations": 1}))
+	citations := resp.Choices[0].Citations()
+	if len(citations) != 1 || citations[0].URI != "https://b.example/1" {
+		t.Errorf("Expected only the top citation, got %+v", citations)
+	}
+	if resp.Metadata != nil {
+		t.Errorf("Expected no raw citations unless requested, got %+v", resp.Metadata)
+	}
+
+	resp = newResponse()
+	ShapeCitations(resp, CitationOptionsFromConfig(map[string]interface{}{"include_raw_citations": true}))
+	if got := resp.Metadata["raw_citations"]; !reflect.DeepEqual(got, raw) {
+		t.Errorf("Expected the raw citation list in metadata, got %+v", got)
+	}
+}
+
+func TestCitationOptionsFromConfig(t *testing.T) {
+	if got := CitationOptionsFromConfig(nil); got.MaxCitations != DefaultMaxCitations || got.IncludeRaw {
+		t.Errorf("Unexpected defaults %+v", got)
+	}
+	// Configs decoded from JSON carry numbers as float64
+	if got := CitationOptionsFromConfig(map[string]interface{}{"max_citations": float64(3)}); got.MaxCitations != 3 {
+		t.Errorf("Expected max_citations 3, got %+v", got)
+	}
+}
+
+func TestCitationStream(t *testing.T) {
+	citation := func(uri string, score float64) StreamEvent {
+		return StreamEvent{Type: EventCitation, Model: "m", Data: CitationEvent{Sources: []Citation{{URI: uri, Score: score}}}}
+	}
+	stream := NewCitationStream(CitationOptions{MaxCitations: DefaultMaxCitations})
+
+	var shaped []StreamEvent
+	for _, event := range []StreamEvent{
+		{Type: EventContent},
+		citation("https://a.example/1", 0.2),
+		citation("https://b.example/1", 0.9),
+		citation("https://a.example/1/", 0.5),
+		{Type: EventFinished},
+	} {
+		shaped = append(shaped, stream.Shape(event)...)
+	}
+	if len(shaped) != 3 || shaped[1].Type != EventCitation || shaped[1].Model != "m" || shaped[2].Type != EventFinished {
+		t.Fatalf("Expected content, one citation event and finished, got %+v", shaped)
+	}
+	sources := shaped[1].Data.(CitationEvent).Sources
+	if len(sources) != 2 || sources[0].URI != "https://b.example/1" || sources[1].Score != 0.5 {
+		t.Errorf("Expected deduped citations ranked by score, got %+v", sources)
+	}
+
+	// A stream that ends without finishing still delivers its citations
+	stream.Shape(citation("https://c.example/1", 0.1))
+	if flushed := stream.Flush(); len(flushed) != 1 || stream.Flush() != nil {
+		t.Errorf("Expected one flushed citation event, got %+v", flushed)
+	}
+}
@@ -0,0 +1,97 @@
+// Package deepseek provides DeepSeek models through their OpenAI-compatible API
+package deepseek
+
+import (
+	"net/http"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+	"gomini/pkg/gomini/providers/openai"
+)
+
+// DefaultBaseURL is DeepSeek's OpenAI-compatible endpoint
+const DefaultBaseURL = "https://api.deepseek.com/v1"
+
+// Config holds DeepSeek-specific configuration
+type Config struct {
+	APIKey       string                 `json:"api_key"`
+	BaseURL      string                 `json:"base_url,omitempty"`
+	DefaultModel string                 `json:"default_model,omitempty"`
+	ExtraHeaders map[string]string      `json:"extra_headers,omitempty"`
+	ExtraQuery   map[string]string      `json:"extra_query,omitempty"`
+	ExtraBody    map[string]interface{} `json:"extra_body,omitempty"`
+	HTTPClient   *http.Client           `json:"-"`
+	Timeout      time.Duration          `json:"timeout,omitempty"`
+
+	// Capability overrides and model discovery; see openai.Config
+	Capabilities   providers.CapabilityTable `json:"capabilities,omitempty"`
+	DiscoverModels bool                      `json:"discover_models,omitempty"`
+	ModelsTTL      time.Duration             `json:"models_ttl,omitempty"`
+}
+
+// NewProvider creates a DeepSeek provider. The reasoning_content that
+// deepseek-reasoner streams is reported as thought events.
+func NewProvider(config *Config) (*openai.Provider, error) {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return openai.NewProvider(&openai.Config{
+		APIKey:       config.APIKey,
+		BaseURL:      baseURL,
+		DefaultModel: config.DefaultModel,
+		ExtraHeaders: config.ExtraHeaders,
+		ExtraQuery:   config.ExtraQuery,
+		ExtraBody:    config.ExtraBody,
+		HTTPClient:   config.HTTPClient,
+		Timeout:      config.Timeout,
+		ProviderType: providers.ProviderDeepSeek,
+		Models:       Models(),
+
+		Capabilities:   config.Capabilities,
+		DiscoverModels: config.DiscoverModels,
+		ModelsTTL:      config.ModelsTTL,
+	})
+}
+
+// Models returns the DeepSeek model catalog
+func Models() []providers.Model {
+	return []providers.Model{
+		{
+			ID:       "deepseek-chat",
+			Name:     "DeepSeek V3",
+			Provider: providers.ProviderDeepSeek,
+			Capabilities: providers.ModelCapabilities{
+				TextGeneration:  true,
+				FunctionCalling: true,
+				JSONMode:        true,
+				SystemMessage:   true,
+				Streaming:       true,
+			},
+			ContextSize: 64000,
+			Cost: &providers.ModelCost{
+				InputTokens:  0.27, // $0.27 per 1M input tokens
+				OutputTokens: 1.1,  // $1.10 per 1M output tokens
+				Currency:     "USD",
+			},
+		},
+		{
+			ID:       "deepseek-reasoner",
+			Name:     "DeepSeek R1",
+			Provider: providers.ProviderDeepSeek,
+			Capabilities: providers.ModelCapabilities{
+				TextGeneration: true,
+				SystemMessage:  true,
+				Streaming:      true,
+				ThinkingMode:   true,
+			},
+			ContextSize: 64000,
+			Cost: &providers.ModelCost{
+				InputTokens:  0.55, // $0.55 per 1M input tokens
+				OutputTokens: 2.19, // $2.19 per 1M output tokens
+				Currency:     "USD",
+			},
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package deepseek
+
+import (
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+)
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(&Config{}); err == nil {
+		t.Error("Expected an error without an API key")
+	}
+
+	provider, err := NewProvider(&Config{APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	if provider.GetProviderType() != providers.ProviderDeepSeek {
+		t.Errorf("Expected provider type deepseek, got %s", provider.GetProviderType())
+	}
+
+	capabilities := provider.GetCapabilities()
+	if len(capabilities.Models) != 2 || capabilities.SpecificFeatures["reasoning"] != "true" {
+		t.Errorf("Unexpected capabilities %+v", capabilities)
+	}
+	if _, ok := providers.FindModel(Models(), "deepseek-reasoner"); !ok {
+		t.Error("Expected deepseek-reasoner in the catalog")
+	}
+}
@@ -0,0 +1,112 @@
+package providers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxDocumentBytes is the largest document accepted in a content part
+	MaxDocumentBytes = 50 << 20 // 50MB, the Gemini per-PDF limit
+	// DefaultInlineDocumentBytes is the size above which documents are uploaded instead of sent inline
+	DefaultInlineDocumentBytes = 15 << 20 // keeps requests under the 20MB inline payload limit
+)
+
+// DocumentMIMETypes lists the document types understood by document-capable models
+var DocumentMIMETypes = []string{
+	"application/pdf",
+	"text/plain", "text/markdown", "text/html", "text/csv", "text/xml", "text/rtf",
+	"application/json", "application/x-javascript", "text/javascript", "application/x-python", "text/x-python",
+}
+
+// DocumentPart is a document (PDF, plain text, ...) sent to a model as a content
+// part. Either Data holds the bytes or URI references an already uploaded file
+// (a gs:// object or a Gemini Files API URI). In map form it is
+// {"type": "document", "data": {"base64"|"uri": ..., "mime_type": ..., "name": ...}}.
+type DocumentPart struct {
+	MIMEType string `json:"mime_type"`
+	Data     []byte `json:"data,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Name     string `json:"name,omitempty"` // Display name, e.g. the file name
+}
+
+// Validate checks the document type and size
+func (d DocumentPart) Validate() error {
+	if d.URI == "" && len(d.Data) == 0 {
+		return fmt.Errorf("document has no data or URI")
+	}
+	if len(d.Data) > MaxDocumentBytes {
+		return fmt.Errorf("document exceeds %d bytes", MaxDocumentBytes)
+	}
+	if !IsDocumentMIMEType(d.MIMEType) {
+		return fmt.Errorf("unsupported document type %q", d.MIMEType)
+	}
+	return nil
+}
+
+// IsText reports whether the document is plain text that can be sent as a text part
+func (d DocumentPart) IsText() bool {
+	return strings.HasPrefix(d.MIMEType, "text/") || d.MIMEType == "application/json"
+}
+
+// MarshalJSON encodes the part in its map form so persisted sessions keep
+// the document recognizable after a round trip
+func (d DocumentPart) MarshalJSON() ([]byte, error) {
+	data := map[string]interface{}{"mime_type": d.MIMEType}
+	if len(d.Data) > 0 {
+		data["base64"] = base64.StdEncoding.EncodeToString(d.Data)
+	}
+	if d.URI != "" {
+		data["uri"] = d.URI
+	}
+	if d.Name != "" {
+		data["name"] = d.Name
+	}
+	return json.Marshal(map[string]interface{}{"type": "document", "data": data})
+}
+
+// IsDocumentMIMEType reports whether mimeType is a supported document type
+func IsDocumentMIMEType(mimeType string) bool {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, allowed := range DocumentMIMETypes {
+		if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// AsDocumentPart extracts a document part from a content item. The second
+// result is false when the item is not a document.
+func AsDocumentPart(item interface{}) (DocumentPart, bool, error) {
+	switch part := item.(type) {
+	case DocumentPart:
+		return part, true, part.Validate()
+	case *DocumentPart:
+		if part == nil {
+			return DocumentPart{}, false, nil
+		}
+		return *part, true, part.Validate()
+	case map[string]interface{}:
+		if part["type"] != "document" {
+			return DocumentPart{}, false, nil
+		}
+		data, _ := part["data"].(map[string]interface{})
+		doc := DocumentPart{}
+		doc.MIMEType, _ = data["mime_type"].(string)
+		doc.URI, _ = data["uri"].(string)
+		doc.Name, _ = data["name"].(string)
+		if encoded, ok := data["base64"].(string); ok && encoded != "" {
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return DocumentPart{}, true, fmt.Errorf("invalid base64 document data: %w", err)
+			}
+			doc.Data = decoded
+		}
+		return doc, true, doc.Validate()
+	}
+	return DocumentPart{}, false, nil
+}
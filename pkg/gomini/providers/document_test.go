@@ -0,0 +1,58 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAsDocumentPart(t *testing.T) {
+	tests := []struct {
+		name        string
+		item        interface{}
+		isDocument  bool
+		expectError bool
+	}{
+		{name: "struct", item: DocumentPart{MIMEType: "application/pdf", Data: []byte("%PDF")}, isDocument: true},
+		{name: "uri", item: &DocumentPart{MIMEType: "application/pdf", URI: "gs://bucket/a.pdf"}, isDocument: true},
+		{
+			name:       "map",
+			item:       map[string]interface{}{"type": "document", "data": map[string]interface{}{"base64": "JVBERg==", "mime_type": "application/pdf"}},
+			isDocument: true,
+		},
+		{name: "unsupported type", item: DocumentPart{MIMEType: "application/zip", Data: []byte("PK")}, isDocument: true, expectError: true},
+		{name: "empty", item: DocumentPart{MIMEType: "application/pdf"}, isDocument: true, expectError: true},
+		{name: "too large", item: DocumentPart{MIMEType: "text/plain", Data: make([]byte, MaxDocumentBytes+1)}, isDocument: true, expectError: true},
+		{name: "image part", item: map[string]interface{}{"type": "image_url"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, isDocument, err := AsDocumentPart(tt.item)
+			if isDocument != tt.isDocument {
+				t.Errorf("expected isDocument=%v, got %v", tt.isDocument, isDocument)
+			}
+			if (err != nil) != tt.expectError {
+				t.Errorf("expected error=%v, got %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestDocumentPart_JSONRoundTrip(t *testing.T) {
+	raw, err := json.Marshal(DocumentPart{MIMEType: "application/pdf", Data: []byte("%PDF"), Name: "a.pdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var item interface{}
+	if err := json.Unmarshal(raw, &item); err != nil {
+		t.Fatal(err)
+	}
+	doc, isDocument, err := AsDocumentPart(item)
+	if !isDocument || err != nil {
+		t.Fatalf("expected document after round trip, got %v (err %v)", item, err)
+	}
+	if doc.Name != "a.pdf" || string(doc.Data) != "%PDF" {
+		t.Errorf("unexpected document %+v", doc)
+	}
+}
@@ -0,0 +1,483 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrorCode represents different types of errors
+type ErrorCode string
+
+const (
+	// Authentication errors
+	ErrorInvalidAPIKey ErrorCode = "invalid_api_key"
+	ErrorInvalidAuth   ErrorCode = "invalid_auth"
+	ErrorAuthRequired  ErrorCode = "auth_required"
+
+	// Request errors
+	ErrorInvalidRequest     ErrorCode = "invalid_request"
+	ErrorInvalidModel       ErrorCode = "invalid_model"
+	ErrorInvalidParameters  ErrorCode = "invalid_parameters"
+	ErrorRequestTooLarge    ErrorCode = "request_too_large"
+	ErrorUnsupportedFeature ErrorCode = "unsupported_feature"
+	ErrorToolDisabled       ErrorCode = "tool_disabled"
+
+	// Rate limiting errors
+	ErrorRateLimit       ErrorCode = "rate_limit"
+	ErrorQuotaExceeded   ErrorCode = "quota_exceeded"
+	ErrorTooManyRequests ErrorCode = "too_many_requests"
+
+	// Server errors
+	ErrorServerError        ErrorCode = "server_error"
+	ErrorServiceUnavailable ErrorCode = "service_unavailable"
+	ErrorTimeout            ErrorCode = "timeout"
+	ErrorInternalError      ErrorCode = "internal_error"
+
+	// Content errors
+	ErrorContentFiltered    ErrorCode = "content_filtered"
+	ErrorSafetyViolation    ErrorCode = "safety_violation"
+	ErrorTokenLimitExceeded ErrorCode = "token_limit_exceeded"
+
+	// Provider errors
+	ErrorProviderNotFound   ErrorCode = "provider_not_found"
+	ErrorProviderDisabled   ErrorCode = "provider_disabled"
+	ErrorProviderSwitch     ErrorCode = "provider_switch"
+	ErrorAllProvidersFailed ErrorCode = "all_providers_failed"
+	ErrorBudgetExceeded     ErrorCode = "budget_exceeded"
+
+	// Network errors
+	ErrorNetworkError     ErrorCode = "network_error"
+	ErrorConnectionFailed ErrorCode = "connection_failed"
+	ErrorDNSError         ErrorCode = "dns_error"
+
+	// Validation errors
+	ErrorValidation    ErrorCode = "validation_error"
+	ErrorMissingField  ErrorCode = "missing_field"
+	ErrorInvalidFormat ErrorCode = "invalid_format"
+
+	// Unknown errors
+	ErrorUnknown ErrorCode = "unknown_error"
+)
+
+// Sentinel errors, one per ErrorCode. errors.Is matches any LLMError with
+// the same code, wherever it sits in the chain:
+//
+//	if errors.Is(err, providers.ErrRateLimit) { ... }
+var (
+	ErrInvalidAPIKey = NewLLMError(ErrorInvalidAPIKey, "Invalid API key", "", nil)
+	ErrInvalidAuth   = NewLLMError(ErrorInvalidAuth, "Invalid authentication", "", nil)
+	ErrAuthRequired  = NewLLMError(ErrorAuthRequired, "Authentication required", "", nil)
+
+	ErrInvalidRequest     = NewLLMError(ErrorInvalidRequest, "Invalid request", "", nil)
+	ErrInvalidModel       = NewLLMError(ErrorInvalidModel, "Invalid model", "", nil)
+	ErrInvalidParameters  = NewLLMError(ErrorInvalidParameters, "Invalid parameters", "", nil)
+	ErrRequestTooLarge    = NewLLMError(ErrorRequestTooLarge, "Request too large", "", nil)
+	ErrUnsupportedFeature = NewLLMError(ErrorUnsupportedFeature, "Feature not supported", "", nil)
+	ErrToolDisabled       = NewLLMError(ErrorToolDisabled, "Tool is disabled", "", nil)
+
+	ErrRateLimit       = NewLLMError(ErrorRateLimit, "Rate limit exceeded", "", nil)
+	ErrQuotaExceeded   = NewLLMError(ErrorQuotaExceeded, "Quota exceeded", "", nil)
+	ErrTooManyRequests = NewLLMError(ErrorTooManyRequests, "Too many requests", "", nil)
+
+	ErrServerError        = NewLLMError(ErrorServerError, "Server error", "", nil)
+	ErrServiceUnavailable = NewLLMError(ErrorServiceUnavailable, "Service unavailable", "", nil)
+	ErrTimeout            = NewLLMError(ErrorTimeout, "Request timeout", "", nil)
+	ErrInternalError      = NewLLMError(ErrorInternalError, "Internal error", "", nil)
+
+	ErrContentFiltered    = NewLLMError(ErrorContentFiltered, "Content filtered", "", nil)
+	ErrSafetyViolation    = NewLLMError(ErrorSafetyViolation, "Safety violation", "", nil)
+	ErrTokenLimitExceeded = NewLLMError(ErrorTokenLimitExceeded, "Token limit exceeded", "", nil)
+
+	ErrProviderNotFound   = NewLLMError(ErrorProviderNotFound, "Provider not found", "", nil)
+	ErrProviderDisabled   = NewLLMError(ErrorProviderDisabled, "Provider is disabled", "", nil)
+	ErrProviderSwitch     = NewLLMError(ErrorProviderSwitch, "Provider switch failed", "", nil)
+	ErrAllProvidersFailed = NewLLMError(ErrorAllProvidersFailed, "All providers failed", "", nil)
+	ErrBudgetExceeded     = NewLLMError(ErrorBudgetExceeded, "Cost budget exceeded", "", nil)
+
+	ErrNetworkError     = NewLLMError(ErrorNetworkError, "Network error", "", nil)
+	ErrConnectionFailed = NewLLMError(ErrorConnectionFailed, "Connection failed", "", nil)
+	ErrDNSError         = NewLLMError(ErrorDNSError, "DNS resolution error", "", nil)
+
+	ErrValidation    = NewLLMError(ErrorValidation, "Validation failed", "", nil)
+	ErrMissingField  = NewLLMError(ErrorMissingField, "Missing field", "", nil)
+	ErrInvalidFormat = NewLLMError(ErrorInvalidFormat, "Invalid format", "", nil)
+
+	ErrUnknown = NewLLMError(ErrorUnknown, "Unknown error", "", nil)
+)
+
+// LLMError represents a unified error from any LLM provider
+type LLMError struct {
+	Code       ErrorCode              `json:"code"`
+	Message    string                 `json:"message"`
+	Provider   ProviderType           `json:"provider,omitempty"`
+	Model      string                 `json:"model,omitempty"`
+	HTTPStatus int                    `json:"http_status,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	Retryable  bool                   `json:"retryable"`
+	RetryAfter *time.Duration         `json:"retry_after,omitempty"`
+	Cause      error                  `json:"-"` // Original error
+	Timestamp  time.Time              `json:"timestamp"`
+	RequestID  string                 `json:"request_id,omitempty"`
+}
+
+// Error implements the error interface
+func (e *LLMError) Error() string {
+	if e.Provider != "" {
+		return fmt.Sprintf("[%s:%s] %s", e.Provider, e.Code, e.Message)
+	}
+	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying error
+func (e *LLMError) Unwrap() error {
+	return e.Cause
+}
+
+// Is checks if the error matches the target error type
+func (e *LLMError) Is(target error) bool {
+	if t, ok := target.(*LLMError); ok {
+		return e.Code == t.Code
+	}
+	return false
+}
+
+// IsRetryable returns true if the error is retryable
+func (e *LLMError) IsRetryable() bool {
+	return e.Retryable
+}
+
+// IsRateLimit returns true if the error is due to rate limiting
+func (e *LLMError) IsRateLimit() bool {
+	return e.Code == ErrorRateLimit || e.Code == ErrorQuotaExceeded || e.Code == ErrorTooManyRequests
+}
+
+// IsAuthError returns true if the error is authentication-related
+func (e *LLMError) IsAuthError() bool {
+	return e.Code == ErrorInvalidAPIKey || e.Code == ErrorInvalidAuth || e.Code == ErrorAuthRequired
+}
+
+// IsContentError returns true if the error is content-related (filtering, safety)
+func (e *LLMError) IsContentError() bool {
+	return e.Code == ErrorContentFiltered || e.Code == ErrorSafetyViolation
+}
+
+// IsProviderError returns true if the error is provider-related
+func (e *LLMError) IsProviderError() bool {
+	return e.Code == ErrorProviderNotFound || e.Code == ErrorProviderDisabled ||
+		e.Code == ErrorProviderSwitch || e.Code == ErrorAllProvidersFailed
+}
+
+// NewLLMError creates a new LLMError
+func NewLLMError(code ErrorCode, message string, provider ProviderType, cause error) *LLMError {
+	return &LLMError{
+		Code:      code,
+		Message:   message,
+		Provider:  provider,
+		Cause:     cause,
+		Timestamp: time.Now(),
+		Retryable: isRetryableErrorCode(code),
+	}
+}
+
+// NewLLMErrorWithDetails creates a new LLMError with additional details
+func NewLLMErrorWithDetails(code ErrorCode, message string, provider ProviderType, cause error, details map[string]interface{}) *LLMError {
+	llmErr := NewLLMError(code, message, provider, cause)
+	llmErr.Details = details
+	return llmErr
+}
+
+// WrapProviderError classifies an error returned by a provider's API into
+// an LLMError. It returns an untyped nil for a nil err, so adapters can
+// return it directly.
+func WrapProviderError(err error, provider ProviderType, model string) error {
+	if err == nil {
+		return nil
+	}
+	return ClassifyError(err, provider, model)
+}
+
+// ClassifyError returns err as an LLMError of provider and model. An LLMError
+// already in err's chain keeps its code; other errors are classified from
+// their decoded APIError, or failing that their text.
+func ClassifyError(err error, provider ProviderType, model string) *LLMError {
+	if err == nil {
+		return nil
+	}
+
+	var llmErr *LLMError
+	if errors.As(err, &llmErr) {
+		// Copy, so shared errors such as the sentinels are never modified
+		classified := *llmErr
+		classified.Provider = provider
+		if model != "" {
+			classified.Model = model
+		}
+		return &classified
+	}
+
+	// Error responses decoded from the SDKs classify by status and code
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return wrapAPIError(err, apiErr, provider, model)
+	}
+
+	// Map provider-specific errors to unified error codes
+	code, httpStatus, retryable := classifyError(err, provider)
+
+	return &LLMError{
+		Code:       code,
+		Message:    err.Error(),
+		Provider:   provider,
+		Model:      model,
+		HTTPStatus: httpStatus,
+		Cause:      err,
+		Retryable:  retryable,
+		Timestamp:  time.Now(),
+	}
+}
+
+// wrapAPIError builds an LLMError from a decoded API error response, keeping
+// its type, code and request ID in Details
+func wrapAPIError(err error, apiErr *APIError, provider ProviderType, model string) *LLMError {
+	code := classifyAPIError(apiErr)
+	message := apiErr.Message
+	if message == "" {
+		message = http.StatusText(apiErr.StatusCode)
+	}
+
+	details := map[string]interface{}{"status_code": apiErr.StatusCode}
+	for key, value := range map[string]string{"type": apiErr.Type, "code": apiErr.Code, "param": apiErr.Param} {
+		if value != "" {
+			details[key] = value
+		}
+	}
+	llmErr := &LLMError{
+		Code:       code,
+		Message:    message,
+		Provider:   provider,
+		Model:      model,
+		HTTPStatus: apiErr.StatusCode,
+		Details:    details,
+		Retryable:  isRetryableErrorCode(code),
+		Cause:      err,
+		Timestamp:  time.Now(),
+		RequestID:  apiErr.RequestID,
+	}
+	if apiErr.RetryAfter > 0 {
+		retryAfter := apiErr.RetryAfter
+		llmErr.RetryAfter = &retryAfter
+	}
+	return llmErr
+}
+
+// classifyAPIError maps an API error's code, type and HTTP status to an ErrorCode
+func classifyAPIError(apiErr *APIError) ErrorCode {
+	switch strings.ToLower(apiErr.Code) {
+	case "context_length_exceeded", "string_above_max_length":
+		return ErrorTokenLimitExceeded
+	case "insufficient_quota":
+		return ErrorQuotaExceeded
+	case "rate_limit_exceeded", "rate_limit_exceeded_per_model":
+		return ErrorRateLimit
+	case "invalid_api_key", "api_key_invalid", "api_key_expired":
+		return ErrorInvalidAPIKey
+	case "model_not_found":
+		return ErrorInvalidModel
+	case "content_filter", "content_policy_violation":
+		return ErrorContentFiltered
+	}
+
+	switch apiErr.Type {
+	case "RESOURCE_EXHAUSTED":
+		return ErrorRateLimit
+	case "UNAUTHENTICATED":
+		return ErrorInvalidAPIKey
+	case "PERMISSION_DENIED":
+		return ErrorInvalidAuth
+	case "INVALID_ARGUMENT", "FAILED_PRECONDITION":
+		if isTokenLimitMessage(strings.ToLower(apiErr.Message)) {
+			return ErrorTokenLimitExceeded
+		}
+		return ErrorInvalidParameters
+	case "DEADLINE_EXCEEDED":
+		return ErrorTimeout
+	case "UNAVAILABLE":
+		return ErrorServiceUnavailable
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusBadRequest:
+		if isTokenLimitMessage(strings.ToLower(apiErr.Message)) {
+			return ErrorTokenLimitExceeded
+		}
+	case http.StatusRequestEntityTooLarge:
+		return ErrorRequestTooLarge
+	case http.StatusRequestTimeout:
+		return ErrorTimeout
+	}
+	return HTTPStatusToErrorCode(apiErr.StatusCode)
+}
+
+// isTokenLimitMessage reports whether a lowercased error message describes a
+// context-window overflow
+func isTokenLimitMessage(errStr string) bool {
+	return strings.Contains(errStr, "token limit") || strings.Contains(errStr, "too long") ||
+		strings.Contains(errStr, "context length") || strings.Contains(errStr, "context_length_exceeded")
+}
+
+// classifyError attempts to classify a provider-specific error from its text
+func classifyError(err error, provider ProviderType) (ErrorCode, int, bool) {
+	errStr := strings.ToLower(err.Error())
+
+	// Context-window overflows are reported as bad requests; check them first
+	if isTokenLimitMessage(errStr) {
+		return ErrorTokenLimitExceeded, 400, false
+	}
+
+	// Common HTTP status-based classification
+	if strings.Contains(errStr, "401") || strings.Contains(errStr, "unauthorized") {
+		return ErrorInvalidAPIKey, 401, false
+	}
+
+	if strings.Contains(errStr, "400") || strings.Contains(errStr, "bad request") {
+		return ErrorInvalidRequest, 400, false
+	}
+
+	if strings.Contains(errStr, "403") || strings.Contains(errStr, "forbidden") {
+		return ErrorInvalidAuth, 403, false
+	}
+
+	if strings.Contains(errStr, "404") || strings.Contains(errStr, "not found") {
+		return ErrorInvalidModel, 404, false
+	}
+
+	if strings.Contains(errStr, "429") || strings.Contains(errStr, "rate limit") || strings.Contains(errStr, "quota") {
+		return ErrorRateLimit, 429, true
+	}
+
+	if strings.Contains(errStr, "500") || strings.Contains(errStr, "internal server error") {
+		return ErrorServerError, 500, true
+	}
+
+	if strings.Contains(errStr, "502") || strings.Contains(errStr, "bad gateway") {
+		return ErrorServerError, 502, true
+	}
+
+	if strings.Contains(errStr, "503") || strings.Contains(errStr, "service unavailable") {
+		return ErrorServiceUnavailable, 503, true
+	}
+
+	if strings.Contains(errStr, "504") || strings.Contains(errStr, "timeout") {
+		return ErrorTimeout, 504, true
+	}
+
+	// Content-related errors
+	if strings.Contains(errStr, "content filter") || strings.Contains(errStr, "safety") {
+		return ErrorContentFiltered, 400, false
+	}
+
+	// Network errors
+	if strings.Contains(errStr, "connection") || strings.Contains(errStr, "network") {
+		return ErrorNetworkError, 0, true
+	}
+
+	if strings.Contains(errStr, "dns") {
+		return ErrorDNSError, 0, true
+	}
+
+	// Provider-specific error handling
+	switch provider {
+	case ProviderOpenAI:
+		return classifyOpenAIError(errStr)
+	case ProviderGemini:
+		return classifyGeminiError(errStr)
+	}
+
+	// Default to unknown error
+	return ErrorUnknown, 0, false
+}
+
+// classifyOpenAIError handles OpenAI-specific error classification
+func classifyOpenAIError(errStr string) (ErrorCode, int, bool) {
+	if strings.Contains(errStr, "insufficient_quota") {
+		return ErrorQuotaExceeded, 429, true
+	}
+
+	if strings.Contains(errStr, "model_not_found") {
+		return ErrorInvalidModel, 404, false
+	}
+
+	if strings.Contains(errStr, "invalid_request_error") {
+		return ErrorInvalidRequest, 400, false
+	}
+
+	if strings.Contains(errStr, "rate_limit_exceeded") {
+		return ErrorRateLimit, 429, true
+	}
+
+	return ErrorUnknown, 0, false
+}
+
+// classifyGeminiError handles Gemini-specific error classification
+func classifyGeminiError(errStr string) (ErrorCode, int, bool) {
+	if strings.Contains(errStr, "recitation") || strings.Contains(errStr, "blocked") {
+		return ErrorContentFiltered, 400, false
+	}
+
+	if strings.Contains(errStr, "safety") {
+		return ErrorSafetyViolation, 400, false
+	}
+
+	if strings.Contains(errStr, "resource_exhausted") {
+		return ErrorQuotaExceeded, 429, true
+	}
+
+	if strings.Contains(errStr, "invalid_argument") {
+		return ErrorInvalidParameters, 400, false
+	}
+
+	return ErrorUnknown, 0, false
+}
+
+// isRetryableErrorCode determines if an error code is retryable
+func isRetryableErrorCode(code ErrorCode) bool {
+	switch code {
+	case ErrorRateLimit, ErrorQuotaExceeded, ErrorTooManyRequests,
+		ErrorServerError, ErrorServiceUnavailable, ErrorTimeout,
+		ErrorNetworkError, ErrorConnectionFailed, ErrorDNSError:
+		return true
+	}
+	return false
+}
+
+// HTTPStatusToErrorCode maps HTTP status codes to error codes
+func HTTPStatusToErrorCode(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorInvalidRequest
+	case http.StatusUnauthorized:
+		return ErrorInvalidAPIKey
+	case http.StatusForbidden:
+		return ErrorInvalidAuth
+	case http.StatusNotFound:
+		return ErrorInvalidModel
+	case http.StatusTooManyRequests:
+		return ErrorRateLimit
+	case http.StatusInternalServerError:
+		return ErrorServerError
+	case http.StatusBadGateway:
+		return ErrorServerError
+	case http.StatusServiceUnavailable:
+		return ErrorServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrorTimeout
+	default:
+		if status >= 500 {
+			return ErrorServerError
+		}
+		return ErrorUnknown
+	}
+}
@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestWrapProviderError_Typed(t *testing.T) {
+	if err := WrapProviderError(nil, ProviderOpenAI, "gpt-4o"); err != nil {
+		t.Fatalf("Expected an untyped nil, got %#v", err)
+	}
+
+	err := WrapProviderError(fmt.Errorf("429 rate limit exceeded"), ProviderOpenAI, "gpt-4o")
+	var llmErr *LLMError
+	if !errors.As(err, &llmErr) {
+		t.Fatalf("Expected an LLMError, got %T", err)
+	}
+	if llmErr.Code != ErrorRateLimit || !llmErr.Retryable || llmErr.Message != "429 rate limit exceeded" {
+		t.Errorf("Unexpected error: %+v", llmErr)
+	}
+	if !errors.Is(err, ErrRateLimit) || errors.Is(err, ErrServerError) {
+		t.Errorf("Expected err to match only ErrRateLimit, got %v", err)
+	}
+}
+
+func TestClassifyError_KeepsCodeThroughWrapping(t *testing.T) {
+	inner := NewLLMError(ErrorUnsupportedFeature, "audio input is not supported", ProviderGemini, nil)
+	wrapped := fmt.Errorf("adapt request: %w", inner)
+
+	got := ClassifyError(wrapped, ProviderOpenAI, "gpt-4o")
+	if got.Code != ErrorUnsupportedFeature || got.Provider != ProviderOpenAI || got.Model != "gpt-4o" {
+		t.Errorf("Unexpected classification: %+v", got)
+	}
+	if inner.Provider != ProviderGemini {
+		t.Error("Expected the wrapped error to be left unchanged")
+	}
+
+	// Sentinels returned as-is are copied rather than tagged in place
+	ClassifyError(ErrBudgetExceeded, ProviderOpenAI, "gpt-4o")
+	if ErrBudgetExceeded.Provider != "" || ErrBudgetExceeded.Model != "" {
+		t.Errorf("Expected the sentinel to be unchanged, got %+v", ErrBudgetExceeded)
+	}
+}
+
+func TestSentinels(t *testing.T) {
+	sentinels := map[ErrorCode]*LLMError{
+		ErrorInvalidAPIKey:      ErrInvalidAPIKey,
+		ErrorQuotaExceeded:      ErrQuotaExceeded,
+		ErrorTokenLimitExceeded: ErrTokenLimitExceeded,
+		ErrorServiceUnavailable: ErrServiceUnavailable,
+		ErrorUnknown:            ErrUnknown,
+	}
+	for code, sentinel := range sentinels {
+		if sentinel.Code != code {
+			t.Errorf("Sentinel for %s has code %s", code, sentinel.Code)
+		}
+		if !errors.Is(fmt.Errorf("call failed: %w", NewLLMError(code, "failed", ProviderOpenAI, nil)), sentinel) {
+			t.Errorf("Expected a wrapped %s error to match its sentinel", code)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ExtraRequest holds query parameters and body fields added to every HTTP
+// request a provider sends, for gateways that require them
+type ExtraRequest struct {
+	Query map[string]string
+	Body  map[string]interface{}
+}
+
+// Empty reports whether there is nothing to add
+func (e ExtraRequest) Empty() bool {
+	return len(e.Query) == 0 && len(e.Body) == 0
+}
+
+// Apply returns req with the extra query parameters set and the extra body
+// fields merged over the top level of a JSON object body. Other bodies are
+// sent unchanged.
+func (e ExtraRequest) Apply(req *http.Request) (*http.Request, error) {
+	if e.Empty() {
+		return req, nil
+	}
+	req = req.Clone(req.Context())
+
+	if len(e.Query) > 0 {
+		query := req.URL.Query()
+		for key, value := range e.Query {
+			query.Set(key, value)
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+
+	if len(e.Body) == 0 || req.Body == nil || req.Body == http.NoBody ||
+		!strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return req, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if json.Unmarshal(body, &fields) == nil && fields != nil {
+		for key, value := range e.Body {
+			fields[key] = value
+		}
+		if merged, err := json.Marshal(fields); err == nil {
+			body = merged
+		}
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return req, nil
+}
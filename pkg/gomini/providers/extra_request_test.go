@@ -0,0 +1,44 @@
+package providers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExtraRequest_Apply(t *testing.T) {
+	extra := ExtraRequest{
+		Query: map[string]string{"api-version": "1"},
+		Body:  map[string]interface{}{"route": "fast", "model": "override"},
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"json object", "application/json", `{"model":"gpt-4o"}`, `{"model":"override","route":"fast"}`},
+		{"json array", "application/json", `[1,2]`, `[1,2]`},
+		{"multipart", "multipart/form-data; boundary=x", `--x--`, `--x--`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "https://api.example/v1/chat?keep=yes", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			applied, err := extra.Apply(req)
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			if got := applied.URL.Query(); got.Get("api-version") != "1" || got.Get("keep") != "yes" {
+				t.Errorf("unexpected query %q", applied.URL.RawQuery)
+			}
+			body, _ := io.ReadAll(applied.Body)
+			if string(body) != tt.want || applied.ContentLength != int64(len(tt.want)) {
+				t.Errorf("expected body %s, got %s (length %d)", tt.want, body, applied.ContentLength)
+			}
+		})
+	}
+}
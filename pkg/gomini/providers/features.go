@@ -0,0 +1,164 @@
+package providers
+
+// Features lists the optional interfaces a provider implements
+type Features struct {
+	JSONStream bool `json:"json_stream,omitempty"` // JSONStreamer
+	Moderation bool `json:"moderation,omitempty"`  // Moderator
+	Images     bool `json:"images,omitempty"`      // ImageGenerator
+	Audio      bool `json:"audio,omitempty"`       // AudioProvider
+}
+
+// FeaturesOf returns the optional interfaces provider implements
+func FeaturesOf(provider LLMProvider) Features {
+	_, streamer := provider.(JSONStreamer)
+	_, moderator := provider.(Moderator)
+	_, generator := provider.(ImageGenerator)
+	_, audio := provider.(AudioProvider)
+	return Features{JSONStream: streamer, Moderation: moderator, Images: generator, Audio: audio}
+}
+
+// Expose returns wrapper restricted to the optional interfaces (JSONStreamer,
+// Moderator, ImageGenerator and AudioProvider) that wrapped implements.
+// Wrappers such as key pools and fault injectors implement every optional
+// interface and forward to the provider they wrap; exposing them through
+// Expose keeps type assertions on the wrapper truthful. Unwrap returns the
+// wrapper.
+func Expose(wrapper, wrapped LLMProvider) LLMProvider {
+	return ExposeFeatures(wrapper, FeaturesOf(wrapped))
+}
+
+// ExposeFeatures is Expose for a wrapper without a provider to compare
+// with, such as a replay of recorded traffic
+func ExposeFeatures(wrapper LLMProvider, features Features) LLMProvider {
+	base := exposed{wrapper}
+	var streamer JSONStreamer
+	var moderator Moderator
+	var generator ImageGenerator
+	var audio AudioProvider
+	if features.JSONStream {
+		streamer, _ = wrapper.(JSONStreamer)
+	}
+	if features.Moderation {
+		moderator, _ = wrapper.(Moderator)
+	}
+	if features.Images {
+		generator, _ = wrapper.(ImageGenerator)
+	}
+	if features.Audio {
+		audio, _ = wrapper.(AudioProvider)
+	}
+
+	switch {
+	case streamer != nil && moderator != nil && generator != nil && audio != nil:
+		return struct {
+			exposed
+			JSONStreamer
+			Moderator
+			ImageGenerator
+			AudioProvider
+		}{base, streamer, moderator, generator, audio}
+	case streamer != nil && moderator != nil && generator != nil:
+		return struct {
+			exposed
+			JSONStreamer
+			Moderator
+			ImageGenerator
+		}{base, streamer, moderator, generator}
+	case streamer != nil && moderator != nil && audio != nil:
+		return struct {
+			exposed
+			JSONStreamer
+			Moderator
+			AudioProvider
+		}{base, streamer, moderator, audio}
+	case streamer != nil && generator != nil && audio != nil:
+		return struct {
+			exposed
+			JSONStreamer
+			ImageGenerator
+			AudioProvider
+		}{base, streamer, generator, audio}
+	case moderator != nil && generator != nil && audio != nil:
+		return struct {
+			exposed
+			Moderator
+			ImageGenerator
+			AudioProvider
+		}{base, moderator, generator, audio}
+	case streamer != nil && moderator != nil:
+		return struct {
+			exposed
+			JSONStreamer
+			Moderator
+		}{base, streamer, moderator}
+	case streamer != nil && generator != nil:
+		return struct {
+			exposed
+			JSONStreamer
+			ImageGenerator
+		}{base, streamer, generator}
+	case streamer != nil && audio != nil:
+		return struct {
+			exposed
+			JSONStreamer
+			AudioProvider
+		}{base, streamer, audio}
+	case moderator != nil && generator != nil:
+		return struct {
+			exposed
+			Moderator
+			ImageGenerator
+		}{base, moderator, generator}
+	case moderator != nil && audio != nil:
+		return struct {
+			exposed
+			Moderator
+			AudioProvider
+		}{base, moderator, audio}
+	case generator != nil && audio != nil:
+		return struct {
+			exposed
+			ImageGenerator
+			AudioProvider
+		}{base, generator, audio}
+	case streamer != nil:
+		return struct {
+			exposed
+			JSONStreamer
+		}{base, streamer}
+	case moderator != nil:
+		return struct {
+			exposed
+			Moderator
+		}{base, moderator}
+	case generator != nil:
+		return struct {
+			exposed
+			ImageGenerator
+		}{base, generator}
+	case audio != nil:
+		return struct {
+			exposed
+			AudioProvider
+		}{base, audio}
+	}
+	return base
+}
+
+// Unwrap returns the wrapper passed to Expose, or provider itself when it
+// was not exposed
+func Unwrap(provider LLMProvider) LLMProvider {
+	if wrapped, ok := provider.(interface{ unwrap() LLMProvider }); ok {
+		return wrapped.unwrap()
+	}
+	return provider
+}
+
+// exposed hides every method of a wrapper beyond LLMProvider
+type exposed struct {
+	LLMProvider
+}
+
+func (e exposed) unwrap() LLMProvider {
+	return e.LLMProvider
+}
@@ -0,0 +1,92 @@
+package providers
+
+import (
+	"context"
+	"testing"
+)
+
+// baseProvider implements only LLMProvider
+type baseProvider struct{}
+
+func (baseProvider) SendMessage(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	return &ChatResponse{}, nil
+}
+func (baseProvider) SendMessageStream(ctx context.Context, req *ChatRequest) <-chan StreamEvent {
+	return nil
+}
+func (baseProvider) GenerateJSON(ctx context.Context, req *JSONRequest) (*JSONResponse, error) {
+	return &JSONResponse{}, nil
+}
+func (baseProvider) ListModels(ctx context.Context) ([]Model, error) { return nil, nil }
+func (baseProvider) GetCapabilities() ProviderCapabilities           { return ProviderCapabilities{} }
+func (baseProvider) GetProviderType() ProviderType                   { return ProviderMock }
+func (baseProvider) Close() error                                    { return nil }
+
+// moderatingProvider adds moderation
+type moderatingProvider struct{ baseProvider }
+
+func (moderatingProvider) Moderate(ctx context.Context, text string) (*ModerationResult, error) {
+	return &ModerationResult{}, nil
+}
+
+// fullWrapper implements every optional interface
+type fullWrapper struct{ moderatingProvider }
+
+func (fullWrapper) GenerateJSONStream(ctx context.Context, req *JSONRequest) <-chan StreamEvent {
+	return nil
+}
+func (fullWrapper) GenerateImage(ctx context.Context, req *ImageRequest) (*ImageResponse, error) {
+	return &ImageResponse{}, nil
+}
+func (fullWrapper) Speech(ctx context.Context, req *SpeechRequest) (*SpeechResponse, error) {
+	return &SpeechResponse{}, nil
+}
+func (fullWrapper) SpeechStream(ctx context.Context, req *SpeechRequest) <-chan StreamEvent {
+	return nil
+}
+func (fullWrapper) Transcribe(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResponse, error) {
+	return &TranscriptionResponse{}, nil
+}
+
+func TestExpose(t *testing.T) {
+	wrapper := &fullWrapper{}
+
+	exposed := Expose(wrapper, moderatingProvider{})
+	if _, ok := exposed.(Moderator); !ok {
+		t.Error("expected moderation to be exposed")
+	}
+	if _, ok := exposed.(ImageGenerator); ok {
+		t.Error("expected image generation to be hidden")
+	}
+	if _, ok := exposed.(AudioProvider); ok {
+		t.Error("expected audio to be hidden")
+	}
+	if _, ok := exposed.(JSONStreamer); ok {
+		t.Error("expected JSON streaming to be hidden")
+	}
+	if Unwrap(exposed) != LLMProvider(wrapper) {
+		t.Error("expected Unwrap to return the wrapper")
+	}
+
+	exposed = Expose(wrapper, wrapper)
+	for name, ok := range map[string]bool{
+		"moderation": is[Moderator](exposed), "images": is[ImageGenerator](exposed),
+		"audio": is[AudioProvider](exposed), "JSON streaming": is[JSONStreamer](exposed),
+	} {
+		if !ok {
+			t.Errorf("expected %s to be exposed", name)
+		}
+	}
+
+	if _, ok := Expose(wrapper, baseProvider{}).(Moderator); ok {
+		t.Error("expected no optional interface over a plain provider")
+	}
+	if provider := (baseProvider{}); Unwrap(provider) != LLMProvider(provider) {
+		t.Error("expected Unwrap to return a provider that was not exposed")
+	}
+}
+
+func is[T any](provider LLMProvider) bool {
+	_, ok := provider.(T)
+	return ok
+}
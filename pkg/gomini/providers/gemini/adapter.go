@@ -1,8 +1,12 @@
 package gemini
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,18 +15,33 @@ import (
 )
 
 // adaptChatRequest converts unified ChatRequest to Gemini GenerateContent request
-func (p *Provider) adaptChatRequest(req *providers.ChatRequest) (*GeminiRequest, error) {
+func (p *Provider) adaptChatRequest(ctx context.Context, req *providers.ChatRequest) (*GeminiRequest, error) {
+	if providers.HasAudioInput(req.Messages) && !p.supportsAudioInput(req.Model) {
+		return nil, providers.NewLLMError(providers.ErrorUnsupportedFeature,
+			fmt.Sprintf("model %s does not accept audio input", req.Model), providers.ProviderGemini, nil)
+	}
+
 	// Convert messages to Gemini Content format
 	contents := make([]*genai.Content, 0, len(req.Messages))
-	
+
+	toolResults := false // Whether the last content holds tool results
 	for _, msg := range req.Messages {
-		content, err := p.adaptMessage(msg)
+		content, err := p.adaptMessage(ctx, msg)
 		if err != nil {
 			return nil, fmt.Errorf("failed to adapt message: %w", err)
 		}
-		if content != nil {
-			contents = append(contents, content)
+		if content == nil {
+			continue
+		}
+		// Gemini expects the responses to one turn's calls in a single content
+		isToolResult := isToolMessage(msg)
+		if isToolResult && toolResults {
+			last := contents[len(contents)-1]
+			last.Parts = append(last.Parts, content.Parts...)
+			continue
 		}
+		contents = append(contents, content)
+		toolResults = isToolResult
 	}
 
 	// Build Gemini configuration
@@ -41,29 +60,56 @@ func (p *Provider) adaptChatRequest(req *providers.ChatRequest) (*GeminiRequest,
 		config.Tools = tools
 	}
 
-	// Apply safety settings
-	if len(p.config.SafetySettings) > 0 {
-		safetySettings := p.adaptSafetySettings(p.config.SafetySettings)
-		config.SafetySettings = safetySettings
+	toolChoice, err := providers.ResolveToolChoice(req.ToolChoice, req.Tools)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tool choice: %w", err)
+	}
+	if len(req.Tools) > 0 && toolChoice.Mode != "" {
+		config.ToolConfig = adaptToolChoice(toolChoice)
+	}
+
+	// Ground answers with Google Search when requested
+	if configMap, ok := req.Config.(map[string]interface{}); ok {
+		if search, _ := configMap["google_search"].(bool); search {
+			config.Tools = append(config.Tools, &genai.Tool{GoogleSearch: &genai.GoogleSearch{}})
+		}
+	}
+
+	// Vertex AI accepts labels for billing breakdowns; the Gemini API rejects them
+	if p.config.UseVertexAI && len(req.Tags) > 0 {
+		config.Labels = adaptLabels(req.Tags)
+	}
+
+	// Apply safety settings, letting the request override the provider defaults
+	safetySettings := p.config.SafetySettings
+	if configMap, ok := req.Config.(map[string]interface{}); ok {
+		if override, ok := configMap["safety_settings"].([]providers.SafetySetting); ok {
+			safetySettings = override
+		}
+	}
+	if len(safetySettings) > 0 {
+		config.SafetySettings = p.adaptSafetySettings(safetySettings)
 	}
 
 	return &GeminiRequest{
-		Contents: contents,
-		Config:   config,
+		Contents:       contents,
+		Config:         config,
+		ThinkingBudget: p.thinkingBudget(req.Config),
 	}, nil
 }
 
 // adaptJSONRequest converts JSONRequest to Gemini request with JSON response format
-func (p *Provider) adaptJSONRequest(req *providers.JSONRequest) (*GeminiRequest, error) {
+func (p *Provider) adaptJSONRequest(ctx context.Context, req *providers.JSONRequest) (*GeminiRequest, error) {
 	// Convert chat request
 	chatReq := &providers.ChatRequest{
 		Messages: req.Messages,
 		Model:    req.Model,
 		Provider: providers.ProviderGemini,
 		Config:   req.Config,
+		Tags:     req.Tags,
 	}
 	
-	geminiReq, err := p.adaptChatRequest(chatReq)
+	geminiReq, err := p.adaptChatRequest(ctx, chatReq)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +117,15 @@ func (p *Provider) adaptJSONRequest(req *providers.JSONRequest) (*GeminiRequest,
 	// Configure for JSON response
 	geminiReq.Config.ResponseMIMEType = "application/json"
 	
-	// Add schema to system instruction if provided
+	// Prefer server-side schema enforcement when the model supports it
+	if req.Schema != nil && p.supportsResponseSchema(req.Model) {
+		if schema, err := schemaFromJSONSchema(req.Schema); err == nil {
+			geminiReq.Config.ResponseSchema = schema
+			return geminiReq, nil
+		}
+	}
+
+	// Fall back to describing the schema in the prompt
 	if req.Schema != nil {
 		schemaJSON, err := json.Marshal(req.Schema)
 		if err != nil {
@@ -98,12 +152,22 @@ func (p *Provider) adaptJSONRequest(req *providers.JSONRequest) (*GeminiRequest,
 	return geminiReq, nil
 }
 
+// supportsResponseSchema reports whether the model enforces ResponseSchema server-side
+func (p *Provider) supportsResponseSchema(model string) bool {
+	return p.modelCapabilities(model).StructuredOutput
+}
+
+// supportsAudioInput reports whether the model accepts inline audio
+func (p *Provider) supportsAudioInput(model string) bool {
+	return p.modelCapabilities(model).AudioInput
+}
+
 // adaptMessage converts unified Message to Gemini Content
-func (p *Provider) adaptMessage(msg providers.Message) (*genai.Content, error) {
+func (p *Provider) adaptMessage(ctx context.Context, msg providers.Message) (*genai.Content, error) {
 	// This is a simplified version - would need proper Message type handling
 	switch msgType := msg.(type) {
 	case map[string]interface{}:
-		role := msgType["role"].(string)
+		role, _ := msgType["role"].(string)
 		content := msgType["content"]
 		
 		// Map roles
@@ -115,13 +179,15 @@ func (p *Provider) adaptMessage(msg providers.Message) (*genai.Content, error) {
 		case "user":
 			geminiRole = "user"
 		case "assistant":
-			geminiRole = "model"
+			return p.adaptAssistantMessage(ctx, msgType)
+		case "tool":
+			return adaptToolResultMessage(msgType)
 		default:
 			return nil, fmt.Errorf("unsupported message role: %s", role)
 		}
 
 		// Convert content parts
-		parts, err := p.adaptContentParts(content)
+		parts, err := p.adaptContentParts(ctx, content)
 		if err != nil {
 			return nil, fmt.Errorf("failed to adapt content parts: %w", err)
 		}
@@ -136,8 +202,63 @@ func (p *Provider) adaptMessage(msg providers.Message) (*genai.Content, error) {
 	}
 }
 
+// adaptAssistantMessage converts an assistant message to model content,
+// sending the tool calls it made as function call parts
+func (p *Provider) adaptAssistantMessage(ctx context.Context, msg map[string]interface{}) (*genai.Content, error) {
+	toolCalls, err := providers.MessageToolCalls(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []*genai.Part
+	if text, ok := msg["content"].(string); !ok || text != "" || len(toolCalls) == 0 {
+		parts, err = p.adaptContentParts(ctx, msg["content"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to adapt content parts: %w", err)
+		}
+	}
+	for _, call := range toolCalls {
+		parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+			ID:   call.ID,
+			Name: call.Name,
+			Args: call.Arguments,
+		}})
+	}
+	return &genai.Content{Role: "model", Parts: parts}, nil
+}
+
+// adaptToolResultMessage converts a tool message to a function response.
+// Results that are JSON objects are sent as is; others under "output".
+func adaptToolResultMessage(msg map[string]interface{}) (*genai.Content, error) {
+	name, _ := msg["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("tool message has no tool name")
+	}
+	callID, _ := msg["tool_call_id"].(string)
+
+	var response map[string]any
+	text, _ := msg["content"].(string)
+	if json.Unmarshal([]byte(text), &response) != nil || response == nil {
+		response = map[string]any{"output": msg["content"]}
+	}
+	return &genai.Content{
+		Role: "user",
+		Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+			ID:       callID,
+			Name:     name,
+			Response: response,
+		}}},
+	}, nil
+}
+
+// isToolMessage reports whether msg carries a tool result
+func isToolMessage(msg providers.Message) bool {
+	msgMap, ok := msg.(map[string]interface{})
+	return ok && msgMap["role"] == "tool"
+}
+
 // adaptContentParts converts content to Gemini Parts
-func (p *Provider) adaptContentParts(content interface{}) ([]*genai.Part, error) {
+func (p *Provider) adaptContentParts(ctx context.Context, content interface{}) ([]*genai.Part, error) {
 	switch contentType := content.(type) {
 	case string:
 		// Simple text content
@@ -148,8 +269,31 @@ func (p *Provider) adaptContentParts(content interface{}) ([]*genai.Part, error)
 		parts := make([]*genai.Part, 0, len(contentType))
 		
 		for _, item := range contentType {
+			if audio, isAudio, err := providers.AsAudioPart(item); isAudio {
+				if err != nil {
+					return nil, err
+				}
+				parts = append(parts, &genai.Part{InlineData: &genai.Blob{
+					MIMEType: providers.NormalizeAudioMIMEType(audio.MIMEType),
+					Data:     audio.Data,
+				}})
+				continue
+			}
+
+			if doc, isDocument, err := providers.AsDocumentPart(item); isDocument {
+				if err != nil {
+					return nil, err
+				}
+				part, err := p.adaptDocumentPart(ctx, doc)
+				if err != nil {
+					return nil, fmt.Errorf("failed to adapt document part: %w", err)
+				}
+				parts = append(parts, part)
+				continue
+			}
+
 			if itemMap, ok := item.(map[string]interface{}); ok {
-				partType := itemMap["type"].(string)
+				partType, _ := itemMap["type"].(string)
 				
 				switch partType {
 				case "text":
@@ -160,8 +304,8 @@ func (p *Provider) adaptContentParts(content interface{}) ([]*genai.Part, error)
 					}
 					
 				case "image_url":
-					if data, ok := itemMap["data"].(map[string]interface{}); ok {
-						part, err := p.adaptImagePart(data)
+					if data := providers.ImagePartData(itemMap); data != nil {
+						part, err := p.adaptImagePart(ctx, data)
 						if err != nil {
 							return nil, fmt.Errorf("failed to adapt image part: %w", err)
 						}
@@ -179,29 +323,85 @@ func (p *Provider) adaptContentParts(content interface{}) ([]*genai.Part, error)
 }
 
 // adaptImagePart converts image content to Gemini Part
-func (p *Provider) adaptImagePart(data map[string]interface{}) (*genai.Part, error) {
-	// Handle different image formats
-	if url, ok := data["url"].(string); ok && url != "" {
-		// For now, return text indicating image URL (would need actual image processing)
-		return &genai.Part{Text: fmt.Sprintf("[Image: %s]", url)}, nil
-	}
-	
+func (p *Provider) adaptImagePart(ctx context.Context, data map[string]interface{}) (*genai.Part, error) {
+	mimeType, _ := data["mime_type"].(string)
+
 	if base64Data, ok := data["base64"].(string); ok && base64Data != "" {
-		mimeType := "image/jpeg"
-		if mime, ok := data["mime_type"].(string); ok {
-			mimeType = mime
+		mimeType, imageData, err := providers.DecodeBase64Image(base64Data, mimeType)
+		if err != nil {
+			return nil, err
 		}
-		
-		// Convert base64 to inline data
-		return &genai.Part{
-			InlineData: &genai.Blob{
-				MIMEType: mimeType,
-				Data:     []byte(base64Data), // Would need proper base64 decoding
-			},
-		}, nil
+		if mimeType, err = providers.CheckImage(mimeType, imageData, p.imageLimits()); err != nil {
+			return nil, err
+		}
+		return &genai.Part{InlineData: &genai.Blob{MIMEType: mimeType, Data: imageData}}, nil
+	}
+
+	imageURL, ok := data["url"].(string)
+	if !ok || imageURL == "" {
+		return nil, fmt.Errorf("invalid image data")
+	}
+
+	switch {
+	case strings.HasPrefix(imageURL, "data:"):
+		urlMIME, imageData, err := providers.DecodeImageDataURL(imageURL, mimeType)
+		if err != nil {
+			return nil, err
+		}
+		if urlMIME, err = providers.CheckImage(urlMIME, imageData, p.imageLimits()); err != nil {
+			return nil, err
+		}
+		return &genai.Part{InlineData: &genai.Blob{MIMEType: urlMIME, Data: imageData}}, nil
+
+	case strings.HasPrefix(imageURL, "gs://"):
+		// Cloud Storage URIs are read by the service directly
+		if mimeType == "" {
+			mimeType = "image/jpeg"
+		}
+		return &genai.Part{FileData: &genai.FileData{FileURI: imageURL, MIMEType: mimeType}}, nil
+
+	default:
+		// Gemini does not fetch arbitrary URLs, so download and inline the image
+		fetchedMIME, imageData, err := providers.FetchImage(ctx, p.directClient(), imageURL, p.imageLimits())
+		if err != nil {
+			return nil, err
+		}
+		return &genai.Part{InlineData: &genai.Blob{MIMEType: fetchedMIME, Data: imageData}}, nil
 	}
-	
-	return nil, fmt.Errorf("invalid image data")
+}
+
+// adaptDocumentPart converts a document to a Gemini Part, sending small
+// documents inline and uploading larger ones through the Files API
+func (p *Provider) adaptDocumentPart(ctx context.Context, doc providers.DocumentPart) (*genai.Part, error) {
+	if doc.URI != "" {
+		return &genai.Part{FileData: &genai.FileData{FileURI: doc.URI, MIMEType: doc.MIMEType}}, nil
+	}
+
+	limit := p.config.InlineDataLimit
+	if limit <= 0 {
+		limit = providers.DefaultInlineDocumentBytes
+	}
+	if int64(len(doc.Data)) <= limit {
+		return &genai.Part{InlineData: &genai.Blob{MIMEType: doc.MIMEType, Data: doc.Data}}, nil
+	}
+
+	file, err := p.uploadFile(ctx, doc.Name, doc.MIMEType, doc.Data)
+	if err != nil {
+		return nil, err
+	}
+	mimeType := file.MIMEType
+	if mimeType == "" {
+		mimeType = doc.MIMEType
+	}
+	return &genai.Part{FileData: &genai.FileData{FileURI: file.URI, MIMEType: mimeType}}, nil
+}
+
+// imageLimits returns the limits applied to inline and fetched images
+func (p *Provider) imageLimits() providers.ImageLimits {
+	if p.config.ImageLimits != nil {
+		return *p.config.ImageLimits
+	}
+	return providers.DefaultImageLimits()
 }
 
 // adaptChatResponse converts Gemini GenerateContentResponse to unified ChatResponse
@@ -211,20 +411,26 @@ func (p *Provider) adaptChatResponse(resp *genai.GenerateContentResponse, model
 	// Gemini typically returns one candidate
 	if len(resp.Candidates) > 0 {
 		for i, candidate := range resp.Candidates {
+			if candidate == nil {
+				continue
+			}
 			choice := p.adaptChoice(candidate, i)
 			choices = append(choices, choice)
 		}
 	}
 
-	var usage *providers.Usage
-	if resp.UsageMetadata != nil {
-		usage = &providers.Usage{
-			InputTokens:  int(*resp.UsageMetadata.PromptTokenCount),
-			OutputTokens: int(*resp.UsageMetadata.CandidatesTokenCount),
-			TotalTokens:  int(resp.UsageMetadata.TotalTokenCount),
-		}
+	// A blocked prompt has no candidates; report it as filtered
+	var candidate *genai.Candidate
+	if len(resp.Candidates) > 0 {
+		candidate = resp.Candidates[0]
+	}
+	safety := adaptSafetyInfo(resp, candidate)
+	if len(choices) == 0 && safety.PromptBlocked() {
+		choices = append(choices, promptBlockedChoice(safety))
 	}
 
+	usage := usageFromMetadata(resp.UsageMetadata)
+
 	return &providers.ChatResponse{
 		ID:       generateResponseID(), // Gemini doesn't provide ID
 		Model:    model,
@@ -232,38 +438,128 @@ func (p *Provider) adaptChatResponse(resp *genai.GenerateContentResponse, model
 		Choices:  choices,
 		Usage:    usage,
 		Created:  time.Now().Unix(),
+		Safety:   safety,
 	}
 }
 
 // adaptChoice converts Gemini Candidate to unified Choice
 func (p *Provider) adaptChoice(candidate *genai.Candidate, index int) providers.Choice {
-	// Extract text content
-	var content string
+	// Extract text content and inline images
+	var content, reasoning string
+	var images []providers.ImagePart
+	var toolCalls []providers.ToolCall
 	if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
 		for _, part := range candidate.Content.Parts {
-			if part.Text != "" {
+			if part == nil {
+				continue
+			}
+			if part.Thought {
+				reasoning += part.Text
+			} else if part.Text != "" {
 				content += part.Text
 			}
+			if image, ok := adaptImageOutput(part); ok {
+				images = append(images, image)
+			}
+			if part.FunctionCall != nil {
+				toolCalls = append(toolCalls, adaptFunctionCall(part.FunctionCall, len(toolCalls)))
+			}
 		}
 	}
 
-	// Map finish reason
+	// Map finish reason; Gemini reports STOP for function calls
 	finishReason := providers.FinishReasonStop
 	if candidate.FinishReason != "" {
 		finishReason = p.adaptFinishReason(candidate.FinishReason)
 	}
+	if len(toolCalls) > 0 && finishReason == providers.FinishReasonStop {
+		finishReason = providers.FinishReasonToolCalls
+	}
 
 	// Create assistant message
 	message := map[string]interface{}{
 		"role":    "assistant",
 		"content": content,
 	}
+	if reasoning != "" {
+		message["reasoning"] = reasoning
+	}
+	if len(images) > 0 {
+		message["images"] = images
+	}
+	if len(toolCalls) > 0 {
+		message["tool_calls"] = toolCalls
+	}
+	if citations := adaptGroundingCitations(candidate.GroundingMetadata); len(citations) > 0 {
+		message["citations"] = citations
+	}
+
+	return providers.Choice{
+		Index:        index,
+		Message:      message,
+		FinishReason: finishReason,
+		Safety:       adaptCandidateSafety(candidate),
+	}
+}
+
+// groundingRedirectHost serves the redirect URLs Gemini returns for web
+// grounding chunks; the chunk title then holds the source domain
+const groundingRedirectHost = "vertexaisearch.cloud.google.com"
+
+// adaptGroundingCitations converts web grounding chunks to citations, scoring
+// each by the highest confidence of the grounding supports that cite it. The
+// answer segments of those supports become the citation's spans.
+func adaptGroundingCitations(metadata *genai.GroundingMetadata) []providers.Citation {
+	if metadata == nil {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	spans := make(map[int][]providers.CitationSpan)
+	for _, support := range metadata.GroundingSupports {
+		if support == nil {
+			continue
+		}
+		for i, chunkIndex := range support.GroundingChunkIndices {
+			if i < len(support.ConfidenceScores) && float64(support.ConfidenceScores[i]) > scores[int(chunkIndex)] {
+				scores[int(chunkIndex)] = float64(support.ConfidenceScores[i])
+			}
+			if segment := support.Segment; segment != nil {
+				spans[int(chunkIndex)] = append(spans[int(chunkIndex)], providers.CitationSpan{
+					StartIndex: int(segment.StartIndex),
+					EndIndex:   int(segment.EndIndex),
+					Text:       segment.Text,
+				})
+			}
+		}
+	}
+
+	var citations []providers.Citation
+	for i, chunk := range metadata.GroundingChunks {
+		if chunk == nil || chunk.Web == nil || chunk.Web.URI == "" {
+			continue
+		}
+		citation := providers.Citation{Title: chunk.Web.Title, URI: chunk.Web.URI, Score: scores[i], Spans: spans[i]}
+		if parsed, err := url.Parse(chunk.Web.URI); err == nil && parsed.Hostname() == groundingRedirectHost {
+			citation.Domain = chunk.Web.Title
+		}
+		citations = append(citations, citation)
+	}
+	return citations
+}
 
-	return map[string]interface{}{
-		"index":         index,
-		"message":       message,
-		"finish_reason": finishReason,
+// adaptFunctionCall converts a Gemini function call to a unified ToolCall.
+// The Gemini API usually omits call IDs, so one is derived from the name.
+func adaptFunctionCall(call *genai.FunctionCall, index int) providers.ToolCall {
+	id := call.ID
+	if id == "" {
+		id = fmt.Sprintf("%s-%d", call.Name, index)
+	}
+	args := call.Args
+	if args == nil {
+		args = map[string]interface{}{}
 	}
+	return providers.ToolCall{ID: id, Name: call.Name, Arguments: args}
 }
 
 // adaptFinishReason converts Gemini FinishReason to unified format
@@ -273,87 +569,161 @@ func (p *Provider) adaptFinishReason(reason genai.FinishReason) providers.Finish
 		return providers.FinishReasonStop
 	case genai.FinishReasonMaxTokens:
 		return providers.FinishReasonLength
-	case genai.FinishReasonSafety:
-		return providers.FinishReasonContentFilter
-	case genai.FinishReasonRecitation:
+	case genai.FinishReasonSafety, genai.FinishReasonRecitation, genai.FinishReasonBlocklist,
+		genai.FinishReasonProhibitedContent, genai.FinishReasonSPII:
 		return providers.FinishReasonContentFilter
 	default:
 		return providers.FinishReasonError
 	}
 }
 
-// adaptStreamChunk converts Gemini streaming chunk to unified StreamEvent
-func (p *Provider) adaptStreamChunk(resp *genai.GenerateContentResponse, model string) *providers.StreamEvent {
-	if len(resp.Candidates) == 0 {
+// adaptStreamChunk converts a Gemini streaming chunk to unified StreamEvents.
+// A single chunk may carry several parts (text, thoughts, images) plus a finish reason.
+func (p *Provider) adaptStreamChunk(resp *genai.GenerateContentResponse, model string) []providers.StreamEvent {
+	if len(resp.Candidates) == 0 || resp.Candidates[0] == nil {
+		// A blocked prompt ends the stream without any candidates
+		if safety := adaptSafetyInfo(resp, nil); safety.PromptBlocked() {
+			return []providers.StreamEvent{{
+				Type:     providers.EventFinished,
+				Provider: providers.ProviderGemini,
+				Model:    model,
+				Metadata: providers.EventMeta{
+					FinishReason: providers.FinishReasonContentFilter,
+					Usage:        usageFromMetadata(resp.UsageMetadata),
+					Safety:       safety,
+				},
+				Timestamp: time.Now(),
+			}}
+		}
 		return nil
 	}
 
 	candidate := resp.Candidates[0]
-	
-	// Handle thinking content (Gemini 2.0 feature)
-	if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
+	var events []providers.StreamEvent
+	toolCalls := 0
+
+	if candidate.Content != nil {
 		for _, part := range candidate.Content.Parts {
-			if part.Text != "" {
-				// Check if this is thinking content
-				if p.isThinkingContent(part.Text) {
-					return &providers.StreamEvent{
-						Type:     providers.EventThought,
-						Provider: providers.ProviderGemini,
-						Model:    model,
-						Data: providers.ThoughtEvent{
-							Text: part.Text,
-						},
-						Timestamp: time.Now(),
-					}
-				} else {
-					// Regular content
-					return &providers.StreamEvent{
-						Type:     providers.EventContent,
-						Provider: providers.ProviderGemini,
-						Model:    model,
-						Data: providers.ContentEvent{
-							Text:  part.Text,
-							Delta: true,
-						},
-						Timestamp: time.Now(),
-					}
-				}
+			// Function calls arrive whole, parallel calls as sibling parts
+			if part != nil && part.FunctionCall != nil {
+				events = append(events, providers.StreamEvent{
+					Type:      providers.EventToolCall,
+					Provider:  providers.ProviderGemini,
+					Model:     model,
+					Data:      adaptFunctionCall(part.FunctionCall, toolCalls),
+					Timestamp: time.Now(),
+				})
+				toolCalls++
+				continue
+			}
+
+			if image, ok := adaptImageOutput(part); ok {
+				events = append(events, providers.StreamEvent{
+					Type:      providers.EventImage,
+					Provider:  providers.ProviderGemini,
+					Model:     model,
+					Data:      providers.ImageEvent{Image: image},
+					Timestamp: time.Now(),
+				})
+				continue
+			}
+
+			if part == nil || part.Text == "" {
+				continue
+			}
+
+			// Thinking models flag thought summaries on the part itself
+			if part.Thought {
+				events = append(events, providers.StreamEvent{
+					Type:     providers.EventThought,
+					Provider: providers.ProviderGemini,
+					Model:    model,
+					Data: providers.ThoughtEvent{
+						Text: part.Text,
+					},
+					Timestamp: time.Now(),
+				})
+			} else {
+				// Regular content
+				events = append(events, providers.StreamEvent{
+					Type:     providers.EventContent,
+					Provider: providers.ProviderGemini,
+					Model:    model,
+					Data: providers.ContentEvent{
+						Text:  part.Text,
+						Delta: true,
+					},
+					Timestamp: time.Now(),
+				})
 			}
 		}
 	}
 
-	// Handle finish reason
+	// Grounded answers report their sources, usually with the final chunk
+	if citations := adaptGroundingCitations(candidate.GroundingMetadata); len(citations) > 0 {
+		events = append(events, providers.StreamEvent{
+			Type:      providers.EventCitation,
+			Provider:  providers.ProviderGemini,
+			Model:     model,
+			Data:      providers.CitationEvent{Sources: citations},
+			Timestamp: time.Now(),
+		})
+	}
+
+	// Handle finish reason; the final chunk carries the usage of the whole response
 	if candidate.FinishReason != "" {
 		finishReason := p.adaptFinishReason(candidate.FinishReason)
-		return &providers.StreamEvent{
+		if toolCalls > 0 && finishReason == providers.FinishReasonStop {
+			finishReason = providers.FinishReasonToolCalls
+		}
+		events = append(events, providers.StreamEvent{
 			Type:     providers.EventFinished,
 			Provider: providers.ProviderGemini,
 			Model:    model,
 			Metadata: providers.EventMeta{
 				FinishReason: finishReason,
+				Usage:        usageFromMetadata(resp.UsageMetadata),
+				Safety:       adaptSafetyInfo(resp, candidate),
 			},
 			Timestamp: time.Now(),
-		}
+		})
 	}
 
-	return nil
+	return events
+}
+
+// adaptImageOutput extracts an image generated by the model from an inline data part
+func adaptImageOutput(part *genai.Part) (providers.ImagePart, bool) {
+	if part == nil || part.InlineData == nil || !strings.HasPrefix(part.InlineData.MIMEType, "image/") {
+		return providers.ImagePart{}, false
+	}
+	return providers.ImagePart{
+		MIMEType: part.InlineData.MIMEType,
+		Data:     part.InlineData.Data,
+	}, true
 }
 
 // adaptJSONResponse converts Gemini response to unified JSONResponse
 func (p *Provider) adaptJSONResponse(resp *genai.GenerateContentResponse, model string, schema map[string]interface{}) (*providers.JSONResponse, error) {
 	if len(resp.Candidates) == 0 {
+		if safety := adaptSafetyInfo(resp, nil); safety.PromptBlocked() {
+			return nil, fmt.Errorf("prompt blocked by safety filters: %s", safety.BlockReason)
+		}
 		return nil, fmt.Errorf("no candidates in response")
 	}
 
 	candidate := resp.Candidates[0]
-	if candidate.Content == nil || len(candidate.Content.Parts) == 0 {
+	if candidate != nil && filteredFinishReason(candidate.FinishReason) {
+		return nil, fmt.Errorf("response blocked by safety filters: %s", candidate.FinishReason)
+	}
+	if candidate == nil || candidate.Content == nil || len(candidate.Content.Parts) == 0 {
 		return nil, fmt.Errorf("no content in response")
 	}
 
 	// Extract text content
 	var textContent string
 	for _, part := range candidate.Content.Parts {
-		if part.Text != "" {
+		if part != nil && !part.Thought && part.Text != "" {
 			textContent += part.Text
 		}
 	}
@@ -362,20 +732,14 @@ func (p *Provider) adaptJSONResponse(resp *genai.GenerateContentResponse, model
 		return nil, fmt.Errorf("empty text content in response")
 	}
 
-	// Parse JSON content
+	// Parse JSON content, repairing malformed output if needed
 	var jsonData map[string]interface{}
-	if err := json.Unmarshal([]byte(textContent), &jsonData); err != nil {
+	repairs, err := providers.UnmarshalJSONWithRepair(textContent, &jsonData)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
 	}
 
-	var usage *providers.Usage
-	if resp.UsageMetadata != nil {
-		usage = &providers.Usage{
-			InputTokens:  int(*resp.UsageMetadata.PromptTokenCount),
-			OutputTokens: int(*resp.UsageMetadata.CandidatesTokenCount),
-			TotalTokens:  int(resp.UsageMetadata.TotalTokenCount),
-		}
-	}
+	usage := usageFromMetadata(resp.UsageMetadata)
 
 	return &providers.JSONResponse{
 		ID:       generateResponseID(),
@@ -384,51 +748,32 @@ func (p *Provider) adaptJSONResponse(resp *genai.GenerateContentResponse, model
 		Data:     jsonData,
 		Usage:    usage,
 		Created:  time.Now().Unix(),
+		Metadata: providers.RepairMetadata(repairs),
 	}, nil
 }
 
-// adaptModel converts Gemini Model to unified Model
+// adaptModel converts a Gemini Model to the unified Model. Capabilities come
+// from the capability table; the token limits and supported actions the
+// listing reports take precedence, and configured overrides win over both.
 func (p *Provider) adaptModel(model *genai.Model) providers.Model {
-	// Determine capabilities based on model name
-	capabilities := providers.ModelCapabilities{
-		TextGeneration: true,
-		SystemMessage:  true,
-		Streaming:      true,
+	adapted := defaultCapabilities.Apply(providers.Model{
+		ID:       strings.TrimPrefix(model.Name, "models/"),
+		Name:     model.DisplayName,
+		Provider: providers.ProviderGemini,
+	})
+	if model.InputTokenLimit > 0 {
+		adapted.ContextSize = int(model.InputTokenLimit)
 	}
-
-	// Set capabilities based on model name
-	if contains(model.Name, "gemini") {
-		capabilities.FunctionCalling = true
-		capabilities.JSONMode = true
-		
-		if contains(model.Name, "vision") || contains(model.Name, "pro") || contains(model.Name, "flash") {
-			capabilities.ImageInput = true
-		}
-		
-		if contains(model.Name, "2.0") {
-			capabilities.ThinkingMode = true
-		}
+	if model.OutputTokenLimit > 0 {
+		adapted.MaxOutputTokens = int(model.OutputTokenLimit)
 	}
-
-	// Estimate context size based on model
-	contextSize := 32768 // Default
-	if contains(model.Name, "1.5") {
-		if contains(model.Name, "pro") {
-			contextSize = 2000000 // 2M tokens
-		} else {
-			contextSize = 1000000 // 1M tokens
-		}
-	} else if contains(model.Name, "2.0") {
-		contextSize = 1000000 // 1M tokens
+	if len(model.SupportedActions) > 0 {
+		adapted.Capabilities.TextGeneration = slices.Contains(model.SupportedActions, "generateContent")
 	}
-
-	return providers.Model{
-		ID:           model.Name,
-		Name:         model.DisplayName,
-		Provider:     providers.ProviderGemini,
-		Capabilities: capabilities,
-		ContextSize:  contextSize,
+	if p.config != nil {
+		adapted = p.config.Capabilities.Apply(adapted)
 	}
+	return adapted
 }
 
 // Helper functions
@@ -458,13 +803,30 @@ func (p *Provider) applyRequestConfig(config *genai.GenerateContentConfig, reqCo
 			}
 		}
 		
-		if maxTokens, exists := configMap["max_output_tokens"]; exists {
-			if maxTokensInt, ok := maxTokens.(int); ok {
-				maxTokensInt32 := int32(maxTokensInt)
-				config.MaxOutputTokens = &maxTokensInt32
-			}
+		if maxTokens, ok := providers.MaxOutputTokensFromConfig(reqConfig); ok {
+			maxTokensInt32 := int32(maxTokens)
+			config.MaxOutputTokens = &maxTokensInt32
 		}
 		
+		if stop := providers.StopFromConfig(reqConfig); len(stop) > 0 {
+			config.StopSequences = stop
+		}
+
+		if penalty, ok := providers.FrequencyPenaltyFromConfig(reqConfig); ok {
+			penalty32 := float32(penalty)
+			config.FrequencyPenalty = &penalty32
+		}
+
+		if penalty, ok := providers.PresencePenaltyFromConfig(reqConfig); ok {
+			penalty32 := float32(penalty)
+			config.PresencePenalty = &penalty32
+		}
+
+		if seed, ok := providers.SeedFromConfig(reqConfig); ok {
+			seed32 := int32(seed)
+			config.Seed = &seed32
+		}
+
 		// Handle thinking config
 		if thinkingConfig, exists := configMap["thinking_config"]; exists {
 			if thinkingMap, ok := thinkingConfig.(map[string]interface{}); ok {
@@ -474,38 +836,62 @@ func (p *Provider) applyRequestConfig(config *genai.GenerateContentConfig, reqCo
 					if includeThoughts, ok := thinkingMap["include_thoughts"].(bool); ok {
 						config.ThinkingConfig.IncludeThoughts = includeThoughts
 					}
-					
-					if budget, ok := thinkingMap["thinking_budget"].(int); ok {
-						budgetInt32 := int32(budget)
-						// config.ThinkingConfig.ThinkingBudget = &budgetInt32 // Field may not exist
-						_ = budgetInt32 // Avoid unused variable
-					}
 				}
 			}
 		}
+
+		// A unified Reasoning setting applies regardless of ThinkingEnabled;
+		// its budget is sent by thinkingTransport
+		if reasoning, ok := providers.ReasoningFromConfig(reqConfig); ok {
+			config.ThinkingConfig = &genai.ThinkingConfig{IncludeThoughts: reasoning.IncludeThoughts}
+		}
 	}
 	
 	return nil
 }
 
 func (p *Provider) adaptTools(tools []providers.Tool) ([]*genai.Tool, error) {
-	geminiTools := make([]*genai.Tool, len(tools))
+	// Gemini groups all function declarations under a single tool
+	declarations := make([]*genai.FunctionDeclaration, 0, len(tools))
 	
-	for i, tool := range tools {
-		// Convert unified tool to Gemini format
-		// This would need proper Tool type handling
-		_ = tool // Avoid unused variable
-		geminiTools[i] = &genai.Tool{
-			FunctionDeclarations: []*genai.FunctionDeclaration{
-				{
-					Name: "placeholder", // Would extract from tool
-					// Add other function parameters
-				},
-			},
+	for _, tool := range tools {
+		fn, err := providers.AsFunctionTool(tool)
+		if err != nil {
+			return nil, err
+		}
+
+		declaration := &genai.FunctionDeclaration{
+			Name:        fn.Name,
+			Description: fn.Description,
 		}
+		if fn.Parameters != nil {
+			declaration.Parameters, err = schemaFromJSONSchema(fn.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parameters for tool %s: %w", fn.Name, err)
+			}
+		}
+		declarations = append(declarations, declaration)
 	}
 	
-	return geminiTools, nil
+	return []*genai.Tool{{FunctionDeclarations: declarations}}, nil
+}
+
+// adaptToolChoice converts a resolved ToolChoice to a function calling
+// config; a forced tool is mode ANY restricted to that one name
+func adaptToolChoice(choice providers.ToolChoice) *genai.ToolConfig {
+	calling := &genai.FunctionCallingConfig{}
+	switch choice.Mode {
+	case providers.ToolChoiceNone:
+		calling.Mode = genai.FunctionCallingConfigModeNone
+	case providers.ToolChoiceRequired:
+		calling.Mode = genai.FunctionCallingConfigModeAny
+	case providers.ToolChoiceFunction:
+		calling.Mode = genai.FunctionCallingConfigModeAny
+		calling.AllowedFunctionNames = []string{choice.Name}
+	default:
+		calling.Mode = genai.FunctionCallingConfigModeAuto
+	}
+	return &genai.ToolConfig{FunctionCallingConfig: calling}
 }
 
 func (p *Provider) adaptSafetySettings(settings []providers.SafetySetting) []*genai.SafetySetting {
@@ -521,20 +907,42 @@ func (p *Provider) adaptSafetySettings(settings []providers.SafetySetting) []*ge
 	return geminiSettings
 }
 
-// isThinkingContent checks if content is thinking/reasoning content
-func (p *Provider) isThinkingContent(text string) bool {
-	// Simple heuristic - in practice, would check for thinking markers
-	return len(text) > 100 && (contains(text, "thinking") || contains(text, "reasoning") || contains(text, "let me"))
-}
-
 // generateResponseID generates a unique response ID
 func generateResponseID() string {
 	return fmt.Sprintf("gemini-%d", time.Now().UnixNano())
 }
 
-// contains checks if a string contains a substring (case-insensitive)
-func contains(s, substr string) bool {
-	s = strings.ToLower(s)
-	substr = strings.ToLower(substr)
-	return strings.Contains(s, substr)
-}
\ No newline at end of file
+// adaptLabels converts tags to Vertex AI labels: lowercase keys and values of at most
+// 63 characters using letters, digits, underscores and dashes, keys starting with a letter
+func adaptLabels(tags map[string]string) map[string]string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	labels := make(map[string]string, len(tags))
+	for _, key := range keys {
+		labelKey := sanitizeLabel(key)
+		if labelKey == "" || labelKey[0] < 'a' || labelKey[0] > 'z' {
+			continue
+		}
+		if len(labels) >= 64 {
+			break
+		}
+		labels[labelKey] = sanitizeLabel(tags[key])
+	}
+	return labels
+}
+
+// sanitizeLabel lowercases a label part and replaces unsupported characters with underscores
+func sanitizeLabel(value string) string {
+	value = strings.ToLower(value)
+	label := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, value)
+	return providers.TruncateRunes(label, 63)
+}
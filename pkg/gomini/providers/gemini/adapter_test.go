@@ -0,0 +1,553 @@
+package gemini
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+	"google.golang.org/genai"
+)
+
+func TestAdaptChatRequest_Labels(t *testing.T) {
+	request := &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "hi"}},
+		Model:    "gemini-1.5-flash",
+		Tags:     map[string]string{"Team": "Search Infra", "9lives": "x", "env": "prod"},
+	}
+
+	vertex := &Provider{config: &Config{UseVertexAI: true}}
+	req, err := vertex.adaptChatRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"team": "search_infra", "env": "prod"}
+	if len(req.Config.Labels) != len(expected) {
+		t.Fatalf("expected labels %v, got %v", expected, req.Config.Labels)
+	}
+	for key, value := range expected {
+		if req.Config.Labels[key] != value {
+			t.Errorf("expected label %s=%s, got %q", key, value, req.Config.Labels[key])
+		}
+	}
+
+	// The Gemini API rejects labels, so they are only sent to Vertex AI
+	geminiAPI := &Provider{config: &Config{}}
+	req, err = geminiAPI.adaptChatRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Config.Labels != nil {
+		t.Errorf("expected no labels for the Gemini API, got %v", req.Config.Labels)
+	}
+}
+
+func TestAdaptChatRequest_Seed(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	req, err := provider.adaptChatRequest(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "roll a die"}},
+		Model:    "gemini-2.0-flash",
+		Config:   map[string]interface{}{providers.ConfigSeed: 42},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Config.Seed == nil || *req.Config.Seed != 42 {
+		t.Errorf("expected seed 42, got %v", req.Config.Seed)
+	}
+}
+
+func TestAdaptChatRequest_StopAndPenalties(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	req, err := provider.adaptChatRequest(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "list three fruits"}},
+		Model:    "gemini-2.0-flash",
+		Config: map[string]interface{}{
+			providers.ConfigStop:             "4.",
+			providers.ConfigFrequencyPenalty: 0.5,
+			providers.ConfigPresencePenalty:  -0.25,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(req.Config.StopSequences) != 1 || req.Config.StopSequences[0] != "4." {
+		t.Errorf("expected stop sequences [4.], got %v", req.Config.StopSequences)
+	}
+	if req.Config.FrequencyPenalty == nil || *req.Config.FrequencyPenalty != 0.5 {
+		t.Errorf("expected frequency penalty 0.5, got %v", req.Config.FrequencyPenalty)
+	}
+	if req.Config.PresencePenalty == nil || *req.Config.PresencePenalty != -0.25 {
+		t.Errorf("expected presence penalty -0.25, got %v", req.Config.PresencePenalty)
+	}
+}
+
+func TestAdaptImagePart(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(png)
+	}))
+	defer server.Close()
+
+	limits := providers.DefaultImageLimits()
+	limits.AllowPrivateNetworks = true
+	provider := &Provider{config: &Config{HTTPClient: server.Client(), ImageLimits: &limits}}
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+	}{
+		{name: "base64", data: map[string]interface{}{"base64": base64.StdEncoding.EncodeToString(png)}},
+		{name: "data url", data: map[string]interface{}{"url": "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)}},
+		{name: "remote url", data: map[string]interface{}{"url": server.URL + "/cat.png"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			part, err := provider.adaptImagePart(context.Background(), tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if part.InlineData == nil {
+				t.Fatalf("expected inline data, got %+v", part)
+			}
+			if part.InlineData.MIMEType != "image/png" {
+				t.Errorf("expected image/png, got %s", part.InlineData.MIMEType)
+			}
+			if string(part.InlineData.Data) != string(png) {
+				t.Errorf("expected decoded image bytes, got %q", part.InlineData.Data)
+			}
+		})
+	}
+
+	parts, err := provider.adaptContentParts(context.Background(), []interface{}{
+		map[string]interface{}{"type": "image_url", "image_url": map[string]interface{}{"url": "data:image/png;base64," + base64.RawURLEncoding.EncodeToString(png)}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parts) != 1 || parts[0].InlineData == nil || string(parts[0].InlineData.Data) != string(png) {
+		t.Errorf("expected wire-shaped data URL to be inlined, got %+v", parts)
+	}
+	if _, err := provider.adaptImagePart(context.Background(), map[string]interface{}{"url": "data:text/plain,hello"}); err == nil {
+		t.Error("expected error for a non-image data URL")
+	}
+
+	part, err := provider.adaptImagePart(context.Background(), map[string]interface{}{"url": "gs://bucket/cat.png", "mime_type": "image/png"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if part.FileData == nil || part.FileData.FileURI != "gs://bucket/cat.png" {
+		t.Errorf("expected file data for gs:// URI, got %+v", part)
+	}
+
+	// Remote images are fetched under the request's context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := provider.adaptImagePart(ctx, map[string]interface{}{"url": server.URL + "/cat.png"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled fetch, got %v", err)
+	}
+
+	// Inline images are held to the same limits as fetched ones, and by
+	// default URLs on internal networks are refused
+	strict := &Provider{config: &Config{ImageLimits: &providers.ImageLimits{MaxBytes: 4}}}
+	for _, data := range []map[string]interface{}{
+		{"base64": base64.StdEncoding.EncodeToString(png)},
+		{"url": "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)},
+	} {
+		if _, err := strict.adaptImagePart(context.Background(), data); err == nil {
+			t.Errorf("expected size limit error for %v", data)
+		}
+	}
+	if _, err := (&Provider{config: &Config{}}).adaptImagePart(context.Background(), map[string]interface{}{"url": server.URL + "/cat.png"}); err == nil {
+		t.Error("expected a loopback image URL to be refused")
+	}
+}
+
+func TestAdaptToolsAndFunctionCalls(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+
+	tools, err := provider.adaptTools([]providers.Tool{
+		providers.FunctionTool{
+			Name:       "get_weather",
+			Parameters: map[string]interface{}{"type": "object", "properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}}},
+		},
+		providers.FunctionTool{Name: "get_time"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tools) != 1 || len(tools[0].FunctionDeclarations) != 2 {
+		t.Fatalf("expected one tool with two declarations, got %+v", tools)
+	}
+	if params := tools[0].FunctionDeclarations[0].Parameters; params == nil || params.Properties["city"] == nil {
+		t.Errorf("expected converted parameter schema, got %+v", params)
+	}
+
+	choice := provider.adaptChoice(&genai.Candidate{
+		Content: &genai.Content{Parts: []*genai.Part{
+			{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]interface{}{"city": "Taipei"}}},
+		}},
+		FinishReason: genai.FinishReasonStop,
+	}, 0)
+
+	if choice.FinishReason != providers.FinishReasonToolCalls {
+		t.Errorf("expected tool_calls finish reason, got %v", choice.FinishReason)
+	}
+	toolCalls := choice.ToolCalls()
+	if len(toolCalls) != 1 || toolCalls[0].Name != "get_weather" || toolCalls[0].ID == "" {
+		t.Errorf("unexpected tool calls %+v", toolCalls)
+	}
+}
+
+func TestAdaptChatRequest_ToolChoice(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	tools := []providers.Tool{providers.FunctionTool{Name: "get_weather"}, providers.FunctionTool{Name: "get_time"}}
+	tests := []struct {
+		choice  interface{}
+		mode    genai.FunctionCallingConfigMode
+		allowed []string
+	}{
+		{"auto", genai.FunctionCallingConfigModeAuto, nil},
+		{"none", genai.FunctionCallingConfigModeNone, nil},
+		{"required", genai.FunctionCallingConfigModeAny, nil},
+		{"get_time", genai.FunctionCallingConfigModeAny, []string{"get_time"}},
+	}
+	for _, tt := range tests {
+		req, err := provider.adaptChatRequest(context.Background(), &providers.ChatRequest{
+			Messages:   []providers.Message{map[string]interface{}{"role": "user", "content": "hi"}},
+			Model:      "gemini-2.0-flash",
+			Tools:      tools,
+			ToolChoice: tt.choice,
+		})
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tt.choice, err)
+		}
+		calling := req.Config.ToolConfig.FunctionCallingConfig
+		if calling.Mode != tt.mode || !reflect.DeepEqual(calling.AllowedFunctionNames, tt.allowed) {
+			t.Errorf("%v: expected mode %s allowing %v, got %+v", tt.choice, tt.mode, tt.allowed, calling)
+		}
+	}
+
+	_, err := provider.adaptChatRequest(context.Background(), &providers.ChatRequest{
+		Messages:   []providers.Message{map[string]interface{}{"role": "user", "content": "hi"}},
+		Model:      "gemini-2.0-flash",
+		ToolChoice: "required",
+	})
+	if !errors.Is(err, providers.ErrInvalidParameters) {
+		t.Errorf("expected required without tools to be rejected, got %v", err)
+	}
+}
+
+func TestAdaptChatRequest_Audio(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	provider.initializeModels()
+
+	request := &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{
+			"role":    "user",
+			"content": []interface{}{providers.AudioPart{MIMEType: "audio/mp3", Data: []byte("mp3")}},
+		}},
+		Model: "gemini-1.5-flash",
+	}
+
+	req, err := provider.adaptChatRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	blob := req.Contents[0].Parts[0].InlineData
+	if blob == nil || blob.MIMEType != "audio/mpeg" || string(blob.Data) != "mp3" {
+		t.Errorf("expected inline audio blob, got %+v", req.Contents[0].Parts[0])
+	}
+
+	request.Model = "gemini-1.0-pro"
+	if _, err := provider.adaptChatRequest(context.Background(), request); err == nil {
+		t.Error("expected audio capability error for gemini-1.0-pro")
+	}
+}
+
+func TestAdaptDocumentPart(t *testing.T) {
+	var uploaded []byte
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/upload/v1beta/files":
+			// The key travels in a header, never in the URL
+			if r.Header.Get("x-goog-api-key") != "test-key" || r.URL.RawQuery != "" || r.Header.Get("X-Goog-Upload-Command") != "start" {
+				http.Error(w, "bad start request", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("X-Goog-Upload-URL", server.URL+"/upload-session")
+		case "/upload-session":
+			uploaded, _ = io.ReadAll(r.Body)
+			w.Write([]byte(`{"file":{"name":"files/abc","uri":"https://files.example/abc","mimeType":"application/pdf","state":"ACTIVE"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := &Provider{config: &Config{APIKey: "test-key", InlineDataLimit: 8, FilesBaseURL: server.URL}}
+
+	small := providers.DocumentPart{MIMEType: "application/pdf", Data: []byte("%PDF")}
+	part, err := provider.adaptDocumentPart(context.Background(), small)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if part.InlineData == nil || part.InlineData.MIMEType != "application/pdf" {
+		t.Errorf("expected inline document, got %+v", part)
+	}
+
+	large := providers.DocumentPart{MIMEType: "application/pdf", Data: []byte("%PDF-1.7 larger document"), Name: "report.pdf"}
+	part, err = provider.adaptDocumentPart(context.Background(), large)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if part.FileData == nil || part.FileData.FileURI != "https://files.example/abc" {
+		t.Errorf("expected uploaded file reference, got %+v", part)
+	}
+	if string(uploaded) != string(large.Data) {
+		t.Errorf("expected document bytes to be uploaded, got %q", uploaded)
+	}
+
+	part, err = provider.adaptDocumentPart(context.Background(), providers.DocumentPart{MIMEType: "application/pdf", URI: "gs://bucket/a.pdf"})
+	if err != nil || part.FileData == nil || part.FileData.FileURI != "gs://bucket/a.pdf" {
+		t.Errorf("expected gs:// reference, got %+v (err %v)", part, err)
+	}
+
+	// Uploads run under the request's context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := provider.adaptDocumentPart(ctx, large); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled upload, got %v", err)
+	}
+
+	vertex := &Provider{config: &Config{UseVertexAI: true, InlineDataLimit: 8}}
+	if _, err := vertex.adaptDocumentPart(context.Background(), large); err == nil {
+		t.Error("expected error uploading through Vertex AI")
+	}
+}
+
+func TestAdaptGroundingCitations(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	provider.initializeModels()
+
+	request, err := provider.adaptChatRequest(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "news?"}},
+		Model:    "gemini-1.5-flash",
+		Config:   map[string]interface{}{"google_search": true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(request.Config.Tools) != 1 || request.Config.Tools[0].GoogleSearch == nil {
+		t.Errorf("expected a Google Search tool, got %+v", request.Config.Tools)
+	}
+
+	choice := provider.adaptChoice(&genai.Candidate{
+		Content: &genai.Content{Parts: []*genai.Part{{Text: "answer"}}},
+		GroundingMetadata: &genai.GroundingMetadata{
+			GroundingChunks: []*genai.GroundingChunk{
+				{Web: &genai.GroundingChunkWeb{Title: "a.example", URI: "https://vertexaisearch.cloud.google.com/grounding-api-redirect/1"}},
+				{Web: &genai.GroundingChunkWeb{Title: "B", URI: "https://b.example/page"}},
+				{RetrievedContext: &genai.GroundingChunkRetrievedContext{}},
+			},
+			GroundingSupports: []*genai.GroundingSupport{
+				{GroundingChunkIndices: []int32{0, 1}, ConfidenceScores: []float32{0.5, 0.25}},
+				{GroundingChunkIndices: []int32{0}, ConfidenceScores: []float32{0.75}},
+			},
+		},
+	}, 0)
+
+	citations := choice.Citations()
+	if len(citations) != 2 {
+		t.Fatalf("expected 2 web citations, got %+v", citations)
+	}
+	if citations[0].Domain != "a.example" || citations[0].Score != 0.75 {
+		t.Errorf("unexpected redirect citation %+v", citations[0])
+	}
+	if citations[1].Domain != "" || citations[1].Score != 0.25 {
+		t.Errorf("unexpected direct citation %+v", citations[1])
+	}
+}
+
+func TestAdaptStreamChunk_ParallelFunctionCalls(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	events := provider.adaptStreamChunk(&genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content: &genai.Content{Parts: []*genai.Part{
+				{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]interface{}{"city": "Taipei"}}},
+				{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: map[string]interface{}{"city": "Tokyo"}}},
+			}},
+			FinishReason: genai.FinishReasonStop,
+		}},
+	}, "gemini-2.0-flash")
+
+	if len(events) != 3 {
+		t.Fatalf("expected two tool calls and a finish, got %+v", events)
+	}
+	for i, city := range []string{"Taipei", "Tokyo"} {
+		call, ok := events[i].Data.(providers.ToolCall)
+		if events[i].Type != providers.EventToolCall || !ok || call.Arguments["city"] != city {
+			t.Errorf("unexpected event %d: %+v", i, events[i])
+		}
+	}
+	if events[0].Data.(providers.ToolCall).ID == events[1].Data.(providers.ToolCall).ID {
+		t.Error("expected distinct call IDs")
+	}
+	if events[2].Metadata.FinishReason != providers.FinishReasonToolCalls {
+		t.Errorf("expected tool_calls finish reason, got %v", events[2].Metadata.FinishReason)
+	}
+}
+
+func TestAdaptStreamChunk_Citations(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	events := provider.adaptStreamChunk(&genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      &genai.Content{Parts: []*genai.Part{{Text: "Rain is likely."}}},
+			FinishReason: genai.FinishReasonStop,
+			GroundingMetadata: &genai.GroundingMetadata{
+				GroundingChunks: []*genai.GroundingChunk{{Web: &genai.GroundingChunkWeb{Title: "Weather", URI: "https://weather.example/today"}}},
+				GroundingSupports: []*genai.GroundingSupport{{
+					GroundingChunkIndices: []int32{0},
+					ConfidenceScores:      []float32{0.9},
+					Segment:               &genai.Segment{StartIndex: 0, EndIndex: 15, Text: "Rain is likely."},
+				}},
+			},
+		}},
+	}, "gemini-2.0-flash")
+
+	if len(events) != 3 || events[1].Type != providers.EventCitation || events[2].Type != providers.EventFinished {
+		t.Fatalf("expected content, citation and finished events, got %+v", events)
+	}
+	sources := events[1].Data.(providers.CitationEvent).Sources
+	if len(sources) != 1 || sources[0].URI != "https://weather.example/today" {
+		t.Fatalf("unexpected sources %+v", sources)
+	}
+	if spans := sources[0].Spans; len(spans) != 1 || spans[0].EndIndex != 15 || spans[0].Text != "Rain is likely." {
+		t.Errorf("unexpected spans %+v", spans)
+	}
+}
+
+func TestAdaptChatRequest_SafetyOverride(t *testing.T) {
+	provider := &Provider{config: &Config{SafetySettings: []providers.SafetySetting{
+		{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_LOW_AND_ABOVE"},
+	}}}
+	provider.initializeModels()
+
+	request := &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "hi"}},
+		Model:    "gemini-1.5-flash",
+	}
+	adapted, err := provider.adaptChatRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(adapted.Config.SafetySettings) != 1 || adapted.Config.SafetySettings[0].Threshold != "BLOCK_LOW_AND_ABOVE" {
+		t.Errorf("expected provider safety settings, got %+v", adapted.Config.SafetySettings)
+	}
+
+	request.Config = map[string]interface{}{"safety_settings": []providers.SafetySetting{
+		{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_NONE"},
+	}}
+	adapted, err = provider.adaptChatRequest(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(adapted.Config.SafetySettings) != 1 || adapted.Config.SafetySettings[0].Threshold != "BLOCK_NONE" {
+		t.Errorf("expected request safety settings, got %+v", adapted.Config.SafetySettings)
+	}
+}
+
+func TestAdaptStreamChunk_Usage(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	prompt, output := int32(12), int32(3)
+
+	events := provider.adaptStreamChunk(&genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content:      &genai.Content{Parts: []*genai.Part{{Text: "done"}}},
+			FinishReason: genai.FinishReasonStop,
+		}},
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     &prompt,
+			CandidatesTokenCount: &output,
+			TotalTokenCount:      15,
+		},
+	}, "gemini-2.0-flash")
+
+	if len(events) != 2 || events[1].Type != providers.EventFinished {
+		t.Fatalf("expected content then finished, got %+v", events)
+	}
+	usage := events[1].Metadata.Usage
+	if usage == nil || usage.InputTokens != 12 || usage.OutputTokens != 3 || usage.TotalTokens != 15 {
+		t.Errorf("expected the chunk usage on the finished event, got %+v", usage)
+	}
+}
+
+func TestAdaptChatRequest_ToolRoundTrip(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	toolCalls := []providers.ToolCall{
+		{ID: "get_weather-0", Name: "get_weather", Arguments: map[string]interface{}{"city": "Taipei"}},
+		{ID: "get_weather-1", Name: "get_weather", Arguments: map[string]interface{}{"city": "Tokyo"}},
+	}
+	req, err := provider.adaptChatRequest(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{
+			map[string]interface{}{"role": "user", "content": "weather in Taipei and Tokyo?"},
+			map[string]interface{}{"role": "assistant", "content": "", "tool_calls": toolCalls},
+			map[string]interface{}{"role": "tool", "tool_call_id": "get_weather-0", "name": "get_weather", "content": `{"sky":"sunny"}`},
+			map[string]interface{}{"role": "tool", "tool_call_id": "get_weather-1", "name": "get_weather", "content": "rain"},
+			map[string]interface{}{"role": "assistant", "content": "Sunny in Taipei, rain in Tokyo."},
+			map[string]interface{}{"role": "user", "content": "and in Seoul?"},
+		},
+		Model: "gemini-2.0-flash",
+		Tools: []providers.Tool{providers.FunctionTool{Name: "get_weather"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(req.Contents) != 5 {
+		t.Fatalf("expected the tool results merged into one content, got %d contents", len(req.Contents))
+	}
+	calls := req.Contents[1]
+	if calls.Role != "model" || len(calls.Parts) != 2 {
+		t.Fatalf("expected a model content with 2 parts, got %+v", calls)
+	}
+	for i, part := range calls.Parts {
+		if part.FunctionCall == nil || part.FunctionCall.ID != toolCalls[i].ID || part.FunctionCall.Args["city"] != toolCalls[i].Arguments["city"] {
+			t.Errorf("part %d: expected function call %+v, got %+v", i, toolCalls[i], part)
+		}
+	}
+
+	results := req.Contents[2]
+	if results.Role != "user" || len(results.Parts) != 2 {
+		t.Fatalf("expected a user content with 2 function responses, got %+v", results)
+	}
+	first, second := results.Parts[0].FunctionResponse, results.Parts[1].FunctionResponse
+	if first == nil || first.Name != "get_weather" || first.ID != "get_weather-0" || first.Response["sky"] != "sunny" {
+		t.Errorf("unexpected first function response %+v", first)
+	}
+	if second == nil || second.Response["output"] != "rain" {
+		t.Errorf("expected a plain result under output, got %+v", second)
+	}
+	if answer := req.Contents[3]; answer.Role != "model" || answer.Parts[0].Text != "Sunny in Taipei, rain in Tokyo." {
+		t.Errorf("unexpected answer content %+v", answer)
+	}
+
+	// Tool calls restored from a stored session arrive as maps
+	var storedCalls []interface{}
+	raw, _ := json.Marshal(toolCalls)
+	json.Unmarshal(raw, &storedCalls)
+	content, err := provider.adaptMessage(context.Background(), map[string]interface{}{"role": "assistant", "content": "", "tool_calls": storedCalls})
+	if err != nil || len(content.Parts) != 2 || content.Parts[1].FunctionCall.Name != "get_weather" {
+		t.Errorf("expected stored tool calls to be adapted, got %+v, %v", content, err)
+	}
+}
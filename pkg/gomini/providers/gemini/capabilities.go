@@ -0,0 +1,72 @@
+package gemini
+
+import "gomini/pkg/gomini/providers"
+
+var (
+	// textCapabilities are shared by every Gemini model
+	textCapabilities = providers.ModelCapabilities{
+		TextGeneration:  true,
+		FunctionCalling: true,
+		JSONMode:        true,
+		SystemMessage:   true,
+		Streaming:       true,
+	}
+
+	// multimodalCapabilities add image, audio and document input and
+	// server-side ResponseSchema; 1.0 models have none of them
+	multimodalCapabilities = withCapabilities(textCapabilities, func(c *providers.ModelCapabilities) {
+		c.ImageInput = true
+		c.AudioInput = true
+		c.DocumentInput = true
+		c.StructuredOutput = true
+	})
+)
+
+// defaultCapabilities describes Gemini models the static catalog does not
+// list. The listing endpoint reports token limits, which take precedence over
+// the ones here; Config.Capabilities overrides both.
+var defaultCapabilities = providers.CapabilityTable{
+	{Pattern: "*", Capabilities: providers.ModelCapabilities{TextGeneration: true, SystemMessage: true, Streaming: true}, ContextSize: 32768},
+
+	{Pattern: "gemini*", Capabilities: textCapabilities, ContextSize: 32768},
+	{Pattern: "gemini-pro-vision", Capabilities: withCapabilities(textCapabilities, func(c *providers.ModelCapabilities) {
+		c.ImageInput = true
+	}), ContextSize: 16384},
+	{Pattern: "gemini-1.0-pro-vision*", Capabilities: withCapabilities(textCapabilities, func(c *providers.ModelCapabilities) {
+		c.ImageInput = true
+	}), ContextSize: 16384},
+
+	{Pattern: "gemini-1.5*", Capabilities: multimodalCapabilities, ContextSize: 1000000, MaxOutputTokens: 8192},
+	{Pattern: "gemini-1.5-pro*", Capabilities: multimodalCapabilities, ContextSize: 2000000, MaxOutputTokens: 8192},
+	{Pattern: "gemini-2.0*", Capabilities: withCapabilities(multimodalCapabilities, func(c *providers.ModelCapabilities) {
+		c.ThinkingMode = true
+	}), ContextSize: 1000000, MaxOutputTokens: 8192},
+	{Pattern: "gemini-2.5*", Capabilities: withCapabilities(multimodalCapabilities, func(c *providers.ModelCapabilities) {
+		c.Reasoning = true
+	}), ContextSize: 1048576, MaxOutputTokens: 65536},
+	{Pattern: "gemini-2.5-flash-preview-tts", Capabilities: providers.ModelCapabilities{SpeechGeneration: true, Streaming: true}},
+	{Pattern: "gemini-2.5-pro-preview-tts", Capabilities: providers.ModelCapabilities{SpeechGeneration: true, Streaming: true}},
+
+	{Pattern: "imagen*", Capabilities: providers.ModelCapabilities{ImageGeneration: true}},
+	{Pattern: "embedding*", Capabilities: providers.ModelCapabilities{}},
+	{Pattern: "text-embedding*", Capabilities: providers.ModelCapabilities{}},
+}
+
+// withCapabilities returns a copy of base changed by edit
+func withCapabilities(base providers.ModelCapabilities, edit func(*providers.ModelCapabilities)) providers.ModelCapabilities {
+	edit(&base)
+	return base
+}
+
+// modelCapabilities returns what the catalog says about model, or what its
+// profile says when the catalog does not list it
+func (p *Provider) modelCapabilities(model string) providers.ModelCapabilities {
+	if m, ok := providers.FindModel(p.catalog(), model); ok {
+		return m.Capabilities
+	}
+	profiled := defaultCapabilities.Apply(providers.Model{ID: model})
+	if p.config != nil {
+		profiled = p.config.Capabilities.Apply(profiled)
+	}
+	return profiled.Capabilities
+}
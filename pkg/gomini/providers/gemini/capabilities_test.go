@@ -0,0 +1,60 @@
+package gemini
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+)
+
+func TestProvider_ListModelsDiscovery(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models" {
+			http.NotFound(w, r)
+			return
+		}
+		pages = append(pages, r.URL.Query().Get("pageToken"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("pageToken") == "" {
+			w.Write([]byte(`{"models":[
+				{"name":"models/gemini-1.5-pro","displayName":"Gemini 1.5 Pro","inputTokenLimit":2097152,"outputTokenLimit":8192,"supportedGenerationMethods":["generateContent"]},
+				{"name":"models/gemini-2.5-flash","displayName":"Gemini 2.5 Flash","inputTokenLimit":1048576,"outputTokenLimit":65536,"supportedGenerationMethods":["generateContent","countTokens"]}],
+				"nextPageToken":"page-2"}`))
+			return
+		}
+		w.Write([]byte(`{"models":[
+			{"name":"models/text-embedding-004","displayName":"Text Embedding 004","inputTokenLimit":2048,"outputTokenLimit":1,"supportedGenerationMethods":["embedContent"]}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{APIKey: "test-key", BaseURL: server.URL, DiscoverModels: true})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	models, err := provider.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(pages) != 2 || len(models) != len(provider.builtin)+2 {
+		t.Fatalf("Expected both pages merged into the catalog, got pages %v and %d models", pages, len(models))
+	}
+
+	if model, _ := providers.FindModel(models, "gemini-1.5-pro"); model.ContextSize != 2000000 || model.Cost == nil {
+		t.Errorf("Expected the catalog entry to be kept, got %+v", model)
+	}
+	flash, _ := providers.FindModel(models, "gemini-2.5-flash")
+	if !flash.Capabilities.Reasoning || flash.ContextSize != 1048576 || flash.MaxOutputTokens != 65536 || flash.Name != "Gemini 2.5 Flash" {
+		t.Errorf("Expected the discovered model to be profiled with its reported limits, got %+v", flash)
+	}
+	if embedding, _ := providers.FindModel(models, "text-embedding-004"); embedding.Capabilities.TextGeneration || embedding.ContextSize != 2048 {
+		t.Errorf("Expected the embedding model to report no text generation, got %+v", embedding)
+	}
+
+	provider.ListModels(context.Background())
+	if len(pages) != 2 {
+		t.Errorf("Expected the listing to be cached within the TTL, got pages %v", pages)
+	}
+}
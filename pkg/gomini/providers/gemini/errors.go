@@ -0,0 +1,50 @@
+package gemini
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+	"google.golang.org/genai"
+)
+
+// apiError decodes an SDK error response into a *providers.APIError; other
+// errors are returned unchanged
+func apiError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var decoded *providers.APIError
+	var clientErr genai.ClientError
+	var serverErr genai.ServerError
+	switch {
+	case errors.As(err, &clientErr):
+		decoded = googleAPIError(clientErr.Code, clientErr.Status, clientErr.Message, clientErr.Details)
+	case errors.As(err, &serverErr):
+		decoded = googleAPIError(serverErr.Code, serverErr.Status, serverErr.Message, serverErr.Details)
+	default:
+		return err
+	}
+	decoded.Err = err
+	return decoded
+}
+
+// googleAPIError reads the reason and retry delay from google.rpc error details
+func googleAPIError(code int, status, message string, details []map[string]any) *providers.APIError {
+	decoded := &providers.APIError{StatusCode: code, Type: status, Message: message}
+	for _, detail := range details {
+		kind, _ := detail["@type"].(string)
+		switch {
+		case strings.HasSuffix(kind, "google.rpc.ErrorInfo"):
+			decoded.Code, _ = detail["reason"].(string)
+		case strings.HasSuffix(kind, "google.rpc.RetryInfo"):
+			if delay, ok := detail["retryDelay"].(string); ok {
+				decoded.RetryAfter, _ = time.ParseDuration(delay)
+			}
+		case strings.HasSuffix(kind, "google.rpc.RequestInfo"):
+			decoded.RequestID, _ = detail["requestId"].(string)
+		}
+	}
+	return decoded
+}
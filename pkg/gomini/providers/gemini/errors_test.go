@@ -0,0 +1,49 @@
+package gemini
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+	"google.golang.org/genai"
+)
+
+func TestAPIError(t *testing.T) {
+	sdkErr := genai.ClientError{}
+	sdkErr.Code = 429
+	sdkErr.Status = "RESOURCE_EXHAUSTED"
+	sdkErr.Message = "Quota exceeded for metric generate_content_requests"
+	sdkErr.Details = []map[string]any{
+		{"@type": "type.googleapis.com/google.rpc.ErrorInfo", "reason": "RATE_LIMIT_EXCEEDED"},
+		{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "37s"},
+	}
+
+	var apiErr *providers.APIError
+	if !errors.As(apiError(fmt.Errorf("generate: %w", sdkErr)), &apiErr) {
+		t.Fatal("Expected a decoded APIError")
+	}
+	if apiErr.StatusCode != 429 || apiErr.Type != "RESOURCE_EXHAUSTED" || apiErr.Code != "RATE_LIMIT_EXCEEDED" {
+		t.Errorf("Unexpected error fields: %+v", apiErr)
+	}
+	if apiErr.RetryAfter != 37*time.Second {
+		t.Errorf("Expected the RetryInfo delay, got %v", apiErr.RetryAfter)
+	}
+	var unwrapped genai.ClientError
+	if !errors.As(apiErr, &unwrapped) {
+		t.Error("Expected the SDK error to stay reachable")
+	}
+
+	serverErr := genai.ServerError{}
+	serverErr.Code = 503
+	serverErr.Status = "UNAVAILABLE"
+	if !errors.As(apiError(serverErr), &apiErr) || apiErr.StatusCode != 503 {
+		t.Errorf("Expected server errors decoded, got %+v", apiErr)
+	}
+
+	plain := errors.New("boom")
+	if apiError(plain) != plain {
+		t.Error("Expected other errors to be returned unchanged")
+	}
+}
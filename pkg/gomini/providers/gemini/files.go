@@ -0,0 +1,152 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultFilesBaseURL = "https://generativelanguage.googleapis.com"
+	filePollInterval    = time.Second
+	fileProcessTimeout  = 2 * time.Minute
+
+	// apiKeyHeader carries the API key, keeping it out of URLs that proxies
+	// and access logs record
+	apiKeyHeader = "x-goog-api-key"
+)
+
+// uploadedFile is the subset of a Files API file resource used by the adapter
+type uploadedFile struct {
+	Name     string `json:"name"`
+	URI      string `json:"uri"`
+	MIMEType string `json:"mimeType"`
+	State    string `json:"state"`
+}
+
+// uploadFile stores data with the Gemini Files API using the resumable upload
+// protocol and waits until the file is ready to be referenced in a request.
+// The genai SDK version in use has no Files API, so this talks HTTP directly.
+func (p *Provider) uploadFile(ctx context.Context, displayName, mimeType string, data []byte) (*uploadedFile, error) {
+	if p.config.UseVertexAI {
+		return nil, fmt.Errorf("the Files API is not available on Vertex AI; upload to Cloud Storage and pass a gs:// URI")
+	}
+	if p.config.APIKey == "" {
+		return nil, fmt.Errorf("the Files API requires an API key")
+	}
+
+	baseURL := strings.TrimRight(p.filesBaseURL(), "/")
+	metadata, err := json.Marshal(map[string]interface{}{"file": map[string]string{"display_name": displayName}})
+	if err != nil {
+		return nil, err
+	}
+
+	// Start the resumable session
+	start, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		baseURL+"/upload/v1beta/files", bytes.NewReader(metadata))
+	if err != nil {
+		return nil, err
+	}
+	start.Header.Set(apiKeyHeader, p.config.APIKey)
+	start.Header.Set("X-Goog-Upload-Protocol", "resumable")
+	start.Header.Set("X-Goog-Upload-Command", "start")
+	start.Header.Set("X-Goog-Upload-Header-Content-Length", strconv.Itoa(len(data)))
+	start.Header.Set("X-Goog-Upload-Header-Content-Type", mimeType)
+	start.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.directClient().Do(start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start file upload: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to start file upload: HTTP %d", resp.StatusCode)
+	}
+	uploadURL := resp.Header.Get("X-Goog-Upload-URL")
+	if uploadURL == "" {
+		return nil, fmt.Errorf("file upload response has no upload URL")
+	}
+
+	// Send the bytes and finalize in one request
+	upload, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	upload.Header.Set("X-Goog-Upload-Command", "upload, finalize")
+	upload.Header.Set("X-Goog-Upload-Offset", "0")
+
+	var uploaded struct {
+		File uploadedFile `json:"file"`
+	}
+	if err := p.doFilesRequest(upload, &uploaded); err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return p.waitForFile(ctx, &uploaded.File)
+}
+
+// waitForFile polls a file until it leaves the PROCESSING state
+func (p *Provider) waitForFile(ctx context.Context, file *uploadedFile) (*uploadedFile, error) {
+	ctx, cancel := context.WithTimeout(ctx, fileProcessTimeout)
+	defer cancel()
+
+	for file.State == "PROCESSING" {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("file %s is still processing: %w", file.Name, ctx.Err())
+		case <-time.After(filePollInterval):
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+			strings.TrimRight(p.filesBaseURL(), "/")+"/v1beta/"+file.Name, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(apiKeyHeader, p.config.APIKey)
+		var refreshed uploadedFile
+		if err := p.doFilesRequest(req, &refreshed); err != nil {
+			return nil, fmt.Errorf("failed to check file %s: %w", file.Name, err)
+		}
+		file = &refreshed
+	}
+
+	if file.State == "FAILED" {
+		return nil, fmt.Errorf("file %s failed processing", file.Name)
+	}
+	return file, nil
+}
+
+// filesBaseURL returns the Files API endpoint
+func (p *Provider) filesBaseURL() string {
+	if p.config.FilesBaseURL != "" {
+		return p.config.FilesBaseURL
+	}
+	if p.config.BaseURL != "" {
+		return strings.TrimSuffix(p.config.BaseURL, "/")
+	}
+	return defaultFilesBaseURL
+}
+
+// doFilesRequest sends a Files API request and decodes the JSON response into v
+func (p *Provider) doFilesRequest(req *http.Request, v interface{}) error {
+	resp, err := p.directClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
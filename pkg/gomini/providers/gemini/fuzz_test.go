@@ -0,0 +1,56 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+// addPayloadSeeds seeds f with the recorded provider payloads matching pattern
+func addPayloadSeeds(f *testing.F, pattern string) {
+	f.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "payloads", pattern))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(raw))
+	}
+}
+
+func FuzzAdaptResponse(f *testing.F) {
+	addPayloadSeeds(f, "chunk_*.json")
+	addPayloadSeeds(f, "response_*.json")
+
+	p := &Provider{}
+	f.Fuzz(func(t *testing.T, raw string) {
+		var resp genai.GenerateContentResponse
+		if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+			return
+		}
+		p.adaptStreamChunk(&resp, "gemini-2.0-flash")
+		p.adaptChatResponse(&resp, "gemini-2.0-flash")
+		p.adaptJSONResponse(&resp, "gemini-2.0-flash", nil)
+	})
+}
+
+func FuzzAdaptMessage(f *testing.F) {
+	addPayloadSeeds(f, "message_*.json")
+
+	p := &Provider{}
+	f.Fuzz(func(t *testing.T, raw string) {
+		var message map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &message); err != nil {
+			return
+		}
+		p.adaptMessage(context.Background(), message)
+	})
+}
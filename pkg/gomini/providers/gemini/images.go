@@ -0,0 +1,128 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gomini/pkg/gomini/providers"
+	"google.golang.org/genai"
+)
+
+// Aspect ratios accepted by Imagen
+var imagenAspectRatios = map[string]bool{"1:1": true, "3:4": true, "4:3": true, "9:16": true, "16:9": true}
+
+// GenerateImage implements providers.ImageGenerator using Imagen. Images are
+// always returned as bytes.
+func (p *Provider) GenerateImage(ctx context.Context, req *providers.ImageRequest) (*providers.ImageResponse, error) {
+	config, err := p.adaptImageRequest(req)
+	if err != nil {
+		return nil, providers.WrapProviderError(err, providers.ProviderGemini, req.Model)
+	}
+
+	resp, err := p.client.Models.GenerateImages(ctx, req.Model, req.Prompt, config)
+	if err != nil {
+		return nil, providers.WrapProviderError(apiError(err), providers.ProviderGemini, req.Model)
+	}
+
+	result, err := p.adaptImagesResponse(resp, req.Model)
+	if err != nil {
+		return nil, providers.WrapProviderError(err, providers.ProviderGemini, req.Model)
+	}
+	return result, nil
+}
+
+// adaptImageRequest converts an image request to an Imagen config
+func (p *Provider) adaptImageRequest(req *providers.ImageRequest) (*genai.GenerateImagesConfig, error) {
+	if req.Prompt == "" {
+		return nil, fmt.Errorf("image prompt is required")
+	}
+	if !p.supportsImageGeneration(req.Model) {
+		return nil, providers.NewLLMError(providers.ErrorUnsupportedFeature,
+			fmt.Sprintf("model %s does not support image generation", req.Model), providers.ProviderGemini, nil)
+	}
+
+	config := &genai.GenerateImagesConfig{
+		NegativePrompt:   req.NegativePrompt,
+		AspectRatio:      req.AspectRatio,
+		IncludeRAIReason: true,
+	}
+	if req.N > 0 {
+		n := int32(req.N)
+		config.NumberOfImages = &n
+	}
+	if config.AspectRatio == "" && req.Size != "" {
+		ratio, err := aspectRatioFromSize(req.Size)
+		if err != nil {
+			return nil, err
+		}
+		config.AspectRatio = ratio
+	}
+	if config.AspectRatio != "" && !imagenAspectRatios[config.AspectRatio] {
+		return nil, fmt.Errorf("unsupported aspect ratio %q", config.AspectRatio)
+	}
+	return config, nil
+}
+
+// adaptImagesResponse converts an Imagen response, skipping images removed by safety filters
+func (p *Provider) adaptImagesResponse(resp *genai.GenerateImagesResponse, model string) (*providers.ImageResponse, error) {
+	var images []providers.GeneratedImage
+	var filtered []string
+	for _, generated := range resp.GeneratedImages {
+		if generated == nil || generated.Image == nil {
+			if generated != nil && generated.RAIFilteredReason != "" {
+				filtered = append(filtered, generated.RAIFilteredReason)
+			}
+			continue
+		}
+		images = append(images, providers.GeneratedImage{
+			URL:           generated.Image.GCSURI,
+			Data:          generated.Image.ImageBytes,
+			MIMEType:      generated.Image.MIMEType,
+			RevisedPrompt: generated.EnhancedPrompt,
+		})
+	}
+	if len(images) == 0 {
+		if len(filtered) > 0 {
+			return nil, providers.NewLLMError(providers.ErrorInvalidRequest,
+				"all images were blocked by safety filters: "+strings.Join(filtered, "; "), providers.ProviderGemini, nil)
+		}
+		return nil, fmt.Errorf("no images returned")
+	}
+
+	return &providers.ImageResponse{
+		Model:    model,
+		Provider: providers.ProviderGemini,
+		Images:   images,
+		Usage:    providers.NewImageUsage(p.catalog(), model, len(images)),
+	}, nil
+}
+
+// supportsImageGeneration reports whether a model can generate images
+func (p *Provider) supportsImageGeneration(model string) bool {
+	if m, ok := providers.FindModel(p.catalog(), model); ok {
+		return m.Capabilities.ImageGeneration
+	}
+	return strings.HasPrefix(model, "imagen-")
+}
+
+// aspectRatioFromSize maps an OpenAI-style "WxH" size to the matching Imagen aspect ratio
+func aspectRatioFromSize(size string) (string, error) {
+	var width, height int
+	if _, err := fmt.Sscanf(size, "%dx%d", &width, &height); err != nil || width <= 0 || height <= 0 {
+		return "", fmt.Errorf("invalid image size %q", size)
+	}
+	divisor := gcd(width, height)
+	ratio := fmt.Sprintf("%d:%d", width/divisor, height/divisor)
+	if !imagenAspectRatios[ratio] {
+		return "", fmt.Errorf("image size %s has unsupported aspect ratio %s", size, ratio)
+	}
+	return ratio, nil
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,67 @@
+package gemini
+
+import (
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+	"google.golang.org/genai"
+)
+
+func TestAdaptImageRequest(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	provider.initializeModels()
+
+	config, err := provider.adaptImageRequest(&providers.ImageRequest{
+		Prompt:         "a lighthouse",
+		Model:          "imagen-3.0-generate-002",
+		N:              2,
+		Size:           "1792x1008",
+		NegativePrompt: "people",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.AspectRatio != "16:9" || *config.NumberOfImages != 2 || config.NegativePrompt != "people" {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	invalid := []providers.ImageRequest{
+		{Model: "imagen-3.0-generate-002"},
+		{Prompt: "a lighthouse", Model: "gemini-1.5-pro"},
+		{Prompt: "a lighthouse", Model: "imagen-3.0-generate-002", Size: "1000x300"},
+		{Prompt: "a lighthouse", Model: "imagen-3.0-generate-002", AspectRatio: "2:1"},
+	}
+	for _, req := range invalid {
+		if _, err := provider.adaptImageRequest(&req); err == nil {
+			t.Errorf("Expected error for %+v", req)
+		}
+	}
+}
+
+func TestAdaptImagesResponse(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	provider.initializeModels()
+
+	resp, err := provider.adaptImagesResponse(&genai.GenerateImagesResponse{
+		GeneratedImages: []*genai.GeneratedImage{
+			{Image: &genai.Image{ImageBytes: []byte("png"), MIMEType: "image/png"}},
+			{RAIFilteredReason: "blocked"},
+		},
+	}, "imagen-3.0-generate-002")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.Images) != 1 || string(resp.Images[0].Data) != "png" {
+		t.Errorf("Expected the unfiltered image, got %+v", resp.Images)
+	}
+	if resp.Usage.Images != 1 || resp.Usage.Cost != 0.03 {
+		t.Errorf("Unexpected usage: %+v", resp.Usage)
+	}
+
+	_, err = provider.adaptImagesResponse(&genai.GenerateImagesResponse{
+		GeneratedImages: []*genai.GeneratedImage{{RAIFilteredReason: "blocked"}},
+	}, "imagen-3.0-generate-002")
+	if err == nil {
+		t.Error("Expected error when every image is filtered")
+	}
+}
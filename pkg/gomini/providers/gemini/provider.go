@@ -2,6 +2,8 @@ package gemini
 
 import (
 	"context"
+	"net/http"
+	"sync"
 	"time"
 
 	"google.golang.org/genai"
@@ -14,6 +16,10 @@ type Provider struct {
 	config   *Config
 	models   []providers.Model
 	created  time.Time
+
+	modelsMu      sync.RWMutex      // Guards models and modelsFetched
+	builtin       []providers.Model // Static catalog that discovered models merge into
+	modelsFetched time.Time
 }
 
 // Config holds Gemini-specific configuration
@@ -27,7 +33,21 @@ type Config struct {
 	ThinkingEnabled bool                       `json:"thinking_enabled,omitempty"`
 	ThinkingBudget  int                        `json:"thinking_budget,omitempty"`
 	ExtraHeaders    map[string]string          `json:"extra_headers,omitempty"`
+	ExtraQuery      map[string]string          `json:"extra_query,omitempty"`
+	ExtraBody       map[string]interface{}     `json:"extra_body,omitempty"`    // Merged into every JSON request body
+	BaseURL         string                     `json:"base_url,omitempty"`          // API endpoint override
 	Timeout         time.Duration              `json:"timeout,omitempty"`
+	HTTPClient      *http.Client               `json:"-"` // Replaces the SDK's default client; on Vertex AI it must handle authentication
+	ImageLimits     *providers.ImageLimits     `json:"image_limits,omitempty"` // Limits for fetched URL images
+	InlineDataLimit int64                      `json:"inline_data_limit,omitempty"` // Larger documents go through the Files API
+	FilesBaseURL    string                     `json:"files_base_url,omitempty"`    // Files API endpoint override
+
+	// Capabilities override the built-in capability table. With
+	// DiscoverModels, ListModels merges the API's model listing into the
+	// catalog and refreshes it every ModelsTTL (default one hour).
+	Capabilities   providers.CapabilityTable `json:"capabilities,omitempty"`
+	DiscoverModels bool                      `json:"discover_models,omitempty"`
+	ModelsTTL      time.Duration             `json:"models_ttl,omitempty"`
 }
 
 // NewProvider creates a new Gemini provider instance
@@ -43,9 +63,11 @@ func NewProvider(config *Config) (*Provider, error) {
 
 		// Create Vertex AI client
 		clientConfig := &genai.ClientConfig{
-			Project:  config.Project,
-			Location: config.Location,
-			Backend:  genai.BackendVertexAI,
+			Project:     config.Project,
+			Location:    config.Location,
+			Backend:     genai.BackendVertexAI,
+			HTTPOptions: httpOptions(config),
+			HTTPClient:  httpClient(config),
 		}
 
 		client, err = genai.NewClient(context.Background(), clientConfig)
@@ -56,8 +78,10 @@ func NewProvider(config *Config) (*Provider, error) {
 
 		// Create Gemini API client
 		clientConfig := &genai.ClientConfig{
-			APIKey:  config.APIKey,
-			Backend: genai.BackendGeminiAPI,
+			APIKey:      config.APIKey,
+			Backend:     genai.BackendGeminiAPI,
+			HTTPOptions: httpOptions(config),
+			HTTPClient:  httpClient(config),
 		}
 
 		client, err = genai.NewClient(context.Background(), clientConfig)
@@ -66,6 +90,8 @@ func NewProvider(config *Config) (*Provider, error) {
 	if err != nil {
 		return nil, providers.WrapProviderError(err, providers.ProviderGemini, "")
 	}
+	installThinkingTransport(client)
+	installExtraTransport(client, providers.ExtraRequest{Query: config.ExtraQuery, Body: config.ExtraBody})
 
 	provider := &Provider{
 		client:  client,
@@ -75,58 +101,142 @@ func NewProvider(config *Config) (*Provider, error) {
 
 	// Initialize available models
 	provider.initializeModels()
+	provider.models = config.Capabilities.ApplyAll(provider.models)
+	provider.builtin = provider.models
 
 	return provider, nil
 }
 
+// extraTransport adds the configured extra query parameters and body fields
+// to every request; genai.HTTPOptions only carries headers
+type extraTransport struct {
+	base  http.RoundTripper
+	extra providers.ExtraRequest
+}
+
+// installExtraTransport wraps the genai client's transport when there are
+// extras to add
+func installExtraTransport(client *genai.Client, extra providers.ExtraRequest) {
+	if extra.Empty() {
+		return
+	}
+	wrapTransport(client, func(base http.RoundTripper) http.RoundTripper {
+		return &extraTransport{base: base, extra: extra}
+	})
+}
+
+func (t *extraTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, err := t.extra.Apply(req)
+	if err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// httpClient returns a copy of the configured HTTP client, so wrapping its
+// transport does not affect the caller's client. Nil leaves the SDK default.
+func httpClient(config *Config) *http.Client {
+	if config.HTTPClient == nil {
+		return nil
+	}
+	client := *config.HTTPClient
+	return &client
+}
+
+// directClient returns the HTTP client for requests made outside the SDK,
+// such as image fetches and Files API calls
+func (p *Provider) directClient() *http.Client {
+	if p.config.HTTPClient != nil {
+		return p.config.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// httpOptions applies the configured base URL and extra headers to the client
+func httpOptions(config *Config) genai.HTTPOptions {
+	options := genai.HTTPOptions{BaseURL: config.BaseURL}
+	if len(config.ExtraHeaders) > 0 {
+		options.Headers = make(http.Header)
+		for key, value := range config.ExtraHeaders {
+			options.Headers.Set(key, value)
+		}
+	}
+	return options
+}
+
 // SendMessage implements LLMProvider.SendMessage
 func (p *Provider) SendMessage(ctx context.Context, req *providers.ChatRequest) (*providers.ChatResponse, error) {
 	// Convert unified request to Gemini format
-	geminiReq, err := p.adaptChatRequest(req)
+	geminiReq, err := p.adaptChatRequest(ctx, req)
 	if err != nil {
 		return nil, providers.WrapProviderError(err, providers.ProviderGemini, req.Model)
 	}
 
 	// Make Gemini API call
-	resp, err := p.client.Models.GenerateContent(ctx, req.Model, geminiReq.Contents, geminiReq.Config)
+	resp, err := p.client.Models.GenerateContent(withThinkingBudget(ctx, geminiReq.ThinkingBudget), req.Model, geminiReq.Contents, geminiReq.Config)
 	if err != nil {
-		return nil, providers.WrapProviderError(err, providers.ProviderGemini, req.Model)
+		return nil, providers.WrapProviderError(apiError(err), providers.ProviderGemini, req.Model)
 	}
 
 	// Convert Gemini response to unified format
-	return p.adaptChatResponse(resp, req.Model), nil
+	chatResp := p.adaptChatResponse(resp, req.Model)
+	providers.ShapeCitations(chatResp, providers.CitationOptionsFromConfig(req.Config))
+	return chatResp, nil
 }
 
 // SendMessageStream implements LLMProvider.SendMessageStream
 func (p *Provider) SendMessageStream(ctx context.Context, req *providers.ChatRequest) <-chan providers.StreamEvent {
+	return p.streamContent(ctx, req.Model, providers.CitationOptionsFromConfig(req.Config), func() (*GeminiRequest, error) {
+		return p.adaptChatRequest(ctx, req)
+	})
+}
+
+// GenerateJSONStream implements providers.JSONStreamer
+func (p *Provider) GenerateJSONStream(ctx context.Context, req *providers.JSONRequest) <-chan providers.StreamEvent {
+	return p.streamContent(ctx, req.Model, providers.CitationOptionsFromConfig(req.Config), func() (*GeminiRequest, error) {
+		return p.adaptJSONRequest(ctx, req)
+	})
+}
+
+// streamContent runs a streaming GenerateContent call built by buildRequest,
+// shaping its citations with citationOptions
+func (p *Provider) streamContent(ctx context.Context, model string, citationOptions providers.CitationOptions, buildRequest func() (*GeminiRequest, error)) <-chan providers.StreamEvent {
 	eventChan := make(chan providers.StreamEvent, 10)
 
 	go func() {
 		defer close(eventChan)
 
 		// Convert to Gemini streaming request
-		geminiReq, err := p.adaptChatRequest(req)
+		geminiReq, err := buildRequest()
 		if err != nil {
-			eventChan <- providers.NewErrorEvent(providers.ProviderGemini, req.Model, err, false)
+			eventChan <- providers.NewErrorEvent(providers.ProviderGemini, model, err, false)
 			return
 		}
 
 		// Create streaming request
-		iter := p.client.Models.GenerateContentStream(ctx, req.Model, geminiReq.Contents, geminiReq.Config)
+		iter := p.client.Models.GenerateContentStream(withThinkingBudget(ctx, geminiReq.ThinkingBudget), model, geminiReq.Contents, geminiReq.Config)
 
 		// Process streaming chunks (simplified for SDK compatibility)
 		// Note: The actual streaming API may need adjustment based on SDK version
+		citations := providers.NewCitationStream(citationOptions)
 		for chunk, err := range iter {
 			if err != nil {
-				eventChan <- providers.NewErrorEvent(providers.ProviderGemini, req.Model, err, false)
-				break
+				for _, event := range citations.Flush() {
+					eventChan <- event
+				}
+				eventChan <- providers.NewErrorEvent(providers.ProviderGemini, model, apiError(err), false)
+				return
 			}
 
-			event := p.adaptStreamChunk(chunk, req.Model)
-			if event != nil {
-				eventChan <- *event
+			for _, event := range p.adaptStreamChunk(chunk, model) {
+				for _, shaped := range citations.Shape(event) {
+					eventChan <- shaped
+				}
 			}
 		}
+		for _, event := range citations.Flush() {
+			eventChan <- event
+		}
 	}()
 
 	return eventChan
@@ -135,42 +245,81 @@ func (p *Provider) SendMessageStream(ctx context.Context, req *providers.ChatReq
 // GenerateJSON implements LLMProvider.GenerateJSON
 func (p *Provider) GenerateJSON(ctx context.Context, req *providers.JSONRequest) (*providers.JSONResponse, error) {
 	// Convert to Gemini request with JSON response format
-	geminiReq, err := p.adaptJSONRequest(req)
+	geminiReq, err := p.adaptJSONRequest(ctx, req)
 	if err != nil {
 		return nil, providers.WrapProviderError(err, providers.ProviderGemini, req.Model)
 	}
 
-	resp, err := p.client.Models.GenerateContent(ctx, req.Model, geminiReq.Contents, geminiReq.Config)
+	resp, err := p.client.Models.GenerateContent(withThinkingBudget(ctx, geminiReq.ThinkingBudget), req.Model, geminiReq.Contents, geminiReq.Config)
 	if err != nil {
-		return nil, providers.WrapProviderError(err, providers.ProviderGemini, req.Model)
+		return nil, providers.WrapProviderError(apiError(err), providers.ProviderGemini, req.Model)
 	}
 
 	return p.adaptJSONResponse(resp, req.Model, req.Schema)
 }
 
-// ListModels implements LLMProvider.ListModels
+// ListModels implements LLMProvider.ListModels. The static catalog is served
+// as is unless DiscoverModels is set; then the API listing is merged into it,
+// and a failed refresh keeps serving the previous catalog.
 func (p *Provider) ListModels(ctx context.Context) ([]providers.Model, error) {
-	if len(p.models) > 0 {
-		return p.models, nil
+	if !p.catalogStale() {
+		return p.catalog(), nil
 	}
 
-	// Fetch models from Gemini API (need to check SDK API)
-	models, err := p.client.Models.List(ctx, nil) // Add nil config parameter 
+	discovered, err := p.listAPIModels(ctx)
 	if err != nil {
-		return nil, providers.WrapProviderError(err, providers.ProviderGemini, "")
+		if catalog := p.catalog(); len(catalog) > 0 {
+			return catalog, nil
+		}
+		return nil, providers.WrapProviderError(apiError(err), providers.ProviderGemini, "")
 	}
 
-	// Convert Gemini models to unified format (simplified for SDK compatibility)
-	unifiedModels := make([]providers.Model, 0)
-	// Note: The actual model listing may need adjustment based on SDK version
-	// for _, model := range models.Models {
-	// 	unifiedModel := p.adaptModel(model)
-	// 	unifiedModels = append(unifiedModels, unifiedModel)
-	// }
-	_ = models // Avoid unused variable
+	p.modelsMu.Lock()
+	defer p.modelsMu.Unlock()
+	p.models = providers.MergeModels(p.builtin, discovered)
+	p.modelsFetched = time.Now()
+	return p.models, nil
+}
 
-	p.models = unifiedModels
-	return unifiedModels, nil
+// listAPIModels reads every page of the Gemini model listing
+func (p *Provider) listAPIModels(ctx context.Context) ([]providers.Model, error) {
+	var discovered []providers.Model
+	page, err := p.client.Models.List(ctx, nil)
+	for err == nil {
+		for _, model := range page.Items {
+			discovered = append(discovered, p.adaptModel(model))
+		}
+		page, err = page.Next(ctx)
+	}
+	if err != genai.ErrPageDone {
+		return nil, err
+	}
+	return discovered, nil
+}
+
+// catalog returns the current model catalog
+func (p *Provider) catalog() []providers.Model {
+	p.modelsMu.RLock()
+	defer p.modelsMu.RUnlock()
+	return p.models
+}
+
+// catalogStale reports whether ListModels should ask the API: nothing has
+// been listed yet, or discovery is on and the last listing is past its TTL
+func (p *Provider) catalogStale() bool {
+	p.modelsMu.RLock()
+	defer p.modelsMu.RUnlock()
+	if len(p.models) == 0 && p.modelsFetched.IsZero() {
+		return true
+	}
+	if p.config == nil || !p.config.DiscoverModels {
+		return false
+	}
+	ttl := p.config.ModelsTTL
+	if ttl <= 0 {
+		ttl = providers.DefaultModelsTTL
+	}
+	return time.Since(p.modelsFetched) >= ttl
 }
 
 // GetCapabilities implements LLMProvider.GetCapabilities
@@ -178,7 +327,8 @@ func (p *Provider) GetCapabilities() providers.ProviderCapabilities {
 	return providers.ProviderCapabilities{
 		Models: []string{
 			"gemini-2.0-flash-exp", "gemini-1.5-pro", "gemini-1.5-flash",
-			"gemini-1.0-pro", "gemini-pro-vision",
+			"gemini-1.0-pro", "gemini-pro-vision", "imagen-3.0-generate-002",
+			"gemini-2.5-flash-preview-tts",
 		},
 		MaxContextSize:      2000000, // 2M tokens for Gemini 1.5 Pro
 		SupportedMimeTypes:  []string{"text/plain", "image/jpeg", "image/png", "image/gif", "image/webp", "video/mp4", "audio/wav"},
@@ -193,6 +343,9 @@ func (p *Provider) GetCapabilities() providers.ProviderCapabilities {
 			"multimodal":      "true",
 			"large_context":   "true",
 			"safety_filters":  "true",
+			"image_generation": "true",
+			"speech":           "true",
+			"transcription":    "true",
 		},
 	}
 }
@@ -221,15 +374,19 @@ func (p *Provider) initializeModels() {
 			Name:     "Gemini 2.0 Flash (Experimental)",
 			Provider: providers.ProviderGemini,
 			Capabilities: providers.ModelCapabilities{
-				TextGeneration:  true,
-				ImageInput:      true,
-				FunctionCalling: true,
-				JSONMode:        true,
-				SystemMessage:   true,
-				Streaming:       true,
-				ThinkingMode:    true,
+				TextGeneration:   true,
+				ImageInput:       true,
+				AudioInput:       true,
+				DocumentInput:    true,
+				FunctionCalling:  true,
+				JSONMode:         true,
+				SystemMessage:    true,
+				Streaming:        true,
+				StructuredOutput: true,
+				ThinkingMode:     true,
 			},
-			ContextSize: 1000000, // 1M tokens
+			ContextSize:     1000000, // 1M tokens
+			MaxOutputTokens: 8192,
 			Cost: &providers.ModelCost{
 				InputTokens:  0.075, // $0.075 per 1M input tokens
 				OutputTokens: 0.3,   // $0.3 per 1M output tokens
@@ -241,14 +398,18 @@ func (p *Provider) initializeModels() {
 			Name:     "Gemini 1.5 Pro",
 			Provider: providers.ProviderGemini,
 			Capabilities: providers.ModelCapabilities{
-				TextGeneration:  true,
-				ImageInput:      true,
-				FunctionCalling: true,
-				JSONMode:        true,
-				SystemMessage:   true,
-				Streaming:       true,
+				TextGeneration:   true,
+				ImageInput:       true,
+				AudioInput:       true,
+				DocumentInput:    true,
+				FunctionCalling:  true,
+				JSONMode:         true,
+				SystemMessage:    true,
+				Streaming:        true,
+				StructuredOutput: true,
 			},
-			ContextSize: 2000000, // 2M tokens
+			ContextSize:     2000000, // 2M tokens
+			MaxOutputTokens: 8192,
 			Cost: &providers.ModelCost{
 				InputTokens:  1.25, // $1.25 per 1M input tokens
 				OutputTokens: 5.0,  // $5 per 1M output tokens
@@ -260,27 +421,53 @@ func (p *Provider) initializeModels() {
 			Name:     "Gemini 1.5 Flash",
 			Provider: providers.ProviderGemini,
 			Capabilities: providers.ModelCapabilities{
-				TextGeneration:  true,
-				ImageInput:      true,
-				FunctionCalling: true,
-				JSONMode:        true,
-				SystemMessage:   true,
-				Streaming:       true,
+				TextGeneration:   true,
+				ImageInput:       true,
+				AudioInput:       true,
+				DocumentInput:    true,
+				FunctionCalling:  true,
+				JSONMode:         true,
+				SystemMessage:    true,
+				Streaming:        true,
+				StructuredOutput: true,
 			},
-			ContextSize: 1000000, // 1M tokens
+			ContextSize:     1000000, // 1M tokens
+			MaxOutputTokens: 8192,
 			Cost: &providers.ModelCost{
 				InputTokens:  0.075, // $0.075 per 1M input tokens
 				OutputTokens: 0.3,   // $0.3 per 1M output tokens
 				Currency:     "USD",
 			},
 		},
+		{
+			ID:       "imagen-3.0-generate-002",
+			Name:     "Imagen 3",
+			Provider: providers.ProviderGemini,
+			Capabilities: providers.ModelCapabilities{
+				ImageGeneration: true,
+			},
+			Cost: &providers.ModelCost{
+				PerImage: 0.03, // $0.03 per image
+				Currency: "USD",
+			},
+		},
+		{
+			ID:       "gemini-2.5-flash-preview-tts",
+			Name:     "Gemini 2.5 Flash TTS (Preview)",
+			Provider: providers.ProviderGemini,
+			Capabilities: providers.ModelCapabilities{
+				SpeechGeneration: true,
+				Streaming:        true,
+			},
+		},
 	}
 }
 
 // Placeholder types for the adapter methods
 type GeminiRequest struct {
-	Contents []*genai.Content
-	Config   *genai.GenerateContentConfig
+	Contents       []*genai.Content
+	Config         *genai.GenerateContentConfig
+	ThinkingBudget *int32 // Sent by thinkingTransport; the SDK has no field for it
 }
 
 type StreamChunk struct {
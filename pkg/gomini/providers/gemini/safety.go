@@ -0,0 +1,88 @@
+package gemini
+
+import (
+	"gomini/pkg/gomini/providers"
+	"google.golang.org/genai"
+)
+
+// adaptSafetyInfo collects the prompt feedback and a candidate's safety
+// ratings. It returns nil when Gemini reported neither.
+func adaptSafetyInfo(resp *genai.GenerateContentResponse, candidate *genai.Candidate) *providers.SafetyInfo {
+	info := &providers.SafetyInfo{}
+	if feedback := resp.PromptFeedback; feedback != nil {
+		info.BlockReason = string(feedback.BlockReason)
+		info.BlockMessage = feedback.BlockReasonMessage
+		info.PromptRatings = adaptSafetyRatings(feedback.SafetyRatings)
+	}
+	if candidate := adaptCandidateSafety(candidate); candidate != nil {
+		info.Ratings = candidate.Ratings
+		info.FinishReason = candidate.FinishReason
+		if info.BlockMessage == "" {
+			info.BlockMessage = candidate.BlockMessage
+		}
+	}
+
+	if info.BlockReason == "" && info.FinishReason == "" && len(info.Ratings) == 0 && len(info.PromptRatings) == 0 {
+		return nil
+	}
+	return info
+}
+
+// adaptCandidateSafety collects a candidate's safety ratings and, when a
+// content policy stopped it, the reason. It returns nil when there are neither.
+func adaptCandidateSafety(candidate *genai.Candidate) *providers.SafetyInfo {
+	if candidate == nil {
+		return nil
+	}
+	info := &providers.SafetyInfo{Ratings: adaptSafetyRatings(candidate.SafetyRatings)}
+	if filteredFinishReason(candidate.FinishReason) {
+		info.FinishReason = string(candidate.FinishReason)
+		info.BlockMessage = candidate.FinishMessage
+	}
+	if info.FinishReason == "" && len(info.Ratings) == 0 {
+		return nil
+	}
+	return info
+}
+
+// adaptSafetyRatings converts Gemini safety ratings to the unified format
+func adaptSafetyRatings(ratings []*genai.SafetyRating) []providers.SafetyRating {
+	var adapted []providers.SafetyRating
+	for _, rating := range ratings {
+		if rating == nil {
+			continue
+		}
+		safetyRating := providers.SafetyRating{
+			Category:    string(rating.Category),
+			Probability: string(rating.Probability),
+			Severity:    string(rating.Severity),
+			Blocked:     rating.Blocked,
+		}
+		if rating.ProbabilityScore != nil {
+			safetyRating.Score = float64(*rating.ProbabilityScore)
+		}
+		adapted = append(adapted, safetyRating)
+	}
+	return adapted
+}
+
+// filteredFinishReason reports whether Gemini stopped a candidate because of
+// a content policy
+func filteredFinishReason(reason genai.FinishReason) bool {
+	switch reason {
+	case genai.FinishReasonSafety, genai.FinishReasonRecitation, genai.FinishReasonBlocklist,
+		genai.FinishReasonProhibitedContent, genai.FinishReasonSPII:
+		return true
+	}
+	return false
+}
+
+// promptBlockedChoice is the empty choice returned when Gemini rejects the
+// prompt and produces no candidates, carrying the prompt's safety details
+func promptBlockedChoice(safety *providers.SafetyInfo) providers.Choice {
+	return providers.Choice{
+		Message:      map[string]interface{}{"role": "assistant", "content": ""},
+		FinishReason: providers.FinishReasonContentFilter,
+		Safety:       safety,
+	}
+}
@@ -0,0 +1,95 @@
+package gemini
+
+import (
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+	"google.golang.org/genai"
+)
+
+func TestAdaptChatResponse_PromptBlocked(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	resp := provider.adaptChatResponse(&genai.GenerateContentResponse{
+		PromptFeedback: &genai.GenerateContentResponsePromptFeedback{
+			BlockReason:        genai.BlockedReasonSafety,
+			BlockReasonMessage: "The prompt was blocked.",
+			SafetyRatings: []*genai.SafetyRating{
+				{Category: genai.HarmCategoryHarassment, Probability: genai.HarmProbabilityHigh, Blocked: true},
+				{Category: genai.HarmCategoryHateSpeech, Probability: genai.HarmProbabilityNegligible},
+			},
+		},
+	}, "gemini-2.0-flash")
+
+	if len(resp.Choices) != 1 {
+		t.Fatalf("expected one filtered choice, got %+v", resp.Choices)
+	}
+	if reason := resp.Choices[0].FinishReason; reason != providers.FinishReasonContentFilter {
+		t.Errorf("expected content_filter, got %v", reason)
+	}
+	if !resp.Choices[0].Safety.PromptBlocked() {
+		t.Errorf("expected the block details on the choice, got %+v", resp.Choices[0].Safety)
+	}
+	if !resp.Safety.PromptBlocked() || resp.Safety.BlockMessage != "The prompt was blocked." {
+		t.Errorf("expected the block reason, got %+v", resp.Safety)
+	}
+	if categories := resp.Safety.BlockedCategories(); len(categories) != 1 || categories[0] != string(genai.HarmCategoryHarassment) {
+		t.Errorf("expected harassment to be blocked, got %v", categories)
+	}
+}
+
+func TestAdaptChatResponse_CandidateRatings(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	score := float32(0.75)
+	resp := provider.adaptChatResponse(&genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			FinishReason: genai.FinishReasonSafety,
+			SafetyRatings: []*genai.SafetyRating{
+				{Category: genai.HarmCategoryDangerousContent, Probability: genai.HarmProbabilityMedium, ProbabilityScore: &score, Blocked: true},
+			},
+		}},
+	}, "gemini-2.0-flash")
+
+	if resp.Safety == nil || resp.Safety.FinishReason != "SAFETY" || resp.Safety.PromptBlocked() {
+		t.Fatalf("expected a filtered response, got %+v", resp.Safety)
+	}
+	rating := resp.Safety.Ratings[0]
+	if rating.Category != string(genai.HarmCategoryDangerousContent) || rating.Probability != "MEDIUM" || rating.Score != 0.75 || !rating.Blocked {
+		t.Errorf("unexpected rating %+v", rating)
+	}
+	if choice := resp.Choices[0]; !choice.Filtered() || choice.Safety == nil || choice.Safety.FinishReason != "SAFETY" || len(choice.Safety.Ratings) != 1 {
+		t.Errorf("expected the candidate's safety details on its choice, got %+v", choice)
+	}
+
+	clean := provider.adaptChatResponse(&genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{FinishReason: genai.FinishReasonStop}},
+	}, "gemini-2.0-flash")
+	if clean.Safety != nil {
+		t.Errorf("expected no safety info, got %+v", clean.Safety)
+	}
+}
+
+func TestAdaptStreamChunk_PromptBlocked(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	events := provider.adaptStreamChunk(&genai.GenerateContentResponse{
+		PromptFeedback: &genai.GenerateContentResponsePromptFeedback{BlockReason: genai.BlockedReasonProhibitedContent},
+	}, "gemini-2.0-flash")
+
+	if len(events) != 1 || events[0].Type != providers.EventFinished {
+		t.Fatalf("expected a finished event, got %+v", events)
+	}
+	meta := events[0].Metadata
+	if meta.FinishReason != providers.FinishReasonContentFilter || meta.Safety.BlockReason != "PROHIBITED_CONTENT" {
+		t.Errorf("expected a filtered finish with the block reason, got %+v", meta)
+	}
+}
+
+func TestAdaptJSONResponse_PromptBlocked(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	_, err := provider.adaptJSONResponse(&genai.GenerateContentResponse{
+		PromptFeedback: &genai.GenerateContentResponsePromptFeedback{BlockReason: genai.BlockedReasonSafety},
+	}, "gemini-2.0-flash", nil)
+	if err == nil || !strings.Contains(err.Error(), "SAFETY") {
+		t.Errorf("expected the block reason in the error, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package gemini
+
+import (
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// unsupportedSchemaKeywords lists JSON Schema keywords Gemini's ResponseSchema cannot express
+var unsupportedSchemaKeywords = []string{"$ref", "$defs", "definitions", "oneOf", "allOf", "not", "if", "then", "else"}
+
+// schemaFromJSONSchema converts a JSON Schema document into a Gemini response schema.
+// It returns an error for constructs Gemini cannot enforce so callers can fall back
+// to prompt-based JSON generation.
+func schemaFromJSONSchema(schema map[string]interface{}) (*genai.Schema, error) {
+	for _, keyword := range unsupportedSchemaKeywords {
+		if _, ok := schema[keyword]; ok {
+			return nil, fmt.Errorf("unsupported schema keyword %q", keyword)
+		}
+	}
+
+	result := &genai.Schema{}
+
+	switch t := schema["type"].(type) {
+	case string:
+		schemaType, err := adaptSchemaType(t)
+		if err != nil {
+			return nil, err
+		}
+		result.Type = schemaType
+	case []interface{}:
+		// ["string", "null"] style nullable types
+		for _, item := range t {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid schema type %v", item)
+			}
+			if name == "null" {
+				result.Nullable = true
+				continue
+			}
+			if result.Type != "" {
+				return nil, fmt.Errorf("union schema types are not supported")
+			}
+			schemaType, err := adaptSchemaType(name)
+			if err != nil {
+				return nil, err
+			}
+			result.Type = schemaType
+		}
+	case nil:
+		if _, ok := schema["anyOf"]; !ok {
+			return nil, fmt.Errorf("schema is missing a type")
+		}
+	default:
+		return nil, fmt.Errorf("invalid schema type %v", t)
+	}
+
+	if description, ok := schema["description"].(string); ok {
+		result.Description = description
+	}
+	if title, ok := schema["title"].(string); ok {
+		result.Title = title
+	}
+	if format, ok := schema["format"].(string); ok {
+		result.Format = format
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		result.Pattern = pattern
+	}
+	if nullable, ok := schema["nullable"].(bool); ok {
+		result.Nullable = nullable
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		for _, value := range enum {
+			if value == nil {
+				result.Nullable = true
+				continue
+			}
+			result.Enum = append(result.Enum, fmt.Sprint(value))
+		}
+	}
+
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		result.Properties = make(map[string]*genai.Schema, len(properties))
+		for name, raw := range properties {
+			property, ok := raw.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("property %q is not a schema object", name)
+			}
+			converted, err := schemaFromJSONSchema(property)
+			if err != nil {
+				return nil, fmt.Errorf("property %q: %w", name, err)
+			}
+			result.Properties[name] = converted
+		}
+	}
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				result.Required = append(result.Required, s)
+			}
+		}
+	} else if required, ok := schema["required"].([]string); ok {
+		result.Required = append(result.Required, required...)
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		converted, err := schemaFromJSONSchema(items)
+		if err != nil {
+			return nil, fmt.Errorf("items: %w", err)
+		}
+		result.Items = converted
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		for i, raw := range anyOf {
+			option, ok := raw.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("anyOf[%d] is not a schema object", i)
+			}
+			converted, err := schemaFromJSONSchema(option)
+			if err != nil {
+				return nil, fmt.Errorf("anyOf[%d]: %w", i, err)
+			}
+			result.AnyOf = append(result.AnyOf, converted)
+		}
+	}
+
+	result.Minimum = schemaFloat(schema, "minimum")
+	result.Maximum = schemaFloat(schema, "maximum")
+	result.MinItems = schemaInt(schema, "minItems")
+	result.MaxItems = schemaInt(schema, "maxItems")
+	result.MinLength = schemaInt(schema, "minLength")
+	result.MaxLength = schemaInt(schema, "maxLength")
+
+	return result, nil
+}
+
+// adaptSchemaType maps a JSON Schema type name to a Gemini schema type
+func adaptSchemaType(name string) (genai.Type, error) {
+	switch name {
+	case "object":
+		return genai.TypeObject, nil
+	case "array":
+		return genai.TypeArray, nil
+	case "string":
+		return genai.TypeString, nil
+	case "number":
+		return genai.TypeNumber, nil
+	case "integer":
+		return genai.TypeInteger, nil
+	case "boolean":
+		return genai.TypeBoolean, nil
+	default:
+		return "", fmt.Errorf("unsupported schema type %q", name)
+	}
+}
+
+// schemaFloat reads a numeric schema keyword
+func schemaFloat(schema map[string]interface{}, key string) *float64 {
+	switch v := schema[key].(type) {
+	case float64:
+		return &v
+	case int:
+		f := float64(v)
+		return &f
+	}
+	return nil
+}
+
+// schemaInt reads an integer schema keyword
+func schemaInt(schema map[string]interface{}, key string) *int64 {
+	switch v := schema[key].(type) {
+	case float64:
+		i := int64(v)
+		return &i
+	case int:
+		i := int64(v)
+		return &i
+	}
+	return nil
+}
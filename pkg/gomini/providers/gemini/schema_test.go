@@ -0,0 +1,131 @@
+package gemini
+
+import (
+	"context"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+	"google.golang.org/genai"
+)
+
+func TestSchemaFromJSONSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{"type": "string", "description": "Full name"},
+			"age":  map[string]interface{}{"type": []interface{}{"integer", "null"}, "minimum": 0.0},
+			"tags": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string", "enum": []interface{}{"a", "b"}},
+			},
+		},
+		"required": []interface{}{"name"},
+	}
+
+	result, err := schemaFromJSONSchema(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Type != genai.TypeObject {
+		t.Errorf("expected object type, got %s", result.Type)
+	}
+	if len(result.Required) != 1 || result.Required[0] != "name" {
+		t.Errorf("unexpected required fields: %v", result.Required)
+	}
+	if result.Properties["name"].Description != "Full name" {
+		t.Errorf("expected description to be preserved")
+	}
+	age := result.Properties["age"]
+	if age.Type != genai.TypeInteger || !age.Nullable || age.Minimum == nil || *age.Minimum != 0 {
+		t.Errorf("unexpected age schema: %+v", age)
+	}
+	tags := result.Properties["tags"]
+	if tags.Type != genai.TypeArray || tags.Items == nil || len(tags.Items.Enum) != 2 {
+		t.Errorf("unexpected tags schema: %+v", tags)
+	}
+}
+
+func TestSchemaFromJSONSchema_Unsupported(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema map[string]interface{}
+	}{
+		{
+			name:   "ref",
+			schema: map[string]interface{}{"$ref": "#/$defs/Item"},
+		},
+		{
+			name: "nested oneOf",
+			schema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"value": map[string]interface{}{"oneOf": []interface{}{}},
+				},
+			},
+		},
+		{
+			name:   "unknown type",
+			schema: map[string]interface{}{"type": "tuple"},
+		},
+		{
+			name:   "missing type",
+			schema: map[string]interface{}{"description": "anything"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := schemaFromJSONSchema(tt.schema); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestAdaptJSONRequest_ResponseSchema(t *testing.T) {
+	p := &Provider{config: &Config{}}
+	p.initializeModels()
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"answer": map[string]interface{}{"type": "string"}},
+	}
+	messages := []providers.Message{
+		map[string]interface{}{"role": "user", "content": "hi"},
+	}
+
+	tests := []struct {
+		name           string
+		model          string
+		schema         map[string]interface{}
+		expectSchema   bool
+		expectContents int
+	}{
+		{"native schema", "gemini-1.5-flash", schema, true, 1},
+		{"legacy model falls back to prompt", "gemini-1.0-pro", schema, false, 2},
+		{"unconvertible schema falls back to prompt", "gemini-1.5-flash", map[string]interface{}{"$ref": "#/x"}, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := p.adaptJSONRequest(context.Background(), &providers.JSONRequest{
+				Messages: messages,
+				Model:    tt.model,
+				Schema:   tt.schema,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if req.Config.ResponseMIMEType != "application/json" {
+				t.Errorf("expected JSON MIME type, got %q", req.Config.ResponseMIMEType)
+			}
+			if (req.Config.ResponseSchema != nil) != tt.expectSchema {
+				t.Errorf("expected ResponseSchema set=%v", tt.expectSchema)
+			}
+			if len(req.Contents) != tt.expectContents {
+				t.Errorf("expected %d contents, got %d", tt.expectContents, len(req.Contents))
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+	"google.golang.org/genai"
+)
+
+const (
+	defaultSpeechModel        = "gemini-2.5-flash-preview-tts"
+	defaultTranscriptionModel = "gemini-1.5-flash"
+	defaultSpeechVoice        = "Kore"
+
+	transcriptionPrompt = "Generate a verbatim transcript of the speech in this audio. Reply with the transcript only."
+)
+
+// Speech implements providers.AudioProvider.Speech. Gemini returns raw PCM,
+// which is wrapped in a WAV container so the result is directly playable.
+func (p *Provider) Speech(ctx context.Context, req *providers.SpeechRequest) (*providers.SpeechResponse, error) {
+	model, contents, config, err := p.adaptSpeechRequest(req)
+	if err != nil {
+		return nil, providers.WrapProviderError(err, providers.ProviderGemini, req.Model)
+	}
+
+	resp, err := p.client.Models.GenerateContent(ctx, model, contents, config)
+	if err != nil {
+		return nil, providers.WrapProviderError(apiError(err), providers.ProviderGemini, model)
+	}
+
+	var audio []byte
+	var mimeType string
+	for _, blob := range audioBlobs(resp) {
+		audio = append(audio, blob.Data...)
+		mimeType = blob.MIMEType
+	}
+	if len(audio) == 0 {
+		return nil, providers.WrapProviderError(fmt.Errorf("no audio in response"), providers.ProviderGemini, model)
+	}
+	if rate, ok := pcmSampleRate(mimeType); ok {
+		audio = wavFromPCM(audio, rate)
+		mimeType = "audio/wav"
+	}
+
+	return &providers.SpeechResponse{
+		Model:    model,
+		Provider: providers.ProviderGemini,
+		Audio:    audio,
+		MIMEType: mimeType,
+		Usage:    usageFromMetadata(resp.UsageMetadata),
+	}, nil
+}
+
+// SpeechStream implements providers.AudioProvider.SpeechStream. Chunks are raw
+// PCM as sent by Gemini; see the chunk MIME type for the sample rate.
+func (p *Provider) SpeechStream(ctx context.Context, req *providers.SpeechRequest) <-chan providers.StreamEvent {
+	eventChan := make(chan providers.StreamEvent, 10)
+
+	go func() {
+		defer close(eventChan)
+
+		model, contents, config, err := p.adaptSpeechRequest(req)
+		if err != nil {
+			eventChan <- providers.NewErrorEvent(providers.ProviderGemini, req.Model, err, false)
+			return
+		}
+
+		var usage *providers.Usage
+		sequence := 0
+		for chunk, err := range p.client.Models.GenerateContentStream(ctx, model, contents, config) {
+			if err != nil {
+				eventChan <- providers.NewErrorEvent(providers.ProviderGemini, model, apiError(err), false)
+				return
+			}
+			for _, blob := range audioBlobs(chunk) {
+				eventChan <- providers.NewAudioChunkEvent(providers.ProviderGemini, model, blob.Data, blob.MIMEType, sequence)
+				sequence++
+			}
+			if chunk.UsageMetadata != nil {
+				usage = usageFromMetadata(chunk.UsageMetadata)
+			}
+		}
+
+		eventChan <- providers.StreamEvent{
+			Type:      providers.EventFinished,
+			Provider:  providers.ProviderGemini,
+			Model:     model,
+			Timestamp: time.Now(),
+			Metadata:  providers.EventMeta{FinishReason: providers.FinishReasonStop, Usage: usage},
+		}
+	}()
+
+	return eventChan
+}
+
+// Transcribe implements providers.AudioProvider.Transcribe by asking a
+// multimodal model for a verbatim transcript
+func (p *Provider) Transcribe(ctx context.Context, req *providers.TranscriptionRequest) (*providers.TranscriptionResponse, error) {
+	if len(req.Audio.Data) == 0 {
+		return nil, providers.WrapProviderError(fmt.Errorf("transcription audio is empty"), providers.ProviderGemini, req.Model)
+	}
+
+	model := req.Model
+	if model == "" {
+		model = defaultTranscriptionModel
+	}
+	if !p.supportsAudioInput(model) {
+		return nil, providers.NewLLMError(providers.ErrorUnsupportedFeature,
+			fmt.Sprintf("model %s does not accept audio input", model), providers.ProviderGemini, nil)
+	}
+
+	prompt := transcriptionPrompt
+	if req.Language != "" {
+		prompt += " The speech is in language " + req.Language + "."
+	}
+	if req.Prompt != "" {
+		prompt += " Context: " + req.Prompt
+	}
+
+	contents := []*genai.Content{{
+		Role: "user",
+		Parts: []*genai.Part{
+			{Text: prompt},
+			{InlineData: &genai.Blob{MIMEType: providers.NormalizeAudioMIMEType(req.Audio.MIMEType), Data: req.Audio.Data}},
+		},
+	}}
+
+	resp, err := p.client.Models.GenerateContent(ctx, model, contents, nil)
+	if err != nil {
+		return nil, providers.WrapProviderError(apiError(err), providers.ProviderGemini, model)
+	}
+
+	var text strings.Builder
+	if len(resp.Candidates) > 0 && resp.Candidates[0].Content != nil {
+		for _, part := range resp.Candidates[0].Content.Parts {
+			text.WriteString(part.Text)
+		}
+	}
+
+	return &providers.TranscriptionResponse{
+		Model:    model,
+		Provider: providers.ProviderGemini,
+		Text:     strings.TrimSpace(text.String()),
+		Usage:    usageFromMetadata(resp.UsageMetadata),
+	}, nil
+}
+
+// adaptSpeechRequest builds an audio-output generation request
+func (p *Provider) adaptSpeechRequest(req *providers.SpeechRequest) (string, []*genai.Content, *genai.GenerateContentConfig, error) {
+	if req.Input == "" {
+		return "", nil, nil, fmt.Errorf("speech input is required")
+	}
+	model := req.Model
+	if model == "" {
+		model = defaultSpeechModel
+	}
+	voice := req.Voice
+	if voice == "" {
+		voice = defaultSpeechVoice
+	}
+
+	contents := []*genai.Content{{Role: "user", Parts: []*genai.Part{{Text: req.Input}}}}
+	config := &genai.GenerateContentConfig{
+		ResponseModalities: []string{"AUDIO"},
+		SpeechConfig: &genai.SpeechConfig{
+			VoiceConfig: &genai.VoiceConfig{
+				PrebuiltVoiceConfig: &genai.PrebuiltVoiceConfig{VoiceName: voice},
+			},
+		},
+	}
+	return model, contents, config, nil
+}
+
+// audioBlobs returns the inline audio parts of a response
+func audioBlobs(resp *genai.GenerateContentResponse) []*genai.Blob {
+	var blobs []*genai.Blob
+	for _, candidate := range resp.Candidates {
+		if candidate.Content == nil {
+			continue
+		}
+		for _, part := range candidate.Content.Parts {
+			if part.InlineData != nil && strings.HasPrefix(part.InlineData.MIMEType, "audio/") {
+				blobs = append(blobs, part.InlineData)
+			}
+		}
+	}
+	return blobs
+}
+
+// usageFromMetadata converts Gemini usage metadata, tolerating missing counts
+func usageFromMetadata(metadata *genai.GenerateContentResponseUsageMetadata) *providers.Usage {
+	if metadata == nil {
+		return nil
+	}
+	usage := &providers.Usage{TotalTokens: int(metadata.TotalTokenCount)}
+	if metadata.PromptTokenCount != nil {
+		usage.InputTokens = int(*metadata.PromptTokenCount)
+	}
+	if metadata.CandidatesTokenCount != nil {
+		usage.OutputTokens = int(*metadata.CandidatesTokenCount)
+	}
+	if metadata.CachedContentTokenCount != nil {
+		usage.CachedInputTokens = int(*metadata.CachedContentTokenCount)
+	}
+	return usage
+}
+
+// pcmSampleRate parses the rate of an "audio/L16;codec=pcm;rate=24000" MIME type
+func pcmSampleRate(mimeType string) (int, bool) {
+	params := strings.Split(mimeType, ";")
+	if !strings.EqualFold(strings.TrimSpace(params[0]), "audio/L16") {
+		return 0, false
+	}
+	for _, param := range params[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if key == "rate" {
+			rate, err := strconv.Atoi(value)
+			return rate, err == nil && rate > 0
+		}
+	}
+	return 24000, true
+}
+
+// wavFromPCM wraps 16-bit mono little-endian PCM samples in a WAV header
+func wavFromPCM(pcm []byte, sampleRate int) []byte {
+	const channels, bitsPerSample = 1, 16
+	blockAlign := channels * bitsPerSample / 8
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(pcm)))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))
+	binary.Write(&buf, binary.LittleEndian, uint16(1)) // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(bitsPerSample))
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(pcm)))
+	buf.Write(pcm)
+	return buf.Bytes()
+}
@@ -0,0 +1,48 @@
+package gemini
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+)
+
+func TestAdaptSpeechRequest(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+
+	model, contents, config, err := provider.adaptSpeechRequest(&providers.SpeechRequest{Input: "Hello", Voice: "Puck"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if model != defaultSpeechModel || contents[0].Parts[0].Text != "Hello" {
+		t.Errorf("Unexpected request: %s %+v", model, contents[0].Parts[0])
+	}
+	if config.ResponseModalities[0] != "AUDIO" || config.SpeechConfig.VoiceConfig.PrebuiltVoiceConfig.VoiceName != "Puck" {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	if _, _, _, err := provider.adaptSpeechRequest(&providers.SpeechRequest{}); err == nil {
+		t.Error("Expected error for empty input")
+	}
+}
+
+func TestWAVFromPCM(t *testing.T) {
+	rate, ok := pcmSampleRate("audio/L16;codec=pcm;rate=16000")
+	if !ok || rate != 16000 {
+		t.Fatalf("Expected 16000 Hz PCM, got %d (%v)", rate, ok)
+	}
+	if _, ok := pcmSampleRate("audio/mpeg"); ok {
+		t.Error("Expected non-PCM type to be rejected")
+	}
+
+	wav := wavFromPCM([]byte{1, 2, 3, 4}, rate)
+	if string(wav[:4]) != "RIFF" || string(wav[8:12]) != "WAVE" || len(wav) != 48 {
+		t.Fatalf("Unexpected WAV header: %q", wav[:12])
+	}
+	if got := binary.LittleEndian.Uint32(wav[24:28]); got != 16000 {
+		t.Errorf("Expected sample rate 16000, got %d", got)
+	}
+	if got := binary.LittleEndian.Uint32(wav[40:44]); got != 4 {
+		t.Errorf("Expected data size 4, got %d", got)
+	}
+}
@@ -0,0 +1,134 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"gomini/pkg/gomini/providers"
+	"google.golang.org/genai"
+)
+
+// thinkingBudgetKey carries a request's thinking budget to thinkingTransport
+type thinkingBudgetKey struct{}
+
+// withThinkingBudget attaches budget to ctx; a nil budget leaves ctx unchanged
+func withThinkingBudget(ctx context.Context, budget *int32) context.Context {
+	if budget == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, thinkingBudgetKey{}, *budget)
+}
+
+// thinkingBudget returns the thinking budget for a request: a unified
+// Reasoning budget, else, with thinking enabled, the request's
+// thinking_config.thinking_budget or the provider's ThinkingBudget. A budget
+// of 0 turns thinking off on models that allow it.
+func (p *Provider) thinkingBudget(reqConfig interface{}) *int32 {
+	if reasoning, ok := providers.ReasoningFromConfig(reqConfig); ok {
+		if budget := reasoning.Budget(); budget > 0 {
+			value := int32(budget)
+			return &value
+		}
+	}
+	if !p.config.ThinkingEnabled {
+		return nil
+	}
+	if configMap, ok := reqConfig.(map[string]interface{}); ok {
+		if thinkingMap, ok := configMap["thinking_config"].(map[string]interface{}); ok {
+			switch budget := thinkingMap["thinking_budget"].(type) {
+			case int:
+				value := int32(budget)
+				return &value
+			case float64:
+				value := int32(budget)
+				return &value
+			}
+		}
+	}
+	if p.config.ThinkingBudget > 0 {
+		value := int32(p.config.ThinkingBudget)
+		return &value
+	}
+	return nil
+}
+
+// thinkingTransport adds generationConfig.thinkingConfig.thinkingBudget to
+// requests whose context carries a budget. The genai SDK version in use has
+// no field for it and drops unknown thinking settings.
+type thinkingTransport struct {
+	base http.RoundTripper
+}
+
+// installThinkingTransport wraps the HTTP client the genai client sends with
+func installThinkingTransport(client *genai.Client) {
+	wrapTransport(client, func(base http.RoundTripper) http.RoundTripper {
+		return &thinkingTransport{base: base}
+	})
+}
+
+// wrapTransport replaces the transport of the genai client's HTTP client
+// with wrap applied to it
+func wrapTransport(client *genai.Client, wrap func(http.RoundTripper) http.RoundTripper) {
+	httpClient := client.ClientConfig().HTTPClient
+	if httpClient == nil {
+		return
+	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	httpClient.Transport = wrap(base)
+}
+
+func (t *thinkingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	budget, ok := req.Context().Value(thinkingBudgetKey{}).(int32)
+	if !ok || req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if patched, ok := setThinkingBudget(body, budget); ok {
+		body = patched
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return t.base.RoundTrip(req)
+}
+
+// setThinkingBudget sets the thinking budget in a GenerateContent request body
+func setThinkingBudget(body []byte, budget int32) ([]byte, bool) {
+	var request map[string]interface{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, false
+	}
+
+	generationConfig, _ := request["generationConfig"].(map[string]interface{})
+	if generationConfig == nil {
+		generationConfig = map[string]interface{}{}
+		request["generationConfig"] = generationConfig
+	}
+	thinkingConfig, _ := generationConfig["thinkingConfig"].(map[string]interface{})
+	if thinkingConfig == nil {
+		thinkingConfig = map[string]interface{}{}
+		generationConfig["thinkingConfig"] = thinkingConfig
+	}
+	thinkingConfig["thinkingBudget"] = budget
+
+	patched, err := json.Marshal(request)
+	if err != nil {
+		return nil, false
+	}
+	return patched, true
+}
@@ -0,0 +1,196 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+	"google.golang.org/genai"
+)
+
+func TestProvider_ThinkingBudgetAndThoughts(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		json.Unmarshal(raw, &body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[
+			{"text":"Let me think about the question.","thought":true},
+			{"text":"42"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{
+		APIKey:          "test-key",
+		BaseURL:         server.URL + "/",
+		ThinkingEnabled: true,
+		ThinkingBudget:  2048,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	resp, err := provider.SendMessage(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "answer?"}},
+		Model:    "gemini-2.5-flash",
+		Config: map[string]interface{}{
+			"thinking_config": map[string]interface{}{"include_thoughts": true, "thinking_budget": 512},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	generationConfig, _ := body["generationConfig"].(map[string]interface{})
+	thinkingConfig, _ := generationConfig["thinkingConfig"].(map[string]interface{})
+	if thinkingConfig["thinkingBudget"] != 512.0 || thinkingConfig["includeThoughts"] != true {
+		t.Errorf("expected the request's thinking config to be sent, got %+v", generationConfig)
+	}
+
+	if choice := resp.Choices[0]; choice.Content() != "42" || choice.Reasoning() != "Let me think about the question." {
+		t.Errorf("expected the thought part as reasoning, got %+v", choice.Message)
+	}
+}
+
+func TestProvider_ThinkingBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		request interface{}
+		want    *int32
+	}{
+		{"disabled", Config{ThinkingBudget: 1024}, nil, nil},
+		{"provider default", Config{ThinkingEnabled: true, ThinkingBudget: 1024}, nil, int32Ptr(1024)},
+		{"request override", Config{ThinkingEnabled: true, ThinkingBudget: 1024},
+			map[string]interface{}{"thinking_config": map[string]interface{}{"thinking_budget": 0.0}}, int32Ptr(0)},
+		{"no budget", Config{ThinkingEnabled: true}, nil, nil},
+		{"reasoning effort without thinking enabled", Config{},
+			map[string]interface{}{providers.ConfigReasoning: providers.Reasoning{Effort: providers.ReasoningEffortLow}}, int32Ptr(1024)},
+		{"reasoning budget over thinking config", Config{ThinkingEnabled: true},
+			map[string]interface{}{
+				providers.ConfigReasoning: providers.Reasoning{BudgetTokens: 300},
+				"thinking_config":         map[string]interface{}{"thinking_budget": 900},
+			}, int32Ptr(300)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &Provider{config: &tt.config}
+			got := provider.thinkingBudget(tt.request)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAdaptStreamChunk_ThoughtFlag(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	prose := "Let me explain: thinking about reasoning is what this long answer does, and it is not a thought summary at all, just prose."
+
+	events := provider.adaptStreamChunk(&genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{
+			Content: &genai.Content{Parts: []*genai.Part{
+				{Text: "Planning the answer", Thought: true},
+				{Text: prose},
+			}},
+		}},
+	}, "gemini-2.5-pro")
+
+	if len(events) != 2 || events[0].Type != providers.EventThought || events[1].Type != providers.EventContent {
+		t.Fatalf("expected a thought then content, got %+v", events)
+	}
+	if content := events[1].Data.(providers.ContentEvent); content.Text != prose {
+		t.Errorf("expected prose to stay content, got %+v", content)
+	}
+}
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func TestProvider_ExtraQueryAndBody(t *testing.T) {
+	var body map[string]interface{}
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		json.Unmarshal(raw, &body)
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{
+		APIKey:          "test-key",
+		BaseURL:         server.URL + "/",
+		ThinkingEnabled: true,
+		ThinkingBudget:  256,
+		ExtraQuery:      map[string]string{"tenant": "blue"},
+		ExtraBody:       map[string]interface{}{"cachedContent": "cachedContents/abc"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	_, err = provider.SendMessage(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "hi"}},
+		Model:    "gemini-2.5-flash",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	if query != "tenant=blue" {
+		t.Errorf("expected the extra query parameter, got %q", query)
+	}
+	if body["cachedContent"] != "cachedContents/abc" || body["contents"] == nil {
+		t.Errorf("expected the extra body field merged into the request, got %+v", body)
+	}
+	generationConfig, _ := body["generationConfig"].(map[string]interface{})
+	if thinkingConfig, _ := generationConfig["thinkingConfig"].(map[string]interface{}); thinkingConfig["thinkingBudget"] != 256.0 {
+		t.Errorf("expected the thinking budget alongside the extras, got %+v", generationConfig)
+	}
+}
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestProvider_HTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	client := &http.Client{Transport: transport}
+	provider, err := NewProvider(&Config{APIKey: "test-key", BaseURL: server.URL + "/", HTTPClient: client})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	_, err = provider.SendMessage(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "hi"}},
+		Model:    "gemini-2.0-flash",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if transport.requests != 1 {
+		t.Errorf("expected the request to go through the injected client, got %d requests", transport.requests)
+	}
+	if client.Transport != transport {
+		t.Error("expected the caller's client to be left unwrapped")
+	}
+}
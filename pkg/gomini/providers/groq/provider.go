@@ -0,0 +1,114 @@
+// Package groq provides models hosted on Groq through their OpenAI-compatible API
+package groq
+
+import (
+	"net/http"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+	"gomini/pkg/gomini/providers/openai"
+)
+
+// DefaultBaseURL is Groq's OpenAI-compatible endpoint
+const DefaultBaseURL = "https://api.groq.com/openai/v1"
+
+// Config holds Groq-specific configuration
+type Config struct {
+	APIKey       string                 `json:"api_key"`
+	BaseURL      string                 `json:"base_url,omitempty"`
+	DefaultModel string                 `json:"default_model,omitempty"`
+	ExtraHeaders map[string]string      `json:"extra_headers,omitempty"`
+	ExtraQuery   map[string]string      `json:"extra_query,omitempty"`
+	ExtraBody    map[string]interface{} `json:"extra_body,omitempty"`
+	HTTPClient   *http.Client           `json:"-"`
+	Timeout      time.Duration          `json:"timeout,omitempty"`
+
+	// Capability overrides and model discovery; see openai.Config
+	Capabilities   providers.CapabilityTable `json:"capabilities,omitempty"`
+	DiscoverModels bool                      `json:"discover_models,omitempty"`
+	ModelsTTL      time.Duration             `json:"models_ttl,omitempty"`
+}
+
+// NewProvider creates a Groq provider
+func NewProvider(config *Config) (*openai.Provider, error) {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return openai.NewProvider(&openai.Config{
+		APIKey:       config.APIKey,
+		BaseURL:      baseURL,
+		DefaultModel: config.DefaultModel,
+		ExtraHeaders: config.ExtraHeaders,
+		ExtraQuery:   config.ExtraQuery,
+		ExtraBody:    config.ExtraBody,
+		HTTPClient:   config.HTTPClient,
+		Timeout:      config.Timeout,
+		ProviderType: providers.ProviderGroq,
+		Models:       Models(),
+
+		Capabilities:   config.Capabilities,
+		DiscoverModels: config.DiscoverModels,
+		ModelsTTL:      config.ModelsTTL,
+	})
+}
+
+// Models returns the Groq model catalog
+func Models() []providers.Model {
+	return []providers.Model{
+		{
+			ID:       "llama-3.3-70b-versatile",
+			Name:     "Llama 3.3 70B Versatile",
+			Provider: providers.ProviderGroq,
+			Capabilities: providers.ModelCapabilities{
+				TextGeneration:  true,
+				FunctionCalling: true,
+				JSONMode:        true,
+				SystemMessage:   true,
+				Streaming:       true,
+			},
+			ContextSize: 128000,
+			Cost: &providers.ModelCost{
+				InputTokens:  0.59, // $0.59 per 1M input tokens
+				OutputTokens: 0.79, // $0.79 per 1M output tokens
+				Currency:     "USD",
+			},
+		},
+		{
+			ID:       "llama-3.1-8b-instant",
+			Name:     "Llama 3.1 8B Instant",
+			Provider: providers.ProviderGroq,
+			Capabilities: providers.ModelCapabilities{
+				TextGeneration:  true,
+				FunctionCalling: true,
+				JSONMode:        true,
+				SystemMessage:   true,
+				Streaming:       true,
+			},
+			ContextSize: 128000,
+			Cost: &providers.ModelCost{
+				InputTokens:  0.05, // $0.05 per 1M input tokens
+				OutputTokens: 0.08, // $0.08 per 1M output tokens
+				Currency:     "USD",
+			},
+		},
+		{
+			ID:       "deepseek-r1-distill-llama-70b",
+			Name:     "DeepSeek R1 Distill Llama 70B",
+			Provider: providers.ProviderGroq,
+			Capabilities: providers.ModelCapabilities{
+				TextGeneration: true,
+				SystemMessage:  true,
+				Streaming:      true,
+				ThinkingMode:   true,
+			},
+			ContextSize: 128000,
+			Cost: &providers.ModelCost{
+				InputTokens:  0.75, // $0.75 per 1M input tokens
+				OutputTokens: 0.99, // $0.99 per 1M output tokens
+				Currency:     "USD",
+			},
+		},
+	}
+}
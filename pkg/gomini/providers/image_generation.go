@@ -0,0 +1,64 @@
+package providers
+
+import "context"
+
+// ImageGenerator is implemented by providers that can generate images from a prompt
+type ImageGenerator interface {
+	// GenerateImage generates one or more images for the request's prompt
+	GenerateImage(ctx context.Context, req *ImageRequest) (*ImageResponse, error)
+}
+
+// Image response formats
+const (
+	ImageFormatURL    = "url"
+	ImageFormatBase64 = "b64_json"
+)
+
+// ImageRequest asks a model to generate images. Fields a provider does not
+// support are ignored.
+type ImageRequest struct {
+	Prompt         string       `json:"prompt"`
+	Model          string       `json:"model"`
+	Provider       ProviderType `json:"provider,omitempty"`
+	N              int          `json:"n,omitempty"`               // Number of images, defaults to 1
+	Size           string       `json:"size,omitempty"`            // e.g. "1024x1024" (OpenAI)
+	AspectRatio    string       `json:"aspect_ratio,omitempty"`    // e.g. "16:9" (Imagen)
+	Quality        string       `json:"quality,omitempty"`         // "standard" or "hd" (OpenAI)
+	Style          string       `json:"style,omitempty"`           // "vivid" or "natural" (OpenAI)
+	NegativePrompt string       `json:"negative_prompt,omitempty"` // What to avoid (Imagen)
+	ResponseFormat string       `json:"response_format,omitempty"` // ImageFormatURL or ImageFormatBase64
+}
+
+// GeneratedImage is a single generated image, returned either as a URL or as bytes
+type GeneratedImage struct {
+	URL           string `json:"url,omitempty"`
+	Data          []byte `json:"data,omitempty"`
+	MIMEType      string `json:"mime_type,omitempty"`
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+}
+
+// ImageUsage reports how many images were billed and what they cost
+type ImageUsage struct {
+	Images   int     `json:"images"`
+	Cost     float64 `json:"cost,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+// ImageResponse holds the images generated for an ImageRequest
+type ImageResponse struct {
+	Model    string           `json:"model"`
+	Provider ProviderType     `json:"provider"`
+	Images   []GeneratedImage `json:"images"`
+	Usage    *ImageUsage      `json:"usage,omitempty"`
+	Created  int64            `json:"created,omitempty"`
+}
+
+// NewImageUsage prices a number of images using the model's per-image cost
+func NewImageUsage(models []Model, modelID string, images int) *ImageUsage {
+	usage := &ImageUsage{Images: images}
+	if model, ok := FindModel(models, modelID); ok && model.Cost != nil {
+		usage.Cost = model.Cost.PerImage * float64(images)
+		usage.Currency = model.Cost.Currency
+	}
+	return usage
+}
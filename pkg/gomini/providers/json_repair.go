@@ -0,0 +1,175 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSON repair kinds recorded in JSONResponse metadata
+const (
+	RepairStrippedProse      = "stripped_prose"
+	RepairSingleQuotes       = "single_quotes"
+	RepairTrailingCommas     = "trailing_commas"
+	RepairUnbalancedBrackets = "unbalanced_brackets"
+	RepairUnterminatedString = "unterminated_string"
+)
+
+// JSONResponse metadata keys set when malformed output was repaired
+const (
+	MetadataJSONRepaired = "json_repaired"
+	MetadataJSONRepairs  = "json_repairs"
+)
+
+// RepairJSON attempts to turn malformed model output into valid JSON. It strips prose
+// around the document, converts single-quoted strings, drops trailing commas and
+// balances brackets. It returns the repaired text and the repairs applied, or an
+// error if the result is still not valid JSON.
+func RepairJSON(text string) (string, []string, error) {
+	var repairs []string
+	applied := make(map[string]bool)
+	record := func(kind string) {
+		if !applied[kind] {
+			applied[kind] = true
+			repairs = append(repairs, kind)
+		}
+	}
+
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return "", nil, fmt.Errorf("no JSON object or array found")
+	}
+	end := strings.LastIndexAny(text, "}]")
+	trimmed := text[start:]
+	if end > start {
+		trimmed = text[start : end+1]
+	}
+	if strings.TrimSpace(text) != trimmed {
+		record(RepairStrippedProse)
+	}
+
+	var (
+		out      strings.Builder
+		stack    []byte
+		inString bool
+		quote    byte
+		escaped  bool
+	)
+
+	// dropTrailingComma removes a comma left before a closing bracket
+	dropTrailingComma := func() {
+		s := strings.TrimRight(out.String(), " \t\r\n")
+		if strings.HasSuffix(s, ",") {
+			out.Reset()
+			out.WriteString(s[:len(s)-1])
+			record(RepairTrailingCommas)
+		}
+	}
+
+	for i := 0; i < len(trimmed); i++ {
+		c := trimmed[i]
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+				out.WriteByte(c)
+			case c == '\\':
+				if quote == '\'' && i+1 < len(trimmed) && trimmed[i+1] == '\'' {
+					// \' is not a valid JSON escape
+					out.WriteByte('\'')
+					i++
+					continue
+				}
+				escaped = true
+				out.WriteByte(c)
+			case c == quote:
+				inString = false
+				out.WriteByte('"')
+			case c == '"' && quote == '\'':
+				out.WriteString(`\"`)
+			default:
+				out.WriteByte(c)
+			}
+			continue
+		}
+
+		switch c {
+		case '"', '\'':
+			if c == '\'' {
+				record(RepairSingleQuotes)
+			}
+			inString = true
+			quote = c
+			out.WriteByte('"')
+		case '{':
+			stack = append(stack, '}')
+			out.WriteByte(c)
+		case '[':
+			stack = append(stack, ']')
+			out.WriteByte(c)
+		case '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != c {
+				record(RepairUnbalancedBrackets)
+				continue
+			}
+			dropTrailingComma()
+			stack = stack[:len(stack)-1]
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	if inString {
+		if escaped {
+			s := out.String()
+			out.Reset()
+			out.WriteString(s[:len(s)-1])
+		}
+		out.WriteByte('"')
+		record(RepairUnterminatedString)
+	}
+	if len(stack) > 0 {
+		record(RepairUnbalancedBrackets)
+		for i := len(stack) - 1; i >= 0; i-- {
+			dropTrailingComma()
+			out.WriteByte(stack[i])
+		}
+	}
+
+	repaired := out.String()
+	if !json.Valid([]byte(repaired)) {
+		return "", repairs, fmt.Errorf("JSON could not be repaired")
+	}
+	return repaired, repairs, nil
+}
+
+// UnmarshalJSONWithRepair decodes text into v, running RepairJSON if the first attempt
+// fails. It returns the repairs applied, which is empty when none were needed.
+func UnmarshalJSONWithRepair(text string, v interface{}) ([]string, error) {
+	err := json.Unmarshal([]byte(text), v)
+	if err == nil {
+		return nil, nil
+	}
+
+	repaired, repairs, repairErr := RepairJSON(text)
+	if repairErr != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(repaired), v); err != nil {
+		return nil, err
+	}
+	return repairs, nil
+}
+
+// RepairMetadata builds JSONResponse metadata recording applied repairs, or nil if none
+func RepairMetadata(repairs []string) map[string]interface{} {
+	if len(repairs) == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		MetadataJSONRepaired: true,
+		MetadataJSONRepairs:  repairs,
+	}
+}
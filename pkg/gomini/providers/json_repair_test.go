@@ -0,0 +1,103 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		repairs  []string
+	}{
+		{
+			name:     "prose around object",
+			input:    "Sure! Here is the result:\n{\"a\": 1}\nLet me know if you need more.",
+			expected: `{"a": 1}`,
+			repairs:  []string{RepairStrippedProse},
+		},
+		{
+			name:     "trailing commas",
+			input:    `{"a": [1, 2, ], "b": 3,}`,
+			expected: `{"a": [1, 2], "b": 3}`,
+			repairs:  []string{RepairTrailingCommas},
+		},
+		{
+			name:     "single quotes",
+			input:    `{'name': 'Ada "the" Countess', 'nick': 'it\'s'}`,
+			expected: `{"name": "Ada \"the\" Countess", "nick": "it's"}`,
+			repairs:  []string{RepairSingleQuotes},
+		},
+		{
+			name:     "missing closers",
+			input:    `{"a": {"b": [1, 2`,
+			expected: `{"a": {"b": [1, 2]}}`,
+			repairs:  []string{RepairUnbalancedBrackets},
+		},
+		{
+			name:     "extra closer",
+			input:    `{"a": 1}}`,
+			expected: `{"a": 1}`,
+			repairs:  []string{RepairUnbalancedBrackets},
+		},
+		{
+			name:     "unterminated string",
+			input:    `{"a": "hello`,
+			expected: `{"a": "hello"}`,
+			repairs:  []string{RepairUnterminatedString, RepairUnbalancedBrackets},
+		},
+		{
+			name:     "braces inside strings are preserved",
+			input:    `{"a": "x}, ]",}`,
+			expected: `{"a": "x}, ]"}`,
+			repairs:  []string{RepairTrailingCommas},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repaired, repairs, err := RepairJSON(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repaired != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, repaired)
+			}
+			if !reflect.DeepEqual(repairs, tt.repairs) {
+				t.Errorf("expected repairs %v, got %v", tt.repairs, repairs)
+			}
+		})
+	}
+}
+
+func TestRepairJSON_Unrepairable(t *testing.T) {
+	for _, input := range []string{"no json here", `{"a": tru}`} {
+		if _, _, err := RepairJSON(input); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
+
+func TestUnmarshalJSONWithRepair(t *testing.T) {
+	var data map[string]interface{}
+	repairs, err := UnmarshalJSONWithRepair(`{"a": 1}`, &data)
+	if err != nil || repairs != nil {
+		t.Fatalf("expected valid JSON to decode without repairs, got %v, %v", repairs, err)
+	}
+
+	repairs, err = UnmarshalJSONWithRepair(`{"a": 1,}`, &data)
+	if err != nil || len(repairs) != 1 {
+		t.Fatalf("expected one repair, got %v, %v", repairs, err)
+	}
+
+	var syntaxErr *json.SyntaxError
+	if _, err := UnmarshalJSONWithRepair(`nothing`, &data); err == nil {
+		t.Fatal("expected an error")
+	} else if !errors.As(err, &syntaxErr) {
+		t.Errorf("expected the original decode error, got %T", err)
+	}
+}
@@ -0,0 +1,41 @@
+package providers
+
+import "time"
+
+// StreamLatency measures how quickly a stream answered, reported on its
+// finished event
+type StreamLatency struct {
+	TimeToFirstToken time.Duration `json:"ttft"`                        // Request start to the first output event
+	Duration         time.Duration `json:"duration"`                    // Request start to the finished event
+	TokensPerSecond  float64       `json:"tokens_per_second,omitempty"` // Output tokens over the time spent generating them; 0 without usage
+}
+
+// NewStreamLatency computes the latency of a stream started at start whose
+// first output arrived at firstToken and which finished at finished. A stream
+// without output counts its first token at finished.
+func NewStreamLatency(start, firstToken, finished time.Time, usage *Usage) *StreamLatency {
+	if firstToken.IsZero() {
+		firstToken = finished
+	}
+	latency := &StreamLatency{
+		TimeToFirstToken: firstToken.Sub(start),
+		Duration:         finished.Sub(start),
+	}
+
+	var outputTokens int
+	if usage != nil {
+		outputTokens = usage.OutputTokens
+		if outputTokens == 0 {
+			outputTokens = usage.CompletionTokens
+		}
+	}
+	// Streams delivered in one chunk have no generation time of their own
+	generating := finished.Sub(firstToken)
+	if generating <= 0 {
+		generating = latency.Duration
+	}
+	if outputTokens > 0 && generating > 0 {
+		latency.TokensPerSecond = float64(outputTokens) / generating.Seconds()
+	}
+	return latency
+}
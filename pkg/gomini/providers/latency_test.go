@@ -0,0 +1,37 @@
+package providers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewStreamLatency(t *testing.T) {
+	start := time.Unix(1000, 0)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	tests := []struct {
+		name       string
+		firstToken time.Time
+		finished   time.Time
+		usage      *Usage
+		wantTTFT   time.Duration
+		wantTPS    float64
+	}{
+		{"streamed", at(500), at(2500), &Usage{OutputTokens: 100}, 500 * time.Millisecond, 50},
+		{"completion tokens", at(500), at(1500), &Usage{CompletionTokens: 30}, 500 * time.Millisecond, 30},
+		{"one chunk", at(1000), at(1000), &Usage{OutputTokens: 20}, time.Second, 20},
+		{"no output", time.Time{}, at(800), &Usage{OutputTokens: 0}, 800 * time.Millisecond, 0},
+		{"no usage", at(200), at(700), nil, 200 * time.Millisecond, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latency := NewStreamLatency(start, tt.firstToken, tt.finished, tt.usage)
+			if latency.TimeToFirstToken != tt.wantTTFT || latency.TokensPerSecond != tt.wantTPS {
+				t.Errorf("Got %+v, want TTFT %v and %v tokens/s", latency, tt.wantTTFT, tt.wantTPS)
+			}
+			if latency.Duration != tt.finished.Sub(start) {
+				t.Errorf("Expected duration %v, got %v", tt.finished.Sub(start), latency.Duration)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package providers
+
+import "math"
+
+// Request config keys asking for token log probabilities, named as in
+// OpenAI's API. Providers without log probabilities ignore them.
+const (
+	ConfigLogprobs    = "logprobs"     // bool
+	ConfigTopLogprobs = "top_logprobs" // int; alternatives returned per token, implies logprobs
+)
+
+// TokenLogprob is the log probability of one generated token
+type TokenLogprob struct {
+	Token       string         `json:"token"`
+	Logprob     float64        `json:"logprob"`
+	Bytes       []int          `json:"bytes,omitempty"`        // UTF-8 bytes of the token, for tokens that split characters
+	TopLogprobs []TokenLogprob `json:"top_logprobs,omitempty"` // Likeliest tokens at this position, with top_logprobs set
+}
+
+// Probability returns the token's probability, from 0 to 1
+func (t TokenLogprob) Probability() float64 {
+	return math.Exp(t.Logprob)
+}
+
+// LogprobsFromConfig reads logprobs and top_logprobs from a request config
+// map. It reports whether log probabilities were requested and how many
+// alternatives to return per token.
+func LogprobsFromConfig(config RequestConfig) (bool, int) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return false, 0
+	}
+
+	var top int
+	switch value := configMap[ConfigTopLogprobs].(type) {
+	case int:
+		top = value
+	case float64:
+		top = int(value)
+	}
+	enabled, _ := configMap[ConfigLogprobs].(bool)
+	return enabled || top > 0, top
+}
@@ -0,0 +1,266 @@
+package providers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ImagePart is an image exchanged with a model, either inline bytes or a URL
+type ImagePart struct {
+	MIMEType string `json:"mime_type,omitempty"`
+	Data     []byte `json:"data,omitempty"` // Raw (decoded) image bytes
+	URL      string `json:"url,omitempty"`  // Remote image location when not inline
+}
+
+// ImageEvent carries an image produced by the model during streaming
+type ImageEvent struct {
+	Image ImagePart `json:"image"`
+}
+
+// ParseDataURL decodes an RFC 2397 data URL ("data:image/png;base64,....")
+// into its MIME type and payload
+func ParseDataURL(dataURL string) (string, []byte, error) {
+	if !strings.HasPrefix(dataURL, "data:") {
+		return "", nil, fmt.Errorf("not a data URL")
+	}
+
+	header, payload, found := strings.Cut(dataURL[len("data:"):], ",")
+	if !found {
+		return "", nil, fmt.Errorf("malformed data URL: missing ','")
+	}
+
+	mimeType := "text/plain"
+	isBase64 := false
+	for i, param := range strings.Split(header, ";") {
+		switch {
+		case i == 0 && param != "":
+			mimeType = strings.ToLower(param)
+		case param == "base64":
+			isBase64 = true
+		}
+	}
+
+	if isBase64 {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			// Some clients emit unpadded or URL-safe base64
+			if data, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(payload, "=")); err != nil {
+				return "", nil, fmt.Errorf("invalid base64 payload in data URL: %w", err)
+			}
+		}
+		return mimeType, data, nil
+	}
+
+	decoded, err := url.PathUnescape(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid data URL payload: %w", err)
+	}
+	return mimeType, []byte(decoded), nil
+}
+
+// DecodeImageDataURL decodes an image sent as a data URL, as browser clients
+// commonly do. An explicit mimeType overrides the one declared in the URL, and
+// the result must be an image.
+func DecodeImageDataURL(dataURL, mimeType string) (string, []byte, error) {
+	mimeType, data, err := DecodeBase64Image(dataURL, mimeType)
+	if err != nil {
+		return "", nil, err
+	}
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", nil, fmt.Errorf("data URL is %s, not an image", mimeType)
+	}
+	return mimeType, data, nil
+}
+
+// ImagePartData returns the payload of an image content part. Besides the
+// unified "data" field it accepts the OpenAI wire shape sent by browser
+// clients: {"type": "image_url", "image_url": {"url": "data:..."}}.
+func ImagePartData(part map[string]interface{}) map[string]interface{} {
+	if data, ok := part["data"].(map[string]interface{}); ok {
+		return data
+	}
+	data, _ := part["image_url"].(map[string]interface{})
+	return data
+}
+
+// ImageLimits bounds images read from files, readers, inline data or fetched
+// from URLs
+type ImageLimits struct {
+	MaxBytes             int64         `json:"max_bytes"`
+	AllowedMIMETypes     []string      `json:"allowed_mime_types"`
+	Timeout              time.Duration `json:"timeout"`                          // Applies to URL fetches
+	AllowPrivateNetworks bool          `json:"allow_private_networks,omitempty"` // Lets URL fetches reach loopback, private and link-local addresses
+}
+
+// DefaultImageLimits returns limits matching what both providers accept inline
+func DefaultImageLimits() ImageLimits {
+	return ImageLimits{
+		MaxBytes:         20 << 20, // 20MB
+		AllowedMIMETypes: []string{"image/png", "image/jpeg", "image/gif", "image/webp", "image/heic", "image/heif"},
+		Timeout:          30 * time.Second,
+	}
+}
+
+// allows reports whether the MIME type is permitted
+func (l ImageLimits) allows(mimeType string) bool {
+	if len(l.AllowedMIMETypes) == 0 {
+		return true
+	}
+	for _, allowed := range l.AllowedMIMETypes {
+		if allowed == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeBase64Image decodes a base64 image payload, which may also be a data URL.
+// When mimeType is empty it is taken from the data URL or sniffed from the bytes.
+func DecodeBase64Image(value, mimeType string) (string, []byte, error) {
+	if strings.HasPrefix(value, "data:") {
+		urlMIME, data, err := ParseDataURL(value)
+		if err != nil {
+			return "", nil, err
+		}
+		if mimeType == "" {
+			mimeType = urlMIME
+		}
+		return mimeType, data, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		if data, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "=")); err != nil {
+			return "", nil, fmt.Errorf("invalid base64 image data: %w", err)
+		}
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return mimeType, data, nil
+}
+
+// ReadImage reads an image from r, enforcing the size and MIME limits.
+// When mimeType is empty it is sniffed from the content.
+func ReadImage(r io.Reader, mimeType string, limits ImageLimits) (string, []byte, error) {
+	reader := r
+	if limits.MaxBytes > 0 {
+		reader = io.LimitReader(r, limits.MaxBytes+1)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	mimeType, err = CheckImage(mimeType, data, limits)
+	if err != nil {
+		return "", nil, err
+	}
+	return mimeType, data, nil
+}
+
+// CheckImage enforces the size and MIME limits on an image already in memory,
+// such as inline base64 data, and returns its MIME type without parameters.
+// When mimeType is empty it is sniffed from the content.
+func CheckImage(mimeType string, data []byte, limits ImageLimits) (string, error) {
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return "", fmt.Errorf("image exceeds %d bytes", limits.MaxBytes)
+	}
+
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = http.DetectContentType(data)
+	}
+	if mediaType, _, found := strings.Cut(mimeType, ";"); found {
+		mimeType = strings.TrimSpace(mediaType)
+	}
+	if !limits.allows(mimeType) {
+		return "", fmt.Errorf("unsupported image type %q", mimeType)
+	}
+	return mimeType, nil
+}
+
+// maxImageRedirects caps the redirects followed when fetching an image
+const maxImageRedirects = 3
+
+// FetchImage downloads an http(s) image with client, or http.DefaultClient
+// when nil, enforcing the size and MIME limits. Unless the limits allow
+// private networks, a URL or redirect whose host resolves to a loopback,
+// private or link-local address is refused, so image URLs in requests cannot
+// reach internal services.
+func FetchImage(ctx context.Context, client *http.Client, imageURL string, limits ImageLimits) (string, []byte, error) {
+	parsed, err := url.Parse(imageURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", nil, fmt.Errorf("unsupported image URL %q", imageURL)
+	}
+
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+	}
+	if err := limits.checkHost(ctx, parsed); err != nil {
+		return "", nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create image request: %w", err)
+	}
+
+	// Copy the client so redirects can be checked without changing the caller's
+	fetcher := *http.DefaultClient
+	if client != nil {
+		fetcher = *client
+	}
+	fetcher.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxImageRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxImageRedirects)
+		}
+		if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+			return fmt.Errorf("unsupported image URL %q", req.URL)
+		}
+		return limits.checkHost(req.Context(), req.URL)
+	}
+
+	resp, err := fetcher.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("failed to fetch image: HTTP %d", resp.StatusCode)
+	}
+	if limits.MaxBytes > 0 && resp.ContentLength > limits.MaxBytes {
+		return "", nil, fmt.Errorf("image exceeds %d bytes", limits.MaxBytes)
+	}
+
+	return ReadImage(resp.Body, resp.Header.Get("Content-Type"), limits)
+}
+
+// checkHost refuses a URL whose host resolves to a loopback, private,
+// link-local or unspecified address, unless AllowPrivateNetworks is set
+func (l ImageLimits) checkHost(ctx context.Context, u *url.URL) error {
+	if l.AllowPrivateNetworks {
+		return nil
+	}
+	host := u.Hostname()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve image host %q: %w", host, err)
+	}
+	for _, addr := range addrs {
+		ip := addr.IP
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+			return fmt.Errorf("image host %q resolves to non-public address %s", host, ip)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pngHeader is enough of a PNG signature for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestDecodeBase64Image(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(pngHeader)
+
+	tests := []struct {
+		name         string
+		value        string
+		mimeType     string
+		expectedMIME string
+		expectError  bool
+	}{
+		{name: "sniffed", value: encoded, expectedMIME: "image/png"},
+		{name: "explicit mime", value: encoded, mimeType: "image/webp", expectedMIME: "image/webp"},
+		{name: "data url", value: "data:image/gif;base64," + encoded, expectedMIME: "image/gif"},
+		{name: "invalid", value: "not base64!", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mimeType, data, err := DecodeBase64Image(tt.value, tt.mimeType)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mimeType != tt.expectedMIME {
+				t.Errorf("expected mime %s, got %s", tt.expectedMIME, mimeType)
+			}
+			if !bytes.Equal(data, pngHeader) {
+				t.Errorf("expected decoded bytes, got %q", data)
+			}
+		})
+	}
+}
+
+func TestDecodeImageDataURL(t *testing.T) {
+	urlSafe := base64.RawURLEncoding.EncodeToString(pngHeader)
+
+	tests := []struct {
+		name         string
+		dataURL      string
+		mimeType     string
+		expectedMIME string
+		expectError  bool
+	}{
+		{name: "declared mime", dataURL: "data:image/png;base64," + urlSafe, expectedMIME: "image/png"},
+		{name: "explicit mime wins", dataURL: "data:image/png;base64," + urlSafe, mimeType: "image/webp", expectedMIME: "image/webp"},
+		{name: "not an image", dataURL: "data:text/plain,hello", expectError: true},
+		{name: "missing payload separator", dataURL: "data:image/png;base64", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mimeType, data, err := DecodeImageDataURL(tt.dataURL, tt.mimeType)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mimeType != tt.expectedMIME || !bytes.Equal(data, pngHeader) {
+				t.Errorf("expected %s image, got %s %q", tt.expectedMIME, mimeType, data)
+			}
+		})
+	}
+}
+
+func TestReadImage_Limits(t *testing.T) {
+	limits := ImageLimits{MaxBytes: 32, AllowedMIMETypes: []string{"image/png"}}
+
+	if mimeType, _, err := ReadImage(bytes.NewReader(pngHeader), "", limits); err != nil || mimeType != "image/png" {
+		t.Errorf("expected image/png, got %q (err %v)", mimeType, err)
+	}
+
+	tooLarge := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{0}, 32)...)
+	if _, _, err := ReadImage(bytes.NewReader(tooLarge), "", limits); err == nil {
+		t.Error("expected size limit error")
+	}
+
+	if _, _, err := ReadImage(strings.NewReader("plain text"), "", limits); err == nil {
+		t.Error("expected mime type error")
+	}
+}
+
+func TestCheckImage(t *testing.T) {
+	limits := ImageLimits{MaxBytes: 32, AllowedMIMETypes: []string{"image/png"}}
+
+	if mimeType, err := CheckImage("image/png; charset=binary", pngHeader, limits); err != nil || mimeType != "image/png" {
+		t.Errorf("expected image/png, got %q (err %v)", mimeType, err)
+	}
+	if _, err := CheckImage("", bytes.Repeat(pngHeader, 8), limits); err == nil {
+		t.Error("expected size limit error")
+	}
+	if _, err := CheckImage("image/gif", pngHeader, limits); err == nil {
+		t.Error("expected mime type error")
+	}
+}
+
+func TestFetchImage(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngHeader)
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		case "/loop":
+			http.Redirect(w, r, server.URL+"/loop", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	limits := DefaultImageLimits()
+	limits.AllowPrivateNetworks = true
+
+	mimeType, data, err := FetchImage(ctx, server.Client(), server.URL+"/image.png", limits)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mimeType != "image/png" || !bytes.Equal(data, pngHeader) {
+		t.Errorf("unexpected image %s %q", mimeType, data)
+	}
+
+	for _, path := range []string{"/page.html", "/missing.png", "/loop"} {
+		if _, _, err := FetchImage(ctx, server.Client(), server.URL+path, limits); err == nil {
+			t.Errorf("expected error for %s", path)
+		}
+	}
+
+	small := limits
+	small.MaxBytes = 4
+	if _, _, err := FetchImage(ctx, nil, server.URL+"/image.png", small); err == nil {
+		t.Error("expected size limit error")
+	}
+
+	if _, _, err := FetchImage(ctx, nil, "file:///etc/passwd", DefaultImageLimits()); err == nil {
+		t.Error("expected unsupported scheme error")
+	}
+
+	// Internal addresses are refused unless allowed
+	for _, imageURL := range []string{server.URL + "/image.png", "http://169.254.169.254/latest/meta-data", "http://10.0.0.1/image.png", "http://[::1]/image.png"} {
+		if _, _, err := FetchImage(ctx, nil, imageURL, DefaultImageLimits()); err == nil || !strings.Contains(err.Error(), "non-public address") {
+			t.Errorf("expected %s to be refused, got %v", imageURL, err)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+// Package mock provides a scripted provider that replays scenario files or
+// queued responses, for exercising conversation flows and unit-testing
+// gomini integrations without calling a real model
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// DefaultModel is the model reported when the scenario does not care
+const DefaultModel = "mock-model"
+
+// Config holds mock provider configuration. Scenario takes precedence over
+// ScenarioFile; with neither, requests are answered only by responses queued
+// with Enqueue.
+type Config struct {
+	Scenario     *Scenario `json:"-"`
+	ScenarioFile string    `json:"scenario_file,omitempty"`
+	DefaultModel string    `json:"default_model,omitempty"`
+
+	// Streaming behavior
+	Latency    time.Duration `json:"-"`                    // Wait before the first event of every response
+	ChunkSize  int           `json:"chunk_size,omitempty"` // Split content and thought text into chunks of this many characters
+	ChunkDelay time.Duration `json:"-"`                    // Wait between chunks
+}
+
+// Provider implements providers.LLMProvider by replaying a Scenario
+type Provider struct {
+	scenario     *Scenario
+	defaultModel string
+	latency      time.Duration
+	chunkSize    int
+	chunkDelay   time.Duration
+	requests     atomic.Int64
+
+	mu          sync.Mutex
+	queued      [][]Step
+	injected    []error
+	lastRequest *providers.ChatRequest
+}
+
+// NewProvider creates a mock provider from a scenario or scenario file
+func NewProvider(config *Config) (*Provider, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+
+	scenario := config.Scenario
+	if scenario == nil && config.ScenarioFile != "" {
+		loaded, err := LoadScenario(config.ScenarioFile)
+		if err != nil {
+			return nil, err
+		}
+		scenario = loaded
+	} else if scenario == nil {
+		scenario = &Scenario{}
+	} else if err := scenario.compile(); err != nil {
+		return nil, err
+	}
+
+	defaultModel := config.DefaultModel
+	if defaultModel == "" {
+		defaultModel = DefaultModel
+	}
+	return &Provider{
+		scenario:     scenario,
+		defaultModel: defaultModel,
+		latency:      config.Latency,
+		chunkSize:    config.ChunkSize,
+		chunkDelay:   config.ChunkDelay,
+	}, nil
+}
+
+// Enqueue queues a scripted response. Queued responses answer requests in
+// order, ahead of the scenario rules.
+func (p *Provider) Enqueue(steps ...Step) error {
+	scripted := Scenario{Rules: []Rule{{Steps: steps}}}
+	if err := scripted.compile(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queued = append(p.queued, steps)
+	return nil
+}
+
+// InjectError makes the next request fail with err, after the configured
+// latency. Errors are returned as is, so typed errors such as
+// *gomini.LLMError reach the caller intact. Injected errors queue up and
+// take precedence over queued responses.
+func (p *Provider) InjectError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.injected = append(p.injected, err)
+}
+
+// LastRequest returns the most recent request, or nil before the first one
+func (p *Provider) LastRequest() *providers.ChatRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastRequest
+}
+
+// Requests returns how many requests the provider has answered
+func (p *Provider) Requests() int {
+	return int(p.requests.Load())
+}
+
+// SendMessage replays the matching rule and collects it into a response
+func (p *Provider) SendMessage(ctx context.Context, req *providers.ChatRequest) (*providers.ChatResponse, error) {
+	model := p.model(req.Model)
+
+	var text strings.Builder
+	var toolCalls []providers.ToolCall
+	var usage *providers.Usage
+	finishReason := providers.FinishReasonStop
+	for event := range p.SendMessageStream(ctx, req) {
+		switch event.Type {
+		case providers.EventContent:
+			text.WriteString(event.Data.(providers.ContentEvent).Text)
+		case providers.EventToolCall:
+			toolCalls = append(toolCalls, event.Data.(providers.ToolCall))
+		case providers.EventError:
+			return nil, event.Error
+		case providers.EventFinished:
+			finishReason = event.Metadata.FinishReason
+			usage = event.Metadata.Usage
+		}
+	}
+
+	message := map[string]interface{}{
+		"role":    "assistant",
+		"content": text.String(),
+	}
+	if len(toolCalls) > 0 {
+		message["tool_calls"] = toolCalls
+	}
+	return &providers.ChatResponse{
+		ID:       fmt.Sprintf("mock-%d", p.requests.Load()),
+		Model:    model,
+		Provider: providers.ProviderMock,
+		Choices:  []providers.Choice{{Message: message, FinishReason: finishReason}},
+		Usage:    usage,
+		Created:  time.Now().Unix(),
+	}, nil
+}
+
+// SendMessageStream replays an injected error, the next queued response or
+// the steps of the first matching rule, honoring latency and step delays. A
+// finished event is appended when the steps end without one.
+func (p *Provider) SendMessageStream(ctx context.Context, req *providers.ChatRequest) <-chan providers.StreamEvent {
+	resultChan := make(chan providers.StreamEvent, 10)
+	model := p.model(req.Model)
+	p.requests.Add(1)
+	steps, scripted, injected := p.next(req)
+
+	go func() {
+		defer close(resultChan)
+
+		fail := func(err error) {
+			resultChan <- providers.NewErrorEvent(providers.ProviderMock, model, err, false)
+		}
+
+		if err := sleep(ctx, p.latency); err != nil {
+			fail(err)
+			return
+		}
+		if injected != nil {
+			fail(injected)
+			return
+		}
+		if !scripted {
+			rule, ok := p.scenario.match(req)
+			if !ok {
+				fail(fmt.Errorf("no scenario rule matches prompt %q", lastUserText(req.Messages)))
+				return
+			}
+			steps = rule.Steps
+		}
+
+		for _, step := range steps {
+			if err := sleep(ctx, time.Duration(step.Delay)); err != nil {
+				fail(err)
+				return
+			}
+
+			for i, event := range p.stepEvents(step, model) {
+				if i > 0 {
+					if err := sleep(ctx, p.chunkDelay); err != nil {
+						fail(err)
+						return
+					}
+				}
+				select {
+				case resultChan <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if step.Type == StepError || step.Type == StepFinished {
+				return
+			}
+		}
+
+		resultChan <- stepEvent(Step{Type: StepFinished}, model)
+	}()
+
+	return resultChan
+}
+
+// next records the request and takes the next injected error or queued response
+func (p *Provider) next(req *providers.ChatRequest) ([]Step, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastRequest = req
+
+	if len(p.injected) > 0 {
+		err := p.injected[0]
+		p.injected = p.injected[1:]
+		return nil, true, err
+	}
+	if len(p.queued) > 0 {
+		steps := p.queued[0]
+		p.queued = p.queued[1:]
+		return steps, true, nil
+	}
+	return nil, false, nil
+}
+
+// stepEvents converts a step into its events, splitting text into chunks
+// when ChunkSize is set
+func (p *Provider) stepEvents(step Step, model string) []providers.StreamEvent {
+	if p.chunkSize <= 0 || (step.Type != StepContent && step.Type != StepThought) {
+		return []providers.StreamEvent{stepEvent(step, model)}
+	}
+	var events []providers.StreamEvent
+	for _, chunk := range chunkText(step.Text, p.chunkSize) {
+		chunked := step
+		chunked.Text = chunk
+		events = append(events, stepEvent(chunked, model))
+	}
+	return events
+}
+
+// chunkText splits text into chunks of at most size characters
+func chunkText(text string, size int) []string {
+	runes := []rune(text)
+	if len(runes) <= size {
+		return []string{text}
+	}
+	chunks := make([]string, 0, (len(runes)+size-1)/size)
+	for start := 0; start < len(runes); start += size {
+		end := start + size
+		if end > len(runes) {
+			end = len(runes)
+		}
+		chunks = append(chunks, string(runes[start:end]))
+	}
+	return chunks
+}
+
+// sleep waits for d, returning ctx's error if it is done first
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// stepEvent converts a scenario step into a stream event
+func stepEvent(step Step, model string) providers.StreamEvent {
+	event := providers.StreamEvent{
+		Provider:  providers.ProviderMock,
+		Model:     model,
+		Timestamp: time.Now(),
+	}
+	switch step.Type {
+	case StepContent:
+		event.Type = providers.EventContent
+		event.Data = providers.ContentEvent{Text: step.Text, Delta: true}
+	case StepThought:
+		event.Type = providers.EventThought
+		event.Data = providers.ThoughtEvent{Text: step.Text}
+	case StepToolCall:
+		call := *step.ToolCall
+		if call.Arguments == nil {
+			call.Arguments = map[string]interface{}{}
+		}
+		event.Type = providers.EventToolCall
+		event.Data = call
+	case StepError:
+		event.Type = providers.EventError
+		event.Error = errors.New(step.Error)
+	case StepFinished:
+		finishReason := step.FinishReason
+		if finishReason == "" {
+			finishReason = providers.FinishReasonStop
+		}
+		event.Type = providers.EventFinished
+		event.Metadata = providers.EventMeta{FinishReason: finishReason, Usage: step.Usage}
+	}
+	return event
+}
+
+// GenerateJSON replays the matching rule and parses its content as JSON
+func (p *Provider) GenerateJSON(ctx context.Context, req *providers.JSONRequest) (*providers.JSONResponse, error) {
+	resp, err := p.SendMessage(ctx, &providers.ChatRequest{
+		Messages: req.Messages,
+		Model:    req.Model,
+		Config:   req.Config,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content := resp.Choices[0].Content()
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	return &providers.JSONResponse{
+		ID:       resp.ID,
+		Model:    resp.Model,
+		Provider: providers.ProviderMock,
+		Data:     data,
+		Usage:    resp.Usage,
+		Created:  resp.Created,
+	}, nil
+}
+
+// ListModels returns the single mock model
+func (p *Provider) ListModels(ctx context.Context) ([]providers.Model, error) {
+	return []providers.Model{{
+		ID:       p.defaultModel,
+		Name:     "Mock Model",
+		Provider: providers.ProviderMock,
+		Capabilities: providers.ModelCapabilities{
+			TextGeneration:  true,
+			FunctionCalling: true,
+			JSONMode:        true,
+			SystemMessage:   true,
+			Streaming:       true,
+			ThinkingMode:    true,
+			Reasoning:       true,
+		},
+		ContextSize: 128000,
+	}}, nil
+}
+
+// GetCapabilities returns the mock provider capabilities
+func (p *Provider) GetCapabilities() providers.ProviderCapabilities {
+	return providers.ProviderCapabilities{
+		Models:            []string{p.defaultModel},
+		MaxContextSize:    128000,
+		SupportsStreaming: true,
+		SupportsFunctions: true,
+		SupportsJSONMode:  true,
+	}
+}
+
+// GetProviderType returns the provider type
+func (p *Provider) GetProviderType() providers.ProviderType {
+	return providers.ProviderMock
+}
+
+// Close is a no-op
+func (p *Provider) Close() error {
+	return nil
+}
+
+// model returns the requested model or the default
+func (p *Provider) model(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return p.defaultModel
+}
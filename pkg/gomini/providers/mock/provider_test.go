@@ -0,0 +1,243 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+)
+
+func newWeatherProvider(t *testing.T) *Provider {
+	t.Helper()
+	provider, err := NewProvider(&Config{ScenarioFile: "testdata/weather.json"})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	return provider
+}
+
+func userRequest(model, text string) *providers.ChatRequest {
+	return &providers.ChatRequest{
+		Model:    model,
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": text}},
+	}
+}
+
+func TestProvider_SendMessageStream(t *testing.T) {
+	provider := newWeatherProvider(t)
+
+	tests := []struct {
+		name   string
+		model  string
+		prompt string
+		want   []providers.EventType
+	}{
+		{"tool call rule", "", "What's the weather?", []providers.EventType{providers.EventThought, providers.EventContent, providers.EventToolCall, providers.EventFinished}},
+		{"finished appended", "", "JSON please", []providers.EventType{providers.EventContent, providers.EventContent, providers.EventFinished}},
+		{"error stops the stream", "", "simulate an OUTAGE", []providers.EventType{providers.EventContent, providers.EventError}},
+		{"catch-all rule", "", "hi", []providers.EventType{providers.EventContent, providers.EventFinished}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []providers.EventType
+			for event := range provider.SendMessageStream(context.Background(), userRequest(tt.model, tt.prompt)) {
+				got = append(got, event.Type)
+				if event.Provider != providers.ProviderMock {
+					t.Errorf("expected mock provider, got %s", event.Provider)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	if provider.Requests() != len(tests) {
+		t.Errorf("expected %d requests, got %d", len(tests), provider.Requests())
+	}
+}
+
+func TestProvider_SendMessage(t *testing.T) {
+	provider := newWeatherProvider(t)
+
+	resp, err := provider.SendMessage(context.Background(), userRequest("", "weather in Taipei"))
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	choice := resp.Choices[0]
+	if choice.Content() != "Let me check. " {
+		t.Errorf("unexpected content %q", choice.Content())
+	}
+	calls := choice.ToolCalls()
+	if len(calls) != 1 || calls[0].Name != "get_weather" || calls[0].Arguments["city"] != "Taipei" {
+		t.Errorf("unexpected tool calls %+v", calls)
+	}
+	if choice.FinishReason != providers.FinishReasonToolCalls || resp.Usage == nil || resp.Usage.TotalTokens != 20 {
+		t.Errorf("unexpected finish %v / usage %+v", choice.FinishReason, resp.Usage)
+	}
+
+	if _, err := provider.SendMessage(context.Background(), userRequest("", "outage")); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("expected scripted 503 error, got %v", err)
+	}
+
+	data, err := provider.GenerateJSON(context.Background(), &providers.JSONRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "json forecast"}},
+	})
+	if err != nil {
+		t.Fatalf("GenerateJSON failed: %v", err)
+	}
+	if data.Data["city"] != "Taipei" || data.Data["temp"] != float64(30) {
+		t.Errorf("unexpected JSON data %+v", data.Data)
+	}
+}
+
+func TestProvider_DelayHonorsContext(t *testing.T) {
+	provider := newWeatherProvider(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var last providers.StreamEvent
+	for event := range provider.SendMessageStream(ctx, userRequest("slow-model", "hello")) {
+		last = event
+	}
+	if last.Type != providers.EventError || last.Error != context.DeadlineExceeded {
+		t.Errorf("expected deadline error, got %+v", last)
+	}
+}
+
+func TestLoadScenario_YAML(t *testing.T) {
+	fromJSON, err := LoadScenario("testdata/weather.json")
+	if err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	fromYAML, err := LoadScenario("testdata/weather.yaml")
+	if err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+	want, _ := json.Marshal(fromJSON)
+	got, _ := json.Marshal(fromYAML)
+	if string(got) != string(want) {
+		t.Errorf("expected the YAML scenario to match the JSON one:\n%s\n%s", got, want)
+	}
+
+	invalid := filepath.Join(t.TempDir(), "invalid.yml")
+	if err := os.WriteFile(invalid, []byte("rules: [unclosed"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadScenario(invalid); err == nil {
+		t.Error("expected a YAML parse error")
+	}
+}
+
+func TestNewProvider_InvalidScenario(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario *Scenario
+	}{
+		{"bad regex", &Scenario{Rules: []Rule{{Match: Matcher{Regex: "("}}}}},
+		{"unknown step", &Scenario{Rules: []Rule{{Steps: []Step{{Type: "dance"}}}}}},
+		{"tool call without name", &Scenario{Rules: []Rule{{Steps: []Step{{Type: StepToolCall}}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewProvider(&Config{Scenario: tt.scenario}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	provider, err := NewProvider(&Config{Scenario: &Scenario{Rules: []Rule{{Match: Matcher{Contains: "only this"}}}}})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	for event := range provider.SendMessageStream(context.Background(), userRequest("", "something else")) {
+		if event.Type != providers.EventError {
+			t.Errorf("expected a no-match error, got %s", event.Type)
+		}
+	}
+}
+
+func TestProvider_EnqueueAndInjectError(t *testing.T) {
+	provider, err := NewProvider(&Config{})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	if err := provider.Enqueue(Step{Type: StepContent, Text: "first"}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := provider.Enqueue(Step{Type: StepContent, Text: "second"}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if err := provider.Enqueue(Step{Type: "dance"}); err == nil {
+		t.Error("expected an invalid step to be rejected")
+	}
+	errQuota := errors.New("quota exceeded")
+	provider.InjectError(errQuota)
+
+	if _, err := provider.SendMessage(context.Background(), userRequest("", "hello")); !errors.Is(err, errQuota) {
+		t.Errorf("expected the injected error, got %v", err)
+	}
+	for _, want := range []string{"first", "second"} {
+		resp, err := provider.SendMessage(context.Background(), userRequest("", "hello"))
+		if err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+		if content := resp.Choices[0].Content(); content != want {
+			t.Errorf("expected %q, got %q", want, content)
+		}
+	}
+	if provider.LastRequest() == nil || lastUserText(provider.LastRequest().Messages) != "hello" {
+		t.Errorf("unexpected last request %+v", provider.LastRequest())
+	}
+
+	// With the queue empty and no scenario, nothing matches
+	if _, err := provider.SendMessage(context.Background(), userRequest("", "hello")); err == nil {
+		t.Error("expected a no-match error")
+	}
+}
+
+func TestProvider_Chunking(t *testing.T) {
+	provider, err := NewProvider(&Config{ChunkSize: 4, ChunkDelay: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	provider.Enqueue(Step{Type: StepContent, Text: "Hello, 世界!"})
+
+	var chunks []string
+	for event := range provider.SendMessageStream(context.Background(), userRequest("", "hi")) {
+		if event.Type == providers.EventContent {
+			chunks = append(chunks, event.Data.(providers.ContentEvent).Text)
+		}
+	}
+	want := []string{"Hell", "o, 世", "界!"}
+	if strings.Join(chunks, "|") != strings.Join(want, "|") {
+		t.Errorf("expected chunks %q, got %q", want, chunks)
+	}
+}
+
+func TestProvider_LatencyHonorsContext(t *testing.T) {
+	provider, err := NewProvider(&Config{Latency: time.Second})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	provider.Enqueue(Step{Type: StepContent, Text: "too late"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := provider.SendMessage(ctx, userRequest("", "hi")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+	"gopkg.in/yaml.v3"
+)
+
+// Step types a scenario can emit
+const (
+	StepContent  = "content"
+	StepThought  = "thought"
+	StepToolCall = "tool_call"
+	StepError    = "error"
+	StepFinished = "finished"
+)
+
+// Scenario scripts the mock provider. Each request is answered by the first
+// rule whose matcher accepts it.
+type Scenario struct {
+	Name  string `json:"name,omitempty"`
+	Rules []Rule `json:"rules"`
+}
+
+// Rule replays Steps for requests accepted by Match
+type Rule struct {
+	Match Matcher `json:"match,omitempty"`
+	Steps []Step  `json:"steps"`
+}
+
+// Matcher selects requests by the text of their last user message and by
+// model. Empty fields match anything.
+type Matcher struct {
+	Contains string `json:"contains,omitempty"`
+	Regex    string `json:"regex,omitempty"`
+	Model    string `json:"model,omitempty"`
+
+	regex *regexp.Regexp
+}
+
+// Step is one scripted stream event, emitted after Delay
+type Step struct {
+	Type         string                 `json:"type"`
+	Text         string                 `json:"text,omitempty"`      // content and thought steps
+	ToolCall     *providers.ToolCall    `json:"tool_call,omitempty"` // tool_call steps
+	Error        string                 `json:"error,omitempty"`     // error steps
+	FinishReason providers.FinishReason `json:"finish_reason,omitempty"`
+	Usage        *providers.Usage       `json:"usage,omitempty"`
+	Delay        Duration               `json:"delay,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "250ms" in scenario files
+type Duration time.Duration
+
+// UnmarshalJSON accepts duration strings and plain nanosecond counts
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		var nanos int64
+		if err := json.Unmarshal(data, &nanos); err != nil {
+			return fmt.Errorf("invalid duration %s", data)
+		}
+		*d = Duration(nanos)
+		return nil
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// LoadScenario reads a scenario file. Files ending in .yaml or .yml are YAML
+// with the same fields as JSON; anything else is parsed as JSON.
+func LoadScenario(path string) (*Scenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if raw, err = yamlToJSON(raw); err != nil {
+			return nil, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+		}
+	}
+	var scenario Scenario
+	if err := json.Unmarshal(raw, &scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+	if err := scenario.compile(); err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", path, err)
+	}
+	return &scenario, nil
+}
+
+// yamlToJSON re-encodes a YAML document as JSON, so YAML scenarios share the
+// JSON field names, durations and validation
+func yamlToJSON(raw []byte) ([]byte, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(raw, &document); err != nil {
+		return nil, err
+	}
+	return json.Marshal(document)
+}
+
+// compile validates the rules and prepares their matchers
+func (s *Scenario) compile() error {
+	for i := range s.Rules {
+		rule := &s.Rules[i]
+		if rule.Match.Regex != "" {
+			regex, err := regexp.Compile(rule.Match.Regex)
+			if err != nil {
+				return fmt.Errorf("rule %d: invalid regex: %w", i, err)
+			}
+			rule.Match.regex = regex
+		}
+		for j, step := range rule.Steps {
+			switch step.Type {
+			case StepContent, StepThought, StepError, StepFinished:
+			case StepToolCall:
+				if step.ToolCall == nil || step.ToolCall.Name == "" {
+					return fmt.Errorf("rule %d step %d: tool_call step needs a tool_call with a name", i, j)
+				}
+			default:
+				return fmt.Errorf("rule %d step %d: unknown step type %q", i, j, step.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// match returns the first rule accepting the request
+func (s *Scenario) match(req *providers.ChatRequest) (*Rule, bool) {
+	prompt := lastUserText(req.Messages)
+	for i := range s.Rules {
+		if s.Rules[i].Match.accepts(prompt, req.Model) {
+			return &s.Rules[i], true
+		}
+	}
+	return nil, false
+}
+
+func (m *Matcher) accepts(prompt, model string) bool {
+	if m.Model != "" && m.Model != model {
+		return false
+	}
+	if m.Contains != "" && !strings.Contains(prompt, m.Contains) {
+		return false
+	}
+	return m.regex == nil || m.regex.MatchString(prompt)
+}
+
+// lastUserText returns the text of the most recent user message
+func lastUserText(messages []providers.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg, ok := messages[i].(map[string]interface{})
+		if !ok || msg["role"] != "user" {
+			continue
+		}
+		switch content := msg["content"].(type) {
+		case string:
+			return content
+		case []interface{}:
+			var texts []string
+			for _, item := range content {
+				part, ok := item.(map[string]interface{})
+				if !ok || part["type"] != "text" {
+					continue
+				}
+				if data, ok := part["data"].(map[string]interface{}); ok {
+					if text, ok := data["text"].(string); ok {
+						texts = append(texts, text)
+					}
+				}
+			}
+			return strings.Join(texts, "\n")
+		}
+		return ""
+	}
+	return ""
+}
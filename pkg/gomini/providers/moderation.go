@@ -0,0 +1,18 @@
+package providers
+
+import "context"
+
+// Moderator is implemented by providers with a content moderation endpoint
+type Moderator interface {
+	// Moderate classifies text against the provider's content policy
+	Moderate(ctx context.Context, text string) (*ModerationResult, error)
+}
+
+// ModerationResult is the verdict of a moderation check
+type ModerationResult struct {
+	Flagged    bool               `json:"flagged"`
+	Categories []string           `json:"categories,omitempty"` // Flagged categories, e.g. "violence" or "self-harm"
+	Scores     map[string]float64 `json:"scores,omitempty"`     // Per-category scores from 0 to 1, where reported
+	Model      string             `json:"model,omitempty"`
+	Provider   ProviderType       `json:"provider,omitempty"` // Empty for the built-in heuristic
+}
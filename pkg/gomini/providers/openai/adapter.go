@@ -1,8 +1,11 @@
 package openai
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/openai/openai-go"
@@ -11,6 +14,11 @@ import (
 
 // adaptChatRequest converts unified ChatRequest to OpenAI ChatCompletionNewParams
 func (p *Provider) adaptChatRequest(req *providers.ChatRequest) (*openai.ChatCompletionNewParams, error) {
+	if providers.HasAudioInput(req.Messages) && !p.supportsAudioInput(req.Model) {
+		return nil, providers.NewLLMError(providers.ErrorUnsupportedFeature,
+			fmt.Sprintf("model %s does not accept audio input", req.Model), p.providerType(), nil)
+	}
+
 	// Convert messages
 	openaiMessages := make([]openai.ChatCompletionMessageParamUnion, 0, len(req.Messages))
 	
@@ -40,20 +48,48 @@ func (p *Provider) adaptChatRequest(req *providers.ChatRequest) (*openai.ChatCom
 			return nil, fmt.Errorf("failed to adapt tools: %w", err)
 		}
 		params.Tools = openai.F(tools)
-		
-		if req.ToolChoice != nil {
-			toolChoice, err := p.adaptToolChoice(req.ToolChoice)
-			if err != nil {
-				return nil, fmt.Errorf("failed to adapt tool choice: %w", err)
-			}
-			// params.ToolChoice = toolChoice // Need type assertion
-			_ = toolChoice // Avoid unused variable error
+
+		if parallel, ok := providers.ParallelToolCallsFromConfig(req.Config); ok {
+			params.ParallelToolCalls = openai.F(parallel)
 		}
 	}
 
+	// OpenAI only accepts tool_choice alongside tools
+	toolChoice, err := providers.ResolveToolChoice(req.ToolChoice, req.Tools)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tool choice: %w", err)
+	}
+	if len(req.Tools) > 0 && toolChoice.Mode != "" {
+		params.ToolChoice = openai.F(adaptToolChoice(toolChoice))
+	}
+
+	// Forward tags so usage can be sliced in the OpenAI dashboard
+	if len(req.Tags) > 0 {
+		params.Metadata = openai.F(adaptMetadata(req.Tags))
+	}
+
 	return params, nil
 }
 
+// adaptMetadata converts tags to OpenAI request metadata, which allows at most
+// 16 pairs with keys up to 64 and values up to 512 characters
+func adaptMetadata(tags map[string]string) map[string]string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	metadata := make(map[string]string, len(tags))
+	for _, key := range keys {
+		if len(metadata) >= 16 {
+			break
+		}
+		metadata[providers.TruncateRunes(key, 64)] = providers.TruncateRunes(tags[key], 512)
+	}
+	return metadata
+}
+
 // adaptChatRequestForStream converts unified ChatRequest to streaming OpenAI request
 func (p *Provider) adaptChatRequestForStream(req *providers.ChatRequest) (*openai.ChatCompletionNewParams, error) {
 	params, err := p.adaptChatRequest(req)
@@ -74,16 +110,38 @@ func (p *Provider) adaptJSONRequest(req *providers.ChatRequest, schema map[strin
 		return nil, err
 	}
 
-	// Configure for JSON response mode
+	// Prefer server-side schema enforcement when the model supports it
+	if schema != nil && p.supportsStructuredOutput(req.Model) {
+		params.ResponseFormat = openai.F[openai.ChatCompletionNewParamsResponseFormatUnion](
+			openai.ResponseFormatJSONSchemaParam{
+				Type: openai.F(openai.ResponseFormatJSONSchemaTypeJSONSchema),
+				JSONSchema: openai.F(openai.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   openai.F(schemaName(schema)),
+					Schema: openai.F[interface{}](schema),
+					Strict: openai.F(isStrictSchema(schema)),
+				}),
+			},
+		)
+		return params, nil
+	}
+
+	// Fall back to JSON mode with the schema described in a system message
 	params.ResponseFormat = openai.F[openai.ChatCompletionNewParamsResponseFormatUnion](
 		openai.ResponseFormatJSONObjectParam{
 			Type: openai.F(openai.ResponseFormatJSONObjectTypeJSONObject),
 		},
 	)
 
-	// Also add a system message to ensure JSON output
-	systemMsg := openai.SystemMessage("You must respond with valid JSON that matches the provided schema. Do not include any other text or formatting.")
-	
+	instruction := "You must respond with valid JSON that matches the provided schema. Do not include any other text or formatting."
+	if schema != nil {
+		schemaJSON, err := json.Marshal(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schema: %w", err)
+		}
+		instruction = fmt.Sprintf("%s\nSchema: %s", instruction, string(schemaJSON))
+	}
+	systemMsg := openai.SystemMessage(instruction)
+
 	// Prepend the system message to existing messages
 	existingMessages := params.Messages.Value
 	allMessages := append([]openai.ChatCompletionMessageParamUnion{systemMsg}, existingMessages...)
@@ -92,6 +150,102 @@ func (p *Provider) adaptJSONRequest(req *providers.ChatRequest, schema map[strin
 	return params, nil
 }
 
+// supportsStructuredOutput reports whether the model accepts the json_schema response format
+func (p *Provider) supportsStructuredOutput(model string) bool {
+	return p.modelCapabilities(model).StructuredOutput
+}
+
+// supportsAudioInput reports whether the model accepts input_audio content parts
+func (p *Provider) supportsAudioInput(model string) bool {
+	return p.modelCapabilities(model).AudioInput
+}
+
+// supportsSeed reports whether the backend accepts a sampling seed; DeepSeek
+// rejects it
+func (p *Provider) supportsSeed() bool {
+	return p.providerType() != providers.ProviderDeepSeek
+}
+
+// schemaName derives a json_schema name from the schema title
+func schemaName(schema map[string]interface{}) string {
+	title, _ := schema["title"].(string)
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		case r == ' ':
+			return '_'
+		}
+		return -1
+	}, title)
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	if name == "" {
+		return "response"
+	}
+	return name
+}
+
+// isStrictSchema reports whether a schema satisfies OpenAI's strict mode subset:
+// every object must disallow additional properties and require all of its properties.
+func isStrictSchema(schema map[string]interface{}) bool {
+	if properties, ok := schema["properties"].(map[string]interface{}); ok || schema["type"] == "object" {
+		if additional, ok := schema["additionalProperties"].(bool); !ok || additional {
+			return false
+		}
+
+		required := make(map[string]bool)
+		switch r := schema["required"].(type) {
+		case []interface{}:
+			for _, name := range r {
+				if s, ok := name.(string); ok {
+					required[s] = true
+				}
+			}
+		case []string:
+			for _, name := range r {
+				required[name] = true
+			}
+		}
+
+		for name, property := range properties {
+			if !required[name] {
+				return false
+			}
+			if sub, ok := property.(map[string]interface{}); ok && !isStrictSchema(sub) {
+				return false
+			}
+		}
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok && !isStrictSchema(items) {
+		return false
+	}
+
+	for _, key := range []string{"anyOf", "oneOf", "allOf"} {
+		if options, ok := schema[key].([]interface{}); ok {
+			for _, option := range options {
+				if sub, ok := option.(map[string]interface{}); ok && !isStrictSchema(sub) {
+					return false
+				}
+			}
+		}
+	}
+
+	for _, key := range []string{"$defs", "definitions"} {
+		if defs, ok := schema[key].(map[string]interface{}); ok {
+			for _, def := range defs {
+				if sub, ok := def.(map[string]interface{}); ok && !isStrictSchema(sub) {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
 // adaptMessage converts unified Message to OpenAI message format
 func (p *Provider) adaptMessage(msg providers.Message) (openai.ChatCompletionMessageParamUnion, error) {
 	// This is a simplified version - in reality, we'd need to handle the actual Message type
@@ -101,16 +255,25 @@ func (p *Provider) adaptMessage(msg providers.Message) (openai.ChatCompletionMes
 	// For demonstration purposes:
 	switch msgType := msg.(type) {
 	case map[string]interface{}:
-		role := msgType["role"].(string)
+		role, _ := msgType["role"].(string)
 		content := msgType["content"]
 		
 		switch role {
 		case "system":
-			return openai.SystemMessage(content.(string)), nil
+			return openai.SystemMessage(textContent(content)), nil
 		case "user":
-			return openai.UserMessage(content.(string)), nil
+			if parts, ok := content.([]interface{}); ok {
+				return p.adaptUserParts(parts)
+			}
+			return openai.UserMessage(textContent(content)), nil
 		case "assistant":
-			return openai.AssistantMessage(content.(string)), nil
+			return adaptAssistantMessage(msgType)
+		case "tool":
+			callID, _ := msgType["tool_call_id"].(string)
+			if callID == "" {
+				return nil, fmt.Errorf("tool message has no tool_call_id")
+			}
+			return openai.ToolMessage(callID, textContent(content)), nil
 		default:
 			return nil, fmt.Errorf("unsupported message role: %s", role)
 		}
@@ -119,6 +282,212 @@ func (p *Provider) adaptMessage(msg providers.Message) (openai.ChatCompletionMes
 	}
 }
 
+// adaptAssistantMessage converts an assistant message, including the tool
+// calls it made, so a following tool message can answer them
+func adaptAssistantMessage(msg map[string]interface{}) (openai.ChatCompletionMessageParamUnion, error) {
+	toolCalls, err := providers.MessageToolCalls(msg)
+	if err != nil {
+		return nil, err
+	}
+	text := textContent(msg["content"])
+	if len(toolCalls) == 0 {
+		return openai.AssistantMessage(text), nil
+	}
+
+	message := openai.ChatCompletionAssistantMessageParam{
+		Role: openai.F(openai.ChatCompletionAssistantMessageParamRoleAssistant),
+	}
+	if text != "" {
+		message.Content = openai.F([]openai.ChatCompletionAssistantMessageParamContentUnion{openai.TextPart(text)})
+	}
+	calls := make([]openai.ChatCompletionMessageToolCallParam, 0, len(toolCalls))
+	for _, call := range toolCalls {
+		args := call.Arguments
+		if args == nil {
+			args = map[string]interface{}{}
+		}
+		arguments, err := json.Marshal(args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal arguments of tool call %s: %w", call.ID, err)
+		}
+		calls = append(calls, openai.ChatCompletionMessageToolCallParam{
+			ID:   openai.F(call.ID),
+			Type: openai.F(openai.ChatCompletionMessageToolCallTypeFunction),
+			Function: openai.F(openai.ChatCompletionMessageToolCallFunctionParam{
+				Name:      openai.F(call.Name),
+				Arguments: openai.F(string(arguments)),
+			}),
+		})
+	}
+	message.ToolCalls = openai.F(calls)
+	return message, nil
+}
+
+// adaptUserParts converts multi-part user content (text and images) to an OpenAI message
+func (p *Provider) adaptUserParts(items []interface{}) (openai.ChatCompletionMessageParamUnion, error) {
+	parts := make([]openai.ChatCompletionContentPartUnionParam, 0, len(items))
+
+	for i, item := range items {
+		part, err := adaptContentPart(item)
+		if err != nil {
+			return nil, fmt.Errorf("content part %d: %w", i, err)
+		}
+		parts = append(parts, part)
+	}
+
+	return openai.UserMessageParts(parts...), nil
+}
+
+// adaptContentPart converts a unified content part to an OpenAI content part union
+func adaptContentPart(item interface{}) (openai.ChatCompletionContentPartUnionParam, error) {
+	if audio, isAudio, err := providers.AsAudioPart(item); isAudio {
+		if err != nil {
+			return nil, err
+		}
+		return adaptAudioPart(audio)
+	}
+
+	if doc, isDocument, err := providers.AsDocumentPart(item); isDocument {
+		if err != nil {
+			return nil, err
+		}
+		return adaptDocumentPart(doc)
+	}
+
+	switch part := item.(type) {
+	case string:
+		return openai.TextPart(part), nil
+	case map[string]interface{}:
+		data, _ := part["data"].(map[string]interface{})
+		if part["type"] == "image_url" {
+			data = providers.ImagePartData(part)
+		}
+
+		switch part["type"] {
+		case "text":
+			text, ok := data["text"].(string)
+			if !ok {
+				return nil, fmt.Errorf("text part has no text")
+			}
+			return openai.TextPart(text), nil
+		case "image_url":
+			imageURL, err := adaptImageURL(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to adapt image part: %w", err)
+			}
+			imagePart := openai.ImagePart(imageURL)
+			if detail, ok := data["detail"].(string); ok && detail != "" {
+				switch openai.ChatCompletionContentPartImageImageURLDetail(detail) {
+				case openai.ChatCompletionContentPartImageImageURLDetailAuto,
+					openai.ChatCompletionContentPartImageImageURLDetailLow,
+					openai.ChatCompletionContentPartImageImageURLDetailHigh:
+				default:
+					return nil, fmt.Errorf("unsupported image detail: %s", detail)
+				}
+				imagePart.ImageURL = openai.F(openai.ChatCompletionContentPartImageImageURLParam{
+					URL:    openai.F(imageURL),
+					Detail: openai.F(openai.ChatCompletionContentPartImageImageURLDetail(detail)),
+				})
+			}
+			return imagePart, nil
+		default:
+			return nil, fmt.Errorf("unsupported content part type: %v", part["type"])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content part: %T", item)
+	}
+}
+
+// adaptAudioPart converts inline audio to an input_audio part; OpenAI accepts wav and mp3
+func adaptAudioPart(audio providers.AudioPart) (openai.ChatCompletionContentPartUnionParam, error) {
+	var format openai.ChatCompletionContentPartInputAudioInputAudioFormat
+	switch providers.NormalizeAudioMIMEType(audio.MIMEType) {
+	case "audio/wav":
+		format = openai.ChatCompletionContentPartInputAudioInputAudioFormatWAV
+	case "audio/mpeg":
+		format = openai.ChatCompletionContentPartInputAudioInputAudioFormatMP3
+	default:
+		return nil, fmt.Errorf("unsupported audio format %q, expected wav or mp3", audio.MIMEType)
+	}
+
+	return openai.ChatCompletionContentPartInputAudioParam{
+		Type: openai.F(openai.ChatCompletionContentPartInputAudioTypeInputAudio),
+		InputAudio: openai.F(openai.ChatCompletionContentPartInputAudioInputAudioParam{
+			Data:   openai.F(base64.StdEncoding.EncodeToString(audio.Data)),
+			Format: openai.F(format),
+		}),
+	}, nil
+}
+
+// adaptDocumentPart sends text documents as text parts; Chat Completions has
+// no content part for binary documents such as PDFs
+func adaptDocumentPart(doc providers.DocumentPart) (openai.ChatCompletionContentPartUnionParam, error) {
+	if !doc.IsText() || doc.URI != "" {
+		return nil, providers.NewLLMError(providers.ErrorUnsupportedFeature,
+			fmt.Sprintf("document type %s is not supported by OpenAI chat completions", doc.MIMEType), providers.ProviderOpenAI, nil)
+	}
+
+	text := string(doc.Data)
+	if doc.Name != "" {
+		text = fmt.Sprintf("%s:\n%s", doc.Name, text)
+	}
+	return openai.TextPart(text), nil
+}
+
+// adaptImageURL returns a URL OpenAI can read: remote URLs pass through and
+// inline base64 data is validated and sent as a data URL
+func adaptImageURL(data map[string]interface{}) (string, error) {
+	mimeType, _ := data["mime_type"].(string)
+
+	if base64Data, ok := data["base64"].(string); ok && base64Data != "" {
+		mimeType, imageData, err := providers.DecodeBase64Image(base64Data, mimeType)
+		if err != nil {
+			return "", err
+		}
+		if mimeType, err = providers.CheckImage(mimeType, imageData, providers.DefaultImageLimits()); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(imageData)), nil
+	}
+
+	if imageURL, ok := data["url"].(string); ok && imageURL != "" {
+		if !strings.HasPrefix(imageURL, "data:") {
+			return imageURL, nil
+		}
+		// Re-encode so URL-safe, unpadded or percent-encoded payloads reach the API as standard base64
+		mimeType, imageData, err := providers.DecodeImageDataURL(imageURL, mimeType)
+		if err != nil {
+			return "", err
+		}
+		if mimeType, err = providers.CheckImage(mimeType, imageData, providers.DefaultImageLimits()); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(imageData)), nil
+	}
+	return "", fmt.Errorf("invalid image data")
+}
+
+// textContent flattens string or multi-part content to text
+func textContent(content interface{}) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []interface{}:
+		var texts []string
+		for _, item := range c {
+			if itemMap, ok := item.(map[string]interface{}); ok && itemMap["type"] == "text" {
+				if data, ok := itemMap["data"].(map[string]interface{}); ok {
+					if text, ok := data["text"].(string); ok {
+						texts = append(texts, text)
+					}
+				}
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
+
 // adaptChatResponse converts OpenAI ChatCompletion to unified ChatResponse
 func (p *Provider) adaptChatResponse(resp openai.ChatCompletion, model string) *providers.ChatResponse {
 	choices := make([]providers.Choice, len(resp.Choices))
@@ -127,44 +496,221 @@ func (p *Provider) adaptChatResponse(resp openai.ChatCompletion, model string) *
 		choices[i] = p.adaptChoice(choice)
 	}
 
-	var usage *providers.Usage
-	// Check if usage data is available (Usage is not a pointer in this SDK version)
-	usage = &providers.Usage{
-			InputTokens:      int(resp.Usage.PromptTokens),
-			OutputTokens:     int(resp.Usage.CompletionTokens),
-			TotalTokens:      int(resp.Usage.TotalTokens),
-			PromptTokens:     int(resp.Usage.PromptTokens),
-			CompletionTokens: int(resp.Usage.CompletionTokens),
-		}
+	usage := adaptUsage(resp.Usage)
 
 	return &providers.ChatResponse{
 		ID:       resp.ID,
 		Model:    model,
-		Provider: providers.ProviderOpenAI,
+		Provider: p.providerType(),
 		Choices:  choices,
 		Usage:    usage,
 		Created:  resp.Created,
+		SystemFingerprint: resp.SystemFingerprint,
+	}
+}
+
+// adaptUsage converts OpenAI token usage to unified Usage
+func adaptUsage(usage openai.CompletionUsage) *providers.Usage {
+	return &providers.Usage{
+		InputTokens:       int(usage.PromptTokens),
+		OutputTokens:      int(usage.CompletionTokens),
+		TotalTokens:       int(usage.TotalTokens),
+		PromptTokens:      int(usage.PromptTokens),
+		CompletionTokens:  int(usage.CompletionTokens),
+		CachedInputTokens: int(usage.PromptTokensDetails.CachedTokens),
 	}
 }
 
+// streamUsage returns the usage carried by a chunk. With include_usage only
+// the final chunk has it; the others send null.
+func streamUsage(chunk openai.ChatCompletionChunk) *providers.Usage {
+	if chunk.JSON.Usage.IsNull() || chunk.JSON.Usage.IsMissing() {
+		return nil
+	}
+	return adaptUsage(chunk.Usage)
+}
+
 // adaptChoice converts OpenAI Choice to unified Choice
 func (p *Provider) adaptChoice(choice openai.ChatCompletionChoice) providers.Choice {
-	// This is a placeholder - would need proper Choice type definition
-	return map[string]interface{}{
-		"index":         choice.Index,
-		"message":       p.adaptAssistantMessage(choice.Message),
-		"finish_reason": p.adaptFinishReason(choice.FinishReason),
+	return providers.Choice{
+		Index:        int(choice.Index),
+		Message:      p.adaptAssistantMessage(choice.Message),
+		FinishReason: p.adaptFinishReason(choice.FinishReason),
+		Refusal:      choice.Message.Refusal,
+		Logprobs:     adaptLogprobs(choice.Logprobs.Content),
 	}
 }
 
+// adaptLogprobs converts OpenAI token log probabilities to the unified format
+func adaptLogprobs(logprobs []openai.ChatCompletionTokenLogprob) []providers.TokenLogprob {
+	if len(logprobs) == 0 {
+		return nil
+	}
+	adapted := make([]providers.TokenLogprob, len(logprobs))
+	for i, logprob := range logprobs {
+		adapted[i] = providers.TokenLogprob{
+			Token:   logprob.Token,
+			Logprob: logprob.Logprob,
+			Bytes:   adaptTokenBytes(logprob.Bytes),
+		}
+		for _, top := range logprob.TopLogprobs {
+			adapted[i].TopLogprobs = append(adapted[i].TopLogprobs, providers.TokenLogprob{
+				Token:   top.Token,
+				Logprob: top.Logprob,
+				Bytes:   adaptTokenBytes(top.Bytes),
+			})
+		}
+	}
+	return adapted
+}
+
+// adaptTokenBytes converts a token's UTF-8 bytes
+func adaptTokenBytes(bytes []int64) []int {
+	if len(bytes) == 0 {
+		return nil
+	}
+	adapted := make([]int, len(bytes))
+	for i, b := range bytes {
+		adapted[i] = int(b)
+	}
+	return adapted
+}
+
 // adaptAssistantMessage converts OpenAI assistant message to unified format
 func (p *Provider) adaptAssistantMessage(msg openai.ChatCompletionMessage) interface{} {
-	// Placeholder implementation
-	return map[string]interface{}{
+	message := map[string]interface{}{
 		"role":    "assistant",
 		"content": msg.Content,
-		// Handle tool calls, function calls, etc.
 	}
+
+	if len(msg.ToolCalls) > 0 {
+		message["tool_calls"] = adaptToolCalls(msg.ToolCalls)
+	}
+
+	if images := adaptImageOutputs(msg.JSON.ExtraFields["images"].Raw()); len(images) > 0 {
+		message["images"] = images
+	}
+
+	if reasoning := adaptReasoningContent(msg.JSON.ExtraFields["reasoning_content"].Raw()); reasoning != "" {
+		message["reasoning"] = reasoning
+	}
+
+	if citations := adaptAnnotations(msg.JSON.ExtraFields["annotations"].Raw(), msg.Content); len(citations) > 0 {
+		message["citations"] = citations
+	}
+
+	return message
+}
+
+// adaptReasoningContent decodes the raw "reasoning_content" string field
+func adaptReasoningContent(raw string) string {
+	if raw == "" || raw == "null" {
+		return ""
+	}
+
+	var reasoning string
+	if err := json.Unmarshal([]byte(raw), &reasoning); err != nil {
+		return ""
+	}
+	return reasoning
+}
+
+// adaptToolCalls converts OpenAI tool calls to unified ToolCalls. Arguments
+// that cannot be parsed are kept under "_raw" so they are not lost.
+func adaptToolCalls(toolCalls []openai.ChatCompletionMessageToolCall) []providers.ToolCall {
+	calls := make([]providers.ToolCall, 0, len(toolCalls))
+	for _, toolCall := range toolCalls {
+		args, err := providers.ParseToolArguments(toolCall.Function.Arguments)
+		if err != nil {
+			args = map[string]interface{}{"_raw": toolCall.Function.Arguments}
+		}
+		calls = append(calls, providers.ToolCall{
+			ID:        toolCall.ID,
+			Name:      toolCall.Function.Name,
+			Arguments: args,
+		})
+	}
+	return calls
+}
+
+// imageOutput mirrors the "images" extension returned by image-capable
+// OpenAI-compatible gateways: [{"type":"image_url","image_url":{"url":"data:..."}}]
+type imageOutput struct {
+	Type     string `json:"type"`
+	ImageURL struct {
+		URL string `json:"url"`
+	} `json:"image_url"`
+}
+
+// adaptImageOutputs converts the raw "images" field into unified image parts
+func adaptImageOutputs(raw string) []providers.ImagePart {
+	if raw == "" || raw == "null" {
+		return nil
+	}
+
+	var outputs []imageOutput
+	if err := json.Unmarshal([]byte(raw), &outputs); err != nil {
+		return nil
+	}
+
+	images := make([]providers.ImagePart, 0, len(outputs))
+	for _, output := range outputs {
+		url := output.ImageURL.URL
+		if url == "" {
+			continue
+		}
+
+		if mimeType, data, err := providers.ParseDataURL(url); err == nil {
+			images = append(images, providers.ImagePart{MIMEType: mimeType, Data: data})
+		} else {
+			images = append(images, providers.ImagePart{URL: url})
+		}
+	}
+	return images
+}
+
+// annotation mirrors the "annotations" returned by web search models:
+// [{"type":"url_citation","url_citation":{"url":"...","title":"...","start_index":0,"end_index":10}}]
+type annotation struct {
+	Type        string `json:"type"`
+	URLCitation struct {
+		URL        string `json:"url"`
+		Title      string `json:"title"`
+		StartIndex int    `json:"start_index"`
+		EndIndex   int    `json:"end_index"`
+	} `json:"url_citation"`
+}
+
+// adaptAnnotations converts the raw "annotations" field into citations, one
+// per URL citation. Spans index into content by character; their text is
+// filled in when content is known.
+func adaptAnnotations(raw, content string) []providers.Citation {
+	if raw == "" || raw == "null" {
+		return nil
+	}
+
+	var annotations []annotation
+	if err := json.Unmarshal([]byte(raw), &annotations); err != nil {
+		return nil
+	}
+
+	runes := []rune(content)
+	citations := make([]providers.Citation, 0, len(annotations))
+	for _, a := range annotations {
+		if a.Type != "url_citation" || a.URLCitation.URL == "" {
+			continue
+		}
+		span := providers.CitationSpan{StartIndex: a.URLCitation.StartIndex, EndIndex: a.URLCitation.EndIndex}
+		if span.StartIndex >= 0 && span.StartIndex <= span.EndIndex && span.EndIndex <= len(runes) {
+			span.Text = string(runes[span.StartIndex:span.EndIndex])
+		}
+		citations = append(citations, providers.Citation{
+			Title: a.URLCitation.Title,
+			URI:   a.URLCitation.URL,
+			Spans: []providers.CitationSpan{span},
+		})
+	}
+	return citations
 }
 
 // adaptFinishReason converts OpenAI finish reason to unified format
@@ -185,56 +731,108 @@ func (p *Provider) adaptFinishReason(reason openai.ChatCompletionChoicesFinishRe
 	}
 }
 
-// adaptStreamChunk converts OpenAI streaming chunk to unified StreamEvent
-func (p *Provider) adaptStreamChunk(chunk openai.ChatCompletionChunk, model string) *providers.StreamEvent {
+// adaptStreamChunk converts an OpenAI streaming chunk to unified StreamEvents.
+// Tool call fragments are accumulated in calls across chunks.
+func (p *Provider) adaptStreamChunk(chunk openai.ChatCompletionChunk, model string, calls *toolCallStream) []providers.StreamEvent {
 	if len(chunk.Choices) == 0 {
 		return nil
 	}
 
 	choice := chunk.Choices[0]
-	
+	var events []providers.StreamEvent
+
+	// Reasoning models on OpenAI-compatible APIs (e.g. DeepSeek) stream their
+	// thinking separately from the answer
+	if reasoning := adaptReasoningContent(choice.Delta.JSON.ExtraFields["reasoning_content"].Raw()); reasoning != "" {
+		events = append(events, providers.StreamEvent{
+			Type:      providers.EventThought,
+			Provider:  p.providerType(),
+			Model:     model,
+			Data:      providers.ThoughtEvent{Text: reasoning},
+			Timestamp: time.Now(),
+		})
+	}
+
 	// Handle content delta
 	if choice.Delta.Content != "" {
-		return &providers.StreamEvent{
+		events = append(events, providers.StreamEvent{
 			Type:     providers.EventContent,
-			Provider: providers.ProviderOpenAI,
+			Provider: p.providerType(),
 			Model:    model,
 			Data: providers.ContentEvent{
-				Text:  choice.Delta.Content,
-				Delta: true,
+				Text:     choice.Delta.Content,
+				Delta:    true,
+				Logprobs: adaptLogprobs(choice.Logprobs.Content),
 			},
 			Timestamp: time.Now(),
-		}
+		})
+	}
+
+	// Handle images emitted by image-capable models
+	for _, image := range adaptImageOutputs(choice.Delta.JSON.ExtraFields["images"].Raw()) {
+		events = append(events, providers.StreamEvent{
+			Type:      providers.EventImage,
+			Provider:  p.providerType(),
+			Model:     model,
+			Data:      providers.ImageEvent{Image: image},
+			Timestamp: time.Now(),
+		})
 	}
 
-	// Handle finish reason
+	// Web search models attach their sources to the delta
+	if citations := adaptAnnotations(choice.Delta.JSON.ExtraFields["annotations"].Raw(), ""); len(citations) > 0 {
+		events = append(events, providers.StreamEvent{
+			Type:      providers.EventCitation,
+			Provider:  p.providerType(),
+			Model:     model,
+			Data:      providers.CitationEvent{Sources: citations},
+			Timestamp: time.Now(),
+		})
+	}
+
+		// Tool call fragments are reported as deltas and assembled in calls
+	for _, delta := range calls.add(choice.Delta.ToolCalls) {
+		events = append(events, providers.StreamEvent{
+			Type:      providers.EventToolCallDelta,
+			Provider:  p.providerType(),
+			Model:     model,
+			Data:      delta,
+			Timestamp: time.Now(),
+		})
+	}
+
+	// Handle finish reason; the assembled tool calls are complete by now
 	if choice.FinishReason != "" {
+		events = append(events, p.toolCallEvents(calls.flush(), model)...)
 		finishReason := p.adaptFinishReason(openai.ChatCompletionChoicesFinishReason(choice.FinishReason))
-		return &providers.StreamEvent{
+		events = append(events, providers.StreamEvent{
 			Type:     providers.EventFinished,
-			Provider: providers.ProviderOpenAI,
+			Provider: p.providerType(),
 			Model:    model,
 			Metadata: providers.EventMeta{
-				FinishReason: finishReason,
+				FinishReason:      finishReason,
+				SystemFingerprint: chunk.SystemFingerprint,
 			},
 			Timestamp: time.Now(),
-		}
+		})
 	}
 
-	// Handle tool calls
-	if len(choice.Delta.ToolCalls) > 0 {
-		// Convert tool calls to events
-		// This would need more detailed implementation
-		return &providers.StreamEvent{
+	return events
+}
+
+// toolCallEvents converts assembled tool calls to EventToolCall events
+func (p *Provider) toolCallEvents(calls []providers.ToolCall, model string) []providers.StreamEvent {
+	events := make([]providers.StreamEvent, 0, len(calls))
+	for _, call := range calls {
+		events = append(events, providers.StreamEvent{
 			Type:      providers.EventToolCall,
-			Provider:  providers.ProviderOpenAI,
+			Provider:  p.providerType(),
 			Model:     model,
+			Data:      call,
 			Timestamp: time.Now(),
-			// Tool call data would go here
-		}
+		})
 	}
-
-	return nil
+	return events
 }
 
 // adaptJSONResponse converts OpenAI response to unified JSONResponse
@@ -251,74 +849,34 @@ func (p *Provider) adaptJSONResponse(resp openai.ChatCompletion, model string, s
 	// Extract JSON from markdown code blocks if present
 	jsonContent := p.extractJSONFromMarkdown(content)
 
-	// Parse JSON content
+	// Parse JSON content, repairing malformed output if needed
 	var jsonData map[string]interface{}
-	if err := json.Unmarshal([]byte(jsonContent), &jsonData); err != nil {
+	repairs, err := providers.UnmarshalJSONWithRepair(jsonContent, &jsonData)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
 	}
 
-	var usage *providers.Usage
-	// Check if usage data is available (Usage is not a pointer in this SDK version)
-	usage = &providers.Usage{
-			InputTokens:      int(resp.Usage.PromptTokens),
-			OutputTokens:     int(resp.Usage.CompletionTokens),
-			TotalTokens:      int(resp.Usage.TotalTokens),
-			PromptTokens:     int(resp.Usage.PromptTokens),
-			CompletionTokens: int(resp.Usage.CompletionTokens),
-		}
+	usage := adaptUsage(resp.Usage)
 
 	return &providers.JSONResponse{
 		ID:       resp.ID,
 		Model:    model,
-		Provider: providers.ProviderOpenAI,
+		Provider: p.providerType(),
 		Data:     jsonData,
 		Usage:    usage,
 		Created:  resp.Created,
+		Metadata: providers.RepairMetadata(repairs),
 	}, nil
 }
 
-// adaptModel converts OpenAI Model to unified Model
+// adaptModel converts an OpenAI Model to the unified Model. The listing
+// reports only IDs, so capabilities and limits come from the capability table.
 func (p *Provider) adaptModel(model openai.Model) providers.Model {
-	// Determine capabilities based on model ID
-	capabilities := providers.ModelCapabilities{
-		TextGeneration: true,
-		SystemMessage:  true,
-		Streaming:      true,
-	}
-
-	// Set capabilities based on model name
-	if contains(model.ID, "gpt-4") {
-		capabilities.FunctionCalling = true
-		capabilities.JSONMode = true
-		capabilities.StructuredOutput = true
-		
-		if contains(model.ID, "vision") || model.ID == "gpt-4o" || model.ID == "gpt-4o-mini" {
-			capabilities.ImageInput = true
-		}
-	} else if contains(model.ID, "gpt-3.5") {
-		capabilities.FunctionCalling = true
-		capabilities.JSONMode = true
-	}
-
-	// Estimate context size
-	contextSize := 4096 // Default
-	if contains(model.ID, "gpt-4o") {
-		contextSize = 128000
-	} else if contains(model.ID, "gpt-4-turbo") {
-		contextSize = 128000
-	} else if contains(model.ID, "16k") {
-		contextSize = 16384
-	} else if contains(model.ID, "32k") {
-		contextSize = 32768
-	}
-
-	return providers.Model{
-		ID:           model.ID,
-		Name:         model.ID, // OpenAI uses ID as name
-		Provider:     providers.ProviderOpenAI,
-		Capabilities: capabilities,
-		ContextSize:  contextSize,
-	}
+	return p.profile(providers.Model{
+		ID:       model.ID,
+		Name:     model.ID, // OpenAI uses ID as name
+		Provider: p.providerType(),
+	})
 }
 
 // Helper functions
@@ -337,19 +895,41 @@ func (p *Provider) applyRequestConfig(params *openai.ChatCompletionNewParams, co
 				params.TopP = openai.F(topPFloat)
 			}
 		}
-		
-		if maxTokens, exists := configMap["max_tokens"]; exists {
-			if maxTokensInt, ok := maxTokens.(int); ok {
-				params.MaxTokens = openai.F(int64(maxTokensInt))
+
+		if reasoning, ok := providers.ReasoningFromConfig(config); ok {
+			params.ReasoningEffort = openai.F(openai.ChatCompletionReasoningEffort(reasoning.EffortLevel()))
+		}
+
+		if seed, ok := providers.SeedFromConfig(config); ok {
+			if !p.supportsSeed() {
+				return providers.NewLLMError(providers.ErrorUnsupportedFeature,
+					fmt.Sprintf("%s does not support seed", p.providerType()), p.providerType(), nil)
 			}
+			params.Seed = openai.F(seed)
 		}
-		
-		if stop, exists := configMap["stop"]; exists {
-			if stopSlice, ok := stop.([]string); ok {
-				// params.Stop = openai.F(stopSlice) // May need different API
-				_ = stopSlice // Avoid unused variable error
+
+		if logprobs, top := providers.LogprobsFromConfig(config); logprobs {
+			params.Logprobs = openai.F(true)
+			if top > 0 {
+				params.TopLogprobs = openai.F(int64(top))
 			}
 		}
+		
+		if maxTokens, ok := providers.MaxOutputTokensFromConfig(config); ok {
+			params.MaxTokens = openai.F(int64(maxTokens))
+		}
+
+		if stop := providers.StopFromConfig(config); len(stop) > 0 {
+			params.Stop = openai.F[openai.ChatCompletionNewParamsStopUnion](openai.ChatCompletionNewParamsStopArray(stop))
+		}
+
+		if penalty, ok := providers.FrequencyPenaltyFromConfig(config); ok {
+			params.FrequencyPenalty = openai.F(penalty)
+		}
+
+		if penalty, ok := providers.PresencePenaltyFromConfig(config); ok {
+			params.PresencePenalty = openai.F(penalty)
+		}
 	}
 	
 	return nil
@@ -359,38 +939,39 @@ func (p *Provider) adaptTools(tools []providers.Tool) ([]openai.ChatCompletionTo
 	openaiTools := make([]openai.ChatCompletionToolParam, len(tools))
 	
 	for i, tool := range tools {
-		// Convert unified tool to OpenAI format
-		// This would need proper Tool type handling
-		_ = tool // Avoid unused variable
+		fn, err := providers.AsFunctionTool(tool)
+		if err != nil {
+			return nil, err
+		}
+
+		definition := openai.FunctionDefinitionParam{
+			Name: openai.F(fn.Name),
+		}
+		if fn.Description != "" {
+			definition.Description = openai.F(fn.Description)
+		}
+		if fn.Parameters != nil {
+			definition.Parameters = openai.F(openai.FunctionParameters(fn.Parameters))
+		}
+
 		openaiTools[i] = openai.ChatCompletionToolParam{
-			Type: openai.F(openai.ChatCompletionToolTypeFunction),
-			Function: openai.F(openai.FunctionDefinitionParam{
-				Name: openai.F("placeholder"), // Would extract from tool
-				// Add other function parameters
-			}),
+			Type:     openai.F(openai.ChatCompletionToolTypeFunction),
+			Function: openai.F(definition),
 		}
 	}
 	
 	return openaiTools, nil
 }
 
-func (p *Provider) adaptToolChoice(choice interface{}) (interface{}, error) {
-	// Handle different tool choice types
-	switch v := choice.(type) {
-	case string:
-		switch v {
-		case "auto":
-			return "auto", nil
-		case "none":
-			return "none", nil
-		case "required":
-			return "required", nil
-		default:
-			return nil, fmt.Errorf("unsupported tool choice string: %s", v)
+// adaptToolChoice converts a resolved ToolChoice to OpenAI's tool_choice
+func adaptToolChoice(choice providers.ToolChoice) openai.ChatCompletionToolChoiceOptionUnionParam {
+	if choice.Mode == providers.ToolChoiceFunction {
+		return openai.ChatCompletionNamedToolChoiceParam{
+			Type:     openai.F(openai.ChatCompletionNamedToolChoiceTypeFunction),
+			Function: openai.F(openai.ChatCompletionNamedToolChoiceFunctionParam{Name: openai.F(choice.Name)}),
 		}
-	default:
-		return nil, fmt.Errorf("unsupported tool choice type: %T", choice)
 	}
+	return openai.ChatCompletionToolChoiceOptionBehavior(choice.Mode)
 }
 
 // extractJSONFromMarkdown extracts JSON content from markdown code blocks
@@ -435,10 +1016,3 @@ func findClosingCodeBlock(content string, start int) int {
 	}
 	return -1
 }
-
-// contains checks if a string contains a substring
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && s != substr && (len(s) == len(substr) || 
-		s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || 
-		(len(s) > len(substr) && s[1:len(substr)+1] == substr))
-}
\ No newline at end of file
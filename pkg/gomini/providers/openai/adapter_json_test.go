@@ -0,0 +1,91 @@
+package openai
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+)
+
+func TestAdaptJSONRequest_ResponseFormat(t *testing.T) {
+	p := &Provider{}
+	p.initializeModels()
+
+	strictSchema := map[string]interface{}{
+		"title": "Weather Report",
+		"type":  "object",
+		"properties": map[string]interface{}{
+			"city": map[string]interface{}{"type": "string"},
+		},
+		"required":             []interface{}{"city"},
+		"additionalProperties": false,
+	}
+	looseSchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"city": map[string]interface{}{"type": "string"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		model        string
+		schema       map[string]interface{}
+		expectType   string
+		expectStrict bool
+		expectName   string
+	}{
+		{"strict schema", "gpt-4o", strictSchema, "json_schema", true, "Weather_Report"},
+		{"non-strict schema", "gpt-4o-mini", looseSchema, "json_schema", false, "response"},
+		{"unsupported model", "gpt-3.5-turbo", strictSchema, "json_object", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := p.adaptJSONRequest(&providers.ChatRequest{
+				Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "weather?"}},
+				Model:    tt.model,
+			}, tt.schema)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			body, err := json.Marshal(params)
+			if err != nil {
+				t.Fatalf("failed to marshal params: %v", err)
+			}
+			var decoded struct {
+				Messages       []map[string]interface{} `json:"messages"`
+				ResponseFormat struct {
+					Type       string `json:"type"`
+					JSONSchema struct {
+						Name   string `json:"name"`
+						Strict bool   `json:"strict"`
+					} `json:"json_schema"`
+				} `json:"response_format"`
+			}
+			if err := json.Unmarshal(body, &decoded); err != nil {
+				t.Fatalf("failed to decode params: %v", err)
+			}
+
+			if decoded.ResponseFormat.Type != tt.expectType {
+				t.Errorf("expected response format %q, got %q", tt.expectType, decoded.ResponseFormat.Type)
+			}
+			if decoded.ResponseFormat.JSONSchema.Strict != tt.expectStrict {
+				t.Errorf("expected strict=%v", tt.expectStrict)
+			}
+			if decoded.ResponseFormat.JSONSchema.Name != tt.expectName {
+				t.Errorf("expected name %q, got %q", tt.expectName, decoded.ResponseFormat.JSONSchema.Name)
+			}
+
+			// Only the prompt fallback injects a system message
+			expectMessages := 1
+			if tt.expectType == "json_object" {
+				expectMessages = 2
+			}
+			if len(decoded.Messages) != expectMessages {
+				t.Errorf("expected %d messages, got %d", expectMessages, len(decoded.Messages))
+			}
+		})
+	}
+}
@@ -0,0 +1,727 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/openai/openai-go"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestAdaptImageOutputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected []providers.ImagePart
+	}{
+		{
+			name:     "empty",
+			raw:      "",
+			expected: nil,
+		},
+		{
+			name:     "null",
+			raw:      "null",
+			expected: nil,
+		},
+		{
+			name: "data url",
+			raw:  `[{"type":"image_url","image_url":{"url":"data:image/png;base64,aGVsbG8="}}]`,
+			expected: []providers.ImagePart{
+				{MIMEType: "image/png", Data: []byte("hello")},
+			},
+		},
+		{
+			name: "remote url",
+			raw:  `[{"type":"image_url","image_url":{"url":"https://example.com/cat.png"}}]`,
+			expected: []providers.ImagePart{
+				{URL: "https://example.com/cat.png"},
+			},
+		},
+		{
+			name:     "malformed",
+			raw:      `{"not":"a list"}`,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			images := adaptImageOutputs(tt.raw)
+			if len(images) != len(tt.expected) {
+				t.Fatalf("expected %d images, got %d", len(tt.expected), len(images))
+			}
+			for i, image := range images {
+				want := tt.expected[i]
+				if image.MIMEType != want.MIMEType || string(image.Data) != string(want.Data) || image.URL != want.URL {
+					t.Errorf("image %d: expected %+v, got %+v", i, want, image)
+				}
+			}
+		})
+	}
+}
+
+func TestAdaptChatRequest_Metadata(t *testing.T) {
+	p := &Provider{}
+
+	tags := map[string]string{"team": "search", "feature": "autocomplete", "region": strings.Repeat("é", 600)}
+	for i := 0; i < 20; i++ {
+		tags[fmt.Sprintf("zz_extra_%02d", i)] = strings.Repeat("v", 600)
+	}
+
+	params, err := p.adaptChatRequest(&providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "hi"}},
+		Model:    "gpt-4o",
+		Tags:     tags,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	metadata := params.Metadata.Value
+	if len(metadata) != 16 {
+		t.Fatalf("expected metadata capped at 16 pairs, got %d", len(metadata))
+	}
+	for key, value := range metadata {
+		if utf8.RuneCountInString(value) > 512 || !utf8.ValidString(value) {
+			t.Errorf("metadata value for %q exceeds 512 characters or splits a rune", key)
+		}
+	}
+	if utf8.RuneCountInString(metadata["region"]) != 512 {
+		t.Errorf("expected multibyte values cut at 512 characters, got %d", utf8.RuneCountInString(metadata["region"]))
+	}
+	if metadata["feature"] != "autocomplete" {
+		t.Errorf("expected feature tag to be forwarded, got %q", metadata["feature"])
+	}
+}
+
+func TestAdaptMessage_ImageParts(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	message := map[string]interface{}{
+		"role": "user",
+		"content": []interface{}{
+			map[string]interface{}{"type": "text", "data": map[string]interface{}{"text": "describe"}},
+			map[string]interface{}{"type": "image_url", "data": map[string]interface{}{"url": "https://example.com/cat.png"}},
+			map[string]interface{}{"type": "image_url", "data": map[string]interface{}{"base64": "aGVsbG8=", "mime_type": "image/png"}},
+		},
+	}
+
+	adapted, err := provider.adaptMessage(message)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	raw, err := json.Marshal(adapted)
+	if err != nil {
+		t.Fatalf("failed to marshal message: %v", err)
+	}
+	for _, expected := range []string{`"text":"describe"`, `"url":"https://example.com/cat.png"`, `"url":"data:image/png;base64,aGVsbG8="`} {
+		if !strings.Contains(string(raw), expected) {
+			t.Errorf("expected %s in %s", expected, raw)
+		}
+	}
+
+	message["content"] = []interface{}{
+		map[string]interface{}{"type": "image_url", "data": map[string]interface{}{"base64": "not base64!"}},
+	}
+	if _, err := provider.adaptMessage(message); err == nil {
+		t.Error("expected error for invalid base64 image")
+	}
+
+	// Browser clients send the OpenAI wire shape with URL-safe, unpadded data URLs
+	message["content"] = []interface{}{
+		map[string]interface{}{"type": "image_url", "image_url": map[string]interface{}{"url": "data:image/png;base64,aGVsbG8"}},
+	}
+	adapted, err = provider.adaptMessage(message)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ = json.Marshal(adapted)
+	if !strings.Contains(string(raw), `"url":"data:image/png;base64,aGVsbG8="`) {
+		t.Errorf("expected normalized data URL in %s", raw)
+	}
+
+	message["content"] = []interface{}{
+		map[string]interface{}{"type": "image_url", "data": map[string]interface{}{"url": "data:text/html,<b>hi</b>"}},
+	}
+	if _, err := provider.adaptMessage(message); err == nil {
+		t.Error("expected error for a non-image data URL")
+	}
+}
+
+func TestAdaptTools(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	tools, err := provider.adaptTools([]providers.Tool{providers.FunctionTool{
+		Name:        "get_weather",
+		Description: "Get the weather",
+		Parameters:  map[string]interface{}{"type": "object"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	raw, err := json.Marshal(tools)
+	if err != nil {
+		t.Fatalf("failed to marshal tools: %v", err)
+	}
+	for _, expected := range []string{`"name":"get_weather"`, `"description":"Get the weather"`, `"parameters":{"type":"object"}`} {
+		if !strings.Contains(string(raw), expected) {
+			t.Errorf("expected %s in %s", expected, raw)
+		}
+	}
+
+	if _, err := provider.adaptTools([]providers.Tool{"not a tool"}); err == nil {
+		t.Error("expected error for unsupported tool")
+	}
+}
+
+func TestAdaptContentPart(t *testing.T) {
+	tests := []struct {
+		name        string
+		part        interface{}
+		expected    string
+		expectError bool
+	}{
+		{name: "plain string", part: "hello", expected: `"text":"hello"`},
+		{
+			name:     "image detail",
+			part:     map[string]interface{}{"type": "image_url", "data": map[string]interface{}{"url": "https://example.com/a.png", "detail": "low"}},
+			expected: `"detail":"low"`,
+		},
+		{
+			name:        "invalid detail",
+			part:        map[string]interface{}{"type": "image_url", "data": map[string]interface{}{"url": "https://example.com/a.png", "detail": "ultra"}},
+			expectError: true,
+		},
+		{
+			name:        "unsupported type",
+			part:        map[string]interface{}{"type": "video", "data": map[string]interface{}{}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			part, err := adaptContentPart(tt.part)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			raw, _ := json.Marshal(part)
+			if !strings.Contains(string(raw), tt.expected) {
+				t.Errorf("expected %s in %s", tt.expected, raw)
+			}
+		})
+	}
+}
+
+func TestAdaptChatRequest_Audio(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	provider.initializeModels()
+
+	request := &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{
+			"role":    "user",
+			"content": []interface{}{providers.AudioPart{MIMEType: "audio/x-wav", Data: []byte("wav")}},
+		}},
+		Model: "gpt-4o-audio-preview",
+	}
+
+	params, err := provider.adaptChatRequest(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ := json.Marshal(params.Messages)
+	if !strings.Contains(string(raw), `"input_audio":{"data":"d2F2","format":"wav"}`) {
+		t.Errorf("expected input_audio part, got %s", raw)
+	}
+
+	request.Model = "gpt-4o-mini"
+	if _, err := provider.adaptChatRequest(request); err == nil || !strings.Contains(err.Error(), "audio") {
+		t.Errorf("expected audio capability error, got %v", err)
+	}
+
+	request.Model = "gpt-4o-audio-preview"
+	request.Messages[0].(map[string]interface{})["content"] = []interface{}{providers.AudioPart{MIMEType: "audio/flac"}}
+	if _, err := provider.adaptChatRequest(request); err == nil {
+		t.Error("expected error for unsupported audio format")
+	}
+}
+
+func TestAdaptDocumentPart(t *testing.T) {
+	part, err := adaptContentPart(providers.DocumentPart{MIMEType: "text/markdown", Data: []byte("# Notes"), Name: "notes.md"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ := json.Marshal(part)
+	if !strings.Contains(string(raw), `"text":"notes.md:\n# Notes"`) {
+		t.Errorf("expected text document part, got %s", raw)
+	}
+
+	if _, err := adaptContentPart(providers.DocumentPart{MIMEType: "application/pdf", Data: []byte("%PDF")}); err == nil {
+		t.Error("expected error for PDF documents")
+	}
+}
+
+func TestAdaptStreamChunk_ReasoningContent(t *testing.T) {
+	provider := &Provider{config: &Config{ProviderType: providers.ProviderDeepSeek}}
+
+	var chunk openai.ChatCompletionChunk
+	raw := `{"id":"1","object":"chat.completion.chunk","created":1,"model":"deepseek-reasoner","choices":[{"index":0,"delta":{"role":"assistant","content":"","reasoning_content":"Let me think."}}]}`
+	if err := json.Unmarshal([]byte(raw), &chunk); err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+
+	events := provider.adaptStreamChunk(chunk, "deepseek-reasoner", newToolCallStream())
+	if len(events) != 1 || events[0].Type != providers.EventThought {
+		t.Fatalf("expected a single thought event, got %+v", events)
+	}
+	if thought := events[0].Data.(providers.ThoughtEvent); thought.Text != "Let me think." {
+		t.Errorf("unexpected thought %+v", thought)
+	}
+	if events[0].Provider != providers.ProviderDeepSeek {
+		t.Errorf("expected the compatible provider identity, got %s", events[0].Provider)
+	}
+
+	var completion openai.ChatCompletion
+	raw = `{"id":"2","object":"chat.completion","created":1,"model":"deepseek-reasoner","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"42","reasoning_content":"Six times seven."}}]}`
+	if err := json.Unmarshal([]byte(raw), &completion); err != nil {
+		t.Fatalf("failed to decode completion: %v", err)
+	}
+	choice := provider.adaptChatResponse(completion, "deepseek-reasoner").Choices[0]
+	if choice.Reasoning() != "Six times seven." || choice.Content() != "42" {
+		t.Errorf("unexpected message %+v", choice.Message)
+	}
+}
+
+func TestAdaptUsage_CachedTokens(t *testing.T) {
+	var completion openai.ChatCompletion
+	raw := `{"id":"1","object":"chat.completion","created":1,"model":"gpt-4o","choices":[],
+		"usage":{"prompt_tokens":2000,"completion_tokens":100,"total_tokens":2100,"prompt_tokens_details":{"cached_tokens":1536}}}`
+	if err := json.Unmarshal([]byte(raw), &completion); err != nil {
+		t.Fatalf("failed to decode completion: %v", err)
+	}
+
+	usage := adaptUsage(completion.Usage)
+	if usage.InputTokens != 2000 || usage.CachedInputTokens != 1536 || usage.OutputTokens != 100 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+}
+
+func TestAdaptChatResponse_Refusal(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+
+	var completion openai.ChatCompletion
+	raw := `{"id":"1","object":"chat.completion","created":1,"model":"gpt-4o","choices":[{"index":1,"finish_reason":"content_filter","message":{"role":"assistant","content":null,"refusal":"I can't help with that."}}]}`
+	if err := json.Unmarshal([]byte(raw), &completion); err != nil {
+		t.Fatalf("failed to decode completion: %v", err)
+	}
+
+	choice := provider.adaptChatResponse(completion, "gpt-4o").Choices[0]
+	if choice.Index != 1 || choice.Refusal != "I can't help with that." || choice.FinishReason != providers.FinishReasonContentFilter {
+		t.Errorf("unexpected choice %+v", choice)
+	}
+	if !choice.Filtered() || choice.Content() != "" {
+		t.Errorf("expected a filtered choice without content, got %+v", choice)
+	}
+}
+
+func TestAdaptAnnotations(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+
+	var completion openai.ChatCompletion
+	raw := `{"id":"1","object":"chat.completion","created":1,"model":"gpt-4o-search-preview","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"Go 1.23 is out.","annotations":[
+		{"type":"url_citation","url_citation":{"url":"https://go.dev/blog","title":"Go Blog","start_index":0,"end_index":6}},
+		{"type":"file_citation"}]}}]}`
+	if err := json.Unmarshal([]byte(raw), &completion); err != nil {
+		t.Fatalf("failed to decode completion: %v", err)
+	}
+	citations := provider.adaptChatResponse(completion, "gpt-4o-search-preview").Choices[0].Citations()
+	if len(citations) != 1 || citations[0].URI != "https://go.dev/blog" || citations[0].Title != "Go Blog" {
+		t.Fatalf("expected one URL citation, got %+v", citations)
+	}
+	if span := citations[0].Spans[0]; span.StartIndex != 0 || span.EndIndex != 6 || span.Text != "Go 1.2" {
+		t.Errorf("unexpected span %+v", span)
+	}
+
+	var chunk openai.ChatCompletionChunk
+	raw = `{"id":"2","object":"chat.completion.chunk","created":1,"model":"gpt-4o-search-preview","choices":[{"index":0,"delta":{"annotations":[
+		{"type":"url_citation","url_citation":{"url":"https://go.dev/blog","title":"Go Blog","start_index":0,"end_index":6}}]}}]}`
+	if err := json.Unmarshal([]byte(raw), &chunk); err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+	events := provider.adaptStreamChunk(chunk, "gpt-4o-search-preview", newToolCallStream())
+	if len(events) != 1 || events[0].Type != providers.EventCitation {
+		t.Fatalf("expected a citation event, got %+v", events)
+	}
+	if sources := events[0].Data.(providers.CitationEvent).Sources; len(sources) != 1 || sources[0].Spans[0].EndIndex != 6 {
+		t.Errorf("unexpected sources %+v", sources)
+	}
+}
+
+func TestAdaptStreamChunk_ToolCallDeltas(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	calls := newToolCallStream()
+
+	chunks := []string{
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"get_time","arguments":"{}"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+	}
+
+	var events []providers.StreamEvent
+	for _, raw := range chunks {
+		var chunk openai.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(raw), &chunk); err != nil {
+			t.Fatalf("failed to decode chunk: %v", err)
+		}
+		events = append(events, provider.adaptStreamChunk(chunk, "gpt-4o", calls)...)
+	}
+
+	var deltas []providers.ToolCallDeltaEvent
+	var complete []providers.ToolCall
+	for _, event := range events {
+		switch event.Type {
+		case providers.EventToolCallDelta:
+			deltas = append(deltas, event.Data.(providers.ToolCallDeltaEvent))
+		case providers.EventToolCall:
+			complete = append(complete, event.Data.(providers.ToolCall))
+		}
+	}
+
+	if len(deltas) != 4 {
+		t.Fatalf("expected a delta per fragment, got %+v", deltas)
+	}
+	if last := deltas[3]; last.CallID != "call_1" || last.ToolName != "get_weather" || last.ArgumentsDelta != `"Paris"}` {
+		t.Errorf("expected later fragments to carry the call identity, got %+v", last)
+	}
+	if len(complete) != 2 {
+		t.Fatalf("expected two complete tool calls, got %+v", complete)
+	}
+	if complete[0].ID != "call_1" || complete[0].Name != "get_weather" || complete[0].Arguments["city"] != "Paris" {
+		t.Errorf("unexpected first call %+v", complete[0])
+	}
+	if complete[1].ID != "call_2" || complete[1].Name != "get_time" || len(complete[1].Arguments) != 0 {
+		t.Errorf("unexpected second call %+v", complete[1])
+	}
+	if events[len(events)-1].Type != providers.EventFinished {
+		t.Errorf("expected the tool calls before the finished event, got %+v", events[len(events)-1])
+	}
+	if pending := calls.flush(); pending != nil {
+		t.Errorf("expected no pending calls after finishing, got %+v", pending)
+	}
+}
+
+func TestToolCallStream_InvalidArguments(t *testing.T) {
+	calls := newToolCallStream()
+	calls.add([]openai.ChatCompletionChunkChoicesDeltaToolCall{{
+		Index:    0,
+		ID:       "call_1",
+		Function: openai.ChatCompletionChunkChoicesDeltaToolCallsFunction{Name: "run", Arguments: `not json`},
+	}})
+
+	flushed := calls.flush()
+	if len(flushed) != 1 || flushed[0].Arguments["_raw"] != "not json" {
+		t.Errorf("expected unparsable arguments under _raw, got %+v", flushed)
+	}
+}
+
+func TestAdaptChatRequest_Reasoning(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	params, err := provider.adaptChatRequest(&providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "prove it"}},
+		Model:    "o3-mini",
+		Config:   map[string]interface{}{providers.ConfigReasoning: providers.Reasoning{BudgetTokens: 20000}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ := json.Marshal(params)
+	if !strings.Contains(string(raw), `"reasoning_effort":"high"`) {
+		t.Errorf("expected the budget to map to high effort, got %s", raw)
+	}
+
+	for model, want := range map[string]bool{"o3-mini": true, "o1": true, "gpt-5": true, "o1-mini": false, "gpt-4o": false} {
+		if got := provider.modelCapabilities(model).Reasoning; got != want {
+			t.Errorf("reasoning support of %s = %v, want %v", model, got, want)
+		}
+	}
+}
+
+func TestAdaptLogprobs(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	params, err := provider.adaptChatRequest(&providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "positive or negative?"}},
+		Model:    "gpt-4o-mini",
+		Config:   map[string]interface{}{providers.ConfigTopLogprobs: float64(2)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ := json.Marshal(params)
+	if !strings.Contains(string(raw), `"logprobs":true`) || !strings.Contains(string(raw), `"top_logprobs":2`) {
+		t.Errorf("expected top_logprobs to enable logprobs, got %s", raw)
+	}
+
+	var completion openai.ChatCompletion
+	body := `{"id":"1","object":"chat.completion","created":1,"model":"gpt-4o-mini","choices":[{"index":0,"finish_reason":"stop",
+		"message":{"role":"assistant","content":"positive"},
+		"logprobs":{"content":[{"token":"positive","logprob":-0.01,"bytes":[112],"top_logprobs":[
+			{"token":"positive","logprob":-0.01,"bytes":null},{"token":"negative","logprob":-4.6,"bytes":null}]}],"refusal":null}}]}`
+	if err := json.Unmarshal([]byte(body), &completion); err != nil {
+		t.Fatalf("failed to decode completion: %v", err)
+	}
+	logprobs := provider.adaptChatResponse(completion, "gpt-4o-mini").Choices[0].Logprobs
+	if len(logprobs) != 1 || logprobs[0].Token != "positive" || logprobs[0].Logprob != -0.01 || len(logprobs[0].Bytes) != 1 {
+		t.Fatalf("unexpected logprobs %+v", logprobs)
+	}
+	if top := logprobs[0].TopLogprobs; len(top) != 2 || top[1].Token != "negative" || top[1].Probability() > 0.02 {
+		t.Errorf("unexpected alternatives %+v", top)
+	}
+
+	var chunk openai.ChatCompletionChunk
+	body = `{"id":"2","object":"chat.completion.chunk","created":1,"model":"gpt-4o-mini","choices":[{"index":0,"delta":{"content":"pos"},
+		"logprobs":{"content":[{"token":"pos","logprob":-0.5,"bytes":null,"top_logprobs":[]}],"refusal":null}}]}`
+	if err := json.Unmarshal([]byte(body), &chunk); err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+	events := provider.adaptStreamChunk(chunk, "gpt-4o-mini", newToolCallStream())
+	if len(events) != 1 {
+		t.Fatalf("expected one content event, got %+v", events)
+	}
+	if content := events[0].Data.(providers.ContentEvent); len(content.Logprobs) != 1 || content.Logprobs[0].Token != "pos" {
+		t.Errorf("expected the chunk's logprobs on its content event, got %+v", content)
+	}
+}
+
+func TestAdaptChatRequest_Seed(t *testing.T) {
+	request := &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "roll a die"}},
+		Model:    "gpt-4o",
+		Config:   map[string]interface{}{providers.ConfigSeed: 42},
+	}
+
+	params, err := (&Provider{config: &Config{}}).adaptChatRequest(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ := json.Marshal(params)
+	if !strings.Contains(string(raw), `"seed":42`) {
+		t.Errorf("expected the seed to be sent, got %s", raw)
+	}
+
+	_, err = (&Provider{config: &Config{ProviderType: providers.ProviderDeepSeek}}).adaptChatRequest(request)
+	if !errors.Is(err, providers.ErrUnsupportedFeature) {
+		t.Errorf("expected DeepSeek to reject the seed with ErrUnsupportedFeature, got %v", err)
+	}
+}
+
+func TestAdaptSystemFingerprint(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+
+	var completion openai.ChatCompletion
+	raw := `{"id":"1","object":"chat.completion","created":1,"model":"gpt-4o","system_fingerprint":"fp_44709d6fcb","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"4"}}]}`
+	if err := json.Unmarshal([]byte(raw), &completion); err != nil {
+		t.Fatalf("failed to decode completion: %v", err)
+	}
+	if got := provider.adaptChatResponse(completion, "gpt-4o").SystemFingerprint; got != "fp_44709d6fcb" {
+		t.Errorf("expected the response fingerprint, got %q", got)
+	}
+
+	var chunk openai.ChatCompletionChunk
+	raw = `{"id":"1","object":"chat.completion.chunk","created":1,"model":"gpt-4o","system_fingerprint":"fp_44709d6fcb","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`
+	if err := json.Unmarshal([]byte(raw), &chunk); err != nil {
+		t.Fatalf("failed to decode chunk: %v", err)
+	}
+	events := provider.adaptStreamChunk(chunk, "gpt-4o", newToolCallStream())
+	finished := events[len(events)-1]
+	if finished.Type != providers.EventFinished || finished.Metadata.SystemFingerprint != "fp_44709d6fcb" {
+		t.Errorf("expected the fingerprint on the finished event, got %+v", finished)
+	}
+}
+
+func TestAdaptChatRequest_StopAndPenalties(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	params, err := provider.adaptChatRequest(&providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "list three fruits"}},
+		Model:    "gpt-4o",
+		Config: map[string]interface{}{
+			providers.ConfigStop:             []string{"4."},
+			providers.ConfigFrequencyPenalty: 0.5,
+			providers.ConfigPresencePenalty:  -0.25,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ := json.Marshal(params)
+	for _, want := range []string{`"stop":["4."]`, `"frequency_penalty":0.5`, `"presence_penalty":-0.25`} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("expected %s in %s", want, raw)
+		}
+	}
+}
+
+func TestAdaptChatRequest_MaxOutputTokens(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	for _, key := range []string{providers.ConfigMaxOutputTokens, providers.ConfigMaxTokens} {
+		params, err := provider.adaptChatRequest(&providers.ChatRequest{
+			Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "hi"}},
+			Model:    "gpt-4o",
+			Config:   map[string]interface{}{key: 300},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if params.MaxTokens.Value != 300 {
+			t.Errorf("expected %s to set max_tokens to 300, got %d", key, params.MaxTokens.Value)
+		}
+	}
+}
+
+func TestAdaptChatRequest_ToolChoice(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	tests := []struct {
+		choice interface{}
+		want   string
+	}{
+		{"required", `"tool_choice":"required"`},
+		{"none", `"tool_choice":"none"`},
+		{providers.ForceTool("get_weather"), `"tool_choice":{"function":{"name":"get_weather"},"type":"function"}`},
+	}
+	for _, tt := range tests {
+		params, err := provider.adaptChatRequest(&providers.ChatRequest{
+			Messages:   []providers.Message{map[string]interface{}{"role": "user", "content": "weather in Taipei?"}},
+			Model:      "gpt-4o",
+			Tools:      []providers.Tool{providers.FunctionTool{Name: "get_weather"}},
+			ToolChoice: tt.choice,
+		})
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tt.choice, err)
+		}
+		raw, _ := json.Marshal(params)
+		if !strings.Contains(string(raw), tt.want) {
+			t.Errorf("%v: expected %s in %s", tt.choice, tt.want, raw)
+		}
+	}
+
+	_, err := provider.adaptChatRequest(&providers.ChatRequest{
+		Messages:   []providers.Message{map[string]interface{}{"role": "user", "content": "what time is it?"}},
+		Model:      "gpt-4o",
+		Tools:      []providers.Tool{providers.FunctionTool{Name: "get_weather"}},
+		ToolChoice: "get_time",
+	})
+	if !errors.Is(err, providers.ErrInvalidParameters) {
+		t.Errorf("expected an unknown forced tool to be rejected, got %v", err)
+	}
+}
+
+func TestAdaptChatRequest_ParallelToolCalls(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	request := &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "weather in Taipei and Tokyo?"}},
+		Model:    "gpt-4o",
+		Config:   map[string]interface{}{providers.ConfigParallelToolCalls: false},
+	}
+
+	// OpenAI rejects parallel_tool_calls without tools
+	params, err := provider.adaptChatRequest(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if raw, _ := json.Marshal(params); strings.Contains(string(raw), "parallel_tool_calls") {
+		t.Errorf("expected no parallel_tool_calls without tools, got %s", raw)
+	}
+
+	request.Tools = []providers.Tool{providers.FunctionTool{Name: "get_weather"}}
+	params, err = provider.adaptChatRequest(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if raw, _ := json.Marshal(params); !strings.Contains(string(raw), `"parallel_tool_calls":false`) {
+		t.Errorf("expected parallel_tool_calls false, got %s", raw)
+	}
+}
+
+func TestAdaptChatRequest_ToolRoundTrip(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	toolCalls := []providers.ToolCall{
+		{ID: "call_1", Name: "get_weather", Arguments: map[string]interface{}{"city": "Taipei"}},
+		{ID: "call_2", Name: "get_weather", Arguments: map[string]interface{}{"city": "Tokyo"}},
+	}
+	// Tool calls restored from a stored session arrive as maps
+	var storedCalls []interface{}
+	raw, _ := json.Marshal(toolCalls)
+	json.Unmarshal(raw, &storedCalls)
+
+	for name, calls := range map[string]interface{}{"values": toolCalls, "stored": storedCalls} {
+		params, err := provider.adaptChatRequest(&providers.ChatRequest{
+			Messages: []providers.Message{
+				map[string]interface{}{"role": "user", "content": "weather in Taipei and Tokyo?"},
+				map[string]interface{}{"role": "assistant", "content": "", "tool_calls": calls},
+				map[string]interface{}{"role": "tool", "tool_call_id": "call_1", "name": "get_weather", "content": "sunny"},
+				map[string]interface{}{"role": "tool", "tool_call_id": "call_2", "name": "get_weather", "content": "rain"},
+				map[string]interface{}{"role": "assistant", "content": "Sunny in Taipei, rain in Tokyo."},
+				map[string]interface{}{"role": "user", "content": "and in Seoul?"},
+			},
+			Model: "gpt-4o",
+			Tools: []providers.Tool{providers.FunctionTool{Name: "get_weather"}},
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		var body struct {
+			Messages []map[string]interface{} `json:"messages"`
+		}
+		raw, _ := json.Marshal(params)
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Fatalf("%s: failed to decode messages: %v", name, err)
+		}
+		messages := body.Messages
+		if len(messages) != 6 {
+			t.Fatalf("%s: expected 6 messages, got %d", name, len(messages))
+		}
+		adaptedCalls, _ := messages[1]["tool_calls"].([]interface{})
+		if len(adaptedCalls) != 2 {
+			t.Fatalf("%s: expected the assistant's 2 tool calls, got %s", name, raw)
+		}
+		first := adaptedCalls[0].(map[string]interface{})
+		function := first["function"].(map[string]interface{})
+		if first["id"] != "call_1" || first["type"] != "function" || function["name"] != "get_weather" ||
+			function["arguments"] != `{"city":"Taipei"}` {
+			t.Errorf("%s: unexpected tool call %v", name, first)
+		}
+		if _, ok := messages[1]["content"]; ok {
+			t.Errorf("%s: expected no content on a tool-call-only assistant message, got %v", name, messages[1]["content"])
+		}
+		for i, callID := range []string{"call_1", "call_2"} {
+			message := messages[2+i]
+			if message["role"] != "tool" || message["tool_call_id"] != callID {
+				t.Errorf("%s: expected tool result for %s, got %v", name, callID, message)
+			}
+		}
+		if !strings.Contains(string(raw), "sunny") || !strings.Contains(string(raw), "Sunny in Taipei") {
+			t.Errorf("%s: expected tool results and the answer to be sent, got %s", name, raw)
+		}
+	}
+
+	_, err := provider.adaptChatRequest(&providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "tool", "content": "sunny"}},
+		Model:    "gpt-4o",
+	})
+	if err == nil {
+		t.Error("expected a tool message without tool_call_id to be rejected")
+	}
+}
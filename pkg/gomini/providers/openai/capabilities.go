@@ -0,0 +1,108 @@
+package openai
+
+import "gomini/pkg/gomini/providers"
+
+var (
+	// chatCapabilities are shared by every chat model
+	chatCapabilities = providers.ModelCapabilities{
+		TextGeneration: true,
+		SystemMessage:  true,
+		Streaming:      true,
+	}
+
+	// toolCapabilities add function calling and JSON mode
+	toolCapabilities = withCapabilities(chatCapabilities, func(c *providers.ModelCapabilities) {
+		c.FunctionCalling = true
+		c.JSONMode = true
+	})
+
+	// multimodalCapabilities add image input and json_schema responses
+	multimodalCapabilities = withCapabilities(toolCapabilities, func(c *providers.ModelCapabilities) {
+		c.ImageInput = true
+		c.StructuredOutput = true
+	})
+
+	// reasoningCapabilities add reasoning_effort
+	reasoningCapabilities = withCapabilities(multimodalCapabilities, func(c *providers.ModelCapabilities) {
+		c.Reasoning = true
+	})
+)
+
+// defaultCapabilities describes OpenAI models the static catalog does not
+// list. Config.Capabilities overrides it.
+var defaultCapabilities = providers.CapabilityTable{
+	{Pattern: "*", Capabilities: chatCapabilities, ContextSize: 4096},
+
+	{Pattern: "gpt-3.5*", Capabilities: toolCapabilities, ContextSize: 16384, MaxOutputTokens: 4096},
+	{Pattern: "gpt-3.5-turbo-instruct*", Capabilities: chatCapabilities, ContextSize: 4096, MaxOutputTokens: 4096},
+
+	{Pattern: "gpt-4*", Capabilities: toolCapabilities, ContextSize: 8192, MaxOutputTokens: 8192},
+	{Pattern: "gpt-4-32k*", Capabilities: toolCapabilities, ContextSize: 32768, MaxOutputTokens: 8192},
+	{Pattern: "gpt-4-turbo*", Capabilities: withCapabilities(toolCapabilities, func(c *providers.ModelCapabilities) {
+		c.ImageInput = true
+	}), ContextSize: 128000, MaxOutputTokens: 4096},
+	{Pattern: "gpt-4-1106-preview", Capabilities: toolCapabilities, ContextSize: 128000, MaxOutputTokens: 4096},
+	{Pattern: "gpt-4-0125-preview", Capabilities: toolCapabilities, ContextSize: 128000, MaxOutputTokens: 4096},
+
+	{Pattern: "gpt-4o*", Capabilities: multimodalCapabilities, ContextSize: 128000, MaxOutputTokens: 16384},
+	{Pattern: "gpt-4o-2024-05-13", Capabilities: withCapabilities(multimodalCapabilities, func(c *providers.ModelCapabilities) {
+		c.StructuredOutput = false
+	}), ContextSize: 128000, MaxOutputTokens: 4096},
+	{Pattern: "gpt-4o-audio*", Capabilities: withCapabilities(chatCapabilities, func(c *providers.ModelCapabilities) {
+		c.AudioInput = true
+		c.FunctionCalling = true
+	}), ContextSize: 128000, MaxOutputTokens: 16384},
+	{Pattern: "gpt-4o-mini-audio*", Capabilities: withCapabilities(chatCapabilities, func(c *providers.ModelCapabilities) {
+		c.AudioInput = true
+		c.FunctionCalling = true
+	}), ContextSize: 128000, MaxOutputTokens: 16384},
+	{Pattern: "gpt-4o-transcribe*", Capabilities: providers.ModelCapabilities{Transcription: true}},
+	{Pattern: "gpt-4o-mini-transcribe*", Capabilities: providers.ModelCapabilities{Transcription: true}},
+	{Pattern: "gpt-4o-mini-tts*", Capabilities: providers.ModelCapabilities{SpeechGeneration: true, Streaming: true}},
+	{Pattern: "gpt-4.1*", Capabilities: multimodalCapabilities, ContextSize: 1047576, MaxOutputTokens: 32768},
+	{Pattern: "gpt-4.5*", Capabilities: multimodalCapabilities, ContextSize: 128000, MaxOutputTokens: 16384},
+
+	{Pattern: "gpt-5*", Capabilities: reasoningCapabilities, ContextSize: 400000, MaxOutputTokens: 128000},
+
+	// The o1 previews and o1-mini predate tools, reasoning_effort and json_schema
+	{Pattern: "o1*", Capabilities: reasoningCapabilities, ContextSize: 200000, MaxOutputTokens: 100000},
+	{Pattern: "o1-preview*", Capabilities: chatCapabilities, ContextSize: 128000, MaxOutputTokens: 32768},
+	{Pattern: "o1-mini*", Capabilities: chatCapabilities, ContextSize: 128000, MaxOutputTokens: 65536},
+	{Pattern: "o3*", Capabilities: reasoningCapabilities, ContextSize: 200000, MaxOutputTokens: 100000},
+	{Pattern: "o3-mini*", Capabilities: withCapabilities(reasoningCapabilities, func(c *providers.ModelCapabilities) {
+		c.ImageInput = false
+	}), ContextSize: 200000, MaxOutputTokens: 100000},
+	{Pattern: "o4*", Capabilities: reasoningCapabilities, ContextSize: 200000, MaxOutputTokens: 100000},
+
+	{Pattern: "dall-e*", Capabilities: providers.ModelCapabilities{ImageGeneration: true}},
+	{Pattern: "gpt-image*", Capabilities: providers.ModelCapabilities{ImageGeneration: true}},
+	{Pattern: "tts*", Capabilities: providers.ModelCapabilities{SpeechGeneration: true, Streaming: true}},
+	{Pattern: "whisper*", Capabilities: providers.ModelCapabilities{Transcription: true}},
+	{Pattern: "text-embedding*", Capabilities: providers.ModelCapabilities{}},
+	{Pattern: "omni-moderation*", Capabilities: providers.ModelCapabilities{}},
+}
+
+// withCapabilities returns a copy of base changed by edit
+func withCapabilities(base providers.ModelCapabilities, edit func(*providers.ModelCapabilities)) providers.ModelCapabilities {
+	edit(&base)
+	return base
+}
+
+// modelCapabilities returns what the catalog says about model, or what its
+// profile says when the catalog does not list it
+func (p *Provider) modelCapabilities(model string) providers.ModelCapabilities {
+	if m, ok := providers.FindModel(p.catalog(), model); ok {
+		return m.Capabilities
+	}
+	return p.profile(providers.Model{ID: model}).Capabilities
+}
+
+// profile fills model from the default capability table, then from the
+// configured overrides
+func (p *Provider) profile(model providers.Model) providers.Model {
+	model = defaultCapabilities.Apply(model)
+	if p.config != nil {
+		model = p.config.Capabilities.Apply(model)
+	}
+	return model
+}
@@ -0,0 +1,120 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"gomini/pkg/gomini/providers"
+)
+
+func TestDefaultCapabilities(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	tests := []struct {
+		model       string
+		check       func(providers.ModelCapabilities) bool
+		contextSize int
+		maxOutput   int
+	}{
+		{"gpt-4o-2024-11-20", func(c providers.ModelCapabilities) bool { return c.ImageInput && c.StructuredOutput }, 128000, 16384},
+		{"gpt-4o-2024-05-13", func(c providers.ModelCapabilities) bool { return c.ImageInput && !c.StructuredOutput }, 128000, 4096},
+		{"gpt-4o-mini-audio-preview", func(c providers.ModelCapabilities) bool { return c.AudioInput && !c.ImageInput }, 128000, 16384},
+		{"gpt-4-32k", func(c providers.ModelCapabilities) bool { return c.FunctionCalling && !c.ImageInput }, 32768, 8192},
+		{"o1-mini", func(c providers.ModelCapabilities) bool { return !c.Reasoning && !c.FunctionCalling }, 128000, 65536},
+		{"o3-mini", func(c providers.ModelCapabilities) bool { return c.Reasoning && !c.ImageInput }, 200000, 100000},
+		{"whisper-1", func(c providers.ModelCapabilities) bool { return c.Transcription && !c.TextGeneration }, 0, 0},
+		{"my-finetune", func(c providers.ModelCapabilities) bool { return c.TextGeneration && !c.FunctionCalling }, 4096, 0},
+	}
+	for _, tt := range tests {
+		model := provider.adaptModel(openai.Model{ID: tt.model})
+		if !tt.check(model.Capabilities) || model.ContextSize != tt.contextSize || model.MaxOutputTokens != tt.maxOutput {
+			t.Errorf("%s: unexpected profile %+v", tt.model, model)
+		}
+	}
+}
+
+func TestProvider_CapabilityOverrides(t *testing.T) {
+	override := providers.ModelProfile{
+		Pattern:      "gpt-4o*",
+		Capabilities: providers.ModelCapabilities{TextGeneration: true, Streaming: true},
+		ContextSize:  64000,
+	}
+	provider, err := NewProvider(&Config{APIKey: "sk-test", Capabilities: providers.CapabilityTable{override}})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	model, _ := providers.FindModel(provider.catalog(), "gpt-4o")
+	if model.Capabilities.ImageInput || model.ContextSize != 64000 || model.MaxOutputTokens != 16384 || model.Cost == nil {
+		t.Errorf("Expected the override to apply to the catalog entry, got %+v", model)
+	}
+	if provider.supportsStructuredOutput("gpt-4o-2024-11-20") {
+		t.Error("Expected the override to apply to models outside the catalog")
+	}
+}
+
+func TestProvider_ListModelsDiscovery(t *testing.T) {
+	requests, status := 0, http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			w.Write([]byte(`{"error":{"message":"unavailable","type":"invalid_request_error"}}`))
+			return
+		}
+		w.Write([]byte(`{"object":"list","data":[
+			{"id":"gpt-4o","object":"model","created":1,"owned_by":"openai"},
+			{"id":"gpt-4.1-mini","object":"model","created":1,"owned_by":"openai"}]}`))
+	}))
+	defer server.Close()
+
+	static, err := NewProvider(&Config{APIKey: "sk-test", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	if _, err := static.ListModels(context.Background()); err != nil || requests != 0 {
+		t.Fatalf("Expected the static catalog without discovery, got %d requests, %v", requests, err)
+	}
+
+	provider, err := NewProvider(&Config{APIKey: "sk-test", BaseURL: server.URL + "/v1", DiscoverModels: true, ModelsTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	models, err := provider.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if requests != 1 || len(models) != len(provider.builtin)+1 {
+		t.Fatalf("Expected one listing merged into the catalog, got %d requests and %d models", requests, len(models))
+	}
+	if model, _ := providers.FindModel(models, "gpt-4o"); model.Cost == nil || model.Name != "GPT-4o" {
+		t.Errorf("Expected the catalog entry to be kept, got %+v", model)
+	}
+	if model, _ := providers.FindModel(models, "gpt-4.1-mini"); !model.Capabilities.StructuredOutput || model.ContextSize != 1047576 {
+		t.Errorf("Expected the discovered model to be profiled, got %+v", model)
+	}
+
+	provider.ListModels(context.Background())
+	if requests != 1 {
+		t.Errorf("Expected the listing to be cached within the TTL, got %d requests", requests)
+	}
+
+	// Past the TTL a failed refresh serves the previous catalog
+	provider.modelsFetched = time.Now().Add(-time.Hour)
+	status = http.StatusBadRequest
+	models, err = provider.ListModels(context.Background())
+	if err != nil || requests != 2 {
+		t.Fatalf("Expected a refresh attempt, got %d requests, %v", requests, err)
+	}
+	if _, ok := providers.FindModel(models, "gpt-4.1-mini"); !ok {
+		t.Error("Expected the previous catalog after a failed refresh")
+	}
+}
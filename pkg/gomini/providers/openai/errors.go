@@ -0,0 +1,47 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/openai/openai-go"
+	"gomini/pkg/gomini/providers"
+)
+
+// apiError decodes an SDK error response into a *providers.APIError; other
+// errors are returned unchanged
+func apiError(err error) error {
+	var sdkErr *openai.Error
+	if err == nil || !errors.As(err, &sdkErr) {
+		return err
+	}
+	decoded := &providers.APIError{
+		StatusCode: sdkErr.StatusCode,
+		Type:       sdkErr.Type,
+		Code:       sdkErr.Code,
+		Param:      sdkErr.Param,
+		Message:    sdkErr.Message,
+		Err:        err,
+	}
+	// The API nests the fields under "error", which this SDK version leaves undecoded
+	var body struct {
+		Error *struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+			Param   string `json:"param"`
+			Code    string `json:"code"`
+		} `json:"error"`
+	}
+	if decoded.Message == "" && json.Unmarshal([]byte(sdkErr.JSON.RawJSON()), &body) == nil && body.Error != nil {
+		decoded.Message, decoded.Type = body.Error.Message, body.Error.Type
+		decoded.Param, decoded.Code = body.Error.Param, body.Error.Code
+	}
+	if sdkErr.Response != nil {
+		if decoded.StatusCode == 0 {
+			decoded.StatusCode = sdkErr.Response.StatusCode
+		}
+		decoded.RetryAfter = providers.ParseRetryAfter(sdkErr.Response.Header)
+		decoded.RequestID = sdkErr.Response.Header.Get("X-Request-Id")
+	}
+	return decoded
+}
@@ -0,0 +1,92 @@
+package openai
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+// addPayloadSeeds seeds f with the recorded provider payloads matching pattern
+func addPayloadSeeds(f *testing.F, pattern string) {
+	f.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "payloads", pattern))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(raw))
+	}
+}
+
+func FuzzExtractJSONFromMarkdown(f *testing.F) {
+	for _, seed := range []string{
+		"```json\n{\"a\": 1}\n```",
+		"```\n[1, 2]\n```",
+		"```json{}```",
+		"```json",
+		"``````",
+		"{\"plain\": true}",
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	p := &Provider{}
+	f.Fuzz(func(t *testing.T, content string) {
+		extracted := p.extractJSONFromMarkdown(content)
+		if !strings.Contains(content, extracted) {
+			t.Errorf("extracted %q is not part of %q", extracted, content)
+		}
+	})
+}
+
+func FuzzAdaptStreamChunk(f *testing.F) {
+	addPayloadSeeds(f, "chunk_*.json")
+
+	p := &Provider{}
+	p.initializeModels()
+	f.Fuzz(func(t *testing.T, raw string) {
+		var chunk openai.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(raw), &chunk); err != nil {
+			return
+		}
+		p.adaptStreamChunk(chunk, "gpt-4o", newToolCallStream())
+	})
+}
+
+func FuzzAdaptCompletion(f *testing.F) {
+	addPayloadSeeds(f, "completion_*.json")
+
+	p := &Provider{}
+	p.initializeModels()
+	f.Fuzz(func(t *testing.T, raw string) {
+		var completion openai.ChatCompletion
+		if err := json.Unmarshal([]byte(raw), &completion); err != nil {
+			return
+		}
+		p.adaptChatResponse(completion, "gpt-4o")
+		p.adaptJSONResponse(completion, "gpt-4o", nil)
+	})
+}
+
+func FuzzAdaptMessage(f *testing.F) {
+	addPayloadSeeds(f, "message_*.json")
+
+	p := &Provider{}
+	p.initializeModels()
+	f.Fuzz(func(t *testing.T, raw string) {
+		var message map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &message); err != nil {
+			return
+		}
+		p.adaptMessage(message)
+	})
+}
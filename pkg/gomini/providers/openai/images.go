@@ -0,0 +1,105 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"gomini/pkg/gomini/providers"
+)
+
+// GenerateImage implements providers.ImageGenerator using the DALL-E images API
+func (p *Provider) GenerateImage(ctx context.Context, req *providers.ImageRequest) (*providers.ImageResponse, error) {
+	params, err := p.adaptImageRequest(req)
+	if err != nil {
+		return nil, providers.WrapProviderError(err, providers.ProviderOpenAI, req.Model)
+	}
+
+	resp, err := p.client.Images.Generate(ctx, params)
+	if err != nil {
+		return nil, providers.WrapProviderError(apiError(err), providers.ProviderOpenAI, req.Model)
+	}
+
+	result, err := p.adaptImagesResponse(resp, req.Model)
+	if err != nil {
+		return nil, providers.WrapProviderError(err, providers.ProviderOpenAI, req.Model)
+	}
+	return result, nil
+}
+
+// adaptImageRequest converts an image request to OpenAI image generation params
+func (p *Provider) adaptImageRequest(req *providers.ImageRequest) (openai.ImageGenerateParams, error) {
+	if req.Prompt == "" {
+		return openai.ImageGenerateParams{}, fmt.Errorf("image prompt is required")
+	}
+	if !p.supportsImageGeneration(req.Model) {
+		return openai.ImageGenerateParams{}, providers.NewLLMError(providers.ErrorUnsupportedFeature,
+			fmt.Sprintf("model %s does not support image generation", req.Model), providers.ProviderOpenAI, nil)
+	}
+	if req.N > 1 && req.Model == string(openai.ImageModelDallE3) {
+		return openai.ImageGenerateParams{}, fmt.Errorf("%s generates one image per request", req.Model)
+	}
+
+	params := openai.ImageGenerateParams{
+		Prompt: openai.F(req.Prompt),
+		Model:  openai.F(openai.ImageModel(req.Model)),
+	}
+	if req.N > 0 {
+		params.N = openai.F(int64(req.N))
+	}
+	if req.Size != "" {
+		params.Size = openai.F(openai.ImageGenerateParamsSize(req.Size))
+	}
+	if req.Quality != "" {
+		params.Quality = openai.F(openai.ImageGenerateParamsQuality(req.Quality))
+	}
+	if req.Style != "" {
+		params.Style = openai.F(openai.ImageGenerateParamsStyle(req.Style))
+	}
+	switch req.ResponseFormat {
+	case "":
+	case providers.ImageFormatURL, providers.ImageFormatBase64:
+		params.ResponseFormat = openai.F(openai.ImageGenerateParamsResponseFormat(req.ResponseFormat))
+	default:
+		return openai.ImageGenerateParams{}, fmt.Errorf("unsupported image response format %q", req.ResponseFormat)
+	}
+	return params, nil
+}
+
+// adaptImagesResponse converts an OpenAI images response, decoding base64 images to bytes
+func (p *Provider) adaptImagesResponse(resp *openai.ImagesResponse, model string) (*providers.ImageResponse, error) {
+	images := make([]providers.GeneratedImage, 0, len(resp.Data))
+	for i, image := range resp.Data {
+		generated := providers.GeneratedImage{
+			URL:           image.URL,
+			RevisedPrompt: image.RevisedPrompt,
+		}
+		if image.B64JSON != "" {
+			data, err := base64.StdEncoding.DecodeString(image.B64JSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode image %d: %w", i, err)
+			}
+			generated.Data = data
+			generated.MIMEType = "image/png"
+		}
+		images = append(images, generated)
+	}
+
+	return &providers.ImageResponse{
+		Model:    model,
+		Provider: providers.ProviderOpenAI,
+		Images:   images,
+		Usage:    providers.NewImageUsage(p.catalog(), model, len(images)),
+		Created:  resp.Created,
+	}, nil
+}
+
+// supportsImageGeneration reports whether a model can generate images
+func (p *Provider) supportsImageGeneration(model string) bool {
+	if m, ok := providers.FindModel(p.catalog(), model); ok {
+		return m.Capabilities.ImageGeneration
+	}
+	return strings.HasPrefix(model, "dall-e")
+}
@@ -0,0 +1,83 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+)
+
+func TestProvider_GenerateImage(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/images/generations" {
+			http.NotFound(w, r)
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		json.Unmarshal(raw, &body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"created":1700000000,"data":[
+			{"url":"https://images.example/1.png","revised_prompt":"a red fox"},
+			{"b64_json":"iVBORw=="}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{APIKey: "sk-test", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	resp, err := provider.GenerateImage(context.Background(), &providers.ImageRequest{
+		Prompt: "a fox",
+		Model:  "dall-e-2",
+		N:      2,
+		Size:   "512x512",
+	})
+	if err != nil {
+		t.Fatalf("GenerateImage failed: %v", err)
+	}
+
+	if body["prompt"] != "a fox" || body["model"] != "dall-e-2" || body["n"] != float64(2) || body["size"] != "512x512" {
+		t.Errorf("Unexpected request body: %v", body)
+	}
+	if len(resp.Images) != 2 {
+		t.Fatalf("Expected 2 images, got %d", len(resp.Images))
+	}
+	if resp.Images[0].URL != "https://images.example/1.png" || resp.Images[0].RevisedPrompt != "a red fox" {
+		t.Errorf("Unexpected URL image: %+v", resp.Images[0])
+	}
+	if string(resp.Images[1].Data) != "\x89PNG" || resp.Images[1].MIMEType != "image/png" {
+		t.Errorf("Expected decoded PNG bytes, got %+v", resp.Images[1])
+	}
+	if resp.Usage == nil || resp.Usage.Images != 2 || resp.Usage.Cost != 0.04 {
+		t.Errorf("Unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestAdaptImageRequest_Validation(t *testing.T) {
+	provider := &Provider{config: &Config{}}
+	provider.initializeModels()
+
+	tests := []struct {
+		name string
+		req  providers.ImageRequest
+	}{
+		{name: "missing prompt", req: providers.ImageRequest{Model: "dall-e-3"}},
+		{name: "chat model", req: providers.ImageRequest{Prompt: "a fox", Model: "gpt-4o"}},
+		{name: "dall-e-3 multiple images", req: providers.ImageRequest{Prompt: "a fox", Model: "dall-e-3", N: 2}},
+		{name: "unknown response format", req: providers.ImageRequest{Prompt: "a fox", Model: "dall-e-3", ResponseFormat: "webp"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := provider.adaptImageRequest(&tt.req); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
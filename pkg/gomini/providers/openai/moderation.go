@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/shared"
+	"gomini/pkg/gomini/providers"
+)
+
+// DefaultModerationModel is the model used by Moderate
+const DefaultModerationModel = openai.ModerationModelOmniModerationLatest
+
+// Moderate implements providers.Moderator using the moderations API
+func (p *Provider) Moderate(ctx context.Context, text string) (*providers.ModerationResult, error) {
+	if p.providerType() != providers.ProviderOpenAI {
+		return nil, providers.NewLLMError(providers.ErrorUnsupportedFeature,
+			fmt.Sprintf("provider %s has no moderation endpoint", p.providerType()), p.providerType(), nil)
+	}
+
+	resp, err := p.client.Moderations.New(ctx, openai.ModerationNewParams{
+		Input: openai.F[openai.ModerationNewParamsInputUnion](shared.UnionString(text)),
+		Model: openai.F(DefaultModerationModel),
+	})
+	if err != nil {
+		return nil, providers.WrapProviderError(apiError(err), providers.ProviderOpenAI, DefaultModerationModel)
+	}
+	if len(resp.Results) == 0 {
+		return nil, providers.WrapProviderError(fmt.Errorf("moderation returned no results"), providers.ProviderOpenAI, resp.Model)
+	}
+	return adaptModeration(resp.Model, resp.Results[0])
+}
+
+// adaptModeration converts a moderation result. Categories are read from the
+// raw JSON so categories newer than the SDK are kept.
+func adaptModeration(model string, moderation openai.Moderation) (*providers.ModerationResult, error) {
+	var raw struct {
+		Categories     map[string]bool    `json:"categories"`
+		CategoryScores map[string]float64 `json:"category_scores"`
+	}
+	if err := json.Unmarshal([]byte(moderation.JSON.RawJSON()), &raw); err != nil {
+		return nil, providers.WrapProviderError(fmt.Errorf("failed to decode moderation result: %w", err), providers.ProviderOpenAI, model)
+	}
+
+	result := &providers.ModerationResult{
+		Flagged:  moderation.Flagged,
+		Scores:   raw.CategoryScores,
+		Model:    model,
+		Provider: providers.ProviderOpenAI,
+	}
+	for category, flagged := range raw.Categories {
+		if flagged {
+			result.Categories = append(result.Categories, category)
+		}
+	}
+	sort.Strings(result.Categories)
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+)
+
+func TestProvider_Moderate(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/moderations" {
+			http.NotFound(w, r)
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		json.Unmarshal(raw, &body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"modr-1","model":"omni-moderation-2024-09-26","results":[{
+			"flagged":true,
+			"categories":{"harassment":true,"violence":true,"self-harm":false},
+			"category_scores":{"harassment":0.91,"violence":0.72,"self-harm":0.01},
+			"category_applied_input_types":{}}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{APIKey: "sk-test", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	result, err := provider.Moderate(context.Background(), "some text")
+	if err != nil {
+		t.Fatalf("Moderate failed: %v", err)
+	}
+
+	if body["input"] != "some text" || body["model"] != DefaultModerationModel {
+		t.Errorf("Unexpected request body: %v", body)
+	}
+	if !result.Flagged || result.Provider != providers.ProviderOpenAI || result.Model != "omni-moderation-2024-09-26" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(result.Categories) != 2 || result.Categories[0] != "harassment" || result.Categories[1] != "violence" {
+		t.Errorf("Expected harassment and violence, got %v", result.Categories)
+	}
+	if result.Scores["harassment"] != 0.91 {
+		t.Errorf("Expected harassment score 0.91, got %v", result.Scores)
+	}
+}
+
+func TestProvider_ModerateCompatibleProvider(t *testing.T) {
+	provider := &Provider{config: &Config{ProviderType: providers.ProviderGroq}}
+	if _, err := provider.Moderate(context.Background(), "text"); err == nil {
+		t.Error("Expected OpenAI-compatible providers to report no moderation endpoint")
+	}
+}
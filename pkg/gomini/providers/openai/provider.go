@@ -3,9 +3,13 @@ package openai
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
 	"gomini/pkg/gomini/providers"
 )
 
@@ -15,6 +19,10 @@ type Provider struct {
 	config   *Config
 	models   []providers.Model
 	created  time.Time
+
+	modelsMu      sync.RWMutex      // Guards models and modelsFetched
+	builtin       []providers.Model // Static catalog that discovered models merge into
+	modelsFetched time.Time
 }
 
 // Config holds OpenAI-specific configuration
@@ -25,21 +33,37 @@ type Config struct {
 	Project      string            `json:"project,omitempty"`
 	DefaultModel string            `json:"default_model,omitempty"`
 	ExtraHeaders map[string]string `json:"extra_headers,omitempty"`
+	ExtraQuery   map[string]string `json:"extra_query,omitempty"`
+	ExtraBody    map[string]interface{} `json:"extra_body,omitempty"` // Merged into every JSON request body
 	Timeout      time.Duration     `json:"timeout,omitempty"`
+	HTTPClient   *http.Client      `json:"-"` // Replaces the SDK's default client, e.g. for proxies or mTLS
+
+	// OpenAI-compatible services reuse this provider under their own identity
+	// and model catalog; zero values mean OpenAI itself
+	ProviderType providers.ProviderType `json:"provider_type,omitempty"`
+	Models       []providers.Model      `json:"-"`
+
+	// Capabilities override the built-in capability table. With
+	// DiscoverModels, ListModels merges the API's model listing into the
+	// catalog and refreshes it every ModelsTTL (default one hour).
+	Capabilities   providers.CapabilityTable `json:"capabilities,omitempty"`
+	DiscoverModels bool                      `json:"discover_models,omitempty"`
+	ModelsTTL      time.Duration             `json:"models_ttl,omitempty"`
 }
 
 // NewProvider creates a new OpenAI provider instance
 func NewProvider(config *Config) (*Provider, error) {
 	if config.APIKey == "" {
-		return nil, providers.NewLLMError(providers.ErrorInvalidAPIKey, "OpenAI API key is required", providers.ProviderOpenAI, nil)
+		providerType := config.ProviderType
+		if providerType == "" {
+			providerType = providers.ProviderOpenAI
+		}
+		return nil, providers.NewLLMError(providers.ErrorInvalidAPIKey,
+			fmt.Sprintf("%s API key is required", providerType), providerType, nil)
 	}
 
-	// Configure OpenAI client  
-	// For this SDK version, we'll create a basic client
-	client := openai.NewClient(
-		// Client options will be handled by the SDK directly
-		// openai.WithAPIKey(config.APIKey), // This may not exist in this version
-	)
+	// Configure OpenAI client
+	client := openai.NewClient(buildClientOptions(config)...)
 
 	provider := &Provider{
 		client:  client,
@@ -49,30 +73,105 @@ func NewProvider(config *Config) (*Provider, error) {
 
 	// Initialize available models
 	provider.initializeModels()
+	provider.models = config.Capabilities.ApplyAll(provider.models)
+	provider.builtin = provider.models
 
 	return provider, nil
 }
 
+// buildClientOptions converts the provider config into SDK request options.
+// Options passed here take precedence over the SDK's environment defaults.
+func buildClientOptions(config *Config) []option.RequestOption {
+	opts := []option.RequestOption{
+		option.WithAPIKey(config.APIKey),
+	}
+
+	if config.BaseURL != "" {
+		// The SDK resolves request paths relative to the base URL, so a missing
+		// trailing slash would drop the last path segment (e.g. "/v1")
+		baseURL := config.BaseURL
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+
+	if config.Organization != "" {
+		opts = append(opts, option.WithOrganization(config.Organization))
+	}
+
+	if config.Project != "" {
+		opts = append(opts, option.WithProject(config.Project))
+	}
+
+	for key, value := range config.ExtraHeaders {
+		opts = append(opts, option.WithHeader(key, value))
+	}
+
+	if extra := (providers.ExtraRequest{Query: config.ExtraQuery, Body: config.ExtraBody}); !extra.Empty() {
+		opts = append(opts, option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+			req, err := extra.Apply(req)
+			if err != nil {
+				return nil, err
+			}
+			return next(req)
+		}))
+	}
+
+	if config.HTTPClient != nil {
+		opts = append(opts, option.WithHTTPClient(config.HTTPClient))
+	}
+
+	if config.Timeout > 0 {
+		opts = append(opts, option.WithRequestTimeout(config.Timeout))
+	}
+
+	return opts
+}
+
 // SendMessage implements LLMProvider.SendMessage
 func (p *Provider) SendMessage(ctx context.Context, req *providers.ChatRequest) (*providers.ChatResponse, error) {
 	// Convert unified request to OpenAI format
 	openaiReq, err := p.adaptChatRequest(req)
 	if err != nil {
-		return nil, providers.WrapProviderError(err, providers.ProviderOpenAI, req.Model)
+		return nil, providers.WrapProviderError(err, p.providerType(), req.Model)
 	}
 
 	// Make OpenAI API call
 	resp, err := p.client.Chat.Completions.New(ctx, *openaiReq)
 	if err != nil {
-		return nil, providers.WrapProviderError(err, providers.ProviderOpenAI, req.Model)
+		return nil, providers.WrapProviderError(apiError(err), p.providerType(), req.Model)
 	}
 
 	// Convert OpenAI response to unified format
-	return p.adaptChatResponse(*resp, req.Model), nil
+	chatResp := p.adaptChatResponse(*resp, req.Model)
+	providers.ShapeCitations(chatResp, providers.CitationOptionsFromConfig(req.Config))
+	return chatResp, nil
 }
 
 // SendMessageStream implements LLMProvider.SendMessageStream
 func (p *Provider) SendMessageStream(ctx context.Context, req *providers.ChatRequest) <-chan providers.StreamEvent {
+	return p.streamCompletion(ctx, req.Model, providers.CitationOptionsFromConfig(req.Config), func() (*openai.ChatCompletionNewParams, error) {
+		return p.adaptChatRequestForStream(req)
+	})
+}
+
+// GenerateJSONStream implements providers.JSONStreamer
+func (p *Provider) GenerateJSONStream(ctx context.Context, req *providers.JSONRequest) <-chan providers.StreamEvent {
+	return p.streamCompletion(ctx, req.Model, providers.CitationOptionsFromConfig(req.Config), func() (*openai.ChatCompletionNewParams, error) {
+		return p.adaptJSONRequest(&providers.ChatRequest{
+			Messages: req.Messages,
+			Model:    req.Model,
+			Provider: p.providerType(),
+			Config:   req.Config,
+			Tags:     req.Tags,
+		}, req.Schema)
+	})
+}
+
+// streamCompletion runs a streaming chat completion built by buildParams,
+// shaping its citations with citationOptions
+func (p *Provider) streamCompletion(ctx context.Context, model string, citationOptions providers.CitationOptions, buildParams func() (*openai.ChatCompletionNewParams, error)) <-chan providers.StreamEvent {
 	eventChan := make(chan providers.StreamEvent, 10)
 
 	go func() {
@@ -82,17 +181,22 @@ func (p *Provider) SendMessageStream(ctx context.Context, req *providers.ChatReq
 		defer func() {
 			if r := recover(); r != nil {
 				err := fmt.Errorf("panic in OpenAI streaming: %v", r)
-				eventChan <- providers.NewErrorEvent(providers.ProviderOpenAI, req.Model, err, false)
+				eventChan <- providers.NewErrorEvent(p.providerType(), model, err, false)
 			}
 		}()
 
 		// Convert to OpenAI streaming request
-		openaiReq, err := p.adaptChatRequestForStream(req)
+		openaiReq, err := buildParams()
 		if err != nil {
-			eventChan <- providers.NewErrorEvent(providers.ProviderOpenAI, req.Model, err, false)
+			eventChan <- providers.NewErrorEvent(p.providerType(), model, err, false)
 			return
 		}
 
+		// Ask for a final usage chunk so streams report token counts
+		openaiReq.StreamOptions = openai.F(openai.ChatCompletionStreamOptionsParam{
+			IncludeUsage: openai.F(true),
+		})
+
 		// Create OpenAI streaming request
 		stream := p.client.Chat.Completions.NewStreaming(ctx, *openaiReq)
 		
@@ -107,22 +211,49 @@ func (p *Provider) SendMessageStream(ctx context.Context, req *providers.ChatReq
 
 		// Check if stream creation failed
 		if stream == nil {
-			eventChan <- providers.NewErrorEvent(providers.ProviderOpenAI, req.Model, 
+			eventChan <- providers.NewErrorEvent(p.providerType(), model, 
 				fmt.Errorf("failed to create streaming request"), false)
 			return
 		}
 
-		// Process streaming chunks
+		// Process streaming chunks. The usage chunk arrives after the finish
+		// reason, so the finished event is held until the stream ends.
+		calls := newToolCallStream()
+		citations := providers.NewCitationStream(citationOptions)
+		var finished *providers.StreamEvent
+		var usage *providers.Usage
 		for stream.Next() {
 			chunk := stream.Current()
-			event := p.adaptStreamChunk(chunk, req.Model)
-			if event != nil {
-				eventChan <- *event
+			for _, event := range p.adaptStreamChunk(chunk, model, calls) {
+				if event.Type == providers.EventFinished {
+					finished = &event
+					continue
+				}
+				for _, shaped := range citations.Shape(event) {
+					eventChan <- shaped
+				}
+			}
+			if chunkUsage := streamUsage(chunk); chunkUsage != nil {
+				usage = chunkUsage
+			}
+		}
+
+		// Calls still pending when a stream ends without a finish reason
+		for _, event := range p.toolCallEvents(calls.flush(), model) {
+			eventChan <- event
+		}
+		if finished != nil {
+			finished.Metadata.Usage = usage
+			for _, shaped := range citations.Shape(*finished) {
+				eventChan <- shaped
 			}
 		}
+		for _, event := range citations.Flush() {
+			eventChan <- event
+		}
 
 		if err := stream.Err(); err != nil {
-			eventChan <- providers.NewErrorEvent(providers.ProviderOpenAI, req.Model, err, false)
+			eventChan <- providers.NewErrorEvent(p.providerType(), model, apiError(err), false)
 		}
 	}()
 
@@ -135,54 +266,91 @@ func (p *Provider) GenerateJSON(ctx context.Context, req *providers.JSONRequest)
 	chatReq := &providers.ChatRequest{
 		Messages: req.Messages,
 		Model:    req.Model,
-		Provider: providers.ProviderOpenAI,
+		Provider: p.providerType(),
 		Config:   req.Config,
+		Tags:     req.Tags,
 	}
 
 	// Add JSON schema to request config
 	// This will be implemented in the adapter
 	openaiReq, err := p.adaptJSONRequest(chatReq, req.Schema)
 	if err != nil {
-		return nil, providers.WrapProviderError(err, providers.ProviderOpenAI, req.Model)
+		return nil, providers.WrapProviderError(err, p.providerType(), req.Model)
 	}
 
 	resp, err := p.client.Chat.Completions.New(ctx, *openaiReq)
 	if err != nil {
-		return nil, providers.WrapProviderError(err, providers.ProviderOpenAI, req.Model)
+		return nil, providers.WrapProviderError(apiError(err), p.providerType(), req.Model)
 	}
 
 	return p.adaptJSONResponse(*resp, req.Model, req.Schema)
 }
 
-// ListModels implements LLMProvider.ListModels
+// ListModels implements LLMProvider.ListModels. The static catalog is served
+// as is unless it is empty or DiscoverModels is set; then the API listing is
+// merged into it, and a failed refresh keeps serving the previous catalog.
 func (p *Provider) ListModels(ctx context.Context) ([]providers.Model, error) {
-	if len(p.models) > 0 {
-		return p.models, nil
+	if !p.catalogStale() {
+		return p.catalog(), nil
 	}
 
 	// Fetch models from OpenAI API
 	models, err := p.client.Models.List(ctx)
 	if err != nil {
-		return nil, providers.WrapProviderError(err, providers.ProviderOpenAI, "")
+		if catalog := p.catalog(); len(catalog) > 0 {
+			return catalog, nil
+		}
+		return nil, providers.WrapProviderError(apiError(err), p.providerType(), "")
 	}
 
 	// Convert OpenAI models to unified format
-	unifiedModels := make([]providers.Model, 0)
+	discovered := make([]providers.Model, 0, len(models.Data))
 	for _, model := range models.Data {
-		unifiedModel := p.adaptModel(model)
-		unifiedModels = append(unifiedModels, unifiedModel)
+		discovered = append(discovered, p.adaptModel(model))
 	}
 
-	p.models = unifiedModels
-	return unifiedModels, nil
+	p.modelsMu.Lock()
+	defer p.modelsMu.Unlock()
+	p.models = providers.MergeModels(p.builtin, discovered)
+	p.modelsFetched = time.Now()
+	return p.models, nil
+}
+
+// catalog returns the current model catalog
+func (p *Provider) catalog() []providers.Model {
+	p.modelsMu.RLock()
+	defer p.modelsMu.RUnlock()
+	return p.models
+}
+
+// catalogStale reports whether ListModels should ask the API: nothing has
+// been listed yet, or discovery is on and the last listing is past its TTL
+func (p *Provider) catalogStale() bool {
+	p.modelsMu.RLock()
+	defer p.modelsMu.RUnlock()
+	if len(p.models) == 0 && p.modelsFetched.IsZero() {
+		return true
+	}
+	if p.config == nil || !p.config.DiscoverModels {
+		return false
+	}
+	ttl := p.config.ModelsTTL
+	if ttl <= 0 {
+		ttl = providers.DefaultModelsTTL
+	}
+	return time.Since(p.modelsFetched) >= ttl
 }
 
 // GetCapabilities implements LLMProvider.GetCapabilities
 func (p *Provider) GetCapabilities() providers.ProviderCapabilities {
+	if p.config != nil && p.config.Models != nil {
+		return compatibleCapabilities(p.config.Models)
+	}
 	return providers.ProviderCapabilities{
 		Models: []string{
-			"gpt-4o", "gpt-4o-mini", "gpt-4-turbo", "gpt-4",
-			"gpt-3.5-turbo", "gpt-3.5-turbo-16k",
+			"gpt-4o", "gpt-4o-mini", "gpt-4o-audio-preview", "gpt-4-turbo", "gpt-4",
+			"gpt-3.5-turbo", "gpt-3.5-turbo-16k", "dall-e-3", "dall-e-2",
+			"tts-1", "tts-1-hd", "whisper-1",
 		},
 		MaxContextSize:      128000, // GPT-4 Turbo context size
 		SupportedMimeTypes:  []string{"text/plain", "image/jpeg", "image/png", "image/gif", "image/webp"},
@@ -195,12 +363,46 @@ func (p *Provider) GetCapabilities() providers.ProviderCapabilities {
 			"function_calling":  "true",
 			"vision":           "true",
 			"json_mode":        "true",
+			"image_generation": "true",
+			"speech":           "true",
+			"transcription":    "true",
 		},
 	}
 }
 
+// compatibleCapabilities derives the capabilities of an OpenAI-compatible
+// service from its model catalog
+func compatibleCapabilities(models []providers.Model) providers.ProviderCapabilities {
+	capabilities := providers.ProviderCapabilities{
+		SupportedMimeTypes: []string{"text/plain"},
+		SupportsStreaming:  true,
+		SpecificFeatures:   map[string]string{},
+	}
+	for _, model := range models {
+		capabilities.Models = append(capabilities.Models, model.ID)
+		if model.ContextSize > capabilities.MaxContextSize {
+			capabilities.MaxContextSize = model.ContextSize
+		}
+		capabilities.SupportsVision = capabilities.SupportsVision || model.Capabilities.ImageInput
+		capabilities.SupportsFunctions = capabilities.SupportsFunctions || model.Capabilities.FunctionCalling
+		capabilities.SupportsJSONMode = capabilities.SupportsJSONMode || model.Capabilities.JSONMode
+		if model.Capabilities.ThinkingMode {
+			capabilities.SpecificFeatures["reasoning"] = "true"
+		}
+	}
+	return capabilities
+}
+
 // GetProviderType implements LLMProvider.GetProviderType
 func (p *Provider) GetProviderType() providers.ProviderType {
+	return p.providerType()
+}
+
+// providerType returns the identity reported on responses, events and errors
+func (p *Provider) providerType() providers.ProviderType {
+	if p.config != nil && p.config.ProviderType != "" {
+		return p.config.ProviderType
+	}
 	return providers.ProviderOpenAI
 }
 
@@ -213,6 +415,11 @@ func (p *Provider) Close() error {
 // Private helper methods
 
 func (p *Provider) initializeModels() {
+	if p.config != nil && p.config.Models != nil {
+		p.models = p.config.Models
+		return
+	}
+
 	// Define common OpenAI models with their capabilities
 	p.models = []providers.Model{
 		{
@@ -228,11 +435,13 @@ func (p *Provider) initializeModels() {
 				Streaming:        true,
 				StructuredOutput: true,
 			},
-			ContextSize: 128000,
+			ContextSize:     128000,
+			MaxOutputTokens: 16384,
 			Cost: &providers.ModelCost{
-				InputTokens:  5.0,  // $5 per 1M input tokens
-				OutputTokens: 15.0, // $15 per 1M output tokens
-				Currency:     "USD",
+				InputTokens:       5.0,  // $5 per 1M input tokens
+				OutputTokens:      15.0, // $15 per 1M output tokens
+				CachedInputTokens: 2.5,  // Prompt cache hits are billed at half price
+				Currency:          "USD",
 			},
 		},
 		{
@@ -248,10 +457,31 @@ func (p *Provider) initializeModels() {
 				Streaming:        true,
 				StructuredOutput: true,
 			},
-			ContextSize: 128000,
+			ContextSize:     128000,
+			MaxOutputTokens: 16384,
 			Cost: &providers.ModelCost{
-				InputTokens:  0.15, // $0.15 per 1M input tokens
-				OutputTokens: 0.6,  // $0.6 per 1M output tokens
+				InputTokens:       0.15,  // $0.15 per 1M input tokens
+				OutputTokens:      0.6,   // $0.6 per 1M output tokens
+				CachedInputTokens: 0.075, // Prompt cache hits are billed at half price
+				Currency:          "USD",
+			},
+		},
+		{
+			ID:       "gpt-4o-audio-preview",
+			Name:     "GPT-4o Audio (Preview)",
+			Provider: providers.ProviderOpenAI,
+			Capabilities: providers.ModelCapabilities{
+				TextGeneration:  true,
+				AudioInput:      true,
+				FunctionCalling: true,
+				SystemMessage:   true,
+				Streaming:       true,
+			},
+			ContextSize:     128000,
+			MaxOutputTokens: 16384,
+			Cost: &providers.ModelCost{
+				InputTokens:  2.5,  // $2.5 per 1M text input tokens
+				OutputTokens: 10.0, // $10 per 1M text output tokens
 				Currency:     "USD",
 			},
 		},
@@ -266,12 +496,63 @@ func (p *Provider) initializeModels() {
 				SystemMessage:   true,
 				Streaming:       true,
 			},
-			ContextSize: 16384,
+			ContextSize:     16384,
+			MaxOutputTokens: 4096,
 			Cost: &providers.ModelCost{
 				InputTokens:  0.5,  // $0.5 per 1M input tokens
 				OutputTokens: 1.5,  // $1.5 per 1M output tokens
 				Currency:     "USD",
 			},
 		},
+		{
+			ID:       "dall-e-3",
+			Name:     "DALL-E 3",
+			Provider: providers.ProviderOpenAI,
+			Capabilities: providers.ModelCapabilities{
+				ImageGeneration: true,
+			},
+			Cost: &providers.ModelCost{
+				PerImage: 0.04, // $0.04 per standard 1024x1024 image
+				Currency: "USD",
+			},
+		},
+		{
+			ID:       "dall-e-2",
+			Name:     "DALL-E 2",
+			Provider: providers.ProviderOpenAI,
+			Capabilities: providers.ModelCapabilities{
+				ImageGeneration: true,
+			},
+			Cost: &providers.ModelCost{
+				PerImage: 0.02, // $0.02 per 1024x1024 image
+				Currency: "USD",
+			},
+		},
+		{
+			ID:       "tts-1",
+			Name:     "TTS 1",
+			Provider: providers.ProviderOpenAI,
+			Capabilities: providers.ModelCapabilities{
+				SpeechGeneration: true,
+				Streaming:        true,
+			},
+		},
+		{
+			ID:       "tts-1-hd",
+			Name:     "TTS 1 HD",
+			Provider: providers.ProviderOpenAI,
+			Capabilities: providers.ModelCapabilities{
+				SpeechGeneration: true,
+				Streaming:        true,
+			},
+		},
+		{
+			ID:       "whisper-1",
+			Name:     "Whisper",
+			Provider: providers.ProviderOpenAI,
+			Capabilities: providers.ModelCapabilities{
+				Transcription: true,
+			},
+		},
 	}
 }
\ No newline at end of file
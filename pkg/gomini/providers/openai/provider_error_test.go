@@ -2,6 +2,13 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -95,4 +102,236 @@ func TestProvider_SendMessage_ErrorHandling(t *testing.T) {
 
 	// If we reach this point, the test passed - no panic occurred
 	t.Log("Success: No panic occurred during network error handling")
-}
\ No newline at end of file
+}
+
+func TestNewProvider_AppliesClientOptions(t *testing.T) {
+	var received *http.Request
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		raw, _ := io.ReadAll(r.Body)
+		json.Unmarshal(raw, &body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4o-mini",
+			"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"hi"}}],
+			"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{
+		APIKey:       "sk-test",
+		BaseURL:      server.URL + "/v1",
+		Organization: "org-123",
+		Project:      "proj-456",
+		ExtraHeaders: map[string]string{"X-Gateway-Key": "secret"},
+		ExtraQuery:   map[string]string{"api-version": "2024-10-21"},
+		ExtraBody:    map[string]interface{}{"route": "fast"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	_, err = provider.SendMessage(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{
+			map[string]interface{}{"role": "user", "content": "Hello"},
+		},
+		Model: "gpt-4o-mini",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	if received == nil {
+		t.Fatal("Expected request to reach the configured base URL")
+	}
+	if received.URL.Path != "/v1/chat/completions" {
+		t.Errorf("Unexpected request path: %s", received.URL.Path)
+	}
+
+	expectedHeaders := map[string]string{
+		"Authorization":       "Bearer sk-test",
+		"OpenAI-Organization": "org-123",
+		"OpenAI-Project":      "proj-456",
+		"X-Gateway-Key":       "secret",
+	}
+	for header, expected := range expectedHeaders {
+		if got := received.Header.Get(header); got != expected {
+			t.Errorf("Expected header %s=%q, got %q", header, expected, got)
+		}
+	}
+	if got := received.URL.Query().Get("api-version"); got != "2024-10-21" {
+		t.Errorf("Expected the extra query parameter, got %q", received.URL.RawQuery)
+	}
+	if body["route"] != "fast" || body["model"] != "gpt-4o-mini" {
+		t.Errorf("Expected the extra body field merged into the request, got %v", body)
+	}
+}
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewProvider_HTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4o-mini",
+			"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"hi"}}]}`))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	provider, err := NewProvider(&Config{
+		APIKey:     "sk-test",
+		BaseURL:    server.URL + "/v1",
+		HTTPClient: &http.Client{Transport: transport},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	_, err = provider.SendMessage(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "Hello"}},
+		Model:    "gpt-4o-mini",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if transport.requests != 1 {
+		t.Errorf("Expected the request to go through the injected client, got %d requests", transport.requests)
+	}
+}
+
+func TestProvider_SendMessageStream_Usage(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"1","object":"chat.completion.chunk","created":1,"model":"gpt-4o","choices":[{"index":0,"delta":{"content":"hi"}}],"usage":null}`,
+			`{"id":"1","object":"chat.completion.chunk","created":1,"model":"gpt-4o","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":null}`,
+			`{"id":"1","object":"chat.completion.chunk","created":1,"model":"gpt-4o","choices":[],"usage":{"prompt_tokens":7,"completion_tokens":2,"total_tokens":9}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{APIKey: "sk-test", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	var events []providers.StreamEvent
+	for event := range provider.SendMessageStream(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "Hello"}},
+		Model:    "gpt-4o",
+	}) {
+		events = append(events, event)
+	}
+
+	if !strings.Contains(string(body), `"stream_options":{"include_usage":true}`) {
+		t.Errorf("expected include_usage in the request, got %s", body)
+	}
+	if len(events) != 2 || events[1].Type != providers.EventFinished {
+		t.Fatalf("expected content then finished, got %+v", events)
+	}
+	usage := events[1].Metadata.Usage
+	if usage == nil || usage.InputTokens != 7 || usage.OutputTokens != 2 || usage.TotalTokens != 9 {
+		t.Errorf("expected the trailing usage on the finished event, got %+v", usage)
+	}
+}
+
+func TestProvider_SendMessageStream_ShapesCitations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"1","object":"chat.completion.chunk","created":1,"model":"gpt-4o-search-preview","choices":[{"index":0,"delta":{"content":"Go 1.23 is out.","annotations":[{"type":"url_citation","url_citation":{"url":"https://go.dev/blog","title":"Go Blog","start_index":0,"end_index":6}}]}}],"usage":null}`,
+			`{"id":"1","object":"chat.completion.chunk","created":1,"model":"gpt-4o-search-preview","choices":[{"index":0,"delta":{"annotations":[{"type":"url_citation","url_citation":{"url":"https://GO.dev/blog/","title":"Go Blog","start_index":7,"end_index":15}},{"type":"url_citation","url_citation":{"url":"https://pkg.go.dev","title":"Packages","start_index":0,"end_index":6}}]}}],"usage":null}`,
+			`{"id":"1","object":"chat.completion.chunk","created":1,"model":"gpt-4o-search-preview","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":null}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{APIKey: "sk-test", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	var events []providers.StreamEvent
+	for event := range provider.SendMessageStream(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "News?"}},
+		Model:    "gpt-4o-search-preview",
+		Config:   map[string]interface{}{"max_citations": 1, "include_raw_citations": true},
+	}) {
+		events = append(events, event)
+	}
+
+	// Citations from both chunks arrive once, deduped and capped, before finished
+	if len(events) != 3 || events[1].Type != providers.EventCitation || events[2].Type != providers.EventFinished {
+		t.Fatalf("expected content, citation, finished, got %+v", events)
+	}
+	citations := events[1].Data.(providers.CitationEvent)
+	if len(citations.Sources) != 1 || citations.Sources[0].URI != "https://go.dev/blog" || len(citations.Sources[0].Spans) != 2 {
+		t.Errorf("expected one merged go.dev citation, got %+v", citations.Sources)
+	}
+	if len(citations.Raw) != 3 {
+		t.Errorf("expected the 3 raw citations, got %+v", citations.Raw)
+	}
+}
+
+func TestProvider_DecodesAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After-Ms", "5")
+		w.Header().Set("X-Request-Id", "req_123")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"This model's maximum context length is 128000 tokens","type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{APIKey: "sk-test", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	_, err = provider.SendMessage(context.Background(), &providers.ChatRequest{
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": "Hello"}},
+		Model:    "gpt-4o-mini",
+	})
+
+	var apiErr *providers.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected a decoded APIError, got %v", err)
+	}
+	if apiErr.StatusCode != 400 || apiErr.Type != "invalid_request_error" || apiErr.Code != "context_length_exceeded" ||
+		apiErr.Param != "messages" || apiErr.Message != "This model's maximum context length is 128000 tokens" {
+		t.Errorf("Unexpected error fields: %+v", apiErr)
+	}
+	if apiErr.RetryAfter != 5*time.Millisecond || apiErr.RequestID != "req_123" {
+		t.Errorf("Expected the retry hint and request ID, got %v and %q", apiErr.RetryAfter, apiErr.RequestID)
+	}
+
+	// The adapter returns the classified LLMError, not a formatted string
+	var llmErr *providers.LLMError
+	if !errors.As(err, &llmErr) || llmErr.Provider != providers.ProviderOpenAI || llmErr.Model != "gpt-4o-mini" {
+		t.Fatalf("Expected an LLMError from the adapter, got %v", err)
+	}
+	if !errors.Is(err, providers.ErrTokenLimitExceeded) || errors.Is(err, providers.ErrRateLimit) {
+		t.Errorf("Expected the error to match only ErrTokenLimitExceeded, got %v", err)
+	}
+
+	// Errors that are not API responses pass through
+	plain := fmt.Errorf("boom")
+	if apiError(plain) != plain {
+		t.Error("Expected other errors to be returned unchanged")
+	}
+}
@@ -0,0 +1,187 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/openai/openai-go"
+	"gomini/pkg/gomini/providers"
+)
+
+const speechChunkSize = 32 * 1024
+
+// MIME types of the speech output formats
+var speechMIMETypes = map[string]string{
+	"mp3":  "audio/mpeg",
+	"opus": "audio/opus",
+	"aac":  "audio/aac",
+	"flac": "audio/flac",
+	"wav":  "audio/wav",
+	"pcm":  "audio/pcm",
+}
+
+// File extensions Whisper uses to detect the upload format
+var transcriptionExtensions = map[string]string{
+	"audio/wav":  ".wav",
+	"audio/mpeg": ".mp3",
+	"audio/mp4":  ".m4a",
+	"audio/ogg":  ".ogg",
+	"audio/webm": ".webm",
+	"audio/flac": ".flac",
+}
+
+// Speech implements providers.AudioProvider.Speech using the TTS models
+func (p *Provider) Speech(ctx context.Context, req *providers.SpeechRequest) (*providers.SpeechResponse, error) {
+	params, mimeType, err := adaptSpeechRequest(req)
+	if err != nil {
+		return nil, providers.WrapProviderError(err, providers.ProviderOpenAI, req.Model)
+	}
+
+	resp, err := p.client.Audio.Speech.New(ctx, params)
+	if err != nil {
+		return nil, providers.WrapProviderError(apiError(err), providers.ProviderOpenAI, params.Model.Value)
+	}
+	defer resp.Body.Close()
+
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, providers.WrapProviderError(fmt.Errorf("failed to read speech audio: %w", err), providers.ProviderOpenAI, params.Model.Value)
+	}
+
+	return &providers.SpeechResponse{
+		Model:    params.Model.Value,
+		Provider: providers.ProviderOpenAI,
+		Audio:    audio,
+		MIMEType: mimeType,
+	}, nil
+}
+
+// SpeechStream implements providers.AudioProvider.SpeechStream, forwarding the
+// response body in chunks as it arrives
+func (p *Provider) SpeechStream(ctx context.Context, req *providers.SpeechRequest) <-chan providers.StreamEvent {
+	eventChan := make(chan providers.StreamEvent, 10)
+
+	go func() {
+		defer close(eventChan)
+
+		params, mimeType, err := adaptSpeechRequest(req)
+		if err != nil {
+			eventChan <- providers.NewErrorEvent(providers.ProviderOpenAI, req.Model, err, false)
+			return
+		}
+		model := params.Model.Value
+
+		resp, err := p.client.Audio.Speech.New(ctx, params)
+		if err != nil {
+			eventChan <- providers.NewErrorEvent(providers.ProviderOpenAI, model, apiError(err), false)
+			return
+		}
+		defer resp.Body.Close()
+
+		buf := make([]byte, speechChunkSize)
+		for sequence := 0; ; sequence++ {
+			n, err := io.ReadFull(resp.Body, buf)
+			if n > 0 {
+				chunk := append([]byte(nil), buf[:n]...)
+				eventChan <- providers.NewAudioChunkEvent(providers.ProviderOpenAI, model, chunk, mimeType, sequence)
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				eventChan <- providers.NewErrorEvent(providers.ProviderOpenAI, model, fmt.Errorf("failed to read speech audio: %w", err), false)
+				return
+			}
+		}
+
+		eventChan <- providers.StreamEvent{
+			Type:      providers.EventFinished,
+			Provider:  providers.ProviderOpenAI,
+			Model:     model,
+			Timestamp: time.Now(),
+			Metadata:  providers.EventMeta{FinishReason: providers.FinishReasonStop},
+		}
+	}()
+
+	return eventChan
+}
+
+// Transcribe implements providers.AudioProvider.Transcribe using Whisper
+func (p *Provider) Transcribe(ctx context.Context, req *providers.TranscriptionRequest) (*providers.TranscriptionResponse, error) {
+	if len(req.Audio.Data) == 0 {
+		return nil, providers.WrapProviderError(fmt.Errorf("transcription audio is empty"), providers.ProviderOpenAI, req.Model)
+	}
+
+	model := req.Model
+	if model == "" {
+		model = openai.AudioModelWhisper1
+	}
+	mimeType := providers.NormalizeAudioMIMEType(req.Audio.MIMEType)
+	fileName := req.FileName
+	if fileName == "" {
+		fileName = "audio" + transcriptionExtensions[mimeType]
+	}
+
+	params := openai.AudioTranscriptionNewParams{
+		File:  openai.FileParam(bytes.NewReader(req.Audio.Data), fileName, mimeType),
+		Model: openai.F(model),
+	}
+	if req.Language != "" {
+		params.Language = openai.F(req.Language)
+	}
+	if req.Prompt != "" {
+		params.Prompt = openai.F(req.Prompt)
+	}
+
+	resp, err := p.client.Audio.Transcriptions.New(ctx, params)
+	if err != nil {
+		return nil, providers.WrapProviderError(apiError(err), providers.ProviderOpenAI, model)
+	}
+
+	return &providers.TranscriptionResponse{
+		Model:    model,
+		Provider: providers.ProviderOpenAI,
+		Text:     resp.Text,
+	}, nil
+}
+
+// adaptSpeechRequest converts a speech request and returns the output MIME type
+func adaptSpeechRequest(req *providers.SpeechRequest) (openai.AudioSpeechNewParams, string, error) {
+	if req.Input == "" {
+		return openai.AudioSpeechNewParams{}, "", fmt.Errorf("speech input is required")
+	}
+
+	model := req.Model
+	if model == "" {
+		model = openai.SpeechModelTTS1
+	}
+	voice := req.Voice
+	if voice == "" {
+		voice = string(openai.AudioSpeechNewParamsVoiceAlloy)
+	}
+	format := req.Format
+	if format == "" {
+		format = "mp3"
+	}
+	mimeType, ok := speechMIMETypes[format]
+	if !ok {
+		return openai.AudioSpeechNewParams{}, "", fmt.Errorf("unsupported speech format %q", format)
+	}
+
+	params := openai.AudioSpeechNewParams{
+		Input:          openai.F(req.Input),
+		Model:          openai.F(model),
+		Voice:          openai.F(openai.AudioSpeechNewParamsVoice(voice)),
+		ResponseFormat: openai.F(openai.AudioSpeechNewParamsResponseFormat(format)),
+	}
+	if req.Speed != 0 {
+		if req.Speed < 0.25 || req.Speed > 4 {
+			return openai.AudioSpeechNewParams{}, "", fmt.Errorf("speech speed %.2f outside 0.25-4.0", req.Speed)
+		}
+		params.Speed = openai.F(req.Speed)
+	}
+	return params, mimeType, nil
+}
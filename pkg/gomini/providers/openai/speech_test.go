@@ -0,0 +1,98 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+)
+
+func TestProvider_Speech(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/speech" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte(strings.Repeat("a", speechChunkSize+10)))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{APIKey: "sk-test", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	resp, err := provider.Speech(context.Background(), &providers.SpeechRequest{Input: "Hello"})
+	if err != nil {
+		t.Fatalf("Speech failed: %v", err)
+	}
+	if len(resp.Audio) != speechChunkSize+10 || resp.MIMEType != "audio/mpeg" || resp.Model != "tts-1" {
+		t.Errorf("Unexpected speech response: model=%s mime=%s bytes=%d", resp.Model, resp.MIMEType, len(resp.Audio))
+	}
+
+	var chunks []int
+	var finished bool
+	for event := range provider.SpeechStream(context.Background(), &providers.SpeechRequest{Input: "Hello"}) {
+		switch event.Type {
+		case providers.EventAudio:
+			chunks = append(chunks, len(event.Data.(providers.AudioChunkEvent).Data))
+		case providers.EventFinished:
+			finished = true
+		case providers.EventError:
+			t.Fatalf("Unexpected error: %v", event.Error)
+		}
+	}
+	if len(chunks) != 2 || chunks[0] != speechChunkSize || chunks[1] != 10 || !finished {
+		t.Errorf("Expected two chunks and a finished event, got %v (finished=%v)", chunks, finished)
+	}
+
+	if _, err := provider.Speech(context.Background(), &providers.SpeechRequest{Input: "Hello", Format: "ogg"}); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestProvider_Transcribe(t *testing.T) {
+	var fileName, model string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			value, _ := io.ReadAll(part)
+			switch part.FormName() {
+			case "file":
+				fileName = part.FileName()
+			case "model":
+				model = string(value)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":"hello world"}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(&Config{APIKey: "sk-test", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	resp, err := provider.Transcribe(context.Background(), &providers.TranscriptionRequest{
+		Audio: providers.AudioPart{MIMEType: "audio/x-wav", Data: []byte("RIFF")},
+	})
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if resp.Text != "hello world" || model != "whisper-1" || fileName != "audio.wav" {
+		t.Errorf("Unexpected transcription: text=%q model=%q file=%q", resp.Text, model, fileName)
+	}
+}
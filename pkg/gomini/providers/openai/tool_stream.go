@@ -0,0 +1,83 @@
+package openai
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/openai/openai-go"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// pendingToolCall is a tool call whose fragments are still arriving
+type pendingToolCall struct {
+	id        string
+	name      string
+	arguments strings.Builder
+}
+
+// toolCallStream accumulates the tool call fragments of one streamed
+// completion. OpenAI sends the id and name in the first fragment of each call
+// and the arguments JSON in pieces, keyed by the call's index.
+type toolCallStream struct {
+	pending map[int64]*pendingToolCall
+}
+
+func newToolCallStream() *toolCallStream {
+	return &toolCallStream{pending: make(map[int64]*pendingToolCall)}
+}
+
+// add records the fragments of a chunk and returns them as deltas
+func (s *toolCallStream) add(deltas []openai.ChatCompletionChunkChoicesDeltaToolCall) []providers.ToolCallDeltaEvent {
+	events := make([]providers.ToolCallDeltaEvent, 0, len(deltas))
+	for _, delta := range deltas {
+		call, ok := s.pending[delta.Index]
+		if !ok {
+			call = &pendingToolCall{}
+			s.pending[delta.Index] = call
+		}
+		if delta.ID != "" {
+			call.id = delta.ID
+		}
+		if call.name == "" {
+			call.name = delta.Function.Name
+		}
+		call.arguments.WriteString(delta.Function.Arguments)
+
+		events = append(events, providers.ToolCallDeltaEvent{
+			Index:          int(delta.Index),
+			CallID:         call.id,
+			ToolName:       call.name,
+			ArgumentsDelta: delta.Function.Arguments,
+		})
+	}
+	return events
+}
+
+// flush returns the pending calls in index order and resets the stream.
+// Arguments that cannot be parsed are kept under "_raw", as for complete
+// responses.
+func (s *toolCallStream) flush() []providers.ToolCall {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	indices := make([]int64, 0, len(s.pending))
+	for index := range s.pending {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	calls := make([]providers.ToolCall, 0, len(indices))
+	for _, index := range indices {
+		call := s.pending[index]
+		raw := call.arguments.String()
+		args, err := providers.ParseToolArguments(raw)
+		if err != nil {
+			args = map[string]interface{}{"_raw": raw}
+		}
+		calls = append(calls, providers.ToolCall{ID: call.id, Name: call.name, Arguments: args})
+	}
+	s.pending = make(map[int64]*pendingToolCall)
+	return calls
+}
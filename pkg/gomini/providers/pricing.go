@@ -0,0 +1,73 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ModelPrice is the price of a model per 1M tokens
+type ModelPrice struct {
+	Input       float64 `json:"input"`
+	Output      float64 `json:"output"`
+	CachedInput float64 `json:"cached_input,omitempty"` // Price of cached input tokens; Input when unset
+	Currency    string  `json:"currency,omitempty"`
+}
+
+// PriceFromCost converts a catalog ModelCost to a ModelPrice
+func PriceFromCost(cost *ModelCost) ModelPrice {
+	if cost == nil {
+		return ModelPrice{}
+	}
+	return ModelPrice{Input: cost.InputTokens, Output: cost.OutputTokens, CachedInput: cost.CachedInputTokens, Currency: cost.Currency}
+}
+
+// Cost returns the cost of usage at this price. Cached input tokens are
+// billed at CachedInput and the rest of the input at Input.
+func (p ModelPrice) Cost(usage *Usage) float64 {
+	if usage == nil {
+		return 0
+	}
+	cachedPrice := p.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = p.Input
+	}
+	cached := usage.CachedInputTokens
+	if cached > usage.InputTokens {
+		cached = usage.InputTokens
+	}
+	uncached := usage.InputTokens - cached
+	return (float64(uncached)*p.Input + float64(cached)*cachedPrice + float64(usage.OutputTokens)*p.Output) / 1e6
+}
+
+// PriceTable maps model IDs to prices. It is loaded from a pricing file of
+// the form {"models": {"gpt-4o": {"input": 2.5, "output": 10}}}.
+type PriceTable struct {
+	Models map[string]ModelPrice `json:"models"`
+}
+
+// ParsePriceTable decodes a pricing file
+func ParsePriceTable(data []byte) (*PriceTable, error) {
+	var table PriceTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("invalid price table: %w", err)
+	}
+	for model, price := range table.Models {
+		if price.Input < 0 || price.Output < 0 || price.CachedInput < 0 {
+			return nil, fmt.Errorf("invalid price table: negative price for %s", model)
+		}
+	}
+	return &table, nil
+}
+
+// Lookup returns the price of a model. Gemini's "models/" resource prefix is ignored.
+func (t *PriceTable) Lookup(model string) (ModelPrice, bool) {
+	if t == nil {
+		return ModelPrice{}, false
+	}
+	price, ok := t.Models[model]
+	if !ok {
+		price, ok = t.Models[strings.TrimPrefix(model, "models/")]
+	}
+	return price, ok
+}
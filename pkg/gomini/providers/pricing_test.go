@@ -0,0 +1,51 @@
+package providers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestModelPrice_Cost(t *testing.T) {
+	price := ModelPrice{Input: 2, Output: 8, CachedInput: 0.5}
+
+	tests := []struct {
+		name  string
+		price ModelPrice
+		usage *Usage
+		want  float64
+	}{
+		{"nil usage", price, nil, 0},
+		{"uncached", price, &Usage{InputTokens: 1_000_000, OutputTokens: 500_000}, 2 + 4},
+		{"half cached", price, &Usage{InputTokens: 1_000_000, CachedInputTokens: 500_000}, 1 + 0.25},
+		{"cached defaults to input", ModelPrice{Input: 2}, &Usage{InputTokens: 1_000_000, CachedInputTokens: 1_000_000}, 2},
+		{"cached capped at input", price, &Usage{InputTokens: 100, CachedInputTokens: 1_000_000}, 100 * 0.5 / 1e6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.price.Cost(tt.usage); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Cost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePriceTable(t *testing.T) {
+	table, err := ParsePriceTable([]byte(`{"models": {"gemini-2.5-flash": {"input": 0.3, "output": 2.5, "cached_input": 0.075}}}`))
+	if err != nil {
+		t.Fatalf("ParsePriceTable failed: %v", err)
+	}
+	price, ok := table.Lookup("models/gemini-2.5-flash")
+	if !ok || price.CachedInput != 0.075 {
+		t.Errorf("Expected the prefixed model to resolve, got %+v, %v", price, ok)
+	}
+	if _, ok := table.Lookup("gpt-4o"); ok {
+		t.Error("Expected unknown models to be missing")
+	}
+
+	if _, err := ParsePriceTable([]byte(`{"models": {"gpt-4o": {"input": -1}}}`)); err == nil {
+		t.Error("Expected negative prices to be rejected")
+	}
+	if _, err := ParsePriceTable([]byte(`not json`)); err == nil {
+		t.Error("Expected malformed tables to be rejected")
+	}
+}
@@ -2,7 +2,6 @@ package providers
 
 import (
 	"context"
-	"fmt"
 	"time"
 )
 
@@ -10,8 +9,11 @@ import (
 type ProviderType string
 
 const (
-	ProviderOpenAI ProviderType = "openai"
-	ProviderGemini ProviderType = "gemini"
+	ProviderOpenAI   ProviderType = "openai"
+	ProviderGemini   ProviderType = "gemini"
+	ProviderGroq     ProviderType = "groq"     // OpenAI-compatible
+	ProviderDeepSeek ProviderType = "deepseek" // OpenAI-compatible
+	ProviderMock     ProviderType = "mock"     // Scripted scenarios for testing
 )
 
 // LLMProvider defines the unified interface for all LLM providers
@@ -38,34 +40,58 @@ type LLMProvider interface {
 	Close() error
 }
 
+// JSONStreamer is implemented by providers that can stream structured JSON output
+type JSONStreamer interface {
+	// GenerateJSONStream streams the raw JSON text as content events
+	GenerateJSONStream(ctx context.Context, req *JSONRequest) <-chan StreamEvent
+}
+
 // Model represents an available model
 type Model struct {
-	ID           string            `json:"id"`
-	Name         string            `json:"name"`
-	Provider     ProviderType      `json:"provider"`
-	Capabilities ModelCapabilities `json:"capabilities"`
-	ContextSize  int               `json:"context_size"`
-	Cost         *ModelCost        `json:"cost,omitempty"`
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Provider        ProviderType      `json:"provider"`
+	Capabilities    ModelCapabilities `json:"capabilities"`
+	ContextSize     int               `json:"context_size"`
+	MaxOutputTokens int               `json:"max_output_tokens,omitempty"` // Longest reply the model can produce; 0 if unknown
+	Cost            *ModelCost        `json:"cost,omitempty"`
 }
 
 // ModelCapabilities defines what a model can do
 type ModelCapabilities struct {
 	TextGeneration   bool `json:"text_generation"`
 	ImageInput       bool `json:"image_input"`
+	AudioInput       bool `json:"audio_input,omitempty"`
+	DocumentInput    bool `json:"document_input,omitempty"` // PDFs and other documents
 	ImageGeneration  bool `json:"image_generation"`
+	SpeechGeneration bool `json:"speech_generation,omitempty"` // Text-to-speech
+	Transcription    bool `json:"transcription,omitempty"`     // Speech-to-text
 	FunctionCalling  bool `json:"function_calling"`
 	JSONMode         bool `json:"json_mode"`
 	SystemMessage    bool `json:"system_message"`
 	Streaming        bool `json:"streaming"`
 	ThinkingMode     bool `json:"thinking_mode,omitempty"`     // Gemini-specific
-	StructuredOutput bool `json:"structured_output,omitempty"` // OpenAI-specific
+	Reasoning        bool `json:"reasoning,omitempty"`         // Accepts a Reasoning effort or thinking budget
+	StructuredOutput bool `json:"structured_output,omitempty"` // Server-side schema enforcement
 }
 
 // ModelCost represents the cost structure for a model
 type ModelCost struct {
-	InputTokens  float64 `json:"input_tokens"`  // Cost per 1K input tokens
-	OutputTokens float64 `json:"output_tokens"` // Cost per 1K output tokens
-	Currency     string  `json:"currency"`      // USD, etc.
+	InputTokens       float64 `json:"input_tokens"`                  // Cost per 1M input tokens
+	OutputTokens      float64 `json:"output_tokens"`                 // Cost per 1M output tokens
+	CachedInputTokens float64 `json:"cached_input_tokens,omitempty"` // Cost per 1M prompt-cached input tokens; InputTokens when unset
+	PerImage          float64 `json:"per_image,omitempty"`           // Cost per generated image
+	Currency          string  `json:"currency"`                      // USD, etc.
+}
+
+// UsageCost estimates the cost of usage on a model from its catalog entry.
+// Catalog token prices are per 1M tokens; unknown models cost 0.
+func UsageCost(models []Model, modelID string, usage *Usage) float64 {
+	model, ok := FindModel(models, modelID)
+	if !ok || model.Cost == nil {
+		return 0
+	}
+	return PriceFromCost(model.Cost).Cost(usage)
 }
 
 // ProviderCapabilities defines what a provider supports
@@ -96,6 +122,7 @@ type Usage struct {
 	TotalTokens      int `json:"total_tokens"`
 	CompletionTokens int `json:"completion_tokens,omitempty"` // OpenAI terminology
 	PromptTokens     int `json:"prompt_tokens,omitempty"`     // OpenAI terminology
+	CachedInputTokens int `json:"cached_input_tokens,omitempty"` // Input tokens served from the provider's prompt cache
 }
 
 // FinishReason indicates why generation stopped
@@ -118,7 +145,13 @@ type RequestConfig interface{}
 
 type Tool interface{}
 
-type Choice interface{}
+// ToolCall is a tool invocation requested by the model, stored on assistant
+// messages under the "tool_calls" key
+type ToolCall struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
 
 // Common types that providers need to work with
 
@@ -129,6 +162,10 @@ type ChatRequest struct {
 	Config      RequestConfig `json:"config,omitempty"`
 	Tools       []Tool        `json:"tools,omitempty"`
 	ToolChoice  interface{}   `json:"tool_choice,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"` // Usage attribution, forwarded as provider metadata/labels
+	DisabledTools []string    `json:"disabled_tools,omitempty"` // Tool names withheld for this turn; removed from Tools before sending
+	Persona     string        `json:"persona,omitempty"` // Name of a Config.Personas preset applied by the client
+	Require     *Requirements `json:"require,omitempty"` // Capabilities the model must have; picks the cheapest fitting model when Model is empty
 }
 
 type ChatResponse struct {
@@ -138,6 +175,12 @@ type ChatResponse struct {
 	Choices  []Choice     `json:"choices"`
 	Usage    *Usage       `json:"usage,omitempty"`
 	Created  int64        `json:"created,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"` // Provider extras, e.g. raw_citations
+	Cached   bool         `json:"cached,omitempty"` // Served from the client's response cache
+	CacheKey string       `json:"cache_key,omitempty"` // Response cache entry holding this response, for Client.InvalidateCachedResponse
+	Stale    bool         `json:"stale,omitempty"` // Cached past the soft TTL and being refreshed in the background
+	Safety   *SafetyInfo  `json:"safety,omitempty"` // Safety ratings and block reasons, where the provider reports them
+	SystemFingerprint string `json:"system_fingerprint,omitempty"` // Backend configuration that served the request, where the provider reports it
 }
 
 type JSONRequest struct {
@@ -146,6 +189,7 @@ type JSONRequest struct {
 	Provider ProviderType           `json:"provider,omitempty"`
 	Schema   map[string]interface{} `json:"schema"`
 	Config   RequestConfig          `json:"config,omitempty"`
+	Tags     map[string]string      `json:"tags,omitempty"`
 }
 
 type JSONResponse struct {
@@ -155,22 +199,10 @@ type JSONResponse struct {
 	Data     map[string]interface{} `json:"data"`
 	Usage    *Usage                 `json:"usage,omitempty"`
 	Created  int64                  `json:"created,omitempty"`
-}
-
-// Forward declarations and helper functions
-
-// NewLLMError creates a new LLMError (to be implemented in errors.go)
-func NewLLMError(code string, message string, provider ProviderType, cause error) error {
-	// Placeholder implementation
-	return fmt.Errorf("[%s:%s] %s", provider, code, message)
-}
-
-// WrapProviderError wraps a provider error (to be implemented in errors.go)  
-func WrapProviderError(err error, provider ProviderType, model string) error {
-	if err == nil {
-		return nil
-	}
-	return fmt.Errorf("[%s:%s] %w", provider, model, err)
+	Metadata map[string]interface{} `json:"metadata,omitempty"` // e.g. MetadataJSONRepaired
+	Cached   bool                   `json:"cached,omitempty"`    // Served from the client's response cache
+	CacheKey string                 `json:"cache_key,omitempty"` // Response cache entry holding this response, for Client.InvalidateCachedResponse
+	Stale    bool                   `json:"stale,omitempty"`     // Cached past the soft TTL and being refreshed in the background
 }
 
 // Event types and helper functions
@@ -180,6 +212,9 @@ const (
 	EventContent        EventType = "content"
 	EventThought        EventType = "thought"
 	EventToolCall       EventType = "tool_call"
+	EventToolCallDelta  EventType = "tool_call_delta"
+	EventImage          EventType = "image"
+	EventCitation       EventType = "citation"
 	EventFinished       EventType = "finished"
 	EventError          EventType = "error"
 	EventProviderSwitch EventType = "provider_switch"
@@ -199,12 +234,16 @@ type StreamEvent struct {
 type EventMeta struct {
 	FinishReason FinishReason `json:"finish_reason,omitempty"`
 	Usage        *Usage       `json:"usage,omitempty"`
+	Safety       *SafetyInfo  `json:"safety,omitempty"`
+	SystemFingerprint string  `json:"system_fingerprint,omitempty"`
+	Latency      *StreamLatency `json:"latency,omitempty"` // Set by the client on finished events
 }
 
 type ContentEvent struct {
 	Text     string `json:"text"`
 	Delta    bool   `json:"delta"`
 	Complete bool   `json:"complete"`
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"` // Log probabilities of the tokens in Text, when requested
 }
 
 type ThoughtEvent struct {
@@ -213,6 +252,15 @@ type ThoughtEvent struct {
 	Text        string `json:"text,omitempty"`
 }
 
+// ToolCallDeltaEvent is a fragment of a tool call still being streamed. The
+// complete call follows as an EventToolCall once it is finalized.
+type ToolCallDeltaEvent struct {
+	Index          int    `json:"index"`
+	CallID         string `json:"call_id,omitempty"`
+	ToolName       string `json:"tool_name,omitempty"`
+	ArgumentsDelta string `json:"arguments_delta,omitempty"`
+}
+
 type SafetySetting struct {
 	Category  string `json:"category"`
 	Threshold string `json:"threshold"`
@@ -238,11 +286,3 @@ func NewContentEvent(provider ProviderType, model, text string, delta bool) Stre
 		Timestamp: time.Now(),
 	}
 }
-
-// Error codes (to match main errors.go)
-const (
-	ErrorInvalidAPIKey  = "invalid_api_key"
-	ErrorInvalidAuth    = "invalid_auth" 
-	ErrorInvalidRequest = "invalid_request"
-	ErrorProviderNotFound = "provider_not_found"
-)
\ No newline at end of file
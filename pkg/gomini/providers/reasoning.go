@@ -0,0 +1,70 @@
+package providers
+
+// ConfigReasoning is the request config key holding a Reasoning
+const ConfigReasoning = "reasoning"
+
+// ReasoningEffort is a coarse reasoning spend level
+type ReasoningEffort string
+
+const (
+	ReasoningEffortLow    ReasoningEffort = "low"
+	ReasoningEffortMedium ReasoningEffort = "medium"
+	ReasoningEffortHigh   ReasoningEffort = "high"
+)
+
+// reasoningEffortBudgets is the thinking budget standing in for each effort
+var reasoningEffortBudgets = map[ReasoningEffort]int{
+	ReasoningEffortLow:    1024,
+	ReasoningEffortMedium: 8192,
+	ReasoningEffortHigh:   24576,
+}
+
+// Reasoning controls how much a model thinks before answering. Providers
+// take either an effort level (OpenAI reasoning_effort) or a token budget
+// (Gemini thinking budget); whichever is missing is derived from the other.
+type Reasoning struct {
+	Effort          ReasoningEffort `json:"effort,omitempty"`
+	BudgetTokens    int             `json:"budget_tokens,omitempty"`
+	IncludeThoughts bool            `json:"include_thoughts,omitempty"` // Return thought summaries where supported
+}
+
+// EffortLevel returns the effort, derived from BudgetTokens if unset
+func (r Reasoning) EffortLevel() ReasoningEffort {
+	if r.Effort != "" {
+		return r.Effort
+	}
+	switch {
+	case r.BudgetTokens <= reasoningEffortBudgets[ReasoningEffortLow]:
+		return ReasoningEffortLow
+	case r.BudgetTokens <= reasoningEffortBudgets[ReasoningEffortMedium]:
+		return ReasoningEffortMedium
+	default:
+		return ReasoningEffortHigh
+	}
+}
+
+// Budget returns the token budget, derived from Effort if unset
+func (r Reasoning) Budget() int {
+	if r.BudgetTokens > 0 || r.Effort == "" {
+		return r.BudgetTokens
+	}
+	return reasoningEffortBudgets[r.Effort]
+}
+
+// ReasoningFromConfig reads the Reasoning stored under ConfigReasoning in a
+// request config map
+func ReasoningFromConfig(config RequestConfig) (Reasoning, bool) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return Reasoning{}, false
+	}
+	switch reasoning := configMap[ConfigReasoning].(type) {
+	case Reasoning:
+		return reasoning, true
+	case *Reasoning:
+		if reasoning != nil {
+			return *reasoning, true
+		}
+	}
+	return Reasoning{}, false
+}
@@ -0,0 +1,44 @@
+package providers
+
+import "testing"
+
+func TestReasoning_EffortAndBudget(t *testing.T) {
+	tests := []struct {
+		reasoning Reasoning
+		effort    ReasoningEffort
+		budget    int
+	}{
+		{Reasoning{Effort: ReasoningEffortMedium}, ReasoningEffortMedium, 8192},
+		{Reasoning{BudgetTokens: 512}, ReasoningEffortLow, 512},
+		{Reasoning{BudgetTokens: 4096}, ReasoningEffortMedium, 4096},
+		{Reasoning{BudgetTokens: 32768}, ReasoningEffortHigh, 32768},
+		{Reasoning{Effort: ReasoningEffortLow, BudgetTokens: 2000}, ReasoningEffortLow, 2000},
+		{Reasoning{IncludeThoughts: true}, ReasoningEffortLow, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.reasoning.EffortLevel(); got != tt.effort {
+			t.Errorf("%+v: expected effort %s, got %s", tt.reasoning, tt.effort, got)
+		}
+		if got := tt.reasoning.Budget(); got != tt.budget {
+			t.Errorf("%+v: expected budget %d, got %d", tt.reasoning, tt.budget, got)
+		}
+	}
+}
+
+func TestReasoningFromConfig(t *testing.T) {
+	want := Reasoning{Effort: ReasoningEffortHigh}
+	for _, config := range []RequestConfig{
+		map[string]interface{}{ConfigReasoning: want},
+		map[string]interface{}{ConfigReasoning: &want},
+	} {
+		if got, ok := ReasoningFromConfig(config); !ok || got != want {
+			t.Errorf("expected %+v from %+v, got %+v", want, config, got)
+		}
+	}
+	if _, ok := ReasoningFromConfig(map[string]interface{}{"temperature": 0.2}); ok {
+		t.Error("expected no reasoning without the key")
+	}
+	if _, ok := ReasoningFromConfig(nil); ok {
+		t.Error("expected no reasoning from a nil config")
+	}
+}
@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// DetailRemediations is the LLMError.Details key holding remediation hints
+const DetailRemediations = "remediations"
+
+// RemediationAction names something a caller can do to make a rejected request succeed
+type RemediationAction string
+
+const (
+	RemediationSwitchModel  RemediationAction = "switch_model"  // Retry on Provider/Model
+	RemediationReducePrompt RemediationAction = "reduce_prompt" // Drop at least Tokens tokens
+	RemediationRemoveInput  RemediationAction = "remove_input"  // Drop the Input modality
+	RemediationEnableTool   RemediationAction = "enable_tool"   // Re-enable the tool named by Input
+	RemediationRaiseBudget  RemediationAction = "raise_budget"  // Raise or reset the session budget
+)
+
+// Remediation is a machine-readable hint attached to a rejected request
+type Remediation struct {
+	Action   RemediationAction `json:"action"`
+	Provider ProviderType      `json:"provider,omitempty"`
+	Model    string            `json:"model,omitempty"`
+	Tokens   int               `json:"tokens,omitempty"`
+	Input    string            `json:"input,omitempty"` // Modality ("audio") or tool name
+	Hint     string            `json:"hint"`            // Human-readable summary
+}
+
+// WithRemediation appends remediation hints to the error's details and returns the error
+func (e *LLMError) WithRemediation(remediations ...Remediation) *LLMError {
+	if len(remediations) == 0 {
+		return e
+	}
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[DetailRemediations] = append(e.Remediations(), remediations...)
+	return e
+}
+
+// Remediations returns the error's remediation hints. Details decoded from
+// JSON are converted back into typed hints.
+func (e *LLMError) Remediations() []Remediation {
+	switch value := e.Details[DetailRemediations].(type) {
+	case nil:
+		return nil
+	case []Remediation:
+		return value
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+		var remediations []Remediation
+		if err := json.Unmarshal(data, &remediations); err != nil {
+			return nil
+		}
+		return remediations
+	}
+}
+
+// Remediations returns the remediation hints of the first LLMError in err's chain
+func Remediations(err error) []Remediation {
+	var llmErr *LLMError
+	if !errors.As(err, &llmErr) {
+		return nil
+	}
+	return llmErr.Remediations()
+}
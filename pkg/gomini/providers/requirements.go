@@ -0,0 +1,45 @@
+package providers
+
+import "fmt"
+
+// Requirements are capabilities a request needs from its model. The client
+// routes a request without a model to the cheapest enabled model meeting
+// them, and rejects a request whose model does not.
+type Requirements struct {
+	Vision           bool `json:"vision,omitempty"`
+	AudioInput       bool `json:"audio_input,omitempty"`
+	DocumentInput    bool `json:"document_input,omitempty"`
+	FunctionCalling  bool `json:"function_calling,omitempty"`
+	JSONMode         bool `json:"json_mode,omitempty"`
+	StructuredOutput bool `json:"structured_output,omitempty"`
+	Reasoning        bool `json:"reasoning,omitempty"`
+	MinContext       int  `json:"min_context,omitempty"`       // Context window in tokens
+	MinOutputTokens  int  `json:"min_output_tokens,omitempty"` // Longest reply in tokens
+}
+
+// Missing lists the requirements model does not meet, empty when it meets
+// them all. Only text generation models qualify.
+func (r Requirements) Missing(model Model) []string {
+	var missing []string
+	check := func(required, has bool, name string) {
+		if required && !has {
+			missing = append(missing, name)
+		}
+	}
+	capabilities := model.Capabilities
+	check(true, capabilities.TextGeneration, "text generation")
+	check(r.Vision, capabilities.ImageInput, "vision")
+	check(r.AudioInput, capabilities.AudioInput, "audio input")
+	check(r.DocumentInput, capabilities.DocumentInput, "document input")
+	check(r.FunctionCalling, capabilities.FunctionCalling, "function calling")
+	check(r.JSONMode, capabilities.JSONMode, "JSON mode")
+	check(r.StructuredOutput, capabilities.StructuredOutput, "structured output")
+	check(r.Reasoning, capabilities.Reasoning, "reasoning")
+	if r.MinContext > 0 && model.ContextSize < r.MinContext {
+		missing = append(missing, fmt.Sprintf("%d-token context", r.MinContext))
+	}
+	if r.MinOutputTokens > 0 && model.MaxOutputTokens < r.MinOutputTokens {
+		missing = append(missing, fmt.Sprintf("%d output tokens", r.MinOutputTokens))
+	}
+	return missing
+}
@@ -0,0 +1,31 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequirements_Missing(t *testing.T) {
+	model := Model{
+		Capabilities:    ModelCapabilities{TextGeneration: true, ImageInput: true, JSONMode: true},
+		ContextSize:     128000,
+		MaxOutputTokens: 16384,
+	}
+	tests := []struct {
+		require Requirements
+		want    []string
+	}{
+		{Requirements{}, nil},
+		{Requirements{Vision: true, JSONMode: true, MinContext: 128000}, nil},
+		{Requirements{Vision: true, FunctionCalling: true, MinContext: 200000}, []string{"function calling", "200000-token context"}},
+		{Requirements{MinOutputTokens: 32000}, []string{"32000 output tokens"}},
+	}
+	for _, tt := range tests {
+		if got := tt.require.Missing(model); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Missing(%+v) = %v, want %v", tt.require, got, tt.want)
+		}
+	}
+	if got := (Requirements{}).Missing(Model{ID: "dall-e-3"}); !reflect.DeepEqual(got, []string{"text generation"}) {
+		t.Errorf("expected image models to lack text generation, got %v", got)
+	}
+}
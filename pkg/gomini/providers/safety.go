@@ -0,0 +1,44 @@
+package providers
+
+// SafetyRating is a provider's assessment of one harm category
+type SafetyRating struct {
+	Category    string  `json:"category"`
+	Probability string  `json:"probability,omitempty"` // e.g. NEGLIGIBLE, LOW, MEDIUM, HIGH
+	Severity    string  `json:"severity,omitempty"`
+	Score       float64 `json:"score,omitempty"` // Probability score from 0 to 1, where reported
+	Blocked     bool    `json:"blocked,omitempty"`
+}
+
+// SafetyInfo explains how a provider rated or filtered a prompt and its
+// response, so apps can tell users why an answer is missing
+type SafetyInfo struct {
+	Ratings       []SafetyRating `json:"ratings,omitempty"`        // Ratings of the response
+	FinishReason  string         `json:"finish_reason,omitempty"`  // Raw provider reason, e.g. SAFETY or RECITATION
+	BlockReason   string         `json:"block_reason,omitempty"`   // Why the prompt was blocked
+	BlockMessage  string         `json:"block_message,omitempty"`  // Provider's explanation of the block
+	PromptRatings []SafetyRating `json:"prompt_ratings,omitempty"` // Ratings of the prompt
+}
+
+// PromptBlocked reports whether the prompt itself was rejected
+func (s *SafetyInfo) PromptBlocked() bool {
+	return s != nil && s.BlockReason != ""
+}
+
+// BlockedCategories returns the categories flagged as blocked in the prompt
+// or response ratings
+func (s *SafetyInfo) BlockedCategories() []string {
+	if s == nil {
+		return nil
+	}
+	var categories []string
+	seen := make(map[string]bool)
+	for _, ratings := range [][]SafetyRating{s.PromptRatings, s.Ratings} {
+		for _, rating := range ratings {
+			if rating.Blocked && !seen[rating.Category] {
+				seen[rating.Category] = true
+				categories = append(categories, rating.Category)
+			}
+		}
+	}
+	return categories
+}
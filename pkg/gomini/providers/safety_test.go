@@ -0,0 +1,21 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSafetyInfo_BlockedCategories(t *testing.T) {
+	info := &SafetyInfo{
+		PromptRatings: []SafetyRating{{Category: "HARASSMENT", Blocked: true}, {Category: "HATE_SPEECH"}},
+		Ratings:       []SafetyRating{{Category: "HARASSMENT", Blocked: true}, {Category: "DANGEROUS_CONTENT", Blocked: true}},
+	}
+	if got := info.BlockedCategories(); !reflect.DeepEqual(got, []string{"HARASSMENT", "DANGEROUS_CONTENT"}) {
+		t.Errorf("unexpected categories %v", got)
+	}
+
+	var none *SafetyInfo
+	if none.PromptBlocked() || none.BlockedCategories() != nil {
+		t.Error("expected a nil SafetyInfo to report nothing")
+	}
+}
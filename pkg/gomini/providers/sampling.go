@@ -0,0 +1,106 @@
+package providers
+
+// ConfigSeed is the request config key holding an int seed for best-effort
+// deterministic sampling. Providers that cannot honour it reject the request
+// with ErrorUnsupportedFeature rather than sample randomly.
+const ConfigSeed = "seed"
+
+// SeedFromConfig reads the seed from a request config map, reporting whether
+// one was set
+func SeedFromConfig(config RequestConfig) (int64, bool) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	switch value := configMap[ConfigSeed].(type) {
+	case int:
+		return int64(value), true
+	case int64:
+		return value, true
+	case float64:
+		return int64(value), true
+	}
+	return 0, false
+}
+
+// Request config keys for stop sequences and repetition penalties, named as
+// in OpenAI's API
+const (
+	ConfigStop             = "stop"              // []string, or a single string
+	ConfigFrequencyPenalty = "frequency_penalty" // float64; positive values discourage repeating tokens by how often they appeared
+	ConfigPresencePenalty  = "presence_penalty"  // float64; positive values discourage any token that already appeared
+)
+
+// StopFromConfig reads the stop sequences from a request config map,
+// accepting a string, []string or a decoded JSON array
+func StopFromConfig(config RequestConfig) []string {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	switch value := configMap[ConfigStop].(type) {
+	case string:
+		if value != "" {
+			return []string{value}
+		}
+	case []string:
+		return value
+	case []interface{}:
+		stop := make([]string, 0, len(value))
+		for _, item := range value {
+			if sequence, ok := item.(string); ok {
+				stop = append(stop, sequence)
+			}
+		}
+		return stop
+	}
+	return nil
+}
+
+// FrequencyPenaltyFromConfig reads frequency_penalty from a request config
+// map, reporting whether it was set
+func FrequencyPenaltyFromConfig(config RequestConfig) (float64, bool) {
+	return floatFromConfig(config, ConfigFrequencyPenalty)
+}
+
+// PresencePenaltyFromConfig reads presence_penalty from a request config
+// map, reporting whether it was set
+func PresencePenaltyFromConfig(config RequestConfig) (float64, bool) {
+	return floatFromConfig(config, ConfigPresencePenalty)
+}
+
+func floatFromConfig(config RequestConfig, key string) (float64, bool) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	switch value := configMap[key].(type) {
+	case float64:
+		return value, true
+	case float32:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	}
+	return 0, false
+}
+
+// Request config keys capping the reply length. max_tokens is OpenAI's name
+// and is read when max_output_tokens is unset.
+const (
+	ConfigMaxOutputTokens = "max_output_tokens"
+	ConfigMaxTokens       = "max_tokens"
+)
+
+// MaxOutputTokensFromConfig reads the output token cap from a request config
+// map, reporting whether one was set
+func MaxOutputTokensFromConfig(config RequestConfig) (int, bool) {
+	for _, key := range []string{ConfigMaxOutputTokens, ConfigMaxTokens} {
+		if value, ok := floatFromConfig(config, key); ok {
+			return int(value), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,62 @@
+package providers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStopFromConfig(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  []string
+	}{
+		{"END", []string{"END"}},
+		{[]string{"\n\n", "END"}, []string{"\n\n", "END"}},
+		{[]interface{}{"END", 3}, []string{"END"}},
+		{"", nil},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		if got := StopFromConfig(map[string]interface{}{ConfigStop: tt.value}); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("StopFromConfig(%#v) = %#v, want %#v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSamplingFromConfig(t *testing.T) {
+	config := map[string]interface{}{ConfigSeed: 7.0, ConfigFrequencyPenalty: 1, ConfigPresencePenalty: 0.5}
+	if seed, ok := SeedFromConfig(config); !ok || seed != 7 {
+		t.Errorf("expected seed 7, got %d (%v)", seed, ok)
+	}
+	if penalty, ok := FrequencyPenaltyFromConfig(config); !ok || penalty != 1 {
+		t.Errorf("expected frequency penalty 1, got %v (%v)", penalty, ok)
+	}
+	if penalty, ok := PresencePenaltyFromConfig(config); !ok || penalty != 0.5 {
+		t.Errorf("expected presence penalty 0.5, got %v (%v)", penalty, ok)
+	}
+
+	if _, ok := SeedFromConfig(map[string]interface{}{}); ok {
+		t.Error("expected no seed without the key")
+	}
+	if _, ok := PresencePenaltyFromConfig(nil); ok {
+		t.Error("expected no penalty without a config")
+	}
+}
+
+func TestMaxOutputTokensFromConfig(t *testing.T) {
+	tests := []struct {
+		config map[string]interface{}
+		want   int
+		ok     bool
+	}{
+		{map[string]interface{}{ConfigMaxOutputTokens: 256}, 256, true},
+		{map[string]interface{}{ConfigMaxTokens: 512.0}, 512, true},
+		{map[string]interface{}{ConfigMaxOutputTokens: 256, ConfigMaxTokens: 512}, 256, true},
+		{map[string]interface{}{}, 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := MaxOutputTokensFromConfig(tt.config); got != tt.want || ok != tt.ok {
+			t.Errorf("MaxOutputTokensFromConfig(%v) = %d, %v, want %d, %v", tt.config, got, ok, tt.want, tt.ok)
+		}
+	}
+}
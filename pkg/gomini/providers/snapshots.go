@@ -0,0 +1,32 @@
+package providers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LatestSnapshotSuffix marks a model name to be resolved to the newest dated
+// snapshot in the provider's catalog, e.g. "gpt-4o@latest"
+const LatestSnapshotSuffix = "@latest"
+
+// snapshotVersion matches the suffixes providers give pinned model versions:
+// dates such as 2024-08-06 or 0613, and revisions such as 002
+var snapshotVersion = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}|\d{3,8})$`)
+
+// LatestSnapshot returns the catalog ID of the newest snapshot of base, such
+// as gpt-4o-2024-11-20 for gpt-4o. Gemini's "models/" prefix is ignored when
+// matching. It reports false when the catalog has no snapshot of base.
+func LatestSnapshot(models []Model, base string) (string, bool) {
+	base = strings.TrimPrefix(base, "models/")
+	var latest, latestVersion string
+	for _, model := range models {
+		version, ok := strings.CutPrefix(strings.TrimPrefix(model.ID, "models/"), base+"-")
+		if !ok || !snapshotVersion.MatchString(version) {
+			continue
+		}
+		if latest == "" || version > latestVersion {
+			latest, latestVersion = model.ID, version
+		}
+	}
+	return latest, latest != ""
+}
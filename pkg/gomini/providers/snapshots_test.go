@@ -0,0 +1,30 @@
+package providers
+
+import "testing"
+
+func TestLatestSnapshot(t *testing.T) {
+	models := []Model{
+		{ID: "gpt-4o"},
+		{ID: "gpt-4o-2024-05-13"},
+		{ID: "gpt-4o-2024-11-20"},
+		{ID: "gpt-4o-mini-2024-07-18"},
+		{ID: "gpt-4o-audio-preview"},
+		{ID: "models/gemini-1.5-pro-001"},
+		{ID: "models/gemini-1.5-pro-002"},
+	}
+	tests := []struct {
+		base string
+		want string
+		ok   bool
+	}{
+		{"gpt-4o", "gpt-4o-2024-11-20", true},
+		{"gpt-4o-mini", "gpt-4o-mini-2024-07-18", true},
+		{"gemini-1.5-pro", "models/gemini-1.5-pro-002", true},
+		{"gpt-3.5-turbo", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := LatestSnapshot(models, tt.base); got != tt.want || ok != tt.ok {
+			t.Errorf("LatestSnapshot(%q) = %q, %v, want %q, %v", tt.base, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"context"
+	"time"
+)
+
+// AudioProvider is implemented by providers with text-to-speech and
+// speech-to-text support
+type AudioProvider interface {
+	// Speech synthesizes the request's input text into audio
+	Speech(ctx context.Context, req *SpeechRequest) (*SpeechResponse, error)
+
+	// SpeechStream synthesizes audio and delivers it as EventAudio chunks,
+	// followed by EventFinished or EventError
+	SpeechStream(ctx context.Context, req *SpeechRequest) <-chan StreamEvent
+
+	// Transcribe converts speech audio into text
+	Transcribe(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResponse, error)
+}
+
+// EventAudio carries a chunk of synthesized audio as AudioChunkEvent data
+const EventAudio EventType = "audio"
+
+// SpeechRequest asks a model to read text aloud
+type SpeechRequest struct {
+	Input    string       `json:"input"`
+	Model    string       `json:"model"`
+	Provider ProviderType `json:"provider,omitempty"`
+	Voice    string       `json:"voice,omitempty"`  // Provider voice name, e.g. "alloy" or "Kore"
+	Format   string       `json:"format,omitempty"` // Output format such as "mp3" or "wav" (OpenAI)
+	Speed    float64      `json:"speed,omitempty"`  // 0.25 to 4.0 (OpenAI)
+}
+
+// SpeechResponse holds synthesized audio
+type SpeechResponse struct {
+	Model    string       `json:"model"`
+	Provider ProviderType `json:"provider"`
+	Audio    []byte       `json:"audio"`
+	MIMEType string       `json:"mime_type"`
+	Usage    *Usage       `json:"usage,omitempty"`
+}
+
+// AudioChunkEvent is a piece of synthesized audio delivered during SpeechStream
+type AudioChunkEvent struct {
+	Data     []byte `json:"data"`
+	MIMEType string `json:"mime_type"`
+	Sequence int    `json:"sequence"`
+}
+
+// TranscriptionRequest asks a model to transcribe speech
+type TranscriptionRequest struct {
+	Audio    AudioPart    `json:"audio"`
+	FileName string       `json:"file_name,omitempty"` // Helps providers that infer the format from the name
+	Model    string       `json:"model"`
+	Provider ProviderType `json:"provider,omitempty"`
+	Language string       `json:"language,omitempty"` // ISO-639-1 code of the spoken language
+	Prompt   string       `json:"prompt,omitempty"`   // Vocabulary or style hints
+}
+
+// TranscriptionResponse holds the transcribed text
+type TranscriptionResponse struct {
+	Model    string       `json:"model"`
+	Provider ProviderType `json:"provider"`
+	Text     string       `json:"text"`
+	Usage    *Usage       `json:"usage,omitempty"`
+}
+
+// NewAudioChunkEvent creates an EventAudio stream event
+func NewAudioChunkEvent(provider ProviderType, model string, data []byte, mimeType string, sequence int) StreamEvent {
+	return StreamEvent{
+		Type:      EventAudio,
+		Provider:  provider,
+		Model:     model,
+		Data:      AudioChunkEvent{Data: data, MIMEType: mimeType, Sequence: sequence},
+		Timestamp: time.Now(),
+	}
+}
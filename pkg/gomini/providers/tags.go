@@ -0,0 +1,15 @@
+package providers
+
+// TruncateRunes shortens s to at most n characters, as provider limits on
+// metadata and label lengths count them, cutting between runes so the result
+// stays valid UTF-8
+func TruncateRunes(s string, n int) string {
+	count := 0
+	for i := range s {
+		if count == n {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
@@ -0,0 +1,22 @@
+package providers
+
+import "testing"
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"search", 10, "search"},
+		{"search", 3, "sea"},
+		{"台北市", 2, "台北"},
+		{"naïve", 3, "naï"},
+		{"", 5, ""},
+	}
+	for _, tt := range tests {
+		if got := TruncateRunes(tt.in, tt.n); got != tt.want {
+			t.Errorf("TruncateRunes(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package providers
+
+import "fmt"
+
+// ToolChoiceMode says whether and how the model must call tools
+type ToolChoiceMode string
+
+const (
+	ToolChoiceAuto     ToolChoiceMode = "auto"     // The model decides
+	ToolChoiceNone     ToolChoiceMode = "none"     // No tool calls
+	ToolChoiceRequired ToolChoiceMode = "required" // At least one call to any tool
+	ToolChoiceFunction ToolChoiceMode = "function" // A call to the named tool
+)
+
+// ConfigParallelToolCalls is the request config key holding a bool that
+// allows or forbids several tool calls in one turn. Only OpenAI-compatible
+// providers accept it; Gemini decides on its own.
+const ConfigParallelToolCalls = "parallel_tool_calls"
+
+// ParallelToolCallsFromConfig reads parallel_tool_calls from a request config
+// map, reporting whether it was set
+func ParallelToolCallsFromConfig(config RequestConfig) (bool, bool) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return false, false
+	}
+	parallel, ok := configMap[ConfigParallelToolCalls].(bool)
+	return parallel, ok
+}
+
+// ToolChoice is the typed form of ChatRequest.ToolChoice
+type ToolChoice struct {
+	Mode ToolChoiceMode `json:"mode"`
+	Name string         `json:"name,omitempty"` // The forced tool, with ToolChoiceFunction
+}
+
+// ForceTool returns a ToolChoice that makes the model call the named tool
+func ForceTool(name string) ToolChoice {
+	return ToolChoice{Mode: ToolChoiceFunction, Name: name}
+}
+
+// ParseToolChoice reads a ChatRequest.ToolChoice. Besides a ToolChoice, it
+// accepts the "auto", "none" and "required" modes, a bare tool name, and a
+// map naming the function in either {"name": ...} or OpenAI's
+// {"type": "function", "function": {"name": ...}} form. A nil choice parses
+// to the zero ToolChoice, leaving the provider default.
+func ParseToolChoice(choice interface{}) (ToolChoice, error) {
+	switch v := choice.(type) {
+	case nil:
+		return ToolChoice{}, nil
+	case ToolChoice:
+		return v, v.validate()
+	case *ToolChoice:
+		if v == nil {
+			return ToolChoice{}, nil
+		}
+		return *v, v.validate()
+	case ToolChoiceMode:
+		return ParseToolChoice(string(v))
+	case string:
+		switch ToolChoiceMode(v) {
+		case "":
+			return ToolChoice{}, nil
+		case ToolChoiceAuto, ToolChoiceNone, ToolChoiceRequired:
+			return ToolChoice{Mode: ToolChoiceMode(v)}, nil
+		}
+		return ForceTool(v), nil
+	case map[string]interface{}:
+		if name, ok := v["name"].(string); ok && name != "" {
+			return ForceTool(name), nil
+		}
+		if fn, ok := v["function"].(map[string]interface{}); ok {
+			if name, ok := fn["name"].(string); ok && name != "" {
+				return ForceTool(name), nil
+			}
+		}
+		if mode, ok := v["type"].(string); ok && mode != string(ToolChoiceFunction) {
+			return ParseToolChoice(mode)
+		}
+		return ToolChoice{}, fmt.Errorf("tool choice map names no function")
+	}
+	return ToolChoice{}, fmt.Errorf("unsupported tool choice type: %T", choice)
+}
+
+func (c ToolChoice) validate() error {
+	switch c.Mode {
+	case "", ToolChoiceAuto, ToolChoiceNone, ToolChoiceRequired:
+		return nil
+	case ToolChoiceFunction:
+		if c.Name == "" {
+			return fmt.Errorf("tool choice forces a function but names none")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported tool choice mode: %s", c.Mode)
+}
+
+// ResolveToolChoice parses a ChatRequest.ToolChoice and checks it against
+// the request's tools: requiring a tool needs at least one, and a forced
+// tool must be among them. Failures are ErrorInvalidParameters.
+func ResolveToolChoice(choice interface{}, tools []Tool) (ToolChoice, error) {
+	parsed, err := ParseToolChoice(choice)
+	if err != nil {
+		return ToolChoice{}, NewLLMError(ErrorInvalidParameters, err.Error(), "", err)
+	}
+
+	switch parsed.Mode {
+	case ToolChoiceRequired:
+		if len(tools) == 0 {
+			return ToolChoice{}, NewLLMError(ErrorInvalidParameters,
+				"tool choice requires a tool but the request offers none", "", nil)
+		}
+	case ToolChoiceFunction:
+		for _, tool := range tools {
+			if fn, err := AsFunctionTool(tool); err == nil && fn.Name == parsed.Name {
+				return parsed, nil
+			}
+		}
+		return ToolChoice{}, NewLLMError(ErrorInvalidParameters,
+			fmt.Sprintf("tool choice forces %q, which is not among the request's tools", parsed.Name), "", nil)
+	}
+	return parsed, nil
+}
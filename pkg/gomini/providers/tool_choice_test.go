@@ -0,0 +1,61 @@
+package providers
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseToolChoice(t *testing.T) {
+	tests := []struct {
+		choice interface{}
+		want   ToolChoice
+	}{
+		{nil, ToolChoice{}},
+		{"auto", ToolChoice{Mode: ToolChoiceAuto}},
+		{"none", ToolChoice{Mode: ToolChoiceNone}},
+		{ToolChoiceRequired, ToolChoice{Mode: ToolChoiceRequired}},
+		{"get_weather", ForceTool("get_weather")},
+		{ForceTool("get_weather"), ForceTool("get_weather")},
+		{map[string]interface{}{"name": "get_weather"}, ForceTool("get_weather")},
+		{map[string]interface{}{"type": "function", "function": map[string]interface{}{"name": "get_weather"}}, ForceTool("get_weather")},
+		{map[string]interface{}{"type": "required"}, ToolChoice{Mode: ToolChoiceRequired}},
+	}
+	for _, tt := range tests {
+		got, err := ParseToolChoice(tt.choice)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseToolChoice(%#v) = %+v, %v; want %+v", tt.choice, got, err, tt.want)
+		}
+	}
+
+	for _, choice := range []interface{}{42, ToolChoice{Mode: ToolChoiceFunction}, map[string]interface{}{"type": "function"}} {
+		if _, err := ParseToolChoice(choice); err == nil {
+			t.Errorf("expected %#v to be rejected", choice)
+		}
+	}
+}
+
+func TestResolveToolChoice(t *testing.T) {
+	tools := []Tool{FunctionTool{Name: "get_weather"}}
+
+	if got, err := ResolveToolChoice("get_weather", tools); err != nil || got != ForceTool("get_weather") {
+		t.Errorf("expected the forced tool, got %+v, %v", got, err)
+	}
+	if got, err := ResolveToolChoice("none", nil); err != nil || got.Mode != ToolChoiceNone {
+		t.Errorf("expected none without tools to pass, got %+v, %v", got, err)
+	}
+
+	for name, tt := range map[string]struct {
+		choice interface{}
+		tools  []Tool
+	}{
+		"unknown tool":       {"get_time", tools},
+		"required, no tools": {"required", nil},
+		"forced, no tools":   {ForceTool("get_weather"), nil},
+		"unsupported choice": {3.5, tools},
+	} {
+		_, err := ResolveToolChoice(tt.choice, tt.tools)
+		if !errors.Is(err, ErrInvalidParameters) {
+			t.Errorf("%s: expected ErrInvalidParameters, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// FunctionTool declares a function the model may call. It is the concrete
+// Tool value understood by the provider adapters.
+type FunctionTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"` // JSON schema for the arguments
+}
+
+// AsFunctionTool converts a Tool (FunctionTool, *FunctionTool or its map form) to a FunctionTool
+func AsFunctionTool(tool Tool) (FunctionTool, error) {
+	var fn FunctionTool
+
+	switch t := tool.(type) {
+	case FunctionTool:
+		fn = t
+	case *FunctionTool:
+		if t == nil {
+			return FunctionTool{}, fmt.Errorf("nil tool")
+		}
+		fn = *t
+	case map[string]interface{}:
+		raw, err := json.Marshal(t)
+		if err != nil {
+			return FunctionTool{}, fmt.Errorf("invalid tool definition: %w", err)
+		}
+		if err := json.Unmarshal(raw, &fn); err != nil {
+			return FunctionTool{}, fmt.Errorf("invalid tool definition: %w", err)
+		}
+	default:
+		return FunctionTool{}, fmt.Errorf("unsupported tool type: %T", tool)
+	}
+
+	if fn.Name == "" {
+		return FunctionTool{}, fmt.Errorf("tool name is required")
+	}
+	return fn, nil
+}
+
+// ParseToolArguments decodes a JSON-encoded argument string from a tool call
+func ParseToolArguments(raw string) (map[string]interface{}, error) {
+	if raw == "" {
+		return map[string]interface{}{}, nil
+	}
+
+	var args map[string]interface{}
+	if _, err := UnmarshalJSONWithRepair(raw, &args); err != nil {
+		return nil, fmt.Errorf("invalid tool arguments: %w", err)
+	}
+	return args, nil
+}
+
+// MessageToolCalls returns the tool calls of an assistant message, whether
+// they are held as ToolCall values or, after a JSON round trip, as maps
+func MessageToolCalls(msg map[string]interface{}) ([]ToolCall, error) {
+	switch calls := msg["tool_calls"].(type) {
+	case nil:
+		return nil, nil
+	case []ToolCall:
+		return calls, nil
+	case []interface{}:
+		raw, err := json.Marshal(calls)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tool calls: %w", err)
+		}
+		var toolCalls []ToolCall
+		if err := json.Unmarshal(raw, &toolCalls); err != nil {
+			return nil, fmt.Errorf("invalid tool calls: %w", err)
+		}
+		return toolCalls, nil
+	default:
+		return nil, fmt.Errorf("unsupported tool calls: %T", calls)
+	}
+}
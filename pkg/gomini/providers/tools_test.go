@@ -0,0 +1,57 @@
+package providers
+
+import "testing"
+
+func TestAsFunctionTool(t *testing.T) {
+	tests := []struct {
+		name        string
+		tool        Tool
+		expected    string
+		expectError bool
+	}{
+		{name: "value", tool: FunctionTool{Name: "search"}, expected: "search"},
+		{name: "pointer", tool: &FunctionTool{Name: "search"}, expected: "search"},
+		{
+			name: "map",
+			tool: map[string]interface{}{
+				"name":       "search",
+				"parameters": map[string]interface{}{"type": "object"},
+			},
+			expected: "search",
+		},
+		{name: "missing name", tool: FunctionTool{Description: "no name"}, expectError: true},
+		{name: "unsupported", tool: "search", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, err := AsFunctionTool(tt.tool)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fn.Name != tt.expected {
+				t.Errorf("expected name %s, got %s", tt.expected, fn.Name)
+			}
+		})
+	}
+}
+
+func TestParseToolArguments(t *testing.T) {
+	args, err := ParseToolArguments(`{"city": "Taipei",}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args["city"] != "Taipei" {
+		t.Errorf("expected repaired arguments, got %v", args)
+	}
+
+	if args, err := ParseToolArguments(""); err != nil || len(args) != 0 {
+		t.Errorf("expected empty arguments, got %v (err %v)", args, err)
+	}
+}
@@ -0,0 +1,257 @@
+// Package vcr records provider traffic to cassette files and replays it, so
+// code built on gomini can be tested without API keys or network access
+package vcr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// CassetteVersion is the version of the cassette file format
+const CassetteVersion = 1
+
+// Interaction kinds
+const (
+	KindChat          = "chat"
+	KindStream        = "stream"
+	KindJSON          = "json"
+	KindJSONStream    = "json_stream"
+	KindModels        = "models"
+	KindModeration    = "moderation"
+	KindImage         = "image"
+	KindSpeech        = "speech"
+	KindSpeechStream  = "speech_stream"
+	KindTranscription = "transcription"
+)
+
+// Cassette holds the recorded interactions of one provider. A cassette is
+// safe for concurrent use and may be shared by several recorders or players;
+// players sharing a cassette also share its playback position.
+type Cassette struct {
+	Version      int                            `json:"version"`
+	Provider     providers.ProviderType         `json:"provider"`
+	Capabilities providers.ProviderCapabilities `json:"capabilities"`
+	Features     providers.Features             `json:"features"` // Optional interfaces replayed
+	Interactions []Interaction                  `json:"interactions"`
+
+	path   string
+	mu     sync.Mutex
+	served map[string]int // Interactions served per key
+}
+
+// Interaction is one recorded request and its outcome. Requests are matched
+// on Key; Request is kept for people reading the cassette.
+type Interaction struct {
+	Kind          string                           `json:"kind"`
+	Key           string                           `json:"key"`
+	Request       json.RawMessage                  `json:"request,omitempty"`
+	Response      *providers.ChatResponse          `json:"response,omitempty"`      // chat
+	JSON          *providers.JSONResponse          `json:"json,omitempty"`          // json
+	Events        []Event                          `json:"events,omitempty"`        // stream, json_stream, speech_stream
+	Models        []providers.Model                `json:"models,omitempty"`        // models
+	Moderation    *providers.ModerationResult      `json:"moderation,omitempty"`    // moderation
+	Image         *providers.ImageResponse         `json:"image,omitempty"`         // image
+	Speech        *providers.SpeechResponse        `json:"speech,omitempty"`        // speech
+	Transcription *providers.TranscriptionResponse `json:"transcription,omitempty"` // transcription
+	Error         string                           `json:"error,omitempty"`         // Failed requests
+}
+
+// Event is a recorded stream event. Data holds the JSON of the event payload.
+type Event struct {
+	Type     providers.EventType `json:"type"`
+	Model    string              `json:"model,omitempty"`
+	Data     json.RawMessage     `json:"data,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Metadata providers.EventMeta `json:"metadata,omitempty"`
+}
+
+// NewCassette creates an empty cassette that Save writes to path
+func NewCassette(path string) *Cassette {
+	return &Cassette{Version: CassetteVersion, path: path}
+}
+
+// LoadCassette reads a cassette file
+func LoadCassette(path string) (*Cassette, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(raw, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	if cassette.Version < 1 || cassette.Version > CassetteVersion {
+		return nil, fmt.Errorf("cassette %s has unsupported version %d", path, cassette.Version)
+	}
+	cassette.path = path
+	return &cassette, nil
+}
+
+// Path returns the file the cassette is saved to
+func (c *Cassette) Path() string {
+	return c.path
+}
+
+// Len returns the number of recorded interactions
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.Interactions)
+}
+
+// Save writes the cassette to its path, creating parent directories
+func (c *Cassette) Save() error {
+	if c.path == "" {
+		return fmt.Errorf("cassette has no path")
+	}
+	c.mu.Lock()
+	raw, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(c.path, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// record appends an interaction
+func (c *Cassette) record(interaction Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, interaction)
+}
+
+// setProvider records the provider the cassette was recorded against
+func (c *Cassette) setProvider(provider providers.LLMProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Provider = provider.GetProviderType()
+	c.Capabilities = provider.GetCapabilities()
+	c.Features = providers.FeaturesOf(provider)
+}
+
+// next returns the next recorded interaction for key. Interactions recorded
+// for the same key are served in order, and the last one is repeated once
+// they run out.
+func (c *Cassette) next(key string) (*Interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.served == nil {
+		c.served = make(map[string]int)
+	}
+
+	var matches []int
+	for i := range c.Interactions {
+		if c.Interactions[i].Key == key {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, false
+	}
+	n := c.served[key]
+	c.served[key] = n + 1
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	return &c.Interactions[matches[n]], true
+}
+
+// requestKey returns the normalized JSON of a request and its match key.
+// Tags only attribute usage, so they do not affect matching.
+func requestKey(kind string, request interface{}) (json.RawMessage, string, error) {
+	var normalized interface{}
+	switch r := request.(type) {
+	case *providers.ChatRequest:
+		keyed := *r
+		keyed.Tags = nil
+		normalized = keyed
+	case *providers.JSONRequest:
+		keyed := *r
+		keyed.Tags = nil
+		normalized = keyed
+	case string:
+		normalized = map[string]string{"input": r}
+	default:
+		normalized = r
+	}
+	// Maps marshal with sorted keys, so equal requests encode identically
+	raw, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(kind+":"), raw...))
+	return raw, kind + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// encodeEvent converts a stream event for the cassette
+func encodeEvent(event providers.StreamEvent) Event {
+	recorded := Event{Type: event.Type, Model: event.Model, Metadata: event.Metadata}
+	if event.Data != nil {
+		if raw, err := json.Marshal(event.Data); err == nil {
+			recorded.Data = raw
+		}
+	}
+	if event.Error != nil {
+		recorded.Error = event.Error.Error()
+	}
+	return recorded
+}
+
+// decode rebuilds the stream event with the payload type providers emit
+func (e Event) decode(providerType providers.ProviderType) providers.StreamEvent {
+	event := providers.StreamEvent{
+		Type:     e.Type,
+		Provider: providerType,
+		Model:    e.Model,
+		Metadata: e.Metadata,
+	}
+	if e.Error != "" {
+		event.Error = errors.New(e.Error)
+	}
+	if len(e.Data) == 0 {
+		return event
+	}
+
+	switch e.Type {
+	case providers.EventContent:
+		event.Data = decodeData[providers.ContentEvent](e.Data)
+	case providers.EventThought:
+		event.Data = decodeData[providers.ThoughtEvent](e.Data)
+	case providers.EventToolCall:
+		event.Data = decodeData[providers.ToolCall](e.Data)
+	case providers.EventToolCallDelta:
+		event.Data = decodeData[providers.ToolCallDeltaEvent](e.Data)
+	case providers.EventImage:
+		event.Data = decodeData[providers.ImageEvent](e.Data)
+	case providers.EventCitation:
+		event.Data = decodeData[providers.CitationEvent](e.Data)
+	case providers.EventAudio:
+		event.Data = decodeData[providers.AudioChunkEvent](e.Data)
+	default:
+		event.Data = decodeData[interface{}](e.Data)
+	}
+	return event
+}
+
+// decodeData decodes an event payload as T, returning nil if it does not fit
+func decodeData[T any](raw json.RawMessage) interface{} {
+	var data T
+	if json.Unmarshal(raw, &data) != nil {
+		return nil
+	}
+	return data
+}
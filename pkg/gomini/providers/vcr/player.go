@@ -0,0 +1,221 @@
+package vcr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// Player implements providers.LLMProvider and every optional provider
+// interface by replaying a cassette. Requests that were not recorded fail
+// with an error naming the cassette. Pass it through
+// providers.ExposeFeatures with the cassette's Features so callers see only
+// the interfaces of the recorded provider.
+type Player struct {
+	cassette *Cassette
+}
+
+// NewPlayer replays cassette
+func NewPlayer(cassette *Cassette) *Player {
+	return &Player{cassette: cassette}
+}
+
+// SendMessage returns the recorded response or error for req
+func (p *Player) SendMessage(ctx context.Context, req *providers.ChatRequest) (*providers.ChatResponse, error) {
+	interaction, err := p.lookup(KindChat, req)
+	if err != nil {
+		return nil, err
+	}
+	if interaction.Error != "" {
+		return nil, errors.New(interaction.Error)
+	}
+	if interaction.Response == nil {
+		return nil, fmt.Errorf("cassette %s: recorded chat interaction has no response", p.cassette.Path())
+	}
+	resp := *interaction.Response
+	resp.Choices = restoreChoices(resp.Choices)
+	return &resp, nil
+}
+
+// SendMessageStream replays the recorded events for req
+func (p *Player) SendMessageStream(ctx context.Context, req *providers.ChatRequest) <-chan providers.StreamEvent {
+	return p.replayStream(ctx, KindStream, req, req.Model)
+}
+
+// replayStream replays the events recorded under kind for req
+func (p *Player) replayStream(ctx context.Context, kind string, req interface{}, model string) <-chan providers.StreamEvent {
+	interaction, err := p.lookup(kind, req)
+	if err != nil {
+		return errorStream(p.GetProviderType(), model, err)
+	}
+
+	resultChan := make(chan providers.StreamEvent, 10)
+	go func() {
+		defer close(resultChan)
+
+		for _, recorded := range interaction.Events {
+			event := recorded.decode(p.GetProviderType())
+			event.Timestamp = time.Now()
+			select {
+			case resultChan <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return resultChan
+}
+
+// GenerateJSON returns the recorded response or error for req
+func (p *Player) GenerateJSON(ctx context.Context, req *providers.JSONRequest) (*providers.JSONResponse, error) {
+	interaction, err := p.lookup(KindJSON, req)
+	if err != nil {
+		return nil, err
+	}
+	if interaction.Error != "" {
+		return nil, errors.New(interaction.Error)
+	}
+	if interaction.JSON == nil {
+		return nil, fmt.Errorf("cassette %s: recorded JSON interaction has no response", p.cassette.Path())
+	}
+	resp := *interaction.JSON
+	return &resp, nil
+}
+
+// ListModels returns the recorded models
+func (p *Player) ListModels(ctx context.Context) ([]providers.Model, error) {
+	interaction, ok := p.cassette.next(KindModels)
+	if !ok {
+		return nil, fmt.Errorf("cassette %s has no recorded model list", p.cassette.Path())
+	}
+	if interaction.Error != "" {
+		return nil, errors.New(interaction.Error)
+	}
+	return interaction.Models, nil
+}
+
+// GenerateJSONStream replays the recorded JSON stream for req
+func (p *Player) GenerateJSONStream(ctx context.Context, req *providers.JSONRequest) <-chan providers.StreamEvent {
+	return p.replayStream(ctx, KindJSONStream, req, req.Model)
+}
+
+// Moderate returns the recorded verdict or error for text
+func (p *Player) Moderate(ctx context.Context, text string) (*providers.ModerationResult, error) {
+	return replay(p, KindModeration, text, func(interaction *Interaction) *providers.ModerationResult {
+		return interaction.Moderation
+	})
+}
+
+// GenerateImage returns the recorded images or error for req
+func (p *Player) GenerateImage(ctx context.Context, req *providers.ImageRequest) (*providers.ImageResponse, error) {
+	return replay(p, KindImage, req, func(interaction *Interaction) *providers.ImageResponse {
+		return interaction.Image
+	})
+}
+
+// Speech returns the recorded audio or error for req
+func (p *Player) Speech(ctx context.Context, req *providers.SpeechRequest) (*providers.SpeechResponse, error) {
+	return replay(p, KindSpeech, req, func(interaction *Interaction) *providers.SpeechResponse {
+		return interaction.Speech
+	})
+}
+
+// SpeechStream replays the recorded audio stream for req
+func (p *Player) SpeechStream(ctx context.Context, req *providers.SpeechRequest) <-chan providers.StreamEvent {
+	return p.replayStream(ctx, KindSpeechStream, req, req.Model)
+}
+
+// Transcribe returns the recorded transcript or error for req
+func (p *Player) Transcribe(ctx context.Context, req *providers.TranscriptionRequest) (*providers.TranscriptionResponse, error) {
+	return replay(p, KindTranscription, req, func(interaction *Interaction) *providers.TranscriptionResponse {
+		return interaction.Transcription
+	})
+}
+
+// replay returns a copy of the result recorded under kind for req, or its error
+func replay[T any](p *Player, kind string, req interface{}, result func(*Interaction) *T) (*T, error) {
+	interaction, err := p.lookup(kind, req)
+	if err != nil {
+		return nil, err
+	}
+	if interaction.Error != "" {
+		return nil, errors.New(interaction.Error)
+	}
+	recorded := result(interaction)
+	if recorded == nil {
+		return nil, fmt.Errorf("cassette %s: recorded %s interaction has no response", p.cassette.Path(), kind)
+	}
+	copied := *recorded
+	return &copied, nil
+}
+
+// GetCapabilities returns the capabilities of the recorded provider
+func (p *Player) GetCapabilities() providers.ProviderCapabilities {
+	return p.cassette.Capabilities
+}
+
+// GetProviderType returns the recorded provider type
+func (p *Player) GetProviderType() providers.ProviderType {
+	return p.cassette.Provider
+}
+
+// Close is a no-op
+func (p *Player) Close() error {
+	return nil
+}
+
+// lookup returns the next recorded interaction matching the request
+func (p *Player) lookup(kind string, request interface{}) (*Interaction, error) {
+	_, key, err := requestKey(kind, request)
+	if err != nil {
+		return nil, err
+	}
+	interaction, ok := p.cassette.next(key)
+	if !ok {
+		return nil, fmt.Errorf("cassette %s has no recorded %s interaction for this request", p.cassette.Path(), kind)
+	}
+	return interaction, nil
+}
+
+// restoreChoices copies decoded choices, turning tool_calls back into
+// []providers.ToolCall, the shape providers return
+func restoreChoices(choices []providers.Choice) []providers.Choice {
+	restored := make([]providers.Choice, len(choices))
+	for i, choice := range choices {
+		restored[i] = choice
+		if message, ok := choice.Message.(map[string]interface{}); ok {
+			restored[i].Message = restoreMessage(message)
+		}
+	}
+	return restored
+}
+
+// restoreMessage copies a decoded message with its tool calls restored
+func restoreMessage(message map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(message))
+	for key, value := range message {
+		copied[key] = value
+	}
+	if calls, ok := copied["tool_calls"]; ok {
+		if raw, err := json.Marshal(calls); err == nil {
+			var toolCalls []providers.ToolCall
+			if json.Unmarshal(raw, &toolCalls) == nil {
+				copied["tool_calls"] = toolCalls
+			}
+		}
+	}
+	return copied
+}
+
+// errorStream returns a stream holding only err
+func errorStream(providerType providers.ProviderType, model string, err error) <-chan providers.StreamEvent {
+	resultChan := make(chan providers.StreamEvent, 1)
+	resultChan <- providers.NewErrorEvent(providerType, model, err, false)
+	close(resultChan)
+	return resultChan
+}
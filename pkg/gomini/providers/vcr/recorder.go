@@ -0,0 +1,226 @@
+package vcr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gomini/pkg/gomini/providers"
+)
+
+// Recorder wraps a provider and records every interaction with it to a
+// cassette. The cassette is saved when the recorder is closed. It implements
+// every optional provider interface; pass it through providers.Expose so
+// callers see only those the wrapped provider has.
+type Recorder struct {
+	providers.LLMProvider
+	cassette *Cassette
+}
+
+// NewRecorder records the traffic of provider to cassette
+func NewRecorder(provider providers.LLMProvider, cassette *Cassette) *Recorder {
+	cassette.setProvider(provider)
+	return &Recorder{LLMProvider: provider, cassette: cassette}
+}
+
+// Cassette returns the cassette being recorded
+func (r *Recorder) Cassette() *Cassette {
+	return r.cassette
+}
+
+// SendMessage forwards the request and records the response or error
+func (r *Recorder) SendMessage(ctx context.Context, req *providers.ChatRequest) (*providers.ChatResponse, error) {
+	resp, err := r.LLMProvider.SendMessage(ctx, req)
+	if interaction, ok := newInteraction(KindChat, req); ok {
+		interaction.Response = snapshot(resp)
+		interaction.Error = errorText(err)
+		r.cassette.record(interaction)
+	}
+	return resp, err
+}
+
+// SendMessageStream forwards the stream and records its events once it
+// ends. Streams cut short by ctx are not recorded.
+func (r *Recorder) SendMessageStream(ctx context.Context, req *providers.ChatRequest) <-chan providers.StreamEvent {
+	return r.recordStream(ctx, KindStream, req, r.LLMProvider.SendMessageStream(ctx, req))
+}
+
+// recordStream forwards source and records its events under kind once it ends
+func (r *Recorder) recordStream(ctx context.Context, kind string, req interface{}, source <-chan providers.StreamEvent) <-chan providers.StreamEvent {
+	resultChan := make(chan providers.StreamEvent, 10)
+
+	go func() {
+		defer close(resultChan)
+
+		var events []Event
+		for event := range source {
+			events = append(events, encodeEvent(event))
+			select {
+			case resultChan <- event:
+			case <-ctx.Done():
+				for range source {
+				}
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if interaction, ok := newInteraction(kind, req); ok {
+			interaction.Events = events
+			r.cassette.record(interaction)
+		}
+	}()
+
+	return resultChan
+}
+
+// GenerateJSON forwards the request and records the response or error
+func (r *Recorder) GenerateJSON(ctx context.Context, req *providers.JSONRequest) (*providers.JSONResponse, error) {
+	resp, err := r.LLMProvider.GenerateJSON(ctx, req)
+	if interaction, ok := newInteraction(KindJSON, req); ok {
+		interaction.JSON = snapshot(resp)
+		interaction.Error = errorText(err)
+		r.cassette.record(interaction)
+	}
+	return resp, err
+}
+
+// ListModels forwards the request and records the models or error
+func (r *Recorder) ListModels(ctx context.Context) ([]providers.Model, error) {
+	models, err := r.LLMProvider.ListModels(ctx)
+	r.cassette.record(Interaction{Kind: KindModels, Key: KindModels, Models: models, Error: errorText(err)})
+	return models, err
+}
+
+// GenerateJSONStream forwards the JSON stream and records its events once it ends
+func (r *Recorder) GenerateJSONStream(ctx context.Context, req *providers.JSONRequest) <-chan providers.StreamEvent {
+	streamer, ok := r.LLMProvider.(providers.JSONStreamer)
+	if !ok {
+		return r.unsupportedStream(req.Model, "JSON streaming")
+	}
+	return r.recordStream(ctx, KindJSONStream, req, streamer.GenerateJSONStream(ctx, req))
+}
+
+// Moderate forwards the check and records the result or error
+func (r *Recorder) Moderate(ctx context.Context, text string) (*providers.ModerationResult, error) {
+	moderator, ok := r.LLMProvider.(providers.Moderator)
+	if !ok {
+		return nil, r.unsupported("moderation")
+	}
+	result, err := moderator.Moderate(ctx, text)
+	if interaction, ok := newInteraction(KindModeration, text); ok {
+		interaction.Moderation = snapshot(result)
+		interaction.Error = errorText(err)
+		r.cassette.record(interaction)
+	}
+	return result, err
+}
+
+// GenerateImage forwards the request and records the images or error
+func (r *Recorder) GenerateImage(ctx context.Context, req *providers.ImageRequest) (*providers.ImageResponse, error) {
+	generator, ok := r.LLMProvider.(providers.ImageGenerator)
+	if !ok {
+		return nil, r.unsupported("image generation")
+	}
+	resp, err := generator.GenerateImage(ctx, req)
+	if interaction, ok := newInteraction(KindImage, req); ok {
+		interaction.Image = snapshot(resp)
+		interaction.Error = errorText(err)
+		r.cassette.record(interaction)
+	}
+	return resp, err
+}
+
+// Speech forwards the request and records the audio or error
+func (r *Recorder) Speech(ctx context.Context, req *providers.SpeechRequest) (*providers.SpeechResponse, error) {
+	audio, ok := r.LLMProvider.(providers.AudioProvider)
+	if !ok {
+		return nil, r.unsupported("speech")
+	}
+	resp, err := audio.Speech(ctx, req)
+	if interaction, ok := newInteraction(KindSpeech, req); ok {
+		interaction.Speech = snapshot(resp)
+		interaction.Error = errorText(err)
+		r.cassette.record(interaction)
+	}
+	return resp, err
+}
+
+// SpeechStream forwards the audio stream and records its events once it ends
+func (r *Recorder) SpeechStream(ctx context.Context, req *providers.SpeechRequest) <-chan providers.StreamEvent {
+	audio, ok := r.LLMProvider.(providers.AudioProvider)
+	if !ok {
+		return r.unsupportedStream(req.Model, "speech")
+	}
+	return r.recordStream(ctx, KindSpeechStream, req, audio.SpeechStream(ctx, req))
+}
+
+// Transcribe forwards the request and records the transcript or error
+func (r *Recorder) Transcribe(ctx context.Context, req *providers.TranscriptionRequest) (*providers.TranscriptionResponse, error) {
+	audio, ok := r.LLMProvider.(providers.AudioProvider)
+	if !ok {
+		return nil, r.unsupported("transcription")
+	}
+	resp, err := audio.Transcribe(ctx, req)
+	if interaction, ok := newInteraction(KindTranscription, req); ok {
+		interaction.Transcription = snapshot(resp)
+		interaction.Error = errorText(err)
+		r.cassette.record(interaction)
+	}
+	return resp, err
+}
+
+// unsupported reports a feature the wrapped provider lacks
+func (r *Recorder) unsupported(feature string) error {
+	return providers.NewLLMError(providers.ErrorUnsupportedFeature,
+		fmt.Sprintf("provider %s does not support %s", r.GetProviderType(), feature), r.GetProviderType(), nil)
+}
+
+func (r *Recorder) unsupportedStream(model, feature string) <-chan providers.StreamEvent {
+	return errorStream(r.GetProviderType(), model, r.unsupported(feature))
+}
+
+// Close closes the wrapped provider and saves the cassette
+func (r *Recorder) Close() error {
+	closeErr := r.LLMProvider.Close()
+	if err := r.cassette.Save(); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// newInteraction starts an interaction for a request. Requests that cannot
+// be encoded are not recorded.
+func newInteraction(kind string, request interface{}) (Interaction, bool) {
+	raw, key, err := requestKey(kind, request)
+	if err != nil {
+		return Interaction{}, false
+	}
+	return Interaction{Kind: kind, Key: key, Request: raw}, true
+}
+
+// errorText returns the message of err, or "" for nil
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// snapshot copies v through JSON, so callers changing a response afterwards
+// do not change the recording
+func snapshot[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var copied T
+	if json.Unmarshal(raw, &copied) != nil {
+		return v
+	}
+	return &copied
+}
@@ -0,0 +1,257 @@
+package vcr
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini/providers"
+	"gomini/pkg/gomini/providers/mock"
+)
+
+func newMockProvider(t *testing.T) *mock.Provider {
+	t.Helper()
+	provider, err := mock.NewProvider(&mock.Config{Scenario: &mock.Scenario{Rules: []mock.Rule{
+		{Match: mock.Matcher{Contains: "weather"}, Steps: []mock.Step{
+			{Type: mock.StepContent, Text: "Checking."},
+			{Type: mock.StepToolCall, ToolCall: &providers.ToolCall{ID: "call_1", Name: "get_weather", Arguments: map[string]interface{}{"city": "Taipei"}}},
+			{Type: mock.StepFinished, FinishReason: providers.FinishReasonToolCalls, Usage: &providers.Usage{TotalTokens: 12}},
+		}},
+		{Match: mock.Matcher{Contains: "outage"}, Steps: []mock.Step{{Type: mock.StepError, Error: "503 service unavailable"}}},
+		{Match: mock.Matcher{Contains: "json"}, Steps: []mock.Step{{Type: mock.StepContent, Text: `{"ok":true}`}}},
+		{Steps: []mock.Step{{Type: mock.StepContent, Text: "Hello!"}}},
+	}}})
+	if err != nil {
+		t.Fatalf("mock.NewProvider failed: %v", err)
+	}
+	return provider
+}
+
+func userRequest(text string) *providers.ChatRequest {
+	return &providers.ChatRequest{
+		Model:    "mock-model",
+		Messages: []providers.Message{map[string]interface{}{"role": "user", "content": text}},
+	}
+}
+
+func collect(ch <-chan providers.StreamEvent) []providers.StreamEvent {
+	var events []providers.StreamEvent
+	for event := range ch {
+		events = append(events, event)
+	}
+	return events
+}
+
+// recordCassette records a few interactions and returns the saved cassette path
+func recordCassette(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassettes", "mock.json")
+	recorder := NewRecorder(newMockProvider(t), NewCassette(path))
+
+	collect(recorder.SendMessageStream(ctx, userRequest("What's the weather?")))
+	if _, err := recorder.SendMessage(ctx, userRequest("hi")); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if _, err := recorder.SendMessage(ctx, userRequest("simulate an outage")); err == nil {
+		t.Fatal("expected the scripted error")
+	}
+	if _, err := recorder.GenerateJSON(ctx, &providers.JSONRequest{
+		Model:    "mock-model",
+		Messages: userRequest("json please").Messages,
+	}); err != nil {
+		t.Fatalf("GenerateJSON failed: %v", err)
+	}
+	if _, err := recorder.ListModels(ctx); err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if recorder.Cassette().Len() != 5 {
+		t.Fatalf("expected 5 interactions, got %d", recorder.Cassette().Len())
+	}
+	return path
+}
+
+func TestRecordReplay(t *testing.T) {
+	ctx := context.Background()
+	cassette, err := LoadCassette(recordCassette(t))
+	if err != nil {
+		t.Fatalf("LoadCassette failed: %v", err)
+	}
+	player := NewPlayer(cassette)
+	if player.GetProviderType() != providers.ProviderMock {
+		t.Errorf("expected the recorded provider type, got %s", player.GetProviderType())
+	}
+
+	events := collect(player.SendMessageStream(ctx, userRequest("What's the weather?")))
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d: %v", len(events), events)
+	}
+	if content, ok := events[0].Data.(providers.ContentEvent); !ok || content.Text != "Checking." {
+		t.Errorf("unexpected content event: %#v", events[0].Data)
+	}
+	if call, ok := events[1].Data.(providers.ToolCall); !ok || call.Name != "get_weather" || call.Arguments["city"] != "Taipei" {
+		t.Errorf("unexpected tool call event: %#v", events[1].Data)
+	}
+	finished := events[2]
+	if finished.Metadata.FinishReason != providers.FinishReasonToolCalls || finished.Metadata.Usage.TotalTokens != 12 {
+		t.Errorf("unexpected finished event: %+v", finished.Metadata)
+	}
+
+	resp, err := player.SendMessage(ctx, userRequest("hi"))
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if content := resp.Choices[0].Content(); content != "Hello!" {
+		t.Errorf("unexpected replayed message: %v", resp.Choices[0].Message)
+	}
+
+	if _, err := player.SendMessage(ctx, userRequest("simulate an outage")); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("expected the recorded error, got %v", err)
+	}
+
+	jsonResp, err := player.GenerateJSON(ctx, &providers.JSONRequest{Model: "mock-model", Messages: userRequest("json please").Messages})
+	if err != nil || jsonResp.Data["ok"] != true {
+		t.Errorf("unexpected JSON replay: %+v, %v", jsonResp, err)
+	}
+
+	models, err := player.ListModels(ctx)
+	if err != nil || len(models) != 1 || models[0].ID != mock.DefaultModel {
+		t.Errorf("unexpected models: %v, %v", models, err)
+	}
+}
+
+func TestPlayer_Matching(t *testing.T) {
+	ctx := context.Background()
+	cassette, err := LoadCassette(recordCassette(t))
+	if err != nil {
+		t.Fatalf("LoadCassette failed: %v", err)
+	}
+	player := NewPlayer(cassette)
+
+	tagged := userRequest("hi")
+	tagged.Tags = map[string]string{"team": "qa"}
+	for i := 0; i < 2; i++ {
+		if _, err := player.SendMessage(ctx, tagged); err != nil {
+			t.Errorf("request %d: tags should not affect matching, got %v", i+1, err)
+		}
+	}
+
+	if _, err := player.SendMessage(ctx, userRequest("never recorded")); err == nil || !strings.Contains(err.Error(), "no recorded chat interaction") {
+		t.Errorf("expected an unrecorded request error, got %v", err)
+	}
+
+	events := collect(player.SendMessageStream(ctx, userRequest("hi")))
+	if len(events) != 1 || events[0].Type != providers.EventError {
+		t.Errorf("expected a stream error for a request recorded only as chat, got %v", events)
+	}
+}
+
+func TestLoadCassette_RejectsNewerVersion(t *testing.T) {
+	cassette := NewCassette(filepath.Join(t.TempDir(), "future.json"))
+	cassette.Version = CassetteVersion + 1
+	if err := cassette.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := LoadCassette(cassette.Path()); err == nil {
+		t.Error("expected an unsupported version error")
+	}
+}
+
+// featuredProvider adds JSON streaming, moderation and audio to a mock provider
+type featuredProvider struct {
+	*mock.Provider
+}
+
+func (p *featuredProvider) GenerateJSONStream(ctx context.Context, req *providers.JSONRequest) <-chan providers.StreamEvent {
+	return p.SendMessageStream(ctx, &providers.ChatRequest{Model: req.Model, Messages: req.Messages})
+}
+
+func (p *featuredProvider) Moderate(ctx context.Context, text string) (*providers.ModerationResult, error) {
+	return &providers.ModerationResult{Flagged: strings.Contains(text, "attack")}, nil
+}
+
+func (p *featuredProvider) Speech(ctx context.Context, req *providers.SpeechRequest) (*providers.SpeechResponse, error) {
+	return &providers.SpeechResponse{Model: req.Model, Audio: []byte("ID3" + req.Input), MIMEType: "audio/mpeg"}, nil
+}
+
+func (p *featuredProvider) SpeechStream(ctx context.Context, req *providers.SpeechRequest) <-chan providers.StreamEvent {
+	events := make(chan providers.StreamEvent, 2)
+	events <- providers.NewAudioChunkEvent(providers.ProviderMock, req.Model, []byte("ID3"), "audio/mpeg", 0)
+	events <- providers.StreamEvent{Type: providers.EventFinished}
+	close(events)
+	return events
+}
+
+func (p *featuredProvider) Transcribe(ctx context.Context, req *providers.TranscriptionRequest) (*providers.TranscriptionResponse, error) {
+	return &providers.TranscriptionResponse{Model: req.Model, Text: "hello world"}, nil
+}
+
+func TestRecordReplay_OptionalFeatures(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "mock.json")
+	wrapped := &featuredProvider{newMockProvider(t)}
+	recorder := providers.Expose(NewRecorder(wrapped, NewCassette(path)), wrapped)
+	if _, ok := recorder.(providers.ImageGenerator); ok {
+		t.Error("expected the recorder to hide image generation the provider lacks")
+	}
+
+	jsonRequest := &providers.JSONRequest{Model: "mock-model", Messages: userRequest("json please").Messages}
+	speechRequest := &providers.SpeechRequest{Model: "tts-1", Input: "hi"}
+	transcriptionRequest := &providers.TranscriptionRequest{Model: "whisper-1", Audio: providers.AudioPart{Data: []byte("RIFF"), MIMEType: "audio/wav"}}
+	collect(recorder.(providers.JSONStreamer).GenerateJSONStream(ctx, jsonRequest))
+	audio := recorder.(providers.AudioProvider)
+	if _, err := recorder.(providers.Moderator).Moderate(ctx, "plan an attack"); err != nil {
+		t.Fatalf("Moderate failed: %v", err)
+	}
+	if _, err := audio.Speech(ctx, speechRequest); err != nil {
+		t.Fatalf("Speech failed: %v", err)
+	}
+	collect(audio.SpeechStream(ctx, speechRequest))
+	if _, err := audio.Transcribe(ctx, transcriptionRequest); err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette failed: %v", err)
+	}
+	player := providers.ExposeFeatures(NewPlayer(cassette), cassette.Features)
+	if _, ok := player.(providers.ImageGenerator); ok {
+		t.Error("expected the player to hide image generation the recorded provider lacked")
+	}
+
+	var text string
+	for _, event := range collect(player.(providers.JSONStreamer).GenerateJSONStream(ctx, jsonRequest)) {
+		if content, ok := event.Data.(providers.ContentEvent); ok {
+			text += content.Text
+		}
+	}
+	if text != `{"ok":true}` {
+		t.Errorf("expected the recorded JSON stream, got %q", text)
+	}
+	if result, err := player.(providers.Moderator).Moderate(ctx, "plan an attack"); err != nil || !result.Flagged {
+		t.Errorf("expected the recorded verdict, got %+v, %v", result, err)
+	}
+	replayed := player.(providers.AudioProvider)
+	if resp, err := replayed.Speech(ctx, speechRequest); err != nil || string(resp.Audio) != "ID3hi" {
+		t.Errorf("expected the recorded audio, got %+v, %v", resp, err)
+	}
+	events := collect(replayed.SpeechStream(ctx, speechRequest))
+	if chunk, ok := events[0].Data.(providers.AudioChunkEvent); !ok || string(chunk.Data) != "ID3" {
+		t.Errorf("expected the recorded audio chunk, got %+v", events[0])
+	}
+	if resp, err := replayed.Transcribe(ctx, transcriptionRequest); err != nil || resp.Text != "hello world" {
+		t.Errorf("expected the recorded transcript, got %+v, %v", resp, err)
+	}
+	if _, err := replayed.Speech(ctx, &providers.SpeechRequest{Model: "tts-1", Input: "other"}); err == nil {
+		t.Error("expected unrecorded speech to fail")
+	}
+}
@@ -0,0 +1,29 @@
+package gomini
+
+import (
+	"gomini/pkg/gomini/providers"
+)
+
+// DetailRemediations is the LLMError.Details key holding remediation hints
+const DetailRemediations = providers.DetailRemediations
+
+type (
+	// RemediationAction names something a caller can do to make a rejected request succeed
+	RemediationAction = providers.RemediationAction
+
+	// Remediation is a machine-readable hint attached to a rejected request
+	Remediation = providers.Remediation
+)
+
+const (
+	RemediationSwitchModel  = providers.RemediationSwitchModel  // Retry on Provider/Model
+	RemediationReducePrompt = providers.RemediationReducePrompt // Drop at least Tokens tokens
+	RemediationRemoveInput  = providers.RemediationRemoveInput  // Drop the Input modality
+	RemediationEnableTool   = providers.RemediationEnableTool   // Re-enable the tool named by Input
+	RemediationRaiseBudget  = providers.RemediationRaiseBudget  // Raise or reset the session budget
+)
+
+// Remediations returns the remediation hints of the first LLMError in err's chain
+func Remediations(err error) []Remediation {
+	return providers.Remediations(err)
+}
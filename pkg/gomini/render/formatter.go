@@ -0,0 +1,175 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Format selects the output produced by a Renderer
+type Format string
+
+const (
+	FormatHTML Format = "html"
+	FormatANSI Format = "ansi"
+)
+
+// formatter turns markdown blocks into a concrete output format
+type formatter interface {
+	openCode(lang string) string
+	codeLine(line string) string
+	closeCode() string
+
+	heading(level int, text string) string
+
+	openList(ordered bool) string
+	listItem(ordered bool, number int, text string) string
+	closeList(ordered bool) string
+
+	openParagraph() string
+	paragraphBreak() string
+	closeParagraph() string
+
+	// inline renders completed text with emphasis and code spans
+	inline(text string) string
+	// plain renders tentative text without inline formatting
+	plain(text string) string
+}
+
+func newFormatter(format Format) formatter {
+	if format == FormatANSI {
+		return ansiFormatter{}
+	}
+	return htmlFormatter{}
+}
+
+var (
+	boldPattern   = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicPattern = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+)
+
+// renderInline applies code spans, bold and italic using the given wrappers
+func renderInline(text string, escape func(string) string, code, bold, italic func(string) string) string {
+	var b strings.Builder
+	segments := strings.Split(text, "`")
+	for i, segment := range segments {
+		// Odd segments sit between backticks; an unmatched trailing backtick stays literal
+		if i%2 == 1 && i < len(segments)-1 {
+			b.WriteString(code(escape(segment)))
+			continue
+		}
+		if i%2 == 1 {
+			b.WriteString(escape("`"))
+		}
+
+		segment = escape(segment)
+		segment = boldPattern.ReplaceAllStringFunc(segment, func(m string) string {
+			return bold(m[2 : len(m)-2])
+		})
+		segment = italicPattern.ReplaceAllStringFunc(segment, func(m string) string {
+			return italic(m[1 : len(m)-1])
+		})
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+type htmlFormatter struct{}
+
+func (htmlFormatter) openCode(lang string) string {
+	if lang != "" {
+		return fmt.Sprintf(`<pre><code class="language-%s">`, html.EscapeString(lang))
+	}
+	return "<pre><code>"
+}
+
+func (htmlFormatter) codeLine(line string) string { return html.EscapeString(line) + "\n" }
+func (htmlFormatter) closeCode() string           { return "</code></pre>\n" }
+
+func (f htmlFormatter) heading(level int, text string) string {
+	return fmt.Sprintf("<h%d>%s</h%d>\n", level, f.inline(text), level)
+}
+
+func (htmlFormatter) openList(ordered bool) string {
+	if ordered {
+		return "<ol>\n"
+	}
+	return "<ul>\n"
+}
+
+func (f htmlFormatter) listItem(ordered bool, number int, text string) string {
+	return "<li>" + f.inline(text) + "</li>\n"
+}
+
+func (htmlFormatter) closeList(ordered bool) string {
+	if ordered {
+		return "</ol>\n"
+	}
+	return "</ul>\n"
+}
+
+func (htmlFormatter) openParagraph() string  { return "<p>" }
+func (htmlFormatter) paragraphBreak() string { return "\n" }
+func (htmlFormatter) closeParagraph() string { return "</p>\n" }
+
+func (htmlFormatter) inline(text string) string {
+	return renderInline(text, html.EscapeString,
+		func(s string) string { return "<code>" + s + "</code>" },
+		func(s string) string { return "<strong>" + s + "</strong>" },
+		func(s string) string { return "<em>" + s + "</em>" },
+	)
+}
+
+func (htmlFormatter) plain(text string) string { return html.EscapeString(text) }
+
+// ANSI escape sequences used by ansiFormatter
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiItalic    = "\x1b[3m"
+	ansiUnderline = "\x1b[4m"
+	ansiCyan      = "\x1b[36m"
+	ansiDim       = "\x1b[2m"
+)
+
+type ansiFormatter struct{}
+
+func (ansiFormatter) openCode(lang string) string {
+	if lang != "" {
+		return ansiDim + "── " + lang + " ──" + ansiReset + "\n"
+	}
+	return ""
+}
+
+func (ansiFormatter) codeLine(line string) string { return "  " + ansiCyan + line + ansiReset + "\n" }
+func (ansiFormatter) closeCode() string           { return "" }
+
+func (f ansiFormatter) heading(level int, text string) string {
+	return ansiBold + ansiUnderline + f.inline(text) + ansiReset + "\n"
+}
+
+func (ansiFormatter) openList(ordered bool) string { return "" }
+
+func (f ansiFormatter) listItem(ordered bool, number int, text string) string {
+	if ordered {
+		return fmt.Sprintf("  %d. %s\n", number, f.inline(text))
+	}
+	return "  • " + f.inline(text) + "\n"
+}
+
+func (ansiFormatter) closeList(ordered bool) string { return "\n" }
+func (ansiFormatter) openParagraph() string         { return "" }
+func (ansiFormatter) paragraphBreak() string        { return "\n" }
+func (ansiFormatter) closeParagraph() string        { return "\n\n" }
+
+func (ansiFormatter) inline(text string) string {
+	identity := func(s string) string { return s }
+	return renderInline(text, identity,
+		func(s string) string { return ansiCyan + s + ansiReset },
+		func(s string) string { return ansiBold + s + ansiReset },
+		func(s string) string { return ansiItalic + s + ansiReset },
+	)
+}
+
+func (ansiFormatter) plain(text string) string { return text }
@@ -0,0 +1,236 @@
+package render
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+
+	"gomini/pkg/gomini"
+)
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	unorderedPattern   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedPattern     = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	fencePrefixPattern = regexp.MustCompile("^\\s*`{1,3}")
+)
+
+// Update is the result of feeding a delta into a Renderer. Committed output never
+// changes once emitted, so UIs append it; Pending is a tentative rendering of the
+// unfinished tail (including closers for open blocks) that replaces the previous one.
+type Update struct {
+	Committed string `json:"committed"`
+	Pending   string `json:"pending"`
+}
+
+// Renderer incrementally converts streamed markdown into HTML or ANSI output.
+// Lines are committed only once complete, so code fences, lists and inline
+// emphasis never flicker between renderings.
+type Renderer struct {
+	format formatter
+
+	committed strings.Builder
+	line      string
+
+	inCode      bool
+	inParagraph bool
+	inList      bool
+	ordered     bool
+	itemNumber  int
+}
+
+// NewRenderer creates a renderer for the given output format
+func NewRenderer(format Format) *Renderer {
+	return &Renderer{format: newFormatter(format)}
+}
+
+// Write feeds a content delta and returns the newly committed output and pending tail
+func (r *Renderer) Write(delta string) Update {
+	r.line += delta
+
+	var committed strings.Builder
+	for {
+		idx := strings.IndexByte(r.line, '\n')
+		if idx < 0 {
+			break
+		}
+		line := strings.TrimSuffix(r.line[:idx], "\r")
+		r.line = r.line[idx+1:]
+		committed.WriteString(r.commitLine(line))
+	}
+
+	r.committed.WriteString(committed.String())
+	return Update{Committed: committed.String(), Pending: r.pending()}
+}
+
+// Flush commits any remaining partial line and closes open blocks
+func (r *Renderer) Flush() Update {
+	var committed strings.Builder
+	if r.line != "" {
+		committed.WriteString(r.commitLine(r.line))
+		r.line = ""
+	}
+	committed.WriteString(r.closeBlocks())
+	if r.inCode {
+		committed.WriteString(r.format.closeCode())
+		r.inCode = false
+	}
+
+	r.committed.WriteString(committed.String())
+	return Update{Committed: committed.String()}
+}
+
+// String returns everything committed so far followed by the pending tail
+func (r *Renderer) String() string {
+	return r.committed.String() + r.pending()
+}
+
+// HTML returns the current rendering as trusted template.HTML for use in html/template.
+// Only meaningful for FormatHTML, whose output escapes all model text.
+func (r *Renderer) HTML() template.HTML {
+	return template.HTML(r.String())
+}
+
+// Consume renders the content events of a stream, calling onUpdate after each delta
+// and once more when the stream ends. Non-content events are ignored.
+func (r *Renderer) Consume(events <-chan gomini.StreamEvent, onUpdate func(Update)) {
+	for event := range events {
+		if content, ok := event.Data.(gomini.ContentEvent); ok && event.Type == gomini.EventContent {
+			onUpdate(r.Write(content.Text))
+		}
+	}
+	onUpdate(r.Flush())
+}
+
+// commitLine renders a complete line, updating block state
+func (r *Renderer) commitLine(line string) string {
+	trimmed := strings.TrimSpace(line)
+
+	if r.inCode {
+		if strings.HasPrefix(trimmed, "```") {
+			r.inCode = false
+			return r.format.closeCode()
+		}
+		return r.format.codeLine(line)
+	}
+
+	if strings.HasPrefix(trimmed, "```") {
+		r.inCode = true
+		return r.closeBlocks() + r.format.openCode(strings.TrimSpace(strings.TrimPrefix(trimmed, "```")))
+	}
+
+	if trimmed == "" {
+		return r.closeBlocks()
+	}
+
+	if m := headingPattern.FindStringSubmatch(trimmed); m != nil {
+		return r.closeBlocks() + r.format.heading(len(m[1]), m[2])
+	}
+
+	if m := unorderedPattern.FindStringSubmatch(line); m != nil {
+		return r.listItem(false, m[1])
+	}
+	if m := orderedPattern.FindStringSubmatch(line); m != nil {
+		return r.listItem(true, m[1])
+	}
+
+	var out strings.Builder
+	if r.inList {
+		out.WriteString(r.closeBlocks())
+	}
+	if r.inParagraph {
+		out.WriteString(r.format.paragraphBreak())
+	} else {
+		out.WriteString(r.format.openParagraph())
+		r.inParagraph = true
+	}
+	out.WriteString(r.format.inline(trimmed))
+	return out.String()
+}
+
+func (r *Renderer) listItem(ordered bool, text string) string {
+	var out strings.Builder
+	if r.inParagraph || (r.inList && r.ordered != ordered) {
+		out.WriteString(r.closeBlocks())
+	}
+	if !r.inList {
+		out.WriteString(r.format.openList(ordered))
+		r.inList = true
+		r.ordered = ordered
+		r.itemNumber = 0
+	}
+	r.itemNumber++
+	out.WriteString(r.format.listItem(ordered, r.itemNumber, text))
+	return out.String()
+}
+
+// closeBlocks closes an open paragraph or list
+func (r *Renderer) closeBlocks() string {
+	switch {
+	case r.inParagraph:
+		r.inParagraph = false
+		return r.format.closeParagraph()
+	case r.inList:
+		r.inList = false
+		return r.format.closeList(r.ordered)
+	}
+	return ""
+}
+
+// pending renders the unfinished line and the closers for any open blocks
+func (r *Renderer) pending() string {
+	var out strings.Builder
+	line := r.line
+
+	// Hold back a line that may be opening or closing a fence
+	if fencePrefixPattern.MatchString(line) && strings.Trim(strings.TrimSpace(line), "`") == "" {
+		line = ""
+	}
+
+	switch {
+	case r.inCode:
+		if line != "" {
+			out.WriteString(r.format.codeLine(line))
+		}
+		out.WriteString(r.format.closeCode())
+		return out.String()
+	case line == "":
+	case r.inParagraph:
+		out.WriteString(r.format.paragraphBreak() + r.format.plain(strings.TrimSpace(line)))
+	case r.inList && (unorderedPattern.MatchString(line) || orderedPattern.MatchString(line)):
+		out.WriteString(r.tentativeItem(line))
+	default:
+		if r.inList {
+			out.WriteString(r.format.closeList(r.ordered))
+		}
+		if m := headingPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			return out.String() + r.format.heading(len(m[1]), m[2])
+		}
+		return out.String() + r.format.openParagraph() + r.format.plain(strings.TrimSpace(line)) + r.format.closeParagraph()
+	}
+
+	if r.inParagraph {
+		out.WriteString(r.format.closeParagraph())
+	}
+	if r.inList {
+		out.WriteString(r.format.closeList(r.ordered))
+	}
+	return out.String()
+}
+
+// tentativeItem renders an unfinished list item without inline formatting
+func (r *Renderer) tentativeItem(line string) string {
+	text := line
+	if m := unorderedPattern.FindStringSubmatch(line); m != nil {
+		text = m[1]
+	} else if m := orderedPattern.FindStringSubmatch(line); m != nil {
+		text = m[1]
+	}
+	item := r.format.listItem(r.ordered, r.itemNumber+1, "")
+	// Insert the escaped tentative text where the empty item body would be
+	body := r.format.plain(text)
+	if idx := strings.Index(item, "</li>"); idx >= 0 {
+		return item[:idx] + body + item[idx:]
+	}
+	return strings.TrimSuffix(item, "\n") + body + "\n"
+}
@@ -0,0 +1,143 @@
+package render
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	"gomini/pkg/gomini"
+)
+
+func TestRenderer_HTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "paragraph with inline formatting",
+			input:    "Hello **world** and *you* with `x < y`\n",
+			expected: "<p>Hello <strong>world</strong> and <em>you</em> with <code>x &lt; y</code></p>\n",
+		},
+		{
+			name:     "heading and list",
+			input:    "# Title\n- one\n- two\n\n1. first\n",
+			expected: "<h1>Title</h1>\n<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n<ol>\n<li>first</li>\n</ol>\n",
+		},
+		{
+			name:     "code fence",
+			input:    "```go\nif a < b {\n```\n",
+			expected: "<pre><code class=\"language-go\">if a &lt; b {\n</code></pre>\n",
+		},
+		{
+			name:     "markdown inside code is literal",
+			input:    "```\n**not bold**\n- not a list\n```\n",
+			expected: "<pre><code>**not bold**\n- not a list\n</code></pre>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRenderer(FormatHTML)
+			r.Write(tt.input)
+			r.Flush()
+			if got := r.String(); got != tt.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRenderer_StableCommittedOutput(t *testing.T) {
+	input := "Intro **bold** text\n```python\nprint('hi')\n```\n- item one\n- item two\n"
+
+	// Feed one byte at a time; committed output must only ever grow
+	r := NewRenderer(FormatHTML)
+	var committed strings.Builder
+	for i := 0; i < len(input); i++ {
+		update := r.Write(input[i : i+1])
+		committed.WriteString(update.Committed)
+
+		if !strings.HasPrefix(r.String(), committed.String()) {
+			t.Fatalf("rendering at byte %d does not extend committed output", i)
+		}
+	}
+	committed.WriteString(r.Flush().Committed)
+
+	whole := NewRenderer(FormatHTML)
+	whole.Write(input)
+	whole.Flush()
+	if committed.String() != whole.String() {
+		t.Errorf("incremental output differs from one-shot output:\n%q\n%q", committed.String(), whole.String())
+	}
+}
+
+func TestRenderer_PendingClosesOpenBlocks(t *testing.T) {
+	r := NewRenderer(FormatHTML)
+
+	update := r.Write("```go\nfunc main() {")
+	if update.Pending != "func main() {\n</code></pre>\n" {
+		t.Errorf("expected tentative code line with closer, got %q", update.Pending)
+	}
+
+	update = r.Write("\n}\n``")
+	if strings.Contains(update.Pending, "``") {
+		t.Errorf("partial fence should be held back, got %q", update.Pending)
+	}
+
+	r = NewRenderer(FormatHTML)
+	update = r.Write("Some **bol")
+	if update.Pending != "<p>Some **bol</p>\n" {
+		t.Errorf("expected plain tentative paragraph, got %q", update.Pending)
+	}
+}
+
+func TestRenderer_ANSI(t *testing.T) {
+	r := NewRenderer(FormatANSI)
+	r.Write("- **a**\n1. b\n")
+	r.Flush()
+
+	out := r.String()
+	if !strings.Contains(out, "  • "+ansiBold+"a"+ansiReset) {
+		t.Errorf("expected bullet with bold text, got %q", out)
+	}
+	if !strings.Contains(out, "  1. b") {
+		t.Errorf("expected numbered item, got %q", out)
+	}
+}
+
+func TestRenderer_Consume(t *testing.T) {
+	events := make(chan gomini.StreamEvent, 3)
+	events <- gomini.StreamEvent{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "Hi ", Delta: true}}
+	events <- gomini.StreamEvent{Type: gomini.EventContent, Data: gomini.ContentEvent{Text: "there", Delta: true}}
+	events <- gomini.StreamEvent{Type: gomini.EventFinished}
+	close(events)
+
+	var committed strings.Builder
+	updates := 0
+	NewRenderer(FormatHTML).Consume(events, func(update Update) {
+		updates++
+		committed.WriteString(update.Committed)
+	})
+
+	if updates != 3 {
+		t.Errorf("expected 3 updates, got %d", updates)
+	}
+	if committed.String() != "<p>Hi there</p>\n" {
+		t.Errorf("unexpected output %q", committed.String())
+	}
+}
+
+func TestRenderer_HTMLTemplate(t *testing.T) {
+	r := NewRenderer(FormatHTML)
+	r.Write("<script>alert(1)</script>\n")
+
+	tmpl := template.Must(template.New("chat").Parse(`<div>{{.}}</div>`))
+	var out strings.Builder
+	if err := tmpl.Execute(&out, r.HTML()); err != nil {
+		t.Fatalf("template execution failed: %v", err)
+	}
+	if out.String() != "<div><p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n</div>" {
+		t.Errorf("unexpected template output %q", out.String())
+	}
+}
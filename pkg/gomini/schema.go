@@ -0,0 +1,361 @@
+package gomini
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaFor derives a JSON schema for T from its Go type and struct tags.
+//
+// Field names follow `json` tags. Fields tagged `omitempty` and pointer fields are
+// optional; all others are required. Pointer fields may also be null, as a nil
+// pointer marshals. A `description` tag documents the field and an `enum` tag
+// lists comma-separated allowed values. Objects derived from structs disallow
+// additional properties.
+func SchemaFor[T any]() (map[string]interface{}, error) {
+	return SchemaOf(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// SchemaOf derives a JSON schema for the given type. A pointer type describes
+// the value it points to.
+func SchemaOf(t reflect.Type) (map[string]interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return schemaOf(t, make(map[reflect.Type]bool))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) (map[string]interface{}, error) {
+	if t.Kind() == reflect.Ptr {
+		schema, err := schemaOf(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return nullable(schema), nil
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}, nil
+		}
+		items, err := schemaOf(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := schemaOf(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Struct:
+		return structSchema(t, visiting)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// nullable lets schema also accept null. Schemas without a type accept it already.
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	switch t := schema["type"].(type) {
+	case string:
+		schema["type"] = []interface{}{t, "null"}
+	case []interface{}:
+		for _, name := range t {
+			if name == "null" {
+				return schema
+			}
+		}
+		schema["type"] = append(t, "null")
+	}
+	return schema
+}
+
+func structSchema(t reflect.Type, visiting map[reflect.Type]bool) (map[string]interface{}, error) {
+	if visiting[t] {
+		return nil, fmt.Errorf("recursive type %s is not supported", t)
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := make(map[string]interface{})
+	required := make([]interface{}, 0)
+
+	if err := collectFields(t, visiting, properties, &required); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
+
+// collectFields adds the JSON-visible fields of t, flattening embedded structs like encoding/json
+func collectFields(t reflect.Type, visiting map[reflect.Type]bool, properties map[string]interface{}, required *[]interface{}) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := collectFields(embedded, visiting, properties, required); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema, err := schemaOf(field.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if description := field.Tag.Get("description"); description != "" {
+			schema["description"] = description
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			values := make([]interface{}, 0)
+			for _, value := range strings.Split(enum, ",") {
+				values = append(values, strings.TrimSpace(value))
+			}
+			if field.Type.Kind() == reflect.Ptr {
+				values = append(values, nil)
+			}
+			schema["enum"] = values
+		}
+
+		properties[name] = schema
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
+
+// FieldError describes a single schema violation at a JSON path
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// SchemaValidationError collects every schema violation found in a value
+type SchemaValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// Error implements the error interface
+func (e *SchemaValidationError) Error() string {
+	parts := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		parts = append(parts, fmt.Sprintf("%s: %s", fieldErr.Path, fieldErr.Message))
+	}
+	return fmt.Sprintf("schema validation failed: %s", strings.Join(parts, "; "))
+}
+
+// ValidateJSON checks a decoded JSON value against a schema produced by SchemaFor.
+// It supports type, properties, required, additionalProperties, items and enum.
+func ValidateJSON(value interface{}, schema map[string]interface{}) error {
+	var errs []FieldError
+	validateValue(value, schema, "$", &errs)
+	if len(errs) > 0 {
+		return &SchemaValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func validateValue(value interface{}, schema map[string]interface{}, path string, errs *[]FieldError) {
+	types := schemaTypes(schema["type"])
+	if len(types) > 0 {
+		matched := ""
+		for _, name := range types {
+			if jsonTypeMatches(value, name) {
+				matched = name
+				break
+			}
+		}
+		if matched == "" {
+			*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), jsonTypeName(value))})
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !enumContains(enum, value) {
+		*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf("value %v is not one of %v", value, enum)})
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		for _, name := range schemaRequired(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, FieldError{Path: path + "." + name, Message: "required field is missing"})
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if propertySchema, ok := properties[key].(map[string]interface{}); ok {
+				validateValue(v[key], propertySchema, path+"."+key, errs)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					*errs = append(*errs, FieldError{Path: path + "." + key, Message: "unexpected field"})
+				}
+			case map[string]interface{}:
+				validateValue(v[key], additional, path+"."+key, errs)
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				validateValue(item, items, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	}
+}
+
+func schemaTypes(raw interface{}) []string {
+	switch t := raw.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func schemaRequired(raw interface{}) []string {
+	switch r := raw.(type) {
+	case []string:
+		return r
+	case []interface{}:
+		names := make([]string, 0, len(r))
+		for _, item := range r {
+			if s, ok := item.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func jsonTypeMatches(value interface{}, name string) bool {
+	switch name {
+	case "null":
+		return value == nil
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		switch value.(type) {
+		case float64, json.Number:
+			return true
+		}
+		return false
+	case "integer":
+		switch n := value.(type) {
+		case float64:
+			return n == math.Trunc(n)
+		case json.Number:
+			_, err := n.Int64()
+			return err == nil
+		}
+		return false
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	}
+	return true
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package gomini
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaAddress struct {
+	Street string `json:"street"`
+}
+
+type schemaBase struct {
+	ID string `json:"id"`
+}
+
+type schemaPerson struct {
+	schemaBase
+	Name      string            `json:"name" description:"Full name"`
+	Age       int               `json:"age"`
+	Nickname  *string           `json:"nickname"`
+	Email     string            `json:"email,omitempty"`
+	Role      string            `json:"role" enum:"admin, user"`
+	Addresses []schemaAddress   `json:"addresses"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Joined    time.Time         `json:"joined"`
+	Ignored   string            `json:"-"`
+	internal  string
+}
+
+type schemaNode struct {
+	Children []schemaNode `json:"children"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema, err := SchemaFor[schemaPerson]()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	for _, name := range []string{"id", "name", "age", "nickname", "email", "role", "addresses", "labels", "joined"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("expected property %q", name)
+		}
+	}
+	for _, name := range []string{"Ignored", "internal", "schemaBase"} {
+		if _, ok := properties[name]; ok {
+			t.Errorf("unexpected property %q", name)
+		}
+	}
+
+	expectedRequired := []interface{}{"id", "name", "age", "role", "addresses", "joined"}
+	if !reflect.DeepEqual(schema["required"], expectedRequired) {
+		t.Errorf("expected required %v, got %v", expectedRequired, schema["required"])
+	}
+
+	name := properties["name"].(map[string]interface{})
+	if name["description"] != "Full name" {
+		t.Errorf("expected description, got %v", name["description"])
+	}
+	role := properties["role"].(map[string]interface{})
+	if !reflect.DeepEqual(role["enum"], []interface{}{"admin", "user"}) {
+		t.Errorf("unexpected enum %v", role["enum"])
+	}
+	age := properties["age"].(map[string]interface{})
+	if age["type"] != "integer" {
+		t.Errorf("expected integer age, got %v", age["type"])
+	}
+	joined := properties["joined"].(map[string]interface{})
+	if joined["format"] != "date-time" {
+		t.Errorf("expected date-time format, got %v", joined["format"])
+	}
+}
+
+func TestSchemaFor_RecursiveType(t *testing.T) {
+	if _, err := SchemaFor[schemaNode](); err == nil {
+		t.Error("expected an error for a recursive type")
+	}
+}
+
+type schemaContact struct {
+	Name    string         `json:"name"`
+	Phone   *string        `json:"phone"`
+	Status  *string        `json:"status" enum:"active,away"`
+	Address *schemaAddress `json:"address"`
+}
+
+func TestSchemaFor_NullablePointers(t *testing.T) {
+	schema, err := SchemaFor[schemaContact]()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	properties := schema["properties"].(map[string]interface{})
+	phone := properties["phone"].(map[string]interface{})
+	if !reflect.DeepEqual(phone["type"], []interface{}{"string", "null"}) {
+		t.Errorf("expected a nullable string, got %v", phone["type"])
+	}
+	status := properties["status"].(map[string]interface{})
+	if !reflect.DeepEqual(status["enum"], []interface{}{"active", "away", nil}) {
+		t.Errorf("expected null among the enum values, got %v", status["enum"])
+	}
+
+	// A value with nil pointers marshals to nulls that validate and decode back to nil
+	phoneNumber := "555-0100"
+	for _, contact := range []schemaContact{
+		{Name: "Ada"},
+		{Name: "Ada", Phone: &phoneNumber, Address: &schemaAddress{Street: "Main St"}},
+	} {
+		raw, err := json.Marshal(contact)
+		if err != nil {
+			t.Fatalf("marshal failed: %v", err)
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			t.Fatalf("unmarshal failed: %v", err)
+		}
+		if err := ValidateJSON(decoded, schema); err != nil {
+			t.Errorf("expected %s to validate, got %v", raw, err)
+		}
+		var roundTripped schemaContact
+		if err := json.Unmarshal(raw, &roundTripped); err != nil {
+			t.Fatalf("unmarshal failed: %v", err)
+		}
+		if !reflect.DeepEqual(roundTripped, contact) {
+			t.Errorf("expected %+v after the round trip, got %+v", contact, roundTripped)
+		}
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	schema, err := SchemaFor[schemaAddress]()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		value         interface{}
+		expectedPaths []string
+	}{
+		{
+			name:  "valid",
+			value: map[string]interface{}{"street": "Main St"},
+		},
+		{
+			name:          "missing field",
+			value:         map[string]interface{}{},
+			expectedPaths: []string{"$.street"},
+		},
+		{
+			name:          "wrong type",
+			value:         map[string]interface{}{"street": 42.0},
+			expectedPaths: []string{"$.street"},
+		},
+		{
+			name:          "unexpected field",
+			value:         map[string]interface{}{"street": "Main St", "zip": "100"},
+			expectedPaths: []string{"$.zip"},
+		},
+		{
+			name:          "not an object",
+			value:         []interface{}{},
+			expectedPaths: []string{"$"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJSON(tt.value, schema)
+			if len(tt.expectedPaths) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var validationErr *SchemaValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected SchemaValidationError, got %v", err)
+			}
+			paths := make([]string, 0, len(validationErr.Errors))
+			for _, fieldErr := range validationErr.Errors {
+				paths = append(paths, fieldErr.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.expectedPaths) {
+				t.Errorf("expected paths %v, got %v", tt.expectedPaths, paths)
+			}
+		})
+	}
+}
+
+func TestValidateJSON_Integer(t *testing.T) {
+	schema := map[string]interface{}{"type": "integer"}
+	if err := ValidateJSON(3.0, schema); err != nil {
+		t.Errorf("expected whole number to validate: %v", err)
+	}
+	if err := ValidateJSON(3.5, schema); err == nil {
+		t.Error("expected fractional number to fail integer validation")
+	}
+}
@@ -0,0 +1,78 @@
+package gomini
+
+import (
+	"context"
+	"io"
+)
+
+// StreamCallbacks dispatches stream events to per-type callbacks. Nil
+// callbacks are skipped.
+type StreamCallbacks struct {
+	OnToken    func(text string)
+	OnThought  func(thought ThoughtEvent)
+	OnToolCall func(call ToolCallEvent)
+	OnError    func(err error)
+}
+
+// Run drains stream, invoking the callbacks as events arrive, and returns
+// the collected response. It stops early with ctx.Err() if ctx is done.
+func (cb StreamCallbacks) Run(ctx context.Context, stream <-chan StreamEvent) (*ChatResponse, error) {
+	collector := NewStreamCollector()
+	for {
+		select {
+		case <-ctx.Done():
+			return collector.Response(), ctx.Err()
+		case event, ok := <-stream:
+			if !ok {
+				return collector.Response(), collector.Err()
+			}
+			collector.Add(event)
+			cb.dispatch(event)
+		}
+	}
+}
+
+// dispatch invokes the callback matching event
+func (cb StreamCallbacks) dispatch(event StreamEvent) {
+	switch event.Type {
+	case EventContent:
+		if content, ok := event.Data.(ContentEvent); ok && cb.OnToken != nil && content.Text != "" {
+			cb.OnToken(content.Text)
+		}
+	case EventThought:
+		if thought, ok := event.Data.(ThoughtEvent); ok && cb.OnThought != nil {
+			cb.OnThought(thought)
+		}
+	case EventToolCall:
+		if call, ok := event.Data.(ToolCallEvent); ok && cb.OnToolCall != nil {
+			cb.OnToolCall(call)
+		}
+	case EventError:
+		if cb.OnError != nil && event.Error != nil {
+			cb.OnError(event.Error)
+		}
+	}
+}
+
+// OnToken calls fn with each piece of content text in stream and returns
+// the first error the stream reports
+func OnToken(stream <-chan StreamEvent, fn func(string)) error {
+	_, err := StreamCallbacks{OnToken: fn}.Run(context.Background(), stream)
+	return err
+}
+
+// StreamTo writes the content text of stream to w as it arrives. It returns
+// the first write or stream error, or ctx.Err() if ctx is done first. After a
+// write error the rest of the stream is drained without writing.
+func StreamTo(ctx context.Context, stream <-chan StreamEvent, w io.Writer) error {
+	var writeErr error
+	_, err := StreamCallbacks{OnToken: func(text string) {
+		if writeErr == nil {
+			_, writeErr = io.WriteString(w, text)
+		}
+	}}.Run(ctx, stream)
+	if writeErr != nil {
+		return writeErr
+	}
+	return err
+}
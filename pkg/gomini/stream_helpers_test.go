@@ -0,0 +1,90 @@
+package gomini
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestStreamTo(t *testing.T) {
+	var out strings.Builder
+	err := StreamTo(context.Background(), streamOf(
+		NewContentEvent(ProviderOpenAI, "gpt-4o", "Hello, ", true),
+		NewThoughtEvent(ProviderOpenAI, "gpt-4o", "plan", "thinking"),
+		NewContentEvent(ProviderOpenAI, "gpt-4o", "world", true),
+	), &out)
+	if err != nil {
+		t.Fatalf("StreamTo failed: %v", err)
+	}
+	if out.String() != "Hello, world" {
+		t.Errorf("expected only content to be written, got %q", out.String())
+	}
+
+	stream := streamOf(
+		NewContentEvent(ProviderOpenAI, "gpt-4o", "a", true),
+		NewContentEvent(ProviderOpenAI, "gpt-4o", "b", true),
+	)
+	if err := StreamTo(context.Background(), stream, failingWriter{}); err == nil || err.Error() != "disk full" {
+		t.Errorf("expected the write error, got %v", err)
+	}
+	if _, ok := <-stream; ok {
+		t.Error("expected the stream to be drained after a write error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := StreamTo(ctx, make(chan StreamEvent), &out); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestOnToken(t *testing.T) {
+	streamErr := errors.New("stream broke")
+	var tokens []string
+	err := OnToken(streamOf(
+		NewContentEvent(ProviderOpenAI, "gpt-4o", "one", true),
+		NewContentEvent(ProviderOpenAI, "gpt-4o", "", true),
+		NewContentEvent(ProviderOpenAI, "gpt-4o", "two", true),
+		NewErrorEvent(ProviderOpenAI, "gpt-4o", streamErr, false),
+	), func(token string) {
+		tokens = append(tokens, token)
+	})
+	if err != streamErr {
+		t.Errorf("expected the stream error, got %v", err)
+	}
+	if strings.Join(tokens, ",") != "one,two" {
+		t.Errorf("expected non-empty tokens in order, got %v", tokens)
+	}
+}
+
+func TestStreamCallbacks_Run(t *testing.T) {
+	var thoughts []string
+	var calls []string
+	var errs []error
+	resp, err := StreamCallbacks{
+		OnThought:  func(thought ThoughtEvent) { thoughts = append(thoughts, thought.Subject) },
+		OnToolCall: func(call ToolCallEvent) { calls = append(calls, call.ToolName) },
+		OnError:    func(err error) { errs = append(errs, err) },
+	}.Run(context.Background(), streamOf(
+		NewThoughtEvent(ProviderGemini, "gemini-2.5-pro", "weather", "look it up"),
+		NewToolCallEvent(ProviderGemini, "gemini-2.5-pro", "call_1", "get_weather", map[string]interface{}{"city": "Oslo"}),
+		NewContentEvent(ProviderGemini, "gemini-2.5-pro", "Checking.", true),
+	))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(thoughts) != 1 || thoughts[0] != "weather" || len(calls) != 1 || calls[0] != "get_weather" || len(errs) != 0 {
+		t.Errorf("unexpected callbacks: thoughts %v, calls %v, errors %v", thoughts, calls, errs)
+	}
+	if content := collectedMessage(t, resp, 0)["content"]; content != "Checking." {
+		t.Errorf("expected the collected response, got %v", content)
+	}
+}
@@ -0,0 +1,75 @@
+package gomini
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// Rough token accounting constants used when no provider tokenizer is available
+const (
+	charsPerToken        = 4   // Average characters per token for English text
+	messageOverheadToken = 4   // Role and separator tokens added per message
+	imageTokenEstimate   = 258 // Typical cost of one image part
+)
+
+// EstimateTokens returns an approximate token count for a list of messages.
+// It is intentionally conservative and meant for budgeting decisions
+// (context-window checks, truncation), not for billing.
+func EstimateTokens(messages []Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += EstimateMessageTokens(msg)
+	}
+	return total
+}
+
+// EstimateMessageTokens returns an approximate token count for a single message
+func EstimateMessageTokens(msg Message) int {
+	msgMap, ok := msg.(map[string]interface{})
+	if !ok {
+		return messageOverheadToken
+	}
+
+	tokens := messageOverheadToken + estimateContentTokens(msgMap["content"])
+	if toolCalls, exists := msgMap["tool_calls"]; exists {
+		if data, err := json.Marshal(toolCalls); err == nil {
+			tokens += EstimateTextTokens(string(data))
+		}
+	}
+	return tokens
+}
+
+// EstimateTextTokens returns an approximate token count for a piece of text
+func EstimateTextTokens(text string) int {
+	chars := utf8.RuneCountInString(text)
+	return (chars + charsPerToken - 1) / charsPerToken
+}
+
+// estimateContentTokens handles both string content and content part arrays
+func estimateContentTokens(content interface{}) int {
+	switch c := content.(type) {
+	case string:
+		return EstimateTextTokens(c)
+	case []interface{}:
+		tokens := 0
+		for _, item := range c {
+			part, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			switch part["type"] {
+			case "text":
+				if data, ok := part["data"].(map[string]interface{}); ok {
+					if text, ok := data["text"].(string); ok {
+						tokens += EstimateTextTokens(text)
+					}
+				}
+			case "image_url":
+				tokens += imageTokenEstimate
+			}
+		}
+		return tokens
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,38 @@
+// Package builtin provides ready-made tools for core.Client.RunTools: an
+// HTTP fetch limited to allowed hosts, file reads inside a sandbox root, the
+// current time and a calculator.
+package builtin
+
+import (
+	"fmt"
+
+	"gomini/pkg/core"
+	"gomini/pkg/gomini"
+)
+
+// Tool pairs a tool's schema with the function that runs it
+type Tool struct {
+	Definition gomini.FunctionTool
+	Run        core.ToolFunc
+	Options    []core.ToolOption
+}
+
+// Register registers tools with client and returns their definitions, to be
+// offered to the model with core.WithTools
+func Register(client *core.Client, tools ...Tool) []gomini.Tool {
+	definitions := make([]gomini.Tool, len(tools))
+	for i, tool := range tools {
+		client.RegisterTool(tool.Definition.Name, tool.Run, tool.Options...)
+		definitions[i] = tool.Definition
+	}
+	return definitions
+}
+
+// stringArg reads a required string argument
+func stringArg(invocation core.ToolInvocation, name string) (string, error) {
+	value, ok := invocation.Arguments[name].(string)
+	if !ok || value == "" {
+		return "", fmt.Errorf("%s: argument %q is required", invocation.Name, name)
+	}
+	return value, nil
+}
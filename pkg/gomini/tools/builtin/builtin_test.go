@@ -0,0 +1,39 @@
+package builtin
+
+import (
+	"context"
+	"testing"
+
+	"gomini/pkg/core"
+	"gomini/pkg/gomini"
+)
+
+func TestRegister(t *testing.T) {
+	config := gomini.NewConfig()
+	config.Providers[gomini.ProviderOpenAI] = &gomini.ProviderConfig{Enabled: true, APIKey: "test-key"}
+	client, err := core.NewClient(config)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	definitions := Register(client, CurrentTime(), Calculator())
+	if len(definitions) != 2 || definitions[0].(gomini.FunctionTool).Name != "current_time" {
+		t.Fatalf("unexpected definitions %+v", definitions)
+	}
+
+	responses := client.RunTools(context.Background(), []core.ToolInvocation{
+		{ID: "call_1", Name: "calculator", Arguments: map[string]interface{}{"expression": "6 * 7"}},
+		{ID: "call_2", Name: "current_time", Arguments: map[string]interface{}{"timezone": "Asia/Taipei"}},
+		{ID: "call_3", Name: "current_time", Arguments: map[string]interface{}{"timezone": "Mars/Olympus"}},
+	})
+	if !responses[0].Success || responses[0].Result.(map[string]interface{})["result"] != 42.0 {
+		t.Errorf("unexpected calculator response %+v", responses[0])
+	}
+	if !responses[1].Success || responses[1].Result.(map[string]interface{})["timezone"] != "Asia/Taipei" {
+		t.Errorf("unexpected time response %+v", responses[1])
+	}
+	if responses[2].Success {
+		t.Errorf("expected an unknown time zone to fail, got %+v", responses[2])
+	}
+}
@@ -0,0 +1,205 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gomini/pkg/core"
+	"gomini/pkg/gomini"
+)
+
+// Calculator returns the calculator tool, which evaluates arithmetic with
+// + - * / % ^, parentheses and decimal numbers
+func Calculator() Tool {
+	return Tool{
+		Definition: gomini.FunctionTool{
+			Name:        "calculator",
+			Description: "Evaluate an arithmetic expression in double-precision floating point, e.g. (3.5 + 2) * 4 ^ 2. Supports + - * / % ^ and parentheses.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"expression": map[string]interface{}{"type": "string"},
+				},
+				"required": []interface{}{"expression"},
+			},
+		},
+		Run: func(ctx context.Context, invocation core.ToolInvocation) (interface{}, error) {
+			expression, err := stringArg(invocation, "expression")
+			if err != nil {
+				return nil, err
+			}
+			value, err := Evaluate(expression)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"expression": expression, "result": value}, nil
+		},
+		Options: []core.ToolOption{core.WithToolRisk(core.ToolRiskLow)},
+	}
+}
+
+// Evaluate computes an arithmetic expression in float64, so decimal results
+// carry the usual rounding (0.1 + 0.2 is 0.30000000000000004). ^ binds
+// tightest and is right associative; a leading - or + applies to the operand
+// after it.
+func Evaluate(expression string) (float64, error) {
+	p := &exprParser{input: expression}
+	value, err := p.parseSum()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("result is not a finite number")
+	}
+	return value, nil
+}
+
+// exprParser is a recursive descent parser over expression
+type exprParser struct {
+	input string
+	pos   int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// next consumes the operator op when it is the next token
+func (p *exprParser) next(op byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.input) && p.input[p.pos] == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseSum() (float64, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		switch {
+		case p.next('+'):
+			right, err := p.parseProduct()
+			if err != nil {
+				return 0, err
+			}
+			left += right
+		case p.next('-'):
+			right, err := p.parseProduct()
+			if err != nil {
+				return 0, err
+			}
+			left -= right
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *exprParser) parseProduct() (float64, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		var op byte
+		switch {
+		case p.next('*'):
+			op = '*'
+		case p.next('/'):
+			op = '/'
+		case p.next('%'):
+			op = '%'
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			left *= right
+		case '/':
+			if right == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			left /= right
+		case '%':
+			if right == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			left = math.Mod(left, right)
+		}
+	}
+}
+
+func (p *exprParser) parseUnary() (float64, error) {
+	if p.next('-') {
+		value, err := p.parseUnary()
+		return -value, err
+	}
+	if p.next('+') {
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+func (p *exprParser) parsePower() (float64, error) {
+	base, err := p.parseOperand()
+	if err != nil {
+		return 0, err
+	}
+	if p.next('^') {
+		exponent, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		return math.Pow(base, exponent), nil
+	}
+	return base, nil
+}
+
+func (p *exprParser) parseOperand() (float64, error) {
+	if p.next('(') {
+		value, err := p.parseSum()
+		if err != nil {
+			return 0, err
+		}
+		if !p.next(')') {
+			return 0, fmt.Errorf("missing closing parenthesis")
+		}
+		return value, nil
+	}
+
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.') {
+		p.pos++
+	}
+	if start == p.pos {
+		if p.pos >= len(p.input) {
+			return 0, fmt.Errorf("unexpected end of expression")
+		}
+		return 0, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+	}
+	number := strings.TrimSpace(p.input[start:p.pos])
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", number)
+	}
+	return value, nil
+}
@@ -0,0 +1,31 @@
+package builtin
+
+import "testing"
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"2 ^ 3 ^ 2", 512},
+		{"-2 ^ 2", -4},
+		{"10 / 4", 2.5},
+		{"10 % 4", 2},
+		{"3.5 - -1.5", 5},
+		{" 42 ", 42},
+	}
+	for _, tt := range tests {
+		got, err := Evaluate(tt.expression)
+		if err != nil || got != tt.want {
+			t.Errorf("Evaluate(%q) = %v, %v; want %v", tt.expression, got, err, tt.want)
+		}
+	}
+
+	for _, expression := range []string{"", "1 +", "(1 + 2", "1 / 0", "2 $ 3", "1.2.3", "10 ^ 400"} {
+		if _, err := Evaluate(expression); err == nil {
+			t.Errorf("expected %q to fail", expression)
+		}
+	}
+}
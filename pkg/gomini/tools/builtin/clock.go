@@ -0,0 +1,43 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gomini/pkg/core"
+	"gomini/pkg/gomini"
+)
+
+// CurrentTime returns the current_time tool, which reports the time in an
+// IANA time zone, UTC by default
+func CurrentTime() Tool {
+	return Tool{
+		Definition: gomini.FunctionTool{
+			Name:        "current_time",
+			Description: "Get the current date and time.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"timezone": map[string]interface{}{"type": "string", "description": "IANA time zone such as Asia/Taipei; defaults to UTC"},
+				},
+			},
+		},
+		Run: func(ctx context.Context, invocation core.ToolInvocation) (interface{}, error) {
+			location := time.UTC
+			if zone, _ := invocation.Arguments["timezone"].(string); zone != "" {
+				var err error
+				if location, err = time.LoadLocation(zone); err != nil {
+					return nil, fmt.Errorf("unknown time zone %q", zone)
+				}
+			}
+			now := time.Now().In(location)
+			return map[string]interface{}{
+				"time":     now.Format(time.RFC3339),
+				"weekday":  now.Weekday().String(),
+				"timezone": location.String(),
+			}, nil
+		},
+		Options: []core.ToolOption{core.WithToolRisk(core.ToolRiskLow)},
+	}
+}
@@ -0,0 +1,101 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gomini/pkg/core"
+	"gomini/pkg/gomini"
+)
+
+// MaxReadBytes caps the content returned by ReadFile; longer files are
+// truncated
+const MaxReadBytes = 256 << 10
+
+// ReadFile returns the read_file tool, which reads a file by its path
+// relative to root. Paths, symlinks included, cannot leave root.
+func ReadFile(root string) Tool {
+	return Tool{
+		Definition: gomini.FunctionTool{
+			Name:        "read_file",
+			Description: "Read a text file from the workspace.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{"type": "string", "description": "Path relative to the workspace root"},
+				},
+				"required": []interface{}{"path"},
+			},
+		},
+		Run: func(ctx context.Context, invocation core.ToolInvocation) (interface{}, error) {
+			path, err := stringArg(invocation, "path")
+			if err != nil {
+				return nil, err
+			}
+			resolved, err := sandboxPath(root, path)
+			if err != nil {
+				return nil, err
+			}
+			return readFile(resolved)
+		},
+		Options: []core.ToolOption{core.WithToolRisk(core.ToolRiskLow)},
+	}
+}
+
+// sandboxPath resolves path inside root, following symlinks, and fails when
+// the result lies outside root
+func sandboxPath(root, path string) (string, error) {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid sandbox root: %w", err)
+	}
+	realRoot, err = filepath.Abs(realRoot)
+	if err != nil {
+		return "", fmt.Errorf("invalid sandbox root: %w", err)
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Join(realRoot, filepath.Clean("/"+path)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("file %s does not exist", path)
+		}
+		return "", err
+	}
+	if resolved != realRoot && !strings.HasPrefix(resolved, realRoot+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside the sandbox", path)
+	}
+	return resolved, nil
+}
+
+func readFile(path string) (interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", filepath.Base(path))
+	}
+
+	content, err := io.ReadAll(io.LimitReader(file, MaxReadBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	truncated := len(content) > MaxReadBytes
+	if truncated {
+		content = content[:MaxReadBytes]
+	}
+	return map[string]interface{}{
+		"content":   string(content),
+		"size":      info.Size(),
+		"truncated": truncated,
+	}, nil
+}
@@ -0,0 +1,42 @@
+package builtin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gomini/pkg/core"
+)
+
+func TestReadFile_Sandbox(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(root, "notes.txt"), []byte("hello"), 0o644)
+	os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644)
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	tool := ReadFile(root)
+	read := func(path string) (interface{}, error) {
+		return tool.Run(context.Background(), core.ToolInvocation{Name: "read_file", Arguments: map[string]interface{}{"path": path}})
+	}
+
+	result, err := read("notes.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content := result.(map[string]interface{})["content"]; content != "hello" {
+		t.Errorf("expected the file content, got %v", content)
+	}
+	if result, err := read("../../notes.txt"); err != nil || result.(map[string]interface{})["content"] != "hello" {
+		t.Errorf("expected .. to stop at the root, got %v, %v", result, err)
+	}
+
+	for _, path := range []string{"link.txt", "missing.txt", "."} {
+		if _, err := read(path); err == nil {
+			t.Errorf("expected reading %s to fail", path)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gomini/pkg/core"
+	"gomini/pkg/gomini"
+)
+
+// MaxFetchBytes caps the response body returned by HTTPFetch; longer bodies
+// are truncated
+const MaxFetchBytes = 1 << 20
+
+// fetchTimeout bounds a single fetch, redirects included
+const fetchTimeout = 30 * time.Second
+
+// HTTPFetch returns the http_fetch tool, which GETs an http(s) URL on one of
+// allowedHosts or their subdomains. Redirects are followed only to allowed
+// hosts. Results are cached per session for a minute.
+func HTTPFetch(allowedHosts ...string) Tool {
+	allowed := make([]string, len(allowedHosts))
+	for i, host := range allowedHosts {
+		allowed[i] = strings.ToLower(strings.TrimSuffix(host, "."))
+	}
+	client := &http.Client{
+		Timeout: fetchTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return fmt.Errorf("too many redirects")
+			}
+			return checkFetchURL(req.URL, allowed)
+		},
+	}
+
+	return Tool{
+		Definition: gomini.FunctionTool{
+			Name:        "http_fetch",
+			Description: "Fetch a web page or API response by URL with an HTTP GET. Only some hosts are allowed.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"url": map[string]interface{}{"type": "string", "description": "Absolute http or https URL"},
+				},
+				"required": []interface{}{"url"},
+			},
+		},
+		Run: func(ctx context.Context, invocation core.ToolInvocation) (interface{}, error) {
+			raw, err := stringArg(invocation, "url")
+			if err != nil {
+				return nil, err
+			}
+			target, err := url.Parse(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid url: %w", err)
+			}
+			if err := checkFetchURL(target, allowed); err != nil {
+				return nil, err
+			}
+			return fetch(ctx, client, target.String())
+		},
+		Options: []core.ToolOption{core.WithToolRisk(core.ToolRiskLow), core.WithToolCacheTTL(time.Minute)},
+	}
+}
+
+// checkFetchURL rejects non-http(s) URLs and hosts outside allowed
+func checkFetchURL(target *url.URL, allowed []string) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return fmt.Errorf("unsupported url scheme %q", target.Scheme)
+	}
+	host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+	for _, allowedHost := range allowed {
+		if host == allowedHost || strings.HasSuffix(host, "."+allowedHost) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %q is not allowed", target.Hostname())
+}
+
+func fetch(ctx context.Context, client *http.Client, target string) (interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxFetchBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	truncated := len(body) > MaxFetchBytes
+	if truncated {
+		body = body[:MaxFetchBytes]
+	}
+	return map[string]interface{}{
+		"status":       resp.StatusCode,
+		"content_type": resp.Header.Get("Content-Type"),
+		"body":         string(body),
+		"truncated":    truncated,
+	}, nil
+}
@@ -0,0 +1,48 @@
+package builtin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"gomini/pkg/core"
+)
+
+func TestHTTPFetch_Allowlist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://blocked.example/", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	host, _ := url.Parse(server.URL)
+
+	tool := HTTPFetch(host.Hostname())
+	fetch := func(target string) (interface{}, error) {
+		return tool.Run(context.Background(), core.ToolInvocation{Name: "http_fetch", Arguments: map[string]interface{}{"url": target}})
+	}
+
+	result, err := fetch(server.URL + "/page")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	page := result.(map[string]interface{})
+	if page["status"] != 200 || page["body"] != "ok" || page["content_type"] != "text/plain" {
+		t.Errorf("unexpected result %+v", page)
+	}
+
+	for _, target := range []string{"http://blocked.example/", "file:///etc/passwd", server.URL + "/redirect", "http://" + host.Hostname() + ".evil.example/"} {
+		if _, err := fetch(target); err == nil {
+			t.Errorf("expected fetching %s to fail", target)
+		}
+	}
+
+	if err := checkFetchURL(&url.URL{Scheme: "https", Host: "api.github.com"}, []string{"github.com"}); err != nil {
+		t.Errorf("expected subdomains to be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package gomini
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// TransportConfig tunes the HTTP connections a provider makes: proxying, TLS
+// (including client certificates for mTLS gateways) and connection pooling.
+// Zero values keep Go's defaults.
+type TransportConfig struct {
+	ProxyURL            string        `json:"proxy_url,omitempty"` // Overrides HTTP(S)_PROXY from the environment
+	CAFile              string        `json:"ca_file,omitempty"`   // PEM bundle trusted in addition to the system roots
+	CertFile            string        `json:"cert_file,omitempty"` // Client certificate for mTLS
+	KeyFile             string        `json:"key_file,omitempty"`
+	InsecureSkipVerify  bool          `json:"insecure_skip_verify,omitempty"`
+	TLSConfig           *tls.Config   `json:"-"` // Used as the base TLS config when set
+	DialTimeout         time.Duration `json:"dial_timeout,omitempty"`
+	TLSHandshakeTimeout time.Duration `json:"tls_handshake_timeout,omitempty"`
+	MaxIdleConns        int           `json:"max_idle_conns,omitempty"`
+	MaxIdleConnsPerHost int           `json:"max_idle_conns_per_host,omitempty"`
+	MaxConnsPerHost     int           `json:"max_conns_per_host,omitempty"`
+	IdleConnTimeout     time.Duration `json:"idle_conn_timeout,omitempty"`
+}
+
+// HTTPClient returns the HTTP client a provider should send with: the
+// injected Client, a client built from Transport, or nil for the SDK
+// default
+func (pc *ProviderConfig) HTTPClient() (*http.Client, error) {
+	if pc.Client != nil {
+		return pc.Client, nil
+	}
+	if pc.Transport == nil {
+		return nil, nil
+	}
+	transport, err := pc.Transport.NewTransport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// NewTransport builds an *http.Transport from the config, starting from a
+// clone of http.DefaultTransport
+func (t *TransportConfig) NewTransport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if t.ProxyURL != "" {
+		proxy, err := url.Parse(t.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	tlsConfig, err := t.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if t.DialTimeout > 0 {
+		dialer := &net.Dialer{Timeout: t.DialTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+	}
+	if t.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = t.TLSHandshakeTimeout
+	}
+	if t.MaxIdleConns > 0 {
+		transport.MaxIdleConns = t.MaxIdleConns
+	}
+	if t.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = t.MaxIdleConnsPerHost
+	}
+	if t.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = t.MaxConnsPerHost
+	}
+	if t.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = t.IdleConnTimeout
+	}
+	return transport, nil
+}
+
+// tlsConfig returns the TLS settings, or nil when none are configured
+func (t *TransportConfig) tlsConfig() (*tls.Config, error) {
+	if t.TLSConfig == nil && t.CAFile == "" && t.CertFile == "" && !t.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if t.TLSConfig != nil {
+		config = t.TLSConfig.Clone()
+	}
+	if t.InsecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", t.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+	return config, nil
+}
@@ -0,0 +1,81 @@
+package gomini
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTransportConfig_NewTransport(t *testing.T) {
+	transport, err := (&TransportConfig{
+		ProxyURL:            "http://proxy.internal:3128",
+		InsecureSkipVerify:  true,
+		DialTimeout:         time.Second,
+		MaxIdleConnsPerHost: 64,
+		MaxConnsPerHost:     128,
+		IdleConnTimeout:     time.Minute,
+	}).NewTransport()
+	if err != nil {
+		t.Fatalf("NewTransport failed: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.openai.com/v1/models", nil)
+	if proxy, err := transport.Proxy(req); err != nil || proxy.Host != "proxy.internal:3128" {
+		t.Errorf("expected the configured proxy, got %v, %v", proxy, err)
+	}
+	if transport.TLSClientConfig == nil || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("expected TLS verification to be disabled, got %+v", transport.TLSClientConfig)
+	}
+	if transport.MaxIdleConnsPerHost != 64 || transport.MaxConnsPerHost != 128 || transport.IdleConnTimeout != time.Minute {
+		t.Errorf("unexpected pool settings %+v", transport)
+	}
+}
+
+func TestTransportConfig_Errors(t *testing.T) {
+	badCA := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(badCA, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config TransportConfig
+	}{
+		{"invalid proxy", TransportConfig{ProxyURL: "://bad"}},
+		{"missing CA file", TransportConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{"CA file without certificates", TransportConfig{CAFile: badCA}},
+		{"missing client certificate", TransportConfig{CertFile: "missing.crt", KeyFile: "missing.key"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.config.NewTransport(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestProviderConfig_HTTPClient(t *testing.T) {
+	injected := &http.Client{}
+	tests := []struct {
+		name   string
+		config ProviderConfig
+		check  func(*http.Client) bool
+	}{
+		{"default", ProviderConfig{}, func(c *http.Client) bool { return c == nil }},
+		{"injected client wins", ProviderConfig{Client: injected, Transport: &TransportConfig{MaxConnsPerHost: 1}},
+			func(c *http.Client) bool { return c == injected }},
+		{"built from transport", ProviderConfig{Transport: &TransportConfig{MaxConnsPerHost: 1}},
+			func(c *http.Client) bool { return c != nil && c.Transport.(*http.Transport).MaxConnsPerHost == 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := tt.config.HTTPClient()
+			if err != nil || !tt.check(client) {
+				t.Errorf("unexpected client %+v, %v", client, err)
+			}
+		})
+	}
+}
@@ -11,6 +11,19 @@ type (
 	Message = providers.Message
 	RequestConfig = providers.RequestConfig  
 	Tool = providers.Tool
+	ToolCall = providers.ToolCall
+	FunctionTool = providers.FunctionTool
+	ToolChoice = providers.ToolChoice
+	ToolChoiceMode = providers.ToolChoiceMode
+	ImagePart = providers.ImagePart
+	AudioPart = providers.AudioPart
+	DocumentPart = providers.DocumentPart
+	Reasoning = providers.Reasoning
+	SafetyInfo = providers.SafetyInfo
+	SafetyRating = providers.SafetyRating
+	CitationSpan = providers.CitationSpan
+	TokenLogprob = providers.TokenLogprob
+	ReasoningEffort = providers.ReasoningEffort
 	Choice = providers.Choice
 	ProviderType = providers.ProviderType
 	
@@ -19,16 +32,31 @@ type (
 	ChatResponse = providers.ChatResponse
 	JSONRequest = providers.JSONRequest
 	JSONResponse = providers.JSONResponse
+	ImageRequest = providers.ImageRequest
+	ImageResponse = providers.ImageResponse
+	GeneratedImage = providers.GeneratedImage
+	ImageUsage = providers.ImageUsage
+	SpeechRequest = providers.SpeechRequest
+	SpeechResponse = providers.SpeechResponse
+	TranscriptionRequest = providers.TranscriptionRequest
+	TranscriptionResponse = providers.TranscriptionResponse
+	ModerationResult = providers.ModerationResult
+	StreamLatency = providers.StreamLatency
 	// StreamEvent = providers.StreamEvent // Defined in events.go
 	
 	// Model and capability types
 	Model = providers.Model
 	ModelCapabilities = providers.ModelCapabilities
+	ModelProfile = providers.ModelProfile
+	CapabilityTable = providers.CapabilityTable
 	ProviderCapabilities = providers.ProviderCapabilities
+	Requirements = providers.Requirements
 	
 	// Safety and configuration types
 	SafetySetting = providers.SafetySetting
 	Usage = providers.Usage
+	ModelPrice = providers.ModelPrice
+	PriceTable = providers.PriceTable
 	FinishReason = providers.FinishReason
 	
 	// Event types (some defined in events.go)
@@ -37,10 +65,33 @@ type (
 
 // Provider constants for convenience
 const (
-	ProviderOpenAI = providers.ProviderOpenAI
-	ProviderGemini = providers.ProviderGemini
+	ProviderOpenAI   = providers.ProviderOpenAI
+	ProviderGemini   = providers.ProviderGemini
+	ProviderGroq     = providers.ProviderGroq
+	ProviderDeepSeek = providers.ProviderDeepSeek
+	ProviderMock     = providers.ProviderMock
 )
 
+// Reasoning effort levels
+const (
+	ReasoningEffortLow    = providers.ReasoningEffortLow
+	ReasoningEffortMedium = providers.ReasoningEffortMedium
+	ReasoningEffortHigh   = providers.ReasoningEffortHigh
+)
+
+// Tool choice modes
+const (
+	ToolChoiceAuto     = providers.ToolChoiceAuto
+	ToolChoiceNone     = providers.ToolChoiceNone
+	ToolChoiceRequired = providers.ToolChoiceRequired
+	ToolChoiceFunction = providers.ToolChoiceFunction
+)
+
+// ForceTool returns a ToolChoice that makes the model call the named tool
+func ForceTool(name string) ToolChoice {
+	return providers.ForceTool(name)
+}
+
 // Additional helper types specific to main package can be defined here
 // For now, we rely on the providers package types for foundational functionality
 
@@ -64,4 +115,23 @@ func NewAssistantMessage(content string) Message {
 		"role":    "assistant",
 		"content": content,
 	}
+}
+
+// NewAssistantToolCallMessage creates an assistant message carrying tool calls
+func NewAssistantToolCallMessage(content string, toolCalls []ToolCall) Message {
+	return map[string]interface{}{
+		"role":       "assistant",
+		"content":    content,
+		"tool_calls": toolCalls,
+	}
+}
+
+// NewToolResultMessage creates a message carrying the result of a tool call
+func NewToolResultMessage(callID, toolName, content string) Message {
+	return map[string]interface{}{
+		"role":         "tool",
+		"tool_call_id": callID,
+		"name":         toolName,
+		"content":      content,
+	}
 }
\ No newline at end of file